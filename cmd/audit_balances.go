@@ -0,0 +1,367 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// balanceAuditTables are the tables folded into the balance deltas of the accounts
+var balanceAuditTables = []string{"transactions", "operations", "effects"}
+
+// balanceAuditSampleSize is the number of mismatched accounts kept in the report
+const balanceAuditSampleSize = 1000
+
+// balanceMismatch is an account whose balance change according to the effects and fees disagrees with the ledger
+type balanceMismatch struct {
+	Account      string `json:"account"`
+	EffectsDelta string `json:"effects_delta"`
+	LedgerDelta  string `json:"ledger_delta"`
+	Difference   string `json:"difference"`
+}
+
+// balanceAuditReport is the outcome of the balance audit of a range
+type balanceAuditReport struct {
+	Start      uint32            `json:"start_ledger"`
+	End        uint32            `json:"end_ledger"`
+	Accounts   int               `json:"audited_accounts"`
+	Failures   int               `json:"failed_transforms"`
+	Mismatches int               `json:"mismatched_accounts"`
+	Samples    []balanceMismatch `json:"mismatches"`
+}
+
+// balanceAudit folds the native balance changes of the accounts of a range. The effects and fees give one
+// delta, the ledger entry changes of the accounts give another: the balance after their last change minus the
+// balance before their first change.
+type balanceAudit struct {
+	effects map[string]int64
+	before  map[string]int64
+	after   map[string]int64
+}
+
+func newBalanceAudit() *balanceAudit {
+	return &balanceAudit{
+		effects: map[string]int64{},
+		before:  map[string]int64{},
+		after:   map[string]int64{},
+	}
+}
+
+var auditBalancesCmd = &cobra.Command{
+	Use:   "audit_balances",
+	Short: "Reconciles the effects of a range with the balances of the accounts",
+	Long: `Folds the effects and transaction fees of a range into a native balance delta per account, and compares it with
+the balance change recorded in the ledger entry changes of the account over the same range. The accounts where they
+disagree are written to a json report, and the command fails if any is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		ctx := context.Background()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		report, err := auditBalances(ctx, backend, startNum, commonArgs.EndNum, env.NetworkPassphrase)
+		if err != nil {
+			cmdLogger.Fatal("could not audit balances: ", err)
+		}
+
+		if err := writeJSONReport(path, report); err != nil {
+			cmdLogger.Fatal(err)
+		}
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if report.Mismatches > 0 {
+			cmdLogger.Fatalf("balances of %d of %d accounts disagree with their effects in ledgers [%d, %d]; see %s", report.Mismatches, report.Accounts, report.Start, report.End, path)
+		}
+		cmdLogger.Infof("Balances of %d accounts agree with their effects in ledgers [%d, %d]", report.Accounts, report.Start, report.End)
+	},
+}
+
+// auditBalances transforms the transactions, operations and effects of a range and reconciles them with the
+// ledger entry changes of the accounts
+func auditBalances(ctx context.Context, backend ledgerbackend.LedgerBackend, start, end uint32, networkPassphrase string) (balanceAuditReport, error) {
+	report := balanceAuditReport{Start: start, End: end, Samples: []balanceMismatch{}}
+
+	tables, err := input.SelectLedgerTables(balanceAuditTables)
+	if err != nil {
+		return report, err
+	}
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return report, fmt.Errorf("could not prepare range [%d, %d]: %v", start, end, err)
+	}
+
+	audit := newBalanceAudit()
+	for seq := start; seq <= end; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			return report, fmt.Errorf("error getting ledger seq %d from the backend: %v", seq, err)
+		}
+
+		ledger, err := input.DecodeLedger(lcm, networkPassphrase)
+		if err != nil {
+			return report, fmt.Errorf("could not decode ledger %d: %v", seq, err)
+		}
+
+		rows := map[string][]interface{}{}
+		for _, table := range tables {
+			transformed, err := table.Transform(ledger, networkPassphrase)
+			if err != nil {
+				cmdLogger.WithFields(utils.RowLogFields(table.Name, seq, "")).LogError(fmt.Errorf("could not transform %s in ledger %d: %w", table.Name, seq, err))
+				report.Failures++
+			}
+			rows[table.Name] = transformed
+		}
+
+		audit.addChanges(ledger.Changes)
+		if err := audit.addRows(rows); err != nil {
+			return report, fmt.Errorf("could not fold the effects of ledger %d: %v", seq, err)
+		}
+	}
+
+	mismatches := audit.mismatches()
+	report.Accounts = len(audit.accounts())
+	report.Mismatches = len(mismatches)
+	if len(mismatches) > balanceAuditSampleSize {
+		mismatches = mismatches[:balanceAuditSampleSize]
+	}
+	report.Samples = append(report.Samples, mismatches...)
+	return report, nil
+}
+
+// addChanges records the balances of the accounts before their first change and after their last change
+func (a *balanceAudit) addChanges(changes []ingest.Change) {
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeAccount {
+			continue
+		}
+
+		var account string
+		var before, after int64
+		if change.Pre != nil {
+			account = change.Pre.Data.MustAccount().AccountId.Address()
+			before = int64(change.Pre.Data.MustAccount().Balance)
+		}
+		if change.Post != nil {
+			account = change.Post.Data.MustAccount().AccountId.Address()
+			after = int64(change.Post.Data.MustAccount().Balance)
+		}
+
+		if _, ok := a.before[account]; !ok {
+			a.before[account] = before
+		}
+		a.after[account] = after
+	}
+}
+
+// addRows folds the fees of the transactions and the native amounts of the effects into the balance deltas
+func (a *balanceAudit) addRows(rows map[string][]interface{}) error {
+	for _, row := range rows["transactions"] {
+		transaction := row.(transform.TransactionOutput)
+		feeAccount := transaction.FeeAccount
+		if feeAccount == "" {
+			feeAccount = transaction.Account
+		}
+		a.effects[feeAccount] -= transaction.FeeCharged
+	}
+
+	// The source of a path payment is debited what it sends, so its side of the trades along the path would count
+	// the amounts twice
+	pathPayments := map[int64]string{}
+	for _, row := range rows["operations"] {
+		operation := row.(transform.OperationOutput)
+		switch xdr.OperationType(operation.Type) {
+		case xdr.OperationTypePathPaymentStrictReceive, xdr.OperationTypePathPaymentStrictSend:
+			pathPayments[operation.OperationID] = operation.SourceAccount
+		}
+	}
+
+	for _, row := range rows["effects"] {
+		effect := row.(transform.EffectOutput)
+		if source, ok := pathPayments[effect.OperationID]; ok {
+			effectType := transform.EffectType(effect.Type)
+			if effectType == transform.EffectLiquidityPoolTrade || (effectType == transform.EffectTrade && effect.Address == source) {
+				continue
+			}
+		}
+
+		delta, err := nativeEffectDelta(effect)
+		if err != nil {
+			return fmt.Errorf("effect %s: %v", effect.EffectId, err)
+		}
+		if delta != 0 {
+			a.effects[effect.Address] += delta
+		}
+	}
+
+	return nil
+}
+
+// nativeEffectDelta is the change of the native balance of the account of an effect
+func nativeEffectDelta(effect transform.EffectOutput) (int64, error) {
+	// The details hold structs of the horizon protocol for some effects, which are read through their json
+	marshalled, err := json.Marshal(effect.Details)
+	if err != nil {
+		return 0, err
+	}
+	details := map[string]interface{}{}
+	if err := json.Unmarshal(marshalled, &details); err != nil {
+		return 0, err
+	}
+
+	switch transform.EffectType(effect.Type) {
+	case transform.EffectAccountCreated:
+		return parseDetailAmount(details["starting_balance"])
+	case transform.EffectAccountCredited:
+		if details["asset_type"] != "native" {
+			return 0, nil
+		}
+		return parseDetailAmount(details["amount"])
+	case transform.EffectAccountDebited:
+		if details["asset_type"] != "native" {
+			return 0, nil
+		}
+		debited, err := parseDetailAmount(details["amount"])
+		return -debited, err
+	case transform.EffectTrade:
+		var delta int64
+		if details["bought_asset_type"] == "native" {
+			bought, err := parseDetailAmount(details["bought_amount"])
+			if err != nil {
+				return 0, err
+			}
+			delta += bought
+		}
+		if details["sold_asset_type"] == "native" {
+			sold, err := parseDetailAmount(details["sold_amount"])
+			if err != nil {
+				return 0, err
+			}
+			delta -= sold
+		}
+		return delta, nil
+	case transform.EffectLiquidityPoolTrade:
+		// sold and bought are the amounts of the pool, so the account gets what the pool sold
+		received, err := nativeAssetAmount(details["sold"])
+		if err != nil {
+			return 0, err
+		}
+		paid, err := nativeAssetAmount(details["bought"])
+		return received - paid, err
+	case transform.EffectLiquidityPoolDeposited:
+		deposited, err := nativeAssetAmounts(details["reserves_deposited"])
+		return -deposited, err
+	case transform.EffectLiquidityPoolWithdrew:
+		return nativeAssetAmounts(details["reserves_received"])
+	default:
+		return 0, nil
+	}
+}
+
+func parseDetailAmount(value interface{}) (int64, error) {
+	s, ok := value.(string)
+	if !ok {
+		return 0, fmt.Errorf("expected an amount, got %v", value)
+	}
+	return amount.ParseInt64(s)
+}
+
+// nativeAssetAmount reads an {asset, amount} detail, which only counts for the native asset
+func nativeAssetAmount(value interface{}) (int64, error) {
+	assetAmount, ok := value.(map[string]interface{})
+	if !ok {
+		return 0, fmt.Errorf("expected an asset amount, got %v", value)
+	}
+	if assetAmount["asset"] != "native" {
+		return 0, nil
+	}
+	return parseDetailAmount(assetAmount["amount"])
+}
+
+func nativeAssetAmounts(value interface{}) (int64, error) {
+	assetAmounts, ok := value.([]interface{})
+	if !ok {
+		return 0, fmt.Errorf("expected a list of asset amounts, got %v", value)
+	}
+	var total int64
+	for _, assetAmount := range assetAmounts {
+		native, err := nativeAssetAmount(assetAmount)
+		if err != nil {
+			return 0, err
+		}
+		total += native
+	}
+	return total, nil
+}
+
+// accounts returns the accounts with effects or ledger entry changes
+func (a *balanceAudit) accounts() map[string]bool {
+	accounts := map[string]bool{}
+	for account := range a.effects {
+		accounts[account] = true
+	}
+	for account := range a.before {
+		accounts[account] = true
+	}
+	return accounts
+}
+
+// mismatches returns the accounts whose deltas disagree, sorted by account
+func (a *balanceAudit) mismatches() []balanceMismatch {
+	mismatches := []balanceMismatch{}
+	for account := range a.accounts() {
+		effectsDelta := a.effects[account]
+		ledgerDelta := a.after[account] - a.before[account]
+		if effectsDelta != ledgerDelta {
+			mismatches = append(mismatches, balanceMismatch{
+				Account:      account,
+				EffectsDelta: amount.StringFromInt64(effectsDelta),
+				LedgerDelta:  amount.StringFromInt64(ledgerDelta),
+				Difference:   amount.StringFromInt64(effectsDelta - ledgerDelta),
+			})
+		}
+	}
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Account < mismatches[j].Account
+	})
+	return mismatches
+}
+
+func init() {
+	rootCmd.AddCommand(auditBalancesCmd)
+	utils.AddCommonFlags(auditBalancesCmd.Flags())
+	utils.AddArchiveFlags("audit_balances", auditBalancesCmd.Flags())
+	utils.AddCloudStorageFlags(auditBalancesCmd.Flags())
+	auditBalancesCmd.Flags().Lookup("output").DefValue = "balance_audit.json"
+	auditBalancesCmd.Flags().Set("output", "balance_audit.json")
+	auditBalancesCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the range
+			end-ledger: the ledger sequence number for the end of the range (required)
+
+			output-file: filename of the audit report
+	*/
+}
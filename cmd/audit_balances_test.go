@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+const (
+	auditTestAccountA = "GAOEOQMXDDXPVJC3HDFX6LZFKANJ4OOLQOD2MNXJ7PGAY5FEO4BRRAQU"
+	auditTestAccountB = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+)
+
+func auditTestAccountEntry(address string, balance int64) *xdr.LedgerEntry {
+	return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type:    xdr.LedgerEntryTypeAccount,
+		Account: &xdr.AccountEntry{AccountId: xdr.MustAddress(address), Balance: xdr.Int64(balance)},
+	}}
+}
+
+func auditTestChange(address string, before, after int64) ingest.Change {
+	return ingest.Change{
+		Type: xdr.LedgerEntryTypeAccount,
+		Pre:  auditTestAccountEntry(address, before),
+		Post: auditTestAccountEntry(address, after),
+	}
+}
+
+func TestBalanceAudit(t *testing.T) {
+	audit := newBalanceAudit()
+
+	// A pays a fee of 100 stroops and sends 10 XLM to B, then sells 2 XLM for 1 USD to B's offer
+	audit.addChanges([]ingest.Change{
+		auditTestChange(auditTestAccountA, 1000_0000000, 999_9999900),
+		auditTestChange(auditTestAccountA, 999_9999900, 989_9999900),
+		auditTestChange(auditTestAccountB, 50_0000000, 60_0000000),
+		auditTestChange(auditTestAccountA, 989_9999900, 987_9999900),
+		auditTestChange(auditTestAccountB, 60_0000000, 62_0000000),
+	})
+	require.NoError(t, audit.addRows(map[string][]interface{}{
+		"transactions": {transform.TransactionOutput{Account: auditTestAccountA, FeeCharged: 100}},
+		"operations": {
+			transform.OperationOutput{OperationID: 1, Type: int32(xdr.OperationTypePayment), SourceAccount: auditTestAccountA},
+			transform.OperationOutput{OperationID: 2, Type: int32(xdr.OperationTypeManageSellOffer), SourceAccount: auditTestAccountA},
+		},
+		"effects": {
+			transform.EffectOutput{Address: auditTestAccountB, OperationID: 1, Type: int32(transform.EffectAccountCredited), Details: map[string]interface{}{"asset_type": "native", "amount": "10.0000000"}},
+			transform.EffectOutput{Address: auditTestAccountA, OperationID: 1, Type: int32(transform.EffectAccountDebited), Details: map[string]interface{}{"asset_type": "native", "amount": "10.0000000"}},
+			transform.EffectOutput{Address: auditTestAccountA, OperationID: 2, Type: int32(transform.EffectTrade), Details: map[string]interface{}{
+				"sold_asset_type": "native", "sold_amount": "2.0000000", "bought_asset_type": "credit_alphanum4", "bought_amount": "1.0000000",
+			}},
+			transform.EffectOutput{Address: auditTestAccountB, OperationID: 2, Type: int32(transform.EffectTrade), Details: map[string]interface{}{
+				"sold_asset_type": "credit_alphanum4", "sold_amount": "1.0000000", "bought_asset_type": "native", "bought_amount": "2.0000000",
+			}},
+			transform.EffectOutput{Address: auditTestAccountB, OperationID: 2, Type: int32(transform.EffectOfferUpdated)},
+		},
+	}))
+
+	assert.Len(t, audit.accounts(), 2)
+	assert.Equal(t, []balanceMismatch{}, audit.mismatches())
+
+	// an effect the ledger does not agree with
+	require.NoError(t, audit.addRows(map[string][]interface{}{
+		"effects": {
+			transform.EffectOutput{Address: auditTestAccountB, Type: int32(transform.EffectAccountCredited), Details: map[string]interface{}{"asset_type": "native", "amount": "0.5000000"}},
+		},
+	}))
+	assert.Equal(t, []balanceMismatch{
+		{Account: auditTestAccountB, EffectsDelta: "12.5000000", LedgerDelta: "12.0000000", Difference: "0.5000000"},
+	}, audit.mismatches())
+}
+
+func TestBalanceAuditPathPayment(t *testing.T) {
+	audit := newBalanceAudit()
+
+	// A sends 5 XLM through a liquidity pool and B's offer, B receives them back as USD
+	audit.addChanges([]ingest.Change{
+		auditTestChange(auditTestAccountA, 100_0000000, 95_0000000),
+		{Type: xdr.LedgerEntryTypeAccount, Post: auditTestAccountEntry(auditTestAccountB, 3_0000000)},
+	})
+	require.NoError(t, audit.addRows(map[string][]interface{}{
+		"operations": {
+			transform.OperationOutput{OperationID: 1, Type: int32(xdr.OperationTypePathPaymentStrictSend), SourceAccount: auditTestAccountA},
+		},
+		"effects": {
+			transform.EffectOutput{Address: auditTestAccountB, OperationID: 1, Type: int32(transform.EffectAccountCreated), Details: map[string]interface{}{"starting_balance": "3.0000000"}},
+			transform.EffectOutput{Address: auditTestAccountA, OperationID: 1, Type: int32(transform.EffectAccountDebited), Details: map[string]interface{}{"asset_type": "native", "amount": "5.0000000"}},
+			transform.EffectOutput{Address: auditTestAccountA, OperationID: 1, Type: int32(transform.EffectLiquidityPoolTrade), Details: map[string]interface{}{
+				"sold":   map[string]string{"asset": "EUR:" + auditTestAccountB, "amount": "4.0000000"},
+				"bought": map[string]string{"asset": "native", "amount": "5.0000000"},
+			}},
+			transform.EffectOutput{Address: auditTestAccountA, OperationID: 1, Type: int32(transform.EffectTrade), Details: map[string]interface{}{
+				"sold_asset_type": "credit_alphanum4", "sold_amount": "4.0000000", "bought_asset_type": "credit_alphanum4", "bought_amount": "4.0000000",
+			}},
+		},
+	}))
+
+	assert.Equal(t, []balanceMismatch{}, audit.mismatches())
+}
+
+func TestNativeEffectDelta(t *testing.T) {
+	deposited := transform.EffectOutput{Type: int32(transform.EffectLiquidityPoolDeposited), Details: map[string]interface{}{
+		"reserves_deposited": []base.AssetAmount{{Asset: "native", Amount: "1.5000000"}, {Asset: "USD:" + auditTestAccountA, Amount: "3.0000000"}},
+	}}
+	delta, err := nativeEffectDelta(deposited)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-1_5000000), delta)
+
+	withdrew := transform.EffectOutput{Type: int32(transform.EffectLiquidityPoolWithdrew), Details: map[string]interface{}{
+		"reserves_received": []base.AssetAmount{{Asset: "USD:" + auditTestAccountA, Amount: "3.0000000"}, {Asset: "native", Amount: "0.2500000"}},
+	}}
+	delta, err = nativeEffectDelta(withdrew)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2500000), delta)
+
+	credited := transform.EffectOutput{Type: int32(transform.EffectAccountCredited), Details: map[string]interface{}{"asset_type": "credit_alphanum4", "amount": "7.0000000"}}
+	delta, err = nativeEffectDelta(credited)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), delta)
+
+	_, err = nativeEffectDelta(transform.EffectOutput{Type: int32(transform.EffectAccountCreated), Details: map[string]interface{}{}})
+	assert.EqualError(t, err, "expected an amount, got <nil>")
+}
+
+func TestAuditBalances(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeDuckDBTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makeDuckDBTestLedger(11), nil)
+
+	report, err := auditBalances(context.Background(), backend, 10, 11, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.Equal(t, balanceAuditReport{Start: 10, End: 11, Samples: []balanceMismatch{}}, report)
+}
@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// benchResult is the throughput of the transform of a table
+type benchResult struct {
+	Table         string  `json:"table"`
+	Ledgers       int     `json:"ledgers"`
+	Rows          int     `json:"rows"`
+	Failures      int     `json:"failures"`
+	Seconds       float64 `json:"seconds"`
+	RowsPerSec    float64 `json:"rows_per_sec"`
+	MBPerSec      float64 `json:"mb_per_sec"`
+	AllocsPerRow  float64 `json:"allocs_per_row"`
+	BytesPerRow   float64 `json:"bytes_allocated_per_row"`
+	OutputBytes   int     `json:"output_bytes"`
+	AllocatedMB   float64 `json:"allocated_mb"`
+	TotalAllocs   uint64  `json:"total_allocs"`
+	LedgersPerSec float64 `json:"ledgers_per_sec"`
+}
+
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmarks the transforms over a set of ledgers",
+	Long: `Runs the transform of every table over a set of ledgers and reports rows/sec, MB/sec and allocations per table
+as json lines. The ledgers are either read from a file with one base64 encoded LedgerCloseMeta per line, or from the
+datastore for the given range. Ledgers are read and decoded before the transforms are timed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		ledgerFile, err := cmd.Flags().GetString("ledger-file")
+		if err != nil {
+			cmdLogger.Fatal("could not get ledger-file: ", err)
+		}
+
+		iterations, err := cmd.Flags().GetInt("iterations")
+		if err != nil {
+			cmdLogger.Fatal("could not get iterations: ", err)
+		}
+
+		tableFilter, err := cmd.Flags().GetStringSlice("bench-tables")
+		if err != nil {
+			cmdLogger.Fatal("could not get bench-tables: ", err)
+		}
+
+		var lcms []xdr.LedgerCloseMeta
+		if ledgerFile != "" {
+			lcms, err = readLedgerCloseMetaFile(ledgerFile)
+		} else {
+			if commonArgs.EndNum == 0 {
+				cmdLogger.Fatal("either ledger-file or end-ledger is required")
+			}
+			lcms, err = readLedgerCloseMetaRange(startNum, commonArgs.EndNum, env)
+		}
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", err)
+		}
+
+		ledgers, err := prepareBenchLedgers(lcms, env.NetworkPassphrase)
+		if err != nil {
+			cmdLogger.Fatal("could not decode ledgers: ", err)
+		}
+
+		tables, err := input.SelectLedgerTables(tableFilter)
+		if err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		out := cmd.OutOrStdout()
+		if path != "" && path != "-" {
+			outFile := MustOutFile(path)
+			defer outFile.Close()
+			out = outFile
+		}
+
+		encoder := json.NewEncoder(out)
+		for _, table := range tables {
+			result := runBenchTable(table, ledgers, env.NetworkPassphrase, iterations)
+			if err := encoder.Encode(result); err != nil {
+				cmdLogger.Fatal("could not write bench result: ", err)
+			}
+		}
+	},
+}
+
+// readLedgerCloseMetaFile reads one base64 encoded LedgerCloseMeta per line
+func readLedgerCloseMetaFile(path string) ([]xdr.LedgerCloseMeta, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lcms := []xdr.LedgerCloseMeta{}
+	reader := bufio.NewReader(file)
+	for lineNumber := 1; ; lineNumber++ {
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, err
+		}
+
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			var lcm xdr.LedgerCloseMeta
+			if decodeErr := xdr.SafeUnmarshalBase64(trimmed, &lcm); decodeErr != nil {
+				return nil, fmt.Errorf("could not decode ledger close meta on line %d: %v", lineNumber, decodeErr)
+			}
+			lcms = append(lcms, lcm)
+		}
+
+		if err == io.EOF {
+			return lcms, nil
+		}
+	}
+}
+
+func readLedgerCloseMetaRange(start, end uint32, env utils.EnvironmentDetails) ([]xdr.LedgerCloseMeta, error) {
+	ctx := context.Background()
+	backend, err := utils.CreateLedgerBackend(ctx, env.CommonFlagValues.UseCaptiveCore, env)
+	if err != nil {
+		return nil, err
+	}
+	defer backend.Close()
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return nil, err
+	}
+
+	lcms := []xdr.LedgerCloseMeta{}
+	for seq := start; seq <= end; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			return nil, fmt.Errorf("error getting ledger seq %d from the backend: %v", seq, err)
+		}
+		lcms = append(lcms, lcm)
+	}
+
+	return lcms, nil
+}
+
+func prepareBenchLedgers(lcms []xdr.LedgerCloseMeta, networkPassphrase string) ([]input.DecodedLedger, error) {
+	ledgers := []input.DecodedLedger{}
+	for _, lcm := range lcms {
+		ledger, err := input.DecodeLedger(lcm, networkPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		ledgers = append(ledgers, ledger)
+	}
+
+	return ledgers, nil
+}
+
+func runBenchTable(table input.LedgerTable, ledgers []input.DecodedLedger, networkPassphrase string, iterations int) benchResult {
+	result := benchResult{Table: table.Name, Ledgers: len(ledgers) * iterations}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < iterations; i++ {
+		for _, ledger := range ledgers {
+			rows, err := table.Transform(ledger, networkPassphrase)
+			if err != nil {
+				result.Failures++
+				cmdLogger.Debugf("could not transform %s in ledger %d: %v", table.Name, ledger.Header.Header.LedgerSeq, err)
+				continue
+			}
+			result.Rows += len(rows)
+
+			// Serializing the rows is part of the cost of exporting them
+			for _, row := range rows {
+				marshalled, err := json.Marshal(row)
+				if err != nil {
+					result.Failures++
+					continue
+				}
+				result.OutputBytes += len(marshalled)
+			}
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	result.Seconds = elapsed.Seconds()
+	result.TotalAllocs = after.Mallocs - before.Mallocs
+	result.AllocatedMB = float64(after.TotalAlloc-before.TotalAlloc) / (1024 * 1024)
+	if result.Seconds > 0 {
+		result.RowsPerSec = float64(result.Rows) / result.Seconds
+		result.LedgersPerSec = float64(result.Ledgers) / result.Seconds
+		result.MBPerSec = float64(result.OutputBytes) / (1024 * 1024) / result.Seconds
+	}
+	if result.Rows > 0 {
+		result.AllocsPerRow = float64(result.TotalAllocs) / float64(result.Rows)
+		result.BytesPerRow = float64(after.TotalAlloc-before.TotalAlloc) / float64(result.Rows)
+	}
+
+	return result
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	utils.AddCommonFlags(benchCmd.Flags())
+	utils.AddArchiveFlags("bench", benchCmd.Flags())
+	benchCmd.Flags().String("ledger-file", "", "File with one base64 encoded LedgerCloseMeta per line to benchmark instead of a ledger range")
+	benchCmd.Flags().Int("iterations", 1, "Number of times the transforms are run over the ledgers")
+	benchCmd.Flags().StringSlice("bench-tables", nil, "Tables to benchmark; all of them if empty")
+	benchCmd.Flags().Lookup("output").DefValue = "-"
+	benchCmd.Flags().Set("output", "-")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the benchmark range
+			end-ledger: the ledger sequence number for the end of the benchmark range
+
+			ledger-file: file of base64 encoded ledger close metas to benchmark instead of a range
+			iterations: number of times the transforms are run over the ledgers
+			bench-tables: tables to benchmark
+
+			output-file: filename of the results; stdout by default
+	*/
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchTable(t *testing.T) {
+	calls := 0
+	table := input.LedgerTable{Name: "test", Transform: func(ledger input.DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+		calls++
+		if calls == 3 {
+			return nil, errors.New("could not transform")
+		}
+		return []interface{}{map[string]int{"a": 1}, map[string]int{"b": 2}}, nil
+	}}
+
+	result := runBenchTable(table, []input.DecodedLedger{{}, {}}, "", 2)
+	assert.Equal(t, 4, calls)
+	assert.Equal(t, "test", result.Table)
+	assert.Equal(t, 4, result.Ledgers)
+	assert.Equal(t, 6, result.Rows)
+	assert.Equal(t, 1, result.Failures)
+	assert.Equal(t, 6*len(`{"a":1}`), result.OutputBytes)
+}
+
+func TestReadLedgerCloseMetaFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ledgers.txt")
+	require.NoError(t, os.WriteFile(path, []byte("\nnot base64\n"), 0644))
+
+	_, err := readLedgerCloseMetaFile(path)
+	assert.ErrorContains(t, err, "could not decode ledger close meta on line 2")
+
+	require.NoError(t, os.WriteFile(path, []byte("\n"), 0644))
+	lcms, err := readLedgerCloseMetaFile(path)
+	require.NoError(t, err)
+	assert.Empty(t, lcms)
+}
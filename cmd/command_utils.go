@@ -2,18 +2,70 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
 	"github.com/xitongsys/parquet-go-source/local"
 	"github.com/xitongsys/parquet-go/writer"
+	"google.golang.org/protobuf/encoding/protowire"
 )
 
+// outputFormat is the encoding of the rows written by ExportEntry, set from the output-format flag of the export
+// commands: json lines, a json array, or protobuf messages each prefixed with its varint encoded length
+var outputFormat = utils.OutputFormatJSON
+
+// jsonArrayFiles are the output files whose json array was opened by the first row written to them. The rows are
+// streamed into the array as they are exported, and closeOutFile closes it.
+var (
+	jsonArrayMu    sync.Mutex
+	jsonArrayFiles = map[*os.File]bool{}
+)
+
+// timestampFormat is the representation of the timestamps of the json rows written by ExportEntry, set from the
+// timestamp-format flag of the export commands
+var timestampFormat = utils.TimestampFormatRFC3339
+
 type CloudStorage interface {
 	UploadTo(credentialsPath, bucket, path string) error
+	CheckAccess() error
+}
+
+// shutdownContext is done once the command gets SIGINT or SIGTERM, so that the export stops at the next ledger and
+// writes out the ledgers read so far
+func shutdownContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// writeCheckpoint writes the last exported ledger of a network to the checkpoint file, if one is set
+func writeCheckpoint(checkpointFile, network string, lastLedger uint32) error {
+	if checkpointFile == "" {
+		return nil
+	}
+	return utils.WriteCheckpoint(checkpointFile, utils.Checkpoint{Network: network, LastLedger: lastLedger, UpdatedAt: time.Now().UTC()})
+}
+
+// recordCheckpoint records the last ledger of a bounded export once its rows are written. If the export was stopped
+// by a shutdown signal, it also logs the ledger to resume from.
+func recordCheckpoint(ctx context.Context, commonArgs utils.CommonFlagValues, network string, lastLedger uint32) {
+	if ctx.Err() != nil {
+		cmdLogger.Infof("stopped after a shutdown signal; ledgers are exported through %d, resume with --start-ledger %d", lastLedger, lastLedger+1)
+	}
+	if err := writeCheckpoint(commonArgs.CheckpointFile, network, lastLedger); err != nil {
+		cmdLogger.Fatal(err)
+	}
 }
 
 func createOutputFile(filepath string) error {
@@ -28,7 +80,13 @@ func createOutputFile(filepath string) error {
 	return nil
 }
 
+// MustOutFile creates the output file at path, or returns stdout for utils.StdoutPath. The rows are written to stdout
+// as they are exported, one write per row, so that it can be piped into other tools while the export runs.
 func MustOutFile(path string) *os.File {
+	if path == utils.StdoutPath {
+		return os.Stdout
+	}
+
 	absolutePath, err := filepath.Abs(path)
 	if err != nil {
 		cmdLogger.Fatal("could not get absolute filepath: ", err)
@@ -66,15 +124,44 @@ func ExportEntry(entry interface{}, outFile *os.File, extra map[string]string) (
 	if err != nil {
 		cmdLogger.Errorf("Error unmarshalling %+v: %v ", i, err)
 	}
+	transform.CanonicalNumbers(i)
 	for k, v := range extra {
 		i[k] = v
 	}
 
+	if outputFormat == utils.OutputFormatProto {
+		encoded, err := transform.EncodeProto(entry, i)
+		if err != nil {
+			return 0, fmt.Errorf("could not proto encode %+v: %s", entry, err)
+		}
+		cmdLogger.Debugf("Writing entry to %s", outFile.Name())
+		numBytes, err := outFile.Write(append(protowire.AppendVarint(nil, uint64(len(encoded))), encoded...))
+		if err != nil {
+			cmdLogger.Errorf("Error writing %+v to file: %s", entry, err)
+		}
+		return numBytes, nil
+	}
+
+	if timestampFormat == utils.TimestampFormatEpochMillis {
+		if err := transform.EpochMillisTimestamps(entry, i); err != nil {
+			return 0, fmt.Errorf("could not convert the timestamps of %+v: %s", entry, err)
+		}
+	}
+
 	marshalled, err := json.Marshal(i)
 	if err != nil {
 		return 0, fmt.Errorf("could not json encode %+v: %s", entry, err)
 	}
 	cmdLogger.Debugf("Writing entry to %s", outFile.Name())
+
+	if outputFormat == utils.OutputFormatJSONArray {
+		numBytes, err := outFile.Write(append(jsonArraySeparator(outFile), marshalled...))
+		if err != nil {
+			cmdLogger.Errorf("Error writing %+v to file: %s", entry, err)
+		}
+		return numBytes, nil
+	}
+
 	numBytes, err := outFile.Write(marshalled)
 	if err != nil {
 		cmdLogger.Errorf("Error writing %+v to file: %s", entry, err)
@@ -86,6 +173,133 @@ func ExportEntry(entry interface{}, outFile *os.File, extra map[string]string) (
 	return numBytes + newLineNumBytes, nil
 }
 
+// jsonArraySeparator returns what goes before a row of a json array file: the opening bracket for its first row, and
+// a comma for the others
+func jsonArraySeparator(outFile *os.File) []byte {
+	jsonArrayMu.Lock()
+	defer jsonArrayMu.Unlock()
+	if jsonArrayFiles[outFile] {
+		return []byte(",\n")
+	}
+	jsonArrayFiles[outFile] = true
+	return []byte("[\n")
+}
+
+// closeOutFile closes an output file once its rows are written, closing the json array of json-array exports first
+func closeOutFile(outFile *os.File) {
+	if outputFormat == utils.OutputFormatJSONArray {
+		jsonArrayMu.Lock()
+		opened := jsonArrayFiles[outFile]
+		delete(jsonArrayFiles, outFile)
+		jsonArrayMu.Unlock()
+
+		closing := "[]\n"
+		if opened {
+			closing = "\n]\n"
+		}
+		if _, err := outFile.WriteString(closing); err != nil {
+			cmdLogger.Errorf("Error closing the json array of file %s: %s", outFile.Name(), err)
+		}
+	}
+	outFile.Close()
+}
+
+// filterFailedTransactions drops the inputs of failed transactions unless includeFailed is set. Dropped inputs
+// are not counted as attempted transforms.
+func filterFailedTransactions[T any](inputs []T, includeFailed bool, transaction func(T) ingest.LedgerTransaction) []T {
+	if includeFailed {
+		return inputs
+	}
+
+	filtered := make([]T, 0, len(inputs))
+	for _, in := range inputs {
+		if transaction(in).Result.Successful() {
+			filtered = append(filtered, in)
+		}
+	}
+	return filtered
+}
+
+// filterSorobanTransactions keeps only the inputs of Soroban transactions when sorobanOnly is set, and only those of
+// classic transactions when classicOnly is set. Like filterFailedTransactions, dropped inputs are not counted as
+// attempted transforms.
+func filterSorobanTransactions[T any](inputs []T, sorobanOnly, classicOnly bool, transaction func(T) ingest.LedgerTransaction) []T {
+	if !sorobanOnly && !classicOnly {
+		return inputs
+	}
+
+	filtered := make([]T, 0, len(inputs))
+	for _, in := range inputs {
+		if isSorobanTransaction(transaction(in)) == sorobanOnly {
+			filtered = append(filtered, in)
+		}
+	}
+	return filtered
+}
+
+// filterOperationTypes keeps only the inputs whose operation is of one of the types, or every input if no type is
+// given. Like filterFailedTransactions, dropped inputs are not counted as attempted transforms.
+func filterOperationTypes[T any](inputs []T, types map[xdr.OperationType]bool, operation func(T) xdr.Operation) []T {
+	if len(types) == 0 {
+		return inputs
+	}
+
+	filtered := make([]T, 0, len(inputs))
+	for _, in := range inputs {
+		if types[operation(in).Body.Type] {
+			filtered = append(filtered, in)
+		}
+	}
+	return filtered
+}
+
+// filterTransactionOperationTypes keeps only the transactions with an operation of one of the types, or every transaction if no
+// type is given
+func filterTransactionOperationTypes[T any](inputs []T, types map[xdr.OperationType]bool, transaction func(T) ingest.LedgerTransaction) []T {
+	if len(types) == 0 {
+		return inputs
+	}
+
+	filtered := make([]T, 0, len(inputs))
+	for _, in := range inputs {
+		for _, operation := range transaction(in).Envelope.Operations() {
+			if types[operation.Body.Type] {
+				filtered = append(filtered, in)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// filterTransactionContracts keeps only the transactions that invoke one of the contracts or in which one of them emits
+// an event, or every transaction if no contracts are given
+func filterTransactionContracts[T any](inputs []T, contractIDs map[xdr.Hash]bool, transaction func(T) ingest.LedgerTransaction) []T {
+	if len(contractIDs) == 0 {
+		return inputs
+	}
+
+	filtered := make([]T, 0, len(inputs))
+	for _, in := range inputs {
+		if transform.TransactionTouchesContracts(transaction(in), contractIDs) {
+			filtered = append(filtered, in)
+		}
+	}
+	return filtered
+}
+
+// isSorobanTransaction tells whether a transaction invokes a host function, extends a footprint ttl or restores a
+// footprint. Soroban transactions have a single operation, so checking the operation types is enough.
+func isSorobanTransaction(transaction ingest.LedgerTransaction) bool {
+	for _, operation := range transaction.Envelope.Operations() {
+		switch operation.Body.Type {
+		case xdr.OperationTypeInvokeHostFunction, xdr.OperationTypeExtendFootprintTtl, xdr.OperationTypeRestoreFootprint:
+			return true
+		}
+	}
+	return false
+}
+
 // Prints the number of attempted, failed, and successful transformations as a JSON object
 func PrintTransformStats(attempts, failures int) {
 	resultsMap := map[string]int{
@@ -94,20 +308,18 @@ func PrintTransformStats(attempts, failures int) {
 		"successful_transforms": attempts - failures,
 	}
 
+	utils.RecordTransformStats(context.Background(), attempts, failures)
+
 	results, err := json.Marshal(resultsMap)
 	if err != nil {
 		cmdLogger.Fatal("Could not marshal results: ", err)
 	}
 
-	cmdLogger.Info(string(results))
-}
-
-func exportFilename(start, end uint32, dataType string) string {
-	return fmt.Sprintf("%d-%d-%s.txt", start, end-1, dataType)
-}
-
-func exportParquetFilename(start, end uint32, dataType string) string {
-	return fmt.Sprintf("%d-%d-%s.parquet", start, end-1, dataType)
+	cmdLogger.WithFields(log.F{
+		"attempted_transforms":  attempts,
+		"failed_transforms":     failures,
+		"successful_transforms": attempts - failures,
+	}).Info(string(results))
 }
 
 func deleteLocalFiles(path string) error {
@@ -126,6 +338,11 @@ func MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path strin
 		return
 	}
 
+	if path == utils.StdoutPath {
+		cmdLogger.Info("The output was written to stdout. Skipping upload.")
+		return
+	}
+
 	if len(cloudStorageBucket) == 0 {
 		cmdLogger.Fatal("No bucket specified")
 		return
@@ -145,12 +362,68 @@ func MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path strin
 	}
 }
 
+// checkCloudCredentials validates the cloud storage and sink credentials at startup when credentials-check is set,
+// so that a misconfigured deployment fails before spending time on the export. The bucket is only checked when the
+// outputs are uploaded, since the sinks, such as SQS or Pub/Sub, do not need a cloud provider.
+func checkCloudCredentials(cmd *cobra.Command) error {
+	check, err := cmd.Flags().GetBool("credentials-check")
+	if err != nil || !check {
+		return nil
+	}
+
+	if err := checkSinkCredentials(); err != nil {
+		return fmt.Errorf("credentials check failed: %v", err)
+	}
+
+	cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+	var cloudStorage CloudStorage
+	switch cloudProvider {
+	case "":
+		cmdLogger.Info("Credentials check passed for the sinks; no cloud provider is set, so nothing is uploaded")
+		return nil
+	case "gcp":
+		cloudStorage = newGCS(cloudCredentials, cloudStorageBucket)
+	default:
+		return fmt.Errorf("unknown cloud provider %s", cloudProvider)
+	}
+
+	if err := cloudStorage.CheckAccess(); err != nil {
+		return fmt.Errorf("credentials check failed: %v", err)
+	}
+	cmdLogger.Infof("Credentials check passed for %s bucket %s", cloudProvider, cloudStorageBucket)
+
+	return nil
+}
+
+// checkSinkCredentials checks that the AWS credentials of SQS and the Google credentials of Pub/Sub set in the
+// environment can be read
+func checkSinkCredentials() error {
+	resolvedAWS, err := utils.ResolveAWSCredentials()
+	if err != nil {
+		return err
+	}
+	awsCredentials, err := resolvedAWS.AWSCredentials()
+	if err != nil {
+		return err
+	}
+	if awsCredentials != nil {
+		if _, err := awsCredentials.Get(); err != nil {
+			return fmt.Errorf("could not read the aws credentials: %v", err)
+		}
+	}
+
+	if _, err := utils.ResolveCloudCredentials(""); err != nil {
+		return err
+	}
+	return nil
+}
+
 // WriteParquet creates the parquet file and writes the exported data into it.
 //
 // Parameters:
 //
-//	data []transform.SchemaParquet  - The slice of data to be written to the Parquet file.
-//										SchemaParquet is an interface used to call ToParquet()
+//	data *parquetRowBuffer          - The buffered data to be written to the Parquet file. The rows are
+//										SchemaParquet values, an interface used to call ToParquet()
 //										which is defined for each schema/export.
 //	path string                     - The file path where the Parquet file will be created and written.
 //										For example, "some/file/path/export_output.parquet"
@@ -159,7 +432,10 @@ func MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path strin
 //	Errors:
 //
 //	stellar-etl will log a Fatal error and stop in the case it cannot create or write to the parquet file
-func WriteParquet(data []transform.SchemaParquet, path string, schema interface{}) {
+func WriteParquet(data *parquetRowBuffer, path string, schema interface{}) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		cmdLogger.Fatalf("could not create directory %s: %s", path, err)
+	}
 	parquetFile, err := local.NewLocalFileWriter(path)
 	if err != nil {
 		cmdLogger.Fatal("could not create parquet file: ", err)
@@ -172,9 +448,10 @@ func WriteParquet(data []transform.SchemaParquet, path string, schema interface{
 	}
 	defer writer.WriteStop()
 
-	for _, record := range data {
-		if err := writer.Write(record.ToParquet()); err != nil {
-			cmdLogger.Fatal("could not write record to parquet file: ", err)
-		}
+	err = data.each(schema, func(record interface{}) error {
+		return writer.Write(record)
+	})
+	if err != nil {
+		cmdLogger.Fatal("could not write record to parquet file: ", err)
 	}
 }
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestExportEntryJSONArray(t *testing.T) {
+	outputFormat = utils.OutputFormatJSONArray
+	defer func() { outputFormat = utils.OutputFormatJSON }()
+
+	dir := t.TempDir()
+	rowsPath := filepath.Join(dir, "rows.json")
+	emptyPath := filepath.Join(dir, "empty.json")
+	rowsFile := MustOutFile(rowsPath)
+	emptyFile := MustOutFile(emptyPath)
+	total := 0
+	for sequence := 1; sequence <= 3; sequence++ {
+		numBytes, err := ExportEntry(map[string]interface{}{"sequence": sequence}, rowsFile, map[string]string{"network": "testnet"})
+		require.NoError(t, err)
+		total += numBytes
+	}
+	closeOutFile(rowsFile)
+	closeOutFile(emptyFile)
+
+	contents, err := os.ReadFile(rowsPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[\n{\"network\":\"testnet\",\"sequence\":1},\n{\"network\":\"testnet\",\"sequence\":2},\n{\"network\":\"testnet\",\"sequence\":3}\n]\n", string(contents))
+	assert.Equal(t, len(contents)-len("\n]\n"), total)
+	var rows []map[string]interface{}
+	require.NoError(t, json.Unmarshal(contents, &rows))
+	assert.Len(t, rows, 3)
+
+	empty, err := os.ReadFile(emptyPath)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", string(empty))
+}
+
+func TestWriteCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state", "checkpoint.json")
+
+	require.NoError(t, writeCheckpoint(path, "testnet", 163))
+	require.NoError(t, writeCheckpoint(path, "testnet", 227))
+	checkpoint, err := utils.ReadCheckpoint(path)
+	require.NoError(t, err)
+	assert.Equal(t, "testnet", checkpoint.Network)
+	assert.Equal(t, uint32(227), checkpoint.LastLedger)
+	assert.False(t, checkpoint.UpdatedAt.IsZero())
+
+	// Only the checkpoint is left in the folder, without the temporary files it was written through
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	// Without a checkpoint file nothing is written
+	require.NoError(t, writeCheckpoint("", "testnet", 227))
+
+	assert.Equal(t, "state/checkpoint-pubnet.json", utils.NetworkCheckpointPath("state/checkpoint.json", "pubnet"))
+	assert.Equal(t, "checkpoint-pubnet", utils.NetworkCheckpointPath("checkpoint", "pubnet"))
+}
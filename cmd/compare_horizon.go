@@ -0,0 +1,594 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest/ledgerbackend"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// horizonParityTables are the tables transformed to compare the sampled transactions with horizon
+var horizonParityTables = []string{"transactions", "operations", "effects"}
+
+// horizonParitySampleSize is the number of differences kept in the report
+const horizonParitySampleSize = 1000
+
+// horizonPageLimit is the number of records requested per page of horizon
+const horizonPageLimit = 200
+
+// defaultHorizonURLs are the horizon instances of the networks, used when no horizon-url is set
+var defaultHorizonURLs = map[string]string{
+	"pubnet":    "https://horizon.stellar.org",
+	"testnet":   "https://horizon-testnet.stellar.org",
+	"futurenet": "https://horizon-futurenet.stellar.org",
+}
+
+// parityOptions are how the transactions are sampled and their fields compared
+type parityOptions struct {
+	SampleRate      float64
+	MaxTransactions int
+	Tolerance       float64
+}
+
+// parityDifference is a field of a record whose value in the export differs from horizon. Records missing on one
+// side have no field.
+type parityDifference struct {
+	Transaction string `json:"transaction_hash"`
+	Table       string `json:"table"`
+	ID          string `json:"id"`
+	Field       string `json:"field,omitempty"`
+	ETL         string `json:"etl"`
+	Horizon     string `json:"horizon"`
+}
+
+// parityReport is the outcome of the comparison of the sampled transactions of a range with horizon
+type parityReport struct {
+	Start        uint32             `json:"start_ledger"`
+	End          uint32             `json:"end_ledger"`
+	HorizonURL   string             `json:"horizon_url"`
+	Transactions int                `json:"sampled_transactions"`
+	Records      map[string]int     `json:"compared_records"`
+	Fields       int                `json:"compared_fields"`
+	Failures     int                `json:"failed_transforms"`
+	Differences  int                `json:"differences"`
+	Samples      []parityDifference `json:"samples"`
+}
+
+// add counts the differences of a transaction and keeps the first horizonParitySampleSize of them
+func (r *parityReport) add(differences []parityDifference) {
+	r.Differences += len(differences)
+	for _, difference := range differences {
+		if len(r.Samples) >= horizonParitySampleSize {
+			return
+		}
+		r.Samples = append(r.Samples, difference)
+	}
+}
+
+// horizonClient reads the operations and effects of transactions from a horizon instance
+type horizonClient struct {
+	url    string
+	client *http.Client
+}
+
+func newHorizonClient(horizonURL string, timeout time.Duration) *horizonClient {
+	return &horizonClient{
+		url:    strings.TrimSuffix(horizonURL, "/"),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// horizonPage is a page of records of a horizon collection
+type horizonPage struct {
+	Embedded struct {
+		Records []map[string]interface{} `json:"records"`
+	} `json:"_embedded"`
+}
+
+// records returns all the records of a collection of a transaction, such as operations or effects, following the
+// pages of horizon until one is not full
+func (c *horizonClient) records(ctx context.Context, hash, collection string) ([]map[string]interface{}, error) {
+	records := []map[string]interface{}{}
+	cursor := ""
+	for {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(horizonPageLimit))
+		query.Set("order", "asc")
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+		if collection == "operations" {
+			query.Set("include_failed", "true")
+		}
+
+		page, err := c.page(ctx, fmt.Sprintf("%s/transactions/%s/%s?%s", c.url, hash, collection, query.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, page.Embedded.Records...)
+		if len(page.Embedded.Records) < horizonPageLimit {
+			return records, nil
+		}
+
+		token, ok := page.Embedded.Records[len(page.Embedded.Records)-1]["paging_token"].(string)
+		if !ok {
+			return nil, fmt.Errorf("%s of transaction %s have no paging_token", collection, hash)
+		}
+		cursor = token
+	}
+}
+
+func (c *horizonClient) page(ctx context.Context, pageURL string) (horizonPage, error) {
+	var page horizonPage
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return page, err
+	}
+	req.Header.Set("Accept", "application/hal+json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return page, fmt.Errorf("could not get %s: %v", pageURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return page, fmt.Errorf("could not get %s: status %d", pageURL, resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	if err := decoder.Decode(&page); err != nil {
+		return page, fmt.Errorf("could not decode %s: %v", pageURL, err)
+	}
+	return page, nil
+}
+
+var compareHorizonCmd = &cobra.Command{
+	Use:   "compare_horizon",
+	Short: "Compares the operations and effects of sampled transactions with horizon",
+	Long: `Transforms the operations and effects of a sample of the transactions of a range, fetches the same
+transactions from a horizon instance, and compares the records field by field. Only the fields present on both sides
+are compared, and numeric values are equal if they differ by at most the tolerance. The differences are written to a
+json report, and the command fails if any is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		horizonURL, err := cmd.Flags().GetString("horizon-url")
+		if err != nil {
+			cmdLogger.Fatal("could not get horizon-url: ", err)
+		}
+		if horizonURL == "" {
+			horizonURL = defaultHorizonURLs[env.Network]
+		}
+
+		var options parityOptions
+		options.SampleRate, err = cmd.Flags().GetFloat64("sample-rate")
+		if err != nil {
+			cmdLogger.Fatal("could not get sample-rate: ", err)
+		}
+		if options.SampleRate <= 0 || options.SampleRate > 1 {
+			cmdLogger.Fatalf("sample-rate must be in (0, 1], got %v", options.SampleRate)
+		}
+
+		options.MaxTransactions, err = cmd.Flags().GetInt("max-transactions")
+		if err != nil {
+			cmdLogger.Fatal("could not get max-transactions: ", err)
+		}
+
+		options.Tolerance, err = cmd.Flags().GetFloat64("tolerance")
+		if err != nil {
+			cmdLogger.Fatal("could not get tolerance: ", err)
+		}
+		if options.Tolerance < 0 {
+			cmdLogger.Fatalf("tolerance must not be negative, got %v", options.Tolerance)
+		}
+
+		timeout, err := cmd.Flags().GetDuration("horizon-timeout")
+		if err != nil {
+			cmdLogger.Fatal("could not get horizon-timeout: ", err)
+		}
+
+		ctx := context.Background()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		report, err := compareHorizon(ctx, backend, newHorizonClient(horizonURL, timeout), startNum, commonArgs.EndNum, env.NetworkPassphrase, options)
+		if err != nil {
+			cmdLogger.Fatal("could not compare with horizon: ", err)
+		}
+
+		if err := writeJSONReport(path, report); err != nil {
+			cmdLogger.Fatal(err)
+		}
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if report.Differences > 0 {
+			cmdLogger.Fatalf("found %d differences with %s in %d transactions of ledgers [%d, %d]; see %s", report.Differences, horizonURL, report.Transactions, report.Start, report.End, path)
+		}
+		cmdLogger.Infof("%d transactions of ledgers [%d, %d] match %s", report.Transactions, report.Start, report.End, horizonURL)
+	},
+}
+
+// compareHorizon transforms the transactions of a range and compares the operations and effects of the sampled
+// ones with horizon
+func compareHorizon(ctx context.Context, backend ledgerbackend.LedgerBackend, horizon *horizonClient, start, end uint32, networkPassphrase string, options parityOptions) (parityReport, error) {
+	report := parityReport{Start: start, End: end, HorizonURL: horizon.url, Records: map[string]int{}, Samples: []parityDifference{}}
+
+	tables, err := input.SelectLedgerTables(horizonParityTables)
+	if err != nil {
+		return report, err
+	}
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return report, fmt.Errorf("could not prepare range [%d, %d]: %v", start, end, err)
+	}
+
+	for seq := start; seq <= end; seq++ {
+		if options.MaxTransactions > 0 && report.Transactions >= options.MaxTransactions {
+			break
+		}
+
+		lcm, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			return report, fmt.Errorf("error getting ledger seq %d from the backend: %v", seq, err)
+		}
+
+		ledger, err := input.DecodeLedger(lcm, networkPassphrase)
+		if err != nil {
+			return report, fmt.Errorf("could not decode ledger %d: %v", seq, err)
+		}
+
+		rows := map[string][]interface{}{}
+		for _, table := range tables {
+			transformed, err := table.Transform(ledger, networkPassphrase)
+			if err != nil {
+				cmdLogger.WithFields(utils.RowLogFields(table.Name, seq, "")).LogError(fmt.Errorf("could not transform %s in ledger %d: %w", table.Name, seq, err))
+				report.Failures++
+			}
+			rows[table.Name] = transformed
+		}
+
+		operations, effects := groupParityRecords(rows)
+		for _, row := range rows["transactions"] {
+			transaction := row.(transform.TransactionOutput)
+			if !sampleParityTransaction(transaction.TransactionHash, options.SampleRate) {
+				continue
+			}
+			if options.MaxTransactions > 0 && report.Transactions >= options.MaxTransactions {
+				break
+			}
+
+			differences, err := compareParityTransaction(ctx, horizon, transaction.TransactionHash, operations[transaction.TransactionID], effects[transaction.TransactionID], options.Tolerance, &report)
+			if err != nil {
+				return report, err
+			}
+			report.Transactions++
+			report.add(differences)
+		}
+	}
+
+	return report, nil
+}
+
+// groupParityRecords groups the operations and effects of a ledger by the id of their transaction
+func groupParityRecords(rows map[string][]interface{}) (operations map[int64][]transform.OperationOutput, effects map[int64][]transform.EffectOutput) {
+	operations = map[int64][]transform.OperationOutput{}
+	transactionOf := map[int64]int64{}
+	for _, row := range rows["operations"] {
+		operation := row.(transform.OperationOutput)
+		operations[operation.TransactionID] = append(operations[operation.TransactionID], operation)
+		transactionOf[operation.OperationID] = operation.TransactionID
+	}
+
+	effects = map[int64][]transform.EffectOutput{}
+	for _, row := range rows["effects"] {
+		effect := row.(transform.EffectOutput)
+		transactionID, ok := transactionOf[effect.OperationID]
+		if !ok {
+			continue
+		}
+		effects[transactionID] = append(effects[transactionID], effect)
+	}
+	return operations, effects
+}
+
+// sampleParityTransaction deterministically selects a share of the transactions by their hash, so that repeated
+// runs over a range compare the same transactions
+func sampleParityTransaction(hash string, rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte(hash))
+	return float64(h.Sum32()) < rate*float64(math.MaxUint32)
+}
+
+// compareParityTransaction compares the exported operations and effects of a transaction with the ones of horizon
+func compareParityTransaction(ctx context.Context, horizon *horizonClient, hash string, operations []transform.OperationOutput, effects []transform.EffectOutput, tolerance float64, report *parityReport) ([]parityDifference, error) {
+	differences := []parityDifference{}
+
+	exportedOperations := map[string]map[string]interface{}{}
+	for _, operation := range operations {
+		record, err := parityOperationRecord(operation)
+		if err != nil {
+			return nil, fmt.Errorf("could not read operation %d: %v", operation.OperationID, err)
+		}
+		exportedOperations[strconv.FormatInt(operation.OperationID, 10)] = record
+	}
+	horizonOperations, err := horizon.records(ctx, hash, "operations")
+	if err != nil {
+		return nil, err
+	}
+	differences = append(differences, diffParityRecords(hash, "operations", exportedOperations, keyParityRecords(horizonOperations, horizonOperationKey), tolerance, report)...)
+
+	exportedEffects := map[string]map[string]interface{}{}
+	for _, effect := range effects {
+		record, err := parityEffectRecord(effect)
+		if err != nil {
+			return nil, fmt.Errorf("could not read effect %s: %v", effect.EffectId, err)
+		}
+		exportedEffects[effect.EffectId] = record
+	}
+	horizonEffects, err := horizon.records(ctx, hash, "effects")
+	if err != nil {
+		return nil, err
+	}
+	differences = append(differences, diffParityRecords(hash, "effects", exportedEffects, keyParityRecords(horizonEffects, horizonEffectKey), tolerance, report)...)
+
+	return differences, nil
+}
+
+// parityOperationRecord returns an exported operation with the names of the fields of horizon
+func parityOperationRecord(operation transform.OperationOutput) (map[string]interface{}, error) {
+	record, err := parityJSONRecord(operation.OperationDetails)
+	if err != nil {
+		return nil, err
+	}
+	record["id"] = strconv.FormatInt(operation.OperationID, 10)
+	record["source_account"] = operation.SourceAccount
+	record["type"] = operation.TypeString
+	record["type_i"] = json.Number(strconv.Itoa(int(operation.Type)))
+	return record, nil
+}
+
+// parityEffectRecord returns an exported effect with the names of the fields of horizon
+func parityEffectRecord(effect transform.EffectOutput) (map[string]interface{}, error) {
+	record, err := parityJSONRecord(effect.Details)
+	if err != nil {
+		return nil, err
+	}
+	record["account"] = effect.Address
+	record["type"] = effect.TypeString
+	record["type_i"] = json.Number(strconv.Itoa(int(effect.Type)))
+	return record, nil
+}
+
+// parityJSONRecord returns the fields of a details map as horizon would serialize them
+func parityJSONRecord(details map[string]interface{}) (map[string]interface{}, error) {
+	record := map[string]interface{}{}
+	if details == nil {
+		return record, nil
+	}
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(encoded)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// horizonOperationKey is the id of a horizon operation, as exported in the operations table
+func horizonOperationKey(record map[string]interface{}) string {
+	id, _ := record["id"].(string)
+	return id
+}
+
+// horizonEffectKey is the id of a horizon effect as exported in the effects table. The paging token of horizon is
+// <operation id>-<order> with an order starting at 1, while the exported index of the effect starts at 0.
+func horizonEffectKey(record map[string]interface{}) string {
+	token, _ := record["paging_token"].(string)
+	operationID, order, found := strings.Cut(token, "-")
+	if !found {
+		return token
+	}
+	index, err := strconv.Atoi(order)
+	if err != nil {
+		return token
+	}
+	return fmt.Sprintf("%s-%d", operationID, index-1)
+}
+
+func keyParityRecords(records []map[string]interface{}, key func(map[string]interface{}) string) map[string]map[string]interface{} {
+	keyed := map[string]map[string]interface{}{}
+	for _, record := range records {
+		keyed[key(record)] = record
+	}
+	return keyed
+}
+
+// diffParityRecords compares the records of a table by id. The fields that only one side has are not compared,
+// since horizon and the export each carry fields of their own.
+func diffParityRecords(hash, table string, exported, horizon map[string]map[string]interface{}, tolerance float64, report *parityReport) []parityDifference {
+	differences := []parityDifference{}
+	for _, id := range parityKeys(exported, horizon) {
+		exportedRecord, inExport := exported[id]
+		horizonRecord, inHorizon := horizon[id]
+		switch {
+		case !inHorizon:
+			differences = append(differences, parityDifference{Transaction: hash, Table: table, ID: id, ETL: "exported", Horizon: "missing"})
+			continue
+		case !inExport:
+			differences = append(differences, parityDifference{Transaction: hash, Table: table, ID: id, ETL: "missing", Horizon: "present"})
+			continue
+		}
+
+		report.Records[table]++
+		fields := make([]string, 0, len(exportedRecord))
+		for field := range exportedRecord {
+			if _, ok := horizonRecord[field]; ok {
+				fields = append(fields, field)
+			}
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			report.Fields++
+			if !parityEqual(exportedRecord[field], horizonRecord[field], tolerance) {
+				differences = append(differences, parityDifference{
+					Transaction: hash,
+					Table:       table,
+					ID:          id,
+					Field:       field,
+					ETL:         parityString(exportedRecord[field]),
+					Horizon:     parityString(horizonRecord[field]),
+				})
+			}
+		}
+	}
+	return differences
+}
+
+// parityKeys returns the sorted union of the ids of both sides
+func parityKeys(exported, horizon map[string]map[string]interface{}) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, records := range []map[string]map[string]interface{}{exported, horizon} {
+		for key := range records {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parityEqual compares two json values. Numbers and numeric strings are compared by value, so that 10 and
+// "10.0000000" are equal, and are equal if they differ by at most the tolerance.
+func parityEqual(exported, horizon interface{}, tolerance float64) bool {
+	switch exportedValue := exported.(type) {
+	case map[string]interface{}:
+		horizonValue, ok := horizon.(map[string]interface{})
+		if !ok || len(exportedValue) != len(horizonValue) {
+			return false
+		}
+		for key, value := range exportedValue {
+			other, ok := horizonValue[key]
+			if !ok || !parityEqual(value, other, tolerance) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		horizonValue, ok := horizon.([]interface{})
+		if !ok || len(exportedValue) != len(horizonValue) {
+			return false
+		}
+		for i := range exportedValue {
+			if !parityEqual(exportedValue[i], horizonValue[i], tolerance) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if parityString(exported) == parityString(horizon) {
+		return true
+	}
+	exportedNumber, ok := parityNumber(exported)
+	if !ok {
+		return false
+	}
+	horizonNumber, ok := parityNumber(horizon)
+	if !ok {
+		return false
+	}
+	// the tolerance is read from its shortest decimal form, since 0.0000001 is not exact in binary
+	limit, _ := new(big.Rat).SetString(strconv.FormatFloat(tolerance, 'g', -1, 64))
+	difference := new(big.Rat).Sub(exportedNumber, horizonNumber)
+	return difference.Abs(difference).Cmp(limit) <= 0
+}
+
+// parityNumber returns the value of a json number or numeric string
+func parityNumber(value interface{}) (*big.Rat, bool) {
+	var text string
+	switch v := value.(type) {
+	case json.Number:
+		text = v.String()
+	case string:
+		text = v
+	default:
+		return nil, false
+	}
+	return new(big.Rat).SetString(text)
+}
+
+// parityString returns a json value as it is written in the report
+func parityString(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return text
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
+
+func init() {
+	rootCmd.AddCommand(compareHorizonCmd)
+	utils.AddCommonFlags(compareHorizonCmd.Flags())
+	utils.AddArchiveFlags("compare_horizon", compareHorizonCmd.Flags())
+	utils.AddCloudStorageFlags(compareHorizonCmd.Flags())
+	compareHorizonCmd.Flags().Lookup("output").DefValue = "horizon_parity.json"
+	compareHorizonCmd.Flags().Set("output", "horizon_parity.json")
+	compareHorizonCmd.Flags().String("horizon-url", "", "Horizon instance to compare with; the horizon of the network by default")
+	compareHorizonCmd.Flags().Float64("sample-rate", 0.01, "Share of the transactions of the range that are compared, selected by hash")
+	compareHorizonCmd.Flags().Int("max-transactions", 100, "Maximum number of transactions compared; no limit if 0")
+	compareHorizonCmd.Flags().Float64("tolerance", 0, "Largest difference between numeric values that are considered equal")
+	compareHorizonCmd.Flags().Duration("horizon-timeout", 30*time.Second, "Timeout of the requests to horizon")
+	compareHorizonCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the range
+			end-ledger: the ledger sequence number for the end of the range (required)
+
+			output-file: filename of the parity report
+
+			horizon-url: horizon instance to compare with
+			sample-rate: share of the transactions that are compared
+			max-transactions: maximum number of transactions compared
+			tolerance: largest difference between numeric values that are considered equal
+			horizon-timeout: timeout of the requests to horizon
+	*/
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+const horizonTestHash = "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb"
+
+// newHorizonTestServer serves the given operations and effects of horizonTestHash
+func newHorizonTestServer(t *testing.T, operations, effects string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var records string
+		switch r.URL.Path {
+		case "/transactions/" + horizonTestHash + "/operations":
+			assert.Equal(t, "true", r.URL.Query().Get("include_failed"))
+			records = operations
+		case "/transactions/" + horizonTestHash + "/effects":
+			records = effects
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"_embedded": {"records": ` + records + `}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestParityEqual(t *testing.T) {
+	assert.True(t, parityEqual(json.Number("10"), "10.0000000", 0))
+	assert.True(t, parityEqual("GA", "GA", 0))
+	assert.False(t, parityEqual("GA", "GB", 0))
+	assert.False(t, parityEqual(json.Number("1.0000001"), "1.0000000", 0))
+	assert.True(t, parityEqual(json.Number("1.0000001"), "1.0000000", 0.0000001))
+	assert.True(t, parityEqual(true, true, 0))
+	assert.False(t, parityEqual(json.Number("1"), true, 0))
+
+	assert.True(t, parityEqual(
+		[]interface{}{map[string]interface{}{"asset": "native", "amount": json.Number("1.5")}},
+		[]interface{}{map[string]interface{}{"asset": "native", "amount": "1.5000000"}},
+		0,
+	))
+	assert.False(t, parityEqual(
+		map[string]interface{}{"asset": "native"},
+		map[string]interface{}{"asset": "native", "amount": "1.5000000"},
+		0,
+	))
+}
+
+func TestHorizonEffectKey(t *testing.T) {
+	assert.Equal(t, "42949677057-0", horizonEffectKey(map[string]interface{}{"paging_token": "42949677057-1"}))
+	assert.Equal(t, "", horizonEffectKey(map[string]interface{}{}))
+}
+
+func TestSampleParityTransaction(t *testing.T) {
+	assert.True(t, sampleParityTransaction(horizonTestHash, 1))
+	assert.False(t, sampleParityTransaction(horizonTestHash, 0))
+	assert.Equal(t, sampleParityTransaction(horizonTestHash, 0.5), sampleParityTransaction(horizonTestHash, 0.5))
+}
+
+func TestCompareParityTransaction(t *testing.T) {
+	server := newHorizonTestServer(t,
+		`[{"id": "42949677057", "paging_token": "42949677057", "type": "payment", "type_i": 1, "source_account": "GA", "from": "GA", "to": "GB", "amount": "10.0000000", "asset_type": "native"}]`,
+		`[
+			{"id": "0042949677057-0000000001", "paging_token": "42949677057-1", "account": "GB", "type": "account_credited", "type_i": 2, "amount": "10.0000000", "asset_type": "native"},
+			{"id": "0042949677057-0000000002", "paging_token": "42949677057-2", "account": "GA", "type": "account_debited", "type_i": 3, "amount": "1.0000000", "asset_type": "native"},
+			{"id": "0042949677057-0000000003", "paging_token": "42949677057-3", "account": "GC", "type": "account_debited", "type_i": 3, "amount": "1.0000000", "asset_type": "native"}
+		]`,
+	)
+
+	operations := []transform.OperationOutput{{
+		OperationID:   42949677057,
+		TransactionID: 42949677056,
+		SourceAccount: "GA",
+		Type:          1,
+		TypeString:    "payment",
+		OperationDetails: map[string]interface{}{
+			"from": "GA", "to": "GB", "amount": 10.0, "asset_type": "native", "asset_id": int64(-5706705804583548011),
+		},
+	}}
+	effects := []transform.EffectOutput{
+		{EffectId: "42949677057-0", OperationID: 42949677057, Address: "GB", Type: 2, TypeString: "account_credited", Details: map[string]interface{}{"amount": "10.0000000", "asset_type": "native"}},
+		{EffectId: "42949677057-1", OperationID: 42949677057, Address: "GA", Type: 3, TypeString: "account_debited", Details: map[string]interface{}{"amount": "10.0000000", "asset_type": "native"}},
+	}
+
+	report := parityReport{Records: map[string]int{}}
+	differences, err := compareParityTransaction(context.Background(), newHorizonClient(server.URL, time.Second), horizonTestHash, operations, effects, 0, &report)
+	require.NoError(t, err)
+
+	assert.Equal(t, []parityDifference{
+		{Transaction: horizonTestHash, Table: "effects", ID: "42949677057-1", Field: "amount", ETL: "10.0000000", Horizon: "1.0000000"},
+		{Transaction: horizonTestHash, Table: "effects", ID: "42949677057-2", ETL: "missing", Horizon: "present"},
+	}, differences)
+	assert.Equal(t, map[string]int{"operations": 1, "effects": 2}, report.Records)
+	// 8 operation fields and 5 fields of each effect are on both sides, asset_id is only exported
+	assert.Equal(t, 18, report.Fields)
+}
+
+func TestCompareParityTransactionNestedDetails(t *testing.T) {
+	server := newHorizonTestServer(t, `[]`,
+		`[{"paging_token": "42949677057-1", "account": "GA", "type": "liquidity_pool_deposited", "type_i": 92, "reserves_deposited": [{"asset": "native", "amount": "1.5000000"}]}]`,
+	)
+	effects := []transform.EffectOutput{{
+		EffectId: "42949677057-0", OperationID: 42949677057, Address: "GA", Type: 92, TypeString: "liquidity_pool_deposited",
+		Details: map[string]interface{}{"reserves_deposited": []base.AssetAmount{{Asset: "native", Amount: "1.5000000"}}},
+	}}
+
+	report := parityReport{Records: map[string]int{}}
+	differences, err := compareParityTransaction(context.Background(), newHorizonClient(server.URL, time.Second), horizonTestHash, nil, effects, 0, &report)
+	require.NoError(t, err)
+	assert.Equal(t, []parityDifference{}, differences)
+}
+
+func TestHorizonClientErrors(t *testing.T) {
+	server := newHorizonTestServer(t, `[]`, `[]`)
+	_, err := newHorizonClient(server.URL, time.Second).records(context.Background(), "unknown", "effects")
+	assert.EqualError(t, err, "could not get "+server.URL+"/transactions/unknown/effects?limit=200&order=asc: status 404")
+}
+
+func TestParityReportSamples(t *testing.T) {
+	report := parityReport{}
+	report.add(make([]parityDifference, horizonParitySampleSize+5))
+	assert.Equal(t, horizonParitySampleSize+5, report.Differences)
+	assert.Len(t, report.Samples, horizonParitySampleSize)
+}
+
+func TestCompareHorizon(t *testing.T) {
+	server := newHorizonTestServer(t, `[]`, `[]`)
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeDuckDBTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makeDuckDBTestLedger(11), nil)
+
+	report, err := compareHorizon(context.Background(), backend, newHorizonClient(server.URL+"/", time.Second), 10, 11, network.TestNetworkPassphrase, parityOptions{SampleRate: 1})
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.Equal(t, parityReport{Start: 10, End: 11, HorizonURL: server.URL, Records: map[string]int{}, Samples: []parityDifference{}}, report)
+}
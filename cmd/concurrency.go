@@ -0,0 +1,175 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// errLimitReached stops the stages of streamTransformed once the limit of the export is written
+var errLimitReached = errors.New("the limit of the export is reached")
+
+// transformedInput is the output of the transform of an input, set once done is closed
+type transformedInput[Out any] struct {
+	out  Out
+	err  error
+	done chan struct{}
+}
+
+// forEachTransformed transforms the inputs with a pool of concurrency.Workers workers and calls write with each
+// input and its output in the order of the inputs, so that the rows are written in the same order whatever the
+// number of workers. At most concurrency.ReadAhead inputs are transformed ahead of the one being written. Transform
+// errors are handed to write along with the input.
+func forEachTransformed[In, Out any](inputs []In, concurrency utils.ConcurrencyFlagValues, transform func(In) (Out, error), write func(In, Out, error)) {
+	if concurrency.Workers <= 1 {
+		for _, in := range inputs {
+			out, err := transform(in)
+			write(in, out, err)
+		}
+		return
+	}
+
+	toWrite := make(chan *transformedInput[Out], max(concurrency.ReadAhead, 1))
+	go func() {
+		defer close(toWrite)
+		workers := make(chan struct{}, concurrency.Workers)
+		for _, in := range inputs {
+			transformed := &transformedInput[Out]{done: make(chan struct{})}
+			toWrite <- transformed
+			workers <- struct{}{}
+			go func(in In) {
+				defer func() { <-workers }()
+				transformed.out, transformed.err = transform(in)
+				close(transformed.done)
+			}(in)
+		}
+	}()
+
+	i := 0
+	for transformed := range toWrite {
+		<-transformed.done
+		write(inputs[i], transformed.out, transformed.err)
+		i++
+	}
+}
+
+// streamedInput is an input along with the output and error of its transform, as a row of the ledger stages
+type streamedInput[In, Out any] struct {
+	in  In
+	out Out
+	err error
+}
+
+// streamTransformed streams the ledgers from start to the end ledger of the export through input.TransformLedgerRange:
+// the ledgers are read from the backend in order, and concurrency.Workers workers turn them into their inputs, drop
+// the inputs left out by filter, if any, and transform the others. write is called with each input and its output in
+// ledger order, so that the rows are written in the same order whatever the number of workers, and only the ledgers
+// being transformed are held in memory rather than every input of the range. Transform errors are handed to write
+// along with the input.
+//
+// A limit that is not negative is the maximum number of inputs written. The export stops at the input that reaches
+// it, or at the end of its ledger with inputs.WholeLedgers. It returns the number of inputs written and the last
+// ledger whose inputs were all written. Once the context is done, the ledgers written so far are kept.
+func streamTransformed[In, Out any](ctx context.Context, env utils.EnvironmentDetails, start uint32, limit int64, inputs input.LedgerInputs[In], filter func([]In) []In, transform func(In) (Out, error), write func(In, Out, error)) (int, uint32, error) {
+	backend, err := utils.CreateLedgerBackend(ctx, env.CommonFlagValues.UseCaptiveCore, env)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer backend.Close()
+	return streamBackendTransformed(ctx, backend, env, start, limit, inputs, filter, transform, write)
+}
+
+// streamBackendTransformed is streamTransformed over the ledgers of the given backend
+func streamBackendTransformed[In, Out any](ctx context.Context, backend ledgerbackend.LedgerBackend, env utils.EnvironmentDetails, start uint32, limit int64, inputs input.LedgerInputs[In], filter func([]In) []In, transform func(In) (Out, error), write func(In, Out, error)) (int, uint32, error) {
+	commonArgs := env.CommonFlagValues
+	end := commonArgs.EndNum
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		if ctx.Err() != nil {
+			return 0, start - 1, nil
+		}
+		return 0, 0, err
+	}
+
+	table := input.LedgerTable{Reads: inputs.Reads, Transform: func(ledger input.DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+		ledgerInputs := inputs.Build(ledger)
+		if filter != nil {
+			ledgerInputs = filter(ledgerInputs)
+		}
+		rows := make([]interface{}, len(ledgerInputs))
+		for i, in := range ledgerInputs {
+			out, err := transform(in)
+			rows[i] = streamedInput[In, Out]{in: in, out: out, err: err}
+		}
+		return rows, nil
+	}}
+	options := input.StageOptions{
+		ReadAhead:        commonArgs.Concurrency.ReadAhead,
+		TransformWorkers: max(commonArgs.Concurrency.Workers, 1),
+		Network:          env.Network,
+		Sample:           commonArgs.Sample,
+		Toid:             commonArgs.Toid,
+	}
+
+	written := 0
+	lastLedger := start - 1
+	limitReached := func() bool {
+		return limit >= 0 && int64(written) >= limit
+	}
+	err := input.TransformLedgerRange(ctx, backend, start, end, env.NetworkPassphrase, []input.LedgerTable{table}, options, func(ledger input.TransformedLedger) error {
+		for _, row := range ledger.Rows[0] {
+			if !inputs.WholeLedgers && limitReached() {
+				// The ledger is cut partway, so it is not exported in full
+				lastLedger = ledger.Sequence - 1
+				return errLimitReached
+			}
+			streamed := row.(streamedInput[In, Out])
+			write(streamed.in, streamed.out, streamed.err)
+			written++
+		}
+		lastLedger = ledger.Sequence
+		if limitReached() {
+			return errLimitReached
+		}
+		return nil
+	})
+	switch {
+	case err == nil:
+		return written, end, nil
+	case errors.Is(err, errLimitReached), ctx.Err() != nil:
+		return written, lastLedger, nil
+	default:
+		return written, lastLedger, err
+	}
+}
+
+// streamInputs streams the inputs of the ledgers from start to the end ledger of the export like streamTransformed,
+// for the commands that aggregate their inputs in order rather than transform each of them on its own
+func streamInputs[In any](ctx context.Context, env utils.EnvironmentDetails, start uint32, limit int64, inputs input.LedgerInputs[In], filter func([]In) []In, write func(In)) (int, uint32, error) {
+	pass := func(in In) (struct{}, error) {
+		return struct{}{}, nil
+	}
+	return streamTransformed(ctx, env, start, limit, inputs, filter, pass, func(in In, _ struct{}, _ error) {
+		write(in)
+	})
+}
+
+// uploadFiles uploads the output files of an export, concurrency.WriteConcurrency files at a time
+func uploadFiles(concurrency utils.ConcurrencyFlagValues, cloudCredentials, cloudStorageBucket, cloudProvider string, paths []string) {
+	uploads := make(chan struct{}, max(concurrency.WriteConcurrency, 1))
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		uploads <- struct{}{}
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-uploads }()
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		}(path)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestForEachTransformed(t *testing.T) {
+	inputs := make([]int, 100)
+	for i := range inputs {
+		inputs[i] = i
+	}
+	double := func(in int) (int, error) {
+		if in%10 == 0 {
+			return 0, fmt.Errorf("multiple of 10")
+		}
+		return 2 * in, nil
+	}
+
+	for _, concurrency := range []utils.ConcurrencyFlagValues{
+		{Workers: 1, ReadAhead: 1},
+		{Workers: 4, ReadAhead: 8},
+		{Workers: 8, ReadAhead: 1},
+	} {
+		t.Run(fmt.Sprintf("%d workers", concurrency.Workers), func(t *testing.T) {
+			var written []int
+			failures := 0
+			forEachTransformed(inputs, concurrency, double, func(in, out int, err error) {
+				if err != nil {
+					failures++
+					return
+				}
+				assert.Equal(t, 2*in, out)
+				written = append(written, in)
+			})
+
+			assert.Equal(t, 10, failures)
+			assert.Len(t, written, 90)
+			assert.IsIncreasing(t, written)
+		})
+	}
+}
+
+func TestStreamTransformed(t *testing.T) {
+	// every ledger has three inputs, and the inputs of ledger 12 fail to transform
+	threeInputs := input.LedgerInputs[int]{Build: func(ledger input.DecodedLedger) []int {
+		seq := int(ledger.Header.Header.LedgerSeq)
+		return []int{10 * seq, 10*seq + 1, 10*seq + 2}
+	}}
+	double := func(in int) (int, error) {
+		if in/10 == 12 {
+			return 0, fmt.Errorf("ledger 12")
+		}
+		return 2 * in, nil
+	}
+	evenOnly := func(inputs []int) []int {
+		filtered := []int{}
+		for _, in := range inputs {
+			if in%2 == 0 {
+				filtered = append(filtered, in)
+			}
+		}
+		return filtered
+	}
+
+	for _, testCase := range []struct {
+		name         string
+		limit        int64
+		wholeLedgers bool
+		filter       func([]int) []int
+		written      []int
+		lastLedger   uint32
+	}{
+		{name: "no limit", limit: -1, written: []int{100, 101, 102, 110, 111, 112, 130, 131, 132}, lastLedger: 13},
+		{name: "limit within a ledger", limit: 4, written: []int{100, 101, 102, 110}, lastLedger: 10},
+		{name: "limit at the end of a ledger", limit: 6, written: []int{100, 101, 102, 110, 111, 112}, lastLedger: 11},
+		{name: "limit of whole ledgers", limit: 4, wholeLedgers: true, written: []int{100, 101, 102, 110, 111, 112}, lastLedger: 11},
+		{name: "filtered inputs are not counted", limit: 3, filter: evenOnly, written: []int{100, 102, 110}, lastLedger: 10},
+	} {
+		for _, workers := range []int{1, 4} {
+			t.Run(fmt.Sprintf("%s with %d workers", testCase.name, workers), func(t *testing.T) {
+				backend := &ledgerbackend.MockDatabaseBackend{}
+				backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 13)).Return(nil)
+				for seq := uint32(10); seq <= 13; seq++ {
+					backend.On("GetLedger", mock.Anything, seq).Return(makeDuckDBTestLedger(seq), nil).Maybe()
+				}
+				env := utils.EnvironmentDetails{
+					NetworkPassphrase: network.TestNetworkPassphrase,
+					CommonFlagValues:  utils.CommonFlagValues{EndNum: 13, Concurrency: utils.ConcurrencyFlagValues{Workers: workers, ReadAhead: 2}},
+				}
+				inputs := threeInputs
+				inputs.WholeLedgers = testCase.wholeLedgers
+
+				written := []int{}
+				failures := 0
+				numWritten, lastLedger, err := streamBackendTransformed(context.Background(), backend, env, 10, testCase.limit, inputs, testCase.filter, double, func(in, out int, err error) {
+					if err != nil {
+						failures++
+						return
+					}
+					assert.Equal(t, 2*in, out)
+					written = append(written, in)
+				})
+				require.NoError(t, err)
+
+				assert.Equal(t, testCase.written, written)
+				assert.Equal(t, len(written)+failures, numWritten)
+				assert.Equal(t, testCase.lastLedger, lastLedger)
+			})
+		}
+	}
+}
+
+func TestStreamTransformedCancel(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 13)).Return(nil)
+	for seq := uint32(10); seq <= 13; seq++ {
+		backend.On("GetLedger", mock.Anything, seq).Return(makeDuckDBTestLedger(seq), nil).Maybe()
+	}
+	env := utils.EnvironmentDetails{NetworkPassphrase: network.TestNetworkPassphrase, CommonFlagValues: utils.CommonFlagValues{EndNum: 13}}
+
+	// once the context is done, the ledgers written so far are kept
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	written := []uint32{}
+	numWritten, lastLedger, err := streamBackendTransformed(ctx, backend, env, 10, -1, input.LedgerCloseMetaInputs(), nil, func(ledger utils.HistoryArchiveLedgerAndLCM) (uint32, error) {
+		return ledger.LCM.LedgerSequence(), nil
+	}, func(_ utils.HistoryArchiveLedgerAndLCM, seq uint32, _ error) {
+		written = append(written, seq)
+		if seq == 11 {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+	// a ledger already transformed can still be written while the stages stop
+	require.GreaterOrEqual(t, len(written), 2)
+	assert.Equal(t, []uint32{10, 11}, written[:2])
+	assert.Less(t, len(written), 4)
+	assert.Equal(t, len(written), numWritten)
+	assert.Equal(t, written[len(written)-1], lastLedger)
+}
+
+func TestDefaultConcurrency(t *testing.T) {
+	assert.Equal(t, utils.ConcurrencyFlagValues{Workers: 8, ReadAhead: 16, WriteConcurrency: 4}, utils.DefaultConcurrency(utils.ConcurrencyFlagValues{}, false, 8))
+	assert.Equal(t, utils.ConcurrencyFlagValues{Workers: 8, ReadAhead: 8, WriteConcurrency: 4}, utils.DefaultConcurrency(utils.ConcurrencyFlagValues{}, true, 8))
+	assert.Equal(t, utils.ConcurrencyFlagValues{Workers: 2, ReadAhead: 4, WriteConcurrency: 2}, utils.DefaultConcurrency(utils.ConcurrencyFlagValues{Workers: 2}, false, 2))
+	assert.Equal(t, utils.ConcurrencyFlagValues{Workers: 1, ReadAhead: 3, WriteConcurrency: 1}, utils.DefaultConcurrency(utils.ConcurrencyFlagValues{Workers: 1, ReadAhead: 3, WriteConcurrency: 1}, false, 8))
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configSections are the top level config keys that are not flags
+var configSections = map[string]bool{
+	"networks": true,
+}
+
+// applyConfig sets the flags of a command from the loaded config file. Top level keys apply to every command,
+// while a section named after the command only applies to that command. Flags set on the command line
+// always take precedence over the config file, and ${VAR} references are expanded from the environment.
+func applyConfig(cmd *cobra.Command, settings map[string]interface{}) error {
+	if err := validateConfig(cmd.Root(), settings); err != nil {
+		return err
+	}
+
+	values := map[string]interface{}{}
+	for key, value := range settings {
+		if !isCommandName(cmd.Root(), key) {
+			values[key] = value
+		}
+	}
+	if section, ok := settings[cmd.Name()].(map[string]interface{}); ok {
+		for key, value := range section {
+			values[key] = value
+		}
+	}
+
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		value, ok := values[flag.Name]
+		if !ok || flag.Changed || err != nil {
+			return
+		}
+
+		if setErr := cmd.Flags().Set(flag.Name, os.ExpandEnv(configValueToString(value))); setErr != nil {
+			err = fmt.Errorf("invalid value for %s in config file: %v", flag.Name, setErr)
+		}
+	})
+
+	return err
+}
+
+// validateConfig rejects config keys that do not match any flag or command so that typos are not silently ignored
+func validateConfig(root *cobra.Command, settings map[string]interface{}) error {
+	commandFlags := map[string]map[string]bool{}
+	allFlags := map[string]bool{}
+	for _, command := range root.Commands() {
+		flags := map[string]bool{}
+		command.Flags().VisitAll(func(flag *pflag.Flag) {
+			flags[flag.Name] = true
+			allFlags[flag.Name] = true
+		})
+		commandFlags[command.Name()] = flags
+	}
+	root.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
+		allFlags[flag.Name] = true
+	})
+
+	for key, value := range settings {
+		if configSections[key] || allFlags[key] {
+			continue
+		}
+
+		flags, isCommand := commandFlags[key]
+		section, isSection := value.(map[string]interface{})
+		if !isCommand || !isSection {
+			return fmt.Errorf("unknown key %s in config file", key)
+		}
+
+		for sectionKey := range section {
+			if !flags[sectionKey] {
+				return fmt.Errorf("unknown key %s.%s in config file", key, sectionKey)
+			}
+		}
+	}
+
+	return nil
+}
+
+func isCommandName(root *cobra.Command, name string) bool {
+	for _, command := range root.Commands() {
+		if command.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// configValueToString formats a config value the same way it would be passed on the command line
+func configValueToString(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		items := make([]string, 0, len(v))
+		for key, item := range v {
+			items = append(items, fmt.Sprintf("%s=%v", key, item))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// loadConfig applies the config file, if one was found, to the command that is being run
+func loadConfig(cmd *cobra.Command, args []string) error {
+	if viper.ConfigFileUsed() == "" {
+		return nil
+	}
+
+	return applyConfig(cmd, viper.AllSettings())
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newConfigTestCommand() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "stellar-etl"}
+	cmd := &cobra.Command{Use: "export_test"}
+	cmd.Flags().Uint32("end-ledger", 0, "")
+	cmd.Flags().String("output", "exported.txt", "")
+	cmd.Flags().Bool("testnet", false, "")
+	cmd.Flags().StringToString("extra-fields", map[string]string{}, "")
+	root.AddCommand(cmd)
+	return root, cmd
+}
+
+func TestApplyConfig(t *testing.T) {
+	t.Setenv("ETL_TEST_OUTPUT", "from_env.txt")
+
+	_, cmd := newConfigTestCommand()
+	require.NoError(t, cmd.Flags().Parse([]string{"--testnet=false"}))
+
+	settings := map[string]interface{}{
+		"end-ledger": 100,
+		"testnet":    true,
+		"extra-fields": map[string]interface{}{
+			"version": "v2",
+			"batch":   1,
+		},
+		"export_test": map[string]interface{}{
+			"end-ledger": 200,
+			"output":     "${ETL_TEST_OUTPUT}",
+		},
+	}
+	require.NoError(t, applyConfig(cmd, settings))
+
+	endLedger, _ := cmd.Flags().GetUint32("end-ledger")
+	output, _ := cmd.Flags().GetString("output")
+	testnet, _ := cmd.Flags().GetBool("testnet")
+	extra, _ := cmd.Flags().GetStringToString("extra-fields")
+
+	// The command section overrides the top level, and the command line overrides both
+	assert.Equal(t, uint32(200), endLedger)
+	assert.Equal(t, "from_env.txt", output)
+	assert.Equal(t, false, testnet)
+	assert.Equal(t, map[string]string{"version": "v2", "batch": "1"}, extra)
+}
+
+func TestApplyConfigValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		wantErr  string
+	}{
+		{
+			name:     "unknown top level key",
+			settings: map[string]interface{}{"end-ledgr": 100},
+			wantErr:  "unknown key end-ledgr in config file",
+		},
+		{
+			name:     "unknown command key",
+			settings: map[string]interface{}{"export_test": map[string]interface{}{"limit": 10}},
+			wantErr:  "unknown key export_test.limit in config file",
+		},
+		{
+			name:     "invalid value",
+			settings: map[string]interface{}{"end-ledger": "latest"},
+			wantErr:  "invalid value for end-ledger in config file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, cmd := newConfigTestCommand()
+			err := applyConfig(cmd, test.settings)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), test.wantErr)
+		})
+	}
+}
+
+func TestNetworkConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []interface{}
+		want     []utils.NetworkConfig
+		wantErr  string
+	}{
+		{
+			name:     "no networks",
+			networks: nil,
+			want:     nil,
+		},
+		{
+			name: "defaults to the name",
+			networks: []interface{}{
+				map[string]interface{}{"name": "testnet", "start_ledger": 100},
+				map[string]interface{}{"name": "mainnet", "network": "pubnet", "end_ledger": 200, "output_prefix": "prod"},
+			},
+			want: []utils.NetworkConfig{
+				{Name: "testnet", Network: "testnet", StartLedger: 100, OutputPrefix: "testnet"},
+				{Name: "mainnet", Network: "pubnet", EndLedger: 200, OutputPrefix: "prod"},
+			},
+		},
+		{
+			name:     "missing name",
+			networks: []interface{}{map[string]interface{}{"name": "testnet"}, map[string]interface{}{"network": "pubnet"}},
+			wantErr:  "network 1 in the config file has no name",
+		},
+		{
+			name:     "duplicate name",
+			networks: []interface{}{map[string]interface{}{"name": "testnet"}, map[string]interface{}{"name": "testnet", "network": "futurenet"}},
+			wantErr:  "network testnet is configured more than once",
+		},
+		{
+			name:     "malformed section",
+			networks: []interface{}{map[string]interface{}{"name": "testnet", "start_ledger": "latest"}},
+			wantErr:  "could not read networks from config file",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			viper.Set("networks", test.networks)
+			defer viper.Set("networks", nil)
+
+			networks, err := utils.NetworkConfigs()
+			if test.wantErr != "" {
+				assert.ErrorContains(t, err, test.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.want, networks)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// dayOutFiles are the output files of an export. With split set, the rows go to one file per UTC day of the close
+// time of their ledger, so that each file maps to a single date partition however many days the range spans.
+// Otherwise every row goes to the single file at path.
+type dayOutFiles struct {
+	path  string
+	split bool
+	files map[string]*os.File
+}
+
+func newDayOutFiles(path string, split bool) *dayOutFiles {
+	if split && path == utils.StdoutPath {
+		cmdLogger.Fatal("split-by-day cannot be used with stdout")
+	}
+
+	files := &dayOutFiles{path: path, split: split, files: map[string]*os.File{}}
+	if !split {
+		files.files[""] = MustOutFile(path)
+	}
+	return files
+}
+
+// file returns the output file for the rows of a ledger closed at closeTime, creating it on first use
+func (d *dayOutFiles) file(closeTime time.Time) *os.File {
+	if !d.split {
+		return d.files[""]
+	}
+
+	day := closeTime.UTC().Format("2006-01-02")
+	file, ok := d.files[day]
+	if !ok {
+		file = MustOutFile(dayPath(d.path, day))
+		d.files[day] = file
+	}
+	return file
+}
+
+func (d *dayOutFiles) close() {
+	for _, file := range d.files {
+		closeOutFile(file)
+	}
+}
+
+// paths returns the paths of the output files in day order
+func (d *dayOutFiles) paths() []string {
+	if !d.split {
+		return []string{d.path}
+	}
+
+	days := make([]string, 0, len(d.files))
+	for day := range d.files {
+		days = append(days, day)
+	}
+	sort.Strings(days)
+
+	paths := make([]string, len(days))
+	for i, day := range days {
+		paths[i] = dayPath(d.path, day)
+	}
+	return paths
+}
+
+// dayPath inserts the day before the extension of path, such as exported_trades_2024-01-02.txt
+func dayPath(path, day string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "_" + day + ext
+}
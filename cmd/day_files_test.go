@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDayPath(t *testing.T) {
+	assert.Equal(t, "out/exported_trades_2024-01-02.txt", dayPath("out/exported_trades.txt", "2024-01-02"))
+	assert.Equal(t, "out/trades_2024-01-02", dayPath("out/trades", "2024-01-02"))
+}
+
+func TestDayOutFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "exported_trades.txt")
+	files := newDayOutFiles(path, true)
+
+	lateNight := time.Date(2024, 1, 1, 23, 59, 59, 0, time.UTC)
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	// The day is the UTC day, not the day in the zone of the close time
+	_, err := files.file(lateNight).WriteString("a\n")
+	require.NoError(t, err)
+	_, err = files.file(midnight.In(time.FixedZone("UTC-5", -5*3600))).WriteString("b\n")
+	require.NoError(t, err)
+	_, err = files.file(midnight.Add(time.Hour)).WriteString("c\n")
+	require.NoError(t, err)
+	files.close()
+
+	paths := files.paths()
+	require.Equal(t, []string{dayPath(path, "2024-01-01"), dayPath(path, "2024-01-02")}, paths)
+
+	first, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "a\n", string(first))
+	second, err := os.ReadFile(paths[1])
+	require.NoError(t, err)
+	assert.Equal(t, "b\nc\n", string(second))
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	single := newDayOutFiles(path, false)
+	assert.Equal(t, single.file(lateNight), single.file(midnight))
+	single.close()
+	assert.Equal(t, []string{path}, single.paths())
+}
@@ -0,0 +1,421 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// deltaLogDir is the folder of the transaction log of a Delta Lake table
+const deltaLogDir = "_delta_log"
+
+// deltaCommitRetries is the number of times a commit is retried when another writer committed the same version
+const deltaCommitRetries = 5
+
+// deltaMetadataCache holds the metadata of the tables committed to by this process, keyed by table folder, so that
+// the log is only read on the first commit to a table
+var deltaMetadataCache = struct {
+	sync.Mutex
+	tables map[string]*deltaMetaData
+}{tables: map[string]*deltaMetaData{}}
+
+// Actions of the Delta Lake transaction protocol, see https://github.com/delta-io/delta/blob/master/PROTOCOL.md
+type deltaAction struct {
+	CommitInfo *deltaCommitInfo `json:"commitInfo,omitempty"`
+	Protocol   *deltaProtocol   `json:"protocol,omitempty"`
+	MetaData   *deltaMetaData   `json:"metaData,omitempty"`
+	Add        *deltaAdd        `json:"add,omitempty"`
+}
+
+type deltaCommitInfo struct {
+	Timestamp           int64             `json:"timestamp"`
+	Operation           string            `json:"operation"`
+	OperationParameters map[string]string `json:"operationParameters"`
+	EngineInfo          string            `json:"engineInfo"`
+}
+
+type deltaProtocol struct {
+	MinReaderVersion int `json:"minReaderVersion"`
+	MinWriterVersion int `json:"minWriterVersion"`
+}
+
+type deltaFormat struct {
+	Provider string            `json:"provider"`
+	Options  map[string]string `json:"options"`
+}
+
+type deltaMetaData struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name,omitempty"`
+	Format           deltaFormat       `json:"format"`
+	SchemaString     string            `json:"schemaString"`
+	PartitionColumns []string          `json:"partitionColumns"`
+	Configuration    map[string]string `json:"configuration"`
+	CreatedTime      int64             `json:"createdTime"`
+}
+
+type deltaAdd struct {
+	Path             string            `json:"path"`
+	PartitionValues  map[string]string `json:"partitionValues"`
+	Size             int64             `json:"size"`
+	ModificationTime int64             `json:"modificationTime"`
+	DataChange       bool              `json:"dataChange"`
+}
+
+// deltaStruct is the schema of a Delta Lake table, serialized as the schemaString of its metadata
+type deltaStruct struct {
+	Type   string       `json:"type"`
+	Fields []deltaField `json:"fields"`
+}
+
+type deltaField struct {
+	Name     string                 `json:"name"`
+	Type     interface{}            `json:"type"`
+	Nullable bool                   `json:"nullable"`
+	Metadata map[string]interface{} `json:"metadata"`
+}
+
+type deltaArray struct {
+	Type         string      `json:"type"`
+	ElementType  interface{} `json:"elementType"`
+	ContainsNull bool        `json:"containsNull"`
+}
+
+type deltaMap struct {
+	Type              string      `json:"type"`
+	KeyType           interface{} `json:"keyType"`
+	ValueType         interface{} `json:"valueType"`
+	ValueContainsNull bool        `json:"valueContainsNull"`
+}
+
+// MaybeCommitDelta adds a parquet file to the Delta Lake table of an output under root. Nothing is done if root
+// is empty. stellar-etl will log a Fatal error and stop in the case it cannot commit the file.
+func MaybeCommitDelta(root, table, parquetPath string, schema interface{}, start, end uint32) {
+	if root == "" {
+		return
+	}
+
+	version, err := commitDelta(root, table, parquetPath, schema, start, end, time.Now())
+	if err != nil {
+		cmdLogger.Fatalf("could not commit %s to the %s delta table: %v", parquetPath, table, err)
+	}
+	cmdLogger.Infof("Committed %s as version %d of the %s delta table", parquetPath, version, table)
+}
+
+// commitDelta copies a parquet file into the folder of a Delta Lake table and commits it with a new version of
+// the transaction log. The table is created by the first commit, and its schema is updated whenever the parquet
+// schema changes. Commits are atomic: the version file is linked into the log, which fails if another writer
+// committed the same version, in which case the commit is retried with the next version.
+func commitDelta(root, table, parquetPath string, schema interface{}, start, end uint32, now time.Time) (int64, error) {
+	tableDir := filepath.Join(root, table)
+	logDir := filepath.Join(tableDir, deltaLogDir)
+	if err := os.MkdirAll(logDir, os.ModePerm); err != nil {
+		return 0, err
+	}
+
+	schemaString, err := deltaSchemaString(schema)
+	if err != nil {
+		return 0, err
+	}
+
+	dataFile := fmt.Sprintf("part-%d-%d-%s.parquet", start, end, uuid.New().String())
+	size, err := copyFile(parquetPath, filepath.Join(tableDir, dataFile))
+	if err != nil {
+		return 0, err
+	}
+
+	add := &deltaAdd{
+		Path:             dataFile,
+		PartitionValues:  map[string]string{},
+		Size:             size,
+		ModificationTime: now.UnixMilli(),
+		DataChange:       true,
+	}
+	commitInfo := &deltaCommitInfo{
+		Timestamp: now.UnixMilli(),
+		Operation: "WRITE",
+		OperationParameters: map[string]string{
+			"mode":        "Append",
+			"ledgerStart": strconv.FormatUint(uint64(start), 10),
+			"ledgerEnd":   strconv.FormatUint(uint64(end), 10),
+		},
+		EngineInfo: "stellar-etl",
+	}
+
+	deltaMetadataCache.Lock()
+	defer deltaMetadataCache.Unlock()
+
+	for attempt := 0; attempt < deltaCommitRetries; attempt++ {
+		version, err := latestDeltaVersion(logDir)
+		if err != nil {
+			return 0, err
+		}
+
+		metaData, ok := deltaMetadataCache.tables[tableDir]
+		if !ok || attempt > 0 {
+			metaData, err = readDeltaMetaData(logDir, version)
+			if err != nil {
+				return 0, err
+			}
+		}
+
+		actions := []deltaAction{{CommitInfo: commitInfo}}
+		if metaData == nil {
+			metaData = &deltaMetaData{
+				ID:               uuid.New().String(),
+				Name:             table,
+				Format:           deltaFormat{Provider: "parquet", Options: map[string]string{}},
+				PartitionColumns: []string{},
+				Configuration:    map[string]string{},
+				CreatedTime:      now.UnixMilli(),
+			}
+			actions = append(actions, deltaAction{Protocol: &deltaProtocol{MinReaderVersion: 1, MinWriterVersion: 2}})
+		}
+		if metaData.SchemaString != schemaString {
+			updated := *metaData
+			updated.SchemaString = schemaString
+			metaData = &updated
+			actions = append(actions, deltaAction{MetaData: metaData})
+		}
+		actions = append(actions, deltaAction{Add: add})
+
+		err = writeDeltaCommit(logDir, version+1, actions)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		deltaMetadataCache.tables[tableDir] = metaData
+		return version + 1, nil
+	}
+
+	return 0, fmt.Errorf("could not commit after %d attempts, other writers kept committing the same versions", deltaCommitRetries)
+}
+
+// deltaCommitPath returns the path of the log file of a version, which is named by the version padded to 20 digits
+func deltaCommitPath(logDir string, version int64) string {
+	return filepath.Join(logDir, fmt.Sprintf("%020d.json", version))
+}
+
+// latestDeltaVersion returns the latest committed version of a table, or -1 if the table has no commits
+func latestDeltaVersion(logDir string) (int64, error) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return 0, err
+	}
+
+	latest := int64(-1)
+	for _, entry := range entries {
+		name := entry.Name()
+		if len(name) != 25 || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		version, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+
+	return latest, nil
+}
+
+// readDeltaMetaData returns the metadata of a table as of a version, which is the last metaData action of the
+// log, or nil if the table has no commits
+func readDeltaMetaData(logDir string, version int64) (*deltaMetaData, error) {
+	for v := version; v >= 0; v-- {
+		file, err := os.Open(deltaCommitPath(logDir, v))
+		if err != nil {
+			return nil, err
+		}
+
+		var metaData *deltaMetaData
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 1024*1024), 64*1024*1024)
+		for scanner.Scan() {
+			var action deltaAction
+			if err := json.Unmarshal(scanner.Bytes(), &action); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("could not decode version %d of the delta log: %v", v, err)
+			}
+			if action.MetaData != nil {
+				metaData = action.MetaData
+			}
+		}
+		err = scanner.Err()
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if metaData != nil {
+			return metaData, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// writeDeltaCommit writes the actions of a version to a temporary file and links it into the log. The link fails
+// with os.ErrExist if the version was already committed.
+func writeDeltaCommit(logDir string, version int64, actions []deltaAction) error {
+	tmp, err := os.CreateTemp(logDir, ".tmp-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	encoder := json.NewEncoder(tmp)
+	for _, action := range actions {
+		if err := encoder.Encode(action); err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Link(tmp.Name(), deltaCommitPath(logDir, version))
+}
+
+func copyFile(src, dst string) (int64, error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return 0, err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	size, err := io.Copy(out, in)
+	if err != nil {
+		out.Close()
+		return 0, err
+	}
+	return size, out.Close()
+}
+
+// deltaSchemaString returns the Delta Lake schema of a parquet schema struct, built from its parquet tags
+func deltaSchemaString(schema interface{}) (string, error) {
+	schemaType := reflect.TypeOf(schema)
+	if schemaType.Kind() == reflect.Ptr {
+		schemaType = schemaType.Elem()
+	}
+
+	deltaSchema := deltaStruct{Type: "struct", Fields: []deltaField{}}
+	for i := 0; i < schemaType.NumField(); i++ {
+		tag := parseParquetTag(schemaType.Field(i).Tag.Get("parquet"))
+		if len(tag["name"]) == 0 {
+			continue
+		}
+
+		fieldType, err := deltaType(tag)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %v", tag["name"][0], err)
+		}
+		deltaSchema.Fields = append(deltaSchema.Fields, deltaField{
+			Name:     tag["name"][0],
+			Type:     fieldType,
+			Nullable: true,
+			Metadata: map[string]interface{}{},
+		})
+	}
+
+	encoded, err := json.Marshal(deltaSchema)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// parseParquetTag parses a parquet struct tag such as "name=id, type=INT64". Keys can be repeated.
+func parseParquetTag(tag string) map[string][]string {
+	values := map[string][]string{}
+	for _, part := range strings.Split(tag, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if found {
+			values[strings.ToLower(key)] = append(values[strings.ToLower(key)], value)
+		}
+	}
+	return values
+}
+
+// deltaType returns the Delta Lake type of a parquet column
+func deltaType(tag map[string][]string) (interface{}, error) {
+	if len(tag["type"]) == 0 {
+		return nil, errors.New("missing parquet type")
+	}
+
+	var columnType interface{}
+	switch parquetType := tag["type"][0]; parquetType {
+	case "MAP":
+		if slices.Contains(tag["convertedtype"], "LIST") {
+			return deltaArray{Type: "array", ElementType: deltaPrimitiveType(tag["valuetype"], tag["valueconvertedtype"]), ContainsNull: true}, nil
+		}
+		return deltaMap{
+			Type:              "map",
+			KeyType:           deltaPrimitiveType(tag["keytype"], tag["keyconvertedtype"]),
+			ValueType:         deltaPrimitiveType(tag["valuetype"], tag["valueconvertedtype"]),
+			ValueContainsNull: true,
+		}, nil
+	default:
+		columnType = deltaPrimitiveType(tag["type"], tag["convertedtype"])
+		if columnType == "" {
+			return nil, fmt.Errorf("unsupported parquet type %s", parquetType)
+		}
+	}
+
+	if slices.Contains(tag["repetitiontype"], "REPEATED") {
+		return deltaArray{Type: "array", ElementType: columnType, ContainsNull: false}, nil
+	}
+	return columnType, nil
+}
+
+func deltaPrimitiveType(parquetTypes, convertedTypes []string) string {
+	if len(parquetTypes) == 0 {
+		return ""
+	}
+
+	switch parquetTypes[0] {
+	case "BYTE_ARRAY", "STRING":
+		if len(convertedTypes) == 0 || slices.Contains(convertedTypes, "UTF8") {
+			return "string"
+		}
+		return "binary"
+	case "INT64":
+		if slices.Contains(convertedTypes, "TIMESTAMP_MILLIS") || slices.Contains(convertedTypes, "TIMESTAMP_MICROS") {
+			return "timestamp"
+		}
+		return "long"
+	case "INT32":
+		return "integer"
+	case "DOUBLE":
+		return "double"
+	case "FLOAT":
+		return "float"
+	case "BOOLEAN":
+		return "boolean"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+func writeTestParquet(t *testing.T, dir string) string {
+	rows := newParquetRowBuffer(newMemoryBudget(0))
+	defer rows.Close()
+	rows.Append(transform.EffectOutput{Address: "GABC", LedgerSequence: 10}, 10)
+
+	path := filepath.Join(dir, "effects.parquet")
+	WriteParquet(rows, path, new(transform.EffectOutputParquet))
+	return path
+}
+
+func readDeltaCommit(t *testing.T, logDir string, version int64) []deltaAction {
+	file, err := os.Open(deltaCommitPath(logDir, version))
+	require.NoError(t, err)
+	defer file.Close()
+
+	actions := []deltaAction{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var action deltaAction
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &action))
+		actions = append(actions, action)
+	}
+	require.NoError(t, scanner.Err())
+	return actions
+}
+
+func TestCommitDelta(t *testing.T) {
+	root := t.TempDir()
+	parquetPath := writeTestParquet(t, t.TempDir())
+	now := time.Unix(1700000000, 0)
+
+	version, err := commitDelta(root, "effects", parquetPath, new(transform.EffectOutputParquet), 10, 20, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), version)
+
+	logDir := filepath.Join(root, "effects", deltaLogDir)
+	actions := readDeltaCommit(t, logDir, 0)
+	require.Len(t, actions, 4)
+	assert.Equal(t, "WRITE", actions[0].CommitInfo.Operation)
+	assert.Equal(t, "10", actions[0].CommitInfo.OperationParameters["ledgerStart"])
+	assert.Equal(t, "20", actions[0].CommitInfo.OperationParameters["ledgerEnd"])
+	assert.Equal(t, &deltaProtocol{MinReaderVersion: 1, MinWriterVersion: 2}, actions[1].Protocol)
+	require.NotNil(t, actions[2].MetaData)
+	assert.Equal(t, "parquet", actions[2].MetaData.Format.Provider)
+	assert.Equal(t, "effects", actions[2].MetaData.Name)
+
+	add := actions[3].Add
+	require.NotNil(t, add)
+	assert.Regexp(t, `^part-10-20-[0-9a-f-]{36}\.parquet$`, add.Path)
+	assert.True(t, add.DataChange)
+	info, err := os.Stat(filepath.Join(root, "effects", add.Path))
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), add.Size)
+
+	// the next commits only add files while the schema is unchanged
+	version, err = commitDelta(root, "effects", parquetPath, new(transform.EffectOutputParquet), 21, 30, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+	actions = readDeltaCommit(t, logDir, 1)
+	require.Len(t, actions, 2)
+	assert.NotNil(t, actions[0].CommitInfo)
+	assert.NotNil(t, actions[1].Add)
+}
+
+func TestCommitDeltaSchemaChange(t *testing.T) {
+	root := t.TempDir()
+	parquetPath := writeTestParquet(t, t.TempDir())
+	now := time.Unix(1700000000, 0)
+
+	_, err := commitDelta(root, "table", parquetPath, new(transform.EffectOutputParquet), 10, 20, now)
+	require.NoError(t, err)
+	logDir := filepath.Join(root, "table", deltaLogDir)
+	created := readDeltaCommit(t, logDir, 0)[2].MetaData
+
+	// a new writer reads the metadata from the log
+	deltaMetadataCache.Lock()
+	delete(deltaMetadataCache.tables, filepath.Join(root, "table"))
+	deltaMetadataCache.Unlock()
+
+	_, err = commitDelta(root, "table", parquetPath, new(transform.LedgerOutputParquet), 21, 30, now)
+	require.NoError(t, err)
+	actions := readDeltaCommit(t, logDir, 1)
+	require.Len(t, actions, 3)
+	require.NotNil(t, actions[1].MetaData)
+	assert.Equal(t, created.ID, actions[1].MetaData.ID)
+	assert.NotEqual(t, created.SchemaString, actions[1].MetaData.SchemaString)
+}
+
+func TestCommitDeltaConcurrentWriter(t *testing.T) {
+	root := t.TempDir()
+	parquetPath := writeTestParquet(t, t.TempDir())
+	now := time.Unix(1700000000, 0)
+
+	_, err := commitDelta(root, "effects", parquetPath, new(transform.EffectOutputParquet), 10, 20, now)
+	require.NoError(t, err)
+
+	// another writer commits version 1 without this process knowing about it
+	logDir := filepath.Join(root, "effects", deltaLogDir)
+	require.NoError(t, writeDeltaCommit(logDir, 1, []deltaAction{{CommitInfo: &deltaCommitInfo{Operation: "WRITE"}}}))
+	assert.ErrorIs(t, writeDeltaCommit(logDir, 1, nil), os.ErrExist)
+
+	version, err := commitDelta(root, "effects", parquetPath, new(transform.EffectOutputParquet), 21, 30, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+}
+
+func TestDeltaSchemaString(t *testing.T) {
+	schemas := []interface{}{
+		new(transform.LedgerOutputParquet),
+		new(transform.TransactionOutputParquet),
+		new(transform.OperationOutputParquet),
+		new(transform.EffectOutputParquet),
+		new(transform.TradeOutputParquet),
+		new(transform.AssetOutputParquet),
+		new(transform.ContractEventOutputParquet),
+		new(transform.OfferEventOutputParquet),
+		new(transform.ArchivalHistoryOutputParquet),
+		new(transform.AccountOutputParquet),
+		new(transform.AccountDataOutputParquet),
+		new(transform.AccountSignerOutputParquet),
+		new(transform.TrustlineOutputParquet),
+		new(transform.OfferOutputParquet),
+		new(transform.PoolOutputParquet),
+		new(transform.ContractDataOutputParquet),
+		new(transform.ContractCodeOutputParquet),
+		new(transform.ConfigSettingOutputParquet),
+		new(transform.TtlOutputParquet),
+	}
+	for _, schema := range schemas {
+		_, err := deltaSchemaString(schema)
+		assert.NoError(t, err)
+	}
+
+	schemaString, err := deltaSchemaString(new(transform.ConfigSettingOutputParquet))
+	require.NoError(t, err)
+	var schema deltaStruct
+	require.NoError(t, json.Unmarshal([]byte(schemaString), &schema))
+	types := map[string]interface{}{}
+	for _, field := range schema.Fields {
+		types[field.Name] = field.Type
+	}
+	assert.Equal(t, "integer", types["config_setting_id"])
+	assert.Equal(t, "timestamp", types["closed_at"])
+	assert.Equal(t, map[string]interface{}{"type": "array", "elementType": "long", "containsNull": false}, types["bucket_list_size_window"])
+
+	schemaString, err = deltaSchemaString(new(transform.TransactionOutputParquet))
+	require.NoError(t, err)
+	assert.Contains(t, schemaString, `{"name":"extra_signers","type":{"type":"array","elementType":"string","containsNull":true},"nullable":true,"metadata":{}}`)
+}
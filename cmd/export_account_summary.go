@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var accountSummaryCmd = &cobra.Command{
+	Use:   "export_account_summary",
+	Short: "Exports a lifetime summary of the accounts seen over a specified range",
+	Long: `Exports a summary of every account seen over a specified ledger range: the ledgers it was first and last seen in,
+the account that funded its creation, the number of payments it sent and received and its current flags. The summary
+can be continued from the output of an earlier range with previous-summary, so that consecutive ranges maintain a
+lifetime summary of the accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		previousPath, err := cmd.Flags().GetString("previous-summary")
+		if err != nil {
+			cmdLogger.Fatal("could not get previous-summary: ", err)
+		}
+
+		summary := transform.NewAccountSummary()
+		if previousPath != "" {
+			previous, err := readAccountSummary(previousPath)
+			if err != nil {
+				cmdLogger.Fatalf("could not read the previous summary %s: %v", previousPath, err)
+			}
+			summary.Load(previous)
+		}
+
+		numFailures := 0
+		numTransactions, lastLedger, err := streamInputs(readCtx, env, startNum, limit, input.TransactionInputs(), nil, func(transformInput input.LedgerTransformInput) {
+			ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+			if err := summary.AddTransaction(transformInput.Transaction, uint32(ledgerSeq)); err != nil {
+				cmdLogger.WithFields(utils.RowLogFields("account_summary", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not summarize the accounts of transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
+				numFailures += 1
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
+		}
+
+		outFile := MustOutFile(path)
+		totalNumBytes := 0
+		transformedAccounts := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedAccounts.Close()
+		for _, transformed := range summary.Outputs() {
+			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			if err != nil {
+				cmdLogger.WithFields(log.F{utils.LogFieldTable: "account_summary"}).LogError(err)
+				numFailures += 1
+				continue
+			}
+			totalNumBytes += numBytes
+
+			if err := checks.add("account_summary", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
+			if commonArgs.WriteParquet {
+				transformedAccounts.Append(transformed, numBytes)
+			}
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Infof("%d bytes written to %s", totalNumBytes, outFile.Name())
+
+		PrintTransformStats(numTransactions, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedAccounts, parquetPath, new(transform.AccountSummaryOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "account_summary", parquetPath, new(transform.AccountSummaryOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+// readAccountSummary reads the json lines of an earlier account summary export
+func readAccountSummary(path string) ([]transform.AccountSummaryOutput, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	outputs := []transform.AccountSummaryOutput{}
+	decoder := json.NewDecoder(file)
+	for {
+		var output transform.AccountSummaryOutput
+		err := decoder.Decode(&output)
+		if err == io.EOF {
+			return outputs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, output)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(accountSummaryCmd)
+	utils.AddCommonFlags(accountSummaryCmd.Flags())
+	utils.AddOutputFormatFlags(accountSummaryCmd.Flags())
+	utils.AddQualityFlags(accountSummaryCmd.Flags())
+	utils.AddArchiveFlags("account_summary", accountSummaryCmd.Flags())
+	utils.AddCloudStorageFlags(accountSummaryCmd.Flags())
+	accountSummaryCmd.Flags().String("previous-summary", "", "Json output of an earlier range that the summary continues from")
+	accountSummaryCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			limit: maximum number of transactions to read; default to 6,000,000
+				each ledger can have up to 1000 transactions
+				there are 60 new ledgers in a 5 minute period
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			previous-summary: json output of an earlier range that the summary continues from
+
+		TODO: implement extra flags if possible
+			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
+			start and end time as a replacement for start and end sequence numbers
+	*/
+}
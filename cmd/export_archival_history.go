@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var archivalHistoryCmd = &cobra.Command{
+	Use:   "export_archival_history",
+	Short: "Exports the soroban state archival history over a specified range",
+	Long:  "Exports the ttl extensions, restorations and evictions of soroban ledger entries over a specified range to an output file.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		outFile := MustOutFile(path)
+		numFailures := 0
+		totalNumBytes := 0
+		transformedArchivalHistory := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedArchivalHistory.Close()
+		transformArchivalHistory := func(ledger utils.HistoryArchiveLedgerAndLCM) ([]transform.ArchivalHistoryOutput, error) {
+			return transform.TransformArchivalHistory(ledger.LCM, env.NetworkPassphrase, commonArgs.Toid)
+		}
+		numLedgers, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.LedgerCloseMetaInputs(), nil, transformArchivalHistory, func(ledger utils.HistoryArchiveLedgerAndLCM, history []transform.ArchivalHistoryOutput, err error) {
+			if err != nil {
+				cmdLogger.WithFields(utils.RowLogFields("archival_history", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not transform archival history in ledger %d: %w", ledger.LCM.LedgerSequence(), err))
+				numFailures += 1
+				return
+			}
+
+			for _, transformed := range history {
+				numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+				if err != nil {
+					cmdLogger.WithFields(utils.RowLogFields("archival_history", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not export archival history in ledger %d: %w", ledger.LCM.LedgerSequence(), err))
+					numFailures += 1
+					continue
+				}
+				totalNumBytes += numBytes
+
+				if err := checks.add("archival_history", transformed); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
+				if commonArgs.WriteParquet {
+					transformedArchivalHistory.Append(transformed, numBytes)
+				}
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", err)
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+
+		PrintTransformStats(numLedgers, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedArchivalHistory, parquetPath, new(transform.ArchivalHistoryOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "archival_history", parquetPath, new(transform.ArchivalHistoryOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archivalHistoryCmd)
+	utils.AddCommonFlags(archivalHistoryCmd.Flags())
+	utils.AddOutputFormatFlags(archivalHistoryCmd.Flags())
+	utils.AddQualityFlags(archivalHistoryCmd.Flags())
+	utils.AddArchiveFlags("archival_history", archivalHistoryCmd.Flags())
+	utils.AddCloudStorageFlags(archivalHistoryCmd.Flags())
+	archivalHistoryCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			limit: maximum number of ledgers to export
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+	*/
+}
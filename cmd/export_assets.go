@@ -18,76 +18,107 @@ var assetsCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("assets", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
 
 		outFile := MustOutFile(path)
 
-		var paymentOps []input.AssetTransformInput
-		var err error
-
-		if commonArgs.UseCaptiveCore {
-			paymentOps, err = input.GetPaymentOperationsHistoryArchive(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		} else {
-			paymentOps, err = input.GetPaymentOperations(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		}
-		if err != nil {
-			cmdLogger.Fatal("could not read asset: ", err)
-		}
-
 		// With seenIDs, the code doesn't export duplicate assets within a single export. Note that across exports, assets may be duplicated
 		seenIDs := map[int64]bool{}
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedAssets []transform.SchemaParquet
-		for _, transformInput := range paymentOps {
-			transformed, err := transform.TransformAsset(transformInput.Operation, transformInput.OperationIndex, transformInput.TransactionIndex, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta)
+		transformedAssets := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedAssets.Close()
+		transformAsset := func(transformInput input.AssetTransformInput) (transform.AssetOutput, error) {
+			return transform.TransformAsset(transformInput.Operation, transformInput.OperationIndex, transformInput.TransactionIndex, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase, commonArgs.Toid)
+		}
+		writeAsset := func(transformInput input.AssetTransformInput, transformed transform.AssetOutput, err error) {
 			if err != nil {
 				txIndex := transformInput.TransactionIndex
-				cmdLogger.LogError(fmt.Errorf("could not extract asset from operation %d in transaction %d in ledger %d: ", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum))
+				cmdLogger.WithFields(utils.RowLogFields("assets", uint32(transformInput.LedgerSeqNum), "")).LogError(fmt.Errorf("could not extract asset from operation %d in transaction %d in ledger %d: %w", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum, err))
 				numFailures += 1
-				continue
+				return
 			}
 
 			// if we have seen the asset already, do not export it
 			if _, exists := seenIDs[transformed.AssetID]; exists {
-				continue
+				return
 			}
 
 			seenIDs[transformed.AssetID] = true
 			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
 			if err != nil {
-				cmdLogger.LogError(err)
+				cmdLogger.WithFields(utils.RowLogFields("assets", uint32(transformInput.LedgerSeqNum), "")).LogError(err)
 				numFailures += 1
-				continue
+				return
 			}
 			totalNumBytes += numBytes
 
+			if err := checks.add("assets", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
 			if commonArgs.WriteParquet {
-				transformedAssets = append(transformedAssets, transformed)
+				transformedAssets.Append(transformed, numBytes)
+			}
+		}
+
+		// The history archives are read whole ahead of the transforms, while the ledger backends are streamed
+		var numPaymentOps int
+		var lastLedger uint32
+		var err error
+		if commonArgs.UseCaptiveCore {
+			var paymentOps []input.AssetTransformInput
+			paymentOps, lastLedger, err = input.GetPaymentOperationsHistoryArchive(readCtx, startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			if err == nil {
+				forEachTransformed(paymentOps, commonArgs.Concurrency, timeTransform(timer, transformAsset, assetInputType), writeAsset)
+				numPaymentOps = len(paymentOps)
 			}
+		} else {
+			numPaymentOps, lastLedger, err = streamTransformed(readCtx, env, startNum, limit, input.PaymentOperationInputs(), nil, timeTransform(timer, transformAsset, assetInputType), writeAsset)
+		}
+		if err != nil {
+			cmdLogger.Fatal("could not read asset: ", err)
 		}
 
-		outFile.Close()
+		closeOutFile(outFile)
 		cmdLogger.Infof("%d bytes written to %s", totalNumBytes, outFile.Name())
 
-		PrintTransformStats(len(paymentOps), numFailures)
+		PrintTransformStats(numPaymentOps, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
 		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
 
 		if commonArgs.WriteParquet {
 			WriteParquet(transformedAssets, parquetPath, new(transform.AssetOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "assets", parquetPath, new(transform.AssetOutputParquet), startNum, commonArgs.EndNum)
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(assetsCmd)
 	utils.AddCommonFlags(assetsCmd.Flags())
+	utils.AddOutputFormatFlags(assetsCmd.Flags())
+	utils.AddQualityFlags(assetsCmd.Flags())
 	utils.AddArchiveFlags("assets", assetsCmd.Flags())
 	utils.AddCloudStorageFlags(assetsCmd.Flags())
+	utils.AddTransformTimingFlags(assetsCmd.Flags())
 	assetsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -102,6 +133,12 @@ func init() {
 
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/stellar-etl/v2/internal/input"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
@@ -20,59 +21,105 @@ var contractEventsCmd = &cobra.Command{
 
 		// TODO: https://stellarorg.atlassian.net/browse/HUBBLE-386 GetEnvironmentDetails should be refactored
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("contract_events", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		includeFailed := utils.MustIncludeFailedFlags(cmd.Flags(), cmdLogger)
+		sorobanOnly, classicOnly := utils.MustSorobanFilterFlags(cmd.Flags(), cmdLogger)
+		contractIDs, err := transform.ParseContractIDs(utils.MustContractIDFlags(cmd.Flags(), cmdLogger))
+		if err != nil {
+			cmdLogger.Fatal("could not parse contract-ids: ", err)
+		}
+		eventFilters, err := transform.ParseEventFilters(utils.MustEventFilterFlags(cmd.Flags(), cmdLogger))
+		if err != nil {
+			cmdLogger.Fatal("could not parse event-filter: ", err)
+		}
+		eventOptions := transform.ContractEventOptions{ContractIDs: contractIDs, Filters: eventFilters}
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
 
-		transactions, err := input.GetTransactions(cmdArgs.StartNum, cmdArgs.EndNum, cmdArgs.Limit, env, cmdArgs.UseCaptiveCore)
-		if err != nil {
-			cmdLogger.Fatal("could not read transactions: ", err)
+		transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+			return in.Transaction
+		}
+		filter := func(transactions []input.LedgerTransformInput) []input.LedgerTransformInput {
+			transactions = filterFailedTransactions(transactions, includeFailed, transaction)
+			transactions = filterSorobanTransactions(transactions, sorobanOnly, classicOnly, transaction)
+			return filterTransactionContracts(transactions, contractIDs, transaction)
 		}
 
 		outFile := MustOutFile(cmdArgs.Path)
 		numFailures := 0
-		var transformedEvents []transform.SchemaParquet
-		for _, transformInput := range transactions {
-			transformed, err := transform.TransformContractEvent(transformInput.Transaction, transformInput.LedgerHistory)
+		transformedEvents := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedEvents.Close()
+		transformContractEvent := func(transformInput input.LedgerTransformInput) ([]transform.ContractEventOutput, error) {
+			return transform.TransformContractEventWithOptions(transformInput.Transaction, transformInput.LedgerHistory, commonArgs.Toid, eventOptions)
+		}
+		numTransactions, lastLedger, err := streamTransformed(readCtx, env, cmdArgs.StartNum, cmdArgs.Limit, input.TransactionInputs(), filter, timeTransform(timer, transformContractEvent, transactionInputType), func(transformInput input.LedgerTransformInput, transformed []transform.ContractEventOutput, err error) {
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform contract events in transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.WithFields(utils.RowLogFields("contract_events", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform contract events in transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
 				numFailures += 1
-				continue
+				return
 			}
 
 			for _, contractEvent := range transformed {
-				_, err := ExportEntry(contractEvent, outFile, cmdArgs.Extra)
+				numBytes, err := ExportEntry(contractEvent, outFile, commonArgs.Extra)
 				if err != nil {
-					cmdLogger.LogError(fmt.Errorf("could not export contract event: %v", err))
+					cmdLogger.WithFields(utils.RowLogFields("contract_events", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not export contract event: %w", err))
 					numFailures += 1
 					continue
 				}
 
+				if err := checks.add("contract_events", contractEvent); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
 				if commonArgs.WriteParquet {
-					transformedEvents = append(transformedEvents, contractEvent)
+					transformedEvents.Append(contractEvent, numBytes)
 				}
 			}
 
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", err)
 		}
 
-		outFile.Close()
+		closeOutFile(outFile)
+
+		PrintTransformStats(numTransactions, numFailures)
+		timer.printSummary()
 
-		PrintTransformStats(len(transactions), numFailures)
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
 		MaybeUpload(cmdArgs.Credentials, cmdArgs.Bucket, cmdArgs.Provider, cmdArgs.Path)
 
 		if commonArgs.WriteParquet {
 			WriteParquet(transformedEvents, cmdArgs.ParquetPath, new(transform.ContractEventOutputParquet))
+			MaybeCommitDelta(cmdArgs.DeltaTableRoot, "contract_events", cmdArgs.ParquetPath, new(transform.ContractEventOutputParquet), cmdArgs.StartNum, cmdArgs.EndNum)
 			MaybeUpload(cmdArgs.Credentials, cmdArgs.Bucket, cmdArgs.Provider, cmdArgs.ParquetPath)
 		}
 
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(contractEventsCmd)
 	utils.AddCommonFlags(contractEventsCmd.Flags())
+	utils.AddOutputFormatFlags(contractEventsCmd.Flags())
+	utils.AddQualityFlags(contractEventsCmd.Flags())
+	utils.AddIncludeFailedFlags(contractEventsCmd.Flags())
+	utils.AddSorobanFilterFlags(contractEventsCmd.Flags())
+	utils.AddContractIDFlags(contractEventsCmd.Flags())
+	utils.AddEventFilterFlags(contractEventsCmd.Flags())
 	utils.AddArchiveFlags("contract_events", contractEventsCmd.Flags())
 	utils.AddCloudStorageFlags(contractEventsCmd.Flags())
+	utils.AddTransformTimingFlags(contractEventsCmd.Flags())
 
 	contractEventsCmd.MarkFlagRequired("start-ledger")
 	contractEventsCmd.MarkFlagRequired("end-ledger")
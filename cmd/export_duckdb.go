@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	_ "github.com/marcboeker/go-duckdb"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/log"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// duckDBExportStats counts the rows written to each table of a DuckDB export and the transforms that failed
+type duckDBExportStats struct {
+	Rows       map[string]int
+	Attempts   int
+	Failures   int
+	LedgerSeqs int
+	// LastLedger is the last ledger loaded, which is before the end of the range if the export was stopped
+	LastLedger uint32
+}
+
+var exportDuckDBCmd = &cobra.Command{
+	Use:   "export_duckdb",
+	Short: "Exports all tables of a range into a DuckDB database",
+	Long: `Transforms the ledgers of a range and writes every table into a single DuckDB database file, which can be
+queried right away with the duckdb cli or any DuckDB client. The tables have the columns of the json exports, with
+nested records as structs and lists as lists. An existing database at the output path is replaced.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		if path == utils.StdoutPath {
+			cmdLogger.Fatal("a duckdb database cannot be written to stdout")
+		}
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		tableNames, err := cmd.Flags().GetStringSlice("tables")
+		if err != nil {
+			cmdLogger.Fatal("could not get tables: ", err)
+		}
+
+		tables, err := input.SelectLedgerTables(tableNames)
+		if err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		ctx, stop := shutdownContext()
+		defer stop()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		stageOptions := input.StageOptions{ReadAhead: commonArgs.Concurrency.ReadAhead, TransformWorkers: commonArgs.Concurrency.Workers, Network: env.Network, Sample: commonArgs.Sample, Toid: commonArgs.Toid}
+		stats, err := exportDuckDB(ctx, backend, startNum, commonArgs.EndNum, env.NetworkPassphrase, tables, stageOptions, path, commonArgs.Extra)
+		if err != nil {
+			cmdLogger.Fatal("could not export to duckdb: ", err)
+		}
+
+		for _, table := range tables {
+			cmdLogger.WithFields(log.F{"table": table.Name}).Infof("Wrote %d rows", stats.Rows[table.Name])
+		}
+		cmdLogger.WithFields(log.F{utils.LogFieldLedgerStart: startNum, utils.LogFieldLedgerEnd: commonArgs.EndNum}).
+			Infof("Exported %d ledgers to %s", stats.LedgerSeqs, path)
+		PrintTransformStats(stats.Attempts, stats.Failures)
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		recordCheckpoint(ctx, commonArgs, env.Network, stats.LastLedger)
+	},
+}
+
+// exportDuckDB transforms the ledgers of a range and loads the rows of the tables into a new DuckDB database.
+// The rows are staged as json lines in a temporary folder and loaded with read_json, using the column types of
+// the output structs rather than inferring them from the data.
+func exportDuckDB(
+	ctx context.Context,
+	backend ledgerbackend.LedgerBackend,
+	start, end uint32,
+	networkPassphrase string,
+	tables []input.LedgerTable,
+	stageOptions input.StageOptions,
+	path string,
+	extra map[string]string) (duckDBExportStats, error) {
+	stats := duckDBExportStats{Rows: map[string]int{}, LastLedger: start - 1}
+
+	stagingDir, err := os.MkdirTemp("", "stellar-etl-duckdb-")
+	if err != nil {
+		return stats, err
+	}
+	defer os.RemoveAll(stagingDir)
+
+	stagingFiles := make([]*os.File, len(tables))
+	for i, table := range tables {
+		stagingFiles[i], err = os.Create(filepath.Join(stagingDir, table.Name+".json"))
+		if err != nil {
+			return stats, err
+		}
+		defer stagingFiles[i].Close()
+	}
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return stats, fmt.Errorf("could not prepare range [%d, %d]: %v", start, end, err)
+	}
+
+	err = input.TransformLedgerRange(ctx, backend, start, end, networkPassphrase, tables, stageOptions, func(ledger input.TransformedLedger) error {
+		stats.LedgerSeqs++
+		stats.LastLedger = ledger.Sequence
+		for i, table := range tables {
+			stats.Attempts++
+			if err := ledger.Errors[i]; err != nil {
+				cmdLogger.WithFields(utils.RowLogFields(table.Name, ledger.Sequence, "")).LogError(fmt.Errorf("could not transform %s in ledger %d: %w", table.Name, ledger.Sequence, err))
+				stats.Failures++
+				continue
+			}
+
+			for _, row := range ledger.Rows[i] {
+				if _, err := ExportEntry(row, stagingFiles[i], extra); err != nil {
+					cmdLogger.WithFields(utils.RowLogFields(table.Name, ledger.Sequence, "")).LogError(fmt.Errorf("could not export %s row in ledger %d: %w", table.Name, ledger.Sequence, err))
+					stats.Failures++
+					continue
+				}
+				stats.Rows[table.Name]++
+			}
+		}
+		return nil
+	})
+	// Once the context is done, the ledgers written so far are loaded
+	if err != nil && ctx.Err() == nil {
+		return stats, err
+	}
+
+	for _, file := range stagingFiles {
+		if err := file.Close(); err != nil {
+			return stats, err
+		}
+	}
+
+	if err := loadDuckDB(path, tables, stagingFiles, stats.Rows, extra); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// loadDuckDB creates the tables in a new database at path and loads the staged rows into them
+func loadDuckDB(path string, tables []input.LedgerTable, stagingFiles []*os.File, rows map[string]int, extra map[string]string) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	for _, existing := range []string{path, path + ".wal"} {
+		if err := os.Remove(existing); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	db, err := sql.Open("duckdb", path)
+	if err != nil {
+		return fmt.Errorf("could not open %s: %v", path, err)
+	}
+	defer db.Close()
+
+	for i, table := range tables {
+		columns := duckDBColumns(transform.SchemaFields(table.Output), extra)
+
+		definitions := make([]string, len(columns))
+		for j, column := range columns {
+			definitions[j] = duckDBIdentifier(column[0]) + " " + column[1]
+		}
+		create := fmt.Sprintf("CREATE TABLE %s (%s)", duckDBIdentifier(table.Name), strings.Join(definitions, ", "))
+		if _, err := db.Exec(create); err != nil {
+			return fmt.Errorf("could not create table %s: %v", table.Name, err)
+		}
+
+		if rows[table.Name] == 0 {
+			continue
+		}
+
+		readColumns := make([]string, len(columns))
+		for j, column := range columns {
+			readColumns[j] = duckDBString(column[0]) + ": " + duckDBString(column[1])
+		}
+		insert := fmt.Sprintf(
+			"INSERT INTO %s BY NAME SELECT * FROM read_json(%s, format = 'newline_delimited', columns = {%s})",
+			duckDBIdentifier(table.Name),
+			duckDBString(stagingFiles[i].Name()),
+			strings.Join(readColumns, ", "),
+		)
+		if _, err := db.Exec(insert); err != nil {
+			return fmt.Errorf("could not load table %s: %v", table.Name, err)
+		}
+	}
+
+	return db.Close()
+}
+
+// duckDBColumns returns the names and types of the columns of a table, followed by the extra fields
+func duckDBColumns(fields []transform.SchemaField, extra map[string]string) [][2]string {
+	columns := [][2]string{}
+	names := map[string]bool{}
+	for _, field := range fields {
+		columns = append(columns, [2]string{field.Name, duckDBType(field)})
+		names[field.Name] = true
+	}
+
+	extraNames := []string{}
+	for name := range extra {
+		if !names[name] {
+			extraNames = append(extraNames, name)
+		}
+	}
+	sort.Strings(extraNames)
+	for _, name := range extraNames {
+		columns = append(columns, [2]string{name, "VARCHAR"})
+	}
+
+	return columns
+}
+
+// duckDBType returns the DuckDB type of a column. Bytes are kept as the base64 strings of the json exports.
+func duckDBType(field transform.SchemaField) string {
+	var columnType string
+	switch field.Type {
+	case "INTEGER":
+		columnType = "BIGINT"
+	case "FLOAT":
+		columnType = "DOUBLE"
+	case "BOOLEAN":
+		columnType = "BOOLEAN"
+	case "TIMESTAMP":
+		columnType = "TIMESTAMP"
+	case "JSON":
+		columnType = "JSON"
+	case "RECORD":
+		children := make([]string, len(field.Fields))
+		for i, child := range field.Fields {
+			children[i] = duckDBIdentifier(child.Name) + " " + duckDBType(child)
+		}
+		columnType = "STRUCT(" + strings.Join(children, ", ") + ")"
+	default:
+		columnType = "VARCHAR"
+	}
+
+	if field.Repeated {
+		return columnType + "[]"
+	}
+	return columnType
+}
+
+func duckDBIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func duckDBString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func init() {
+	rootCmd.AddCommand(exportDuckDBCmd)
+	utils.AddCommonFlags(exportDuckDBCmd.Flags())
+	utils.AddArchiveFlags("network", exportDuckDBCmd.Flags())
+	utils.AddCloudStorageFlags(exportDuckDBCmd.Flags())
+	exportDuckDBCmd.Flags().Lookup("output").DefValue = "exported_network.duckdb"
+	exportDuckDBCmd.Flags().Set("output", "exported_network.duckdb")
+	exportDuckDBCmd.Flags().StringSlice("tables", nil, "Tables to export, such as transactions or operations; all of them if empty")
+	exportDuckDBCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			output-file: filename of the DuckDB database
+			tables: tables to export; all of them if empty
+			read-ahead: number of ledgers read ahead of the one being written
+			workers: number of ledgers transformed at once
+	*/
+}
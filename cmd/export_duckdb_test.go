@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+func makeDuckDBTestLedger(seq uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:      xdr.StellarValue{CloseTime: xdr.TimePoint(1000 + seq)},
+					LedgerSeq:     xdr.Uint32(seq),
+					LedgerVersion: 22,
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+		},
+	}
+}
+
+func TestExportDuckDB(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeDuckDBTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makeDuckDBTestLedger(11), nil)
+
+	tables := input.LedgerTables()
+
+	path := filepath.Join(t.TempDir(), "out", "network.duckdb")
+	stats, err := exportDuckDB(context.Background(), backend, 10, 11, network.TestNetworkPassphrase, tables, input.StageOptions{TransformWorkers: 2}, path, map[string]string{"batch_id": "b1"})
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.Equal(t, map[string]int{"ledgers": 2}, stats.Rows)
+	assert.Equal(t, 2*len(tables), stats.Attempts)
+	assert.Equal(t, 0, stats.Failures)
+	assert.Equal(t, 2, stats.LedgerSeqs)
+	assert.Equal(t, uint32(11), stats.LastLedger)
+
+	db, err := sql.Open("duckdb", path)
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := db.Query(`SELECT sequence, closed_at, batch_id FROM ledgers ORDER BY sequence`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	sequences := []int64{}
+	for rows.Next() {
+		var sequence int64
+		var closedAt time.Time
+		var batchID string
+		require.NoError(t, rows.Scan(&sequence, &closedAt, &batchID))
+		assert.Equal(t, time.Unix(1000+sequence, 0).UTC(), closedAt.UTC())
+		assert.Equal(t, "b1", batchID)
+		sequences = append(sequences, sequence)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []int64{10, 11}, sequences)
+
+	// tables without rows are created with their columns
+	var count int
+	require.NoError(t, db.QueryRow(`SELECT count(*) FROM transactions`).Scan(&count))
+	assert.Equal(t, 0, count)
+}
+
+func TestDuckDBType(t *testing.T) {
+	assert.Equal(t, "BIGINT", duckDBType(transform.SchemaField{Type: "INTEGER"}))
+	assert.Equal(t, "VARCHAR[]", duckDBType(transform.SchemaField{Type: "STRING", Repeated: true}))
+	assert.Equal(t, `STRUCT("n" BIGINT, "d" DOUBLE)`, duckDBType(transform.SchemaField{
+		Type:   "RECORD",
+		Fields: []transform.SchemaField{{Name: "n", Type: "INTEGER"}, {Name: "d", Type: "FLOAT"}},
+	}))
+}
+
+func TestDuckDBColumnsForEveryTable(t *testing.T) {
+	for _, table := range input.LedgerTables() {
+		for _, column := range duckDBColumns(transform.SchemaFields(table.Output), nil) {
+			assert.False(t, strings.ContainsAny(column[0], `"'`), table.Name)
+			assert.NotEmpty(t, column[1], table.Name)
+		}
+	}
+}
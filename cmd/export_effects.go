@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/stellar-etl/v2/internal/input"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
@@ -18,63 +20,164 @@ var effectsCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("effects", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		includeFailed := utils.MustIncludeFailedFlags(cmd.Flags(), cmdLogger)
+		sorobanOnly, classicOnly := utils.MustSorobanFilterFlags(cmd.Flags(), cmdLogger)
+		operationTypes, err := transform.ParseOperationTypes(utils.MustOperationTypeFlags(cmd.Flags(), cmdLogger))
+		if err != nil {
+			cmdLogger.Fatal("could not parse operation-types: ", err)
+		}
+		contractIDs, err := transform.ParseContractIDs(utils.MustContractIDFlags(cmd.Flags(), cmdLogger))
+		if err != nil {
+			cmdLogger.Fatal("could not parse contract-ids: ", err)
+		}
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		splitByDay := utils.MustSplitFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+		ctx := context.Background()
+		queue := mustQueueSink(ctx, utils.MustQueueFlags(cmd.Flags(), cmdLogger), utils.RetryPolicyFromFlags(commonArgs))
 
-		transactions, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		offerSponsorships, err := cmd.Flags().GetBool("offer-sponsorship-effects")
 		if err != nil {
-			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
+			cmdLogger.Fatal("could not get offer-sponsorship-effects: ", err)
+		}
+		unfilledOffers, err := cmd.Flags().GetBool("unfilled-offer-effects")
+		if err != nil {
+			cmdLogger.Fatal("could not get unfilled-offer-effects: ", err)
+		}
+		zeroFills, err := cmd.Flags().GetBool("zero-fill-effects")
+		if err != nil {
+			cmdLogger.Fatal("could not get zero-fill-effects: ", err)
+		}
+		effectOptions := transform.EffectOptions{OfferSponsorships: offerSponsorships, UnfilledOffers: unfilledOffers, ZeroFills: zeroFills, OperationTypes: operationTypes, ContractIDs: contractIDs}
+
+		wide, err := cmd.Flags().GetBool("wide")
+		if err != nil {
+			cmdLogger.Fatal("could not get wide: ", err)
+		}
+		table := "effects"
+		var parquetSchema interface{} = new(transform.EffectOutputParquet)
+		if wide {
+			table = "effects_wide"
+			parquetSchema = new(transform.EffectWideOutputParquet)
+		}
+
+		transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+			return in.Transaction
+		}
+		filter := func(transactions []input.LedgerTransformInput) []input.LedgerTransformInput {
+			transactions = filterFailedTransactions(transactions, includeFailed, transaction)
+			transactions = filterSorobanTransactions(transactions, sorobanOnly, classicOnly, transaction)
+			transactions = filterTransactionOperationTypes(transactions, operationTypes, transaction)
+			return filterTransactionContracts(transactions, contractIDs, transaction)
 		}
 
-		outFile := MustOutFile(path)
+		outFiles := newDayOutFiles(path, splitByDay)
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedEffects []transform.SchemaParquet
-		for _, transformInput := range transactions {
+		transformedEffects := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedEffects.Close()
+		transformEffects := func(transformInput input.LedgerTransformInput) ([]transform.EffectOutput, error) {
 			LedgerSeq := uint32(transformInput.LedgerHistory.Header.LedgerSeq)
-			effects, err := transform.TransformEffect(transformInput.Transaction, LedgerSeq, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+			return transform.TransformEffectWithOptions(transformInput.Transaction, LedgerSeq, transformInput.LedgerCloseMeta, env.NetworkPassphrase, commonArgs.Toid, effectOptions)
+		}
+		numTransactions, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TransactionInputs(), filter, timeTransform(timer, transformEffects, transactionInputType), func(transformInput input.LedgerTransformInput, effects []transform.EffectOutput, err error) {
+			LedgerSeq := uint32(transformInput.LedgerHistory.Header.LedgerSeq)
+			closeTime, _ := utils.ExtractLedgerCloseTime(transformInput.LedgerHistory)
 			if err != nil {
 				txIndex := transformInput.Transaction.Index
-				cmdLogger.LogError(fmt.Errorf("could not transform transaction %d in ledger %d: %v", txIndex, LedgerSeq, err))
+				cmdLogger.WithFields(utils.RowLogFields(table, LedgerSeq, utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform transaction %d in ledger %d: %w", txIndex, LedgerSeq, err))
 				numFailures += 1
-				continue
+				return
 			}
 
-			for _, transformed := range effects {
-				numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			for _, effect := range effects {
+				var transformed transform.SchemaParquet = effect
+				if wide {
+					transformed, err = transform.TransformWideEffect(effect)
+					if err != nil {
+						cmdLogger.WithFields(utils.RowLogFields(table, LedgerSeq, utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(err)
+						numFailures += 1
+						continue
+					}
+				}
+
+				numBytes, err := ExportEntry(transformed, outFiles.file(closeTime), commonArgs.Extra)
 				if err != nil {
-					cmdLogger.LogError(err)
+					cmdLogger.WithFields(utils.RowLogFields(table, LedgerSeq, utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(err)
 					numFailures += 1
 					continue
 				}
 				totalNumBytes += numBytes
 
+				if err := checks.add(table, transformed); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
+				if err := queue.add(ctx, table, transformed, commonArgs.Extra); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
 				if commonArgs.WriteParquet {
-					transformedEffects = append(transformedEffects, transformed)
+					transformedEffects.Append(transformed, numBytes)
 				}
 			}
+		})
+		if err != nil {
+			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
 		}
 
-		outFile.Close()
+		outFiles.close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(transactions), numFailures)
+		if err := queue.flush(ctx); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		PrintTransformStats(numTransactions, numFailures)
+		timer.printSummary()
 
-		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		uploadFiles(commonArgs.Concurrency, cloudCredentials, cloudStorageBucket, cloudProvider, outFiles.paths())
 
 		if commonArgs.WriteParquet {
-			WriteParquet(transformedEffects, parquetPath, new(transform.EffectOutputParquet))
+			WriteParquet(transformedEffects, parquetPath, parquetSchema)
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, table, parquetPath, parquetSchema, startNum, commonArgs.EndNum)
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(effectsCmd)
 	utils.AddCommonFlags(effectsCmd.Flags())
+	utils.AddOutputFormatFlags(effectsCmd.Flags())
+	utils.AddQualityFlags(effectsCmd.Flags())
+	utils.AddIncludeFailedFlags(effectsCmd.Flags())
+	utils.AddSorobanFilterFlags(effectsCmd.Flags())
+	utils.AddOperationTypeFlags(effectsCmd.Flags())
+	utils.AddContractIDFlags(effectsCmd.Flags())
+	effectsCmd.Flags().Bool("offer-sponsorship-effects", false, "If set, export the sponsorship created, updated and removed effects of offers, which horizon does not have")
+	effectsCmd.Flags().Bool("unfilled-offer-effects", false, "If set, export an offer_created effect for the offers created without crossing any offer or pool, which horizon does not have")
+	effectsCmd.Flags().Bool("zero-fill-effects", false, "If set, export a trade_zero_fill effect for the claims of offers whose amounts were rounded down to zero, which horizon skips")
+	effectsCmd.Flags().Bool("wide", false, "If set, export the most common details of effects as top-level columns instead of in the details object")
 	utils.AddArchiveFlags("effects", effectsCmd.Flags())
 	utils.AddCloudStorageFlags(effectsCmd.Flags())
+	utils.AddSplitFlags(effectsCmd.Flags())
+	utils.AddQueueFlags(effectsCmd.Flags())
+	utils.AddTransformTimingFlags(effectsCmd.Flags())
 	effectsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -89,6 +192,26 @@ func init() {
 
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
+			include-failed: whether rows of failed transactions are exported
+			soroban-only: whether only the rows of Soroban transactions are exported
+			classic-only: whether only the rows of classic transactions are exported
+			operation-types: types of the operations whose effects are exported
+			offer-sponsorship-effects: whether the sponsorship effects of offers are exported
+			unfilled-offer-effects: whether offers created without trading have an offer created effect
+			zero-fill-effects: whether the claims of offers rounded down to zero have a trade zero fill effect
+			wide: whether the common details of effects are exported as top-level columns
+
+			pubsub-topic: Pub/Sub topic every exported row is published to
+			sqs-queue-url: SQS queue every exported row is sent to
+			sqs-region: region of the SQS queue
+			queue-dedupe-keys: add a dedupe key of the ledger, table and sequence of the row to every message
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
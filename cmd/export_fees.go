@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var feesCmd = &cobra.Command{
+	Use:   "export_fees",
+	Short: "Exports the fees of the transactions over a specified range.",
+	Long: `Exports one row per transaction with the fee charged, the refund and whether it was surge priced, from the fee
+events of the unified events stream, over a specified range to an output file.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("fees", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		outFile := MustOutFile(path)
+		numFailures := 0
+		totalNumBytes := 0
+		transformedFees := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedFees.Close()
+		transformFee := func(transformInput input.LedgerTransformInput) (transform.FeeOutput, error) {
+			return transform.TransformFee(transformInput.Transaction, transformInput.LedgerHistory, env.NetworkPassphrase, commonArgs.Toid)
+		}
+		numTransactions, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TransactionInputs(), nil, timeTransform(timer, transformFee, transactionInputType), func(transformInput input.LedgerTransformInput, transformed transform.FeeOutput, err error) {
+			if err != nil {
+				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+				cmdLogger.WithFields(utils.RowLogFields("fees", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform the fees of transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
+				numFailures += 1
+				return
+			}
+
+			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			if err != nil {
+				cmdLogger.WithFields(utils.RowLogFields("fees", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not export fees: %w", err))
+				numFailures += 1
+				return
+			}
+			totalNumBytes += numBytes
+
+			if err := checks.add("fees", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
+			if commonArgs.WriteParquet {
+				transformedFees.Append(transformed, numBytes)
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", err)
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+
+		PrintTransformStats(numTransactions, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedFees, parquetPath, new(transform.FeeOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "fees", parquetPath, new(transform.FeeOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(feesCmd)
+	utils.AddCommonFlags(feesCmd.Flags())
+	utils.AddOutputFormatFlags(feesCmd.Flags())
+	utils.AddQualityFlags(feesCmd.Flags())
+	utils.AddArchiveFlags("fees", feesCmd.Flags())
+	utils.AddCloudStorageFlags(feesCmd.Flags())
+	utils.AddTransformTimingFlags(feesCmd.Flags())
+	feesCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (*required)
+
+			limit: maximum number of transactions to export
+				The current max_tx_set_size is 1000 and there are 60 new ledgers in a 5 minute period:
+					1000*60 = 60000
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
+		TODO: implement extra flags if possible
+			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
+			start and end time as a replacement for start and end sequence numbers
+	*/
+}
@@ -5,11 +5,19 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/log"
 	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/v2/internal/input"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
@@ -18,7 +26,7 @@ import (
 
 var exportLedgerEntryChangesCmd = &cobra.Command{
 	Use:   "export_ledger_entry_changes",
-	Short: "This command exports the changes in accounts, offers, trustlines and liquidity pools.",
+	Short: "This command exports the changes in accounts, account data, offers, trustlines and liquidity pools.",
 	Long: `This command instantiates a stellar-core instance and uses it to export about accounts, offers, trustlines and liquidity pools.
 The information is exported in batches determined by the batch-size flag. Each exported file will include the changes to the
 relevant data type that occurred during that batch.
@@ -32,22 +40,34 @@ be exported.`,
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
 
 		_, configPath, startNum, batchSize, outputFolder, parquetOutputFolder := utils.MustCoreFlags(cmd.Flags(), cmdLogger)
 		exports := utils.MustExportTypeFlags(cmd.Flags(), cmdLogger)
+		templates := utils.MustOutputTemplateFlags(cmd.Flags(), cmdLogger)
+		for table := range templates.Tables {
+			if !slices.Contains(changeTables, table) {
+				cmdLogger.Fatalf("unknown table %s in output-templates", table)
+			}
+		}
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		webhook := newWebhookSink(utils.MustWebhookFlags(cmd.Flags(), cmdLogger))
+		queue := mustQueueSink(context.Background(), utils.MustQueueFlags(cmd.Flags(), cmdLogger), utils.RetryPolicyFromFlags(commonArgs))
+		quality := utils.MustQualityFlags(cmd.Flags(), cmdLogger)
+		// Each batch gets its own checker, but invalid expectations should fail before the first batch
+		mustQualityChecker(quality)
 
-		cmd.Flags()
-
-		err := os.MkdirAll(outputFolder, os.ModePerm)
+		healthAddr, err := cmd.Flags().GetString("health-addr")
 		if err != nil {
-			cmdLogger.Fatalf("unable to mkdir %s: %v", outputFolder, err)
+			cmdLogger.Fatal("could not get health-addr: ", err)
 		}
 
-		err = os.MkdirAll(parquetOutputFolder, os.ModePerm)
+		healthMaxLedgerAge, err := cmd.Flags().GetDuration("health-max-ledger-age")
 		if err != nil {
-			cmdLogger.Fatalf("unable to mkdir %s: %v", parquetOutputFolder, err)
+			cmdLogger.Fatal("could not get health-max-ledger-age: ", err)
 		}
 
 		if batchSize <= 0 {
@@ -73,291 +93,638 @@ be exported.`,
 			cmdLogger.Fatal("stellar-core needs a config file path when exporting ledgers continuously (endNum = 0)")
 		}
 
-		ctx := context.Background()
-		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
-		if err != nil {
-			cmdLogger.Fatal("error creating a cloud storage backend: ", err)
+		// SIGINT and SIGTERM stop the export at the next ledger, after the changes read so far are written out
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		health := serveHealth(healthAddr, healthMaxLedgerAge)
+		// The rows buffered for parquet by every table of every network share the memory budget
+		rowBudget := newRowMemoryBudget(commonArgs.MaxMemory)
+
+		networks := utils.MustNetworkConfigs(cmdLogger)
+		if len(networks) == 0 {
+			mustMakeOutputFolders(outputFolder, parquetOutputFolder)
+			err := exportLedgerEntryChanges(ctx, env, env.Network, startNum, batchSize, outputFolder, parquetOutputFolder, templates, exports, cloudCredentials, cloudStorageBucket, cloudProvider, webhook, queue, quality, rowBudget, cmdLogger, health.network(env.Network))
+			if err != nil {
+				cmdLogger.Fatal(err)
+			}
+			return
 		}
 
-		err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(startNum, commonArgs.EndNum))
-		if err != nil {
-			cmdLogger.Fatal("error preparing ledger range for cloud storage backend: ", err)
+		// Each configured network is streamed concurrently, writing under its own output prefix. A network that
+		// fails stops on its own, and the command fails once the other networks are done.
+		var wg sync.WaitGroup
+		var failed atomic.Int32
+		for _, network := range networks {
+			networkArgs := commonArgs
+			if network.EndLedger != 0 {
+				networkArgs.EndNum = network.EndLedger
+			}
+			if networkArgs.CheckpointFile != "" {
+				networkArgs.CheckpointFile = utils.NetworkCheckpointPath(networkArgs.CheckpointFile, network.Name)
+			}
+			networkEnv, err := utils.GetNetworkEnvironmentDetails(network.Network, networkArgs)
+			if err != nil {
+				cmdLogger.Fatalf("could not configure network %s: %v", network.Name, err)
+			}
+
+			networkStart := startNum
+			if network.StartLedger != 0 {
+				networkStart = network.StartLedger
+			}
+
+			networkOutputFolder := filepath.Join(outputFolder, network.OutputPrefix)
+			networkParquetOutputFolder := filepath.Join(parquetOutputFolder, network.OutputPrefix)
+			mustMakeOutputFolders(networkOutputFolder, networkParquetOutputFolder)
+
+			networkLogger := cmdLogger.WithFields(log.F{utils.LogFieldNetwork: network.Name})
+			networkHealth := health.network(network.Name)
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := exportLedgerEntryChanges(ctx, networkEnv, network.Name, networkStart, batchSize, networkOutputFolder, networkParquetOutputFolder, templates, exports, cloudCredentials, cloudStorageBucket, cloudProvider, webhook, queue, quality, rowBudget, networkLogger, networkHealth)
+				if err != nil {
+					networkLogger.Error(err)
+					failed.Add(1)
+				}
+			}()
 		}
+		wg.Wait()
+		if n := failed.Load(); n > 0 {
+			cmdLogger.Fatalf("%d of %d networks failed", n, len(networks))
+		}
+	},
+}
+
+// changeTables are the tables exported by export_ledger_entry_changes
+var changeTables = []string{
+	"accounts",
+	"signers",
+	"home_domain_history",
+	"inflation_destination_history",
+	"account_flags_history",
+	"claimable_balances",
+	"offers",
+	"account_data",
+	"trustlines",
+	"liquidity_pools",
+	"pool_share_holders",
+	"contract_data",
+	"contract_balances",
+	"contract_code",
+	"config_settings",
+	"ttl",
+}
+
+// mustMakeOutputFolders creates the folders that the exported batches are written to
+func mustMakeOutputFolders(outputFolder, parquetOutputFolder string) {
+	err := os.MkdirAll(outputFolder, os.ModePerm)
+	if err != nil {
+		cmdLogger.Fatalf("unable to mkdir %s: %v", outputFolder, err)
+	}
+
+	err = os.MkdirAll(parquetOutputFolder, os.ModePerm)
+	if err != nil {
+		cmdLogger.Fatalf("unable to mkdir %s: %v", parquetOutputFolder, err)
+	}
+}
 
-		if commonArgs.EndNum == 0 {
-			commonArgs.EndNum = math.MaxInt32
+// exportLedgerEntryChanges streams the changes of a single network in batches and exports the transformed
+// entries of each batch until the end of the range is reached. The metrics of the export are labelled with
+// networkName. It returns the error that stopped the export, so that the other networks keep going.
+func exportLedgerEntryChanges(
+	ctx context.Context,
+	env utils.EnvironmentDetails,
+	networkName string,
+	startNum, batchSize uint32,
+	outputFolder, parquetOutputFolder string,
+	templates utils.OutputTemplates,
+	exports map[string]bool,
+	cloudCredentials, cloudStorageBucket, cloudProvider string,
+	webhook *webhookSink,
+	queue *queueSink,
+	quality utils.QualityFlagValues,
+	rowBudget *memoryBudget,
+	logger *utils.EtlLogger,
+	health *networkHealth) error {
+	endNum := env.CommonFlagValues.EndNum
+	backend, err := prepareChangesBackend(ctx, env, startNum, endNum, logger, health)
+	if err != nil {
+		return err
+	}
+	defer backend.Close()
+
+	if endNum == 0 {
+		endNum = math.MaxInt32
+	}
+
+	// The stream is stopped and drained if the export fails, so that it does not block on the change channel
+	streamCtx, cancelStream := context.WithCancel(ctx)
+	changeChan := make(chan input.ChangeBatch)
+	closeChan := make(chan error, 1)
+	go input.StreamChanges(streamCtx, &backend, startNum, endNum, batchSize, changeChan, closeChan, env, networkName, logger)
+	defer func() {
+		cancelStream()
+		for range changeChan {
 		}
+	}()
 
-		changeChan := make(chan input.ChangeBatch)
-		closeChan := make(chan int)
-		go input.StreamChanges(&backend, startNum, commonArgs.EndNum, batchSize, changeChan, closeChan, env, cmdLogger)
-
-		for {
-			select {
-			case <-closeChan:
-				return
-			case batch, ok := <-changeChan:
-				if !ok {
-					continue
-				}
-				transformedOutputs := map[string][]interface{}{
-					"accounts":           {},
-					"signers":            {},
-					"claimable_balances": {},
-					"offers":             {},
-					"trustlines":         {},
-					"liquidity_pools":    {},
-					"contract_data":      {},
-					"contract_code":      {},
-					"config_settings":    {},
-					"ttl":                {},
-				}
+	lastExported := startNum - 1
+	for {
+		select {
+		case err := <-closeChan:
+			if err != nil {
+				health.readFailed(err)
+				return fmt.Errorf("could not read the changes; changes are exported through ledger %d, resume with --start-ledger %d: %w", lastExported, lastExported+1, err)
+			}
+			if ctx.Err() != nil {
+				logger.Infof("stopped after a shutdown signal; changes are exported through ledger %d, resume with --start-ledger %d", lastExported, lastExported+1)
+			}
+			return nil
+		case batch, ok := <-changeChan:
+			if !ok {
+				continue
+			}
+			batchLogger := logger.WithFields(log.F{
+				utils.LogFieldLedgerStart: batch.BatchStart,
+				utils.LogFieldLedgerEnd:   batch.BatchEnd,
+			})
+			transformStart := time.Now()
+			_, transformSpan := utils.StartSpan(ctx, "transform", utils.LedgerRangeAttributes(batch.BatchStart, batch.BatchEnd)...)
+
+			transformedOutputs := map[string][]interface{}{}
+			for _, table := range changeTables {
+				transformedOutputs[table] = []interface{}{}
+			}
 
-				for entryType, changes := range batch.Changes {
-					switch entryType {
-					case xdr.LedgerEntryTypeAccount:
-						if !exports["export-accounts"] {
+			for entryType, changes := range batch.Changes {
+				switch entryType {
+				case xdr.LedgerEntryTypeAccount:
+					for _, history := range accountHistories {
+						if !exports[history.export] {
 							continue
 						}
 						for i, change := range changes.Changes {
-							if changed, err := change.AccountChangedExceptSigners(); err != nil {
-								cmdLogger.LogError(fmt.Errorf("unable to identify changed accounts: %v", err))
+							row, ok, err := history.transform(change, changes.LedgerHeaders[i])
+							if err != nil {
+								entry, _, _, _ := utils.ExtractEntryFromChange(change)
+								batchLogger.WithFields(utils.RowLogFields(history.table, uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming the %s of account entry last updated at %d: %w", history.table, entry.LastModifiedLedgerSeq, err))
 								continue
-							} else if changed {
-
-								acc, err := transform.TransformAccount(change, changes.LedgerHeaders[i])
-								if err != nil {
-									entry, _, _, _ := utils.ExtractEntryFromChange(change)
-									cmdLogger.LogError(fmt.Errorf("error transforming account entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-									continue
-								}
-								transformedOutputs["accounts"] = append(transformedOutputs["accounts"], acc)
 							}
-							if utils.AccountSignersChanged(change) {
-								signers, err := transform.TransformSigners(change, changes.LedgerHeaders[i])
-								if err != nil {
-									entry, _, _, _ := utils.ExtractEntryFromChange(change)
-									cmdLogger.LogError(fmt.Errorf("error transforming account signers from %d :%s", entry.LastModifiedLedgerSeq, err))
-									continue
-								}
-								for _, s := range signers {
-									transformedOutputs["signers"] = append(transformedOutputs["signers"], s)
-								}
+							if ok {
+								transformedOutputs[history.table] = append(transformedOutputs[history.table], row)
 							}
 						}
-					case xdr.LedgerEntryTypeClaimableBalance:
-						if !exports["export-balances"] {
+					}
+					if !exports["export-accounts"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						if changed, err := change.AccountChangedExceptSigners(); err != nil {
+							batchLogger.WithFields(log.F{utils.LogFieldTable: "accounts"}).LogError(fmt.Errorf("unable to identify changed accounts: %w", err))
 							continue
-						}
-						for i, change := range changes.Changes {
-							balance, err := transform.TransformClaimableBalance(change, changes.LedgerHeaders[i])
+						} else if changed {
+
+							acc, err := transform.TransformAccount(change, changes.LedgerHeaders[i])
 							if err != nil {
 								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming balance entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
+								batchLogger.WithFields(utils.RowLogFields("accounts", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming account entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 								continue
 							}
-							transformedOutputs["claimable_balances"] = append(transformedOutputs["claimable_balances"], balance)
-						}
-					case xdr.LedgerEntryTypeOffer:
-						if !exports["export-offers"] {
-							continue
+							transformedOutputs["accounts"] = append(transformedOutputs["accounts"], acc)
 						}
-						for i, change := range changes.Changes {
-							offer, err := transform.TransformOffer(change, changes.LedgerHeaders[i])
+						if utils.AccountSignersChanged(change) {
+							signers, err := transform.TransformSigners(change, changes.LedgerHeaders[i])
 							if err != nil {
 								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming offer entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
+								batchLogger.WithFields(utils.RowLogFields("signers", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming account signers from %d :%w", entry.LastModifiedLedgerSeq, err))
 								continue
 							}
-							transformedOutputs["offers"] = append(transformedOutputs["offers"], offer)
+							for _, s := range signers {
+								transformedOutputs["signers"] = append(transformedOutputs["signers"], s)
+							}
+						}
+					}
+				case xdr.LedgerEntryTypeClaimableBalance:
+					if !exports["export-balances"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						balance, err := transform.TransformClaimableBalance(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("claimable_balances", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming balance entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
+							continue
 						}
-					case xdr.LedgerEntryTypeTrustline:
-						if !exports["export-trustlines"] {
+						transformedOutputs["claimable_balances"] = append(transformedOutputs["claimable_balances"], balance)
+					}
+				case xdr.LedgerEntryTypeOffer:
+					if !exports["export-offers"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						offer, err := transform.TransformOffer(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("offers", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming offer entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 							continue
 						}
+						transformedOutputs["offers"] = append(transformedOutputs["offers"], offer)
+					}
+				case xdr.LedgerEntryTypeTrustline:
+					if exports["export-pool-share-holders"] {
 						for i, change := range changes.Changes {
-							trust, err := transform.TransformTrustline(change, changes.LedgerHeaders[i])
+							holder, ok, err := transform.TransformPoolShareHolder(change, changes.LedgerHeaders[i])
 							if err != nil {
 								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming trustline entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
+								batchLogger.WithFields(utils.RowLogFields("pool_share_holders", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming pool share trustline entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 								continue
 							}
-							transformedOutputs["trustlines"] = append(transformedOutputs["trustlines"], trust)
+							if ok {
+								transformedOutputs["pool_share_holders"] = append(transformedOutputs["pool_share_holders"], holder)
+							}
 						}
-					case xdr.LedgerEntryTypeLiquidityPool:
-						if !exports["export-pools"] {
+					}
+					if !exports["export-trustlines"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						trust, err := transform.TransformTrustline(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("trustlines", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming trustline entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 							continue
 						}
-						for i, change := range changes.Changes {
-							pool, err := transform.TransformPool(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming liquidity pool entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["liquidity_pools"] = append(transformedOutputs["liquidity_pools"], pool)
+						transformedOutputs["trustlines"] = append(transformedOutputs["trustlines"], trust)
+					}
+				case xdr.LedgerEntryTypeData:
+					if !exports["export-account-data"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						data, err := transform.TransformAccountData(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("account_data", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming account data entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
+							continue
 						}
-					case xdr.LedgerEntryTypeContractData:
-						if !exports["export-contract-data"] {
+						transformedOutputs["account_data"] = append(transformedOutputs["account_data"], data)
+					}
+				case xdr.LedgerEntryTypeLiquidityPool:
+					if !exports["export-pools"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						pool, err := transform.TransformPool(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("liquidity_pools", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming liquidity pool entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 							continue
 						}
+						transformedOutputs["liquidity_pools"] = append(transformedOutputs["liquidity_pools"], pool)
+					}
+				case xdr.LedgerEntryTypeContractData:
+					if exports["export-contract-balances"] {
 						for i, change := range changes.Changes {
-							TransformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
-							contractData, err, _ := TransformContractData.TransformContractData(change, env.NetworkPassphrase, changes.LedgerHeaders[i])
+							balance, ok, err := transform.TransformContractBalance(change, changes.LedgerHeaders[i])
 							if err != nil {
 								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming contract data entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
+								batchLogger.WithFields(utils.RowLogFields("contract_balances", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming contract balance entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 								continue
 							}
-
-							// Empty contract data that has no error is a nonce. Does not need to be recorded
-							if contractData.ContractId == "" {
-								continue
+							if ok {
+								transformedOutputs["contract_balances"] = append(transformedOutputs["contract_balances"], balance)
 							}
-
-							transformedOutputs["contract_data"] = append(transformedOutputs["contract_data"], contractData)
 						}
-					case xdr.LedgerEntryTypeContractCode:
-						if !exports["export-contract-code"] {
+					}
+					if !exports["export-contract-data"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						TransformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
+						contractData, err, _ := TransformContractData.TransformContractData(change, env.NetworkPassphrase, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("contract_data", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming contract data entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 							continue
 						}
-						for i, change := range changes.Changes {
-							contractCode, err := transform.TransformContractCode(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming contract code entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["contract_code"] = append(transformedOutputs["contract_code"], contractCode)
-						}
-					case xdr.LedgerEntryTypeConfigSetting:
-						if !exports["export-config-settings"] {
+
+						// Empty contract data that has no error is a nonce. Does not need to be recorded
+						if contractData.ContractId == "" {
 							continue
 						}
-						for i, change := range changes.Changes {
-							configSettings, err := transform.TransformConfigSetting(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming config settings entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["config_settings"] = append(transformedOutputs["config_settings"], configSettings)
+
+						transformedOutputs["contract_data"] = append(transformedOutputs["contract_data"], contractData)
+					}
+				case xdr.LedgerEntryTypeContractCode:
+					if !exports["export-contract-code"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						contractCode, err := transform.TransformContractCode(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("contract_code", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming contract code entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
+							continue
 						}
-					case xdr.LedgerEntryTypeTtl:
-						if !exports["export-ttl"] {
+						transformedOutputs["contract_code"] = append(transformedOutputs["contract_code"], contractCode)
+					}
+				case xdr.LedgerEntryTypeConfigSetting:
+					if !exports["export-config-settings"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						configSettings, err := transform.TransformConfigSetting(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("config_settings", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming config settings entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
 							continue
 						}
-						for i, change := range changes.Changes {
-							ttl, err := transform.TransformTtl(change, changes.LedgerHeaders[i])
-							if err != nil {
-								entry, _, _, _ := utils.ExtractEntryFromChange(change)
-								cmdLogger.LogError(fmt.Errorf("error transforming ttl entry last updated at %d: %s", entry.LastModifiedLedgerSeq, err))
-								continue
-							}
-							transformedOutputs["ttl"] = append(transformedOutputs["ttl"], ttl)
+						transformedOutputs["config_settings"] = append(transformedOutputs["config_settings"], configSettings)
+					}
+				case xdr.LedgerEntryTypeTtl:
+					if !exports["export-ttl"] {
+						continue
+					}
+					for i, change := range changes.Changes {
+						ttl, err := transform.TransformTtl(change, changes.LedgerHeaders[i])
+						if err != nil {
+							entry, _, _, _ := utils.ExtractEntryFromChange(change)
+							batchLogger.WithFields(utils.RowLogFields("ttl", uint32(entry.LastModifiedLedgerSeq), "")).LogError(fmt.Errorf("error transforming ttl entry last updated at %d: %w", entry.LastModifiedLedgerSeq, err))
+							continue
 						}
+						transformedOutputs["ttl"] = append(transformedOutputs["ttl"], ttl)
 					}
 				}
+			}
+			transformSpan.End()
+			transformDuration := time.Since(transformStart)
+			utils.RecordPhaseDuration(ctx, networkName, "transform", transformDuration)
 
-				err := exportTransformedData(
-					batch.BatchStart,
-					batch.BatchEnd,
-					outputFolder,
-					parquetOutputFolder,
-					transformedOutputs,
-					cloudCredentials,
-					cloudStorageBucket,
-					cloudProvider,
-					commonArgs.Extra,
-					commonArgs.WriteParquet,
-				)
-				if err != nil {
-					cmdLogger.LogError(err)
-					continue
-				}
+			for table, rows := range transformedOutputs {
+				batchLogger.WithFields(log.F{utils.LogFieldTable: table, utils.LogFieldRows: len(rows)}).Debug("transformed table")
+			}
+
+			// Each batch of a streaming export is traced back to its own batch id
+			extra := env.CommonFlagValues.Extra
+			if env.CommonFlagValues.Provenance {
+				extra = utils.WithProvenance(extra, utils.NewBatchID(), time.Now(), env.CommonFlagValues.Toid)
+			}
+
+			// Unbounded exports record how far behind the network each batch is, to tell backfills from real-time data
+			if env.CommonFlagValues.EndNum == 0 {
+				extra = utils.WithIngestionLag(extra, batch.CloseTime, time.Now())
+			}
+
+			// Batches that fail the quality checks are not written, so they are never uploaded
+			if err := checkBatchQuality(quality, outputFolder, batch.BatchStart, batch.BatchEnd, transformedOutputs); err != nil {
+				health.batchRejected(err)
+				batchLogger.LogError(err)
+				continue
+			}
+
+			writeStart := time.Now()
+			_, writeSpan := utils.StartSpan(ctx, "write", utils.LedgerRangeAttributes(batch.BatchStart, batch.BatchEnd)...)
+			err := exportTransformedData(
+				batch.BatchStart,
+				batch.BatchEnd,
+				outputFolder,
+				parquetOutputFolder,
+				templates,
+				env.Network,
+				transformedOutputs,
+				cloudCredentials,
+				cloudStorageBucket,
+				cloudProvider,
+				extra,
+				env.CommonFlagValues.WriteParquet,
+				env.CommonFlagValues.DeltaTableRoot,
+				rowBudget,
+				webhook == nil && queue == nil,
+			)
+			// The batch read before a shutdown signal is still sent
+			if err == nil {
+				err = webhook.send(context.WithoutCancel(ctx), batch.BatchStart, batch.BatchEnd, transformedOutputs, extra)
+			}
+			if err == nil {
+				err = queue.send(context.WithoutCancel(ctx), transformedOutputs, extra)
+			}
+			writeSpan.End()
+			writeDuration := time.Since(writeStart)
+			utils.RecordPhaseDuration(ctx, networkName, "write", writeDuration)
+			health.batchExported(batch.BatchEnd, batch.CloseTime, err)
+			if err != nil && ctx.Err() == nil && !utils.IsRetryable(err) {
+				// Schema and auth errors fail every batch the same way, so the export stops at once
+				return fmt.Errorf("could not write the batch %d-%d, and the error is not retryable; resume with --start-ledger %d: %w", batch.BatchStart, batch.BatchEnd, lastExported+1, err)
 			}
+			if err != nil {
+				batchLogger.LogError(err)
+				continue
+			}
+			lastExported = batch.BatchEnd
+			if err := writeCheckpoint(env.CommonFlagValues.CheckpointFile, networkName, lastExported); err != nil {
+				batchLogger.LogError(err)
+			}
+
+			rowCounts := map[string]int{}
+			for table, rows := range transformedOutputs {
+				rowCounts[table] = len(rows)
+			}
+			utils.RecordExportedBatch(ctx, networkName, batch.BatchStart, batch.BatchEnd, rowCounts)
+
+			batchFields := log.F{
+				"transform_" + utils.LogFieldDurationMs: transformDuration.Milliseconds(),
+				"write_" + utils.LogFieldDurationMs:     writeDuration.Milliseconds(),
+			}
+			if ingestionLag, ok := extra[utils.IngestionLagField]; ok {
+				batchFields[utils.IngestionLagField] = ingestionLag
+			}
+			batchLogger.WithFields(batchFields).Info("exported batch")
 		}
-	},
+	}
+}
+
+// prepareChangesBackend creates the ledger backend of a network and prepares its range. With the health endpoints
+// served, a backend that cannot be prepared is reported by them and tried again every retry-max-wait, so that the
+// orchestrator probing the endpoints decides when to restart the exporter.
+func prepareChangesBackend(
+	ctx context.Context,
+	env utils.EnvironmentDetails,
+	startNum, endNum uint32,
+	logger *utils.EtlLogger,
+	health *networkHealth) (ledgerbackend.LedgerBackend, error) {
+	policy := utils.RetryPolicyFromFlags(env.CommonFlagValues)
+	wait := policy.MaxWait
+	if wait == 0 {
+		wait = policy.BaseWait
+	}
+	if wait < time.Second {
+		wait = time.Second
+	}
+
+	for {
+		backend, err := utils.CreateLedgerBackend(ctx, env.CommonFlagValues.UseCaptiveCore, env)
+		if err != nil {
+			err = fmt.Errorf("error creating a cloud storage backend: %w", err)
+		} else if err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(startNum, endNum)); err != nil {
+			backend.Close()
+			err = fmt.Errorf("error preparing ledger range for cloud storage backend: %w", err)
+		}
+		health.backendReady(err)
+		if err == nil || health == nil {
+			return backend, err
+		}
+
+		logger.Errorf("%v; trying again in %s", err, wait)
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(wait):
+		}
+	}
+}
+
+// accountHistory is a table of the changes of a field of accounts, exported when its export flag is set
+type accountHistory struct {
+	export    string
+	table     string
+	transform func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) (interface{}, bool, error)
+}
+
+var accountHistories = []accountHistory{
+	{export: "export-home-domain-history", table: "home_domain_history", transform: func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) (interface{}, bool, error) {
+		return transform.TransformHomeDomainChange(change, header)
+	}},
+	{export: "export-inflation-destination-history", table: "inflation_destination_history", transform: func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) (interface{}, bool, error) {
+		return transform.TransformInflationDestinationChange(change, header)
+	}},
+	{export: "export-account-flags-history", table: "account_flags_history", transform: func(change ingest.Change, header xdr.LedgerHeaderHistoryEntry) (interface{}, bool, error) {
+		return transform.TransformAccountFlagsChange(change, header)
+	}},
 }
 
 func exportTransformedData(
 	start, end uint32,
 	folderPath string,
 	parquetFolderPath string,
+	templates utils.OutputTemplates,
+	network string,
 	transformedOutput map[string][]interface{},
 	cloudCredentials, cloudStorageBucket, cloudProvider string,
 	extra map[string]string,
-	writeParquet bool) error {
+	writeParquet bool,
+	deltaTableRoot string,
+	rowBudget *memoryBudget,
+	releaseRows bool) error {
 
 	for resource, output := range transformedOutput {
-		// Filenames are typically exclusive of end point. This processor
-		// is different and we have to increment by 1 since the end batch number
-		// is included in this filename.
-		path := filepath.Join(folderPath, exportFilename(start, end+1, resource))
-		parquetPath := filepath.Join(parquetFolderPath, exportParquetFilename(start, end+1, resource))
+		// The changes of a table are written to a single file per batch, its shard 0
+		file := utils.OutputFile{Table: resource, Network: network, Start: start, End: end, Ext: "txt"}
+		path := filepath.Join(folderPath, templates.Filename(file))
+		file.Ext = "parquet"
+		parquetPath := filepath.Join(parquetFolderPath, templates.Filename(file))
 		outFile := MustOutFile(path)
-		var transformedResource []transform.SchemaParquet
+		transformedResource := newParquetRowBuffer(rowBudget)
 		var parquetSchema interface{}
 		var skip bool
-		for _, o := range output {
-			_, err := ExportEntry(o, outFile, extra)
+		for i, o := range output {
+			numBytes, err := ExportEntry(o, outFile, extra)
 			if err != nil {
 				return err
 			}
+			// With a memory budget, the rows that no sink sends anymore are dropped once written, so that the rows
+			// spilled to disk are freed from memory too
+			if releaseRows && rowBudget.limited() {
+				output[i] = nil
+			}
 
 			if writeParquet {
 				switch v := o.(type) {
 				case transform.AccountOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.AccountOutputParquet)
 					skip = false
+				case transform.AccountDataOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.AccountDataOutputParquet)
+					skip = false
 				case transform.AccountSignerOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.AccountSignerOutputParquet)
 					skip = false
+				case transform.HomeDomainOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.HomeDomainOutputParquet)
+					skip = false
+				case transform.InflationDestinationOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.InflationDestinationOutputParquet)
+					skip = false
+				case transform.AccountFlagsOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.AccountFlagsOutputParquet)
+					skip = false
 				case transform.ClaimableBalanceOutput:
 					// Skipping ClaimableBalanceOutputParquet because it is not needed in the current scope of work
 					// Note that ClaimableBalanceOutputParquet uses nested structs that will need to be handled
 					// for parquet conversion
 					skip = true
 				case transform.ConfigSettingOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.ConfigSettingOutputParquet)
 					skip = false
 				case transform.ContractCodeOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.ContractCodeOutputParquet)
 					skip = false
 				case transform.ContractDataOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.ContractDataOutputParquet)
 					skip = false
+				case transform.ContractBalanceOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.ContractBalanceOutputParquet)
+					skip = false
 				case transform.PoolOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.PoolOutputParquet)
 					skip = false
+				case transform.PoolShareHolderOutput:
+					transformedResource.Append(v, numBytes)
+					parquetSchema = new(transform.PoolShareHolderOutputParquet)
+					skip = false
 				case transform.OfferOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.OfferOutputParquet)
 					skip = false
 				case transform.TrustlineOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.TrustlineOutputParquet)
 					skip = false
 				case transform.TtlOutput:
-					transformedResource = append(transformedResource, v)
+					transformedResource.Append(v, numBytes)
 					parquetSchema = new(transform.TtlOutputParquet)
 					skip = false
 				}
 			}
 		}
 
+		closeOutFile(outFile)
 		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
 
 		if !skip && writeParquet {
 			WriteParquet(transformedResource, parquetPath, parquetSchema)
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+
+			if deltaTableRoot != "" && parquetSchema != nil {
+				if _, err := commitDelta(deltaTableRoot, resource, parquetPath, parquetSchema, start, end, time.Now()); err != nil {
+					transformedResource.Close()
+					return fmt.Errorf("could not commit %s to the %s delta table: %v", parquetPath, resource, err)
+				}
+			}
 		}
+		transformedResource.Close()
 	}
 
 	return nil
@@ -366,9 +733,16 @@ func exportTransformedData(
 func init() {
 	rootCmd.AddCommand(exportLedgerEntryChangesCmd)
 	utils.AddCommonFlags(exportLedgerEntryChangesCmd.Flags())
+	utils.AddOutputFormatFlags(exportLedgerEntryChangesCmd.Flags())
 	utils.AddCoreFlags(exportLedgerEntryChangesCmd.Flags(), "changes_output/")
+	utils.AddOutputTemplateFlags(exportLedgerEntryChangesCmd.Flags())
 	utils.AddExportTypeFlags(exportLedgerEntryChangesCmd.Flags())
 	utils.AddCloudStorageFlags(exportLedgerEntryChangesCmd.Flags())
+	utils.AddWebhookFlags(exportLedgerEntryChangesCmd.Flags())
+	utils.AddQueueFlags(exportLedgerEntryChangesCmd.Flags())
+	utils.AddQualityFlags(exportLedgerEntryChangesCmd.Flags())
+	exportLedgerEntryChangesCmd.Flags().String("health-addr", "", "If set, serve /healthz and /readyz on this address, e.g. :8080")
+	exportLedgerEntryChangesCmd.Flags().Duration("health-max-ledger-age", 15*time.Minute, "Age of the last exported ledger, without any batch exported meanwhile, after which /healthz reports the export as unhealthy")
 
 	exportLedgerEntryChangesCmd.MarkFlagRequired("start-ledger")
 	/*
@@ -377,16 +751,43 @@ func init() {
 			end-ledger: the ledger sequence number for the end of the export range
 
 			output-folder: folder that will contain the output files
+			output-template: template of the names of the files of each table under the output folders
+			output-templates: templates of specific tables
 			limit: maximum number of changes to export in a given batch; if negative then everything gets exported
 			batch-size: size of the export batches
 
 			core-executable: path to stellar-core executable
 			core-config: path to stellar-core config file
 
+			health-addr: address to serve the /healthz and /readyz endpoints on; disabled if empty
+			health-max-ledger-age: age of the last exported ledger, without any batch exported meanwhile, after which the export is unhealthy
+
+			webhook-url: endpoint the exported rows are posted to; disabled if empty
+			webhook-routes: endpoints of specific tables
+			webhook-secret: secret used to sign the webhook requests
+			webhook-batch-size: maximum number of rows in a webhook request
+			webhook-retries: number of times a failed webhook request is retried
+			webhook-timeout: timeout of a webhook request
+
+			pubsub-topic: Pub/Sub topic every exported row is published to
+			sqs-queue-url: SQS queue every exported row is sent to
+			sqs-region: region of the SQS queue
+			queue-dedupe-keys: add a dedupe key of the ledger, table and sequence of the row to every message
+
+			quality-checks: data quality checks to run on the rows of each batch
+			quality-mode: whether failed quality checks warn or skip the batch
+			quality-report: name of the json report of the quality checks, written next to the files of each batch
+
 			If none of the export_X flags are set, assume everything should be exported
 				export_accounts: boolean flag; if set then accounts should be exported
+				export_home_domain_history: boolean flag; if set then the changes of the home domains of accounts should be exported
+				export_inflation_destination_history: boolean flag; if set then the changes of the inflation destinations of accounts should be exported
+				export_account_flags_history: boolean flag; if set then the changes of the auth flags of accounts should be exported
 				export_trustlines: boolean flag; if set then trustlines should be exported
+				export_pool_share_holders: boolean flag; if set then liquidity pool share trustlines should be exported
 				export_offers: boolean flag; if set then offers should be exported
+				export_account_data: boolean flag; if set then account data entries should be exported
+				export_contract_balances: boolean flag; if set then Stellar Asset Contract balances should be exported
 
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
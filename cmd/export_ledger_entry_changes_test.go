@@ -2,7 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
 )
 
 const coreExecutablePath = "../stellar-core/src/stellar-core"
@@ -99,3 +107,72 @@ func TestExportChanges(t *testing.T) {
 		RunCLITest(t, test, "testdata/changes/", "", false)
 	}
 }
+
+func TestValidateOutputTemplate(t *testing.T) {
+	valid := []string{utils.DefaultOutputTemplate, "{table}/{network}/{start}-{end}-{shard}.{ext}", "dt={start}/{table}.{ext}"}
+	for _, template := range valid {
+		assert.NoError(t, utils.ValidateOutputTemplate(template, true), template)
+	}
+	assert.NoError(t, utils.ValidateOutputTemplate("accounts/{end}.{ext}", false))
+
+	invalid := map[string]bool{
+		"":                           true,
+		"{table}.{ext}":              true,
+		"accounts/{end}.{ext}":       true,
+		"{table}/{start}-{date}.txt": true,
+		"{table}/{start.txt":         true,
+		"{table}/start}.txt":         true,
+		"{table}/{{start}}.txt":      true,
+		"/data/{table}/{start}.txt":  true,
+		"../{table}/{start}.txt":     true,
+		"{table}//{start}.txt":       true,
+		"{table}/{start}/":           true,
+	}
+	for template, shared := range invalid {
+		assert.Error(t, utils.ValidateOutputTemplate(template, shared), template)
+	}
+}
+
+func TestExportTransformedDataOutputTemplates(t *testing.T) {
+	templates := utils.OutputTemplates{
+		Default: "{table}/{network}/{start}-{end}-{shard}.{ext}",
+		Tables:  map[string]string{"ttl": "expirations/{end}.{ext}"},
+	}
+	assert.Equal(t, "100-163-accounts.txt", utils.OutputTemplates{Default: utils.DefaultOutputTemplate}.Filename(utils.OutputFile{Table: "accounts", Start: 100, End: 163, Ext: "txt"}))
+
+	dir := t.TempDir()
+	err := exportTransformedData(100, 163, dir, dir, templates, "testnet", map[string][]interface{}{
+		"offers": {transform.OfferOutput{SellerID: "GA"}},
+		"ttl":    {transform.TtlOutput{KeyHash: "abc"}},
+	}, "", "", "", nil, false, "", newMemoryBudget(0), true)
+	require.NoError(t, err)
+
+	for _, path := range []string{"offers/testnet/100-163-00000.txt", "expirations/163.txt"} {
+		contents, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(path)))
+		require.NoError(t, err, path)
+		assert.NotEmpty(t, contents, path)
+	}
+}
+
+func TestExportTransformedDataReleasesRows(t *testing.T) {
+	templates := utils.OutputTemplates{Default: utils.DefaultOutputTemplate}
+	newOutputs := func() map[string][]interface{} {
+		return map[string][]interface{}{
+			"offers": {transform.OfferOutput{SellerID: "GA", OfferID: 1}, transform.OfferOutput{SellerID: "GB", OfferID: 2}},
+		}
+	}
+
+	// with a budget of 1 byte every row is spilled, and dropped from the batch once written
+	dir := t.TempDir()
+	outputs := newOutputs()
+	require.NoError(t, exportTransformedData(100, 163, dir, dir, templates, "testnet", outputs, "", "", "", nil, true, "", newMemoryBudget(1), true))
+	assert.Equal(t, []interface{}{nil, nil}, outputs["offers"])
+	info, err := os.Stat(filepath.Join(dir, "100-163-offers.parquet"))
+	require.NoError(t, err)
+	assert.NotZero(t, info.Size())
+
+	// the rows are kept while the sinks still send them
+	outputs = newOutputs()
+	require.NoError(t, exportTransformedData(100, 163, t.TempDir(), t.TempDir(), templates, "testnet", outputs, "", "", "", nil, true, "", newMemoryBudget(1), false))
+	assert.Equal(t, newOutputs(), outputs)
+}
@@ -18,50 +18,71 @@ var ledgerTransactionCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("ledger_transaction", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
 		startNum, path, _, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
-
-		ledgerTransaction, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		if err != nil {
-			cmdLogger.Fatal("could not read ledger_transaction: ", err)
-		}
+		readCtx, stop := shutdownContext()
+		defer stop()
 
 		outFile := MustOutFile(path)
 		numFailures := 0
 		totalNumBytes := 0
-		for _, transformInput := range ledgerTransaction {
-			transformed, err := transform.TransformLedgerTransaction(transformInput.Transaction, transformInput.LedgerHistory)
+		transformLedgerTransaction := func(transformInput input.LedgerTransformInput) (transform.LedgerTransactionOutput, error) {
+			return transform.TransformLedgerTransaction(transformInput.Transaction, transformInput.LedgerHistory)
+		}
+		numLedgerTransaction, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TransactionInputs(), nil, timeTransform(timer, transformLedgerTransaction, transactionInputType), func(transformInput input.LedgerTransformInput, transformed transform.LedgerTransactionOutput, err error) {
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform ledger_transaction transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.WithFields(utils.RowLogFields("ledger_transaction", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform ledger_transaction transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
 				numFailures += 1
-				continue
+				return
 			}
 
 			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export transaction: %v", err))
+				cmdLogger.WithFields(utils.RowLogFields("ledger_transaction", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not export transaction: %w", err))
 				numFailures += 1
-				continue
+				return
 			}
 			totalNumBytes += numBytes
+
+			if err := checks.add("ledger_transaction", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read ledger_transaction: ", err)
 		}
 
-		outFile.Close()
+		closeOutFile(outFile)
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(ledgerTransaction), numFailures)
+		PrintTransformStats(numLedgerTransaction, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
 		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(ledgerTransactionCmd)
 	utils.AddCommonFlags(ledgerTransactionCmd.Flags())
+	utils.AddOutputFormatFlags(ledgerTransactionCmd.Flags())
+	utils.AddQualityFlags(ledgerTransactionCmd.Flags())
 	utils.AddArchiveFlags("ledger_transaction", ledgerTransactionCmd.Flags())
 	utils.AddCloudStorageFlags(ledgerTransactionCmd.Flags())
+	utils.AddTransformTimingFlags(ledgerTransactionCmd.Flags())
 	ledgerTransactionCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -76,6 +97,12 @@ func init() {
 
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
@@ -18,67 +18,98 @@ var ledgersCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		splitByDay := utils.MustSplitFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
 
-		var ledgers []utils.HistoryArchiveLedgerAndLCM
-		var err error
-
-		if commonArgs.UseCaptiveCore {
-			ledgers, err = input.GetLedgersHistoryArchive(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		} else {
-			ledgers, err = input.GetLedgers(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		}
-		if err != nil {
-			cmdLogger.Fatal("could not read ledgers: ", err)
-		}
-
-		outFile := MustOutFile(path)
+		outFiles := newDayOutFiles(path, splitByDay)
 
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedLedgers []transform.SchemaParquet
-		for i, ledger := range ledgers {
-			transformed, err := transform.TransformLedger(ledger.Ledger, ledger.LCM)
+		transformedLedgers := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedLedgers.Close()
+		transformLedger := func(ledger utils.HistoryArchiveLedgerAndLCM) (transform.LedgerOutput, error) {
+			return transform.TransformLedger(ledger.Ledger, ledger.LCM, commonArgs.Toid)
+		}
+		writeLedger := func(ledger utils.HistoryArchiveLedgerAndLCM, transformed transform.LedgerOutput, err error) {
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not json transform ledger %d: %s", startNum+uint32(i), err))
+				cmdLogger.WithFields(utils.RowLogFields("ledgers", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not json transform ledger %d: %w", ledger.LCM.LedgerSequence(), err))
 				numFailures += 1
-				continue
+				return
 			}
 
-			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			closeTime, _ := utils.GetCloseTime(ledger.LCM)
+			numBytes, err := ExportEntry(transformed, outFiles.file(closeTime), commonArgs.Extra)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export ledger %d: %s", startNum+uint32(i), err))
+				cmdLogger.WithFields(utils.RowLogFields("ledgers", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not export ledger %d: %w", ledger.LCM.LedgerSequence(), err))
 				numFailures += 1
-				continue
+				return
 			}
 			totalNumBytes += numBytes
 
+			if err := checks.add("ledgers", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
 			if commonArgs.WriteParquet {
-				transformedLedgers = append(transformedLedgers, transformed)
+				transformedLedgers.Append(transformed, numBytes)
 			}
 		}
 
-		outFile.Close()
+		// The history archives are read whole ahead of the transforms, while the ledger backends are streamed
+		var numLedgers int
+		var lastLedger uint32
+		var err error
+		if commonArgs.UseCaptiveCore {
+			var ledgers []utils.HistoryArchiveLedgerAndLCM
+			ledgers, lastLedger, err = input.GetLedgersHistoryArchive(readCtx, startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+			if err == nil {
+				forEachTransformed(ledgers, commonArgs.Concurrency, transformLedger, writeLedger)
+				numLedgers = len(ledgers)
+			}
+		} else {
+			numLedgers, lastLedger, err = streamTransformed(readCtx, env, startNum, limit, input.LedgerCloseMetaInputs(), nil, transformLedger, writeLedger)
+		}
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", err)
+		}
+
+		outFiles.close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(ledgers), numFailures)
+		PrintTransformStats(numLedgers, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
-		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		uploadFiles(commonArgs.Concurrency, cloudCredentials, cloudStorageBucket, cloudProvider, outFiles.paths())
 
 		if commonArgs.WriteParquet {
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 			WriteParquet(transformedLedgers, parquetPath, new(transform.LedgerOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "ledgers", parquetPath, new(transform.LedgerOutputParquet), startNum, commonArgs.EndNum)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(ledgersCmd)
 	utils.AddCommonFlags(ledgersCmd.Flags())
+	utils.AddOutputFormatFlags(ledgersCmd.Flags())
+	utils.AddQualityFlags(ledgersCmd.Flags())
 	utils.AddArchiveFlags("ledgers", ledgersCmd.Flags())
 	utils.AddCloudStorageFlags(ledgersCmd.Flags())
+	utils.AddSplitFlags(ledgersCmd.Flags())
 	ledgersCmd.MarkFlagRequired("end-ledger")
 	/*
 		Current flags:
@@ -88,6 +119,10 @@ func init() {
 			limit: maximum number of ledgers to export; default to 60 (1 ledger per 5 seconds over our 5 minute update period)
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
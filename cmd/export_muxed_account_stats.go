@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var muxedAccountStatsCmd = &cobra.Command{
+	Use:   "export_muxed_account_stats",
+	Short: "Exports the payment counts and volumes of the muxed accounts over a specified range",
+	Long: `Exports the number and volume of the payments sent and received by every muxed account over a specified ledger
+range, per base account, muxed id and asset. The payments are the credit and debit effects of the muxed accounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		numFailures := 0
+		stats := transform.NewMuxedAccountStats(env.NetworkPassphrase)
+		numTransactions, lastLedger, err := streamInputs(readCtx, env, startNum, limit, input.TransactionInputs(), nil, func(transformInput input.LedgerTransformInput) {
+			if err := stats.AddTransaction(transformInput.Transaction, transformInput.LedgerCloseMeta); err != nil {
+				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+				cmdLogger.WithFields(utils.RowLogFields("muxed_account_stats", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not collect the muxed account payments of transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
+				numFailures += 1
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
+		}
+
+		outFile := MustOutFile(path)
+		totalNumBytes := 0
+		transformedStats := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedStats.Close()
+		for _, transformed := range stats.Outputs() {
+			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			if err != nil {
+				cmdLogger.WithFields(log.F{utils.LogFieldTable: "muxed_account_stats"}).LogError(err)
+				numFailures += 1
+				continue
+			}
+			totalNumBytes += numBytes
+
+			if err := checks.add("muxed_account_stats", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
+			if commonArgs.WriteParquet {
+				transformedStats.Append(transformed, numBytes)
+			}
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Infof("%d bytes written to %s", totalNumBytes, outFile.Name())
+
+		PrintTransformStats(numTransactions, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedStats, parquetPath, new(transform.MuxedAccountStatsOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "muxed_account_stats", parquetPath, new(transform.MuxedAccountStatsOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(muxedAccountStatsCmd)
+	utils.AddCommonFlags(muxedAccountStatsCmd.Flags())
+	utils.AddOutputFormatFlags(muxedAccountStatsCmd.Flags())
+	utils.AddQualityFlags(muxedAccountStatsCmd.Flags())
+	utils.AddArchiveFlags("muxed_account_stats", muxedAccountStatsCmd.Flags())
+	utils.AddCloudStorageFlags(muxedAccountStatsCmd.Flags())
+	muxedAccountStatsCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			limit: maximum number of transactions to read; default to 6,000,000
+				each ledger can have up to 1000 transactions
+				there are 60 new ledgers in a 5 minute period
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+		TODO: implement extra flags if possible
+			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
+			start and end time as a replacement for start and end sequence numbers
+	*/
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var networkUpgradesCmd = &cobra.Command{
+	Use:   "export_network_upgrades",
+	Short: "Exports the network upgrades over a specified range",
+	Long: `Exports the upgrades of the protocol version, base fee, base reserve, max tx set size, ledger flags and soroban
+config settings applied over a specified range to an output file, with the values before and after each upgrade.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		// The ledger before the range is read as well, since its header has the values replaced by the upgrades of
+		// the first ledger of the range
+		readStart := startNum
+		if readStart > 1 {
+			readStart -= 1
+			if limit >= 0 {
+				limit += 1
+			}
+		}
+
+		outFile := MustOutFile(path)
+		numLedgers := 0
+		numFailures := 0
+		totalNumBytes := 0
+		transformedUpgrades := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedUpgrades.Close()
+		var previousHeader *xdr.LedgerHeader
+		_, lastLedger, err := streamInputs(readCtx, env, readStart, limit, input.LedgerCloseMetaInputs(), nil, func(ledger utils.HistoryArchiveLedgerAndLCM) {
+			header := ledger.LCM.LedgerHeaderHistoryEntry().Header
+			ledgerSeq := uint32(header.LedgerSeq)
+			// Ledgers left out by sampling leave a gap, so the previous values are unknown
+			if previousHeader != nil && uint32(previousHeader.LedgerSeq)+1 != ledgerSeq {
+				previousHeader = nil
+			}
+			if ledgerSeq < startNum {
+				previousHeader = &header
+				return
+			}
+			numLedgers += 1
+
+			upgrades, err := transform.TransformNetworkUpgrades(ledger.LCM, previousHeader)
+			previousHeader = &header
+			if err != nil {
+				cmdLogger.WithFields(utils.RowLogFields("network_upgrades", ledgerSeq, "")).LogError(fmt.Errorf("could not transform network upgrades in ledger %d: %w", ledgerSeq, err))
+				numFailures += 1
+				return
+			}
+
+			for _, transformed := range upgrades {
+				numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+				if err != nil {
+					cmdLogger.WithFields(utils.RowLogFields("network_upgrades", ledgerSeq, "")).LogError(fmt.Errorf("could not export network upgrades in ledger %d: %w", ledgerSeq, err))
+					numFailures += 1
+					continue
+				}
+				totalNumBytes += numBytes
+
+				if err := checks.add("network_upgrades", transformed); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
+				if commonArgs.WriteParquet {
+					transformedUpgrades.Append(transformed, numBytes)
+				}
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", err)
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+
+		PrintTransformStats(numLedgers, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedUpgrades, parquetPath, new(transform.NetworkUpgradeOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "network_upgrades", parquetPath, new(transform.NetworkUpgradeOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(networkUpgradesCmd)
+	utils.AddCommonFlags(networkUpgradesCmd.Flags())
+	utils.AddOutputFormatFlags(networkUpgradesCmd.Flags())
+	utils.AddQualityFlags(networkUpgradesCmd.Flags())
+	utils.AddArchiveFlags("network_upgrades", networkUpgradesCmd.Flags())
+	utils.AddCloudStorageFlags(networkUpgradesCmd.Flags())
+	networkUpgradesCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			limit: maximum number of ledgers to export
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+	*/
+}
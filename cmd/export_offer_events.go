@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var offerEventsCmd = &cobra.Command{
+	Use:   "export_offer_events",
+	Short: "Exports the offer lifecycle events over a specified range",
+	Long:  "Exports every offer creation, update and removal over a specified range to an output file, along with the cause of the change.",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("offer_events", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+
+		outFile := MustOutFile(path)
+		numFailures := 0
+		totalNumBytes := 0
+		transformedOfferEvents := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedOfferEvents.Close()
+		transformOfferEvent := func(transformInput input.LedgerTransformInput) ([]transform.OfferEventOutput, error) {
+			return transform.TransformOfferEvent(transformInput.Transaction, transformInput.LedgerHistory, commonArgs.Toid)
+		}
+		numTransactions, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TransactionInputs(), nil, timeTransform(timer, transformOfferEvent, transactionInputType), func(transformInput input.LedgerTransformInput, offerEvents []transform.OfferEventOutput, err error) {
+			if err != nil {
+				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
+				cmdLogger.WithFields(utils.RowLogFields("offer_events", uint32(ledgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform offer events in transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
+				numFailures += 1
+				return
+			}
+
+			for _, transformed := range offerEvents {
+				numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+				if err != nil {
+					cmdLogger.WithFields(utils.RowLogFields("offer_events", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(err)
+					numFailures += 1
+					continue
+				}
+				totalNumBytes += numBytes
+
+				if err := checks.add("offer_events", transformed); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
+				if commonArgs.WriteParquet {
+					transformedOfferEvents.Append(transformed, numBytes)
+				}
+			}
+		})
+		if err != nil {
+			cmdLogger.Fatalf("could not read transactions in [%d, %d] (limit=%d): %v", startNum, commonArgs.EndNum, limit, err)
+		}
+
+		closeOutFile(outFile)
+		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
+
+		PrintTransformStats(numTransactions, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		if commonArgs.WriteParquet {
+			WriteParquet(transformedOfferEvents, parquetPath, new(transform.OfferEventOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "offer_events", parquetPath, new(transform.OfferEventOutputParquet), startNum, commonArgs.EndNum)
+			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
+		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(offerEventsCmd)
+	utils.AddCommonFlags(offerEventsCmd.Flags())
+	utils.AddOutputFormatFlags(offerEventsCmd.Flags())
+	utils.AddQualityFlags(offerEventsCmd.Flags())
+	utils.AddArchiveFlags("offer_events", offerEventsCmd.Flags())
+	utils.AddCloudStorageFlags(offerEventsCmd.Flags())
+	utils.AddTransformTimingFlags(offerEventsCmd.Flags())
+	offerEventsCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the export period
+			end-ledger: the ledger sequence number for the end of the export range (required)
+
+			limit: maximum number of transactions to export
+
+			output-file: filename of the output file
+
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+	*/
+}
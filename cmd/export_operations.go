@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/v2/internal/input"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
@@ -18,60 +21,124 @@ var operationsCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("operations", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		includeFailed := utils.MustIncludeFailedFlags(cmd.Flags(), cmdLogger)
+		sorobanOnly, classicOnly := utils.MustSorobanFilterFlags(cmd.Flags(), cmdLogger)
+		operationTypes, err := transform.ParseOperationTypes(utils.MustOperationTypeFlags(cmd.Flags(), cmdLogger))
+		if err != nil {
+			cmdLogger.Fatal("could not parse operation-types: ", err)
+		}
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		splitByDay := utils.MustSplitFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
+		ctx := context.Background()
+		queue := mustQueueSink(ctx, utils.MustQueueFlags(cmd.Flags(), cmdLogger), utils.RetryPolicyFromFlags(commonArgs))
 
-		operations, err := input.GetOperations(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		claimedOffers, err := cmd.Flags().GetBool("claimed-offers")
 		if err != nil {
-			cmdLogger.Fatal("could not read operations: ", err)
+			cmdLogger.Fatal("could not get claimed-offers: ", err)
+		}
+		operationOptions := transform.OperationOptions{ClaimedOffers: claimedOffers}
+
+		transaction := func(in input.OperationTransformInput) ingest.LedgerTransaction {
+			return in.Transaction
+		}
+		filter := func(operations []input.OperationTransformInput) []input.OperationTransformInput {
+			operations = filterFailedTransactions(operations, includeFailed, transaction)
+			operations = filterSorobanTransactions(operations, sorobanOnly, classicOnly, transaction)
+			return filterOperationTypes(operations, operationTypes, func(in input.OperationTransformInput) xdr.Operation {
+				return in.Operation
+			})
 		}
 
-		outFile := MustOutFile(path)
+		outFiles := newDayOutFiles(path, splitByDay)
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedOps []transform.SchemaParquet
-		for _, transformInput := range operations {
-			transformed, err := transform.TransformOperation(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase)
+		transformedOps := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedOps.Close()
+		transformOperation := func(transformInput input.OperationTransformInput) (transform.OperationOutput, error) {
+			return transform.TransformOperationWithOptions(transformInput.Operation, transformInput.OperationIndex, transformInput.Transaction, transformInput.LedgerSeqNum, transformInput.LedgerCloseMeta, env.NetworkPassphrase, commonArgs.Toid, operationOptions)
+		}
+		numOperations, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.OperationInputs(), filter, timeTransform(timer, transformOperation, operationInputType), func(transformInput input.OperationTransformInput, transformed transform.OperationOutput, err error) {
 			if err != nil {
 				txIndex := transformInput.Transaction.Index
-				cmdLogger.LogError(fmt.Errorf("could not transform operation %d in transaction %d in ledger %d: %v", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum, err))
+				cmdLogger.WithFields(utils.RowLogFields("operations", uint32(transformInput.LedgerSeqNum), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform operation %d in transaction %d in ledger %d: %w", transformInput.OperationIndex, txIndex, transformInput.LedgerSeqNum, err))
 				numFailures += 1
-				continue
+				return
 			}
 
-			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			closeTime, _ := utils.GetCloseTime(transformInput.LedgerCloseMeta)
+			numBytes, err := ExportEntry(transformed, outFiles.file(closeTime), commonArgs.Extra)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export operation: %v", err))
+				cmdLogger.WithFields(utils.RowLogFields("operations", uint32(transformInput.LedgerSeqNum), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not export operation: %w", err))
 				numFailures += 1
-				continue
+				return
 			}
 			totalNumBytes += numBytes
 
+			if err := checks.add("operations", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
+			if err := queue.add(ctx, "operations", transformed, commonArgs.Extra); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
 			if commonArgs.WriteParquet {
-				transformedOps = append(transformedOps, transformed)
+				transformedOps.Append(transformed, numBytes)
 			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read operations: ", err)
 		}
 
-		outFile.Close()
+		outFiles.close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(operations), numFailures)
+		if err := queue.flush(ctx); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		PrintTransformStats(numOperations, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
-		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		uploadFiles(commonArgs.Concurrency, cloudCredentials, cloudStorageBucket, cloudProvider, outFiles.paths())
 
 		if commonArgs.WriteParquet {
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 			WriteParquet(transformedOps, parquetPath, new(transform.OperationOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "operations", parquetPath, new(transform.OperationOutputParquet), startNum, commonArgs.EndNum)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(operationsCmd)
 	utils.AddCommonFlags(operationsCmd.Flags())
+	utils.AddOutputFormatFlags(operationsCmd.Flags())
+	utils.AddQualityFlags(operationsCmd.Flags())
+	utils.AddIncludeFailedFlags(operationsCmd.Flags())
+	utils.AddSorobanFilterFlags(operationsCmd.Flags())
+	utils.AddOperationTypeFlags(operationsCmd.Flags())
 	utils.AddArchiveFlags("operations", operationsCmd.Flags())
 	utils.AddCloudStorageFlags(operationsCmd.Flags())
+	utils.AddSplitFlags(operationsCmd.Flags())
+	utils.AddQueueFlags(operationsCmd.Flags())
+	utils.AddTransformTimingFlags(operationsCmd.Flags())
+	operationsCmd.Flags().Bool("claimed-offers", false, "If set, add the offers claimed by manage offer operations and the offer they left on the book to their details")
 	operationsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -86,6 +153,23 @@ func init() {
 
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
+			include-failed: whether rows of failed transactions are exported
+			soroban-only: whether only the rows of Soroban transactions are exported
+			classic-only: whether only the rows of classic transactions are exported
+			operation-types: types of the operations that are exported
+			claimed-offers: whether manage offer operations have the offers they claimed and left on the book in their details
+
+			pubsub-topic: Pub/Sub topic every exported row is published to
+			sqs-queue-url: SQS queue every exported row is sent to
+			sqs-region: region of the SQS queue
+			queue-dedupe-keys: add a dedupe key of the ledger, table and sequence of the row to every message
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
@@ -18,54 +18,68 @@ var tokenTransfersCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
 		startNum, path, _, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
-
-		var ledgers []utils.HistoryArchiveLedgerAndLCM
-		var err error
-
-		ledgers, err = input.GetLedgers(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-
-		if err != nil {
-			cmdLogger.Fatal("could not read ledgers: ", err)
-		}
+		readCtx, stop := shutdownContext()
+		defer stop()
 
 		outFile := MustOutFile(path)
 
 		numFailures := 0
 		totalNumBytes := 0
-		for i, ledger := range ledgers {
-			transformed, err := transform.TransformTokenTransfer(ledger.LCM, env.NetworkPassphrase)
+		transformTokenTransfer := func(ledger utils.HistoryArchiveLedgerAndLCM) ([]transform.TokenTransferOutput, error) {
+			return transform.TransformTokenTransfer(ledger.LCM, env.NetworkPassphrase, commonArgs.Toid)
+		}
+		numLedgers, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.LedgerCloseMetaInputs(), nil, transformTokenTransfer, func(ledger utils.HistoryArchiveLedgerAndLCM, transformed []transform.TokenTransferOutput, err error) {
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not json transform ttp %d: %s", startNum+uint32(i), err))
+				cmdLogger.WithFields(utils.RowLogFields("token_transfers", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not json transform ttp %d: %w", ledger.LCM.LedgerSequence(), err))
 				numFailures += 1
-				continue
+				return
 			}
 
 			for _, transform := range transformed {
 				numBytes, err := ExportEntry(transform, outFile, commonArgs.Extra)
 				if err != nil {
-					cmdLogger.LogError(fmt.Errorf("could not export ledger %d: %s", startNum+uint32(i), err))
+					cmdLogger.WithFields(utils.RowLogFields("token_transfers", ledger.LCM.LedgerSequence(), "")).LogError(fmt.Errorf("could not export ledger %d: %w", ledger.LCM.LedgerSequence(), err))
 					numFailures += 1
 					continue
 				}
 				totalNumBytes += numBytes
+
+				if err := checks.add("token_transfers", transform); err != nil {
+					cmdLogger.Fatal(err)
+				}
 			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read ledgers: ", err)
 		}
 
-		outFile.Close()
+		closeOutFile(outFile)
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(ledgers), numFailures)
+		PrintTransformStats(numLedgers, numFailures)
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
 		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(tokenTransfersCmd)
 	utils.AddCommonFlags(tokenTransfersCmd.Flags())
+	utils.AddOutputFormatFlags(tokenTransfersCmd.Flags())
+	utils.AddQualityFlags(tokenTransfersCmd.Flags())
 	utils.AddArchiveFlags("token_transfer", tokenTransfersCmd.Flags())
 	utils.AddCloudStorageFlags(tokenTransfersCmd.Flags())
 	tokenTransfersCmd.MarkFlagRequired("end-ledger")
@@ -77,6 +91,10 @@ func init() {
 			limit: maximum number of ledgers to export; default to 60 (1 ledger per 5 seconds over our 5 minute update period)
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
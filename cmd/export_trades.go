@@ -4,7 +4,6 @@ import (
 	"fmt"
 
 	"github.com/sirupsen/logrus"
-	"github.com/stellar/stellar-etl/v2/internal/toid"
 
 	"github.com/spf13/cobra"
 	"github.com/stellar/stellar-etl/v2/internal/input"
@@ -21,62 +20,93 @@ var tradesCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("trades", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		splitByDay := utils.MustSplitFlags(cmd.Flags(), cmdLogger)
+		normalizePairs := utils.MustTradeFlags(cmd.Flags(), cmdLogger)
 
-		trades, err := input.GetTrades(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
-		if err != nil {
-			cmdLogger.Fatal("could not read trades ", err)
-		}
-
-		outFile := MustOutFile(path)
+		outFiles := newDayOutFiles(path, splitByDay)
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedTrades []transform.SchemaParquet
-		for _, tradeInput := range trades {
-			trades, err := transform.TransformTrade(tradeInput.OperationIndex, tradeInput.OperationHistoryID, tradeInput.Transaction, tradeInput.CloseTime)
+		transformedTrades := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedTrades.Close()
+		transformTrade := func(tradeInput input.TradeTransformInput) ([]transform.TradeOutput, error) {
+			return transform.TransformTrade(tradeInput.OperationIndex, tradeInput.OperationHistoryID, tradeInput.Transaction, tradeInput.CloseTime, commonArgs.Toid)
+		}
+		numTrades, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TradeInputs(), nil, timeTransform(timer, transformTrade, tradeInputType), func(tradeInput input.TradeTransformInput, trades []transform.TradeOutput, err error) {
 			if err != nil {
-				parsedID := toid.Parse(tradeInput.OperationHistoryID)
-				cmdLogger.LogError(fmt.Errorf("from ledger %d, transaction %d, operation %d: %v", parsedID.LedgerSequence, parsedID.TransactionOrder, parsedID.OperationOrder, err))
+				parsedID := commonArgs.Toid.Parse(tradeInput.OperationHistoryID)
+				cmdLogger.WithFields(utils.RowLogFields("trades", uint32(parsedID.LedgerSequence), utils.HashToHexString(tradeInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("from ledger %d, transaction %d, operation %d: %w", parsedID.LedgerSequence, parsedID.TransactionOrder, parsedID.OperationOrder, err))
 				numFailures += 1
-				continue
+				return
 			}
 
 			for _, transformed := range trades {
-				numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+				if normalizePairs {
+					transformed = transform.NormalizeTradePair(transformed)
+				}
+
+				numBytes, err := ExportEntry(transformed, outFiles.file(tradeInput.CloseTime), commonArgs.Extra)
 				if err != nil {
-					cmdLogger.LogError(err)
+					cmdLogger.WithFields(utils.RowLogFields("trades", tradeInput.Transaction.Ledger.LedgerSequence(), utils.HashToHexString(tradeInput.Transaction.Result.TransactionHash))).LogError(err)
 					numFailures += 1
 					continue
 				}
 				totalNumBytes += numBytes
 
+				if err := checks.add("trades", transformed); err != nil {
+					cmdLogger.Fatal(err)
+				}
+
 				if commonArgs.WriteParquet {
-					transformedTrades = append(transformedTrades, transformed)
+					transformedTrades.Append(transformed, numBytes)
 				}
 			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read trades ", err)
 		}
 
-		outFile.Close()
+		outFiles.close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(trades), numFailures)
+		PrintTransformStats(numTrades, numFailures)
+		timer.printSummary()
 
-		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		uploadFiles(commonArgs.Concurrency, cloudCredentials, cloudStorageBucket, cloudProvider, outFiles.paths())
 
 		if commonArgs.WriteParquet {
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 			WriteParquet(transformedTrades, parquetPath, new(transform.TradeOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "trades", parquetPath, new(transform.TradeOutputParquet), startNum, commonArgs.EndNum)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(tradesCmd)
 	utils.AddCommonFlags(tradesCmd.Flags())
+	utils.AddOutputFormatFlags(tradesCmd.Flags())
+	utils.AddQualityFlags(tradesCmd.Flags())
 	utils.AddArchiveFlags("trades", tradesCmd.Flags())
 	utils.AddCloudStorageFlags(tradesCmd.Flags())
+	utils.AddSplitFlags(tradesCmd.Flags())
+	utils.AddTradeFlags(tradesCmd.Flags())
+	utils.AddTransformTimingFlags(tradesCmd.Flags())
 	tradesCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -5,6 +5,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
 	"github.com/stellar/stellar-etl/v2/internal/input"
 	"github.com/stellar/stellar-etl/v2/internal/transform"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
@@ -18,60 +19,113 @@ var transactionsCmd = &cobra.Command{
 		cmdLogger.SetLevel(logrus.InfoLevel)
 		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
 		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		timer := newTransformTimer("transactions", utils.MustTransformTimingFlags(cmd.Flags(), cmdLogger))
+		outputFormat = utils.MustOutputFormatFlags(cmd.Flags(), cmdLogger)
+		timestampFormat = utils.MustTimestampFormatFlags(cmd.Flags(), cmdLogger)
+		checks := mustQualityChecker(utils.MustQualityFlags(cmd.Flags(), cmdLogger))
+		includeFailed := utils.MustIncludeFailedFlags(cmd.Flags(), cmdLogger)
+		sorobanOnly, classicOnly := utils.MustSorobanFilterFlags(cmd.Flags(), cmdLogger)
 		startNum, path, parquetPath, limit := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
 		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		splitByDay := utils.MustSplitFlags(cmd.Flags(), cmdLogger)
 		env := utils.GetEnvironmentDetails(commonArgs)
+		readCtx, stop := shutdownContext()
+		defer stop()
 
-		transactions, err := input.GetTransactions(startNum, commonArgs.EndNum, limit, env, commonArgs.UseCaptiveCore)
+		sizeMetrics, err := cmd.Flags().GetBool("size-metrics")
 		if err != nil {
-			cmdLogger.Fatal("could not read transactions: ", err)
+			cmdLogger.Fatal("could not get size-metrics: ", err)
 		}
 
-		outFile := MustOutFile(path)
+		transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+			return in.Transaction
+		}
+		filter := func(transactions []input.LedgerTransformInput) []input.LedgerTransformInput {
+			transactions = filterFailedTransactions(transactions, includeFailed, transaction)
+			return filterSorobanTransactions(transactions, sorobanOnly, classicOnly, transaction)
+		}
+
+		outFiles := newDayOutFiles(path, splitByDay)
 		numFailures := 0
 		totalNumBytes := 0
-		var transformedTransaction []transform.SchemaParquet
-		for _, transformInput := range transactions {
-			transformed, err := transform.TransformTransaction(transformInput.Transaction, transformInput.LedgerHistory)
+		transformedTransaction := newParquetRowBuffer(newRowMemoryBudget(commonArgs.MaxMemory))
+		defer transformedTransaction.Close()
+		transformTransaction := func(transformInput input.LedgerTransformInput) (transform.TransactionOutput, error) {
+			return transform.TransformTransaction(transformInput.Transaction, transformInput.LedgerHistory, commonArgs.Toid)
+		}
+		numTransactions, lastLedger, err := streamTransformed(readCtx, env, startNum, limit, input.TransactionInputs(), filter, timeTransform(timer, transformTransaction, transactionInputType), func(transformInput input.LedgerTransformInput, transformed transform.TransactionOutput, err error) {
 			if err != nil {
 				ledgerSeq := transformInput.LedgerHistory.Header.LedgerSeq
-				cmdLogger.LogError(fmt.Errorf("could not transform transaction %d in ledger %d: ", transformInput.Transaction.Index, ledgerSeq))
+				cmdLogger.WithFields(utils.RowLogFields("transactions", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not transform transaction %d in ledger %d: %w", transformInput.Transaction.Index, ledgerSeq, err))
 				numFailures += 1
-				continue
+				return
 			}
 
-			numBytes, err := ExportEntry(transformed, outFile, commonArgs.Extra)
+			if sizeMetrics {
+				transformed, err = transform.TransactionSizeMetrics(transformInput.Transaction, transformed)
+				if err != nil {
+					cmdLogger.WithFields(utils.RowLogFields("transactions", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not measure transaction %d: %w", transformInput.Transaction.Index, err))
+					numFailures += 1
+					return
+				}
+			}
+
+			closeTime, _ := utils.ExtractLedgerCloseTime(transformInput.LedgerHistory)
+			numBytes, err := ExportEntry(transformed, outFiles.file(closeTime), commonArgs.Extra)
 			if err != nil {
-				cmdLogger.LogError(fmt.Errorf("could not export transaction: %v", err))
+				cmdLogger.WithFields(utils.RowLogFields("transactions", uint32(transformInput.LedgerHistory.Header.LedgerSeq), utils.HashToHexString(transformInput.Transaction.Result.TransactionHash))).LogError(fmt.Errorf("could not export transaction: %w", err))
 				numFailures += 1
-				continue
+				return
 			}
 			totalNumBytes += numBytes
 
+			if err := checks.add("transactions", transformed); err != nil {
+				cmdLogger.Fatal(err)
+			}
+
 			if commonArgs.WriteParquet {
-				transformedTransaction = append(transformedTransaction, transformed)
+				transformedTransaction.Append(transformed, numBytes)
 			}
+		})
+		if err != nil {
+			cmdLogger.Fatal("could not read transactions: ", err)
 		}
 
-		outFile.Close()
+		outFiles.close()
 		cmdLogger.Info("Number of bytes written: ", totalNumBytes)
 
-		PrintTransformStats(len(transactions), numFailures)
+		PrintTransformStats(numTransactions, numFailures)
+		timer.printSummary()
+
+		if err := checks.finish(); err != nil {
+			cmdLogger.Fatal(err)
+		}
 
-		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+		uploadFiles(commonArgs.Concurrency, cloudCredentials, cloudStorageBucket, cloudProvider, outFiles.paths())
 
 		if commonArgs.WriteParquet {
 			MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, parquetPath)
 			WriteParquet(transformedTransaction, parquetPath, new(transform.TransactionOutputParquet))
+			MaybeCommitDelta(commonArgs.DeltaTableRoot, "transactions", parquetPath, new(transform.TransactionOutputParquet), startNum, commonArgs.EndNum)
 		}
+
+		recordCheckpoint(readCtx, commonArgs, env.Network, lastLedger)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(transactionsCmd)
 	utils.AddCommonFlags(transactionsCmd.Flags())
+	utils.AddOutputFormatFlags(transactionsCmd.Flags())
+	utils.AddQualityFlags(transactionsCmd.Flags())
+	utils.AddIncludeFailedFlags(transactionsCmd.Flags())
+	utils.AddSorobanFilterFlags(transactionsCmd.Flags())
 	utils.AddArchiveFlags("transactions", transactionsCmd.Flags())
 	utils.AddCloudStorageFlags(transactionsCmd.Flags())
+	utils.AddSplitFlags(transactionsCmd.Flags())
+	utils.AddTransformTimingFlags(transactionsCmd.Flags())
+	transactionsCmd.Flags().Bool("size-metrics", false, "If set, fill the envelope_size, result_size, meta_size and operation_changes_count columns")
 	transactionsCmd.MarkFlagRequired("end-ledger")
 
 	/*
@@ -86,6 +140,17 @@ func init() {
 
 			output-file: filename of the output file
 
+			quality-checks: data quality checks to run on the exported rows
+			quality-mode: whether failed quality checks warn or stop the export
+			quality-report: path of the json report of the quality checks
+
+			transform-timing: whether the transform durations of the rows are recorded by operation type
+
+			include-failed: whether rows of failed transactions are exported
+			soroban-only: whether only the rows of Soroban transactions are exported
+			classic-only: whether only the rows of classic transactions are exported
+			size-metrics: whether the sizes of the xdr and the number of ledger entry changes are exported
+
 		TODO: implement extra flags if possible
 			serialize-method: the method for serialization of the output data (JSON, XDR, etc)
 			start and end time as a replacement for start and end sequence numbers
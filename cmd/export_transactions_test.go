@@ -2,6 +2,12 @@ package cmd
 
 import (
 	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
 )
 
 func TestExportTransactions(t *testing.T) {
@@ -36,3 +42,133 @@ func TestExportTransactions(t *testing.T) {
 		RunCLITest(t, test, "testdata/transactions/", "", false)
 	}
 }
+
+func TestFilterFailedTransactions(t *testing.T) {
+	withCode := func(index uint32, code xdr.TransactionResultCode) input.LedgerTransformInput {
+		return input.LedgerTransformInput{Transaction: ingest.LedgerTransaction{
+			Index:  index,
+			Result: xdr.TransactionResultPair{Result: xdr.TransactionResult{Result: xdr.TransactionResultResult{Code: code}}},
+		}}
+	}
+	transactions := []input.LedgerTransformInput{
+		withCode(1, xdr.TransactionResultCodeTxSuccess),
+		withCode(2, xdr.TransactionResultCodeTxFailed),
+		withCode(3, xdr.TransactionResultCodeTxFeeBumpInnerSuccess),
+	}
+	transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+		return in.Transaction
+	}
+
+	assert.Equal(t, transactions, filterFailedTransactions(transactions, true, transaction))
+	assert.Equal(t, []input.LedgerTransformInput{transactions[0], transactions[2]}, filterFailedTransactions(transactions, false, transaction))
+}
+
+func TestFilterSorobanTransactions(t *testing.T) {
+	withOperations := func(index uint32, operationTypes ...xdr.OperationType) input.LedgerTransformInput {
+		operations := []xdr.Operation{}
+		for _, operationType := range operationTypes {
+			operations = append(operations, xdr.Operation{Body: xdr.OperationBody{Type: operationType}})
+		}
+		return input.LedgerTransformInput{Transaction: ingest.LedgerTransaction{
+			Index: index,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1:   &xdr.TransactionV1Envelope{Tx: xdr.Transaction{Operations: operations}},
+			},
+		}}
+	}
+	transactions := []input.LedgerTransformInput{
+		withOperations(1, xdr.OperationTypePayment, xdr.OperationTypeManageSellOffer),
+		withOperations(2, xdr.OperationTypeInvokeHostFunction),
+		withOperations(3, xdr.OperationTypeExtendFootprintTtl),
+		withOperations(4, xdr.OperationTypeRestoreFootprint),
+		withOperations(5, xdr.OperationTypeCreateAccount),
+	}
+	transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+		return in.Transaction
+	}
+
+	assert.Equal(t, transactions, filterSorobanTransactions(transactions, false, false, transaction))
+	assert.Equal(t, transactions[1:4], filterSorobanTransactions(transactions, true, false, transaction))
+	assert.Equal(t, []input.LedgerTransformInput{transactions[0], transactions[4]}, filterSorobanTransactions(transactions, false, true, transaction))
+}
+
+func TestFilterOperationTypes(t *testing.T) {
+	withOperation := func(operationType xdr.OperationType) input.OperationTransformInput {
+		return input.OperationTransformInput{Operation: xdr.Operation{Body: xdr.OperationBody{Type: operationType}}}
+	}
+	operations := []input.OperationTransformInput{
+		withOperation(xdr.OperationTypePayment),
+		withOperation(xdr.OperationTypeManageSellOffer),
+		withOperation(xdr.OperationTypeCreateAccount),
+	}
+	operation := func(in input.OperationTransformInput) xdr.Operation {
+		return in.Operation
+	}
+
+	assert.Equal(t, operations, filterOperationTypes(operations, nil, operation))
+	types := map[xdr.OperationType]bool{xdr.OperationTypePayment: true, xdr.OperationTypeCreateAccount: true}
+	assert.Equal(t, []input.OperationTransformInput{operations[0], operations[2]}, filterOperationTypes(operations, types, operation))
+
+	transactions := []input.LedgerTransformInput{}
+	for i, operationTypes := range [][]xdr.OperationType{
+		{xdr.OperationTypeManageSellOffer, xdr.OperationTypePayment},
+		{xdr.OperationTypeManageBuyOffer},
+	} {
+		ops := []xdr.Operation{}
+		for _, operationType := range operationTypes {
+			ops = append(ops, xdr.Operation{Body: xdr.OperationBody{Type: operationType}})
+		}
+		transactions = append(transactions, input.LedgerTransformInput{Transaction: ingest.LedgerTransaction{
+			Index: uint32(i),
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1:   &xdr.TransactionV1Envelope{Tx: xdr.Transaction{Operations: ops}},
+			},
+		}})
+	}
+	transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+		return in.Transaction
+	}
+	assert.Equal(t, transactions[:1], filterTransactionOperationTypes(transactions, types, transaction))
+}
+
+func TestFilterTransactionContracts(t *testing.T) {
+	invoking := func(index uint32, contractID xdr.Hash) input.LedgerTransformInput {
+		operation := xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{HostFunction: xdr.HostFunction{
+				Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+				InvokeContract: &xdr.InvokeContractArgs{
+					ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+				},
+			}},
+		}}
+		return input.LedgerTransformInput{Transaction: ingest.LedgerTransaction{
+			Index: index,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1:   &xdr.TransactionV1Envelope{Tx: xdr.Transaction{Operations: []xdr.Operation{operation}}},
+			},
+		}}
+	}
+	transactions := []input.LedgerTransformInput{
+		invoking(1, xdr.Hash{1}),
+		invoking(2, xdr.Hash{2}),
+		{Transaction: ingest.LedgerTransaction{
+			Index: 3,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{Tx: xdr.Transaction{Operations: []xdr.Operation{
+					{Body: xdr.OperationBody{Type: xdr.OperationTypePayment}},
+				}}},
+			},
+		}},
+	}
+	transaction := func(in input.LedgerTransformInput) ingest.LedgerTransaction {
+		return in.Transaction
+	}
+
+	assert.Equal(t, transactions, filterTransactionContracts(transactions, nil, transaction))
+	assert.Equal(t, transactions[1:2], filterTransactionContracts(transactions, map[xdr.Hash]bool{{2}: true}, transaction))
+}
@@ -0,0 +1,251 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// txRow is a row of a table transformed from a single transaction, printed as a json line
+type txRow struct {
+	Table string      `json:"table"`
+	Row   interface{} `json:"row"`
+}
+
+// rpcClient looks transactions up on a stellar rpc server
+type rpcClient struct {
+	url    string
+	client *http.Client
+}
+
+func newRPCClient(rpcURL string, timeout time.Duration) *rpcClient {
+	return &rpcClient{
+		url:    rpcURL,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// rpcTransactionResponse is the response of the getTransaction method of stellar rpc
+type rpcTransactionResponse struct {
+	Result struct {
+		Status string `json:"status"`
+		Ledger uint32 `json:"ledger"`
+	} `json:"result"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// transactionLedger returns the sequence of the ledger of a transaction. Rpc only keeps the transactions of its
+// retention window, so older transactions are not found.
+func (c *rpcClient) transactionLedger(ctx context.Context, hash string) (uint32, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      1,
+		"method":  "getTransaction",
+		"params":  map[string]string{"hash": hash},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("could not get transaction %s from %s: %v", hash, c.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("could not get transaction %s from %s: status %d", hash, c.url, resp.StatusCode)
+	}
+
+	var response rpcTransactionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return 0, fmt.Errorf("could not decode transaction %s from %s: %v", hash, c.url, err)
+	}
+	if response.Error != nil {
+		return 0, fmt.Errorf("could not get transaction %s from %s: %s (code %d)", hash, c.url, response.Error.Message, response.Error.Code)
+	}
+	if response.Result.Status == "NOT_FOUND" {
+		return 0, fmt.Errorf("transaction %s is not in the retention window of %s; set ledger instead", hash, c.url)
+	}
+	return response.Result.Ledger, nil
+}
+
+// transactionOnlyLedger returns the decoded ledger reduced to a single transaction and the ledger entry changes it
+// caused, so that the per transaction and per change tables only transform that transaction
+func transactionOnlyLedger(ledger input.DecodedLedger, hash string) (input.DecodedLedger, bool) {
+	var found bool
+	transactions := []ingest.LedgerTransaction{}
+	for _, tx := range ledger.Transactions {
+		if tx.Result.TransactionHash.HexString() == hash {
+			transactions = append(transactions, tx)
+			found = true
+		}
+	}
+
+	changes := []ingest.Change{}
+	for _, change := range ledger.Changes {
+		if change.Transaction != nil && change.Transaction.Result.TransactionHash.HexString() == hash {
+			changes = append(changes, change)
+		}
+	}
+
+	ledger.Transactions = transactions
+	ledger.Changes = changes
+	return ledger, found
+}
+
+// transformTransactionRows runs the transforms of every table on a single transaction of a ledger. The tables of
+// the whole ledger, such as ledgers or token_transfers, are left out.
+func transformTransactionRows(ledger input.DecodedLedger, hash, networkPassphrase string) ([]txRow, error) {
+	ledger, found := transactionOnlyLedger(ledger, hash)
+	if !found {
+		return nil, fmt.Errorf("transaction %s is not in ledger %d", hash, ledger.Header.Header.LedgerSeq)
+	}
+
+	rows := []txRow{}
+	for _, table := range input.LedgerTables() {
+		if table.Reads == 0 {
+			continue
+		}
+
+		transformed, err := table.Transform(ledger, networkPassphrase)
+		if err != nil {
+			return rows, fmt.Errorf("could not transform %s: %v", table.Name, err)
+		}
+		for _, row := range transformed {
+			rows = append(rows, txRow{Table: table.Name, Row: row})
+		}
+	}
+	return rows, nil
+}
+
+// exportTransaction reads a single ledger from the backend and transforms the transaction with the given hash
+func exportTransaction(ctx context.Context, backend ledgerbackend.LedgerBackend, ledgerSeq uint32, hash, networkPassphrase string, ids toid.Generator) ([]txRow, error) {
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(ledgerSeq, ledgerSeq)); err != nil {
+		return nil, fmt.Errorf("could not prepare ledger %d: %v", ledgerSeq, err)
+	}
+	lcm, err := backend.GetLedger(ctx, ledgerSeq)
+	if err != nil {
+		return nil, fmt.Errorf("error getting ledger seq %d from the backend: %v", ledgerSeq, err)
+	}
+
+	ledger, err := input.DecodeLedger(lcm, networkPassphrase)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode ledger %d: %v", ledgerSeq, err)
+	}
+	ledger.Toid = ids
+
+	return transformTransactionRows(ledger, hash, networkPassphrase)
+}
+
+var txCmd = &cobra.Command{
+	Use:   "export_tx",
+	Short: "Prints the rows of every table for a single transaction",
+	Long: `Finds a transaction by its hash, runs the transforms of every table on just that transaction and prints the
+rows as json lines, each with the name of its table. The ledger of the transaction is read from the ledger flag, or
+looked up on a stellar rpc server. It is meant for debugging the rows of a transaction.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		hash, err := cmd.Flags().GetString("hash")
+		if err != nil {
+			cmdLogger.Fatal("could not get hash: ", err)
+		}
+
+		ledgerSeq, err := cmd.Flags().GetUint32("ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get ledger: ", err)
+		}
+
+		rpcURL, err := cmd.Flags().GetString("rpc-url")
+		if err != nil {
+			cmdLogger.Fatal("could not get rpc-url: ", err)
+		}
+
+		timeout, err := cmd.Flags().GetDuration("rpc-timeout")
+		if err != nil {
+			cmdLogger.Fatal("could not get rpc-timeout: ", err)
+		}
+
+		ctx := context.Background()
+		if ledgerSeq == 0 {
+			if rpcURL == "" {
+				cmdLogger.Fatal("either ledger or rpc-url must be set to find the ledger of the transaction")
+			}
+			ledgerSeq, err = newRPCClient(rpcURL, timeout).transactionLedger(ctx, hash)
+			if err != nil {
+				cmdLogger.Fatal(err)
+			}
+		}
+
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		rows, err := exportTransaction(ctx, backend, ledgerSeq, hash, env.NetworkPassphrase, commonArgs.Toid)
+		if err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		if err := printTxRows(os.Stdout, rows); err != nil {
+			cmdLogger.Fatal("could not print rows: ", err)
+		}
+	},
+}
+
+// printTxRows writes the rows of a transaction as json lines
+func printTxRows(w io.Writer, rows []txRow) error {
+	encoder := json.NewEncoder(w)
+	for _, row := range rows {
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(txCmd)
+	utils.AddCommonFlags(txCmd.Flags())
+	txCmd.Flags().String("hash", "", "Hex hash of the transaction")
+	txCmd.Flags().Uint32("ledger", 0, "Sequence of the ledger of the transaction; looked up on rpc-url if 0")
+	txCmd.Flags().String("rpc-url", "", "Url of a stellar rpc server to look up the ledger of the transaction on")
+	txCmd.Flags().Duration("rpc-timeout", 30*time.Second, "Timeout of the requests to the rpc server")
+	txCmd.MarkFlagRequired("hash")
+
+	/*
+		Current flags:
+			hash: hex hash of the transaction (required)
+			ledger: sequence of the ledger of the transaction
+			rpc-url: stellar rpc server the ledger of the transaction is looked up on when ledger is not set
+			rpc-timeout: timeout of the requests to the rpc server
+	*/
+}
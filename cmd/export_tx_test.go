@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+)
+
+// newRPCTestServer answers getTransaction requests with the given result
+func newRPCTestServer(t *testing.T, result string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Method string            `json:"method"`
+			Params map[string]string `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "getTransaction", request.Method)
+		assert.Equal(t, horizonTestHash, request.Params["hash"])
+		w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": ` + result + `}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRPCTransactionLedger(t *testing.T) {
+	server := newRPCTestServer(t, `{"status": "SUCCESS", "ledger": 1234}`)
+	ledgerSeq, err := newRPCClient(server.URL, time.Second).transactionLedger(context.Background(), horizonTestHash)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1234), ledgerSeq)
+
+	server = newRPCTestServer(t, `{"status": "NOT_FOUND"}`)
+	_, err = newRPCClient(server.URL, time.Second).transactionLedger(context.Background(), horizonTestHash)
+	assert.EqualError(t, err, "transaction "+horizonTestHash+" is not in the retention window of "+server.URL+"; set ledger instead")
+}
+
+func TestTransactionOnlyLedger(t *testing.T) {
+	txWithHash := func(hash byte) ingest.LedgerTransaction {
+		tx := ingest.LedgerTransaction{}
+		tx.Result.TransactionHash[0] = hash
+		return tx
+	}
+	first, second := txWithHash(1), txWithHash(2)
+	ledger := input.DecodedLedger{
+		Transactions: []ingest.LedgerTransaction{first, second},
+		Changes: []ingest.Change{
+			{Type: xdr.LedgerEntryTypeAccount, Transaction: &first},
+			{Type: xdr.LedgerEntryTypeOffer, Transaction: &second},
+			{Type: xdr.LedgerEntryTypeTtl},
+		},
+	}
+
+	only, found := transactionOnlyLedger(ledger, second.Result.TransactionHash.HexString())
+	assert.True(t, found)
+	assert.Equal(t, []ingest.LedgerTransaction{second}, only.Transactions)
+	assert.Equal(t, []ingest.Change{{Type: xdr.LedgerEntryTypeOffer, Transaction: &second}}, only.Changes)
+
+	_, found = transactionOnlyLedger(ledger, horizonTestHash)
+	assert.False(t, found)
+}
+
+func TestExportTransactionNotInLedger(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 10)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeDuckDBTestLedger(10), nil)
+
+	_, err := exportTransaction(context.Background(), backend, 10, horizonTestHash, network.TestNetworkPassphrase, toid.Generator{})
+	assert.EqualError(t, err, "transaction "+horizonTestHash+" is not in ledger 10")
+	backend.AssertExpectations(t)
+}
+
+func TestPrintTxRows(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, printTxRows(&out, []txRow{{Table: "transactions", Row: map[string]int{"ledger_sequence": 10}}}))
+	assert.Equal(t, `{"table":"transactions","row":{"ledger_sequence":10}}`+"\n", out.String())
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// The transaction index and close time used by TestOperationEffects in internal/transform/effects_test.go
+const (
+	effectsTestTransactionIndex = 1
+	effectsTestCloseTime        = "genericCloseTime.UTC()"
+)
+
+var generateEffectsTestcaseCmd = &cobra.Command{
+	Use:   "generate_effects_testcase",
+	Short: "Generates effects test cases from a transaction on the network",
+	Long: `Fetches the envelope, result and meta of a transaction from the datastore, runs the effects transform
+and prints a test case per operation that can be pasted into TestOperationEffects in internal/transform/effects_test.go.
+The ledger that contains the transaction is required, since transactions are read from the ledger close meta.
+The expected effects are what the transform currently outputs, so review them before committing the test.`,
+	Hidden: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		hash, err := cmd.Flags().GetString("hash")
+		if err != nil {
+			cmdLogger.Fatal("could not get hash: ", err)
+		}
+
+		ledgerSeq, err := cmd.Flags().GetUint32("ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get ledger: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output: ", err)
+		}
+
+		ctx := context.Background()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(ledgerSeq, ledgerSeq)); err != nil {
+			cmdLogger.Fatal("could not prepare ledger range: ", err)
+		}
+
+		lcm, err := backend.GetLedger(ctx, ledgerSeq)
+		if err != nil {
+			cmdLogger.Fatalf("could not get ledger %d: %v", ledgerSeq, err)
+		}
+
+		tx, err := findTransaction(lcm, hash, env.NetworkPassphrase)
+		if err != nil {
+			cmdLogger.Fatal(err)
+		}
+
+		testcases, err := effectsTestcases(tx, lcm)
+		if err != nil {
+			cmdLogger.Fatal("could not generate test cases: ", err)
+		}
+
+		out := cmd.OutOrStdout()
+		if path != "" && path != "-" {
+			outFile := MustOutFile(path)
+			defer outFile.Close()
+			out = outFile
+		}
+
+		if _, err := io.WriteString(out, testcases); err != nil {
+			cmdLogger.Fatal("could not write test cases: ", err)
+		}
+	},
+}
+
+func findTransaction(lcm xdr.LedgerCloseMeta, hash, networkPassphrase string) (ingest.LedgerTransaction, error) {
+	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, lcm)
+	if err != nil {
+		return ingest.LedgerTransaction{}, err
+	}
+	defer txReader.Close()
+
+	for {
+		tx, err := txReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ingest.LedgerTransaction{}, err
+		}
+
+		if tx.Result.TransactionHash.HexString() == hash {
+			return tx, nil
+		}
+	}
+
+	return ingest.LedgerTransaction{}, fmt.Errorf("transaction %s not found in ledger %d", hash, lcm.LedgerSequence())
+}
+
+// effectsTestcases renders the test cases of every operation in the transaction. The effects are computed
+// the same way TestOperationEffects does, so the operation ids and close times match the test.
+func effectsTestcases(tx ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) (string, error) {
+	ledgerSeq := lcm.LedgerSequence()
+	tx.Index = effectsTestTransactionIndex
+
+	envelopeXDR, err := xdr.MarshalBase64(tx.Envelope)
+	if err != nil {
+		return "", err
+	}
+	resultXDR, err := xdr.MarshalBase64(tx.Result.Result)
+	if err != nil {
+		return "", err
+	}
+	metaXDR, err := xdr.MarshalBase64(tx.UnsafeMeta)
+	if err != nil {
+		return "", err
+	}
+	feeChangesXDR, err := xdr.MarshalBase64(tx.FeeChanges)
+	if err != nil {
+		return "", err
+	}
+
+	// TestOperationEffects does not set a network passphrase on the operations
+	effects, err := transform.TransformEffect(tx, ledgerSeq, lcm, "", toid.Generator{})
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("package transform\n\nvar _ = []testcase{\n")
+	for index, op := range tx.Envelope.Operations() {
+		operationID := toid.New(int32(ledgerSeq), effectsTestTransactionIndex, int32(index+1)).ToInt64()
+
+		fmt.Fprintf(&buf, "{\n")
+		fmt.Fprintf(&buf, "desc: %s,\n", strconv.Quote(fmt.Sprintf("%s - %s", operationTypeName(op.Body.Type), tx.Result.TransactionHash.HexString())))
+		fmt.Fprintf(&buf, "envelopeXDR: %s,\n", strconv.Quote(envelopeXDR))
+		fmt.Fprintf(&buf, "resultXDR: %s,\n", strconv.Quote(resultXDR))
+		fmt.Fprintf(&buf, "metaXDR: %s,\n", strconv.Quote(metaXDR))
+		fmt.Fprintf(&buf, "feeChangesXDR: %s,\n", strconv.Quote(feeChangesXDR))
+		fmt.Fprintf(&buf, "hash: %s,\n", strconv.Quote(tx.Result.TransactionHash.HexString()))
+		fmt.Fprintf(&buf, "index: %d,\n", index)
+		fmt.Fprintf(&buf, "sequence: %d,\n", ledgerSeq)
+		fmt.Fprintf(&buf, "expected: []EffectOutput{\n")
+		for _, effect := range effects {
+			if effect.OperationID != operationID {
+				continue
+			}
+			writeEffectLiteral(&buf, effect)
+		}
+		fmt.Fprintf(&buf, "},\n},\n")
+	}
+	buf.WriteString("}\n")
+
+	formatted, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", fmt.Errorf("could not format test cases: %v", err)
+	}
+
+	// Only keep the test cases, unindented by one level, so they can be pasted into the table
+	lines := strings.Split(strings.TrimRight(string(formatted), "\n"), "\n")
+	lines = lines[3 : len(lines)-1]
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, "\t")
+	}
+
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// operationTypeName converts OperationTypeBumpSequence to bumpSequence, matching the descriptions of the test cases
+func operationTypeName(operationType xdr.OperationType) string {
+	name := strings.TrimPrefix(operationType.String(), "OperationType")
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// writeEffectLiteral writes the effect as a Go composite literal. The effect index and id are left out
+// because the test fills them in.
+func writeEffectLiteral(buf *bytes.Buffer, effect transform.EffectOutput) {
+	fmt.Fprintf(buf, "{\n")
+	fmt.Fprintf(buf, "Address: %s,\n", strconv.Quote(effect.Address))
+	if effect.AddressMuxed.Valid {
+		fmt.Fprintf(buf, "AddressMuxed: null.StringFrom(%s),\n", strconv.Quote(effect.AddressMuxed.String))
+	}
+	fmt.Fprintf(buf, "Type: int32(%s),\n", effectTypeConstant(effect.Type))
+	fmt.Fprintf(buf, "TypeString: EffectTypeNames[%s],\n", effectTypeConstant(effect.Type))
+	fmt.Fprintf(buf, "OperationID: int64(%d),\n", effect.OperationID)
+	fmt.Fprintf(buf, "Details: %s,\n", goLiteral(reflect.ValueOf(effect.Details)))
+	fmt.Fprintf(buf, "LedgerClosed: %s,\n", effectsTestCloseTime)
+	fmt.Fprintf(buf, "LedgerSequence: %d,\n", effect.LedgerSequence)
+	fmt.Fprintf(buf, "},\n")
+}
+
+// effectTypeConstant finds the name of the EffectType constant, e.g. EffectAccountCreated
+func effectTypeConstant(effectType int32) string {
+	name, ok := transform.EffectTypeNames[transform.EffectType(effectType)]
+	if !ok {
+		return fmt.Sprintf("EffectType(%d)", effectType)
+	}
+
+	constant := "Effect"
+	for _, word := range strings.Split(name, "_") {
+		if word == "" {
+			continue
+		}
+		constant += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return constant
+}
+
+// goLiteral renders a value as Go source, keeping the named types of the values in interface fields so that
+// the expected details compare equal to the transformed ones
+func goLiteral(v reflect.Value) string {
+	if !v.IsValid() {
+		return "nil"
+	}
+
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return "nil"
+		}
+		return goLiteral(v.Elem())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return "nil"
+		}
+		return "&" + goLiteral(v.Elem())
+	case reflect.String:
+		if v.Type().PkgPath() == "" {
+			return strconv.Quote(v.String())
+		}
+		return fmt.Sprintf("%s(%s)", v.Type().String(), strconv.Quote(v.String()))
+	case reflect.Bool:
+		if v.Type().PkgPath() == "" {
+			return strconv.FormatBool(v.Bool())
+		}
+		return fmt.Sprintf("%s(%t)", v.Type().String(), v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprintf("%s(%d)", v.Type().String(), v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprintf("%s(%d)", v.Type().String(), v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return fmt.Sprintf("%s(%s)", v.Type().String(), strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return "nil"
+		}
+		elements := make([]string, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elements[i] = goLiteral(v.Index(i))
+		}
+		return fmt.Sprintf("%s{%s}", v.Type().String(), strings.Join(elements, ", "))
+	case reflect.Map:
+		if v.IsNil() {
+			return "nil"
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface()) })
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "%s{\n", v.Type().String())
+		for _, key := range keys {
+			fmt.Fprintf(&buf, "%s: %s,\n", goLiteral(key), goLiteral(v.MapIndex(key)))
+		}
+		buf.WriteString("}")
+		return buf.String()
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return fmt.Sprintf("time.Unix(%d, %d).UTC()", t.Unix(), t.Nanosecond())
+		}
+		var buf strings.Builder
+		fmt.Fprintf(&buf, "%s{", v.Type().String())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || v.Field(i).IsZero() {
+				continue
+			}
+			fmt.Fprintf(&buf, "%s: %s, ", field.Name, goLiteral(v.Field(i)))
+		}
+		return strings.TrimSuffix(buf.String(), ", ") + "}"
+	}
+
+	return fmt.Sprintf("%#v", v.Interface())
+}
+
+func init() {
+	rootCmd.AddCommand(generateEffectsTestcaseCmd)
+	utils.AddCommonFlags(generateEffectsTestcaseCmd.Flags())
+	generateEffectsTestcaseCmd.Flags().String("hash", "", "Hash of the transaction to generate the test cases from")
+	generateEffectsTestcaseCmd.Flags().Uint32("ledger", 0, "Sequence of the ledger that contains the transaction")
+	generateEffectsTestcaseCmd.Flags().StringP("output", "o", "-", "Filename of the test cases; stdout by default")
+	generateEffectsTestcaseCmd.MarkFlagRequired("hash")
+	generateEffectsTestcaseCmd.MarkFlagRequired("ledger")
+
+	/*
+		Current flags:
+			hash: hash of the transaction to generate the test cases from
+			ledger: sequence of the ledger that contains the transaction
+
+			output: filename of the test cases; stdout by default
+	*/
+}
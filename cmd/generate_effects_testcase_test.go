@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectsTestcases(t *testing.T) {
+	// The bumpSequence test case of TestOperationEffects
+	tx := ingest.LedgerTransaction{Index: 3}
+	require.NoError(t, xdr.SafeUnmarshalBase64("AAAAAKGX7RT96eIn205uoUHYnqLbt2cPRNORraEoeTAcrRKUAAAAZAAAADkAAAABAAAAAAAAAAAAAAABAAAAAAAAAAsAAABF2WS4AAAAAAAAAAABHK0SlAAAAEDq0JVhKNIq9ag0sR+R/cv3d9tEuaYEm2BazIzILRdGj9alaVMZBhxoJ3ZIpP3rraCJzyoKZO+p5HBVe10a2+UG", &tx.Envelope))
+	require.NoError(t, xdr.SafeUnmarshalBase64("AAAAAAAAAGQAAAAAAAAAAQAAAAAAAAALAAAAAAAAAAA=", &tx.Result.Result))
+	require.NoError(t, xdr.SafeUnmarshalBase64("AAAAAQAAAAIAAAADAAAAOgAAAAAAAAAAoZftFP3p4ifbTm6hQdieotu3Zw9E05GtoSh5MBytEpQAAAACVAvjnAAAADkAAAAAAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAABAAAAOgAAAAAAAAAAoZftFP3p4ifbTm6hQdieotu3Zw9E05GtoSh5MBytEpQAAAACVAvjnAAAADkAAAABAAAAAAAAAAAAAAAAAAAAAAEAAAAAAAAAAAAAAAAAAAAAAAABAAAAAgAAAAMAAAA6AAAAAAAAAAChl+0U/eniJ9tObqFB2J6i27dnD0TTka2hKHkwHK0SlAAAAAJUC+OcAAAAOQAAAAEAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAAAAAAEAAAA6AAAAAAAAAAChl+0U/eniJ9tObqFB2J6i27dnD0TTka2hKHkwHK0SlAAAAAJUC+OcAAAARdlkuAAAAAAAAAAAAAAAAAAAAAAAAQAAAAAAAAAAAAAAAAAAAA==", &tx.UnsafeMeta))
+	_, err := hex.Decode(tx.Result.TransactionHash[:], []byte("829d53f2dceebe10af8007564b0aefde819b95734ad431df84270651e7ed8a90"))
+	require.NoError(t, err)
+
+	lcm := xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{LedgerSeq: 58},
+			},
+		},
+	}
+
+	testcases, err := effectsTestcases(tx, lcm)
+	require.NoError(t, err)
+	assert.Contains(t, testcases, `desc:          "bumpSequence - 829d53f2dceebe10af8007564b0aefde819b95734ad431df84270651e7ed8a90",`)
+	assert.Contains(t, testcases, `index:         0,`)
+	assert.Contains(t, testcases, `sequence:      58,`)
+	assert.Contains(t, testcases, `Type:        int32(EffectSequenceBumped),`)
+	assert.Contains(t, testcases, `OperationID: int64(249108107265),`)
+	assert.Contains(t, testcases, `"new_seq": int64(300000000000),`)
+	assert.Contains(t, testcases, `LedgerClosed:   genericCloseTime.UTC(),`)
+}
+
+func TestGoLiteral(t *testing.T) {
+	details := map[string]interface{}{
+		"b":      true,
+		"amount": "10.0000000",
+		"seq":    xdr.SequenceNumber(5),
+		"flags":  []string{"auth_required"},
+		"nested": map[string]interface{}{"id": int64(1)},
+	}
+
+	assert.Equal(t, `map[string]interface {}{
+"amount": "10.0000000",
+"b": true,
+"flags": []string{"auth_required"},
+"nested": map[string]interface {}{
+"id": int64(1),
+},
+"seq": xdr.SequenceNumber(5),
+}`, goLiteral(reflect.ValueOf(details)))
+}
@@ -0,0 +1,629 @@
+package cmd
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// The parameters of the generated ledgers that are not set by flags
+const (
+	generatedProtocolVersion = 22
+	generatedBaseFee         = 100
+	generatedBaseReserve     = 5000000
+	generatedCloseInterval   = 5 * time.Second
+	// Every account starts with a million XLM and a million of the generated asset, and an offer selling all of its
+	// asset for XLM at 2 XLM each
+	generatedStartingBalance = 1000000 * 10000000
+	generatedContracts       = 16
+)
+
+var generatedOfferPrice = xdr.Price{N: 2, D: 1}
+
+// transactionMix is the relative weights of the kinds of transactions in the generated ledgers
+type transactionMix struct {
+	Payments    int
+	Trades      int
+	Invocations int
+}
+
+// parseTransactionMix reads the weights of the tx-mix flag, keyed by payments, trades and invocations
+func parseTransactionMix(weights map[string]int) (transactionMix, error) {
+	mix := transactionMix{}
+	for kind, weight := range weights {
+		if weight < 0 {
+			return transactionMix{}, fmt.Errorf("weight of %s is negative: %d", kind, weight)
+		}
+		switch kind {
+		case "payments":
+			mix.Payments = weight
+		case "trades":
+			mix.Trades = weight
+		case "invocations":
+			mix.Invocations = weight
+		default:
+			return transactionMix{}, fmt.Errorf("unknown transaction kind %s; expected payments, trades or invocations", kind)
+		}
+	}
+	if mix.Payments+mix.Trades+mix.Invocations == 0 {
+		return transactionMix{}, fmt.Errorf("at least one transaction kind needs a positive weight")
+	}
+	return mix, nil
+}
+
+// generatedAccount is an account of the generated ledgers along with its trustline to the generated asset and its
+// offer. The entries are updated as the generated transactions move balances, so that the changes in the metas of
+// consecutive transactions and ledgers follow each other.
+type generatedAccount struct {
+	account           xdr.AccountEntry
+	trustline         xdr.TrustLineEntry
+	offer             xdr.OfferEntry
+	accountModified   xdr.Uint32
+	trustlineModified xdr.Uint32
+	offerModified     xdr.Uint32
+}
+
+// generatedOperation is the single operation of a generated transaction, with its result and meta
+type generatedOperation struct {
+	operation   xdr.Operation
+	result      xdr.OperationResult
+	changes     xdr.LedgerEntryChanges
+	sorobanMeta *xdr.SorobanTransactionMeta
+}
+
+// ledgerGenerator synthesizes ledger close metas with a mix of payments, trades and Soroban invocations between a
+// fixed set of accounts, for load testing the transforms and sinks without network data
+type ledgerGenerator struct {
+	networkPassphrase     string
+	random                *rand.Rand
+	mix                   transactionMix
+	transactionsPerLedger int
+	eventsPerInvocation   int
+	asset                 xdr.Asset
+	// assetContract is the Stellar Asset Contract of the generated asset, which emits the events of the invocations
+	assetContract xdr.Hash
+	accounts      []*generatedAccount
+	// contracts are the addresses of the contracts between which the invocations transfer the generated asset
+	contracts    []string
+	ledgerSeq    xdr.Uint32
+	closeTime    time.Time
+	previousHash xdr.Hash
+}
+
+func newLedgerGenerator(networkPassphrase string, seed int64, startLedger uint32, accounts, transactionsPerLedger, eventsPerInvocation int, mix transactionMix) (*ledgerGenerator, error) {
+	if accounts < 2 {
+		return nil, fmt.Errorf("at least 2 accounts are needed, got %d", accounts)
+	}
+
+	g := &ledgerGenerator{
+		networkPassphrase:     networkPassphrase,
+		random:                rand.New(rand.NewSource(seed)),
+		mix:                   mix,
+		transactionsPerLedger: transactionsPerLedger,
+		eventsPerInvocation:   eventsPerInvocation,
+		ledgerSeq:             xdr.Uint32(startLedger),
+		closeTime:             time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	issuer, err := g.randomKeypair()
+	if err != nil {
+		return nil, err
+	}
+	g.asset = xdr.MustNewCreditAsset("USD", issuer.Address())
+	contractID, err := g.asset.ContractID(networkPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	g.assetContract = contractID
+
+	for i := 0; i < accounts; i++ {
+		kp, err := g.randomKeypair()
+		if err != nil {
+			return nil, err
+		}
+		accountID := xdr.MustAddress(kp.Address())
+		g.accounts = append(g.accounts, &generatedAccount{
+			account: xdr.AccountEntry{
+				AccountId:     accountID,
+				Balance:       generatedStartingBalance,
+				SeqNum:        xdr.SequenceNumber(startLedger) << 32,
+				NumSubEntries: 2,
+				Thresholds:    xdr.Thresholds{1, 0, 0, 0},
+			},
+			trustline: xdr.TrustLineEntry{
+				AccountId: accountID,
+				Asset:     g.asset.ToTrustLineAsset(),
+				Balance:   generatedStartingBalance,
+				Limit:     xdr.Int64(1<<63 - 1),
+				Flags:     xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+			},
+			offer: xdr.OfferEntry{
+				SellerId: accountID,
+				OfferId:  xdr.Int64(i + 1),
+				Selling:  g.asset,
+				Buying:   xdr.MustNewNativeAsset(),
+				Amount:   generatedStartingBalance,
+				Price:    generatedOfferPrice,
+			},
+			accountModified:   g.ledgerSeq - 1,
+			trustlineModified: g.ledgerSeq - 1,
+			offerModified:     g.ledgerSeq - 1,
+		})
+	}
+
+	for i := 0; i < generatedContracts; i++ {
+		var contractID [32]byte
+		g.random.Read(contractID[:])
+		address, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+		if err != nil {
+			return nil, err
+		}
+		g.contracts = append(g.contracts, address)
+	}
+
+	return g, nil
+}
+
+func (g *ledgerGenerator) randomKeypair() (*keypair.Full, error) {
+	var seed [32]byte
+	g.random.Read(seed[:])
+	return keypair.FromRawSeed(seed)
+}
+
+// otherAccount returns a random account other than the given one
+func (g *ledgerGenerator) otherAccount(account *generatedAccount) *generatedAccount {
+	for {
+		other := g.accounts[g.random.Intn(len(g.accounts))]
+		if other != account {
+			return other
+		}
+	}
+}
+
+// nextLedger returns the ledger close meta of the next ledger
+func (g *ledgerGenerator) nextLedger() (xdr.LedgerCloseMeta, error) {
+	var classic, soroban []xdr.TransactionEnvelope
+	processing := []xdr.TransactionResultMeta{}
+	var feePool xdr.Int64
+	for i := 0; i < g.transactionsPerLedger; i++ {
+		envelope, resultMeta, err := g.nextTransaction()
+		if err != nil {
+			return xdr.LedgerCloseMeta{}, err
+		}
+		if envelope.V1.Tx.Ext.V == 1 {
+			soroban = append(soroban, envelope)
+		} else {
+			classic = append(classic, envelope)
+		}
+		processing = append(processing, resultMeta)
+		feePool += resultMeta.Result.Result.FeeCharged
+	}
+
+	header := xdr.LedgerHeader{
+		LedgerVersion:      generatedProtocolVersion,
+		PreviousLedgerHash: g.previousHash,
+		ScpValue:           xdr.StellarValue{CloseTime: xdr.TimePoint(g.closeTime.Unix())},
+		LedgerSeq:          g.ledgerSeq,
+		TotalCoins:         1000000000000000000,
+		FeePool:            feePool,
+		BaseFee:            generatedBaseFee,
+		BaseReserve:        generatedBaseReserve,
+		MaxTxSetSize:       xdr.Uint32(max(g.transactionsPerLedger, 1000)),
+	}
+	g.random.Read(header.ScpValue.TxSetHash[:])
+	g.random.Read(header.TxSetResultHash[:])
+	g.random.Read(header.BucketListHash[:])
+	encoded, err := header.MarshalBinary()
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, err
+	}
+	hash := xdr.Hash(sha256.Sum256(encoded))
+
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			Ext: xdr.LedgerCloseMetaExt{V: 1, V1: &xdr.LedgerCloseMetaExtV1{SorobanFeeWrite1Kb: 1000}},
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Hash:   hash,
+				Header: header,
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V: 1,
+				V1TxSet: &xdr.TransactionSetV1{
+					PreviousLedgerHash: g.previousHash,
+					Phases:             []xdr.TransactionPhase{transactionPhase(classic), transactionPhase(soroban)},
+				},
+			},
+			TxProcessing: processing,
+		},
+	}
+
+	g.previousHash = hash
+	g.ledgerSeq++
+	g.closeTime = g.closeTime.Add(generatedCloseInterval)
+	return lcm, nil
+}
+
+func transactionPhase(envelopes []xdr.TransactionEnvelope) xdr.TransactionPhase {
+	baseFee := xdr.Int64(generatedBaseFee)
+	return xdr.TransactionPhase{
+		V: 0,
+		V0Components: &[]xdr.TxSetComponent{{
+			Type:                  xdr.TxSetComponentTypeTxsetCompTxsMaybeDiscountedFee,
+			TxsMaybeDiscountedFee: &xdr.TxSetComponentTxsMaybeDiscountedFee{BaseFee: &baseFee, Txs: envelopes},
+		}},
+	}
+}
+
+// nextTransaction returns a transaction of a random kind from the mix, with a random source account, along with its
+// result and meta. The fee is charged and the sequence number bumped before the operation is applied, as in core.
+func (g *ledgerGenerator) nextTransaction() (xdr.TransactionEnvelope, xdr.TransactionResultMeta, error) {
+	source := g.accounts[g.random.Intn(len(g.accounts))]
+
+	pick := g.random.Intn(g.mix.Payments + g.mix.Trades + g.mix.Invocations)
+	invocation := pick >= g.mix.Payments+g.mix.Trades
+
+	fee := xdr.Int64(generatedBaseFee)
+	ext := xdr.TransactionExt{V: 0}
+	if invocation {
+		resources := xdr.SorobanTransactionData{
+			Resources: xdr.SorobanResources{
+				Instructions: xdr.Uint32(1000000 + g.random.Intn(10000000)),
+				ReadBytes:    xdr.Uint32(1000 + g.random.Intn(10000)),
+				WriteBytes:   xdr.Uint32(100 + g.random.Intn(1000)),
+			},
+			ResourceFee: xdr.Int64(10000 + g.random.Intn(100000)),
+		}
+		fee += resources.ResourceFee
+		ext = xdr.TransactionExt{V: 1, SorobanData: &resources}
+	}
+
+	feeChanges := g.updateAccount(source, -fee, false)
+	seqChanges := g.updateAccount(source, 0, true)
+
+	var operation generatedOperation
+	switch {
+	case invocation:
+		operation = g.invocation(fee - generatedBaseFee)
+	case pick >= g.mix.Payments:
+		operation = g.trade(source)
+	default:
+		operation = g.payment(source)
+	}
+
+	envelope := xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+		V1: &xdr.TransactionV1Envelope{
+			Tx: xdr.Transaction{
+				SourceAccount: source.account.AccountId.ToMuxedAccount(),
+				Fee:           xdr.Uint32(fee),
+				SeqNum:        source.account.SeqNum,
+				Cond:          xdr.Preconditions{Type: xdr.PreconditionTypePrecondNone},
+				Operations:    []xdr.Operation{operation.operation},
+				Ext:           ext,
+			},
+		},
+	}
+	hash, err := network.HashTransactionInEnvelope(envelope, g.networkPassphrase)
+	if err != nil {
+		return xdr.TransactionEnvelope{}, xdr.TransactionResultMeta{}, err
+	}
+
+	resultMeta := xdr.TransactionResultMeta{
+		Result: xdr.TransactionResultPair{
+			TransactionHash: hash,
+			Result: xdr.TransactionResult{
+				FeeCharged: fee,
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{operation.result},
+				},
+			},
+		},
+		FeeProcessing: feeChanges,
+		TxApplyProcessing: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				TxChangesBefore: seqChanges,
+				Operations:      []xdr.OperationMeta{{Changes: operation.changes}},
+				SorobanMeta:     operation.sorobanMeta,
+			},
+		},
+	}
+	return envelope, resultMeta, nil
+}
+
+// payment is a native payment from the source to another account
+func (g *ledgerGenerator) payment(source *generatedAccount) generatedOperation {
+	destination := g.otherAccount(source)
+	amount := xdr.Int64(1 + g.random.Int63n(1000*10000000))
+
+	changes := g.updateAccount(source, -amount, false)
+	changes = append(changes, g.updateAccount(destination, amount, false)...)
+	return generatedOperation{
+		operation: xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypePayment,
+			PaymentOp: &xdr.PaymentOp{
+				Destination: destination.account.AccountId.ToMuxedAccount(),
+				Asset:       xdr.MustNewNativeAsset(),
+				Amount:      amount,
+			},
+		}},
+		result: xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+			Type:          xdr.OperationTypePayment,
+			PaymentResult: &xdr.PaymentResult{Code: xdr.PaymentResultCodePaymentSuccess},
+		}},
+		changes: changes,
+	}
+}
+
+// trade is a manage sell offer of the source selling XLM for the generated asset that fully crosses the offer of
+// another account. It falls back to a payment when the other account has run out of the asset.
+func (g *ledgerGenerator) trade(source *generatedAccount) generatedOperation {
+	seller := g.otherAccount(source)
+	amountSold := xdr.Int64(1 + g.random.Int63n(100*10000000))
+	if seller.offer.Amount < amountSold || seller.trustline.Balance < amountSold {
+		return g.payment(source)
+	}
+	amountBought := amountSold * xdr.Int64(generatedOfferPrice.N) / xdr.Int64(generatedOfferPrice.D)
+
+	changes := g.updateOffer(seller, -amountSold)
+	changes = append(changes, g.updateTrustline(seller, -amountSold)...)
+	changes = append(changes, g.updateAccount(seller, amountBought, false)...)
+	changes = append(changes, g.updateAccount(source, -amountBought, false)...)
+	changes = append(changes, g.updateTrustline(source, amountSold)...)
+
+	claim := xdr.ClaimAtom{
+		Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+		OrderBook: &xdr.ClaimOfferAtom{
+			SellerId:     seller.account.AccountId,
+			OfferId:      seller.offer.OfferId,
+			AssetSold:    g.asset,
+			AmountSold:   amountSold,
+			AssetBought:  xdr.MustNewNativeAsset(),
+			AmountBought: amountBought,
+		},
+	}
+	return generatedOperation{
+		operation: xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: xdr.MustNewNativeAsset(),
+				Buying:  g.asset,
+				Amount:  amountBought,
+				Price:   xdr.Price{N: generatedOfferPrice.D, D: generatedOfferPrice.N},
+			},
+		}},
+		result: xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferResult: &xdr.ManageSellOfferResult{
+				Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+				Success: &xdr.ManageOfferSuccessResult{
+					OffersClaimed: []xdr.ClaimAtom{claim},
+					Offer:         xdr.ManageOfferSuccessResultOffer{Effect: xdr.ManageOfferEffectManageOfferDeleted},
+				},
+			},
+		}},
+		changes: changes,
+	}
+}
+
+// invocation is a call to the contract of the generated asset that emits eventsPerInvocation transfers between
+// contracts. Balances held by contracts are not tracked, so the invocation changes no ledger entries.
+func (g *ledgerGenerator) invocation(resourceFee xdr.Int64) generatedOperation {
+	events := make([]xdr.ContractEvent, 0, g.eventsPerInvocation)
+	for i := 0; i < g.eventsPerInvocation; i++ {
+		from := g.contracts[g.random.Intn(len(g.contracts))]
+		to := g.contracts[g.random.Intn(len(g.contracts))]
+		amount := big.NewInt(1 + g.random.Int63n(1000*10000000))
+		events = append(events, contractevents.GenerateEvent(contractevents.EventTypeTransfer, from, to, "", g.asset, amount, g.networkPassphrase))
+	}
+
+	var resultHash xdr.Hash
+	g.random.Read(resultHash[:])
+	nonRefundable := resourceFee / 2
+	return generatedOperation{
+		operation: xdr.Operation{Body: xdr.OperationBody{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{
+				HostFunction: xdr.HostFunction{
+					Type: xdr.HostFunctionTypeHostFunctionTypeInvokeContract,
+					InvokeContract: &xdr.InvokeContractArgs{
+						ContractAddress: xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &g.assetContract},
+						FunctionName:    "transfer",
+					},
+				},
+			},
+		}},
+		result: xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeInvokeHostFunction,
+			InvokeHostFunctionResult: &xdr.InvokeHostFunctionResult{
+				Code:    xdr.InvokeHostFunctionResultCodeInvokeHostFunctionSuccess,
+				Success: &resultHash,
+			},
+		}},
+		sorobanMeta: &xdr.SorobanTransactionMeta{
+			Ext: xdr.SorobanTransactionMetaExt{V: 1, V1: &xdr.SorobanTransactionMetaExtV1{
+				TotalNonRefundableResourceFeeCharged: nonRefundable,
+				TotalRefundableResourceFeeCharged:    resourceFee - nonRefundable,
+			}},
+			Events:      events,
+			ReturnValue: xdr.ScVal{Type: xdr.ScValTypeScvVoid},
+		},
+	}
+}
+
+// updateAccount changes the balance of the account, and bumps its sequence number if bumpSeq is set, returning the
+// state and updated changes of the entry
+func (g *ledgerGenerator) updateAccount(account *generatedAccount, delta xdr.Int64, bumpSeq bool) xdr.LedgerEntryChanges {
+	pre := account.account
+	account.account.Balance += delta
+	if bumpSeq {
+		account.account.SeqNum++
+	}
+	post := account.account
+	changes := entryUpdate(
+		xdr.LedgerEntry{LastModifiedLedgerSeq: account.accountModified, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &pre}},
+		xdr.LedgerEntry{LastModifiedLedgerSeq: g.ledgerSeq, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeAccount, Account: &post}},
+	)
+	account.accountModified = g.ledgerSeq
+	return changes
+}
+
+func (g *ledgerGenerator) updateTrustline(account *generatedAccount, delta xdr.Int64) xdr.LedgerEntryChanges {
+	pre := account.trustline
+	account.trustline.Balance += delta
+	post := account.trustline
+	changes := entryUpdate(
+		xdr.LedgerEntry{LastModifiedLedgerSeq: account.trustlineModified, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTrustline, TrustLine: &pre}},
+		xdr.LedgerEntry{LastModifiedLedgerSeq: g.ledgerSeq, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTrustline, TrustLine: &post}},
+	)
+	account.trustlineModified = g.ledgerSeq
+	return changes
+}
+
+func (g *ledgerGenerator) updateOffer(account *generatedAccount, delta xdr.Int64) xdr.LedgerEntryChanges {
+	pre := account.offer
+	account.offer.Amount += delta
+	post := account.offer
+	changes := entryUpdate(
+		xdr.LedgerEntry{LastModifiedLedgerSeq: account.offerModified, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: &pre}},
+		xdr.LedgerEntry{LastModifiedLedgerSeq: g.ledgerSeq, Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: &post}},
+	)
+	account.offerModified = g.ledgerSeq
+	return changes
+}
+
+func entryUpdate(pre, post xdr.LedgerEntry) xdr.LedgerEntryChanges {
+	return xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &pre},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &post},
+	}
+}
+
+// writeGeneratedLedgers writes count ledgers as one base64 encoded LedgerCloseMeta per line, the format read by the
+// ledger-file flag of bench
+func writeGeneratedLedgers(out io.Writer, generator *ledgerGenerator, count uint32) error {
+	writer := bufio.NewWriter(out)
+	for i := uint32(0); i < count; i++ {
+		lcm, err := generator.nextLedger()
+		if err != nil {
+			return err
+		}
+		encoded, err := xdr.MarshalBase64(lcm)
+		if err != nil {
+			return fmt.Errorf("could not encode ledger %d: %w", lcm.LedgerSequence(), err)
+		}
+		if _, err := fmt.Fprintln(writer, encoded); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+var generateLedgersCmd = &cobra.Command{
+	Use:   "generate_ledgers",
+	Short: "Generates synthetic ledgers for load testing",
+	Long: `Synthesizes ledger close metas with a configurable mix of native payments, trades against the order book and
+Soroban invocations emitting Stellar Asset Contract transfer events, and writes them as one base64 encoded
+LedgerCloseMeta per line, the format read by bench --ledger-file. The balances in the metas follow each other from
+transaction to transaction, so every table transforms without network data. The same seed always generates the same
+ledgers.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		if commonArgs.EndNum < startNum {
+			cmdLogger.Fatalf("end-ledger %d is before start-ledger %d", commonArgs.EndNum, startNum)
+		}
+
+		transactionsPerLedger, err := cmd.Flags().GetInt("transactions-per-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get transactions-per-ledger: ", err)
+		}
+
+		eventsPerInvocation, err := cmd.Flags().GetInt("events-per-invocation")
+		if err != nil {
+			cmdLogger.Fatal("could not get events-per-invocation: ", err)
+		}
+
+		accounts, err := cmd.Flags().GetInt("accounts")
+		if err != nil {
+			cmdLogger.Fatal("could not get accounts: ", err)
+		}
+
+		seed, err := cmd.Flags().GetInt64("seed")
+		if err != nil {
+			cmdLogger.Fatal("could not get seed: ", err)
+		}
+
+		weights, err := cmd.Flags().GetStringToInt("tx-mix")
+		if err != nil {
+			cmdLogger.Fatal("could not get tx-mix: ", err)
+		}
+		mix, err := parseTransactionMix(weights)
+		if err != nil {
+			cmdLogger.Fatal("could not parse tx-mix: ", err)
+		}
+
+		generator, err := newLedgerGenerator(env.NetworkPassphrase, seed, startNum, accounts, transactionsPerLedger, eventsPerInvocation, mix)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger generator: ", err)
+		}
+
+		out := cmd.OutOrStdout()
+		if path != "" && path != "-" {
+			outFile := MustOutFile(path)
+			defer outFile.Close()
+			out = outFile
+		}
+
+		if err := writeGeneratedLedgers(out, generator, commonArgs.EndNum-startNum+1); err != nil {
+			cmdLogger.Fatal("could not generate ledgers: ", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(generateLedgersCmd)
+	utils.AddCommonFlags(generateLedgersCmd.Flags())
+	utils.AddArchiveFlags("ledgers", generateLedgersCmd.Flags())
+	generateLedgersCmd.Flags().Int("transactions-per-ledger", 100, "Number of transactions in each generated ledger")
+	generateLedgersCmd.Flags().StringToInt("tx-mix", map[string]int{"payments": 60, "trades": 25, "invocations": 15}, "Relative weights of payments, trades and invocations among the generated transactions")
+	generateLedgersCmd.Flags().Int("events-per-invocation", 4, "Number of contract events emitted by each generated invocation")
+	generateLedgersCmd.Flags().Int("accounts", 100, "Number of accounts between which the generated transactions move balances")
+	generateLedgersCmd.Flags().Int64("seed", 0, "Seed of the generated ledgers; the same seed always generates the same ledgers")
+	generateLedgersCmd.Flags().Lookup("output").DefValue = "-"
+	generateLedgersCmd.Flags().Set("output", "-")
+	generateLedgersCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number of the first generated ledger
+			end-ledger: the ledger sequence number of the last generated ledger
+
+			transactions-per-ledger: number of transactions in each ledger
+			tx-mix: relative weights of payments, trades and invocations
+			events-per-invocation: number of contract events emitted by each invocation
+			accounts: number of accounts the transactions move balances between
+			seed: seed of the generated ledgers
+
+			output-file: filename of the generated ledgers; stdout by default
+	*/
+}
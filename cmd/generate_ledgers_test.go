@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+)
+
+func generateTestLedgers(t *testing.T, seed int64) []byte {
+	mix := transactionMix{Payments: 2, Trades: 1, Invocations: 1}
+	generator, err := newLedgerGenerator(network.TestNetworkPassphrase, seed, 100, 10, 20, 3, mix)
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, writeGeneratedLedgers(&out, generator, 3))
+	return out.Bytes()
+}
+
+func TestGenerateLedgers(t *testing.T) {
+	generated := generateTestLedgers(t, 1)
+	assert.Equal(t, generated, generateTestLedgers(t, 1))
+	assert.NotEqual(t, generated, generateTestLedgers(t, 2))
+
+	path := filepath.Join(t.TempDir(), "ledgers.txt")
+	require.NoError(t, os.WriteFile(path, generated, 0644))
+	lcms, err := readLedgerCloseMetaFile(path)
+	require.NoError(t, err)
+	require.Len(t, lcms, 3)
+	for i, lcm := range lcms {
+		assert.Equal(t, uint32(100+i), lcm.LedgerSequence())
+		assert.Equal(t, 20, lcm.CountTransactions())
+	}
+	assert.Equal(t, lcms[0].LedgerHeaderHistoryEntry().Hash, lcms[1].PreviousLedgerHash())
+
+	ledgers, err := prepareBenchLedgers(lcms, network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	for _, table := range input.LedgerTables() {
+		result := runBenchTable(table, ledgers, network.TestNetworkPassphrase, 1)
+		assert.Zero(t, result.Failures, table.Name)
+		switch table.Name {
+		case "transactions", "operations", "effects", "trades", "contract_events", "token_transfers", "accounts", "trustlines", "offers":
+			assert.NotZero(t, result.Rows, table.Name)
+		}
+	}
+}
+
+func TestParseTransactionMix(t *testing.T) {
+	mix, err := parseTransactionMix(map[string]int{"payments": 3, "invocations": 1})
+	require.NoError(t, err)
+	assert.Equal(t, transactionMix{Payments: 3, Invocations: 1}, mix)
+
+	_, err = parseTransactionMix(map[string]int{"swaps": 1})
+	assert.ErrorContains(t, err, "unknown transaction kind swaps")
+
+	_, err = parseTransactionMix(map[string]int{"payments": -1})
+	assert.ErrorContains(t, err, "negative")
+
+	_, err = parseTransactionMix(map[string]int{"payments": 0})
+	assert.ErrorContains(t, err, "positive weight")
+}
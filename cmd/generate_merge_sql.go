@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// mergeTable is a state table exported by export_ledger_entry_changes along with the columns that identify a ledger entry
+type mergeTable struct {
+	output interface{}
+	keys   []string
+}
+
+var mergeTables = map[string]mergeTable{
+	"accounts":           {output: outputTables["accounts"], keys: []string{"account_id"}},
+	"account_data":       {output: outputTables["account_data"], keys: []string{"account_id", "data_name"}},
+	"signers":            {output: outputTables["signers"], keys: []string{"account_id", "signer"}},
+	"trustlines":         {output: outputTables["trustlines"], keys: []string{"ledger_key"}},
+	"offers":             {output: outputTables["offers"], keys: []string{"offer_id"}},
+	"liquidity_pools":    {output: outputTables["liquidity_pools"], keys: []string{"liquidity_pool_id"}},
+	"pool_share_holders": {output: outputTables["pool_share_holders"], keys: []string{"ledger_key"}},
+	"claimable_balances": {output: outputTables["claimable_balances"], keys: []string{"balance_id"}},
+	"contract_data":      {output: outputTables["contract_data"], keys: []string{"ledger_key_hash"}},
+	"contract_balances":  {output: outputTables["contract_balances"], keys: []string{"ledger_key_hash"}},
+	"contract_code":      {output: outputTables["contract_code"], keys: []string{"ledger_key_hash"}},
+	"config_settings":    {output: outputTables["config_settings"], keys: []string{"config_setting_id"}},
+	"ttl":                {output: outputTables["ttl"], keys: []string{"key_hash"}},
+}
+
+var generateMergeSQLCmd = &cobra.Command{
+	Use:   "generate_merge_sql",
+	Short: "Generates BigQuery MERGE statements that apply ledger entry changes to state tables",
+	Long: `Generates a BigQuery MERGE statement per state table that upserts the rows exported by export_ledger_entry_changes
+into a table holding the current state of every ledger entry. The changes are read from <dataset>.<table> and merged
+into <dataset>.<table><target-suffix>. The latest change of each ledger entry wins and deleted entries are removed.
+The statements are idempotent, so loading the same or an older batch again leaves the state tables unchanged.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+
+		dataset, err := cmd.Flags().GetString("dataset")
+		if err != nil {
+			cmdLogger.Fatal("could not get dataset: ", err)
+		}
+
+		tables, err := cmd.Flags().GetStringSlice("tables")
+		if err != nil {
+			cmdLogger.Fatal("could not get tables: ", err)
+		}
+
+		targetSuffix, err := cmd.Flags().GetString("target-suffix")
+		if err != nil {
+			cmdLogger.Fatal("could not get target-suffix: ", err)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output: ", err)
+		}
+
+		if len(tables) == 0 {
+			for table := range mergeTables {
+				tables = append(tables, table)
+			}
+			sort.Strings(tables)
+		}
+
+		statements := []string{}
+		for _, table := range tables {
+			mergeTable, ok := mergeTables[table]
+			if !ok {
+				cmdLogger.Fatalf("unknown state table %s", table)
+			}
+			source := fmt.Sprintf("%s.%s", dataset, table)
+			target := source + targetSuffix
+			statements = append(statements, mergeSQL(mergeTable, source, target))
+		}
+
+		out := cmd.OutOrStdout()
+		if path != "" && path != "-" {
+			outFile := MustOutFile(path)
+			defer outFile.Close()
+			out = outFile
+		}
+
+		if _, err := io.WriteString(out, strings.Join(statements, "\n")); err != nil {
+			cmdLogger.Fatal("could not write statements: ", err)
+		}
+	},
+}
+
+// outputColumns returns the json names of the fields of an output struct, which are the columns of its table
+func outputColumns(output interface{}) []string {
+	outputType := reflect.TypeOf(output)
+	columns := []string{}
+	for i := 0; i < outputType.NumField(); i++ {
+		name := strings.Split(outputType.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// mergeSQL builds the MERGE of a state table. The changes are deduplicated to the latest one per ledger entry,
+// ordered by the ledger of the change first since deleted rows keep the last_modified_ledger of the removed entry.
+func mergeSQL(table mergeTable, source, target string) string {
+	columns := outputColumns(table.output)
+
+	on := make([]string, len(table.keys))
+	for i, key := range table.keys {
+		on[i] = fmt.Sprintf("target.%s = source.%s", key, key)
+	}
+
+	set := make([]string, 0, len(columns))
+	values := make([]string, len(columns))
+	for i, column := range columns {
+		values[i] = "source." + column
+		if !slices.Contains(table.keys, column) {
+			set = append(set, fmt.Sprintf("    %s = source.%s", column, column))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "MERGE `%s` AS target\n", target)
+	fmt.Fprintf(&b, "USING (\n")
+	fmt.Fprintf(&b, "  SELECT * EXCEPT (row_number)\n")
+	fmt.Fprintf(&b, "  FROM (\n")
+	fmt.Fprintf(&b, "    SELECT *, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS row_number\n", strings.Join(table.keys, ", "))
+	fmt.Fprintf(&b, "    FROM `%s`\n", source)
+	fmt.Fprintf(&b, "  )\n")
+	fmt.Fprintf(&b, "  WHERE row_number = 1\n")
+	fmt.Fprintf(&b, ") AS source\n")
+	fmt.Fprintf(&b, "ON %s\n", strings.Join(on, " AND "))
+	fmt.Fprintf(&b, "WHEN MATCHED AND source.deleted AND source.ledger_sequence >= target.last_modified_ledger THEN\n")
+	fmt.Fprintf(&b, "  DELETE\n")
+	fmt.Fprintf(&b, "WHEN MATCHED AND NOT source.deleted AND source.last_modified_ledger >= target.last_modified_ledger THEN\n")
+	fmt.Fprintf(&b, "  UPDATE SET\n%s\n", strings.Join(set, ",\n"))
+	fmt.Fprintf(&b, "WHEN NOT MATCHED AND NOT source.deleted THEN\n")
+	fmt.Fprintf(&b, "  INSERT (%s)\n", strings.Join(columns, ", "))
+	fmt.Fprintf(&b, "  VALUES (%s);\n", strings.Join(values, ", "))
+
+	return b.String()
+}
+
+func init() {
+	rootCmd.AddCommand(generateMergeSQLCmd)
+	generateMergeSQLCmd.Flags().String("dataset", "", "BigQuery dataset, as project.dataset, that holds the exported changes and the state tables")
+	generateMergeSQLCmd.Flags().StringSlice("tables", nil, "State tables to generate statements for; all of them if empty")
+	generateMergeSQLCmd.Flags().String("target-suffix", "_current", "Suffix of the state tables the changes are merged into")
+	generateMergeSQLCmd.Flags().StringP("output", "o", "-", "Filename of the statements; stdout by default")
+	generateMergeSQLCmd.MarkFlagRequired("dataset")
+
+	/*
+		Current flags:
+			dataset: BigQuery dataset that holds the exported changes and the state tables
+			tables: state tables to generate statements for
+			target-suffix: suffix of the state tables the changes are merged into
+
+			output: filename of the statements; stdout by default
+	*/
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSQL(t *testing.T) {
+	expected := "MERGE `project.dataset.ttl_current` AS target\n" +
+		"USING (\n" +
+		"  SELECT * EXCEPT (row_number)\n" +
+		"  FROM (\n" +
+		"    SELECT *, ROW_NUMBER() OVER (PARTITION BY key_hash ORDER BY ledger_sequence DESC, last_modified_ledger DESC) AS row_number\n" +
+		"    FROM `project.dataset.ttl`\n" +
+		"  )\n" +
+		"  WHERE row_number = 1\n" +
+		") AS source\n" +
+		"ON target.key_hash = source.key_hash\n" +
+		"WHEN MATCHED AND source.deleted AND source.ledger_sequence >= target.last_modified_ledger THEN\n" +
+		"  DELETE\n" +
+		"WHEN MATCHED AND NOT source.deleted AND source.last_modified_ledger >= target.last_modified_ledger THEN\n" +
+		"  UPDATE SET\n" +
+		"    ledger_key = source.ledger_key,\n" +
+		"    ledger_key_canonical = source.ledger_key_canonical,\n" +
+		"    live_until_ledger_seq = source.live_until_ledger_seq,\n" +
+		"    last_modified_ledger = source.last_modified_ledger,\n" +
+		"    ledger_entry_change = source.ledger_entry_change,\n" +
+		"    deleted = source.deleted,\n" +
+		"    closed_at = source.closed_at,\n" +
+		"    ledger_sequence = source.ledger_sequence\n" +
+		"WHEN NOT MATCHED AND NOT source.deleted THEN\n" +
+		"  INSERT (key_hash, ledger_key, ledger_key_canonical, live_until_ledger_seq, last_modified_ledger, ledger_entry_change, deleted, closed_at, ledger_sequence)\n" +
+		"  VALUES (source.key_hash, source.ledger_key, source.ledger_key_canonical, source.live_until_ledger_seq, source.last_modified_ledger, source.ledger_entry_change, source.deleted, source.closed_at, source.ledger_sequence);\n"
+
+	assert.Equal(t, expected, mergeSQL(mergeTables["ttl"], "project.dataset.ttl", "project.dataset.ttl_current"))
+}
+
+func TestMergeTablesColumns(t *testing.T) {
+	for name, table := range mergeTables {
+		columns := outputColumns(table.output)
+		for _, column := range append([]string{"last_modified_ledger", "ledger_sequence", "deleted"}, table.keys...) {
+			assert.Contains(t, columns, column, "table %s", name)
+		}
+	}
+}
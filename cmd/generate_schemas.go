@@ -0,0 +1,277 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/pkg/schema"
+)
+
+// outputTables are the tables exported by stellar-etl along with the struct of their rows
+var outputTables = schema.Tables()
+
+var schemaFormats = map[string]struct {
+	extension string
+	generate  func(table string, output interface{}, fields []transform.SchemaField) interface{}
+}{
+	"bigquery": {extension: ".json", generate: bigQuerySchema},
+	"avro":     {extension: ".avsc", generate: avroSchema},
+	"arrow":    {extension: ".json", generate: arrowSchema},
+	"proto":    {extension: ".proto", generate: protoSchema},
+}
+
+var generateSchemasCmd = &cobra.Command{
+	Use:     "generate_schemas",
+	Aliases: []string{"generate-schemas"},
+	Short:   "Generates BigQuery, Avro, Arrow and protobuf schemas of the exported tables",
+	Long: `Generates machine-readable schema files of the exported tables from the structs of their rows, so that warehouse
+DDL can be kept in sync with the code. Field comments in internal/transform/schema.go are used as column descriptions.
+A folder is created in the output folder for each format: bigquery holds BigQuery JSON schemas, avro holds Avro
+schemas, arrow holds Arrow schemas in the JSON format of the Arrow integration tests and proto holds the proto3
+definitions of the records written with --output-format proto.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+
+		outputFolder, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output: ", err)
+		}
+
+		formats, err := cmd.Flags().GetStringSlice("formats")
+		if err != nil {
+			cmdLogger.Fatal("could not get formats: ", err)
+		}
+
+		tables, err := cmd.Flags().GetStringSlice("tables")
+		if err != nil {
+			cmdLogger.Fatal("could not get tables: ", err)
+		}
+
+		if len(tables) == 0 {
+			for table := range outputTables {
+				tables = append(tables, table)
+			}
+			sort.Strings(tables)
+		}
+
+		for _, format := range formats {
+			schemaFormat, ok := schemaFormats[format]
+			if !ok {
+				cmdLogger.Fatalf("unknown schema format %s; expected bigquery, avro, arrow or proto", format)
+			}
+
+			for _, table := range tables {
+				output, ok := outputTables[table]
+				if !ok {
+					cmdLogger.Fatalf("unknown table %s", table)
+				}
+
+				schema := schemaFormat.generate(table, output, transform.SchemaFields(output))
+				path := filepath.Join(outputFolder, format, table+schemaFormat.extension)
+				if err := writeSchema(path, schema); err != nil {
+					cmdLogger.Fatalf("could not write schema %s: %v", path, err)
+				}
+			}
+		}
+
+		cmdLogger.Infof("Wrote %s schemas of %d tables to %s", strings.Join(formats, ", "), len(tables), outputFolder)
+	},
+}
+
+func writeSchema(path string, schema interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	if definition, ok := schema.(string); ok {
+		return os.WriteFile(path, []byte(definition), 0644)
+	}
+
+	marshalled, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(marshalled, '\n'), 0644)
+}
+
+type bigQueryField struct {
+	Name        string          `json:"name"`
+	Type        string          `json:"type"`
+	Mode        string          `json:"mode"`
+	Description string          `json:"description,omitempty"`
+	Fields      []bigQueryField `json:"fields,omitempty"`
+}
+
+// bigQuerySchema is the BigQuery JSON schema of a table. Columns are nullable since rows are loaded from json
+// where empty fields can be left out.
+func bigQuerySchema(table string, output interface{}, fields []transform.SchemaField) interface{} {
+	return bigQueryFields(fields)
+}
+
+func bigQueryFields(fields []transform.SchemaField) []bigQueryField {
+	if len(fields) == 0 {
+		return nil
+	}
+	bqFields := make([]bigQueryField, len(fields))
+	for i, field := range fields {
+		mode := "NULLABLE"
+		if field.Repeated {
+			mode = "REPEATED"
+		}
+		bqFields[i] = bigQueryField{
+			Name:        field.Name,
+			Type:        field.Type,
+			Mode:        mode,
+			Description: field.Description,
+			Fields:      bigQueryFields(field.Fields),
+		}
+	}
+	return bqFields
+}
+
+// avroSchema is the Avro record schema of a table. Json columns are strings holding the serialized json.
+func avroSchema(table string, output interface{}, fields []transform.SchemaField) interface{} {
+	record := map[string]interface{}{
+		"type":      "record",
+		"name":      table,
+		"namespace": "stellar_etl",
+		"fields":    avroFields(fields, map[string]bool{}),
+	}
+	if doc := transform.SchemaDocs(output).Doc; doc != "" {
+		record["doc"] = doc
+	}
+	return record
+}
+
+func avroFields(fields []transform.SchemaField, definedRecords map[string]bool) []map[string]interface{} {
+	columns := make([]map[string]interface{}, len(fields))
+	for i, field := range fields {
+		var avroType interface{}
+		switch field.Type {
+		case "STRING", "JSON":
+			avroType = "string"
+		case "INTEGER":
+			avroType = "long"
+		case "FLOAT":
+			avroType = "double"
+		case "BOOLEAN":
+			avroType = "boolean"
+		case "BYTES":
+			avroType = "bytes"
+		case "TIMESTAMP":
+			avroType = map[string]string{"type": "long", "logicalType": "timestamp-micros"}
+		case "RECORD":
+			// Named records can only be defined once per schema and are referenced by name afterwards
+			if definedRecords[field.RecordName] {
+				avroType = field.RecordName
+			} else {
+				definedRecords[field.RecordName] = true
+				avroType = map[string]interface{}{
+					"type":   "record",
+					"name":   field.RecordName,
+					"fields": avroFields(field.Fields, definedRecords),
+				}
+			}
+		}
+
+		if field.Repeated {
+			avroType = map[string]interface{}{"type": "array", "items": avroType}
+		}
+
+		avroField := map[string]interface{}{"name": field.Name, "type": avroType}
+		if field.Nullable {
+			avroField["type"] = []interface{}{"null", avroType}
+			avroField["default"] = nil
+		}
+		if field.Description != "" {
+			avroField["doc"] = field.Description
+		}
+		columns[i] = avroField
+	}
+	return columns
+}
+
+type arrowField struct {
+	Name     string              `json:"name"`
+	Nullable bool                `json:"nullable"`
+	Type     map[string]string   `json:"type"`
+	Children []arrowField        `json:"children"`
+	Metadata []map[string]string `json:"metadata,omitempty"`
+}
+
+// arrowSchema is the Arrow schema of a table in the json format used by the Arrow integration tests
+func arrowSchema(table string, output interface{}, fields []transform.SchemaField) interface{} {
+	return map[string]interface{}{"fields": arrowFields(fields)}
+}
+
+func arrowFields(fields []transform.SchemaField) []arrowField {
+	columns := make([]arrowField, len(fields))
+	for i, field := range fields {
+		columns[i] = arrowColumn(field)
+	}
+	return columns
+}
+
+func arrowColumn(field transform.SchemaField) arrowField {
+	column := arrowField{Name: field.Name, Nullable: field.Nullable, Children: []arrowField{}}
+	if field.Description != "" {
+		column.Metadata = []map[string]string{{"key": "description", "value": field.Description}}
+	}
+
+	switch field.Type {
+	case "STRING", "JSON":
+		column.Type = map[string]string{"name": "utf8"}
+	case "INTEGER":
+		column.Type = map[string]string{"name": "int", "bitWidth": "64", "isSigned": "true"}
+	case "FLOAT":
+		column.Type = map[string]string{"name": "floatingpoint", "precision": "DOUBLE"}
+	case "BOOLEAN":
+		column.Type = map[string]string{"name": "bool"}
+	case "BYTES":
+		column.Type = map[string]string{"name": "binary"}
+	case "TIMESTAMP":
+		column.Type = map[string]string{"name": "timestamp", "unit": "MICROSECOND", "timezone": "UTC"}
+	case "RECORD":
+		column.Type = map[string]string{"name": "struct"}
+		column.Children = arrowFields(field.Fields)
+	default:
+		panic(fmt.Sprintf("unknown column type %s", field.Type))
+	}
+
+	if field.Repeated {
+		item := column
+		item.Name = "item"
+		item.Metadata = nil
+		column.Type = map[string]string{"name": "list"}
+		column.Children = []arrowField{item}
+	}
+
+	return column
+}
+
+// protoSchema is the proto3 definition of the records of a table
+func protoSchema(table string, output interface{}, fields []transform.SchemaField) interface{} {
+	return transform.ProtoDefinition(output)
+}
+
+func init() {
+	rootCmd.AddCommand(generateSchemasCmd)
+	generateSchemasCmd.Flags().StringP("output", "o", "schemas", "Folder that will contain the schema files")
+	generateSchemasCmd.Flags().StringSlice("formats", []string{"bigquery", "avro", "arrow", "proto"}, "Schema formats to generate: bigquery, avro, arrow and proto")
+	generateSchemasCmd.Flags().StringSlice("tables", nil, "Tables to generate schemas for; all of them if empty")
+
+	/*
+		Current flags:
+			output: folder that will contain the schema files
+			formats: schema formats to generate
+			tables: tables to generate schemas for
+	*/
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaFormats(t *testing.T) {
+	fields := []transform.SchemaField{
+		{Name: "id", Type: "INTEGER", Description: "id of the row"},
+		{Name: "sponsor", Type: "STRING", Nullable: true},
+		{Name: "signers", Type: "STRING", Repeated: true},
+	}
+
+	assert.Equal(t, []bigQueryField{
+		{Name: "id", Type: "INTEGER", Mode: "NULLABLE", Description: "id of the row"},
+		{Name: "sponsor", Type: "STRING", Mode: "NULLABLE"},
+		{Name: "signers", Type: "STRING", Mode: "REPEATED"},
+	}, bigQuerySchema("example", struct{}{}, fields))
+
+	assert.Equal(t, map[string]interface{}{
+		"type":      "record",
+		"name":      "example",
+		"namespace": "stellar_etl",
+		"fields": []map[string]interface{}{
+			{"name": "id", "type": "long", "doc": "id of the row"},
+			{"name": "sponsor", "type": []interface{}{"null", "string"}, "default": nil},
+			{"name": "signers", "type": map[string]interface{}{"type": "array", "items": "string"}},
+		},
+	}, avroSchema("example", struct{}{}, fields))
+
+	assert.Equal(t, map[string]interface{}{
+		"fields": []arrowField{
+			{
+				Name:     "id",
+				Type:     map[string]string{"name": "int", "bitWidth": "64", "isSigned": "true"},
+				Children: []arrowField{},
+				Metadata: []map[string]string{{"key": "description", "value": "id of the row"}},
+			},
+			{Name: "sponsor", Nullable: true, Type: map[string]string{"name": "utf8"}, Children: []arrowField{}},
+			{
+				Name:     "signers",
+				Type:     map[string]string{"name": "list"},
+				Children: []arrowField{{Name: "item", Type: map[string]string{"name": "utf8"}, Children: []arrowField{}}},
+			},
+		},
+	}, arrowSchema("example", struct{}{}, fields))
+}
+
+func TestOutputTablesSchemas(t *testing.T) {
+	for table, output := range outputTables {
+		for format, schemaFormat := range schemaFormats {
+			assert.NotPanics(t, func() { schemaFormat.generate(table, output, transform.SchemaFields(output)) }, "%s %s", table, format)
+		}
+	}
+}
+
+func TestProtoRecordsUpToDate(t *testing.T) {
+	for table, output := range outputTables {
+		committed, err := os.ReadFile(filepath.Join("proto", "stellar_etl", "records", "v1", table+".proto"))
+		require.NoError(t, err, table)
+		assert.Equal(t, transform.ProtoDefinition(output), string(committed), "%s.proto is out of date, run make proto-records", table)
+	}
+}
@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// exportHealth tracks the progress of the streaming exports so that orchestrators can restart an exporter
+// that is stuck or can no longer write its output
+type exportHealth struct {
+	mu           sync.Mutex
+	maxLedgerAge time.Duration
+	networks     map[string]*networkHealth
+	now          func() time.Time
+}
+
+// networkHealth is the state of the export of a single network
+type networkHealth struct {
+	health     *exportHealth
+	ready      bool
+	lastLedger uint32
+	// lastLedgerClose is the close time of the last exported ledger, and lastProcessed the time it was exported
+	lastLedgerClose time.Time
+	lastProcessed   time.Time
+	lastSinkError   string
+	// lastQualityError is why the last batch failed the quality checks, which keeps it from being written
+	lastQualityError string
+	startedAt        time.Time
+	backendFailure   string
+}
+
+type networkHealthResponse struct {
+	Network          string  `json:"network"`
+	Ready            bool    `json:"ready"`
+	Healthy          bool    `json:"healthy"`
+	LastLedger       uint32  `json:"last_ledger"`
+	LastLedgerAgeSec float64 `json:"last_ledger_age_seconds"`
+	LastExportAgeSec float64 `json:"last_export_age_seconds"`
+	BackendError     string  `json:"backend_error,omitempty"`
+	SinkError        string  `json:"sink_error,omitempty"`
+	QualityError     string  `json:"quality_error,omitempty"`
+}
+
+func newExportHealth(maxLedgerAge time.Duration) *exportHealth {
+	return &exportHealth{
+		maxLedgerAge: maxLedgerAge,
+		networks:     map[string]*networkHealth{},
+		now:          time.Now,
+	}
+}
+
+// network registers a network whose export is reported by the endpoints
+func (h *exportHealth) network(name string) *networkHealth {
+	if h == nil {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n := &networkHealth{health: h, startedAt: h.now()}
+	h.networks[name] = n
+	return n
+}
+
+// backendReady records that the ledger backend was prepared, or why it could not be
+func (n *networkHealth) backendReady(err error) {
+	if n == nil {
+		return
+	}
+
+	n.health.mu.Lock()
+	defer n.health.mu.Unlock()
+	n.ready = err == nil
+	n.backendFailure = ""
+	if err != nil {
+		n.backendFailure = err.Error()
+	}
+}
+
+// readFailed records that the ledgers could no longer be read from the backend partway through the export
+func (n *networkHealth) readFailed(err error) {
+	if n == nil || err == nil {
+		return
+	}
+
+	n.health.mu.Lock()
+	defer n.health.mu.Unlock()
+	n.backendFailure = err.Error()
+}
+
+// batchRejected records that a batch failed the quality checks, so it was not written to the sinks
+func (n *networkHealth) batchRejected(err error) {
+	if n == nil {
+		return
+	}
+
+	n.health.mu.Lock()
+	defer n.health.mu.Unlock()
+	n.lastQualityError = err.Error()
+}
+
+// batchExported records the result of writing a batch to the sinks, with the close time of its last ledger
+func (n *networkHealth) batchExported(batchEnd uint32, closeTime time.Time, err error) {
+	if n == nil {
+		return
+	}
+
+	n.health.mu.Lock()
+	defer n.health.mu.Unlock()
+	if err != nil {
+		n.lastSinkError = err.Error()
+		return
+	}
+	n.lastSinkError = ""
+	n.lastQualityError = ""
+	n.lastLedger = batchEnd
+	n.lastLedgerClose = closeTime
+	n.lastProcessed = n.health.now()
+}
+
+func (h *exportHealth) status() ([]networkHealthResponse, bool, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	now := h.now()
+	allReady, allHealthy := true, true
+	responses := []networkHealthResponse{}
+	for name, n := range h.networks {
+		// A network that has not exported anything yet is measured from the time it started
+		lastLedgerClose, lastProcessed := n.lastLedgerClose, n.lastProcessed
+		if lastProcessed.IsZero() {
+			lastLedgerClose, lastProcessed = n.startedAt, n.startedAt
+		}
+		ledgerAge := now.Sub(lastLedgerClose)
+		exportAge := now.Sub(lastProcessed)
+
+		// An export is stuck once its last ledger is too old. Backfills of old ledgers stay healthy while they
+		// keep exporting batches.
+		stuck := ledgerAge > h.maxLedgerAge && exportAge > h.maxLedgerAge
+		healthy := n.backendFailure == "" && n.lastSinkError == "" && n.lastQualityError == "" && !stuck
+		allReady = allReady && n.ready
+		allHealthy = allHealthy && healthy
+		responses = append(responses, networkHealthResponse{
+			Network:          name,
+			Ready:            n.ready,
+			Healthy:          healthy,
+			LastLedger:       n.lastLedger,
+			LastLedgerAgeSec: ledgerAge.Seconds(),
+			LastExportAgeSec: exportAge.Seconds(),
+			BackendError:     n.backendFailure,
+			SinkError:        n.lastSinkError,
+			QualityError:     n.lastQualityError,
+		})
+	}
+	sort.Slice(responses, func(i, j int) bool { return responses[i].Network < responses[j].Network })
+
+	return responses, allReady, allHealthy
+}
+
+func (h *exportHealth) handler() http.Handler {
+	writeStatus := func(w http.ResponseWriter, ok bool, responses []networkHealthResponse) {
+		w.Header().Set("Content-Type", "application/json")
+		if !ok {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(responses)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		responses, _, healthy := h.status()
+		writeStatus(w, healthy, responses)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		responses, ready, _ := h.status()
+		writeStatus(w, ready, responses)
+	})
+	return mux
+}
+
+// serveHealth starts the health endpoints in the background. An empty address disables them.
+func serveHealth(addr string, maxLedgerAge time.Duration) *exportHealth {
+	if addr == "" {
+		return nil
+	}
+
+	health := newExportHealth(maxLedgerAge)
+	go func() {
+		if err := http.ListenAndServe(addr, health.handler()); err != nil {
+			cmdLogger.Fatalf("could not serve health endpoints on %s: %v", addr, err)
+		}
+	}()
+	cmdLogger.Infof("Serving /healthz and /readyz on %s", addr)
+
+	return health
+}
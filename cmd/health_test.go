@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestExportHealth(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	health := newExportHealth(10 * time.Minute)
+	health.now = func() time.Time { return now }
+	handler := health.handler()
+
+	get := func(path string) (int, []networkHealthResponse) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		var responses []networkHealthResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &responses))
+		return recorder.Code, responses
+	}
+
+	pubnet := health.network("pubnet")
+	testnet := health.network("testnet")
+
+	code, _ := get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+
+	pubnet.backendReady(nil)
+	testnet.backendReady(nil)
+	code, _ = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+
+	now = now.Add(5 * time.Minute)
+	pubnet.batchExported(64, now.Add(-5*time.Second), nil)
+	testnet.batchExported(128, now.Add(-10*time.Second), nil)
+	code, responses := get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []networkHealthResponse{
+		{Network: "pubnet", Ready: true, Healthy: true, LastLedger: 64, LastLedgerAgeSec: 5},
+		{Network: "testnet", Ready: true, Healthy: true, LastLedger: 128, LastLedgerAgeSec: 10},
+	}, responses)
+
+	// A failed upload keeps the last exported ledger and reports the sink error
+	now = now.Add(time.Minute)
+	testnet.batchExported(192, now, errors.New("unable to upload"))
+	code, responses = get("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, networkHealthResponse{Network: "testnet", Ready: true, Healthy: false, LastLedger: 128, LastLedgerAgeSec: 70, LastExportAgeSec: 60, SinkError: "unable to upload"}, responses[1])
+
+	// A stuck export becomes unhealthy once its last ledger is older than the max ledger age and no batch was
+	// exported since
+	testnet.batchExported(192, now, nil)
+	now = now.Add(11 * time.Minute)
+	code, responses = get("/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.False(t, responses[0].Healthy)
+	assert.Equal(t, float64(12*60+5), responses[0].LastLedgerAgeSec)
+	assert.Equal(t, float64(12*60), responses[0].LastExportAgeSec)
+}
+
+func TestExportHealthBackfill(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	health := newExportHealth(10 * time.Minute)
+	health.now = func() time.Time { return now }
+	network := health.network("pubnet")
+	network.backendReady(nil)
+
+	// Ledgers closed a year ago are exported every minute, so the export is making progress
+	closeTime := now.AddDate(-1, 0, 0)
+	for batchEnd := uint32(64); batchEnd <= 640; batchEnd += 64 {
+		now = now.Add(time.Minute)
+		closeTime = closeTime.Add(5 * time.Minute)
+		network.batchExported(batchEnd, closeTime, nil)
+	}
+	responses, _, healthy := health.status()
+	assert.True(t, healthy)
+	assert.Equal(t, now.Sub(closeTime).Seconds(), responses[0].LastLedgerAgeSec)
+
+	// It is stuck once it stops exporting
+	now = now.Add(11 * time.Minute)
+	_, _, healthy = health.status()
+	assert.False(t, healthy)
+}
+
+func TestExportHealthFailures(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	health := newExportHealth(10 * time.Minute)
+	health.now = func() time.Time { return now }
+	network := health.network("pubnet")
+	network.backendReady(nil)
+	network.batchExported(64, now, nil)
+
+	// A batch that fails the quality checks is reported on its own, and the next exported batch clears it
+	network.batchRejected(errors.New("batch 65-128 failed the quality checks"))
+	responses, ready, healthy := health.status()
+	assert.True(t, ready)
+	assert.False(t, healthy)
+	assert.Equal(t, "batch 65-128 failed the quality checks", responses[0].QualityError)
+	assert.Empty(t, responses[0].SinkError)
+	network.batchExported(192, now, nil)
+	_, _, healthy = health.status()
+	assert.True(t, healthy)
+
+	// The backend failing partway through the stream is a backend error, while the ledgers prepared before it
+	// leave the export ready
+	network.readFailed(errors.New("error getting ledger 193"))
+	responses, ready, healthy = health.status()
+	assert.True(t, ready)
+	assert.False(t, healthy)
+	assert.Equal(t, "error getting ledger 193", responses[0].BackendError)
+}
+
+func TestPrepareChangesBackendReportsFailures(t *testing.T) {
+	health := newExportHealth(time.Minute)
+	network := health.network("testnet")
+
+	// The tests run without GCP credentials, so the backend keeps failing until the export stops
+	env := utils.GetEnvironmentDetails(utils.CommonFlagValues{IsTest: true})
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := prepareChangesBackend(ctx, env, 100, 200, utils.NewEtlLogger(), network)
+	assert.ErrorContains(t, err, "error creating a cloud storage backend")
+
+	responses, ready, healthy := health.status()
+	assert.False(t, ready)
+	assert.False(t, healthy)
+	assert.NotEmpty(t, responses[0].BackendError)
+}
+
+func TestExportHealthDisabled(t *testing.T) {
+	health := serveHealth("", time.Minute)
+	assert.Nil(t, health)
+
+	// The export reports to a disabled health server without any checks
+	network := health.network("pubnet")
+	network.backendReady(nil)
+	network.batchExported(64, time.Now(), nil)
+	network.batchRejected(errors.New("rejected"))
+	network.readFailed(errors.New("unreadable"))
+	assert.Nil(t, network)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/support/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func readCachedFile(t *testing.T, store datastore.DataStore, path string) []byte {
+	reader, err := store.GetFile(context.Background(), path)
+	require.NoError(t, err)
+	defer reader.Close()
+	contents, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return contents
+}
+
+func TestLedgerCache(t *testing.T) {
+	key := archiveTestSchema.GetObjectKeyFromSequenceNumber
+	files := map[string][][]byte{}
+	for sequence := uint32(2); sequence <= 4; sequence++ {
+		files[key(sequence)] = [][]byte{archiveTestFile(t, sequence, false)}
+	}
+	store := &archiveTestStore{files: files, downloads: map[string]int{}}
+	size := int64(len(files[key(2)][0]))
+	dir := t.TempDir()
+
+	// the cache holds two files
+	cached, err := utils.WithLedgerCache(store, dir, "testnet", 2*size+1)
+	require.NoError(t, err)
+	assert.Equal(t, files[key(2)][0], readCachedFile(t, cached, key(2)))
+	assert.Equal(t, files[key(2)][0], readCachedFile(t, cached, key(2)))
+	assert.Equal(t, 1, store.downloads[key(2)])
+
+	readCachedFile(t, cached, key(3))
+	readCachedFile(t, cached, key(2))
+	// the least recently read file is removed for the third one
+	readCachedFile(t, cached, key(4))
+	readCachedFile(t, cached, key(2))
+	readCachedFile(t, cached, key(3))
+	assert.Equal(t, map[string]int{key(2): 1, key(3): 2, key(4): 1}, store.downloads)
+	cachedFiles, err := filepath.Glob(filepath.Join(dir, "*.lcm"))
+	require.NoError(t, err)
+	assert.Len(t, cachedFiles, 2)
+
+	// another run finds the files cached by the previous one, but not the files of another namespace
+	again, err := utils.WithLedgerCache(store, dir, "testnet", 2*size+1)
+	require.NoError(t, err)
+	readCachedFile(t, again, key(3))
+	assert.Equal(t, 2, store.downloads[key(3)])
+	other, err := utils.WithLedgerCache(store, dir, "pubnet", 2*size+1)
+	require.NoError(t, err)
+	readCachedFile(t, other, key(3))
+	assert.Equal(t, 3, store.downloads[key(3)])
+
+	_, err = cached.GetFile(context.Background(), key(5))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = utils.WithLedgerCache(store, dir, "testnet", 0)
+	assert.Error(t, err)
+}
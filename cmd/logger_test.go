@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRowLogFields(t *testing.T) {
+	var out bytes.Buffer
+	logger := utils.NewEtlLogger()
+	logger.SetOutput(&out)
+	logger.Configure(logrus.InfoLevel, "json")
+
+	// the fields of the row and those of the transform error are both written
+	err := &transform.TransformError{Code: transform.ErrorCodeInvalidOperation, OperationIndex: 2, Err: errors.New("bad operation")}
+	logger.WithFields(utils.RowLogFields("operations", 30578981, "a8f4e2")).LogError(fmt.Errorf("could not transform operation: %w", err))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "operations", line[utils.LogFieldTable])
+	assert.Equal(t, float64(30578981), line[utils.LogFieldLedger])
+	assert.Equal(t, "a8f4e2", line[utils.LogFieldTxHash])
+	assert.Equal(t, float64(2), line[transform.LogFieldOperationIndex])
+	assert.Equal(t, "invalid_operation", line[transform.LogFieldErrorCode])
+	assert.Equal(t, "error", line["level"])
+
+	// rows that are not read from a transaction have no hash
+	out.Reset()
+	logger.WithFields(utils.RowLogFields("ledgers", 30578981, "")).LogError(errors.New("could not export ledger"))
+	line = map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "ledgers", line[utils.LogFieldTable])
+	assert.NotContains(t, line, utils.LogFieldTxHash)
+}
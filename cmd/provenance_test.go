@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithProvenance(t *testing.T) {
+	extra := map[string]string{utils.ProvenanceBatchID: "mine", "source": "etl"}
+	exportedAt := time.Date(2024, 3, 1, 12, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+
+	fields := utils.WithProvenance(extra, "batch-1", exportedAt, toid.Generator{})
+	assert.Equal(t, "batch-1", fields[utils.ProvenanceBatchID])
+	assert.Equal(t, "etl", fields["source"])
+	assert.Equal(t, "2024-03-01T10:30:00Z", fields[utils.ProvenanceExportedAt])
+	etlVersion, transformVersion := utils.BuildVersions()
+	assert.Equal(t, etlVersion, fields[utils.ProvenanceEtlVersion])
+	assert.Equal(t, transformVersion, fields[utils.ProvenanceTransformVersion])
+	assert.NotEmpty(t, fields[utils.ProvenanceEtlVersion])
+	assert.NotContains(t, fields, utils.ProvenanceToidLedgerOffset)
+
+	// the extra fields of the command are left as they are
+	assert.Equal(t, map[string]string{utils.ProvenanceBatchID: "mine", "source": "etl"}, extra)
+
+	fields = utils.WithProvenance(extra, "batch-2", exportedAt, toid.Generator{LedgerOffset: 100})
+	assert.Equal(t, "100", fields[utils.ProvenanceToidLedgerOffset])
+}
+
+func TestWithIngestionLag(t *testing.T) {
+	extra := map[string]string{"source": "etl"}
+	closeTime := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// the lag is counted in whole seconds, in any time zone
+	exportedAt := time.Date(2024, 3, 1, 14, 1, 30, 900_000_000, time.FixedZone("UTC+2", 2*60*60))
+	fields := utils.WithIngestionLag(extra, closeTime, exportedAt)
+	assert.Equal(t, "90", fields[utils.IngestionLagField])
+	assert.Equal(t, "etl", fields["source"])
+	assert.Equal(t, map[string]string{"source": "etl"}, extra)
+
+	fields = utils.WithIngestionLag(nil, closeTime, closeTime)
+	assert.Equal(t, map[string]string{utils.IngestionLagField: "0"}, fields)
+}
+
+func TestExportEntryProvenance(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offers.txt")
+	outFile := MustOutFile(path)
+	extra := utils.WithProvenance(nil, "batch-1", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), toid.Generator{})
+	_, err := ExportEntry(transform.OfferOutput{SellerID: "GA", OfferID: 1}, outFile, extra)
+	require.NoError(t, err)
+	closeOutFile(outFile)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var row map[string]interface{}
+	require.NoError(t, json.Unmarshal(contents, &row))
+	assert.Equal(t, "GA", row["seller_id"])
+	assert.Equal(t, "batch-1", row[utils.ProvenanceBatchID])
+	assert.Equal(t, "2024-03-01T00:00:00Z", row[utils.ProvenanceExportedAt])
+	assert.Contains(t, row, utils.ProvenanceEtlVersion)
+	assert.Contains(t, row, utils.ProvenanceTransformVersion)
+}
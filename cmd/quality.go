@@ -0,0 +1,417 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// Data quality checks that can be enabled with the quality-checks flag
+const (
+	qualityNonNull       = "non-null"
+	qualityNonNegative   = "non-negative"
+	qualityUniqueIDs     = "unique-ids"
+	qualityIncreasingIDs = "increasing-ids"
+)
+
+var qualityChecks = []string{qualityNonNull, qualityNonNegative, qualityUniqueIDs, qualityIncreasingIDs}
+
+// qualitySampleSize is the number of offending rows kept in the report for each failed expectation
+const qualitySampleSize = 10
+
+// defaultQualityNonNull are the columns that must not be null or empty, unless set with quality-non-null
+var defaultQualityNonNull = map[string][]string{
+	"ledgers":                       {"sequence", "ledger_hash", "closed_at"},
+	"transactions":                  {"id", "transaction_hash", "account", "ledger_sequence", "closed_at"},
+	"operations":                    {"id", "transaction_id", "source_account", "closed_at"},
+	"effects":                       {"id", "address", "operation_id", "closed_at"},
+	"effects_wide":                  {"id", "address", "operation_id", "closed_at"},
+	"trades":                        {"history_operation_id", "ledger_closed_at", "trade_id"},
+	"contract_events":               {"transaction_hash", "ledger_sequence"},
+	"token_transfers":               {"transaction_hash", "ledger_sequence"},
+	"fees":                          {"transaction_hash", "fee_account", "ledger_sequence", "closed_at"},
+	"offer_events":                  {"offer_id", "seller_id", "operation_id"},
+	"network_upgrades":              {"upgrade_type", "new_value", "ledger_sequence", "closed_at"},
+	"accounts":                      {"account_id", "ledger_sequence"},
+	"signers":                       {"account_id", "signer"},
+	"home_domain_history":           {"account_id", "ledger_sequence"},
+	"inflation_destination_history": {"account_id", "ledger_sequence"},
+	"account_flags_history":         {"account_id", "ledger_sequence"},
+	"trustlines":                    {"ledger_key", "account_id"},
+	"offers":                        {"offer_id", "seller_id"},
+	"liquidity_pools":               {"liquidity_pool_id"},
+	"pool_share_holders":            {"account_id", "liquidity_pool_id"},
+	"claimable_balances":            {"balance_id"},
+	"asset_dimension":               {"asset_type"},
+	"account_summary":               {"account_id"},
+	"muxed_account_stats":           {"account_id", "account_muxed"},
+}
+
+// defaultQualityNonNegative are the columns that must not be negative, unless set with quality-non-negative
+var defaultQualityNonNegative = map[string][]string{
+	"transactions":        {"fee_charged", "max_fee"},
+	"trades":              {"selling_amount", "buying_amount"},
+	"offer_events":        {"amount"},
+	"token_transfers":     {"amount"},
+	"fees":                {"initial_fee_charged", "fee_refund", "fee_charged", "max_fee"},
+	"accounts":            {"balance"},
+	"trustlines":          {"balance"},
+	"offers":              {"amount"},
+	"liquidity_pools":     {"asset_a_amount", "asset_b_amount"},
+	"pool_share_holders":  {"balance"},
+	"claimable_balances":  {"asset_amount"},
+	"muxed_account_stats": {"amount_sent", "amount_received"},
+}
+
+// qualityUniqueColumns are the ids that must be unique within an export
+var qualityUniqueColumns = map[string]string{
+	"effects":         "id",
+	"effects_wide":    "id",
+	"fees":            "transaction_id",
+	"trades":          "trade_id",
+	"asset_dimension": "asset_id",
+	"account_summary": "account_id",
+}
+
+// qualityIncreasingColumns are the toids that must increase from row to row, since rows are exported in ledger order
+var qualityIncreasingColumns = map[string]string{
+	"ledgers":      "id",
+	"transactions": "id",
+	"operations":   "id",
+	"fees":         "transaction_id",
+}
+
+// qualitySample is an offending row of a failed expectation
+type qualitySample struct {
+	Ledger int64  `json:"ledger,omitempty"`
+	Value  string `json:"value"`
+}
+
+// qualityFailure counts the rows that failed an expectation on a column
+type qualityFailure struct {
+	Check   string          `json:"check"`
+	Table   string          `json:"table"`
+	Column  string          `json:"column"`
+	Count   int             `json:"count"`
+	Samples []qualitySample `json:"samples"`
+}
+
+// qualityReport is the outcome of the quality checks of an export
+type qualityReport struct {
+	Passed   bool             `json:"passed"`
+	Checks   []string         `json:"checks"`
+	Rows     map[string]int   `json:"rows"`
+	Failures []qualityFailure `json:"failures"`
+}
+
+// qualityChecker runs the data quality checks on the exported rows before their files are uploaded
+type qualityChecker struct {
+	checks      map[string]bool
+	mode        string
+	reportPath  string
+	nonNull     map[string][]string
+	nonNegative map[string][]string
+
+	rows     map[string]int
+	seen     map[string]map[string]bool
+	last     map[string]int64
+	failures map[string]*qualityFailure
+}
+
+// newQualityChecker returns the checker configured by the quality flags, or nil if no check is enabled
+func newQualityChecker(values utils.QualityFlagValues) (*qualityChecker, error) {
+	checks := map[string]bool{}
+	for _, check := range values.Checks {
+		switch {
+		case check == "all":
+			for _, c := range qualityChecks {
+				checks[c] = true
+			}
+		case isQualityCheck(check):
+			checks[check] = true
+		default:
+			return nil, fmt.Errorf("unknown quality check %s; expected one of %s or all", check, strings.Join(qualityChecks, ", "))
+		}
+	}
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	nonNull, err := parseQualityColumns(values.NonNull, defaultQualityNonNull)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quality-non-null: %v", err)
+	}
+	nonNegative, err := parseQualityColumns(values.NonNegative, defaultQualityNonNegative)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quality-non-negative: %v", err)
+	}
+
+	return &qualityChecker{
+		checks:      checks,
+		mode:        values.Mode,
+		reportPath:  values.ReportPath,
+		nonNull:     nonNull,
+		nonNegative: nonNegative,
+		rows:        map[string]int{},
+		seen:        map[string]map[string]bool{},
+		last:        map[string]int64{},
+		failures:    map[string]*qualityFailure{},
+	}, nil
+}
+
+func mustQualityChecker(values utils.QualityFlagValues) *qualityChecker {
+	checker, err := newQualityChecker(values)
+	if err != nil {
+		cmdLogger.Fatal(err)
+	}
+	return checker
+}
+
+func isQualityCheck(check string) bool {
+	for _, c := range qualityChecks {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+// parseQualityColumns groups <table>.<column> values by table, checking that the columns exist
+func parseQualityColumns(values []string, defaults map[string][]string) (map[string][]string, error) {
+	if len(values) == 0 {
+		return defaults, nil
+	}
+
+	columns := map[string][]string{}
+	for _, value := range values {
+		table, column, ok := strings.Cut(value, ".")
+		if !ok {
+			return nil, fmt.Errorf("%s is not a <table>.<column>", value)
+		}
+		output, ok := outputTables[table]
+		if !ok {
+			return nil, fmt.Errorf("unknown table %s", table)
+		}
+		if !hasSchemaField(transform.SchemaFields(output), column) {
+			return nil, fmt.Errorf("unknown column %s of %s", column, table)
+		}
+		columns[table] = append(columns[table], column)
+	}
+	return columns, nil
+}
+
+func hasSchemaField(fields []transform.SchemaField, name string) bool {
+	for _, field := range fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// add checks an exported row of a table. It does nothing on a nil checker.
+func (c *qualityChecker) add(table string, entry interface{}) error {
+	if c == nil {
+		return nil
+	}
+
+	marshalled, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("could not json encode %+v: %v", entry, err)
+	}
+	row := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	if err := decoder.Decode(&row); err != nil {
+		return fmt.Errorf("could not json decode %+v: %v", entry, err)
+	}
+
+	c.rows[table]++
+	ledger := qualityRowLedger(row)
+
+	if c.checks[qualityNonNull] {
+		for _, column := range c.nonNull[table] {
+			if value := row[column]; value == nil || value == "" {
+				c.fail(qualityNonNull, table, column, qualitySample{Ledger: ledger, Value: fmt.Sprint(value)})
+			}
+		}
+	}
+
+	if c.checks[qualityNonNegative] {
+		for _, column := range c.nonNegative[table] {
+			number, ok := qualityNumber(row[column])
+			if ok && number < 0 {
+				c.fail(qualityNonNegative, table, column, qualitySample{Ledger: ledger, Value: fmt.Sprint(row[column])})
+			}
+		}
+	}
+
+	if column, ok := qualityUniqueColumns[table]; ok && c.checks[qualityUniqueIDs] {
+		id := fmt.Sprint(row[column])
+		if c.seen[table] == nil {
+			c.seen[table] = map[string]bool{}
+		}
+		if c.seen[table][id] {
+			c.fail(qualityUniqueIDs, table, column, qualitySample{Ledger: ledger, Value: id})
+		}
+		c.seen[table][id] = true
+	}
+
+	if column, ok := qualityIncreasingColumns[table]; ok && c.checks[qualityIncreasingIDs] {
+		if number, ok := row[column].(json.Number); ok {
+			id, err := number.Int64()
+			if err != nil {
+				return fmt.Errorf("%s of %s is not an integer: %v", column, table, err)
+			}
+			if last, ok := c.last[table]; ok && id <= last {
+				c.fail(qualityIncreasingIDs, table, column, qualitySample{Ledger: ledger, Value: number.String()})
+			}
+			c.last[table] = id
+		}
+	}
+
+	return nil
+}
+
+func (c *qualityChecker) fail(check, table, column string, sample qualitySample) {
+	key := check + "/" + table + "/" + column
+	failure, ok := c.failures[key]
+	if !ok {
+		failure = &qualityFailure{Check: check, Table: table, Column: column, Samples: []qualitySample{}}
+		c.failures[key] = failure
+	}
+	failure.Count++
+	if len(failure.Samples) < qualitySampleSize {
+		failure.Samples = append(failure.Samples, sample)
+	}
+}
+
+// qualityRowLedger is the ledger of a row, used to find the offending rows of the report
+func qualityRowLedger(row map[string]interface{}) int64 {
+	for _, column := range []string{"ledger_sequence", "sequence"} {
+		if number, ok := row[column].(json.Number); ok {
+			ledger, _ := number.Int64()
+			return ledger
+		}
+	}
+	return 0
+}
+
+// qualityNumber reads json numbers, and amounts encoded as strings
+func qualityNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// report returns the outcome of the checks of the rows added so far
+func (c *qualityChecker) report() qualityReport {
+	report := qualityReport{
+		Passed:   len(c.failures) == 0,
+		Checks:   []string{},
+		Rows:     c.rows,
+		Failures: []qualityFailure{},
+	}
+	for _, check := range qualityChecks {
+		if c.checks[check] {
+			report.Checks = append(report.Checks, check)
+		}
+	}
+
+	keys := make([]string, 0, len(c.failures))
+	for key := range c.failures {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		report.Failures = append(report.Failures, *c.failures[key])
+	}
+
+	return report
+}
+
+// finish logs the report of the checks and writes it to the report path. In fail mode, it returns an error if a
+// check failed, so that the export stops before its files are uploaded. It does nothing on a nil checker.
+func (c *qualityChecker) finish() error {
+	if c == nil {
+		return nil
+	}
+
+	report := c.report()
+	if c.reportPath != "" {
+		if err := writeQualityReport(c.reportPath, report); err != nil {
+			return err
+		}
+	}
+
+	if report.Passed {
+		cmdLogger.Infof("Quality checks %s passed", strings.Join(report.Checks, ", "))
+		return nil
+	}
+
+	for _, failure := range report.Failures {
+		cmdLogger.Warnf("Quality check %s failed for %d rows of %s.%s, such as %+v", failure.Check, failure.Count, failure.Table, failure.Column, failure.Samples[0])
+	}
+	if c.mode == utils.QualityModeFail {
+		return fmt.Errorf("%d quality checks failed", len(report.Failures))
+	}
+	return nil
+}
+
+// checkBatchQuality runs the quality checks on the tables of a batch of export_ledger_entry_changes. The report of
+// the batch is written to the output folder, prefixed with the ledger range like the other files of the batch.
+func checkBatchQuality(values utils.QualityFlagValues, outputFolder string, start, end uint32, outputs map[string][]interface{}) error {
+	if values.ReportPath != "" {
+		values.ReportPath = filepath.Join(outputFolder, fmt.Sprintf("%d-%d-%s", start, end, filepath.Base(values.ReportPath)))
+	}
+
+	checker, err := newQualityChecker(values)
+	if err != nil || checker == nil {
+		return err
+	}
+
+	tables := make([]string, 0, len(outputs))
+	for table := range outputs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		for _, row := range outputs[table] {
+			if err := checker.add(table, row); err != nil {
+				return err
+			}
+		}
+	}
+
+	return checker.finish()
+}
+
+func writeQualityReport(path string, report qualityReport) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("could not create directory of quality report %s: %v", path, err)
+	}
+
+	marshalled, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not json encode quality report: %v", err)
+	}
+	if err := os.WriteFile(path, append(marshalled, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write quality report %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestNewQualityChecker(t *testing.T) {
+	checker, err := newQualityChecker(utils.QualityFlagValues{Mode: utils.QualityModeWarn})
+	require.NoError(t, err)
+	assert.Nil(t, checker)
+	assert.NoError(t, checker.add("effects", transform.EffectOutput{}))
+	assert.NoError(t, checker.finish())
+
+	checker, err = newQualityChecker(utils.QualityFlagValues{Checks: []string{"all"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{qualityNonNull: true, qualityNonNegative: true, qualityUniqueIDs: true, qualityIncreasingIDs: true}, checker.checks)
+	assert.Equal(t, defaultQualityNonNull, checker.nonNull)
+
+	checker, err = newQualityChecker(utils.QualityFlagValues{Checks: []string{qualityNonNull}, NonNull: []string{"trades.selling_account_address", "trades.buying_account_address"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"trades": {"selling_account_address", "buying_account_address"}}, checker.nonNull)
+
+	_, err = newQualityChecker(utils.QualityFlagValues{Checks: []string{"positive"}})
+	assert.EqualError(t, err, "unknown quality check positive; expected one of non-null, non-negative, unique-ids, increasing-ids or all")
+
+	_, err = newQualityChecker(utils.QualityFlagValues{Checks: []string{qualityNonNull}, NonNull: []string{"trades.amount"}})
+	assert.EqualError(t, err, "invalid quality-non-null: unknown column amount of trades")
+
+	_, err = newQualityChecker(utils.QualityFlagValues{Checks: []string{qualityNonNegative}, NonNegative: []string{"amount"}})
+	assert.EqualError(t, err, "invalid quality-non-negative: amount is not a <table>.<column>")
+}
+
+func TestDefaultQualityColumns(t *testing.T) {
+	for _, defaults := range []map[string][]string{defaultQualityNonNull, defaultQualityNonNegative} {
+		for table, columns := range defaults {
+			for _, column := range columns {
+				assert.True(t, hasSchemaField(transform.SchemaFields(outputTables[table]), column), "%s.%s", table, column)
+			}
+		}
+	}
+	for _, columns := range []map[string]string{qualityUniqueColumns, qualityIncreasingColumns} {
+		for table, column := range columns {
+			assert.True(t, hasSchemaField(transform.SchemaFields(outputTables[table]), column), "%s.%s", table, column)
+		}
+	}
+}
+
+func TestQualityChecker(t *testing.T) {
+	checker, err := newQualityChecker(utils.QualityFlagValues{Checks: []string{"all"}, Mode: utils.QualityModeWarn})
+	require.NoError(t, err)
+
+	effects := []transform.EffectOutput{
+		{EffectId: "1-1", Address: "GA", OperationID: 1, LedgerSequence: 10},
+		{EffectId: "1-2", Address: "", OperationID: 1, LedgerSequence: 10},
+		{EffectId: "1-1", Address: "GB", OperationID: 1, LedgerSequence: 11},
+	}
+	for _, effect := range effects {
+		require.NoError(t, checker.add("effects", effect))
+	}
+	for _, id := range []int64{5, 7, 6} {
+		require.NoError(t, checker.add("operations", transform.OperationOutput{OperationID: id, TransactionID: 1, SourceAccount: "GA", LedgerSequence: 12}))
+	}
+	require.NoError(t, checker.add("offers", transform.OfferOutput{OfferID: 1, SellerID: "GA", Amount: -1, Sponsor: null.StringFrom("GB")}))
+
+	report := checker.report()
+	assert.False(t, report.Passed)
+	assert.Equal(t, map[string]int{"effects": 3, "operations": 3, "offers": 1}, report.Rows)
+	assert.Equal(t, []qualityFailure{
+		{Check: qualityIncreasingIDs, Table: "operations", Column: "id", Count: 1, Samples: []qualitySample{{Ledger: 12, Value: "6"}}},
+		{Check: qualityNonNegative, Table: "offers", Column: "amount", Count: 1, Samples: []qualitySample{{Value: "-1"}}},
+		{Check: qualityNonNull, Table: "effects", Column: "address", Count: 1, Samples: []qualitySample{{Ledger: 10, Value: ""}}},
+		{Check: qualityUniqueIDs, Table: "effects", Column: "id", Count: 1, Samples: []qualitySample{{Ledger: 11, Value: "1-1"}}},
+	}, report.Failures)
+
+	// warnings do not stop the export
+	assert.NoError(t, checker.finish())
+}
+
+func TestQualityCheckerFailMode(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "reports", "quality.json")
+	checker, err := newQualityChecker(utils.QualityFlagValues{
+		Checks:      []string{qualityNonNegative},
+		Mode:        utils.QualityModeFail,
+		ReportPath:  reportPath,
+		NonNegative: []string{"trades.selling_amount"},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, checker.add("trades", transform.TradeOutput{SellingAmount: 1}))
+	require.NoError(t, checker.add("trades", transform.TradeOutput{SellingAmount: -2.5}))
+	assert.EqualError(t, checker.finish(), "1 quality checks failed")
+
+	contents, err := os.ReadFile(reportPath)
+	require.NoError(t, err)
+	var report qualityReport
+	require.NoError(t, json.Unmarshal(contents, &report))
+	assert.False(t, report.Passed)
+	assert.Equal(t, []string{qualityNonNegative}, report.Checks)
+	require.Len(t, report.Failures, 1)
+	assert.Equal(t, []qualitySample{{Value: "-2.5"}}, report.Failures[0].Samples)
+}
+
+func TestCheckBatchQuality(t *testing.T) {
+	outputFolder := t.TempDir()
+	values := utils.QualityFlagValues{Checks: []string{"all"}, Mode: utils.QualityModeFail, ReportPath: "quality.json"}
+
+	outputs := map[string][]interface{}{
+		"accounts": {transform.AccountOutput{AccountID: "GA", Balance: 10, LedgerSequence: 100}},
+	}
+	require.NoError(t, checkBatchQuality(values, outputFolder, 100, 163, outputs))
+	assert.FileExists(t, filepath.Join(outputFolder, "100-163-quality.json"))
+
+	outputs["accounts"] = append(outputs["accounts"], transform.AccountOutput{AccountID: "GB", Balance: -1, LedgerSequence: 101})
+	assert.EqualError(t, checkBatchQuality(values, outputFolder, 100, 163, outputs), "1 quality checks failed")
+
+	// without checks the batch is not inspected
+	assert.NoError(t, checkBatchQuality(utils.QualityFlagValues{}, outputFolder, 164, 227, outputs))
+	assert.NoFileExists(t, filepath.Join(outputFolder, "164-227-quality.json"))
+}
@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/dedupe"
+)
+
+// Attributes set on every queue message, so that consumers can filter and route messages without decoding them
+const (
+	queueLedgerAttribute = "ledger"
+	queueTableAttribute  = "table"
+	queueTypeAttribute   = "type"
+)
+
+// queueBufferSize is the number of messages the sink buffers before publishing them
+const queueBufferSize = 1000
+
+// Limits of a single publish request. Pub/Sub requests are limited to 1000 messages and 10MB, of which the base64
+// encoding of the data takes a third more; SQS batches are limited to 10 messages and 256KiB.
+const (
+	pubsubMaxMessages = 1000
+	pubsubMaxBytes    = 7 * 1024 * 1024
+	sqsMaxMessages    = 10
+	sqsMaxBytes       = 256 * 1024
+)
+
+// queueAccountFields are the fields holding the account of a row, in order of preference. The account is the
+// ordering key of the messages, so the events of an account are delivered in order.
+var queueAccountFields = []string{"account_id", "address", "source_account"}
+
+// queueMessage is a row published to a message queue
+type queueMessage struct {
+	Body        []byte
+	Attributes  map[string]string
+	OrderingKey string
+}
+
+// queuePublisher publishes messages to a message queue
+type queuePublisher interface {
+	name() string
+	publish(ctx context.Context, messages []queueMessage) error
+}
+
+// queueSink publishes every exported row as a message to the configured queues
+type queueSink struct {
+	publishers []queuePublisher
+	// retrier retries the messages that failed to publish with a transient error; nil leaves them unretried
+	retrier *utils.Retrier
+	pending []queueMessage
+	// keys sets the dedupe keys of the messages added to the buffer; nil unless dedupe keys are enabled
+	keys *queueKeys
+}
+
+// queueKeys numbers the rows of each table in a ledger, to set the dedupe key of their messages
+type queueKeys struct {
+	next map[queueKeyScope]uint32
+}
+
+// queueKeyScope is a table in a ledger, the rows of which are numbered from 0
+type queueKeyScope struct {
+	ledger uint32
+	table  string
+}
+
+func newQueueKeys() *queueKeys {
+	return &queueKeys{next: map[queueKeyScope]uint32{}}
+}
+
+// stamp sets the dedupe key of a message to its ledger, its table and the number of messages of that table in the
+// ledger stamped before it. Messages without a ledger get no key.
+func (k *queueKeys) stamp(message *queueMessage) {
+	if k == nil {
+		return
+	}
+
+	ledger, err := strconv.ParseUint(message.Attributes[queueLedgerAttribute], 10, 32)
+	if err != nil {
+		return
+	}
+
+	scope := queueKeyScope{ledger: uint32(ledger), table: message.Attributes[queueTableAttribute]}
+	key := dedupe.Key{Ledger: scope.ledger, Table: scope.table, Sequence: k.next[scope]}
+	k.next[scope]++
+	message.Attributes[dedupe.Attribute] = key.String()
+}
+
+// newQueueSink returns the sink configured by the queue flags, or nil if no queue is set. Publishing is retried with
+// the retry policy.
+func newQueueSink(ctx context.Context, values utils.QueueFlagValues, policy utils.RetryPolicy) (*queueSink, error) {
+	sink := &queueSink{retrier: utils.NewRetrier("queue", policy)}
+
+	if values.PubsubTopic != "" {
+		credentials, err := utils.ResolveCloudCredentials("")
+		if err != nil {
+			return nil, err
+		}
+		publisher, err := newPubsubPublisher(ctx, values.PubsubTopic, credentials.GoogleClientOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		sink.publishers = append(sink.publishers, publisher)
+	}
+
+	if values.SQSQueueURL != "" {
+		publisher, err := newSQSPublisher(values.SQSQueueURL, values.SQSRegion)
+		if err != nil {
+			return nil, err
+		}
+		sink.publishers = append(sink.publishers, publisher)
+	}
+
+	if len(sink.publishers) == 0 {
+		return nil, nil
+	}
+	if values.DedupeKeys {
+		sink.keys = newQueueKeys()
+	}
+	return sink, nil
+}
+
+// mustQueueSink returns the sink configured by the queue flags of a command, or nil if no queue is set
+func mustQueueSink(ctx context.Context, values utils.QueueFlagValues, policy utils.RetryPolicy) *queueSink {
+	sink, err := newQueueSink(ctx, values, policy)
+	if err != nil {
+		cmdLogger.Fatal("could not create the queue sink: ", err)
+	}
+	return sink
+}
+
+// add buffers the message of a row and publishes the buffered messages once the buffer is full
+func (s *queueSink) add(ctx context.Context, table string, row interface{}, extra map[string]string) error {
+	if s == nil {
+		return nil
+	}
+
+	message, err := newQueueMessage(table, row, extra)
+	if err != nil {
+		return err
+	}
+	s.keys.stamp(&message)
+	s.pending = append(s.pending, message)
+
+	if len(s.pending) >= queueBufferSize {
+		return s.flush(ctx)
+	}
+	return nil
+}
+
+// send publishes the rows of a batch, one table after the other. Unlike add, it does not use the buffer, so the
+// batches of several networks can be sent concurrently.
+func (s *queueSink) send(ctx context.Context, outputs map[string][]interface{}, extra map[string]string) error {
+	if s == nil {
+		return nil
+	}
+
+	tables := make([]string, 0, len(outputs))
+	for table := range outputs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var keys *queueKeys
+	if s.keys != nil {
+		keys = newQueueKeys()
+	}
+
+	messages := []queueMessage{}
+	for _, table := range tables {
+		tableMessages := make([]queueMessage, 0, len(outputs[table]))
+		for _, row := range outputs[table] {
+			message, err := newQueueMessage(table, row, extra)
+			if err != nil {
+				return err
+			}
+			tableMessages = append(tableMessages, message)
+		}
+
+		if keys != nil {
+			// The changes of a batch come out of the compactors in no particular order, so the messages are sorted to
+			// get the same keys when the batch is exported again. The extra fields are the same for every row, so
+			// they do not change the order.
+			sort.SliceStable(tableMessages, func(i, j int) bool {
+				return bytes.Compare(tableMessages[i].Body, tableMessages[j].Body) < 0
+			})
+			for i := range tableMessages {
+				keys.stamp(&tableMessages[i])
+			}
+		}
+		messages = append(messages, tableMessages...)
+	}
+
+	return s.publish(ctx, messages)
+}
+
+// flush publishes the buffered messages
+func (s *queueSink) flush(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+
+	if err := s.publish(ctx, s.pending); err != nil {
+		return err
+	}
+	s.pending = s.pending[:0]
+	return nil
+}
+
+// publish publishes messages to every queue. The messages are published even if the context is done, so that the
+// last rows are delivered on shutdown.
+func (s *queueSink) publish(ctx context.Context, messages []queueMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	for _, publisher := range s.publishers {
+		err := s.retrier.Do(ctx, "publish", func() error {
+			return publisher.publish(ctx, messages)
+		})
+		if err != nil {
+			return fmt.Errorf("could not publish %d messages to %s: %w", len(messages), publisher.name(), err)
+		}
+	}
+
+	return nil
+}
+
+// newQueueMessage converts a row to the json object written by ExportEntry, with the ledger, table and type of
+// the row as attributes and its account as ordering key
+func newQueueMessage(table string, row interface{}, extra map[string]string) (queueMessage, error) {
+	decoded, err := webhookRow(row, extra)
+	if err != nil {
+		return queueMessage{}, err
+	}
+
+	body, err := json.Marshal(decoded)
+	if err != nil {
+		return queueMessage{}, fmt.Errorf("could not json encode the %s message: %v", table, err)
+	}
+
+	message := queueMessage{
+		Body:       body,
+		Attributes: map[string]string{queueTableAttribute: table},
+	}
+	if ledger, ok := decoded["ledger_sequence"].(json.Number); ok {
+		message.Attributes[queueLedgerAttribute] = ledger.String()
+	}
+	if rowType, ok := decoded["type_string"].(string); ok && rowType != "" {
+		message.Attributes[queueTypeAttribute] = rowType
+	}
+	for _, field := range queueAccountFields {
+		if account, ok := decoded[field].(string); ok && account != "" {
+			message.OrderingKey = account
+			break
+		}
+	}
+
+	return message, nil
+}
+
+// chunkQueueMessages splits messages into chunks of at most maxMessages messages and maxBytes bytes of body
+func chunkQueueMessages(messages []queueMessage, maxMessages, maxBytes int) [][]queueMessage {
+	chunks := [][]queueMessage{}
+	start, size := 0, 0
+	for i, message := range messages {
+		if i > start && (i-start >= maxMessages || size+len(message.Body) > maxBytes) {
+			chunks = append(chunks, messages[start:i])
+			start, size = i, 0
+		}
+		size += len(message.Body)
+	}
+	if start < len(messages) {
+		chunks = append(chunks, messages[start:])
+	}
+	return chunks
+}
+
+// pubsubPublisher publishes messages to a Google Pub/Sub topic. Ordering keys are only honoured by subscriptions
+// with message ordering enabled.
+type pubsubPublisher struct {
+	topic  string
+	topics *pubsub.ProjectsTopicsService
+}
+
+func newPubsubPublisher(ctx context.Context, topic string, opts ...option.ClientOption) (*pubsubPublisher, error) {
+	if !strings.HasPrefix(topic, "projects/") || !strings.Contains(topic, "/topics/") {
+		return nil, fmt.Errorf("pubsub topic %s is not of the form projects/<project>/topics/<topic>", topic)
+	}
+
+	service, err := pubsub.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create the pubsub client: %v", err)
+	}
+
+	return &pubsubPublisher{topic: topic, topics: pubsub.NewProjectsTopicsService(service)}, nil
+}
+
+func (p *pubsubPublisher) name() string {
+	return p.topic
+}
+
+func (p *pubsubPublisher) publish(ctx context.Context, messages []queueMessage) error {
+	for _, chunk := range chunkQueueMessages(messages, pubsubMaxMessages, pubsubMaxBytes) {
+		request := &pubsub.PublishRequest{}
+		for _, message := range chunk {
+			request.Messages = append(request.Messages, &pubsub.PubsubMessage{
+				Data:        base64.StdEncoding.EncodeToString(message.Body),
+				Attributes:  message.Attributes,
+				OrderingKey: message.OrderingKey,
+			})
+		}
+
+		if _, err := p.topics.Publish(p.topic, request).Context(ctx).Do(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sqsPublisher sends messages to an Amazon SQS queue. On FIFO queues the ordering key is the message group, and the
+// dedupe key, or else the hash of the body, the deduplication id, so that rows exported twice are delivered once.
+type sqsPublisher struct {
+	queueURL string
+	fifo     bool
+	client   sqsiface.SQSAPI
+}
+
+func newSQSPublisher(queueURL, region string) (*sqsPublisher, error) {
+	resolved, err := utils.ResolveAWSCredentials()
+	if err != nil {
+		return nil, err
+	}
+	awsCredentials, err := resolved.AWSCredentials()
+	if err != nil {
+		return nil, err
+	}
+
+	config := aws.Config{Credentials: awsCredentials}
+	if region != "" {
+		config.Region = aws.String(region)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create the aws session: %v", err)
+	}
+
+	return &sqsPublisher{
+		queueURL: queueURL,
+		fifo:     strings.HasSuffix(queueURL, ".fifo"),
+		client:   sqs.New(sess),
+	}, nil
+}
+
+func (p *sqsPublisher) name() string {
+	return p.queueURL
+}
+
+func (p *sqsPublisher) publish(ctx context.Context, messages []queueMessage) error {
+	for _, chunk := range chunkQueueMessages(messages, sqsMaxMessages, sqsMaxBytes) {
+		input := &sqs.SendMessageBatchInput{QueueUrl: aws.String(p.queueURL)}
+		for i, message := range chunk {
+			entry := &sqs.SendMessageBatchRequestEntry{
+				Id:                aws.String(strconv.Itoa(i)),
+				MessageBody:       aws.String(string(message.Body)),
+				MessageAttributes: map[string]*sqs.MessageAttributeValue{},
+			}
+			for key, value := range message.Attributes {
+				dataType := "String"
+				if key == queueLedgerAttribute {
+					dataType = "Number"
+				}
+				entry.MessageAttributes[key] = &sqs.MessageAttributeValue{DataType: aws.String(dataType), StringValue: aws.String(value)}
+			}
+			if p.fifo {
+				group := message.OrderingKey
+				if group == "" {
+					group = message.Attributes[queueTableAttribute]
+				}
+				deduplicationID, ok := message.Attributes[dedupe.Attribute]
+				if !ok {
+					hash := sha256.Sum256(message.Body)
+					deduplicationID = hex.EncodeToString(hash[:])
+				}
+				entry.MessageGroupId = aws.String(group)
+				entry.MessageDeduplicationId = aws.String(deduplicationID)
+			}
+			input.Entries = append(input.Entries, entry)
+		}
+
+		output, err := p.client.SendMessageBatchWithContext(ctx, input)
+		if err != nil {
+			return err
+		}
+		if len(output.Failed) > 0 {
+			failed := output.Failed[0]
+			err := fmt.Errorf("%d of %d messages were not sent, first error: %s %s", len(output.Failed), len(chunk), aws.StringValue(failed.Code), aws.StringValue(failed.Message))
+			// Messages that failed through the fault of the sender, such as invalid attributes, fail again if resent
+			if aws.BoolValue(failed.SenderFault) {
+				return utils.PermanentError(err)
+			}
+			return utils.TransientError(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/dedupe"
+)
+
+type fakeSQSClient struct {
+	sqsiface.SQSAPI
+	inputs []*sqs.SendMessageBatchInput
+	failed []*sqs.BatchResultErrorEntry
+}
+
+func (c *fakeSQSClient) SendMessageBatchWithContext(ctx aws.Context, input *sqs.SendMessageBatchInput, opts ...request.Option) (*sqs.SendMessageBatchOutput, error) {
+	c.inputs = append(c.inputs, input)
+	return &sqs.SendMessageBatchOutput{Failed: c.failed}, nil
+}
+
+type recordingPublisher struct {
+	batches [][]queueMessage
+}
+
+func (p *recordingPublisher) name() string {
+	return "recording"
+}
+
+func (p *recordingPublisher) publish(ctx context.Context, messages []queueMessage) error {
+	p.batches = append(p.batches, append([]queueMessage{}, messages...))
+	return nil
+}
+
+func testEffect(i int) transform.EffectOutput {
+	return transform.EffectOutput{
+		Address:        "GABC",
+		TypeString:     "account_credited",
+		LedgerSequence: uint32(100 + i),
+	}
+}
+
+func TestNewQueueSinkDisabled(t *testing.T) {
+	sink, err := newQueueSink(context.Background(), utils.QueueFlagValues{}, utils.RetryPolicy{})
+	require.NoError(t, err)
+	assert.Nil(t, sink)
+
+	// a nil sink is a no-op
+	assert.NoError(t, sink.add(context.Background(), "effects", testEffect(0), nil))
+	assert.NoError(t, sink.flush(context.Background()))
+	assert.NoError(t, sink.send(context.Background(), map[string][]interface{}{"effects": {testEffect(0)}}, nil))
+}
+
+func TestNewQueueSinkInvalidTopic(t *testing.T) {
+	_, err := newQueueSink(context.Background(), utils.QueueFlagValues{PubsubTopic: "effects"}, utils.RetryPolicy{})
+	assert.ErrorContains(t, err, "projects/<project>/topics/<topic>")
+}
+
+func TestNewQueueMessage(t *testing.T) {
+	message, err := newQueueMessage("effects", testEffect(0), map[string]string{"batch_id": "b1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"table": "effects", "ledger": "100", "type": "account_credited"}, message.Attributes)
+	assert.Equal(t, "GABC", message.OrderingKey)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(message.Body, &body))
+	assert.Equal(t, "b1", body["batch_id"])
+	assert.Equal(t, "GABC", body["address"])
+
+	message, err = newQueueMessage("operations", transform.OperationOutput{SourceAccount: "GDEF", TypeString: "payment", LedgerSequence: 7}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "GDEF", message.OrderingKey)
+	assert.Equal(t, "payment", message.Attributes["type"])
+}
+
+func TestQueueSinkBuffersMessages(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := &queueSink{publishers: []queuePublisher{publisher}}
+
+	for i := 0; i < queueBufferSize+1; i++ {
+		require.NoError(t, sink.add(context.Background(), "effects", testEffect(i), nil))
+	}
+	require.Len(t, publisher.batches, 1)
+	assert.Len(t, publisher.batches[0], queueBufferSize)
+
+	require.NoError(t, sink.flush(context.Background()))
+	require.Len(t, publisher.batches, 2)
+	assert.Len(t, publisher.batches[1], 1)
+
+	require.NoError(t, sink.flush(context.Background()))
+	assert.Len(t, publisher.batches, 2)
+}
+
+func TestQueueSinkDedupeKeys(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := &queueSink{publishers: []queuePublisher{publisher}, keys: newQueueKeys()}
+
+	for _, i := range []int{0, 0, 1} {
+		require.NoError(t, sink.add(context.Background(), "effects", testEffect(i), nil))
+	}
+	require.NoError(t, sink.add(context.Background(), "operations", transform.OperationOutput{LedgerSequence: 100}, nil))
+	require.NoError(t, sink.flush(context.Background()))
+
+	keys := []string{}
+	for _, message := range publisher.batches[0] {
+		keys = append(keys, message.Attributes[dedupe.Attribute])
+	}
+	assert.Equal(t, []string{
+		"0000000100:effects:0000000000",
+		"0000000100:effects:0000000001",
+		"0000000101:effects:0000000000",
+		"0000000100:operations:0000000000",
+	}, keys)
+}
+
+func TestQueueSinkSendDedupeKeys(t *testing.T) {
+	publisher := &recordingPublisher{}
+	sink := &queueSink{publishers: []queuePublisher{publisher}, keys: newQueueKeys()}
+
+	first := transform.EffectOutput{Address: "GB", LedgerSequence: 100}
+	second := transform.EffectOutput{Address: "GA", LedgerSequence: 100}
+	require.NoError(t, sink.send(context.Background(), map[string][]interface{}{"effects": {first, second}}, nil))
+	require.NoError(t, sink.send(context.Background(), map[string][]interface{}{"effects": {second, first}}, nil))
+
+	// the keys of a batch do not depend on the order of its rows, nor on the batches sent before it
+	for _, batch := range publisher.batches {
+		require.Len(t, batch, 2)
+		assert.Equal(t, "0000000100:effects:0000000000", batch[0].Attributes[dedupe.Attribute])
+		assert.Equal(t, "GA", batch[0].OrderingKey)
+		assert.Equal(t, "0000000100:effects:0000000001", batch[1].Attributes[dedupe.Attribute])
+	}
+}
+
+func TestChunkQueueMessages(t *testing.T) {
+	messages := make([]queueMessage, 5)
+	for i := range messages {
+		messages[i] = queueMessage{Body: []byte(strings.Repeat("x", 10))}
+	}
+
+	chunks := chunkQueueMessages(messages, 2, 100)
+	assert.Len(t, chunks, 3)
+	assert.Len(t, chunks[2], 1)
+
+	chunks = chunkQueueMessages(messages, 10, 25)
+	assert.Len(t, chunks, 3)
+	assert.Len(t, chunks[0], 2)
+
+	// a message larger than the limit is sent on its own
+	chunks = chunkQueueMessages(messages, 10, 5)
+	assert.Len(t, chunks, 5)
+}
+
+func TestPubsubPublisher(t *testing.T) {
+	requests := []pubsub.PublishRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/projects/p/topics/t:publish", r.URL.Path)
+		var publishRequest pubsub.PublishRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&publishRequest))
+		requests = append(requests, publishRequest)
+		json.NewEncoder(w).Encode(pubsub.PublishResponse{MessageIds: []string{"1"}})
+	}))
+	defer server.Close()
+
+	publisher, err := newPubsubPublisher(context.Background(), "projects/p/topics/t", option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	require.NoError(t, err)
+
+	message, err := newQueueMessage("effects", testEffect(0), nil)
+	require.NoError(t, err)
+	require.NoError(t, publisher.publish(context.Background(), []queueMessage{message}))
+
+	require.Len(t, requests, 1)
+	require.Len(t, requests[0].Messages, 1)
+	published := requests[0].Messages[0]
+	data, err := base64.StdEncoding.DecodeString(published.Data)
+	require.NoError(t, err)
+	assert.Equal(t, message.Body, data)
+	assert.Equal(t, message.Attributes, published.Attributes)
+	assert.Equal(t, "GABC", published.OrderingKey)
+}
+
+func TestSQSPublisher(t *testing.T) {
+	client := &fakeSQSClient{}
+	publisher := &sqsPublisher{queueURL: "https://sqs.us-east-1.amazonaws.com/1/effects", client: client}
+
+	messages := []queueMessage{}
+	for i := 0; i < 12; i++ {
+		message, err := newQueueMessage("effects", testEffect(i), nil)
+		require.NoError(t, err)
+		messages = append(messages, message)
+	}
+	require.NoError(t, publisher.publish(context.Background(), messages))
+
+	require.Len(t, client.inputs, 2)
+	assert.Len(t, client.inputs[0].Entries, 10)
+	assert.Len(t, client.inputs[1].Entries, 2)
+
+	entry := client.inputs[0].Entries[0]
+	assert.Equal(t, "0", aws.StringValue(entry.Id))
+	assert.Equal(t, "Number", aws.StringValue(entry.MessageAttributes["ledger"].DataType))
+	assert.Equal(t, "100", aws.StringValue(entry.MessageAttributes["ledger"].StringValue))
+	assert.Equal(t, "effects", aws.StringValue(entry.MessageAttributes["table"].StringValue))
+	assert.Nil(t, entry.MessageGroupId)
+	assert.Nil(t, entry.MessageDeduplicationId)
+}
+
+func TestSQSPublisherFifo(t *testing.T) {
+	client := &fakeSQSClient{}
+	publisher := &sqsPublisher{queueURL: "https://sqs.us-east-1.amazonaws.com/1/effects.fifo", fifo: true, client: client}
+
+	message, err := newQueueMessage("effects", testEffect(0), nil)
+	require.NoError(t, err)
+	require.NoError(t, publisher.publish(context.Background(), []queueMessage{message, message}))
+
+	entries := client.inputs[0].Entries
+	assert.Equal(t, "GABC", aws.StringValue(entries[0].MessageGroupId))
+	assert.Len(t, aws.StringValue(entries[0].MessageDeduplicationId), 64)
+	assert.Equal(t, aws.StringValue(entries[0].MessageDeduplicationId), aws.StringValue(entries[1].MessageDeduplicationId))
+
+	message.Attributes[dedupe.Attribute] = "0000000100:effects:0000000000"
+	require.NoError(t, publisher.publish(context.Background(), []queueMessage{message}))
+	assert.Equal(t, "0000000100:effects:0000000000", aws.StringValue(client.inputs[1].Entries[0].MessageDeduplicationId))
+}
+
+func TestSQSPublisherFailedEntries(t *testing.T) {
+	client := &fakeSQSClient{failed: []*sqs.BatchResultErrorEntry{{Id: aws.String("0"), Code: aws.String("InvalidParameterValue"), Message: aws.String("bad")}}}
+	publisher := &sqsPublisher{queueURL: "https://sqs.us-east-1.amazonaws.com/1/effects", client: client}
+
+	message, err := newQueueMessage("effects", testEffect(0), nil)
+	require.NoError(t, err)
+	err = publisher.publish(context.Background(), []queueMessage{message})
+	assert.ErrorContains(t, err, "1 of 1 messages were not sent")
+	assert.True(t, utils.IsRetryable(err))
+
+	client.failed[0].SenderFault = aws.Bool(true)
+	err = publisher.publish(context.Background(), []queueMessage{message})
+	assert.False(t, utils.IsRetryable(err))
+}
+
+type failingPublisher struct {
+	errs     []error
+	attempts int
+}
+
+func (p *failingPublisher) name() string {
+	return "failing"
+}
+
+func (p *failingPublisher) publish(ctx context.Context, messages []queueMessage) error {
+	p.attempts++
+	if len(p.errs) == 0 {
+		return nil
+	}
+	err := p.errs[0]
+	p.errs = p.errs[1:]
+	return err
+}
+
+func TestQueueSinkRetries(t *testing.T) {
+	message, err := newQueueMessage("effects", testEffect(0), nil)
+	require.NoError(t, err)
+	policy := utils.RetryPolicy{Attempts: 3}
+
+	publisher := &failingPublisher{errs: []error{errors.New("connection reset"), &googleapi.Error{Code: http.StatusServiceUnavailable}}}
+	sink := &queueSink{publishers: []queuePublisher{publisher}, retrier: utils.NewRetrier("queue", policy)}
+	require.NoError(t, sink.publish(context.Background(), []queueMessage{message}))
+	assert.Equal(t, 3, publisher.attempts)
+
+	publisher = &failingPublisher{errs: []error{&googleapi.Error{Code: http.StatusForbidden}}}
+	sink = &queueSink{publishers: []queuePublisher{publisher}, retrier: utils.NewRetrier("queue", policy)}
+	err = sink.publish(context.Background(), []queueMessage{message})
+	assert.False(t, utils.IsRetryable(err))
+	assert.Equal(t, 1, publisher.attempts)
+
+	publisher = &failingPublisher{errs: []error{utils.TransientError(errors.New("throttled")), utils.TransientError(errors.New("throttled")), utils.TransientError(errors.New("throttled"))}}
+	sink = &queueSink{publishers: []queuePublisher{publisher}, retrier: utils.NewRetrier("queue", policy)}
+	err = sink.publish(context.Background(), []queueMessage{message})
+	assert.EqualError(t, err, "could not publish 1 messages to failing: publish failed after 3 attempts: throttled")
+	assert.True(t, utils.IsRetryable(err))
+}
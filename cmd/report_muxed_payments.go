@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+// muxedPaymentsRow is the payments of one muxed id of the base account, or of the base account itself, in an asset
+type muxedPaymentsRow struct {
+	MuxedID        *uint64        `json:"muxed_id,omitempty"`
+	AccountMuxed   string         `json:"account_muxed,omitempty"`
+	Asset          string         `json:"asset"`
+	Credits        int            `json:"credits"`
+	Debits         int            `json:"debits"`
+	AmountCredited string         `json:"amount_credited"`
+	AmountDebited  string         `json:"amount_debited"`
+	NetAmount      string         `json:"net_amount"`
+	FirstLedger    uint32         `json:"first_ledger"`
+	LastLedger     uint32         `json:"last_ledger"`
+	OperationTypes map[string]int `json:"operation_types,omitempty"`
+	credited       int64
+	debited        int64
+}
+
+// muxedPaymentsReport is the reconciliation of the muxed ids of a base account over a range
+type muxedPaymentsReport struct {
+	Account string `json:"account"`
+	Start   uint32 `json:"start_ledger"`
+	End     uint32 `json:"end_ledger"`
+	Effects int    `json:"effects"`
+	// MuxedAccounts are the payments of every muxed id, ordered by muxed id and asset
+	MuxedAccounts []*muxedPaymentsRow `json:"muxed_accounts"`
+	// Unmuxed are the payments of the base account made without a muxed id, such as deposits sent to its G address
+	Unmuxed []*muxedPaymentsRow `json:"unmuxed"`
+}
+
+// muxedPaymentsEffect holds the columns of an exported effect that the report reads. The rest of the row, such as
+// its timestamps whatever their format, is ignored.
+type muxedPaymentsEffect struct {
+	Address        string                 `json:"address"`
+	AddressMuxed   null.String            `json:"address_muxed"`
+	OperationID    int64                  `json:"operation_id"`
+	Type           int32                  `json:"type"`
+	LedgerSequence uint32                 `json:"ledger_sequence"`
+	EffectID       string                 `json:"id"`
+	Details        map[string]interface{} `json:"details"`
+}
+
+// muxedPaymentsOperation holds the columns of an exported operation that the report reads
+type muxedPaymentsOperation struct {
+	OperationID int64  `json:"id"`
+	TypeString  string `json:"type_string"`
+}
+
+var reportMuxedPaymentsCmd = &cobra.Command{
+	Use:   "report_muxed_payments",
+	Short: "Reconciles the payments of the muxed ids of an account from exported effects",
+	Long: `Reads the json output of export_effects and summarizes, for every muxed id of a base account and every asset,
+the number and amount of the credits and debits over a ledger range, along with the payments made to or from the base
+account without a muxed id. Amounts are exact decimal strings. When the json output of export_operations is given too,
+each row also counts its payments by operation type. The report is written as json.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+
+		account, err := cmd.Flags().GetString("account")
+		if err != nil {
+			cmdLogger.Fatal("could not get account: ", err)
+		}
+		if !strkey.IsValidEd25519PublicKey(account) {
+			cmdLogger.Fatalf("account %s is not a valid base account address", account)
+		}
+
+		effectsPaths, err := cmd.Flags().GetStringSlice("effects")
+		if err != nil {
+			cmdLogger.Fatal("could not get effects: ", err)
+		}
+
+		operationsPaths, err := cmd.Flags().GetStringSlice("operations")
+		if err != nil {
+			cmdLogger.Fatal("could not get operations: ", err)
+		}
+
+		start, err := cmd.Flags().GetUint32("start-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get start-ledger: ", err)
+		}
+
+		end, err := cmd.Flags().GetUint32("end-ledger")
+		if err != nil {
+			cmdLogger.Fatal("could not get end-ledger: ", err)
+		}
+		if end != 0 && end < start {
+			cmdLogger.Fatalf("end-ledger %d is before start-ledger %d", end, start)
+		}
+
+		path, err := cmd.Flags().GetString("output")
+		if err != nil {
+			cmdLogger.Fatal("could not get output: ", err)
+		}
+
+		operationTypes, err := readOperationTypes(operationsPaths)
+		if err != nil {
+			cmdLogger.Fatal("could not read operations: ", err)
+		}
+
+		report, err := reportMuxedPayments(account, start, end, effectsPaths, operationTypes)
+		if err != nil {
+			cmdLogger.Fatal("could not report the muxed payments: ", err)
+		}
+
+		if err := writeJSONReport(path, report); err != nil {
+			cmdLogger.Fatal(err)
+		}
+		cmdLogger.Infof("Payments of %d muxed accounts of %s written to %s", len(report.MuxedAccounts), account, path)
+	},
+}
+
+// reportMuxedPayments folds the credit and debit effects of the base account in the ledger range into the report.
+// An end of 0 leaves the range open. The operation types, keyed by operation id, are optional.
+func reportMuxedPayments(account string, start, end uint32, effectsPaths []string, operationTypes map[int64]string) (muxedPaymentsReport, error) {
+	report := muxedPaymentsReport{Account: account, Start: start, End: end}
+	muxed := map[string]*muxedPaymentsRow{}
+	unmuxed := map[string]*muxedPaymentsRow{}
+
+	for _, path := range effectsPaths {
+		err := decodeJSONLines(path, func(decoder *json.Decoder) error {
+			var effect muxedPaymentsEffect
+			if err := decoder.Decode(&effect); err != nil {
+				return err
+			}
+
+			effectType := transform.EffectType(effect.Type)
+			if effectType != transform.EffectAccountCredited && effectType != transform.EffectAccountDebited {
+				return nil
+			}
+			if effect.Address != account || effect.LedgerSequence < start || (end != 0 && effect.LedgerSequence > end) {
+				return nil
+			}
+
+			row, err := muxedPaymentsRowOf(effect, muxed, unmuxed)
+			if err != nil {
+				return err
+			}
+			stroops, err := amount.ParseInt64(detailString(effect.Details, "amount"))
+			if err != nil {
+				return fmt.Errorf("could not parse the amount of effect %s: %v", effect.EffectID, err)
+			}
+
+			if effectType == transform.EffectAccountCredited {
+				row.Credits++
+				row.credited += stroops
+			} else {
+				row.Debits++
+				row.debited += stroops
+			}
+			if row.FirstLedger == 0 || effect.LedgerSequence < row.FirstLedger {
+				row.FirstLedger = effect.LedgerSequence
+			}
+			if effect.LedgerSequence > row.LastLedger {
+				row.LastLedger = effect.LedgerSequence
+			}
+			if operationType, ok := operationTypes[effect.OperationID]; ok {
+				if row.OperationTypes == nil {
+					row.OperationTypes = map[string]int{}
+				}
+				row.OperationTypes[operationType]++
+			}
+			report.Effects++
+			return nil
+		})
+		if err != nil {
+			return report, fmt.Errorf("could not read effects %s: %v", path, err)
+		}
+	}
+
+	report.MuxedAccounts = muxedPaymentsRows(muxed)
+	report.Unmuxed = muxedPaymentsRows(unmuxed)
+	return report, nil
+}
+
+// muxedPaymentsRowOf returns the row of the muxed id and asset of an effect, adding it if it is the first one
+func muxedPaymentsRowOf(effect muxedPaymentsEffect, muxed, unmuxed map[string]*muxedPaymentsRow) (*muxedPaymentsRow, error) {
+	asset := detailString(effect.Details, "asset_type")
+	if asset != "native" {
+		asset = detailString(effect.Details, "asset_code") + ":" + detailString(effect.Details, "asset_issuer")
+	}
+
+	rows := unmuxed
+	row := &muxedPaymentsRow{Asset: asset}
+	if effect.AddressMuxed.Valid {
+		muxedAccount, err := xdr.AddressToMuxedAccount(effect.AddressMuxed.String)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode muxed account %s of effect %s: %v", effect.AddressMuxed.String, effect.EffectID, err)
+		}
+		muxedID, err := muxedAccount.GetId()
+		if err != nil {
+			return nil, fmt.Errorf("could not get the id of muxed account %s of effect %s: %v", effect.AddressMuxed.String, effect.EffectID, err)
+		}
+		row.MuxedID = &muxedID
+		row.AccountMuxed = effect.AddressMuxed.String
+		rows = muxed
+	}
+
+	key := row.AccountMuxed + "/" + asset
+	if existing, ok := rows[key]; ok {
+		return existing, nil
+	}
+	rows[key] = row
+	return row, nil
+}
+
+// muxedPaymentsRows returns the rows ordered by muxed id and asset, with their amounts formatted
+func muxedPaymentsRows(rows map[string]*muxedPaymentsRow) []*muxedPaymentsRow {
+	ordered := make([]*muxedPaymentsRow, 0, len(rows))
+	for _, row := range rows {
+		row.AmountCredited = amount.StringFromInt64(row.credited)
+		row.AmountDebited = amount.StringFromInt64(row.debited)
+		row.NetAmount = amount.StringFromInt64(row.credited - row.debited)
+		ordered = append(ordered, row)
+	}
+	sort.Slice(ordered, func(i, j int) bool {
+		if ordered[i].AccountMuxed != ordered[j].AccountMuxed {
+			return *ordered[i].MuxedID < *ordered[j].MuxedID
+		}
+		return ordered[i].Asset < ordered[j].Asset
+	})
+	return ordered
+}
+
+// readOperationTypes reads the type of every operation of the json outputs of export_operations, keyed by operation id
+func readOperationTypes(paths []string) (map[int64]string, error) {
+	operationTypes := map[int64]string{}
+	for _, path := range paths {
+		err := decodeJSONLines(path, func(decoder *json.Decoder) error {
+			var operation muxedPaymentsOperation
+			if err := decoder.Decode(&operation); err != nil {
+				return err
+			}
+			operationTypes[operation.OperationID] = operation.TypeString
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not read operations %s: %v", path, err)
+		}
+	}
+	return operationTypes, nil
+}
+
+// decodeJSONLines calls decode for every row of a json lines file until the end of the file
+func decodeJSONLines(path string, decode func(*json.Decoder) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		if err := decode(decoder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func detailString(details map[string]interface{}, key string) string {
+	value, _ := details[key].(string)
+	return value
+}
+
+func init() {
+	rootCmd.AddCommand(reportMuxedPaymentsCmd)
+	reportMuxedPaymentsCmd.Flags().String("account", "", "Base account whose muxed ids are reconciled")
+	reportMuxedPaymentsCmd.Flags().StringSlice("effects", nil, "Json outputs of export_effects to read the payments from")
+	reportMuxedPaymentsCmd.Flags().StringSlice("operations", nil, "Json outputs of export_operations to count the payments by operation type from")
+	reportMuxedPaymentsCmd.Flags().Uint32("start-ledger", 0, "First ledger of the range to report")
+	reportMuxedPaymentsCmd.Flags().Uint32("end-ledger", 0, "Last ledger of the range to report; the range is open if 0")
+	reportMuxedPaymentsCmd.Flags().StringP("output", "o", "muxed_payments_report.json", "Filename of the json report")
+	reportMuxedPaymentsCmd.MarkFlagRequired("account")
+	reportMuxedPaymentsCmd.MarkFlagRequired("effects")
+
+	/*
+		Current flags:
+			account: base account whose muxed ids are reconciled (required)
+			effects: json outputs of export_effects (required)
+			operations: json outputs of export_operations, to count the payments by operation type
+
+			start-ledger: first ledger of the range to report
+			end-ledger: last ledger of the range to report; the range is open if 0
+
+			output: filename of the json report
+	*/
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+const (
+	muxedReportTestAccount = "GAOEOQMXDDXPVJC3HDFX6LZFKANJ4OOLQOD2MNXJ7PGAY5FEO4BRRAQU"
+	muxedReportTestOther   = "GBRPYHIL2CI3FNQ4BXLFMNDLFJUNPU2HY3ZMFSHONUCEOASW7QC7OX2H"
+)
+
+func muxedReportTestAddress(t *testing.T, id uint64) string {
+	muxed, err := xdr.MuxedAccountFromAccountId(muxedReportTestAccount, id)
+	require.NoError(t, err)
+	return muxed.Address()
+}
+
+func muxedReportTestEffect(address, muxed string, effectType transform.EffectType, operationID int64, ledger uint32, details map[string]interface{}) transform.EffectOutput {
+	return transform.EffectOutput{
+		Address:        address,
+		AddressMuxed:   null.NewString(muxed, muxed != ""),
+		OperationID:    operationID,
+		Type:           int32(effectType),
+		TypeString:     transform.EffectTypeNames[effectType],
+		LedgerSequence: ledger,
+		Details:        details,
+	}
+}
+
+func writeJSONLinesFile(t *testing.T, path string, rows ...interface{}) {
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	defer file.Close()
+	for _, row := range rows {
+		marshalled, err := json.Marshal(row)
+		require.NoError(t, err)
+		_, err = file.Write(append(marshalled, '\n'))
+		require.NoError(t, err)
+	}
+}
+
+func TestReportMuxedPayments(t *testing.T) {
+	dir := t.TempDir()
+	first, second := muxedReportTestAddress(t, 7), muxedReportTestAddress(t, 0)
+	native := map[string]interface{}{"asset_type": "native", "amount": "10.0000000"}
+	usd := map[string]interface{}{"asset_type": "credit_alphanum4", "asset_code": "USD", "asset_issuer": muxedReportTestOther, "amount": "2.5000000"}
+
+	effectsPath := filepath.Join(dir, "effects.txt")
+	writeJSONLinesFile(t, effectsPath,
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectAccountCredited, 1, 10, native),
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectAccountCredited, 2, 12, native),
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectAccountDebited, 3, 14, map[string]interface{}{"asset_type": "native", "amount": "25.0000000"}),
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectAccountCredited, 4, 15, usd),
+		muxedReportTestEffect(muxedReportTestAccount, second, transform.EffectAccountCredited, 5, 15, native),
+		muxedReportTestEffect(muxedReportTestAccount, "", transform.EffectAccountCredited, 6, 16, native),
+		// outside of the range, of another account or not a payment
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectAccountCredited, 7, 30, native),
+		muxedReportTestEffect(muxedReportTestOther, "", transform.EffectAccountDebited, 1, 10, native),
+		muxedReportTestEffect(muxedReportTestAccount, first, transform.EffectSignerCreated, 8, 11, map[string]interface{}{"weight": 1}),
+	)
+
+	operationsPath := filepath.Join(dir, "operations.txt")
+	writeJSONLinesFile(t, operationsPath,
+		transform.OperationOutput{OperationID: 1, TypeString: "payment"},
+		transform.OperationOutput{OperationID: 2, TypeString: "path_payment_strict_send"},
+		transform.OperationOutput{OperationID: 3, TypeString: "payment"},
+	)
+
+	operationTypes, err := readOperationTypes([]string{operationsPath})
+	require.NoError(t, err)
+
+	report, err := reportMuxedPayments(muxedReportTestAccount, 10, 20, []string{effectsPath}, operationTypes)
+	require.NoError(t, err)
+
+	zero, seven := uint64(0), uint64(7)
+	assert.Equal(t, muxedPaymentsReport{
+		Account: muxedReportTestAccount,
+		Start:   10,
+		End:     20,
+		Effects: 6,
+		MuxedAccounts: []*muxedPaymentsRow{
+			{
+				MuxedID: &zero, AccountMuxed: second, Asset: "native",
+				Credits: 1, AmountCredited: "10.0000000", AmountDebited: "0.0000000", NetAmount: "10.0000000",
+				FirstLedger: 15, LastLedger: 15, credited: 10_0000000,
+			},
+			{
+				MuxedID: &seven, AccountMuxed: first, Asset: "USD:" + muxedReportTestOther,
+				Credits: 1, AmountCredited: "2.5000000", AmountDebited: "0.0000000", NetAmount: "2.5000000",
+				FirstLedger: 15, LastLedger: 15, credited: 2_5000000,
+			},
+			{
+				MuxedID: &seven, AccountMuxed: first, Asset: "native",
+				Credits: 2, Debits: 1, AmountCredited: "20.0000000", AmountDebited: "25.0000000", NetAmount: "-5.0000000",
+				FirstLedger: 10, LastLedger: 14, credited: 20_0000000, debited: 25_0000000,
+				OperationTypes: map[string]int{"payment": 2, "path_payment_strict_send": 1},
+			},
+		},
+		Unmuxed: []*muxedPaymentsRow{
+			{
+				Asset:   "native",
+				Credits: 1, AmountCredited: "10.0000000", AmountDebited: "0.0000000", NetAmount: "10.0000000",
+				FirstLedger: 16, LastLedger: 16, credited: 10_0000000,
+			},
+		},
+	}, report)
+}
+
+func TestReportMuxedPaymentsOpenRange(t *testing.T) {
+	effectsPath := filepath.Join(t.TempDir(), "effects.txt")
+	writeJSONLinesFile(t, effectsPath,
+		muxedReportTestEffect(muxedReportTestAccount, muxedReportTestAddress(t, 1), transform.EffectAccountCredited, 1, 10, map[string]interface{}{"asset_type": "native", "amount": "1.0000000"}),
+		muxedReportTestEffect(muxedReportTestAccount, muxedReportTestAddress(t, 1), transform.EffectAccountCredited, 2, 9000, map[string]interface{}{"asset_type": "native", "amount": "1.0000000"}),
+	)
+
+	report, err := reportMuxedPayments(muxedReportTestAccount, 0, 0, []string{effectsPath}, nil)
+	require.NoError(t, err)
+	require.Len(t, report.MuxedAccounts, 1)
+	assert.Equal(t, 2, report.MuxedAccounts[0].Credits)
+	assert.Equal(t, "2.0000000", report.MuxedAccounts[0].NetAmount)
+	assert.Nil(t, report.MuxedAccounts[0].OperationTypes)
+	assert.Empty(t, report.Unmuxed)
+}
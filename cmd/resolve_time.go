@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+)
+
+type resolvedTime struct {
+	Time   string `json:"time"`
+	Ledger int64  `json:"ledger"`
+}
+
+var resolveTimeCmd = &cobra.Command{
+	Use:     "resolve_time",
+	Aliases: []string{"resolve-time"},
+	Short:   "Converts a time into the sequence of the ledger containing it",
+	Long: `Converts a time into the sequence of the ledger containing it, which is the first ledger that closed on or after
+	it, by binary searching the ledger headers of the history archives. Times must be in the format
+	YYYY-MM-DDTHH:MM:SS+HH:MM, such as 2019-09-13T23:00:00+00:00. Times after the most recent ledger resolve to it
+	and times before the network started resolve to ledger 2.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		timeString, err := cmd.Flags().GetString("time")
+		if err != nil {
+			cmdLogger.Fatal("could not get time: ", err)
+		}
+
+		isTest, err := cmd.Flags().GetBool("testnet")
+		if err != nil {
+			cmdLogger.Fatal("could not get testnet boolean: ", err)
+		}
+
+		isFuture, err := cmd.Flags().GetBool("futurenet")
+		if err != nil {
+			cmdLogger.Fatal("could not get futurenet boolean: ", err)
+		}
+
+		targetTime, err := time.Parse("2006-01-02T15:04:05-07:00", timeString)
+		if err != nil {
+			cmdLogger.Fatal("could not parse time: ", err)
+		}
+
+		ledger, err := input.GetLedgerForTime(targetTime, isTest, isFuture)
+		if err != nil {
+			cmdLogger.Fatal("could not resolve time: ", err)
+		}
+
+		marshalled, err := json.Marshal(resolvedTime{Time: timeString, Ledger: ledger})
+		if err != nil {
+			cmdLogger.Fatal("could not json encode resolved time", err)
+		}
+		fmt.Println(string(marshalled))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(resolveTimeCmd)
+
+	resolveTimeCmd.Flags().StringP("time", "t", "", "The time to resolve")
+	resolveTimeCmd.Flags().Bool("testnet", false, "If set, the batch job will connect to testnet instead of mainnet.")
+	resolveTimeCmd.Flags().Bool("futurenet", false, "If set, the batch job will connect to futurenet instead of mainnet.")
+
+	resolveTimeCmd.MarkFlagRequired("time")
+}
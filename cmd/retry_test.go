@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func retryTestLedger(sequence uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)}},
+		},
+	}
+}
+
+// retryTestBackends returns a function creating the backends in turn, and the number of backends created
+func retryTestBackends(backends ...*ledgerbackend.MockDatabaseBackend) (func() (ledgerbackend.LedgerBackend, error), *int) {
+	created := 0
+	return func() (ledgerbackend.LedgerBackend, error) {
+		if created == len(backends) {
+			return nil, errors.New("no backend left")
+		}
+		created++
+		return backends[created-1], nil
+	}, &created
+}
+
+func TestWithRetriesCreatesFailedBackendAgain(t *testing.T) {
+	var out bytes.Buffer
+	logger := utils.NewEtlLogger()
+	logger.SetOutput(&out)
+	logger.Configure(logrus.InfoLevel, "text")
+
+	failing := &ledgerbackend.MockDatabaseBackend{}
+	failing.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 20)).Return(nil).Once()
+	failing.On("GetLedger", mock.Anything, uint32(10)).Return(retryTestLedger(10), nil).Once()
+	failing.On("GetLedger", mock.Anything, uint32(11)).Return(xdr.LedgerCloseMeta{}, errors.New("503 from the datastore")).Once()
+	failing.On("Close").Return(nil).Once()
+
+	// the backend created again is prepared from the ledger that failed
+	recreated := &ledgerbackend.MockDatabaseBackend{}
+	recreated.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(11, 20)).Return(nil).Once()
+	recreated.On("GetLedger", mock.Anything, uint32(11)).Return(retryTestLedger(11), nil).Once()
+	recreated.On("Close").Return(nil).Once()
+
+	newBackend, created := retryTestBackends(failing, recreated)
+	backend, err := utils.WithRetries(newBackend, "datastore", utils.RetryPolicy{Attempts: 3, Logger: logger})
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)))
+	lcm, err := backend.GetLedger(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(10), lcm.LedgerSequence())
+	lcm, err = backend.GetLedger(ctx, 11)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), lcm.LedgerSequence())
+	require.NoError(t, backend.Close())
+
+	assert.Equal(t, 2, *created)
+	failing.AssertExpectations(t)
+	recreated.AssertExpectations(t)
+	assert.Contains(t, out.String(), "get_ledger on datastore failed, attempt 1 of 3")
+}
+
+func TestWithRetriesStops(t *testing.T) {
+	ctx := context.Background()
+
+	// errors that fail the same way every time are returned at once
+	permanent := &ledgerbackend.MockDatabaseBackend{}
+	permanent.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 20)).Return(utils.PermanentError(errors.New("403 from the datastore"))).Once()
+	permanent.On("Close").Return(nil).Once()
+	newBackend, created := retryTestBackends(permanent)
+	backend, err := utils.WithRetries(newBackend, "datastore", utils.RetryPolicy{Attempts: 3})
+	require.NoError(t, err)
+	assert.EqualError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)), "403 from the datastore")
+	assert.Equal(t, 1, *created)
+	permanent.AssertExpectations(t)
+
+	// transient errors are retried until the attempts run out
+	backends := []*ledgerbackend.MockDatabaseBackend{}
+	for i := 0; i < 2; i++ {
+		transient := &ledgerbackend.MockDatabaseBackend{}
+		transient.On("GetLatestLedgerSequence", mock.Anything).Return(uint32(0), errors.New("503 from the datastore")).Once()
+		transient.On("Close").Return(nil).Once()
+		backends = append(backends, transient)
+	}
+	newBackend, created = retryTestBackends(backends...)
+	backend, err = utils.WithRetries(newBackend, "datastore", utils.RetryPolicy{Attempts: 2})
+	require.NoError(t, err)
+	_, err = backend.GetLatestLedgerSequence(ctx)
+	assert.EqualError(t, err, "get_latest_ledger_sequence failed after 2 attempts: 503 from the datastore")
+	assert.Equal(t, 2, *created)
+	for _, transient := range backends {
+		transient.AssertExpectations(t)
+	}
+
+	// without retries the backend is not wrapped
+	single := &ledgerbackend.MockDatabaseBackend{}
+	newBackend, _ = retryTestBackends(single)
+	backend, err = utils.WithRetries(newBackend, "datastore", utils.RetryPolicy{Attempts: 1})
+	require.NoError(t, err)
+	assert.Same(t, single, backend)
+}
@@ -1,8 +1,10 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -12,6 +14,8 @@ import (
 )
 
 var cfgFile string
+var otlpEndpoint string
+var shutdownTelemetry = func(context.Context) error { return nil }
 
 var cmdLogger = utils.NewEtlLogger()
 
@@ -20,6 +24,30 @@ var rootCmd = &cobra.Command{
 	Use:   "stellar-etl",
 	Short: "Stellar Development Foundation ETL.",
 	Long:  `A tool to extract data from the historical record of the Stellar network.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		commandLine := commandLineFlags(cmd)
+		if err := loadConfig(cmd, args); err != nil {
+			return err
+		}
+		if err := writeRunConfig(cmd, commandLine); err != nil {
+			return err
+		}
+		if err := checkCloudCredentials(cmd); err != nil {
+			return err
+		}
+
+		var err error
+		shutdownTelemetry, err = utils.SetupTelemetry(context.Background(), otlpEndpoint)
+		if err != nil {
+			return fmt.Errorf("could not set up telemetry: %v", err)
+		}
+		return nil
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return shutdownTelemetry(ctx)
+	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	//	Run: func(cmd *cobra.Command, args []string) { },
@@ -41,7 +69,11 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.stellar-etl.yaml)")
+	rootCmd.PersistentFlags().StringVar(&otlpEndpoint, "otlp-endpoint", "", "If set, push traces and metrics over OTLP/HTTP to this endpoint, e.g. http://localhost:4318. "+
+		"The standard OTEL_EXPORTER_OTLP_* variables are also honored")
+	rootCmd.PersistentFlags().StringVar(&runConfigFormat, "run-config-format", "json", "Format of the run config written next to the output at the start of the run, with the resolved flags and the code version "+
+		"to reproduce the export: json, toml or none")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.stellar-etl.yaml). Keys are flag names, either at the top level or under a section named after the command; flags set on the command line take precedence")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -68,8 +100,12 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. A config file that was asked for explicitly must be readable.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		// stderr keeps stdout for the rows of the exports streamed to it
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	} else if cfgFile != "" {
+		fmt.Fprintln(os.Stderr, "could not read config file:", err)
+		os.Exit(1)
 	}
 }
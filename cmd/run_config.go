@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var runConfigFormat string
+
+// Sources of the values of the flags in the run config
+const (
+	runConfigSourceCommandLine = "command_line"
+	runConfigSourceConfigFile  = "config_file"
+	runConfigSourceDefault     = "default"
+)
+
+// redactedValue replaces the values of the flags holding secrets in the run config
+const redactedValue = "REDACTED"
+
+// runConfig is the fully resolved configuration of a run, written next to its output so that the export can be
+// reproduced later with the same flags and the same code
+type runConfig struct {
+	Command          string                   `json:"command" toml:"command"`
+	Args             []string                 `json:"args" toml:"args"`
+	ConfigFile       string                   `json:"config_file,omitempty" toml:"config_file,omitempty"`
+	EtlVersion       string                   `json:"etl_version" toml:"etl_version"`
+	TransformVersion string                   `json:"transform_version" toml:"transform_version"`
+	GoVersion        string                   `json:"go_version" toml:"go_version"`
+	StartedAt        string                   `json:"started_at" toml:"started_at"`
+	Flags            map[string]runConfigFlag `json:"flags" toml:"flags"`
+}
+
+type runConfigFlag struct {
+	Value  string `json:"value" toml:"value"`
+	Source string `json:"source" toml:"source"`
+}
+
+// commandLineFlags returns the names of the flags of a command that were set on the command line. It has to be
+// called before the config file is applied, which marks the flags it sets as changed too.
+func commandLineFlags(cmd *cobra.Command) map[string]bool {
+	changed := map[string]bool{}
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		changed[flag.Name] = true
+	})
+	return changed
+}
+
+// newRunConfig resolves the value and the source of every flag of a command, after the config file was applied
+func newRunConfig(cmd *cobra.Command, commandLine map[string]bool, startedAt time.Time) runConfig {
+	etlVersion, transformVersion := utils.BuildVersions()
+	config := runConfig{
+		Command:          cmd.CommandPath(),
+		Args:             redactArgs(cmd, os.Args[1:]),
+		ConfigFile:       viper.ConfigFileUsed(),
+		EtlVersion:       etlVersion,
+		TransformVersion: transformVersion,
+		GoVersion:        runtime.Version(),
+		StartedAt:        startedAt.UTC().Format(time.RFC3339),
+		Flags:            map[string]runConfigFlag{},
+	}
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		source := runConfigSourceDefault
+		if commandLine[flag.Name] {
+			source = runConfigSourceCommandLine
+		} else if flag.Changed {
+			source = runConfigSourceConfigFile
+		}
+		config.Flags[flag.Name] = runConfigFlag{Value: redactValue(flag.Name, flag.Value.String()), Source: source}
+	})
+	return config
+}
+
+// isSecretFlag tells whether the value of a flag must be left out of the run config
+func isSecretFlag(name string) bool {
+	for _, secret := range []string{"secret", "password", "token"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactValue returns the value of a flag as written in the run config. The values of the secret flags are replaced,
+// and so are the query and the password of URLs, which can hold credentials such as the signature of a signed
+// datastore URL.
+func redactValue(name, value string) string {
+	if value == "" {
+		return value
+	}
+	if isSecretFlag(name) {
+		return redactedValue
+	}
+	return redactURL(value)
+}
+
+// redactURL replaces the query and the password of a URL, and returns other values as they are
+func redactURL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return value
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redactedValue
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), redactedValue)
+	}
+	return u.String()
+}
+
+// redactArgs returns the command line with the values of the secret flags and the credentials of URLs replaced
+func redactArgs(cmd *cobra.Command, args []string) []string {
+	redacted := make([]string, len(args))
+	copy(redacted, args)
+	for i, arg := range redacted {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "--") || cmd.Flags().Lookup(name) == nil {
+			// the values given apart from their flags may be URLs too
+			redacted[i] = redactURL(arg)
+			continue
+		}
+		if hasValue {
+			redacted[i] = "--" + name + "=" + redactValue(name, value)
+		} else if isSecretFlag(name) && i+1 < len(redacted) {
+			redacted[i+1] = redactedValue
+		}
+	}
+	return redacted
+}
+
+// runConfigPath returns where the run config of an output is written: next to it, with the extension of the format
+func runConfigPath(output, format string) string {
+	return strings.TrimSuffix(output, string(filepath.Separator)) + ".run." + format
+}
+
+// writeRunConfig writes the run config of a command next to its output, in the format of the run-config-format flag.
+// Commands without an output file, or writing to stdout, have no run config.
+func writeRunConfig(cmd *cobra.Command, commandLine map[string]bool) error {
+	if runConfigFormat == "none" {
+		return nil
+	}
+	outputFlag := cmd.Flags().Lookup("output")
+	if outputFlag == nil || outputFlag.Value.String() == "" || outputFlag.Value.String() == utils.StdoutPath {
+		return nil
+	}
+	if stdout, err := cmd.Flags().GetBool("stdout"); err == nil && stdout {
+		return nil
+	}
+
+	config := newRunConfig(cmd, commandLine, time.Now())
+	var marshalled []byte
+	var err error
+	switch runConfigFormat {
+	case "json":
+		marshalled, err = json.MarshalIndent(config, "", "  ")
+	case "toml":
+		marshalled, err = toml.Marshal(config)
+	default:
+		return fmt.Errorf("unknown run config format %s, expected json, toml or none", runConfigFormat)
+	}
+	if err != nil {
+		return fmt.Errorf("could not marshal run config: %v", err)
+	}
+
+	path := runConfigPath(outputFlag.Value.String(), runConfigFormat)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("could not create directory for run config %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, append(marshalled, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write run config %s: %v", path, err)
+	}
+	cmdLogger.Infof("Wrote run config to %s", path)
+	return nil
+}
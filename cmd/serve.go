@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/etlpb"
+)
+
+// transformServer serves the transforms over gRPC. Ledger ranges are read from a new backend per request.
+type transformServer struct {
+	etlpb.UnimplementedTransformServiceServer
+	networkPassphrase string
+	ids               toid.Generator
+	maxLedgers        uint32
+	newBackend        func(ctx context.Context) (ledgerbackend.LedgerBackend, error)
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serves the transforms over gRPC",
+	Long: `Starts a gRPC server exposing the stellar_etl.v1.TransformService defined in proto/stellar_etl/v1/transform.proto.
+Given a ledger range, which is read from the datastore, or a list of LedgerCloseMeta, the server streams the
+transformed rows of the requested tables as json. This lets other services reuse the transforms without embedding
+the Go module.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		address, err := cmd.Flags().GetString("grpc-address")
+		if err != nil {
+			cmdLogger.Fatal("could not get grpc-address: ", err)
+		}
+
+		maxLedgers, err := cmd.Flags().GetUint32("max-ledgers")
+		if err != nil {
+			cmdLogger.Fatal("could not get max-ledgers: ", err)
+		}
+
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			cmdLogger.Fatalf("could not listen on %s: %v", address, err)
+		}
+
+		server := grpc.NewServer()
+		etlpb.RegisterTransformServiceServer(server, &transformServer{
+			networkPassphrase: env.NetworkPassphrase,
+			ids:               commonArgs.Toid,
+			maxLedgers:        maxLedgers,
+			newBackend: func(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+				return utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+			},
+		})
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			<-ctx.Done()
+			cmdLogger.Info("Stopping the gRPC server")
+			server.GracefulStop()
+		}()
+
+		cmdLogger.WithFields(log.F{utils.LogFieldNetwork: env.Network}).Infof("Serving transforms on %s", listener.Addr())
+		if err := server.Serve(listener); err != nil {
+			cmdLogger.Fatal("could not serve: ", err)
+		}
+	},
+}
+
+func (s *transformServer) ListTables(ctx context.Context, request *etlpb.ListTablesRequest) (*etlpb.ListTablesResponse, error) {
+	response := &etlpb.ListTablesResponse{}
+	for _, table := range input.LedgerTables() {
+		response.Tables = append(response.Tables, table.Name)
+	}
+	return response, nil
+}
+
+func (s *transformServer) Transform(request *etlpb.TransformRequest, stream etlpb.TransformService_TransformServer) error {
+	tables, err := input.SelectLedgerTables(request.GetTables())
+	if err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch source := request.GetSource().(type) {
+	case *etlpb.TransformRequest_Ledgers:
+		for i, encoded := range source.Ledgers.GetLedgerCloseMetas() {
+			var lcm xdr.LedgerCloseMeta
+			if err := lcm.UnmarshalBinary(encoded); err != nil {
+				return status.Errorf(codes.InvalidArgument, "could not decode ledger close meta %d: %v", i, err)
+			}
+			if err := s.sendLedger(stream, tables, lcm); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *etlpb.TransformRequest_Range:
+		return s.sendRange(stream, tables, source.Range.GetStartLedger(), source.Range.GetEndLedger())
+
+	default:
+		return status.Error(codes.InvalidArgument, "either a ledger range or ledgers are required")
+	}
+}
+
+func (s *transformServer) sendRange(stream etlpb.TransformService_TransformServer, tables []input.LedgerTable, start, end uint32) error {
+	if start == 0 || end < start {
+		return status.Errorf(codes.InvalidArgument, "invalid ledger range [%d, %d]", start, end)
+	}
+	if s.maxLedgers > 0 && end-start+1 > s.maxLedgers {
+		return status.Errorf(codes.InvalidArgument, "ledger range [%d, %d] is larger than the maximum of %d ledgers", start, end, s.maxLedgers)
+	}
+
+	ctx := stream.Context()
+	backend, err := s.newBackend(ctx)
+	if err != nil {
+		return status.Errorf(codes.Unavailable, "could not create ledger backend: %v", err)
+	}
+	defer backend.Close()
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return status.Errorf(codes.Unavailable, "could not prepare range [%d, %d]: %v", start, end, err)
+	}
+
+	for seq := start; seq <= end; seq++ {
+		lcm, err := backend.GetLedger(ctx, seq)
+		if err != nil {
+			if ctx.Err() != nil {
+				return status.FromContextError(ctx.Err()).Err()
+			}
+			return status.Errorf(codes.Unavailable, "error getting ledger seq %d from the backend: %v", seq, err)
+		}
+		if err := s.sendLedger(stream, tables, lcm); err != nil {
+			return err
+		}
+	}
+
+	cmdLogger.WithFields(log.F{utils.LogFieldLedgerStart: start, utils.LogFieldLedgerEnd: end}).Debug("Served ledger range")
+	return nil
+}
+
+// sendLedger streams the rows of the tables for a ledger. A transform error fails the whole request, since
+// skipping the rows would leave the caller with an incomplete ledger.
+func (s *transformServer) sendLedger(stream etlpb.TransformService_TransformServer, tables []input.LedgerTable, lcm xdr.LedgerCloseMeta) error {
+	seq := lcm.LedgerSequence()
+	ledger, err := input.DecodeLedgerParts(lcm, s.networkPassphrase, input.TablesReads(tables))
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "could not decode ledger %d: %v", seq, err)
+	}
+	ledger.Toid = s.ids
+
+	for _, table := range tables {
+		rows, err := table.Transform(ledger, s.networkPassphrase)
+		if err != nil {
+			return status.Errorf(codes.Internal, "could not transform %s in ledger %d: %v", table.Name, seq, err)
+		}
+
+		for _, row := range rows {
+			marshalled, err := transform.CanonicalJSON(row)
+			if err != nil {
+				return status.Errorf(codes.Internal, "could not json encode %s row in ledger %d: %v", table.Name, seq, err)
+			}
+			if err := stream.Send(&etlpb.Row{Table: table.Name, LedgerSequence: seq, Json: marshalled}); err != nil {
+				return fmt.Errorf("could not send %s row in ledger %d: %w", table.Name, seq, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	utils.AddCommonFlags(serveCmd.Flags())
+	serveCmd.Flags().String("grpc-address", ":50051", "Address the gRPC server listens on")
+	serveCmd.Flags().Uint32("max-ledgers", 1000, "Maximum number of ledgers in the range of a request; 0 for no limit")
+
+	/*
+		Current flags:
+			grpc-address: address the gRPC server listens on
+			max-ledgers: maximum number of ledgers in the range of a request
+
+			testnet/futurenet: network the ledger ranges are read from
+			datastore-path: datastore bucket path the ledger ranges are read from
+			billing-project: GCP project billed for the reads of a requester pays datastore bucket
+			datastore-url: URL the ledger ranges are read from over HTTP(S) instead of the datastore bucket
+			ledger-cache-dir: directory where the files of the ledger ranges are cached across requests
+	*/
+}
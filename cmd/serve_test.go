@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/stellar/stellar-etl/v2/pkg/etlpb"
+)
+
+func makeServeTestLedger(seq uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:      xdr.StellarValue{CloseTime: 1000},
+					LedgerSeq:     xdr.Uint32(seq),
+					LedgerVersion: 22,
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+		},
+	}
+}
+
+func newServeTestClient(t *testing.T, server *transformServer) etlpb.TransformServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := grpc.NewServer()
+	etlpb.RegisterTransformServiceServer(grpcServer, server)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return etlpb.NewTransformServiceClient(conn)
+}
+
+func receiveRows(stream etlpb.TransformService_TransformClient) ([]*etlpb.Row, error) {
+	rows := []*etlpb.Row{}
+	for {
+		row, err := stream.Recv()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return rows, err
+		}
+		rows = append(rows, row)
+	}
+}
+
+func TestServeTransformLedgers(t *testing.T) {
+	client := newServeTestClient(t, &transformServer{networkPassphrase: network.TestNetworkPassphrase})
+
+	encoded, err := makeServeTestLedger(10).MarshalBinary()
+	require.NoError(t, err)
+	stream, err := client.Transform(context.Background(), &etlpb.TransformRequest{
+		Tables: []string{"ledgers", "transactions"},
+		Source: &etlpb.TransformRequest_Ledgers{Ledgers: &etlpb.LedgerCloseMetas{LedgerCloseMetas: [][]byte{encoded}}},
+	})
+	require.NoError(t, err)
+
+	rows, err := receiveRows(stream)
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "ledgers", rows[0].Table)
+	assert.Equal(t, uint32(10), rows[0].LedgerSequence)
+
+	var ledger map[string]interface{}
+	require.NoError(t, json.Unmarshal(rows[0].Json, &ledger))
+	assert.Equal(t, float64(10), ledger["sequence"])
+}
+
+func TestServeTransformRange(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeServeTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makeServeTestLedger(11), nil)
+	backend.On("Close").Return(nil)
+
+	client := newServeTestClient(t, &transformServer{
+		networkPassphrase: network.TestNetworkPassphrase,
+		maxLedgers:        2,
+		newBackend: func(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+			return backend, nil
+		},
+	})
+
+	stream, err := client.Transform(context.Background(), &etlpb.TransformRequest{
+		Tables: []string{"ledgers"},
+		Source: &etlpb.TransformRequest_Range{Range: &etlpb.LedgerRange{StartLedger: 10, EndLedger: 11}},
+	})
+	require.NoError(t, err)
+
+	rows, err := receiveRows(stream)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, uint32(10), rows[0].LedgerSequence)
+	assert.Equal(t, uint32(11), rows[1].LedgerSequence)
+	backend.AssertExpectations(t)
+}
+
+func TestServeTransformErrors(t *testing.T) {
+	client := newServeTestClient(t, &transformServer{
+		networkPassphrase: network.TestNetworkPassphrase,
+		maxLedgers:        2,
+		newBackend: func(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+			return nil, errors.New("no datastore")
+		},
+	})
+
+	for _, testCase := range []struct {
+		request *etlpb.TransformRequest
+		code    codes.Code
+		message string
+	}{
+		{&etlpb.TransformRequest{}, codes.InvalidArgument, "either a ledger range or ledgers are required"},
+		{&etlpb.TransformRequest{Tables: []string{"unknown"}}, codes.InvalidArgument, "unknown table unknown"},
+		{
+			&etlpb.TransformRequest{Source: &etlpb.TransformRequest_Range{Range: &etlpb.LedgerRange{StartLedger: 10, EndLedger: 12}}},
+			codes.InvalidArgument, "ledger range [10, 12] is larger than the maximum of 2 ledgers",
+		},
+		{
+			&etlpb.TransformRequest{Source: &etlpb.TransformRequest_Range{Range: &etlpb.LedgerRange{StartLedger: 10, EndLedger: 11}}},
+			codes.Unavailable, "could not create ledger backend: no datastore",
+		},
+		{
+			&etlpb.TransformRequest{Source: &etlpb.TransformRequest_Ledgers{Ledgers: &etlpb.LedgerCloseMetas{LedgerCloseMetas: [][]byte{{1, 2}}}}},
+			codes.InvalidArgument, "could not decode ledger close meta 0",
+		},
+	} {
+		stream, err := client.Transform(context.Background(), testCase.request)
+		require.NoError(t, err)
+		_, err = receiveRows(stream)
+		assert.Equal(t, testCase.code, status.Code(err), testCase.message)
+		assert.Contains(t, status.Convert(err).Message(), testCase.message)
+	}
+}
+
+func TestServeListTables(t *testing.T) {
+	client := newServeTestClient(t, &transformServer{networkPassphrase: network.TestNetworkPassphrase})
+
+	response, err := client.ListTables(context.Background(), &etlpb.ListTablesRequest{})
+	require.NoError(t, err)
+	assert.Contains(t, response.Tables, "ledgers")
+	assert.Contains(t, response.Tables, "ttl")
+}
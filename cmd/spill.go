@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+func init() {
+	// Decoded soroban values are stored as json in the interface fields of the parquet schemas
+	gob.Register(json.RawMessage{})
+}
+
+// memoryBudget is the memory budget shared by the row buffers of an export, so that the rows buffered for every table
+// and every network stay within a single budget rather than one budget per buffer
+type memoryBudget struct {
+	maxBytes int64
+	mu       sync.Mutex
+	used     int64
+}
+
+// newMemoryBudget creates a budget in bytes. A budget of 0 keeps every row in memory.
+func newMemoryBudget(maxBytes int64) *memoryBudget {
+	return &memoryBudget{maxBytes: maxBytes}
+}
+
+// newRowMemoryBudget creates the budget of the rows buffered for parquet from the max-memory of an export. The ledger
+// files read ahead are bounded to half of max-memory, so the rows share the other half.
+func newRowMemoryBudget(maxMemory int64) *memoryBudget {
+	return newMemoryBudget(maxMemory / 2)
+}
+
+// limited tells whether rows are spilled once the budget is exceeded
+func (m *memoryBudget) limited() bool {
+	return m.maxBytes > 0
+}
+
+// reserve adds the size of a row to the bytes in use, and tells whether the budget is exceeded
+func (m *memoryBudget) reserve(size int64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used += size
+	return m.limited() && m.used > m.maxBytes
+}
+
+// release removes the size of the rows spilled or dropped by a buffer from the bytes in use
+func (m *memoryBudget) release(size int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.used -= size
+}
+
+// parquetRowBuffer holds the rows that are written to parquet once the export is done. When the rows held in
+// memory by all the buffers of the budget exceed it, the buffer that adds a row serializes its rows to a temporary
+// file, and they are read back when writing.
+type parquetRowBuffer struct {
+	budget    *memoryBudget
+	memBytes  int64
+	rows      []transform.SchemaParquet
+	spillFile *os.File
+	encoder   *gob.Encoder
+	spilled   int
+}
+
+// newParquetRowBuffer creates a row buffer whose rows count against the budget
+func newParquetRowBuffer(budget *memoryBudget) *parquetRowBuffer {
+	return &parquetRowBuffer{budget: budget}
+}
+
+// Append adds a row along with its approximate size, spilling the buffered rows to disk if the budget is exceeded.
+// stellar-etl will log a Fatal error and stop in the case it cannot spill the rows.
+func (b *parquetRowBuffer) Append(row transform.SchemaParquet, size int) {
+	b.rows = append(b.rows, row)
+	b.memBytes += int64(size)
+	if !b.budget.reserve(int64(size)) {
+		return
+	}
+
+	if err := b.spill(); err != nil {
+		cmdLogger.Fatal(err)
+	}
+}
+
+// Len is the number of rows in the buffer, including the ones spilled to disk
+func (b *parquetRowBuffer) Len() int {
+	return b.spilled + len(b.rows)
+}
+
+func (b *parquetRowBuffer) spill() error {
+	if b.spillFile == nil {
+		spillFile, err := os.CreateTemp("", "stellar-etl-spill-*.gob")
+		if err != nil {
+			return fmt.Errorf("could not create spill file: %v", err)
+		}
+		b.spillFile = spillFile
+		b.encoder = gob.NewEncoder(spillFile)
+		cmdLogger.Infof("Memory budget of %d bytes exceeded; spilling rows to %s", b.budget.maxBytes, spillFile.Name())
+	}
+
+	for _, row := range b.rows {
+		if err := b.encoder.Encode(row.ToParquet()); err != nil {
+			return fmt.Errorf("could not spill row to %s: %v", b.spillFile.Name(), err)
+		}
+	}
+	b.spilled += len(b.rows)
+	b.rows = nil
+	b.budget.release(b.memBytes)
+	b.memBytes = 0
+
+	return nil
+}
+
+// each calls fn with the parquet form of every row in the order they were appended. Spilled rows are decoded
+// into new values of the schema type.
+func (b *parquetRowBuffer) each(schema interface{}, fn func(interface{}) error) error {
+	if b.spillFile != nil {
+		if _, err := b.spillFile.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		schemaType := reflect.TypeOf(schema).Elem()
+		decoder := gob.NewDecoder(b.spillFile)
+		for i := 0; i < b.spilled; i++ {
+			row := reflect.New(schemaType)
+			if err := decoder.Decode(row.Interface()); err != nil {
+				return fmt.Errorf("could not read spilled row from %s: %v", b.spillFile.Name(), err)
+			}
+			if err := fn(row.Elem().Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range b.rows {
+		if err := fn(row.ToParquet()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Close drops the rows held in memory from the budget and removes the spill file
+func (b *parquetRowBuffer) Close() {
+	b.budget.release(b.memBytes)
+	b.rows = nil
+	b.memBytes = 0
+	if b.spillFile == nil {
+		return
+	}
+
+	b.spillFile.Close()
+	os.Remove(b.spillFile.Name())
+	b.spillFile = nil
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParquetRowBuffer(t *testing.T) {
+	closedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	rows := []transform.SchemaParquet{}
+	for i := 0; i < 5; i++ {
+		rows = append(rows, transform.ContractEventOutput{
+			TransactionHash: "hash",
+			TransactionID:   int64(i),
+			LedgerSequence:  uint32(i),
+			ClosedAt:        closedAt,
+			Topics:          []interface{}{"AAAADwAAAAh0cmFuc2Zlcg=="},
+			TopicsDecoded:   []interface{}{json.RawMessage(`{"symbol":"transfer"}`)},
+			Data:            "n/a",
+			DataDecoded:     json.RawMessage(`{"i128":"100"}`),
+		})
+	}
+
+	tests := []struct {
+		name        string
+		maxBytes    int64
+		wantSpilled bool
+	}{
+		{name: "in memory", maxBytes: 0, wantSpilled: false},
+		{name: "spilled", maxBytes: 150, wantSpilled: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buffer := newParquetRowBuffer(newMemoryBudget(test.maxBytes))
+			for _, row := range rows {
+				buffer.Append(row, 100)
+			}
+			assert.Equal(t, len(rows), buffer.Len())
+			assert.Equal(t, test.wantSpilled, buffer.spillFile != nil)
+
+			var got []interface{}
+			err := buffer.each(new(transform.ContractEventOutputParquet), func(record interface{}) error {
+				got = append(got, record)
+				return nil
+			})
+			require.NoError(t, err)
+
+			var want []interface{}
+			for _, row := range rows {
+				want = append(want, row.ToParquet())
+			}
+			assert.Equal(t, want, got)
+
+			if test.wantSpilled {
+				spillPath := buffer.spillFile.Name()
+				buffer.Close()
+				_, err := os.Stat(spillPath)
+				assert.True(t, os.IsNotExist(err))
+			}
+		})
+	}
+}
+
+func TestParquetRowBufferSharedBudget(t *testing.T) {
+	row := transform.ContractEventOutput{TransactionHash: "hash", Data: "n/a"}
+	budget := newMemoryBudget(250)
+	accounts := newParquetRowBuffer(budget)
+	defer accounts.Close()
+	offers := newParquetRowBuffer(budget)
+	defer offers.Close()
+
+	// each buffer fits the budget on its own, and the buffer that exceeds it together spills its rows
+	accounts.Append(row, 100)
+	offers.Append(row, 100)
+	assert.Nil(t, accounts.spillFile)
+	assert.Nil(t, offers.spillFile)
+	accounts.Append(row, 100)
+	assert.NotNil(t, accounts.spillFile)
+	assert.Nil(t, offers.spillFile)
+	assert.Equal(t, int64(100), budget.used)
+
+	// closing a buffer frees its share of the budget
+	offers.Close()
+	assert.Equal(t, int64(0), budget.used)
+	assert.Equal(t, 2, accounts.Len())
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// captureStdout replaces stdout with a pipe while write runs and returns what was written to it
+func captureStdout(t *testing.T, write func()) string {
+	reader, writer, err := os.Pipe()
+	require.NoError(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	defer func() { os.Stdout = stdout }()
+
+	write()
+	require.NoError(t, writer.Close())
+	written, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	return string(written)
+}
+
+func TestStdoutOutput(t *testing.T) {
+	cmd := &cobra.Command{Use: "export_things"}
+	utils.AddArchiveFlags("things", cmd.Flags())
+	dir := t.TempDir()
+	require.NoError(t, cmd.ParseFlags([]string{"--output", filepath.Join(dir, "things.txt"), "--stdout"}))
+
+	_, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+	assert.Equal(t, utils.StdoutPath, path)
+
+	outputFormat = utils.OutputFormatJSON
+	written := captureStdout(t, func() {
+		outFile := MustOutFile(path)
+		for sequence := 1; sequence <= 2; sequence++ {
+			_, err := ExportEntry(map[string]interface{}{"sequence": sequence}, outFile, map[string]string{"network": "testnet"})
+			require.NoError(t, err)
+		}
+	})
+	assert.Equal(t, "{\"network\":\"testnet\",\"sequence\":1}\n{\"network\":\"testnet\",\"sequence\":2}\n", written)
+
+	written = captureStdout(t, func() {
+		require.NoError(t, writeJSONReport(path, map[string]int{"files": 1}))
+	})
+	var report map[string]int
+	require.NoError(t, json.Unmarshal([]byte(written), &report))
+	assert.Equal(t, map[string]int{"files": 1}, report)
+
+	// neither the output file nor its run config are written
+	require.NoError(t, writeRunConfig(cmd, map[string]bool{}))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
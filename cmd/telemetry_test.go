@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupTelemetryDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	shutdown, err := utils.SetupTelemetry(context.Background(), "")
+	require.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetupTelemetryExportsOverOTLP(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] += 1
+		mu.Unlock()
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	shutdown, err := utils.SetupTelemetry(ctx, server.URL)
+	require.NoError(t, err)
+
+	spanCtx, span := utils.StartSpan(ctx, "export_batch", utils.LedgerRangeAttributes(2, 65)...)
+	utils.RecordExportedBatch(spanCtx, "testnet", 2, 65, map[string]int{"accounts": 3})
+	span.End()
+
+	// shutting down flushes the spans and metrics that were not pushed yet
+	require.NoError(t, shutdown(ctx))
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, received["/v1/traces"])
+	assert.GreaterOrEqual(t, received["/v1/metrics"], 1)
+}
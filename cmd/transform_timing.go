@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// multipleOperationTypes is the operation type of the transactions whose operations are not all of the same type
+const multipleOperationTypes = "multiple"
+
+// transformTiming is the histogram of the transform durations of the rows of one operation type, in the buckets of
+// utils.TransformDurationBuckets
+type transformTiming struct {
+	OperationType string  `json:"op_type"`
+	Count         int64   `json:"count"`
+	TotalMs       float64 `json:"total_ms"`
+	MaxMs         float64 `json:"max_ms"`
+	P50Ms         float64 `json:"p50_ms"`
+	P99Ms         float64 `json:"p99_ms"`
+	// Buckets counts the durations up to each bound of the buckets, and past the last one
+	Buckets []int64 `json:"buckets"`
+}
+
+func (t *transformTiming) add(milliseconds float64) {
+	t.Count++
+	t.TotalMs += milliseconds
+	t.MaxMs = max(t.MaxMs, milliseconds)
+	bucket := sort.SearchFloat64s(utils.TransformDurationBuckets, milliseconds)
+	t.Buckets[bucket]++
+}
+
+// quantile returns the upper bound of the bucket holding the quantile, or the maximum duration when it is past the
+// last bucket
+func (t *transformTiming) quantile(q float64) float64 {
+	rank := int64(q * float64(t.Count))
+	seen := int64(0)
+	for i, count := range t.Buckets {
+		seen += count
+		if seen > rank && i < len(utils.TransformDurationBuckets) {
+			return utils.TransformDurationBuckets[i]
+		}
+	}
+	return t.MaxMs
+}
+
+// transformTimer records how long the transforms of the rows of a table take, by operation type. A nil timer records
+// nothing, so that exports without the transform-timing flag are not slowed down.
+type transformTimer struct {
+	table   string
+	mu      sync.Mutex
+	timings map[string]*transformTiming
+}
+
+// newTransformTimer returns a timer for the table, or nil if timing is not enabled
+func newTransformTimer(table string, enabled bool) *transformTimer {
+	if !enabled {
+		return nil
+	}
+	return &transformTimer{table: table, timings: map[string]*transformTiming{}}
+}
+
+// timeTransform wraps a transform so that every call is timed under the operation type of its input. The transform
+// is returned as is if the timer is nil. The wrapped transform can be called by concurrent workers.
+func timeTransform[In, Out any](timer *transformTimer, transform func(In) (Out, error), operationType func(In) string) func(In) (Out, error) {
+	if timer == nil {
+		return transform
+	}
+	return func(in In) (Out, error) {
+		start := time.Now()
+		out, err := transform(in)
+		timer.record(operationType(in), time.Since(start))
+		return out, err
+	}
+}
+
+func (t *transformTimer) record(operationType string, duration time.Duration) {
+	utils.RecordTransformDuration(context.Background(), t.table, operationType, duration)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timing, ok := t.timings[operationType]
+	if !ok {
+		timing = &transformTiming{OperationType: operationType, Buckets: make([]int64, len(utils.TransformDurationBuckets)+1)}
+		t.timings[operationType] = timing
+	}
+	timing.add(float64(duration) / float64(time.Millisecond))
+}
+
+// summary returns the timings of every operation type, the slowest in total first
+func (t *transformTimer) summary() []transformTiming {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timings := make([]transformTiming, 0, len(t.timings))
+	for _, timing := range t.timings {
+		summarized := *timing
+		summarized.P50Ms = timing.quantile(0.5)
+		summarized.P99Ms = timing.quantile(0.99)
+		timings = append(timings, summarized)
+	}
+	sort.Slice(timings, func(i, j int) bool {
+		if timings[i].TotalMs != timings[j].TotalMs {
+			return timings[i].TotalMs > timings[j].TotalMs
+		}
+		return timings[i].OperationType < timings[j].OperationType
+	})
+	return timings
+}
+
+// printSummary logs the timings of every operation type at the end of an export, next to the transform stats
+func (t *transformTimer) printSummary() {
+	if t == nil {
+		return
+	}
+	for _, timing := range t.summary() {
+		marshalled, err := json.Marshal(timing)
+		if err != nil {
+			cmdLogger.Fatal("could not marshal transform timing: ", err)
+		}
+		cmdLogger.WithFields(log.F{
+			utils.LogFieldTable: t.table,
+			"op_type":           timing.OperationType,
+		}).Info(string(marshalled))
+	}
+}
+
+// transactionInputType, operationInputType, tradeInputType and assetInputType are the operation types of the
+// inputs of the exports for the transform timings
+func transactionInputType(in input.LedgerTransformInput) string {
+	return transactionOperationType(in.Transaction)
+}
+
+func operationInputType(in input.OperationTransformInput) string {
+	return operationTypeOf(in.Operation)
+}
+
+func tradeInputType(in input.TradeTransformInput) string {
+	return operationTypeOf(in.Transaction.Envelope.Operations()[in.OperationIndex])
+}
+
+func assetInputType(in input.AssetTransformInput) string {
+	return operationTypeOf(in.Operation)
+}
+
+// transactionOperationType is the operation type of a transaction for the transform timings: the type of its
+// operations, or multiple if they are not all of the same type
+func transactionOperationType(transaction ingest.LedgerTransaction) string {
+	operations := transaction.Envelope.Operations()
+	if len(operations) == 0 {
+		return multipleOperationTypes
+	}
+	operationType := operations[0].Body.Type
+	for _, operation := range operations[1:] {
+		if operation.Body.Type != operationType {
+			return multipleOperationTypes
+		}
+	}
+	return transform.OperationTypeName(operationType)
+}
+
+// operationTypeOf is the operation type of an operation for the transform timings
+func operationTypeOf(operation xdr.Operation) string {
+	return transform.OperationTypeName(operation.Body.Type)
+}
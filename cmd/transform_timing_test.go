@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformTimer(t *testing.T) {
+	transform := func(in int) (int, error) {
+		if in < 0 {
+			return 0, errors.New("negative")
+		}
+		return in * 2, nil
+	}
+	label := func(in int) string {
+		if in%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	}
+
+	// without the flag the transform is not wrapped
+	assert.Nil(t, newTransformTimer("numbers", false))
+	var disabled *transformTimer
+	disabled.printSummary()
+
+	timer := newTransformTimer("numbers", true)
+	timed := timeTransform(timer, transform, label)
+	for _, in := range []int{1, 2, 3, 4, -1} {
+		out, err := timed(in)
+		wantOut, wantErr := transform(in)
+		assert.Equal(t, wantOut, out)
+		assert.Equal(t, wantErr, err)
+	}
+
+	summary := timer.summary()
+	assert.Len(t, summary, 2)
+	counts := map[string]int64{}
+	for _, timing := range summary {
+		counts[timing.OperationType] = timing.Count
+		assert.Len(t, timing.Buckets, 11)
+	}
+	assert.Equal(t, map[string]int64{"even": 2, "odd": 3}, counts)
+}
+
+func TestTransformTimingQuantiles(t *testing.T) {
+	timer := newTransformTimer("numbers", true)
+	for i := 0; i < 98; i++ {
+		timer.record("payment", 500*time.Microsecond)
+	}
+	timer.record("payment", 7*time.Millisecond)
+	timer.record("payment", 2*time.Second)
+
+	summary := timer.summary()
+	assert.Equal(t, []transformTiming{{
+		OperationType: "payment",
+		Count:         100,
+		TotalMs:       98*0.5 + 7 + 2000,
+		MaxMs:         2000,
+		P50Ms:         1,
+		P99Ms:         2000,
+		Buckets:       []int64{98, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1},
+	}}, summary)
+}
+
+func TestTransactionOperationType(t *testing.T) {
+	transaction := func(operationTypes ...xdr.OperationType) ingest.LedgerTransaction {
+		operations := []xdr.Operation{}
+		for _, operationType := range operationTypes {
+			operations = append(operations, xdr.Operation{Body: xdr.OperationBody{Type: operationType}})
+		}
+		return ingest.LedgerTransaction{Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1:   &xdr.TransactionV1Envelope{Tx: xdr.Transaction{Operations: operations}},
+		}}
+	}
+
+	assert.Equal(t, "liquidity_pool_deposit", transactionOperationType(transaction(xdr.OperationTypeLiquidityPoolDeposit)))
+	assert.Equal(t, "payment", transactionOperationType(transaction(xdr.OperationTypePayment, xdr.OperationTypePayment)))
+	assert.Equal(t, multipleOperationTypes, transactionOperationType(transaction(xdr.OperationTypePayment, xdr.OperationTypeManageSellOffer)))
+}
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"cloud.google.com/go/storage"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
 )
 
 type GCS struct {
@@ -22,22 +24,51 @@ func newGCS(gcsCredentialsPath, gcsBucket string) CloudStorage {
 	}
 }
 
-func (g *GCS) UploadTo(credentialsPath, bucket, path string) error {
-	// Use credentials file in dev/local runs. Otherwise, derive credentials from the service account.
-	if len(credentialsPath) > 0 {
-		os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentialsPath)
-		cmdLogger.Infof("Using credentials found at: %s", credentialsPath)
+// newGCSClient creates a storage client from the credentials file in dev/local runs. Otherwise, credentials are
+// read from the environment or derived from the service account.
+func newGCSClient(ctx context.Context, credentialsPath string) (*storage.Client, error) {
+	credentials, err := utils.ResolveCloudCredentials(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	cmdLogger.Infof("Using %s", credentials)
+
+	client, err := storage.NewClient(ctx, credentials.GoogleClientOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %v", err)
+	}
+
+	return client, nil
+}
+
+// CheckAccess verifies that the credentials can read the bucket before anything is exported
+func (g *GCS) CheckAccess() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client, err := newGCSClient(ctx, g.gcsCredentialsPath)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if _, err := client.Bucket(g.gcsBucket).Attrs(ctx); err != nil {
+		return fmt.Errorf("unable to access bucket %s: %v", g.gcsBucket, err)
 	}
 
+	return nil
+}
+
+func (g *GCS) UploadTo(credentialsPath, bucket, path string) error {
 	reader, err := os.Open(path)
 	if err != nil {
 		return fmt.Errorf("failed to open file %s: %v", path, err)
 	}
 
 	ctx := context.Background()
-	client, err := storage.NewClient(ctx)
+	client, err := newGCSClient(ctx, credentialsPath)
 	if err != nil {
-		return fmt.Errorf("failed to create client: %v", err)
+		return err
 	}
 	defer client.Close()
 
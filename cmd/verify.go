@@ -0,0 +1,328 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/log"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// Invariants checked by the verify command
+const (
+	verifyLedgerTransactions    = "ledger-transactions"
+	verifyOperationTransaction  = "operation-transaction"
+	verifyTransactionOperations = "transaction-operations"
+	verifyEffectOperation       = "effect-operation"
+	verifyTradeOperation        = "trade-operation"
+	verifyTradeEffects          = "trade-effects"
+)
+
+var verifyChecks = []string{
+	verifyLedgerTransactions,
+	verifyOperationTransaction,
+	verifyTransactionOperations,
+	verifyEffectOperation,
+	verifyTradeOperation,
+	verifyTradeEffects,
+}
+
+// verifyTables are the tables the invariants are checked across
+var verifyTables = []string{"ledgers", "transactions", "operations", "effects", "trades"}
+
+// verifySampleSize is the number of discrepancies of each invariant kept in the report
+const verifySampleSize = 100
+
+// verifyDiscrepancy is a row that breaks an invariant
+type verifyDiscrepancy struct {
+	Check  string `json:"check"`
+	Ledger uint32 `json:"ledger"`
+	Table  string `json:"table"`
+	ID     string `json:"id"`
+	Detail string `json:"detail"`
+}
+
+// verifyReport is the outcome of the verification of a range
+type verifyReport struct {
+	Start         uint32              `json:"start_ledger"`
+	End           uint32              `json:"end_ledger"`
+	Rows          map[string]int      `json:"rows"`
+	Failures      int                 `json:"failed_transforms"`
+	Counts        map[string]int      `json:"discrepancy_counts"`
+	Discrepancies []verifyDiscrepancy `json:"discrepancies"`
+}
+
+// total is the number of discrepancies of every invariant
+func (r verifyReport) total() int {
+	total := 0
+	for _, count := range r.Counts {
+		total += count
+	}
+	return total
+}
+
+func (r *verifyReport) add(discrepancies []verifyDiscrepancy) {
+	for _, discrepancy := range discrepancies {
+		r.Counts[discrepancy.Check]++
+		if r.Counts[discrepancy.Check] <= verifySampleSize {
+			r.Discrepancies = append(r.Discrepancies, discrepancy)
+		}
+	}
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Checks invariants across the exported tables of a range",
+	Long: `Transforms the ledgers, transactions, operations, effects and trades of a range and checks that they agree with
+each other: every operation has a parent transaction, every effect and trade has a parent operation, the counts of
+ledgers and transactions match the exported rows, and the traded amounts match the trade effects. The discrepancies
+are written to a json report, and the command fails if any is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		ctx := context.Background()
+		backend, err := utils.CreateLedgerBackend(ctx, commonArgs.UseCaptiveCore, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create ledger backend: ", err)
+		}
+		defer backend.Close()
+
+		stageOptions := input.StageOptions{ReadAhead: commonArgs.Concurrency.ReadAhead, TransformWorkers: commonArgs.Concurrency.Workers, Network: env.Network, Sample: commonArgs.Sample, Toid: commonArgs.Toid}
+		report, err := verifyRange(ctx, backend, startNum, commonArgs.EndNum, env.NetworkPassphrase, stageOptions)
+		if err != nil {
+			cmdLogger.Fatal("could not verify range: ", err)
+		}
+
+		if err := writeJSONReport(path, report); err != nil {
+			cmdLogger.Fatal(err)
+		}
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		for _, check := range verifyChecks {
+			if count := report.Counts[check]; count > 0 {
+				cmdLogger.WithFields(log.F{"check": check}).Warnf("Found %d discrepancies", count)
+			}
+		}
+		if total := report.total(); total > 0 {
+			cmdLogger.Fatalf("found %d discrepancies in ledgers [%d, %d]; see %s", total, report.Start, report.End, path)
+		}
+		cmdLogger.Infof("Ledgers [%d, %d] passed the %d invariants", report.Start, report.End, len(verifyChecks))
+	},
+}
+
+// verifyRange transforms the ledgers of a range and checks the invariants across their tables. Every row refers to
+// rows of the same ledger, so the ledgers are checked one by one.
+func verifyRange(ctx context.Context, backend ledgerbackend.LedgerBackend, start, end uint32, networkPassphrase string, stageOptions input.StageOptions) (verifyReport, error) {
+	report := verifyReport{Start: start, End: end, Rows: map[string]int{}, Counts: map[string]int{}, Discrepancies: []verifyDiscrepancy{}}
+
+	tables, err := input.SelectLedgerTables(verifyTables)
+	if err != nil {
+		return report, err
+	}
+
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end)); err != nil {
+		return report, fmt.Errorf("could not prepare range [%d, %d]: %v", start, end, err)
+	}
+
+	err = input.TransformLedgerRange(ctx, backend, start, end, networkPassphrase, tables, stageOptions, func(ledger input.TransformedLedger) error {
+		rows := map[string][]interface{}{}
+		for i, table := range tables {
+			if err := ledger.Errors[i]; err != nil {
+				cmdLogger.WithFields(utils.RowLogFields(table.Name, ledger.Sequence, "")).LogError(fmt.Errorf("could not transform %s in ledger %d: %w", table.Name, ledger.Sequence, err))
+				report.Failures++
+			}
+			rows[table.Name] = ledger.Rows[i]
+			report.Rows[table.Name] += len(ledger.Rows[i])
+		}
+
+		report.add(verifyLedger(ledger.Sequence, rows))
+		return nil
+	})
+
+	return report, err
+}
+
+// verifyLedger checks the invariants across the tables of a ledger
+func verifyLedger(seq uint32, rows map[string][]interface{}) []verifyDiscrepancy {
+	discrepancies := []verifyDiscrepancy{}
+	discrepancy := func(check, table string, id interface{}, format string, args ...interface{}) {
+		discrepancies = append(discrepancies, verifyDiscrepancy{
+			Check:  check,
+			Ledger: seq,
+			Table:  table,
+			ID:     fmt.Sprint(id),
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	transactionOperations := map[int64]int{}
+	for _, row := range rows["transactions"] {
+		transactionOperations[row.(transform.TransactionOutput).TransactionID] = 0
+	}
+
+	// operation id -> traded amount in stroops, of both sides of its trades
+	operationTrades := map[int64]int64{}
+	for _, row := range rows["operations"] {
+		operation := row.(transform.OperationOutput)
+		operationTrades[operation.OperationID] = 0
+		if _, ok := transactionOperations[operation.TransactionID]; !ok {
+			discrepancy(verifyOperationTransaction, "operations", operation.OperationID, "transaction %d was not exported", operation.TransactionID)
+			continue
+		}
+		transactionOperations[operation.TransactionID]++
+	}
+
+	for _, row := range rows["ledgers"] {
+		ledger := row.(transform.LedgerOutput)
+		exported := len(rows["transactions"])
+		if counted := int(ledger.SuccessfulTransactionCount + ledger.FailedTransactionCount); counted != exported {
+			discrepancy(verifyLedgerTransactions, "ledgers", ledger.LedgerID, "ledger counts %d transactions, %d were exported", counted, exported)
+		}
+	}
+
+	for _, row := range rows["transactions"] {
+		transaction := row.(transform.TransactionOutput)
+		if exported := transactionOperations[transaction.TransactionID]; int(transaction.OperationCount) != exported {
+			discrepancy(verifyTransactionOperations, "transactions", transaction.TransactionID, "transaction counts %d operations, %d were exported", transaction.OperationCount, exported)
+		}
+	}
+
+	for _, row := range rows["trades"] {
+		trade := row.(transform.TradeOutput)
+		if _, ok := operationTrades[trade.HistoryOperationID]; !ok {
+			discrepancy(verifyTradeOperation, "trades", trade.HistoryOperationID, "operation %d was not exported", trade.HistoryOperationID)
+			continue
+		}
+		operationTrades[trade.HistoryOperationID] += toStroops(trade.SellingAmount) + toStroops(trade.BuyingAmount)
+	}
+
+	// Order book trades have an effect for both the buyer and the seller, which both hold the amounts of the trade,
+	// while liquidity pool trades only have an effect for the source of the operation
+	effectTrades := map[int64]int64{}
+	for _, row := range rows["effects"] {
+		effect := row.(transform.EffectOutput)
+		if _, ok := operationTrades[effect.OperationID]; !ok {
+			discrepancy(verifyEffectOperation, "effects", effect.EffectId, "operation %d was not exported", effect.OperationID)
+			continue
+		}
+
+		var traded int64
+		var err error
+		switch transform.EffectType(effect.Type) {
+		case transform.EffectTrade:
+			traded, err = sumEffectAmounts(effect.Details["sold_amount"], effect.Details["bought_amount"])
+			traded /= 2
+		case transform.EffectLiquidityPoolTrade:
+			traded, err = sumEffectAmounts(nestedEffectAmount(effect.Details["sold"]), nestedEffectAmount(effect.Details["bought"]))
+		default:
+			continue
+		}
+		if err != nil {
+			discrepancy(verifyTradeEffects, "effects", effect.EffectId, "invalid trade amounts: %v", err)
+			continue
+		}
+		effectTrades[effect.OperationID] += traded
+	}
+
+	for _, row := range rows["operations"] {
+		operationID := row.(transform.OperationOutput).OperationID
+		if traded, effects := operationTrades[operationID], effectTrades[operationID]; traded != effects {
+			discrepancy(verifyTradeEffects, "operations", operationID, "trades amount to %s, trade effects to %s", amount.StringFromInt64(traded), amount.StringFromInt64(effects))
+		}
+	}
+
+	return discrepancies
+}
+
+// toStroops converts the real amounts of the outputs back to stroops
+func toStroops(real float64) int64 {
+	return int64(math.Round(real * 1e7))
+}
+
+func sumEffectAmounts(values ...interface{}) (int64, error) {
+	var total int64
+	for _, value := range values {
+		s, ok := value.(string)
+		if !ok {
+			return 0, fmt.Errorf("expected an amount, got %v", value)
+		}
+		parsed, err := amount.ParseInt64(s)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse amount %s: %v", strconv.Quote(s), err)
+		}
+		total += parsed
+	}
+	return total, nil
+}
+
+// nestedEffectAmount reads the amount of the sold and bought details of liquidity pool trades
+func nestedEffectAmount(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]string:
+		return v["amount"]
+	case map[string]interface{}:
+		return v["amount"]
+	default:
+		return nil
+	}
+}
+
+// writeJSONReport writes a report as indented json, creating its folder, or to stdout for utils.StdoutPath
+func writeJSONReport(path string, report interface{}) error {
+	marshalled, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("could not json encode report: %v", err)
+	}
+	if path == utils.StdoutPath {
+		if _, err := os.Stdout.Write(append(marshalled, '\n')); err != nil {
+			return fmt.Errorf("could not write report to stdout: %v", err)
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("could not create directory of report %s: %v", path, err)
+	}
+	if err := os.WriteFile(path, append(marshalled, '\n'), 0644); err != nil {
+		return fmt.Errorf("could not write report %s: %v", path, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+	utils.AddCommonFlags(verifyCmd.Flags())
+	utils.AddArchiveFlags("verify", verifyCmd.Flags())
+	utils.AddCloudStorageFlags(verifyCmd.Flags())
+	verifyCmd.Flags().Lookup("output").DefValue = "verify_report.json"
+	verifyCmd.Flags().Set("output", "verify_report.json")
+	verifyCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the range
+			end-ledger: the ledger sequence number for the end of the range (required)
+
+			output-file: filename of the discrepancy report
+			read-ahead: number of ledgers read ahead of the one being checked
+			workers: number of ledgers transformed at once
+	*/
+}
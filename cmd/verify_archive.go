@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/support/log"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// Problems found by the verify_archive command
+const (
+	archiveFileMissing    = "missing"
+	archiveFileUnreadable = "unreadable"
+	archiveFileCorrupt    = "corrupt"
+)
+
+var archiveProblems = []string{archiveFileMissing, archiveFileUnreadable, archiveFileCorrupt}
+
+// archiveSampleSize is the number of files of each problem kept in the report
+const archiveSampleSize = 100
+
+// archiveProblem is a datastore file that could not be read or failed its integrity checks
+type archiveProblem struct {
+	Problem     string `json:"problem"`
+	File        string `json:"file"`
+	StartLedger uint32 `json:"start_ledger"`
+	Detail      string `json:"detail"`
+}
+
+// archiveReport is the outcome of the verification of the files of a range
+type archiveReport struct {
+	Start    uint32           `json:"start_ledger"`
+	End      uint32           `json:"end_ledger"`
+	Files    int              `json:"files"`
+	Counts   map[string]int   `json:"problem_counts"`
+	Problems []archiveProblem `json:"problems"`
+}
+
+// total is the number of files with a problem
+func (r archiveReport) total() int {
+	total := 0
+	for _, count := range r.Counts {
+		total += count
+	}
+	return total
+}
+
+func (r *archiveReport) add(problem archiveProblem) {
+	r.Counts[problem.Problem]++
+	if r.Counts[problem.Problem] <= archiveSampleSize {
+		r.Problems = append(r.Problems, problem)
+	}
+}
+
+var verifyArchiveCmd = &cobra.Command{
+	Use:   "verify_archive",
+	Short: "Checks the integrity of the datastore files of a range",
+	Long: `Downloads the datastore files holding the ledgers of a range and checks their integrity: the checksums of
+their zstd or gzip compression, that they decode, and that they hold the ledgers they are named after, in sequence and
+chained by their hashes. The missing, unreadable and corrupt files are written to a json report, and the command fails
+if any is found.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmdLogger.SetLevel(logrus.InfoLevel)
+		commonArgs := utils.MustCommonFlags(cmd.Flags(), cmdLogger)
+		cmdLogger.StrictExport = commonArgs.StrictExport
+		cmdLogger.Configure(commonArgs.LogLevel, commonArgs.LogFormat)
+		startNum, path, _, _ := utils.MustArchiveFlags(cmd.Flags(), cmdLogger)
+		cloudStorageBucket, cloudCredentials, cloudProvider := utils.MustCloudStorageFlags(cmd.Flags(), cmdLogger)
+		env := utils.GetEnvironmentDetails(commonArgs)
+
+		ctx := context.Background()
+		dataStore, err := utils.CreateDatastore(ctx, env)
+		if err != nil {
+			cmdLogger.Fatal("could not create datastore: ", err)
+		}
+		defer dataStore.Close()
+
+		// with verify-files the corrupt files are downloaded again before they are reported
+		var retrier *utils.Retrier
+		if commonArgs.VerifyFiles {
+			retrier = utils.NewRetrier("datastore", utils.RetryPolicyFromFlags(commonArgs))
+		}
+
+		report, err := verifyArchive(ctx, dataStore, startNum, commonArgs.EndNum, int(commonArgs.NumWorkers), retrier)
+		if err != nil {
+			cmdLogger.Fatal("could not verify archive: ", err)
+		}
+
+		if err := writeJSONReport(path, report); err != nil {
+			cmdLogger.Fatal(err)
+		}
+		MaybeUpload(cloudCredentials, cloudStorageBucket, cloudProvider, path)
+
+		for _, problem := range archiveProblems {
+			if count := report.Counts[problem]; count > 0 {
+				cmdLogger.WithFields(log.F{"problem": problem}).Warnf("Found %d files", count)
+			}
+		}
+		if total := report.total(); total > 0 {
+			cmdLogger.Fatalf("found %d bad files of %d for ledgers [%d, %d]; see %s", total, report.Files, report.Start, report.End, path)
+		}
+		cmdLogger.Infof("The %d files of ledgers [%d, %d] passed the integrity checks", report.Files, report.Start, report.End)
+	},
+}
+
+// verifyArchive downloads the files holding the ledgers of a range with the given number of workers and checks them
+// with utils.VerifyLedgerFile. The problems are reported in the order of the files.
+func verifyArchive(ctx context.Context, dataStore datastore.DataStore, start, end uint32, workers int, retrier *utils.Retrier) (archiveReport, error) {
+	report := archiveReport{Start: start, End: end, Counts: map[string]int{}, Problems: []archiveProblem{}}
+	if end < start {
+		return report, fmt.Errorf("end ledger %d is before start ledger %d", end, start)
+	}
+
+	schema := dataStore.GetSchema()
+	if schema.LedgersPerFile == 0 {
+		return report, errors.New("the datastore schema has no ledgers per file")
+	}
+	fileStarts := []uint32{}
+	for first := schema.GetSequenceNumberStartBoundary(start); first <= end; first += schema.LedgersPerFile {
+		fileStarts = append(fileStarts, first)
+		if first+schema.LedgersPerFile < first {
+			break
+		}
+	}
+	report.Files = len(fileStarts)
+
+	problems := make([]*archiveProblem, len(fileStarts))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < max(workers, 1); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				problems[i] = verifyArchiveFile(ctx, dataStore, schema, fileStarts[i], retrier)
+			}
+		}()
+	}
+	for i := range fileStarts {
+		if ctx.Err() != nil {
+			break
+		}
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+
+	for _, problem := range problems {
+		if problem != nil {
+			report.add(*problem)
+		}
+	}
+	return report, nil
+}
+
+// verifyArchiveFile downloads and checks the file starting at a ledger, and returns its problem or nil if it is fine.
+// Corrupt files are downloaded again with the retrier, and a nil retrier checks the file once.
+func verifyArchiveFile(ctx context.Context, dataStore datastore.DataStore, schema datastore.DataStoreSchema, startLedger uint32, retrier *utils.Retrier) *archiveProblem {
+	var problem *archiveProblem
+	retrier.Do(ctx, "verify_file", func() error {
+		problem = checkArchiveFile(ctx, dataStore, schema, startLedger)
+		if problem != nil && problem.Problem == archiveFileCorrupt {
+			return utils.TransientError(errors.New(problem.Detail))
+		}
+		return nil
+	})
+	return problem
+}
+
+// checkArchiveFile downloads and checks the file starting at a ledger once
+func checkArchiveFile(ctx context.Context, dataStore datastore.DataStore, schema datastore.DataStoreSchema, startLedger uint32) *archiveProblem {
+	objectKey := schema.GetObjectKeyFromSequenceNumber(startLedger)
+	problem := func(kind string, err error) *archiveProblem {
+		return &archiveProblem{Problem: kind, File: objectKey, StartLedger: startLedger, Detail: err.Error()}
+	}
+
+	reader, err := dataStore.GetFile(ctx, objectKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return problem(archiveFileMissing, err)
+	}
+	if errors.Is(err, utils.ErrCorruptFile) {
+		// the file failed the checks of a datastore read with verify-files
+		return problem(archiveFileCorrupt, err)
+	}
+	if err != nil {
+		return problem(archiveFileUnreadable, err)
+	}
+	defer reader.Close()
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return problem(archiveFileUnreadable, err)
+	}
+	if err := utils.VerifyLedgerFile(contents, schema, objectKey); err != nil {
+		return problem(archiveFileCorrupt, err)
+	}
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(verifyArchiveCmd)
+	utils.AddCommonFlags(verifyArchiveCmd.Flags())
+	utils.AddArchiveFlags("verify_archive", verifyArchiveCmd.Flags())
+	utils.AddCloudStorageFlags(verifyArchiveCmd.Flags())
+	verifyArchiveCmd.Flags().Lookup("output").DefValue = "archive_report.json"
+	verifyArchiveCmd.Flags().Set("output", "archive_report.json")
+	verifyArchiveCmd.MarkFlagRequired("end-ledger")
+
+	/*
+		Current flags:
+			start-ledger: the ledger sequence number for the beginning of the range
+			end-ledger: the ledger sequence number for the end of the range (required)
+
+			output-file: filename of the integrity report
+			num-workers: number of files downloaded and checked at once
+			datastore-path: datastore bucket path the files are read from
+			verify-files: download the corrupt files again before reporting them
+	*/
+}
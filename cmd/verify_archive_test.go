@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stellar/go/support/compressxdr"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+var archiveTestSchema = datastore.DataStoreSchema{LedgersPerFile: 1, FilesPerPartition: 10}
+
+// archiveTestStore is a datastore of files in memory. Each file holds the contents of its successive downloads, the
+// last one being served from then on.
+type archiveTestStore struct {
+	datastore.DataStore
+	mu        sync.Mutex
+	files     map[string][][]byte
+	downloads map[string]int
+}
+
+func (s *archiveTestStore) GetFile(_ context.Context, path string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	versions, ok := s.files[path]
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	download := min(s.downloads[path], len(versions)-1)
+	s.downloads[path]++
+	return io.NopCloser(bytes.NewReader(versions[download])), nil
+}
+
+func (s *archiveTestStore) GetSchema() datastore.DataStoreSchema {
+	return archiveTestSchema
+}
+
+func archiveTestFile(t *testing.T, sequence uint32, gzipped bool) []byte {
+	batch := xdr.LedgerCloseMetaBatch{
+		StartSequence: xdr.Uint32(sequence),
+		EndSequence:   xdr.Uint32(sequence),
+		LedgerCloseMetas: []xdr.LedgerCloseMeta{{
+			V: 0,
+			V0: &xdr.LedgerCloseMetaV0{
+				LedgerHeader: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)}},
+			},
+		}},
+	}
+	var buf bytes.Buffer
+	if gzipped {
+		w := gzip.NewWriter(&buf)
+		_, err := xdr.Marshal(w, batch)
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		return buf.Bytes()
+	}
+	_, err := compressxdr.NewXDREncoder(compressxdr.DefaultCompressor, batch).WriteTo(&buf)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func TestVerifyArchive(t *testing.T) {
+	key := archiveTestSchema.GetObjectKeyFromSequenceNumber
+	corrupt := archiveTestFile(t, 4, false)
+	corrupt[len(corrupt)-6] ^= 0xff
+
+	store := &archiveTestStore{
+		files: map[string][][]byte{
+			key(2): {archiveTestFile(t, 2, false)},
+			key(3): {archiveTestFile(t, 3, true)},
+			key(4): {corrupt},
+			key(5): {archiveTestFile(t, 6, false)},
+			key(6): {[]byte("not compressed")},
+		},
+		downloads: map[string]int{},
+	}
+
+	report, err := verifyArchive(context.Background(), store, 2, 7, 3, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 6, report.Files)
+	assert.Equal(t, map[string]int{archiveFileCorrupt: 3, archiveFileMissing: 1}, report.Counts)
+	problems := []string{}
+	for _, problem := range report.Problems {
+		problems = append(problems, problem.Problem+" "+problem.File)
+	}
+	assert.Equal(t, []string{
+		archiveFileCorrupt + " " + key(4),
+		archiveFileCorrupt + " " + key(5),
+		archiveFileCorrupt + " " + key(6),
+		archiveFileMissing + " " + key(7),
+	}, problems)
+	assert.Contains(t, report.Problems[1].Detail, "holds ledgers from 6")
+
+	_, err = verifyArchive(context.Background(), store, 7, 2, 1, nil)
+	assert.Error(t, err)
+}
+
+func TestVerifiedDatastoreFailsCorruptFiles(t *testing.T) {
+	key := archiveTestSchema.GetObjectKeyFromSequenceNumber
+	good := archiveTestFile(t, 2, false)
+	store := &archiveTestStore{
+		files: map[string][][]byte{
+			key(2): {[]byte("truncated"), good},
+		},
+		downloads: map[string]int{},
+	}
+	verified := utils.WithVerification(store)
+
+	// a corrupt file fails the read at once, and is downloaded again by the retries of the ledger backend
+	_, err := verified.GetFile(context.Background(), key(2))
+	assert.True(t, errors.Is(err, utils.ErrCorruptFile))
+	assert.True(t, utils.IsRetryable(err))
+	assert.Equal(t, 1, store.downloads[key(2)])
+
+	reader, err := verified.GetFile(context.Background(), key(2))
+	require.NoError(t, err)
+	contents, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, good, contents)
+
+	_, err = verified.GetFile(context.Background(), key(4))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.Equal(t, 0, store.downloads[key(4)])
+}
+
+func TestVerifyArchiveDownloadsCorruptFilesAgain(t *testing.T) {
+	key := archiveTestSchema.GetObjectKeyFromSequenceNumber
+	store := &archiveTestStore{
+		files: map[string][][]byte{
+			key(2): {[]byte("truncated"), archiveTestFile(t, 2, false)},
+			key(3): {[]byte("truncated")},
+		},
+		downloads: map[string]int{},
+	}
+
+	report, err := verifyArchive(context.Background(), store, 2, 3, 1, utils.NewRetrier("datastore", utils.RetryPolicy{Attempts: 3}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{archiveFileCorrupt: 1}, report.Counts)
+	assert.Equal(t, key(3), report.Problems[0].File)
+	assert.Equal(t, 2, store.downloads[key(2)])
+	assert.Equal(t, 3, store.downloads[key(3)])
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+func verifyTestRows() map[string][]interface{} {
+	return map[string][]interface{}{
+		"ledgers": {
+			transform.LedgerOutput{Sequence: 10, LedgerID: 42949672960, SuccessfulTransactionCount: 1, FailedTransactionCount: 1},
+		},
+		"transactions": {
+			transform.TransactionOutput{TransactionID: 42949677056, OperationCount: 1},
+			transform.TransactionOutput{TransactionID: 42949681152, OperationCount: 1},
+		},
+		"operations": {
+			transform.OperationOutput{OperationID: 42949677057, TransactionID: 42949677056},
+			transform.OperationOutput{OperationID: 42949681153, TransactionID: 42949681152},
+		},
+		"trades": {
+			transform.TradeOutput{HistoryOperationID: 42949677057, SellingAmount: 10, BuyingAmount: 2.5},
+			transform.TradeOutput{HistoryOperationID: 42949677057, SellingAmount: 1, BuyingAmount: 0.1234567},
+		},
+		"effects": {
+			transform.EffectOutput{EffectId: "42949677057-1", OperationID: 42949677057, Type: int32(transform.EffectTrade), Details: map[string]interface{}{"sold_amount": "2.5000000", "bought_amount": "10.0000000"}},
+			transform.EffectOutput{EffectId: "42949677057-2", OperationID: 42949677057, Type: int32(transform.EffectTrade), Details: map[string]interface{}{"sold_amount": "10.0000000", "bought_amount": "2.5000000"}},
+			transform.EffectOutput{EffectId: "42949677057-3", OperationID: 42949677057, Type: int32(transform.EffectLiquidityPoolTrade), Details: map[string]interface{}{
+				"sold":   map[string]string{"asset": "native", "amount": "0.1234567"},
+				"bought": map[string]string{"asset": "native", "amount": "1.0000000"},
+			}},
+			transform.EffectOutput{EffectId: "42949681153-1", OperationID: 42949681153, Type: int32(transform.EffectAccountCredited)},
+		},
+	}
+}
+
+func TestVerifyLedger(t *testing.T) {
+	assert.Equal(t, []verifyDiscrepancy{}, verifyLedger(10, verifyTestRows()))
+}
+
+func TestVerifyLedgerDiscrepancies(t *testing.T) {
+	rows := verifyTestRows()
+	// the second transaction, its operation's effect and one of the trades of the first operation are missing
+	rows["transactions"] = rows["transactions"][:1]
+	rows["trades"] = rows["trades"][:1]
+	rows["effects"] = append(rows["effects"], transform.EffectOutput{EffectId: "42949685249-1", OperationID: 42949685249})
+
+	assert.Equal(t, []verifyDiscrepancy{
+		{Check: verifyOperationTransaction, Ledger: 10, Table: "operations", ID: "42949681153", Detail: "transaction 42949681152 was not exported"},
+		{Check: verifyLedgerTransactions, Ledger: 10, Table: "ledgers", ID: "42949672960", Detail: "ledger counts 2 transactions, 1 were exported"},
+		{Check: verifyEffectOperation, Ledger: 10, Table: "effects", ID: "42949685249-1", Detail: "operation 42949685249 was not exported"},
+		{Check: verifyTradeEffects, Ledger: 10, Table: "operations", ID: "42949677057", Detail: "trades amount to 12.5000000, trade effects to 13.6234567"},
+	}, verifyLedger(10, rows))
+}
+
+func TestVerifyReportSamples(t *testing.T) {
+	report := verifyReport{Counts: map[string]int{}}
+	discrepancies := make([]verifyDiscrepancy, verifySampleSize+5)
+	for i := range discrepancies {
+		discrepancies[i] = verifyDiscrepancy{Check: verifyTradeOperation}
+	}
+	report.add(discrepancies)
+	report.add([]verifyDiscrepancy{{Check: verifyEffectOperation}})
+
+	assert.Equal(t, map[string]int{verifyTradeOperation: verifySampleSize + 5, verifyEffectOperation: 1}, report.Counts)
+	assert.Len(t, report.Discrepancies, verifySampleSize+1)
+	assert.Equal(t, verifySampleSize+6, report.total())
+}
+
+func TestVerifyRange(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeDuckDBTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makeDuckDBTestLedger(11), nil)
+
+	report, err := verifyRange(context.Background(), backend, 10, 11, network.TestNetworkPassphrase, input.StageOptions{TransformWorkers: 2})
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.Equal(t, map[string]int{"ledgers": 2, "transactions": 0, "operations": 0, "effects": 0, "trades": 0}, report.Rows)
+	assert.Equal(t, 0, report.Failures)
+	assert.Equal(t, 0, report.total())
+}
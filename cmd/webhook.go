@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// Headers of the webhook requests. The signature is the hex HMAC-SHA256 of "<timestamp>.<body>", so that receivers
+// can reject requests that were tampered with or replayed.
+const (
+	webhookTableHeader     = "X-Stellar-Etl-Table"
+	webhookTimestampHeader = "X-Stellar-Etl-Timestamp"
+	webhookSignatureHeader = "X-Stellar-Etl-Signature"
+)
+
+// webhookSink posts the rows of the exported batches as json to http endpoints
+type webhookSink struct {
+	client     *http.Client
+	defaultURL string
+	routes     map[string]string
+	secret     []byte
+	batchSize  int
+	retries    int
+	backoff    time.Duration
+	now        func() time.Time
+}
+
+// webhookPayload is the body of a webhook request
+type webhookPayload struct {
+	Table       string                   `json:"table"`
+	LedgerStart uint32                   `json:"ledger_start"`
+	LedgerEnd   uint32                   `json:"ledger_end"`
+	Rows        []map[string]interface{} `json:"rows"`
+}
+
+// newWebhookSink returns the sink configured by the webhook flags, or nil if it is disabled
+func newWebhookSink(values utils.WebhookFlagValues) *webhookSink {
+	if values.URL == "" && len(values.Routes) == 0 {
+		return nil
+	}
+
+	return &webhookSink{
+		client:     &http.Client{Timeout: values.Timeout},
+		defaultURL: values.URL,
+		routes:     values.Routes,
+		secret:     []byte(values.Secret),
+		batchSize:  values.BatchSize,
+		retries:    values.Retries,
+		backoff:    time.Second,
+		now:        time.Now,
+	}
+}
+
+// url returns the endpoint the rows of a table are posted to, or an empty string if the table is not routed
+func (s *webhookSink) url(table string) string {
+	if url, ok := s.routes[table]; ok {
+		return url
+	}
+	return s.defaultURL
+}
+
+// send posts the rows of a batch, in requests of at most batchSize rows per table. The requests are sent even if
+// the context is done, so that the last batch is delivered on shutdown.
+func (s *webhookSink) send(ctx context.Context, start, end uint32, outputs map[string][]interface{}, extra map[string]string) error {
+	if s == nil {
+		return nil
+	}
+	ctx = context.WithoutCancel(ctx)
+
+	tables := make([]string, 0, len(outputs))
+	for table := range outputs {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		url := s.url(table)
+		if url == "" {
+			continue
+		}
+
+		rows := outputs[table]
+		for offset := 0; offset < len(rows); offset += s.batchSize {
+			payload := webhookPayload{Table: table, LedgerStart: start, LedgerEnd: end}
+			for _, row := range rows[offset:min(offset+s.batchSize, len(rows))] {
+				jsonRow, err := webhookRow(row, extra)
+				if err != nil {
+					return err
+				}
+				payload.Rows = append(payload.Rows, jsonRow)
+			}
+
+			body, err := json.Marshal(payload)
+			if err != nil {
+				return utils.PermanentError(fmt.Errorf("could not json encode the %s webhook payload: %v", table, err))
+			}
+			if err := s.post(ctx, url, table, body); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// webhookRow converts a row to the json object written by ExportEntry, including the extra fields. Rows that cannot be
+// converted fail the same way every time, so the errors are not retryable.
+func webhookRow(row interface{}, extra map[string]string) (map[string]interface{}, error) {
+	marshalled, err := json.Marshal(row)
+	if err != nil {
+		return nil, utils.PermanentError(fmt.Errorf("could not json encode %+v: %v", row, err))
+	}
+
+	decoded := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, utils.PermanentError(fmt.Errorf("could not json decode %s: %v", marshalled, err))
+	}
+	transform.CanonicalNumbers(decoded)
+	for k, v := range extra {
+		decoded[k] = v
+	}
+
+	return decoded, nil
+}
+
+// post sends a request, retrying with exponential backoff on network errors, 408, 429 and 5xx responses
+func (s *webhookSink) post(ctx context.Context, url, table string, body []byte) error {
+	var lastErr error
+	for attempt := 0; attempt <= s.retries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(s.backoff * time.Duration(1<<(attempt-1)))
+		}
+
+		err := s.postOnce(ctx, url, table, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !utils.IsRetryable(err) {
+			break
+		}
+		cmdLogger.Warnf("webhook request for %s failed, attempt %d of %d: %v", table, attempt+1, s.retries+1, err)
+	}
+
+	return fmt.Errorf("could not post %s rows to the webhook: %w", table, lastErr)
+}
+
+// postOnce sends a request. Its errors tell whether the request can be retried.
+func (s *webhookSink) postOnce(ctx context.Context, url, table string, body []byte) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return utils.PermanentError(err)
+	}
+
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(webhookTableHeader, table)
+	request.Header.Set(webhookTimestampHeader, timestamp)
+	if len(s.secret) > 0 {
+		request.Header.Set(webhookSignatureHeader, "sha256="+signWebhook(s.secret, timestamp, body))
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return utils.TransientError(err)
+	}
+	defer response.Body.Close()
+	io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+	err = fmt.Errorf("unexpected status %s", response.Status)
+	if utils.RetryableStatus(response.StatusCode) {
+		return utils.TransientError(err)
+	}
+	return utils.PermanentError(err)
+}
+
+// signWebhook returns the hex HMAC-SHA256 of the timestamp and body of a request
+func signWebhook(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+type webhookTestRequest struct {
+	path      string
+	headers   http.Header
+	body      []byte
+	payload   webhookPayload
+	timestamp string
+}
+
+func newWebhookTestServer(t *testing.T, statuses ...int) (*httptest.Server, func() []webhookTestRequest) {
+	var mu sync.Mutex
+	requests := []webhookTestRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		var payload webhookPayload
+		require.NoError(t, json.Unmarshal(body, &payload))
+
+		mu.Lock()
+		requests = append(requests, webhookTestRequest{path: r.URL.Path, headers: r.Header, body: body, payload: payload})
+		status := http.StatusOK
+		if len(statuses) > 0 {
+			status, statuses = statuses[0], statuses[1:]
+		}
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []webhookTestRequest {
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+}
+
+func newTestWebhookSink(values utils.WebhookFlagValues) *webhookSink {
+	sink := newWebhookSink(values)
+	sink.backoff = time.Millisecond
+	sink.now = func() time.Time { return time.Unix(1700000000, 0) }
+	return sink
+}
+
+func TestNewWebhookSinkDisabled(t *testing.T) {
+	assert.Nil(t, newWebhookSink(utils.WebhookFlagValues{BatchSize: 10}))
+	var sink *webhookSink
+	assert.NoError(t, sink.send(context.Background(), 1, 2, map[string][]interface{}{"accounts": {map[string]int{"balance": 1}}}, nil))
+}
+
+func TestWebhookSinkSend(t *testing.T) {
+	server, requests := newWebhookTestServer(t)
+	sink := newTestWebhookSink(utils.WebhookFlagValues{
+		URL:       server.URL + "/default",
+		Routes:    map[string]string{"offers": server.URL + "/offers", "ttl": ""},
+		Secret:    "secret",
+		BatchSize: 2,
+		Timeout:   time.Second,
+	})
+
+	outputs := map[string][]interface{}{
+		"accounts": {map[string]int{"balance": 1}, map[string]int{"balance": 2}, map[string]int{"balance": 3}},
+		"offers":   {map[string]int{"offer_id": 4}},
+		"ttl":      {map[string]int{"live_until_ledger_seq": 5}},
+		"signers":  {},
+	}
+	require.NoError(t, sink.send(context.Background(), 10, 20, outputs, map[string]string{"batch": "b"}))
+
+	received := requests()
+	require.Len(t, received, 3)
+
+	assert.Equal(t, "/default", received[0].path)
+	assert.Equal(t, webhookPayload{
+		Table:       "accounts",
+		LedgerStart: 10,
+		LedgerEnd:   20,
+		Rows: []map[string]interface{}{
+			{"balance": float64(1), "batch": "b"},
+			{"balance": float64(2), "batch": "b"},
+		},
+	}, received[0].payload)
+	assert.Equal(t, "accounts", received[0].headers.Get(webhookTableHeader))
+	assert.Equal(t, "1700000000", received[0].headers.Get(webhookTimestampHeader))
+	assert.Equal(t, "sha256="+signWebhook([]byte("secret"), "1700000000", received[0].body), received[0].headers.Get(webhookSignatureHeader))
+
+	assert.Equal(t, "/default", received[1].path)
+	assert.Len(t, received[1].payload.Rows, 1)
+
+	assert.Equal(t, "/offers", received[2].path)
+	assert.Equal(t, "offers", received[2].payload.Table)
+}
+
+func TestWebhookSinkRetries(t *testing.T) {
+	server, requests := newWebhookTestServer(t, http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	sink := newTestWebhookSink(utils.WebhookFlagValues{URL: server.URL, BatchSize: 10, Retries: 2, Timeout: time.Second})
+
+	require.NoError(t, sink.send(context.Background(), 1, 1, map[string][]interface{}{"offers": {map[string]int{"offer_id": 1}}}, nil))
+	assert.Len(t, requests(), 3)
+	assert.Empty(t, requests()[0].headers.Get(webhookSignatureHeader))
+
+	server, requests = newWebhookTestServer(t, http.StatusBadRequest)
+	sink = newTestWebhookSink(utils.WebhookFlagValues{URL: server.URL, BatchSize: 10, Retries: 2, Timeout: time.Second})
+	err := sink.send(context.Background(), 1, 1, map[string][]interface{}{"offers": {map[string]int{"offer_id": 1}}}, nil)
+	assert.EqualError(t, err, "could not post offers rows to the webhook: unexpected status 400 Bad Request")
+	assert.False(t, utils.IsRetryable(err))
+	assert.Len(t, requests(), 1)
+
+	server, requests = newWebhookTestServer(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	sink = newTestWebhookSink(utils.WebhookFlagValues{URL: server.URL, BatchSize: 10, Retries: 1, Timeout: time.Second})
+	err = sink.send(context.Background(), 1, 1, map[string][]interface{}{"offers": {map[string]int{"offer_id": 1}}}, nil)
+	assert.EqualError(t, err, "could not post offers rows to the webhook: unexpected status 500 Internal Server Error")
+	assert.Len(t, requests(), 2)
+}
+
+func TestWebhookSinkSendsAfterCancel(t *testing.T) {
+	server, requests := newWebhookTestServer(t)
+	sink := newTestWebhookSink(utils.WebhookFlagValues{URL: server.URL, BatchSize: 10, Timeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, sink.send(ctx, 1, 1, map[string][]interface{}{"offers": {map[string]int{"offer_id": 1}}}, nil))
+	assert.Len(t, requests(), 1)
+}
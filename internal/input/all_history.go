@@ -19,10 +19,9 @@ type AllHistoryTransformInput struct {
 }
 
 // GetAllHistory returns a slice of operations, trades, effects, transactions, diagnostic events
-// for the ledgers in the provided range (inclusive on both ends)
-func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) (AllHistoryTransformInput, error) {
-	ctx := context.Background()
-
+// for the ledgers in the provided range (inclusive on both ends). Once the context is done, the ledgers read so far are
+// returned.
+func GetAllHistory(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) (AllHistoryTransformInput, error) {
 	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
 	if err != nil {
 		return AllHistoryTransformInput{}, err
@@ -33,7 +32,7 @@ func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails,
 	txSlice := []LedgerTransformInput{}
 	err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end))
 	panicIf(err)
-	for seq := start; seq <= end; seq++ {
+	for seq := start; seq <= end && ctx.Err() == nil; seq++ {
 		changeReader, err := ingest.NewLedgerChangeReader(ctx, backend, env.NetworkPassphrase, seq)
 		if err != nil {
 			return AllHistoryTransformInput{}, err
@@ -69,7 +68,7 @@ func GetAllHistory(start, end uint32, limit int64, env utils.EnvironmentDetails,
 				})
 
 				// Trades
-				if operationResultsInTrade(op) && tx.Result.Successful() {
+				if OperationResultsInTrade(op) && tx.Result.Successful() {
 					tradeSlice = append(tradeSlice, TradeTransformInput{
 						OperationIndex:     int32(index),
 						Transaction:        tx,
@@ -9,23 +9,32 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
-// GetPaymentOperationsHistoryArchive returns a slice of payment operations that can include new assets from the ledgers in the provided range (inclusive on both ends)
-func GetPaymentOperationsHistoryArchive(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptivere bool) ([]AssetTransformInput, error) {
+// GetPaymentOperationsHistoryArchive returns a slice of payment operations that can include new assets from the ledgers in the provided range (inclusive on both ends),
+// and the last ledger read. The limit stops the reads at the end of a ledger, so the last ledger read is always exported
+// whole. Once the context is done, the ledgers read so far are returned.
+func GetPaymentOperationsHistoryArchive(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptivere bool) ([]AssetTransformInput, uint32, error) {
 	backend, err := utils.CreateBackend(start, end, env.ArchiveURLs)
 	if err != nil {
-		return []AssetTransformInput{}, err
+		return []AssetTransformInput{}, 0, err
 	}
 
 	assetSlice := []AssetTransformInput{}
-	ctx := context.Background()
-	for seq := start; seq <= end; seq++ {
+	lastLedger := start - 1
+	for seq := start; seq <= end && ctx.Err() == nil; seq++ {
 		// Get ledger from sequence number
 		ledger, err := backend.GetLedgerArchive(ctx, seq)
 		if err != nil {
-			return []AssetTransformInput{}, err
+			if ctx.Err() != nil {
+				break
+			}
+			return []AssetTransformInput{}, 0, err
 		}
+		lastLedger = seq
 
-		transactionSet := transform.GetTransactionSet(ledger)
+		transactionSet, err := transform.GetTransactionSet(ledger)
+		if err != nil {
+			return []AssetTransformInput{}, 0, err
+		}
 
 		for txIndex, transaction := range transactionSet {
 			for opIndex, op := range transaction.Operations() {
@@ -47,5 +56,5 @@ func GetPaymentOperationsHistoryArchive(start, end uint32, limit int64, env util
 		}
 	}
 
-	return assetSlice, nil
+	return assetSlice, lastLedger, nil
 }
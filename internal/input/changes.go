@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"math"
+	"time"
 
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 
@@ -27,6 +28,8 @@ type ChangeBatch struct {
 	Changes    map[xdr.LedgerEntryType]LedgerChanges
 	BatchStart uint32
 	BatchEnd   uint32
+	// CloseTime is the close time of the last ledger read into the batch
+	CloseTime time.Time
 }
 
 // PrepareCaptiveCore creates a new captive core instance and prepares it with the given range. The range is unbounded when end = 0, and is bounded and validated otherwise
@@ -81,16 +84,19 @@ func PrepareCaptiveCore(execPath string, tomlPath string, start, end uint32, env
 	return captiveBackend, nil
 }
 
-// extractBatch gets the changes from the ledgers in the range [batchStart, batchEnd] and compacts them
+// extractBatch gets the changes from the ledgers in the range [batchStart, batchEnd] and compacts them.
+// If the context is cancelled, the batch ends at the last ledger that was fully read.
 func extractBatch(
+	ctx context.Context,
 	batchStart, batchEnd uint32,
 	backend *ledgerbackend.LedgerBackend,
-	env utils.EnvironmentDetails, logger *utils.EtlLogger) ChangeBatch {
+	env utils.EnvironmentDetails, logger *utils.EtlLogger) (ChangeBatch, error) {
 
 	dataTypes := []xdr.LedgerEntryType{
 		xdr.LedgerEntryTypeAccount,
 		xdr.LedgerEntryTypeOffer,
 		xdr.LedgerEntryTypeTrustline,
+		xdr.LedgerEntryTypeData,
 		xdr.LedgerEntryTypeLiquidityPool,
 		xdr.LedgerEntryTypeClaimableBalance,
 		xdr.LedgerEntryTypeContractData,
@@ -99,8 +105,13 @@ func extractBatch(
 		xdr.LedgerEntryTypeTtl}
 
 	ledgerChanges := map[xdr.LedgerEntryType]LedgerChanges{}
-	ctx := context.Background()
+	var closeTime time.Time
 	for seq := batchStart; seq <= batchEnd; {
+		if ctx.Err() != nil {
+			batchEnd = seq - 1
+			break
+		}
+
 		changeCompactors := map[xdr.LedgerEntryType]*ingest.ChangeCompactor{}
 		for _, dt := range dataTypes {
 			changeCompactors[dt] = ingest.NewChangeCompactor()
@@ -111,10 +122,19 @@ func extractBatch(
 		var header xdr.LedgerHeaderHistoryEntry
 		if seq <= batchEnd {
 			changeReader, err := ingest.NewLedgerChangeReader(ctx, *backend, env.NetworkPassphrase, seq)
+			if err != nil && ctx.Err() != nil {
+				batchEnd = seq - 1
+				break
+			}
 			if err != nil {
-				logger.Fatal(fmt.Sprintf("unable to create change reader for ledger %d: ", seq), err)
+				return ChangeBatch{}, fmt.Errorf("unable to create change reader for ledger %d: %w", seq, err)
 			}
 			header = changeReader.LedgerTransactionReader.GetHeader()
+			closeTime, err = utils.ExtractLedgerCloseTime(header)
+			if err != nil {
+				changeReader.Close()
+				return ChangeBatch{}, fmt.Errorf("unable to extract close time from ledger %d: %w", seq, err)
+			}
 
 			for {
 				change, err := changeReader.Read()
@@ -122,16 +142,12 @@ func extractBatch(
 					break
 				}
 				if err != nil {
-					logger.Fatal(fmt.Sprintf("unable to read changes from ledger %d: ", seq), err)
+					changeReader.Close()
+					return ChangeBatch{}, fmt.Errorf("unable to read changes from ledger %d: %w", seq, err)
 				}
 				cache, ok := changeCompactors[change.Type]
 				if !ok {
-					// TODO: once LedgerEntryTypeData is tracked as well, all types should be addressed,
-					// so this info log should be a warning.
-					// Skip LedgerEntryTypeData as we are intentionally not processing it
-					if change.Type != xdr.LedgerEntryTypeData {
-						logger.Infof("change type: %v not tracked", change.Type)
-					}
+					logger.Warnf("change type: %v not tracked", change.Type)
 				} else {
 					cache.AddChange(change)
 				}
@@ -156,25 +172,43 @@ func extractBatch(
 		Changes:    ledgerChanges,
 		BatchStart: batchStart,
 		BatchEnd:   batchEnd,
-	}
+		CloseTime:  closeTime,
+	}, nil
 }
 
 // StreamChanges reads in ledgers, processes the changes, and send the changes to the channel matching their type
-// Ledgers are processed in batches of size <batchSize>.
-func StreamChanges(backend *ledgerbackend.LedgerBackend, start, end, batchSize uint32, changeChannel chan ChangeBatch, closeChan chan int, env utils.EnvironmentDetails, logger *utils.EtlLogger) {
+// Ledgers are processed in batches of size <batchSize>. Once the context is cancelled, the partially read batch
+// is sent and no further batches are read. The error that stopped the stream, or nil, is sent to closeChan once the
+// change channel is closed. The read durations are recorded under networkName.
+func StreamChanges(ctx context.Context, backend *ledgerbackend.LedgerBackend, start, end, batchSize uint32, changeChannel chan ChangeBatch, closeChan chan error, env utils.EnvironmentDetails, networkName string, logger *utils.EtlLogger) {
+	var err error
+	defer func() {
+		close(changeChannel)
+		closeChan <- err
+	}()
+
 	batchStart := start
 	batchEnd := uint32(math.Min(float64(batchStart+batchSize), float64(end)))
-	for batchStart < batchEnd {
+	for batchStart < batchEnd && ctx.Err() == nil {
 		if batchEnd < end {
 			batchEnd = uint32(batchEnd - 1)
 		}
-		batch := ExtractBatch(batchStart, batchEnd, backend, env, logger)
+		readStart := time.Now()
+		readCtx, readSpan := utils.StartSpan(ctx, "read", utils.LedgerRangeAttributes(batchStart, batchEnd)...)
+		var batch ChangeBatch
+		batch, err = ExtractBatch(readCtx, batchStart, batchEnd, backend, env, logger)
+		readSpan.End()
+		if err != nil {
+			return
+		}
+		utils.RecordPhaseDuration(ctx, networkName, "read", time.Since(readStart))
+		if batch.BatchEnd < batch.BatchStart {
+			break
+		}
 		changeChannel <- batch
 		// batchStart and batchEnd should not overlap
 		// overlapping batches causes duplicate record loads
 		batchStart = uint32(math.Min(float64(batchEnd), float64(end)) + 1)
 		batchEnd = uint32(math.Min(float64(batchStart+batchSize), float64(end)))
 	}
-	close(changeChannel)
-	closeChan <- 1
 }
@@ -1,13 +1,19 @@
 package input
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
 	"github.com/stellar/go/support/log"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"github.com/stellar/go/xdr"
 )
@@ -128,15 +134,16 @@ func wrapLedgerEntry(entryType xdr.LedgerEntryType, entry xdr.LedgerEntry) Chang
 }
 
 func mockExtractBatch(
+	ctx context.Context,
 	batchStart, batchEnd uint32,
 	backend *ledgerbackend.LedgerBackend,
-	env utils.EnvironmentDetails, logger *utils.EtlLogger) ChangeBatch {
+	env utils.EnvironmentDetails, logger *utils.EtlLogger) (ChangeBatch, error) {
 	log.Errorf("mock called")
 	return ChangeBatch{
 		Changes:    map[xdr.LedgerEntryType]LedgerChanges{},
 		BatchStart: batchStart,
 		BatchEnd:   batchEnd,
-	}
+	}, nil
 }
 
 func TestStreamChangesBatchNumbers(t *testing.T) {
@@ -207,7 +214,7 @@ func TestStreamChangesBatchNumbers(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			batchSize := uint32(64)
 			changeChan := make(chan ChangeBatch, 10)
-			closeChan := make(chan int)
+			closeChan := make(chan error, 1)
 			env := utils.EnvironmentDetails{
 				NetworkPassphrase: "",
 				ArchiveURLs:       nil,
@@ -216,7 +223,7 @@ func TestStreamChangesBatchNumbers(t *testing.T) {
 			}
 			logger := utils.NewEtlLogger()
 			ExtractBatch = mockExtractBatch
-			go StreamChanges(nil, tt.args.batchStart, tt.args.batchEnd, batchSize, changeChan, closeChan, env, logger)
+			go StreamChanges(context.Background(), nil, tt.args.batchStart, tt.args.batchEnd, batchSize, changeChan, closeChan, env, "testnet", logger)
 			var got []batchRange
 			for b := range changeChan {
 				got = append(got, batchRange{
@@ -228,3 +235,98 @@ func TestStreamChangesBatchNumbers(t *testing.T) {
 		})
 	}
 }
+
+func TestStreamChangesCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The shutdown signal arrives while the first batch is being read
+	ExtractBatch = func(
+		ctx context.Context,
+		batchStart, batchEnd uint32,
+		backend *ledgerbackend.LedgerBackend,
+		env utils.EnvironmentDetails, logger *utils.EtlLogger) (ChangeBatch, error) {
+		cancel()
+		return ChangeBatch{
+			Changes:    map[xdr.LedgerEntryType]LedgerChanges{},
+			BatchStart: batchStart,
+			BatchEnd:   batchStart + 9,
+		}, nil
+	}
+	defer func() { ExtractBatch = extractBatch }()
+
+	changeChan := make(chan ChangeBatch, 10)
+	closeChan := make(chan error, 1)
+	go StreamChanges(ctx, nil, 1, 256, 64, changeChan, closeChan, utils.EnvironmentDetails{}, "testnet", utils.NewEtlLogger())
+
+	var got []ChangeBatch
+	for b := range changeChan {
+		got = append(got, b)
+	}
+	assert.NoError(t, <-closeChan)
+
+	assert.Len(t, got, 1)
+	assert.Equal(t, uint32(1), got[0].BatchStart)
+	assert.Equal(t, uint32(10), got[0].BatchEnd)
+}
+
+func TestStreamChangesError(t *testing.T) {
+	// The second batch cannot be read, so the stream stops with the error after the first one
+	ExtractBatch = func(
+		ctx context.Context,
+		batchStart, batchEnd uint32,
+		backend *ledgerbackend.LedgerBackend,
+		env utils.EnvironmentDetails, logger *utils.EtlLogger) (ChangeBatch, error) {
+		if batchStart > 1 {
+			return ChangeBatch{}, errors.New("unable to read changes from ledger 65")
+		}
+		return ChangeBatch{Changes: map[xdr.LedgerEntryType]LedgerChanges{}, BatchStart: batchStart, BatchEnd: batchEnd}, nil
+	}
+	defer func() { ExtractBatch = extractBatch }()
+
+	changeChan := make(chan ChangeBatch, 10)
+	closeChan := make(chan error, 1)
+	go StreamChanges(context.Background(), nil, 1, 256, 64, changeChan, closeChan, utils.EnvironmentDetails{}, "testnet", utils.NewEtlLogger())
+
+	var got []ChangeBatch
+	for b := range changeChan {
+		got = append(got, b)
+	}
+	assert.Len(t, got, 1)
+	assert.EqualError(t, <-closeChan, "unable to read changes from ledger 65")
+}
+
+func TestExtractBatchCloseTime(t *testing.T) {
+	mockBackend := &ledgerbackend.MockDatabaseBackend{}
+	for seq := uint32(10); seq <= 12; seq++ {
+		mockBackend.On("GetLedger", mock.Anything, seq).Return(makeStagesTestLedger(seq), nil).Once()
+	}
+	var backend ledgerbackend.LedgerBackend = mockBackend
+	env := utils.EnvironmentDetails{NetworkPassphrase: network.TestNetworkPassphrase}
+
+	// The close time of a batch is the one of its last ledger
+	batch, err := extractBatch(context.Background(), 10, 12, &backend, env, utils.NewEtlLogger())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(12), batch.BatchEnd)
+	assert.Equal(t, time.Unix(1012, 0).UTC(), batch.CloseTime.UTC())
+	mockBackend.AssertExpectations(t)
+}
+
+func TestExtractBatchCancelledCloseTime(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The shutdown signal arrives once ledger 11 is read, so that the batch ends there
+	mockBackend := &ledgerbackend.MockDatabaseBackend{}
+	mockBackend.On("GetLedger", mock.Anything, uint32(10)).Return(makeStagesTestLedger(10), nil).Once()
+	mockBackend.On("GetLedger", mock.Anything, uint32(11)).Run(func(mock.Arguments) { cancel() }).
+		Return(makeStagesTestLedger(11), nil).Once()
+	var backend ledgerbackend.LedgerBackend = mockBackend
+	env := utils.EnvironmentDetails{NetworkPassphrase: network.TestNetworkPassphrase}
+
+	batch, err := extractBatch(ctx, 10, 20, &backend, env, utils.NewEtlLogger())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(11), batch.BatchEnd)
+	assert.Equal(t, time.Unix(1011, 0).UTC(), batch.CloseTime.UTC())
+	mockBackend.AssertExpectations(t)
+}
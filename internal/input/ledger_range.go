@@ -26,8 +26,6 @@ type graph struct {
 	EndPoint   graphPoint
 }
 
-const avgCloseTime = time.Second * 5 // average time to close a stellar ledger
-
 // GetLedgerRange calculates the ledger range that spans the provided date range
 func GetLedgerRange(startTime, endTime time.Time, isTest bool, isFuture bool) (int64, int64, error) {
 	startTime = startTime.UTC()
@@ -52,14 +50,12 @@ func GetLedgerRange(startTime, endTime time.Time, isTest bool, isFuture bool) (i
 		return 0, 0, err
 	}
 
-	// Ledger sequence 2 is the start ledger because the genesis ledger (ledger 1), has a close time of 0 in Unix time.
-	// The second ledger has a valid close time that matches with the network start time.
-	startLedger, err := graph.findLedgerForDate(2, startTime, map[int64]struct{}{})
+	startLedger, err := graph.findLedgerForTime(startTime)
 	if err != nil {
 		return 0, 0, err
 	}
 
-	endLedger, err := graph.findLedgerForDate(2, endTime, map[int64]struct{}{})
+	endLedger, err := graph.findLedgerForTime(endTime)
 	if err != nil {
 		return 0, 0, err
 	}
@@ -67,15 +63,46 @@ func GetLedgerRange(startTime, endTime time.Time, isTest bool, isFuture bool) (i
 	return startLedger, endLedger, nil
 }
 
+// GetLedgerForTime returns the sequence of the ledger containing the provided time, which is the first ledger that
+// closed on or after it. Times after the most recent ledger resolve to it.
+func GetLedgerForTime(targetTime time.Time, isTest bool, isFuture bool) (int64, error) {
+	commonFlagValues := utils.CommonFlagValues{
+		IsTest:   isTest,
+		IsFuture: isFuture,
+	}
+	env := utils.GetEnvironmentDetails(commonFlagValues)
+
+	archive, err := utils.CreateHistoryArchiveClient(env.ArchiveURLs)
+	if err != nil {
+		return 0, err
+	}
+
+	return LedgerForTime(archive, targetTime)
+}
+
+// LedgerForTime returns the sequence of the ledger containing the provided time, read from the given history archive
+func LedgerForTime(archive historyarchive.ArchiveInterface, targetTime time.Time) (int64, error) {
+	graph, err := newGraph(archive)
+	if err != nil {
+		return 0, err
+	}
+
+	return graph.findLedgerForTime(targetTime.UTC())
+}
+
 // createNewGraph makes a new graph with the endpoints equal to the network's endpoints
 func createNewGraph(archiveURLs []string) (graph, error) {
-	graph := graph{}
 	archive, err := utils.CreateHistoryArchiveClient(archiveURLs)
 	if err != nil {
-		return graph, err
+		return graph{}, err
 	}
 
-	graph.Client = archive
+	return newGraph(archive)
+}
+
+// newGraph makes a new graph over the ledgers of the archive
+func newGraph(archive historyarchive.ArchiveInterface) (graph, error) {
+	graph := graph{Client: archive}
 
 	secondLedgerPoint, err := graph.getGraphPoint(2) // the second ledger has a real close time, unlike the 1970s close time of the genesis ledger
 	if err != nil {
@@ -98,99 +125,26 @@ func createNewGraph(archiveURLs []string) (graph, error) {
 	return graph, nil
 }
 
-func (g graph) findLedgerForTimeBinary(targetTime time.Time, start, end graphPoint) (int64, error) {
-	if end.Seq >= 2 {
-		middleLedger := start.Seq + (end.Seq-start.Seq)/2
-		middleTime, err := g.getGraphPoint(middleLedger)
-		if err != nil {
-			return 0, err
-		}
-
-		// check if middle element is the one to choose
-		if middleLedger > 1 {
-			prevLedger := middleLedger - 1
-			prevTime, err := g.getGraphPoint(prevLedger)
-			if err != nil {
-				return 0, err
-			}
-
-			if prevTime.CloseTime.Unix() < targetTime.Unix() && middleTime.CloseTime.Unix() >= targetTime.Unix() {
-				return middleLedger, nil
-			}
-		}
-
-		if middleTime.CloseTime.Unix() > targetTime.Unix() {
-			newEnd, err := g.getGraphPoint(middleLedger - 1)
-			if err != nil {
-				return 0, err
-			}
-
-			return g.findLedgerForTimeBinary(targetTime, start, newEnd)
-		}
-
-		newStart, err := g.getGraphPoint(middleLedger + 1)
-		if err != nil {
-			return 0, err
-		}
-
-		return g.findLedgerForTimeBinary(targetTime, newStart, end)
-	}
-
-	return 0, fmt.Errorf("unable to find ledger with close time %v: ", targetTime)
-}
-
-// findLedgerForDate recursively searches for the ledger that was closed on or directly after targetTime
-func (g graph) findLedgerForDate(currentLedger int64, targetTime time.Time, seenLedgers map[int64]struct{}) (int64, error) {
-	seenLedgers[currentLedger] = struct{}{}
-
-	currentPoint, err := g.getGraphPoint(currentLedger)
-	if err != nil {
-		return 0, err
-	}
-
-	if currentLedger > 1 {
-		prevLedger := currentLedger - 1
-		prevTime, err := g.getGraphPoint(prevLedger)
+// findLedgerForTime binary searches the ledgers of the graph for the ledger containing targetTime: the first ledger that
+// closed on or after it. Close times increase with the sequence, so the search fetches about log2 of the network's
+// ledger count headers. Times outside the graph resolve to its first or last ledger.
+func (g graph) findLedgerForTime(targetTime time.Time) (int64, error) {
+	low, high := g.BeginPoint.Seq, g.EndPoint.Seq
+	for low < high {
+		middle := low + (high-low)/2
+		middlePoint, err := g.getGraphPoint(middle)
 		if err != nil {
 			return 0, err
 		}
 
-		if prevTime.CloseTime.Unix() < targetTime.Unix() && currentPoint.CloseTime.Unix() >= targetTime.Unix() {
-			return currentLedger, nil
-		}
-	}
-
-	timeDiff := targetTime.Sub(currentPoint.CloseTime).Seconds()
-	ledgerOffset := int64(timeDiff / avgCloseTime.Seconds())
-	if ledgerOffset == 0 {
-		if timeDiff > 0 {
-			ledgerOffset = 1
+		if middlePoint.CloseTime.Unix() < targetTime.Unix() {
+			low = middle + 1
 		} else {
-			ledgerOffset = -1
+			high = middle
 		}
 	}
 
-	newLedger := currentLedger + ledgerOffset
-
-	if newLedger > g.EndPoint.Seq {
-		newLedger = g.EndPoint.Seq
-	} else if newLedger < g.BeginPoint.Seq {
-		// since we started with BeginPoint, returning to it would create an infinite cycle;
-		newLedger = g.BeginPoint.Seq + 1
-	}
-
-	// if we have already seen this ledger, it means the algorithm is trapped in a cycle; use binary search instead (slower but will find the ledger)
-	if _, exists := seenLedgers[newLedger]; exists {
-		// since we have already calculated the current point, we can use it as the upper or lower bound.
-		// This way, the binary search doesn't have to search the entire space
-		if ledgerOffset > 0 {
-			return g.findLedgerForTimeBinary(targetTime, currentPoint, g.EndPoint)
-		}
-
-		return g.findLedgerForTimeBinary(targetTime, g.BeginPoint, currentPoint)
-	}
-
-	return g.findLedgerForDate(newLedger, targetTime, seenLedgers)
+	return low, nil
 }
 
 // limitLedgerRange restricts start and end by setting them to be the edges of the network's range if they are outside that range
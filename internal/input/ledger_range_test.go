@@ -0,0 +1,50 @@
+package input
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newLedgerRangeTestArchive serves ledgers 2 to latest, each closing 5 seconds after the previous one
+func newLedgerRangeTestArchive(latest uint32) *historyarchive.MockArchive {
+	archive := &historyarchive.MockArchive{}
+	archive.On("GetRootHAS").Return(historyarchive.HistoryArchiveState{CurrentLedger: latest}, nil)
+	for seq := uint32(2); seq <= latest; seq++ {
+		header := xdr.LedgerHeaderHistoryEntry{
+			Header: xdr.LedgerHeader{
+				LedgerSeq: xdr.Uint32(seq),
+				ScpValue:  xdr.StellarValue{CloseTime: xdr.TimePoint(1000 + 5*seq)},
+			},
+		}
+		archive.On("GetLedgerHeader", seq).Return(header, nil).Maybe()
+	}
+	return archive
+}
+
+func TestLedgerForTime(t *testing.T) {
+	archive := newLedgerRangeTestArchive(1000)
+
+	tests := []struct {
+		name       string
+		targetTime time.Time
+		wantLedger int64
+	}{
+		{"close time of a ledger", time.Unix(1000+5*500, 0), 500},
+		{"between close times", time.Unix(1000+5*500+2, 0), 501},
+		{"before the network", time.Unix(0, 0), 2},
+		{"after the latest ledger", time.Unix(1000+5*2000, 0), 1000},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ledger, err := LedgerForTime(archive, test.targetTime)
+			require.NoError(t, err)
+			assert.Equal(t, test.wantLedger, ledger)
+		})
+	}
+}
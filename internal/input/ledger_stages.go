@@ -0,0 +1,168 @@
+package input
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// StageOptions size the stages of TransformLedgerRange
+type StageOptions struct {
+	// ReadAhead is how many ledgers are fetched ahead of the one being written; 2 * TransformWorkers if 0
+	ReadAhead int
+	// TransformWorkers is how many ledgers are decoded and transformed at once; the number of CPUs if 0
+	TransformWorkers int
+	// Network is the network attribute of the stage metrics
+	Network string
+	// Sample selects the ledgers that are transformed and written; all of them if empty
+	Sample utils.LedgerSample
+	// Toid encodes the ids of the rows
+	Toid toid.Generator
+}
+
+// TransformedLedger holds the rows of every table for a ledger, in the order of the tables that were transformed.
+// Errors holds the transform error of each table, if any.
+type TransformedLedger struct {
+	Sequence uint32
+	Rows     [][]interface{}
+	Errors   []error
+}
+
+// stagedLedger is a ledger on its way through the stages. done is closed once the ledger is transformed, so that
+// the write stage can wait for the ledgers in sequence order while they are transformed in parallel.
+type stagedLedger struct {
+	TransformedLedger
+	lcm  xdr.LedgerCloseMeta
+	err  error
+	done chan struct{}
+}
+
+// TransformLedgerRange reads the ledgers of a prepared range from the backend and transforms them into the rows of
+// the tables. Fetching, transforming and writing run as separate stages connected by bounded channels: a single
+// goroutine fetches the ledgers in order, since the backends are read sequentially, a pool of workers decodes and
+// transforms them, and write is called with each transformed ledger in sequence order. Fetch, decode and write
+// errors stop the stages and are returned; transform errors are handed to write along with the rows.
+func TransformLedgerRange(
+	ctx context.Context,
+	backend ledgerbackend.LedgerBackend,
+	start, end uint32,
+	networkPassphrase string,
+	tables []LedgerTable,
+	options StageOptions,
+	write func(TransformedLedger) error) error {
+	workers := options.TransformWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	readAhead := options.ReadAhead
+	if readAhead <= 0 {
+		readAhead = 2 * workers
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	toTransform := make(chan *stagedLedger, readAhead)
+	toWrite := make(chan *stagedLedger, readAhead)
+
+	var fetchErr error
+	go func() {
+		defer close(toTransform)
+		defer close(toWrite)
+		for seq := start; seq <= end && seq >= start; seq++ {
+			fetchStart := time.Now()
+			lcm, sampled, ok, err := nextLedger(ctx, backend, seq, options.Sample)
+			if err != nil {
+				fetchErr = fmt.Errorf("error getting ledger seq %d from the backend: %w", seq, err)
+				return
+			}
+			if !ok {
+				return
+			}
+			if !sampled {
+				continue
+			}
+			utils.RecordPhaseDuration(ctx, options.Network, "read", time.Since(fetchStart))
+
+			ledger := &stagedLedger{TransformedLedger: TransformedLedger{Sequence: seq}, lcm: lcm, done: make(chan struct{})}
+			// toWrite is sent to first, so that the write stage bounds how far ahead the fetch stage can get
+			select {
+			case toWrite <- ledger:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case toTransform <- ledger:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Only the parts of the ledgers read by the tables are decoded, so that tables left out cost nothing
+	parts := TablesReads(tables)
+	var workersDone sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		workersDone.Add(1)
+		go func() {
+			defer workersDone.Done()
+			for ledger := range toTransform {
+				transformStart := time.Now()
+				transformStagedLedger(ledger, networkPassphrase, options.Toid, tables, parts)
+				utils.RecordPhaseDuration(ctx, options.Network, "transform", time.Since(transformStart))
+				close(ledger.done)
+			}
+		}()
+	}
+	// Cancelling unblocks the fetch stage, which then closes the channels so that the workers can exit
+	defer func() {
+		cancel()
+		workersDone.Wait()
+	}()
+
+	for ledger := range toWrite {
+		select {
+		case <-ledger.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if ledger.err != nil {
+			return ledger.err
+		}
+
+		writeStart := time.Now()
+		if err := write(ledger.TransformedLedger); err != nil {
+			return err
+		}
+		utils.RecordPhaseDuration(ctx, options.Network, "write", time.Since(writeStart))
+	}
+
+	if fetchErr != nil {
+		return fetchErr
+	}
+	return ctx.Err()
+}
+
+// transformStagedLedger decodes the parts of a fetched ledger read by the tables and transforms it into the rows of every table
+func transformStagedLedger(ledger *stagedLedger, networkPassphrase string, ids toid.Generator, tables []LedgerTable, parts LedgerParts) {
+	decoded, err := DecodeLedgerParts(ledger.lcm, networkPassphrase, parts)
+	ledger.lcm = xdr.LedgerCloseMeta{}
+	if err != nil {
+		ledger.err = fmt.Errorf("could not decode ledger %d: %w", ledger.Sequence, err)
+		return
+	}
+	decoded.Toid = ids
+
+	ledger.Rows = make([][]interface{}, len(tables))
+	ledger.Errors = make([]error, len(tables))
+	for i, table := range tables {
+		ledger.Rows[i], ledger.Errors[i] = table.Transform(decoded, networkPassphrase)
+	}
+}
@@ -0,0 +1,123 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func makeStagesTestLedger(seq uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:  xdr.StellarValue{CloseTime: xdr.TimePoint(1000 + seq)},
+					LedgerSeq: xdr.Uint32(seq),
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+		},
+	}
+}
+
+func TestTransformLedgerRange(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	for seq := uint32(10); seq <= 19; seq++ {
+		backend.On("GetLedger", mock.Anything, seq).Return(makeStagesTestLedger(seq), nil)
+	}
+
+	// Earlier ledgers take longer to transform, so that the workers finish them out of order
+	tables := []LedgerTable{
+		{Name: "sequences", Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			seq := uint32(ledger.Header.Header.LedgerSeq)
+			time.Sleep(time.Duration(20-seq) * time.Millisecond)
+			if seq == 15 {
+				return nil, errors.New("broken ledger")
+			}
+			return []interface{}{seq}, nil
+		}},
+	}
+
+	written := []uint32{}
+	failed := []uint32{}
+	err := TransformLedgerRange(context.Background(), backend, 10, 19, network.TestNetworkPassphrase, tables, StageOptions{TransformWorkers: 4, ReadAhead: 3}, func(ledger TransformedLedger) error {
+		written = append(written, ledger.Sequence)
+		if ledger.Errors[0] != nil {
+			failed = append(failed, ledger.Sequence)
+			return nil
+		}
+		assert.Equal(t, []interface{}{ledger.Sequence}, ledger.Rows[0])
+		return nil
+	})
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.Equal(t, []uint32{10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, written)
+	assert.Equal(t, []uint32{15}, failed)
+}
+
+func TestTransformLedgerRangeErrors(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeStagesTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(xdr.LedgerCloseMeta{}, errors.New("unavailable"))
+	tables := []LedgerTable{
+		{Name: "none", Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			return nil, nil
+		}},
+	}
+
+	writes := 0
+	err := TransformLedgerRange(context.Background(), backend, 10, 12, network.TestNetworkPassphrase, tables, StageOptions{}, func(ledger TransformedLedger) error {
+		writes++
+		return nil
+	})
+	assert.EqualError(t, err, "error getting ledger seq 11 from the backend: unavailable")
+	assert.Equal(t, 1, writes)
+
+	for seq := uint32(12); seq <= 20; seq++ {
+		backend.On("GetLedger", mock.Anything, seq).Return(makeStagesTestLedger(seq), nil).Maybe()
+	}
+	err = TransformLedgerRange(context.Background(), backend, 12, 20, network.TestNetworkPassphrase, tables, StageOptions{TransformWorkers: 2}, func(ledger TransformedLedger) error {
+		return errors.New("disk full")
+	})
+	assert.EqualError(t, err, "disk full")
+}
+
+func TestTransformLedgerRangeSample(t *testing.T) {
+	// Only the sampled ledgers are read from the backend
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	for seq := uint32(10); seq <= 25; seq += 5 {
+		backend.On("GetLedger", mock.Anything, seq).Return(makeStagesTestLedger(seq), nil).Once()
+	}
+	tables := []LedgerTable{
+		{Name: "none", Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			return nil, nil
+		}},
+	}
+
+	sample, err := utils.ParseLedgerSample("1/5", false, 0)
+	require.NoError(t, err)
+	written := []uint32{}
+	err = TransformLedgerRange(context.Background(), backend, 10, 25, network.TestNetworkPassphrase, tables, StageOptions{Sample: sample}, func(ledger TransformedLedger) error {
+		written = append(written, ledger.Sequence)
+		return nil
+	})
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+	assert.Equal(t, []uint32{10, 15, 20, 25}, written)
+	assert.Equal(t, "0.2", sample.Rate())
+}
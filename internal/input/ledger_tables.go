@@ -0,0 +1,343 @@
+package input
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// DecodedLedger is a ledger along with its transactions and ledger entry changes, read ahead of time so that the
+// transforms of every table can run over it
+type DecodedLedger struct {
+	Ledger       utils.HistoryArchiveLedgerAndLCM
+	Header       xdr.LedgerHeaderHistoryEntry
+	CloseTime    time.Time
+	Transactions []ingest.LedgerTransaction
+	Changes      []ingest.Change
+	// Toid encodes the ids of the rows transformed from the ledger
+	Toid toid.Generator
+}
+
+// LedgerParts are the parts of a ledger that are decoded ahead of the transforms, as a bit set
+type LedgerParts uint8
+
+const (
+	LedgerTransactions LedgerParts = 1 << iota
+	LedgerEntryChanges
+
+	AllLedgerParts = LedgerTransactions | LedgerEntryChanges
+)
+
+// LedgerTable is a table that can be transformed from a single ledger
+type LedgerTable struct {
+	Name   string
+	Output interface{}
+	// Reads are the parts of the decoded ledger that the transform reads; the others are left empty when only
+	// tables that do not read them are transformed
+	Reads     LedgerParts
+	Transform func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error)
+}
+
+// LedgerInputs turn a decoded ledger into the inputs of the transform of an export command, so that the export
+// commands can stream their inputs through TransformLedgerRange rather than reading the whole range first
+type LedgerInputs[In any] struct {
+	// Reads are the parts of the decoded ledger that Build reads
+	Reads LedgerParts
+	// WholeLedgers is set when the limit of the export only stops it at the end of a ledger, rather than at the
+	// input that reaches the limit
+	WholeLedgers bool
+	Build        func(ledger DecodedLedger) []In
+}
+
+// TablesReads returns the parts of a ledger read by any of the tables
+func TablesReads(tables []LedgerTable) LedgerParts {
+	var parts LedgerParts
+	for _, table := range tables {
+		parts |= table.Reads
+	}
+	return parts
+}
+
+// DecodeLedger reads the transactions and ledger entry changes of a ledger close meta
+func DecodeLedger(lcm xdr.LedgerCloseMeta, networkPassphrase string) (DecodedLedger, error) {
+	return DecodeLedgerParts(lcm, networkPassphrase, AllLedgerParts)
+}
+
+// DecodeLedgerParts reads the given parts of a ledger close meta. Reading the ledger entry changes is the costliest
+// part of decoding a ledger, so it is skipped when none of the transformed tables read them.
+func DecodeLedgerParts(lcm xdr.LedgerCloseMeta, networkPassphrase string, parts LedgerParts) (DecodedLedger, error) {
+	closeTime, err := utils.GetCloseTime(lcm)
+	if err != nil {
+		return DecodedLedger{}, err
+	}
+
+	ledger := DecodedLedger{
+		Ledger:    HistoryArchiveLedgerFromLCM(lcm),
+		Header:    lcm.LedgerHeaderHistoryEntry(),
+		CloseTime: closeTime,
+	}
+
+	if parts&LedgerTransactions != 0 {
+		txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, lcm)
+		if err != nil {
+			return DecodedLedger{}, err
+		}
+		defer txReader.Close()
+		for {
+			tx, err := txReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return DecodedLedger{}, err
+			}
+			ledger.Transactions = append(ledger.Transactions, tx)
+		}
+	}
+
+	if parts&LedgerEntryChanges != 0 {
+		changeReader, err := ingest.NewLedgerChangeReaderFromLedgerCloseMeta(networkPassphrase, lcm)
+		if err != nil {
+			return DecodedLedger{}, err
+		}
+		defer changeReader.Close()
+		for {
+			change, err := changeReader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return DecodedLedger{}, err
+			}
+			ledger.Changes = append(ledger.Changes, change)
+		}
+	}
+
+	return ledger, nil
+}
+
+// SelectLedgerTables returns the tables with the given names in order, or all of them if no names are given
+func SelectLedgerTables(names []string) ([]LedgerTable, error) {
+	tables := LedgerTables()
+	if len(names) == 0 {
+		return tables, nil
+	}
+
+	byName := map[string]LedgerTable{}
+	for _, table := range tables {
+		byName[table.Name] = table
+	}
+
+	selected := []LedgerTable{}
+	for _, name := range names {
+		table, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown table %s", name)
+		}
+		selected = append(selected, table)
+	}
+
+	return selected, nil
+}
+
+// transactionTable is a table whose transform is run once per transaction
+func transactionTable(name string, output interface{}, transformTx func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error)) LedgerTable {
+	return LedgerTable{Name: name, Output: output, Reads: LedgerTransactions, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+		rows := []interface{}{}
+		for _, tx := range ledger.Transactions {
+			transformed, err := transformTx(tx, ledger, networkPassphrase)
+			if err != nil {
+				return rows, err
+			}
+			rows = append(rows, transformed...)
+		}
+		return rows, nil
+	}}
+}
+
+// changeTable is a table whose transform is run once per ledger entry change of the given type
+func changeTable(name string, output interface{}, entryType xdr.LedgerEntryType, transformChange func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error)) LedgerTable {
+	return LedgerTable{Name: name, Output: output, Reads: LedgerEntryChanges, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+		rows := []interface{}{}
+		for _, change := range ledger.Changes {
+			if change.Type != entryType {
+				continue
+			}
+			transformed, err := transformChange(change, ledger, networkPassphrase)
+			if err != nil {
+				return rows, err
+			}
+			rows = append(rows, transformed)
+		}
+		return rows, nil
+	}}
+}
+
+// optionalChangeTable is a table whose transform is run once per ledger entry change of the given type, and only adds
+// a row for the changes it returns true for
+func optionalChangeTable(name string, output interface{}, entryType xdr.LedgerEntryType, transformChange func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, bool, error)) LedgerTable {
+	return LedgerTable{Name: name, Output: output, Reads: LedgerEntryChanges, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+		rows := []interface{}{}
+		for _, change := range ledger.Changes {
+			if change.Type != entryType {
+				continue
+			}
+			transformed, ok, err := transformChange(change, ledger, networkPassphrase)
+			if err != nil {
+				return rows, err
+			}
+			if ok {
+				rows = append(rows, transformed)
+			}
+		}
+		return rows, nil
+	}}
+}
+
+func toRows[T any](outputs []T) []interface{} {
+	rows := make([]interface{}, len(outputs))
+	for i, output := range outputs {
+		rows[i] = output
+	}
+	return rows
+}
+
+// LedgerTables returns the tables that can be transformed from a single ledger
+func LedgerTables() []LedgerTable {
+	return []LedgerTable{
+		{Name: "ledgers", Output: transform.LedgerOutput{}, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformLedger(ledger.Ledger.Ledger, ledger.Ledger.LCM, ledger.Toid)
+			return []interface{}{transformed}, err
+		}},
+		transactionTable("transactions", transform.TransactionOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformTransaction(tx, ledger.Header, ledger.Toid)
+			return []interface{}{transformed}, err
+		}),
+		transactionTable("operations", transform.OperationOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			rows := []interface{}{}
+			ledgerSeq := int32(ledger.Header.Header.LedgerSeq)
+			for index, op := range tx.Envelope.Operations() {
+				transformed, err := transform.TransformOperation(op, int32(index), tx, ledgerSeq, ledger.Ledger.LCM, networkPassphrase, ledger.Toid)
+				if err != nil {
+					return rows, err
+				}
+				rows = append(rows, transformed)
+			}
+			return rows, nil
+		}),
+		transactionTable("effects", transform.EffectOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformEffect(tx, uint32(ledger.Header.Header.LedgerSeq), ledger.Ledger.LCM, networkPassphrase, ledger.Toid)
+			return toRows(transformed), err
+		}),
+		transactionTable("trades", transform.TradeOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			rows := []interface{}{}
+			if !tx.Result.Successful() {
+				return rows, nil
+			}
+			ledgerSeq := int32(ledger.Header.Header.LedgerSeq)
+			for index, op := range tx.Envelope.Operations() {
+				if !OperationResultsInTrade(op) {
+					continue
+				}
+				operationID := ledger.Toid.New(ledgerSeq, int32(tx.Index), int32(index))
+				transformed, err := transform.TransformTrade(int32(index), operationID, tx, ledger.CloseTime, ledger.Toid)
+				if err != nil {
+					return rows, err
+				}
+				rows = append(rows, toRows(transformed)...)
+			}
+			return rows, nil
+		}),
+		transactionTable("fees", transform.FeeOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformFee(tx, ledger.Header, networkPassphrase, ledger.Toid)
+			return []interface{}{transformed}, err
+		}),
+		transactionTable("ledger_transaction", transform.LedgerTransactionOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformLedgerTransaction(tx, ledger.Header)
+			return []interface{}{transformed}, err
+		}),
+		transactionTable("contract_events", transform.ContractEventOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformContractEvent(tx, ledger.Header, ledger.Toid)
+			return toRows(transformed), err
+		}),
+		transactionTable("offer_events", transform.OfferEventOutput{}, func(tx ingest.LedgerTransaction, ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformOfferEvent(tx, ledger.Header, ledger.Toid)
+			return toRows(transformed), err
+		}),
+		{Name: "token_transfers", Output: transform.TokenTransferOutput{}, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformTokenTransfer(ledger.Ledger.LCM, networkPassphrase, ledger.Toid)
+			return toRows(transformed), err
+		}},
+		{Name: "archival_history", Output: transform.ArchivalHistoryOutput{}, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			transformed, err := transform.TransformArchivalHistory(ledger.Ledger.LCM, networkPassphrase, ledger.Toid)
+			return toRows(transformed), err
+		}},
+		changeTable("accounts", transform.AccountOutput{}, xdr.LedgerEntryTypeAccount, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformAccount(change, ledger.Header)
+		}),
+		optionalChangeTable("home_domain_history", transform.HomeDomainOutput{}, xdr.LedgerEntryTypeAccount, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, bool, error) {
+			return transform.TransformHomeDomainChange(change, ledger.Header)
+		}),
+		optionalChangeTable("inflation_destination_history", transform.InflationDestinationOutput{}, xdr.LedgerEntryTypeAccount, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, bool, error) {
+			return transform.TransformInflationDestinationChange(change, ledger.Header)
+		}),
+		optionalChangeTable("account_flags_history", transform.AccountFlagsOutput{}, xdr.LedgerEntryTypeAccount, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, bool, error) {
+			return transform.TransformAccountFlagsChange(change, ledger.Header)
+		}),
+		changeTable("account_data", transform.AccountDataOutput{}, xdr.LedgerEntryTypeData, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformAccountData(change, ledger.Header)
+		}),
+		changeTable("trustlines", transform.TrustlineOutput{}, xdr.LedgerEntryTypeTrustline, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformTrustline(change, ledger.Header)
+		}),
+		changeTable("offers", transform.OfferOutput{}, xdr.LedgerEntryTypeOffer, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformOffer(change, ledger.Header)
+		}),
+		changeTable("liquidity_pools", transform.PoolOutput{}, xdr.LedgerEntryTypeLiquidityPool, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformPool(change, ledger.Header)
+		}),
+		optionalChangeTable("pool_share_holders", transform.PoolShareHolderOutput{}, xdr.LedgerEntryTypeTrustline, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, bool, error) {
+			return transform.TransformPoolShareHolder(change, ledger.Header)
+		}),
+		changeTable("claimable_balances", transform.ClaimableBalanceOutput{}, xdr.LedgerEntryTypeClaimableBalance, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformClaimableBalance(change, ledger.Header)
+		}),
+		changeTable("contract_data", transform.ContractDataOutput{}, xdr.LedgerEntryTypeContractData, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			transformContractData := transform.NewTransformContractDataStruct(transform.AssetFromContractData, transform.ContractBalanceFromContractData)
+			transformed, err, _ := transformContractData.TransformContractData(change, networkPassphrase, ledger.Header)
+			return transformed, err
+		}),
+		{Name: "contract_balances", Output: transform.ContractBalanceOutput{}, Reads: LedgerEntryChanges, Transform: func(ledger DecodedLedger, networkPassphrase string) ([]interface{}, error) {
+			rows := []interface{}{}
+			for _, change := range ledger.Changes {
+				if change.Type != xdr.LedgerEntryTypeContractData {
+					continue
+				}
+				transformed, ok, err := transform.TransformContractBalance(change, ledger.Header)
+				if err != nil {
+					return rows, err
+				}
+				if ok {
+					rows = append(rows, transformed)
+				}
+			}
+			return rows, nil
+		}},
+		changeTable("contract_code", transform.ContractCodeOutput{}, xdr.LedgerEntryTypeContractCode, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformContractCode(change, ledger.Header)
+		}),
+		changeTable("config_settings", transform.ConfigSettingOutput{}, xdr.LedgerEntryTypeConfigSetting, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformConfigSetting(change, ledger.Header)
+		}),
+		changeTable("ttl", transform.TtlOutput{}, xdr.LedgerEntryTypeTtl, func(change ingest.Change, ledger DecodedLedger, networkPassphrase string) (interface{}, error) {
+			return transform.TransformTtl(change, ledger.Header)
+		}),
+	}
+}
@@ -0,0 +1,48 @@
+package input
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectLedgerTables(t *testing.T) {
+	tables, err := SelectLedgerTables(nil)
+	require.NoError(t, err)
+	assert.Equal(t, len(LedgerTables()), len(tables))
+
+	tables, err = SelectLedgerTables([]string{"trades", "ledgers"})
+	require.NoError(t, err)
+	require.Len(t, tables, 2)
+	assert.Equal(t, "trades", tables[0].Name)
+	assert.Equal(t, "ledgers", tables[1].Name)
+
+	_, err = SelectLedgerTables([]string{"unknown"})
+	assert.EqualError(t, err, "unknown table unknown")
+}
+
+func TestLedgerTablesOutputs(t *testing.T) {
+	names := map[string]bool{}
+	for _, table := range LedgerTables() {
+		assert.False(t, names[table.Name], "duplicate table %s", table.Name)
+		names[table.Name] = true
+		assert.NotNil(t, table.Output, "table %s", table.Name)
+	}
+}
+
+func TestTablesReads(t *testing.T) {
+	tables, err := SelectLedgerTables([]string{"ledgers", "token_transfers"})
+	require.NoError(t, err)
+	assert.Equal(t, LedgerParts(0), TablesReads(tables))
+
+	tables, err = SelectLedgerTables([]string{"effects", "trades", "transactions"})
+	require.NoError(t, err)
+	assert.Equal(t, LedgerTransactions, TablesReads(tables))
+
+	tables, err = SelectLedgerTables([]string{"trades", "contract_balances"})
+	require.NoError(t, err)
+	assert.Equal(t, AllLedgerParts, TablesReads(tables))
+
+	assert.Equal(t, AllLedgerParts, TablesReads(LedgerTables()))
+}
@@ -10,74 +10,79 @@ import (
 	"github.com/stellar/go/xdr"
 )
 
-// GetLedgers returns a slice of ledger close metas for the ledgers in the provided range (inclusive on both ends)
-func GetLedgers(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
-	ctx := context.Background()
-	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
+// nextLedger reads a ledger from the backend, unless the context is done or the sample of the export leaves it out.
+// It returns false once the export is stopped, so that the export ends at a ledger boundary with the ledgers read so
+// far. The ledgers left out by the sample are not read at all, which sampled is false for: the backends skip ahead to
+// the next ledger requested, and the datastore backend of a sampled export does not decode the files of these ledgers.
+func nextLedger(ctx context.Context, backend ledgerbackend.LedgerBackend, seq uint32, sample utils.LedgerSample) (lcm xdr.LedgerCloseMeta, sampled bool, ok bool, err error) {
+	if ctx.Err() != nil {
+		return xdr.LedgerCloseMeta{}, false, false, nil
+	}
+	if !sample.Includes(seq) {
+		return xdr.LedgerCloseMeta{}, false, true, nil
+	}
+	lcm, err = backend.GetLedger(ctx, seq)
 	if err != nil {
-		return []utils.HistoryArchiveLedgerAndLCM{}, err
+		if ctx.Err() != nil {
+			return xdr.LedgerCloseMeta{}, false, false, nil
+		}
+		return xdr.LedgerCloseMeta{}, false, false, err
 	}
+	return lcm, true, true, nil
+}
 
-	ledgerSlice := []utils.HistoryArchiveLedgerAndLCM{}
-	err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end))
-	panicIf(err)
-	for seq := start; seq <= end; seq++ {
-		lcm, err := backend.GetLedger(ctx, seq)
-		if err != nil {
-			return []utils.HistoryArchiveLedgerAndLCM{}, err
-		}
+// LedgerCloseMetaInputs returns the ledger itself as the only input of a ledger
+func LedgerCloseMetaInputs() LedgerInputs[utils.HistoryArchiveLedgerAndLCM] {
+	return LedgerInputs[utils.HistoryArchiveLedgerAndLCM]{Build: func(ledger DecodedLedger) []utils.HistoryArchiveLedgerAndLCM {
+		return []utils.HistoryArchiveLedgerAndLCM{ledger.Ledger}
+	}}
+}
 
-		var ext xdr.TransactionHistoryEntryExt
-		var transactionResultPair []xdr.TransactionResultPair
+// HistoryArchiveLedgerFromLCM rebuilds the history archive representation of a ledger from its close meta
+func HistoryArchiveLedgerFromLCM(lcm xdr.LedgerCloseMeta) utils.HistoryArchiveLedgerAndLCM {
+	var ext xdr.TransactionHistoryEntryExt
+	var transactionResultPair []xdr.TransactionResultPair
 
-		switch lcm.V {
-		case 0:
-			ext = xdr.TransactionHistoryEntryExt{
-				V:                0,
-				GeneralizedTxSet: nil,
-			}
-			for _, transactionResultMeta := range lcm.V0.TxProcessing {
-				transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
-			}
-		case 1:
-			ext = xdr.TransactionHistoryEntryExt{
-				V:                1,
-				GeneralizedTxSet: &lcm.V1.TxSet,
-			}
-			for _, transactionResultMeta := range lcm.V1.TxProcessing {
-				transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
-			}
+	switch lcm.V {
+	case 0:
+		ext = xdr.TransactionHistoryEntryExt{
+			V:                0,
+			GeneralizedTxSet: nil,
 		}
-
-		ledger := historyarchive.Ledger{
-			Header: lcm.LedgerHeaderHistoryEntry(),
-			Transaction: xdr.TransactionHistoryEntry{
-				LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
-				TxSet: xdr.TransactionSet{
-					PreviousLedgerHash: lcm.LedgerHeaderHistoryEntry().Header.PreviousLedgerHash,
-					Txs:                lcm.TransactionEnvelopes(),
-				},
-				Ext: ext,
-			},
-			TransactionResult: xdr.TransactionHistoryResultEntry{
-				LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
-				TxResultSet: xdr.TransactionResultSet{
-					Results: transactionResultPair,
-				},
-				Ext: xdr.TransactionHistoryResultEntryExt{},
-			},
+		for _, transactionResultMeta := range lcm.V0.TxProcessing {
+			transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
 		}
-
-		ledgerLCM := utils.HistoryArchiveLedgerAndLCM{
-			Ledger: ledger,
-			LCM:    lcm,
+	case 1:
+		ext = xdr.TransactionHistoryEntryExt{
+			V:                1,
+			GeneralizedTxSet: &lcm.V1.TxSet,
 		}
-
-		ledgerSlice = append(ledgerSlice, ledgerLCM)
-		if int64(len(ledgerSlice)) >= limit && limit >= 0 {
-			break
+		for _, transactionResultMeta := range lcm.V1.TxProcessing {
+			transactionResultPair = append(transactionResultPair, transactionResultMeta.Result)
 		}
 	}
 
-	return ledgerSlice, nil
+	ledger := historyarchive.Ledger{
+		Header: lcm.LedgerHeaderHistoryEntry(),
+		Transaction: xdr.TransactionHistoryEntry{
+			LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
+			TxSet: xdr.TransactionSet{
+				PreviousLedgerHash: lcm.LedgerHeaderHistoryEntry().Header.PreviousLedgerHash,
+				Txs:                lcm.TransactionEnvelopes(),
+			},
+			Ext: ext,
+		},
+		TransactionResult: xdr.TransactionHistoryResultEntry{
+			LedgerSeq: lcm.LedgerHeaderHistoryEntry().Header.LedgerSeq,
+			TxResultSet: xdr.TransactionResultSet{
+				Results: transactionResultPair,
+			},
+			Ext: xdr.TransactionHistoryResultEntryExt{},
+		},
+	}
+
+	return utils.HistoryArchiveLedgerAndLCM{
+		Ledger: ledger,
+		LCM:    lcm,
+	}
 }
@@ -6,20 +6,25 @@ import (
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 )
 
-// GetLedgersHistoryArchive returns a slice of ledger close metas for the ledgers in the provided range (inclusive on both ends)
-func GetLedgersHistoryArchive(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, error) {
+// GetLedgersHistoryArchive returns a slice of ledger close metas for the ledgers in the provided range (inclusive on both ends),
+// and the last ledger read. Once the context is done, the ledgers read so far are returned.
+func GetLedgersHistoryArchive(ctx context.Context, start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]utils.HistoryArchiveLedgerAndLCM, uint32, error) {
 	backend, err := utils.CreateBackend(start, end, env.ArchiveURLs)
 	if err != nil {
-		return []utils.HistoryArchiveLedgerAndLCM{}, err
+		return []utils.HistoryArchiveLedgerAndLCM{}, 0, err
 	}
 
 	ledgerSlice := []utils.HistoryArchiveLedgerAndLCM{}
-	ctx := context.Background()
-	for seq := start; seq <= end; seq++ {
+	lastLedger := start - 1
+	for seq := start; seq <= end && ctx.Err() == nil; seq++ {
 		ledger, err := backend.GetLedgerArchive(ctx, seq)
 		if err != nil {
-			return []utils.HistoryArchiveLedgerAndLCM{}, err
+			if ctx.Err() != nil {
+				break
+			}
+			return []utils.HistoryArchiveLedgerAndLCM{}, 0, err
 		}
+		lastLedger = seq
 
 		ledgerLCM := utils.HistoryArchiveLedgerAndLCM{
 			Ledger: ledger,
@@ -31,5 +36,5 @@ func GetLedgersHistoryArchive(start, end uint32, limit int64, env utils.Environm
 		}
 	}
 
-	return ledgerSlice, nil
+	return ledgerSlice, lastLedger, nil
 }
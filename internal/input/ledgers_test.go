@@ -0,0 +1,71 @@
+package input
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestNextLedger(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makeStagesTestLedger(10), nil).Once()
+	// The shutdown signal arrives while ledger 11 is read
+	backend.On("GetLedger", mock.Anything, uint32(11)).Run(func(mock.Arguments) { cancel() }).
+		Return(xdr.LedgerCloseMeta{}, context.Canceled).Once()
+
+	lcm, sampled, ok, err := nextLedger(ctx, backend, 10, utils.LedgerSample{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, sampled)
+	assert.Equal(t, uint32(10), lcm.LedgerSequence())
+
+	_, _, ok, err = nextLedger(ctx, backend, 11, utils.LedgerSample{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// No ledger is read once the context is done
+	_, _, ok, err = nextLedger(ctx, backend, 12, utils.LedgerSample{})
+	require.NoError(t, err)
+	assert.False(t, ok)
+	backend.AssertExpectations(t)
+}
+
+func TestNextLedgerError(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(xdr.LedgerCloseMeta{}, errors.New("file not found"))
+
+	_, _, ok, err := nextLedger(context.Background(), backend, 10, utils.LedgerSample{})
+	assert.EqualError(t, err, "file not found")
+	assert.False(t, ok)
+}
+
+func TestNextLedgerSample(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("GetLedger", mock.Anything, uint32(15)).Return(makeStagesTestLedger(15), nil).Once()
+
+	// The ledgers left out by the sample are not read from the backend
+	sample := utils.LedgerSample{Every: 5}
+	for seq := uint32(11); seq < 15; seq++ {
+		_, sampled, ok, err := nextLedger(context.Background(), backend, seq, sample)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.False(t, sampled)
+	}
+	lcm, sampled, ok, err := nextLedger(context.Background(), backend, 15, sample)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, sampled)
+	assert.Equal(t, uint32(15), lcm.LedgerSequence())
+	backend.AssertExpectations(t)
+}
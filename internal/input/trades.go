@@ -1,16 +1,9 @@
 package input
 
 import (
-	"context"
-	"io"
 	"time"
 
-	"github.com/stellar/stellar-etl/v2/internal/toid"
-	"github.com/stellar/stellar-etl/v2/internal/utils"
-
 	"github.com/stellar/go/ingest"
-	"github.com/stellar/go/ingest/ledgerbackend"
-	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
 )
 
@@ -22,37 +15,11 @@ type TradeTransformInput struct {
 	OperationHistoryID int64
 }
 
-// GetTrades returns a slice of trades for the ledgers in the provided range (inclusive on both ends)
-func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, useCaptiveCore bool) ([]TradeTransformInput, error) {
-	ctx := context.Background()
-
-	backend, err := utils.CreateLedgerBackend(ctx, useCaptiveCore, env)
-	if err != nil {
-		return []TradeTransformInput{}, err
-	}
-
-	tradeSlice := []TradeTransformInput{}
-	err = backend.PrepareRange(ctx, ledgerbackend.BoundedRange(start, end))
-	panicIf(err)
-	for seq := start; seq <= end; seq++ {
-		ledgerCloseMeta, err := backend.GetLedger(ctx, seq)
-		if err != nil {
-			return []TradeTransformInput{}, errors.Wrap(err, "error getting ledger from the backend")
-		}
-
-		txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(env.NetworkPassphrase, ledgerCloseMeta)
-		if err != nil {
-			return []TradeTransformInput{}, err
-		}
-
-		closeTime, _ := utils.TimePointToUTCTimeStamp(txReader.GetHeader().Header.ScpValue.CloseTime)
-
-		for int64(len(tradeSlice)) < limit || limit < 0 {
-			tx, err := txReader.Read()
-			if err == io.EOF {
-				break
-			}
-
+// TradeInputs returns the inputs of the operations of a ledger that can result in trades
+func TradeInputs() LedgerInputs[TradeTransformInput] {
+	return LedgerInputs[TradeTransformInput]{Reads: LedgerTransactions, Build: func(ledger DecodedLedger) []TradeTransformInput {
+		inputs := []TradeTransformInput{}
+		for _, tx := range ledger.Transactions {
 			for index, op := range tx.Envelope.Operations() {
 				/*
 					Trades occur on these operation types:
@@ -61,32 +28,22 @@ func GetTrades(start, end uint32, limit int64, env utils.EnvironmentDetails, use
 
 					Trades also can only occur when these operations are successful
 				*/
-				if operationResultsInTrade(op) && tx.Result.Successful() {
-					tradeSlice = append(tradeSlice, TradeTransformInput{
+				if OperationResultsInTrade(op) && tx.Result.Successful() {
+					inputs = append(inputs, TradeTransformInput{
 						OperationIndex:     int32(index),
 						Transaction:        tx,
-						CloseTime:          closeTime,
-						OperationHistoryID: toid.New(int32(seq), int32(tx.Index), int32(index)).ToInt64(),
+						CloseTime:          ledger.CloseTime,
+						OperationHistoryID: ledger.Toid.New(int32(ledger.Header.Header.LedgerSeq), int32(tx.Index), int32(index)),
 					})
 				}
-
-				if int64(len(tradeSlice)) >= limit && limit >= 0 {
-					break
-				}
 			}
 		}
-
-		txReader.Close()
-		if int64(len(tradeSlice)) >= limit && limit >= 0 {
-			break
-		}
-	}
-
-	return tradeSlice, nil
+		return inputs
+	}}
 }
 
-// operationResultsInTrade returns true if the operation results in a trade
-func operationResultsInTrade(operation xdr.Operation) bool {
+// OperationResultsInTrade returns true if the operation results in a trade
+func OperationResultsInTrade(operation xdr.Operation) bool {
 	switch operation.Body.Type {
 	case xdr.OperationTypeManageBuyOffer:
 		return true
@@ -3,6 +3,7 @@ package toid
 import (
 	"errors"
 	"fmt"
+	"math"
 )
 
 // ID represents the total order of Ledgers, Transactions and
@@ -83,6 +84,36 @@ const (
 	OperationShift = 0
 )
 
+// Generator encodes the ids of an export. Its LedgerOffset is added to the
+// ledger sequence of every id, so that networks that restart from a custom
+// genesis do not reuse the ids of a previous epoch. The zero value encodes the
+// ids unchanged.
+type Generator struct {
+	LedgerOffset uint32
+}
+
+// New returns the int64 id of the operation order op of the transaction order
+// tx of the ledger, with the ledger offset added to the ledger sequence
+func (g Generator) New(ledger int32, tx int32, op int32) int64 {
+	if ledger < 0 {
+		panic("invalid ledger sequence")
+	}
+
+	ledgerSequence := int64(ledger) + int64(g.LedgerOffset)
+	if ledgerSequence > math.MaxInt32 {
+		panic("ledger sequence overflow")
+	}
+
+	return New(int32(ledgerSequence), tx, op).ToInt64()
+}
+
+// Parse parses an id encoded by New, removing the ledger offset
+func (g Generator) Parse(id int64) ID {
+	result := Parse(id)
+	result.LedgerSequence -= int32(g.LedgerOffset)
+	return result
+}
+
 // AfterLedger returns a new toid that represents the ledger time _after_ any
 // contents (e.g. transactions, operations) that occur within the specified
 // ledger.
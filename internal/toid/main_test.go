@@ -123,6 +123,17 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestGeneratorLedgerOffset(t *testing.T) {
+	ids := Generator{LedgerOffset: 100}
+
+	id := ids.New(1, 1, 1)
+	assert.Equal(t, 101*ledger+tx+op, id)
+	assert.Equal(t, ID{1, 1, 1}, ids.Parse(id))
+	assert.Equal(t, ID{101, 1, 1}, Parse(id))
+	assert.Equal(t, New(1, 1, 1).ToInt64(), Generator{}.New(1, 1, 1))
+	assert.Panics(t, func() { ids.New(math.MaxInt32, 0, 0) })
+}
+
 // Test InOperationOrder to make sure it rolls over to the next ledger sequence if overflow occurs.
 func TestID_IncOperationOrder(t *testing.T) {
 	tid := ID{0, 0, 0}
@@ -10,11 +10,15 @@ import (
 )
 
 // TransformAccount converts an account from the history archive ingestion system into a form suitable for BigQuery
-func TransformAccount(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (AccountOutput, error) {
+func TransformAccount(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ AccountOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return AccountOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return AccountOutput{}, err
+	}
 
 	accountEntry, accountFound := ledgerEntry.Data.GetAccount()
 	if !accountFound {
@@ -110,3 +114,23 @@ func TransformAccount(ledgerChange ingest.Change, header xdr.LedgerHeaderHistory
 	}
 	return transformedAccount, nil
 }
+
+// accountChangeEntries returns the account entries before and after a change. before is nil for created accounts and
+// after is nil for removed accounts.
+func accountChangeEntries(ledgerChange ingest.Change) (before, after *xdr.AccountEntry, err error) {
+	if ledgerChange.Pre != nil {
+		entry, ok := ledgerChange.Pre.Data.GetAccount()
+		if !ok {
+			return nil, nil, fmt.Errorf("could not extract account data from ledger entry; actual type is %s", ledgerChange.Pre.Data.Type)
+		}
+		before = &entry
+	}
+	if ledgerChange.Post != nil {
+		entry, ok := ledgerChange.Post.Data.GetAccount()
+		if !ok {
+			return nil, nil, fmt.Errorf("could not extract account data from ledger entry; actual type is %s", ledgerChange.Post.Data.Type)
+		}
+		after = &entry
+	}
+	return before, after, nil
+}
@@ -0,0 +1,76 @@
+package transform
+
+import (
+	"encoding/base64"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformAccountData converts an account data entry from the history archive ingestion system into a form suitable for BigQuery
+func TransformAccountData(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ AccountDataOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
+	if err != nil {
+		return AccountDataOutput{}, err
+	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return AccountDataOutput{}, err
+	}
+
+	dataEntry, dataFound := ledgerEntry.Data.GetData()
+	if !dataFound {
+		return AccountDataOutput{}, fmt.Errorf("could not extract account data from ledger entry; actual type is %s", ledgerEntry.Data.Type)
+	}
+
+	outputAccountID, err := dataEntry.AccountId.GetAddress()
+	if err != nil {
+		return AccountDataOutput{}, err
+	}
+
+	outputDataName := string(dataEntry.DataName)
+	outputDataValue := base64.StdEncoding.EncodeToString(dataEntry.DataValue)
+
+	// Data values are arbitrary bytes; only expose a decoded value when they happen to be valid text
+	var outputDataValueDecoded null.String
+	if utf8.Valid(dataEntry.DataValue) {
+		outputDataValueDecoded = null.StringFrom(string(dataEntry.DataValue))
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return AccountDataOutput{}, err
+	}
+
+	ledgerSequence := header.Header.LedgerSeq
+
+	// The entries do not keep the ledger they were created in, so it is only known on the change that creates them.
+	// The same goes for the ledger they were removed in.
+	var outputCreatedLedger, outputRemovedLedger null.Int
+	switch changeType {
+	case xdr.LedgerEntryChangeTypeLedgerEntryCreated:
+		outputCreatedLedger = null.IntFrom(int64(ledgerSequence))
+	case xdr.LedgerEntryChangeTypeLedgerEntryRemoved:
+		outputRemovedLedger = null.IntFrom(int64(ledgerSequence))
+	}
+
+	transformedData := AccountDataOutput{
+		AccountID:          outputAccountID,
+		DataName:           outputDataName,
+		DataValue:          outputDataValue,
+		DataValueDecoded:   outputDataValueDecoded,
+		Sponsor:            ledgerEntrySponsorToNullString(ledgerEntry),
+		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:  uint32(changeType),
+		Deleted:            outputDeleted,
+		ClosedAt:           closedAt,
+		LedgerSequence:     uint32(ledgerSequence),
+		CreatedLedger:      outputCreatedLedger,
+		RemovedLedger:      outputRemovedLedger,
+	}
+	return transformedData, nil
+}
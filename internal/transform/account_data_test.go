@@ -0,0 +1,134 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformAccountData(t *testing.T) {
+	type transformTest struct {
+		input      ingest.Change
+		wantOutput AccountDataOutput
+		wantErr    error
+	}
+
+	hardCodedInput := makeAccountDataTestInput()
+	hardCodedOutput := makeAccountDataTestOutput()
+	tests := []transformTest{
+		{
+			ingest.Change{
+				Type: xdr.LedgerEntryTypeOffer,
+				Pre:  nil,
+				Post: &xdr.LedgerEntry{
+					Data: xdr.LedgerEntryData{
+						Type: xdr.LedgerEntryTypeOffer,
+					},
+				},
+			},
+			AccountDataOutput{}, fmt.Errorf("could not extract account data from ledger entry; actual type is LedgerEntryTypeOffer"),
+		},
+	}
+
+	for i := range hardCodedInput {
+		tests = append(tests, transformTest{
+			input:      hardCodedInput[i],
+			wantOutput: hardCodedOutput[i],
+			wantErr:    nil,
+		})
+	}
+
+	for _, test := range tests {
+		header := xdr.LedgerHeaderHistoryEntry{
+			Header: xdr.LedgerHeader{
+				ScpValue: xdr.StellarValue{
+					CloseTime: 1000,
+				},
+				LedgerSeq: 10,
+			},
+		}
+		actualOutput, actualError := TransformAccountData(test.input, header)
+		assertTransformError(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeAccountDataTestInput() []ingest.Change {
+	textDataEntry := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 30705278,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{
+				AccountId: testAccount1ID,
+				DataName:  "config.memo_required",
+				DataValue: xdr.DataValue("1"),
+			},
+		},
+		Ext: xdr.LedgerEntryExt{
+			V: 1,
+			V1: &xdr.LedgerEntryExtensionV1{
+				SponsoringId: &testAccount3ID,
+			},
+		},
+	}
+
+	binaryDataEntry := xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 30705279,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeData,
+			Data: &xdr.DataEntry{
+				AccountId: testAccount2ID,
+				DataName:  "key",
+				DataValue: xdr.DataValue([]byte{0xff, 0xfe, 0x00}),
+			},
+		},
+	}
+
+	return []ingest.Change{
+		{
+			Type: xdr.LedgerEntryTypeData,
+			Pre:  nil,
+			Post: &textDataEntry,
+		},
+		{
+			Type: xdr.LedgerEntryTypeData,
+			Pre:  &binaryDataEntry,
+			Post: nil,
+		},
+	}
+}
+
+func makeAccountDataTestOutput() []AccountDataOutput {
+	return []AccountDataOutput{
+		{
+			AccountID:          testAccount1Address,
+			DataName:           "config.memo_required",
+			DataValue:          "MQ==",
+			DataValueDecoded:   null.StringFrom("1"),
+			Sponsor:            null.StringFrom(testAccount3Address),
+			LastModifiedLedger: 30705278,
+			LedgerEntryChange:  0,
+			Deleted:            false,
+			CreatedLedger:      null.IntFrom(10),
+			LedgerSequence:     10,
+			ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+		},
+		{
+			AccountID:          testAccount2Address,
+			DataName:           "key",
+			DataValue:          "//4A",
+			LastModifiedLedger: 30705279,
+			LedgerEntryChange:  2,
+			Deleted:            true,
+			RemovedLedger:      null.IntFrom(10),
+			LedgerSequence:     10,
+			ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformAccountFlagsChange converts an account change into a row of the account auth flags history. The returned
+// bool is false when the change does not toggle any auth flag, including when the account is removed. Created
+// accounts are compared to an account without flags.
+func TransformAccountFlagsChange(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ AccountFlagsOutput, _ bool, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	if err := checkLedgerProtocol(uint32(header.Header.LedgerVersion)); err != nil {
+		return AccountFlagsOutput{}, false, err
+	}
+	before, after, err := accountChangeEntries(ledgerChange)
+	if err != nil || after == nil {
+		return AccountFlagsOutput{}, false, err
+	}
+
+	var oldFlags xdr.AccountFlags
+	if before != nil {
+		oldFlags = xdr.AccountFlags(before.Flags)
+	}
+	newFlags := xdr.AccountFlags(after.Flags)
+	if oldFlags == newFlags {
+		return AccountFlagsOutput{}, false, nil
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return AccountFlagsOutput{}, false, err
+	}
+
+	return AccountFlagsOutput{
+		AccountID:              after.AccountId.Address(),
+		OldFlags:               uint32(oldFlags),
+		NewFlags:               uint32(newFlags),
+		OldAuthRequired:        oldFlags.IsAuthRequired(),
+		NewAuthRequired:        newFlags.IsAuthRequired(),
+		OldAuthRevocable:       oldFlags.IsAuthRevocable(),
+		NewAuthRevocable:       newFlags.IsAuthRevocable(),
+		OldAuthImmutable:       oldFlags.IsAuthImmutable(),
+		NewAuthImmutable:       newFlags.IsAuthImmutable(),
+		OldAuthClawbackEnabled: oldFlags.IsAuthClawbackEnabled(),
+		NewAuthClawbackEnabled: newFlags.IsAuthClawbackEnabled(),
+		ClosedAt:               closedAt,
+		LedgerSequence:         uint32(header.Header.LedgerSeq),
+	}, true, nil
+}
@@ -0,0 +1,91 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformAccountFlagsChange(t *testing.T) {
+	accountEntry := func(flags xdr.AccountFlags) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId: testAccount1ID,
+					Flags:     xdr.Uint32(flags),
+				},
+			},
+		}
+	}
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+
+	tests := []struct {
+		name       string
+		change     ingest.Change
+		wantOutput AccountFlagsOutput
+		wantOk     bool
+	}{
+		{
+			name: "flags toggled",
+			change: ingest.Change{
+				Type: xdr.LedgerEntryTypeAccount,
+				Pre:  accountEntry(xdr.AccountFlagsAuthRequiredFlag | xdr.AccountFlagsAuthRevocableFlag),
+				Post: accountEntry(xdr.AccountFlagsAuthRequiredFlag | xdr.AccountFlagsAuthClawbackEnabledFlag),
+			},
+			wantOutput: AccountFlagsOutput{
+				AccountID:              testAccount1Address,
+				OldFlags:               3,
+				NewFlags:               9,
+				OldAuthRequired:        true,
+				NewAuthRequired:        true,
+				OldAuthRevocable:       true,
+				NewAuthClawbackEnabled: true,
+				ClosedAt:               time.Unix(1000, 0).UTC(),
+				LedgerSequence:         10,
+			},
+			wantOk: true,
+		},
+		{
+			name:   "account created with flags",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Post: accountEntry(xdr.AccountFlagsAuthImmutableFlag)},
+			wantOutput: AccountFlagsOutput{
+				AccountID:        testAccount1Address,
+				NewFlags:         4,
+				NewAuthImmutable: true,
+				ClosedAt:         time.Unix(1000, 0).UTC(),
+				LedgerSequence:   10,
+			},
+			wantOk: true,
+		},
+		{
+			name:   "flags unchanged",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(xdr.AccountFlagsAuthRequiredFlag), Post: accountEntry(xdr.AccountFlagsAuthRequiredFlag)},
+		},
+		{
+			name:   "account created without flags",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Post: accountEntry(0)},
+		},
+		{
+			name:   "account removed",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(xdr.AccountFlagsAuthRequiredFlag)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, ok, err := TransformAccountFlagsChange(test.change, header)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.wantOutput, output)
+		})
+	}
+}
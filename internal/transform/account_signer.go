@@ -11,13 +11,17 @@ import (
 )
 
 // TransformSigners converts account signers from the history archive ingestion system into a form suitable for BigQuery
-func TransformSigners(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) ([]AccountSignerOutput, error) {
+func TransformSigners(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ []AccountSignerOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	var signers []AccountSignerOutput
 
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return signers, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return signers, err
+	}
 	outputLastModifiedLedger := uint32(ledgerEntry.LastModifiedLedgerSeq)
 	accountEntry, accountFound := ledgerEntry.Data.GetAccount()
 	if !accountFound {
@@ -50,6 +54,37 @@ func TransformSigners(ledgerChange ingest.Change, header xdr.LedgerHeaderHistory
 			LedgerSequence:     uint32(ledgerSequence),
 		})
 	}
+
+	// The signer summary leaves out a master key of weight 0, so a change that sets it to 0 writes a row of weight 0
+	// for the account's own key, rather than no row that would leave its previous weight as the latest
+	if masterKeyWeightCleared(ledgerChange) {
+		signers = append(signers, AccountSignerOutput{
+			AccountID:          accountEntry.AccountId.Address(),
+			Signer:             accountEntry.AccountId.Address(),
+			Weight:             0,
+			LastModifiedLedger: outputLastModifiedLedger,
+			LedgerEntryChange:  uint32(changeType),
+			Deleted:            outputDeleted,
+			ClosedAt:           closedAt,
+			LedgerSequence:     uint32(ledgerSequence),
+		})
+	}
 	sort.Slice(signers, func(a, b int) bool { return signers[a].Weight < signers[b].Weight })
 	return signers, nil
 }
+
+// masterKeyWeightCleared returns whether an account change sets the weight of the master key of the account to 0
+func masterKeyWeightCleared(ledgerChange ingest.Change) bool {
+	if ledgerChange.Pre == nil || ledgerChange.Post == nil {
+		return false
+	}
+	before, ok := ledgerChange.Pre.Data.GetAccount()
+	if !ok {
+		return false
+	}
+	after, ok := ledgerChange.Post.Data.GetAccount()
+	if !ok {
+		return false
+	}
+	return before.MasterKeyWeight() > 0 && after.MasterKeyWeight() == 0
+}
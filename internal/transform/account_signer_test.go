@@ -60,7 +60,7 @@ func TestTransformAccountSigner(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformSigners(test.input.ingest, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -163,3 +163,42 @@ func makeSignersTestOutput() []AccountSignerOutput {
 		},
 	}
 }
+
+func TestTransformAccountSignerMasterKeyWeightCleared(t *testing.T) {
+	accountEntry := func(masterKeyWeight byte) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 10,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:  testAccount1ID,
+					Thresholds: xdr.Thresholds{masterKeyWeight, 0, 0, 0},
+				},
+			},
+		}
+	}
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+
+	change := ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(1), Post: accountEntry(0)}
+	signers, err := TransformSigners(change, header)
+	assert.NoError(t, err)
+	assert.Equal(t, []AccountSignerOutput{{
+		AccountID:          testAccount1Address,
+		Signer:             testAccount1Address,
+		Weight:             0,
+		LastModifiedLedger: 10,
+		LedgerEntryChange:  uint32(xdr.LedgerEntryChangeTypeLedgerEntryUpdated),
+		ClosedAt:           time.Unix(1000, 0).UTC(),
+		LedgerSequence:     10,
+	}}, signers)
+
+	change = ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(0), Post: accountEntry(0)}
+	signers, err = TransformSigners(change, header)
+	assert.NoError(t, err)
+	assert.Empty(t, signers)
+}
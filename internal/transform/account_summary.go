@@ -0,0 +1,141 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// AccountSummary folds the changes and payments of a range of transactions into a lifetime summary per account.
+// Transactions are added in ledger order, so that the flags of an account are the ones of its latest change. A summary
+// can be continued from the outputs of an earlier range with Load.
+type AccountSummary struct {
+	accounts map[string]*AccountSummaryOutput
+}
+
+// NewAccountSummary returns an empty AccountSummary
+func NewAccountSummary() *AccountSummary {
+	return &AccountSummary{accounts: map[string]*AccountSummaryOutput{}}
+}
+
+// Load seeds the summary with the outputs of an earlier range, so that the transactions added next extend them
+func (s *AccountSummary) Load(outputs []AccountSummaryOutput) {
+	for _, output := range outputs {
+		account := output
+		s.accounts[account.AccountID] = &account
+	}
+}
+
+// AddTransaction adds the account entry changes of a transaction, along with the creations and payments of its
+// operations if it was successful
+func (s *AccountSummary) AddTransaction(transaction ingest.LedgerTransaction, ledgerSeq uint32) error {
+	changes, err := transaction.GetChanges()
+	if err != nil {
+		return err
+	}
+	for _, change := range changes {
+		if change.Type == xdr.LedgerEntryTypeAccount {
+			s.addChange(change, ledgerSeq)
+		}
+	}
+
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	for i, op := range transaction.Envelope.Operations() {
+		source := transaction.Envelope.SourceAccount()
+		if op.SourceAccount != nil {
+			source = *op.SourceAccount
+		}
+		sourceAccountID := source.ToAccountId()
+		sourceAddress, err := sourceAccountID.GetAddress()
+		if err != nil {
+			return fmt.Errorf("could not get source account of operation %d: %v", i, err)
+		}
+
+		var destination xdr.MuxedAccount
+		switch op.Body.Type {
+		case xdr.OperationTypeCreateAccount:
+			created := op.Body.MustCreateAccountOp().Destination.Address()
+			s.add(created, ledgerSeq).Funder = null.StringFrom(sourceAddress)
+			continue
+		case xdr.OperationTypePayment:
+			destination = op.Body.MustPaymentOp().Destination
+		case xdr.OperationTypePathPaymentStrictReceive:
+			destination = op.Body.MustPathPaymentStrictReceiveOp().Destination
+		case xdr.OperationTypePathPaymentStrictSend:
+			destination = op.Body.MustPathPaymentStrictSendOp().Destination
+		default:
+			continue
+		}
+
+		destinationAccountID := destination.ToAccountId()
+		destinationAddress, err := destinationAccountID.GetAddress()
+		if err != nil {
+			return fmt.Errorf("could not get destination of operation %d: %v", i, err)
+		}
+		s.add(sourceAddress, ledgerSeq).PaymentsSent++
+		s.add(destinationAddress, ledgerSeq).PaymentsReceived++
+	}
+
+	return nil
+}
+
+// Outputs returns the summaries of the accounts ordered by the ledger they were first seen in
+func (s *AccountSummary) Outputs() []AccountSummaryOutput {
+	outputs := make([]AccountSummaryOutput, 0, len(s.accounts))
+	for _, account := range s.accounts {
+		outputs = append(outputs, *account)
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].FirstSeenLedger != outputs[j].FirstSeenLedger {
+			return outputs[i].FirstSeenLedger < outputs[j].FirstSeenLedger
+		}
+		return outputs[i].AccountID < outputs[j].AccountID
+	})
+	return outputs
+}
+
+// addChange records the flags of the account after the change, or that it was merged if the change removed it
+func (s *AccountSummary) addChange(change ingest.Change, ledgerSeq uint32) {
+	entry := change.Post
+	if entry == nil {
+		entry = change.Pre
+	}
+	account := s.add(entry.Data.MustAccount().AccountId.Address(), ledgerSeq)
+	if ledgerSeq < account.LastSeenLedger {
+		return
+	}
+
+	if change.Post == nil {
+		account.Deleted = true
+		return
+	}
+	account.Flags = uint32(change.Post.Data.MustAccount().Flags)
+	account.Deleted = false
+}
+
+func (s *AccountSummary) add(accountID string, ledgerSeq uint32) *AccountSummaryOutput {
+	account, ok := s.accounts[accountID]
+	if !ok {
+		account = &AccountSummaryOutput{
+			AccountID:       accountID,
+			FirstSeenLedger: ledgerSeq,
+			LastSeenLedger:  ledgerSeq,
+		}
+		s.accounts[accountID] = account
+		return account
+	}
+
+	if ledgerSeq < account.FirstSeenLedger {
+		account.FirstSeenLedger = ledgerSeq
+	}
+	if ledgerSeq > account.LastSeenLedger {
+		account.LastSeenLedger = ledgerSeq
+	}
+	return account
+}
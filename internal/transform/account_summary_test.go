@@ -0,0 +1,119 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeAccountSummaryEntry(accountID xdr.AccountId, flags uint32) xdr.LedgerEntry {
+	return xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeAccount,
+			Account: &xdr.AccountEntry{
+				AccountId: accountID,
+				Flags:     xdr.Uint32(flags),
+			},
+		},
+	}
+}
+
+func makeAccountSummaryTestInput() ingest.LedgerTransaction {
+	transaction := genericLedgerTransaction
+	envelope := genericBumpOperationEnvelope
+	envelope.Tx.SourceAccount = testAccount1
+	envelope.Tx.Operations = []xdr.Operation{
+		{
+			Body: xdr.OperationBody{
+				Type: xdr.OperationTypeCreateAccount,
+				CreateAccountOp: &xdr.CreateAccountOp{
+					Destination:     testAccount2ID,
+					StartingBalance: 10000000,
+				},
+			},
+		},
+		{
+			Body: xdr.OperationBody{
+				Type: xdr.OperationTypePayment,
+				PaymentOp: &xdr.PaymentOp{
+					Destination: testAccount5,
+					Asset:       nativeAsset,
+					Amount:      350000000,
+				},
+			},
+		},
+	}
+	transaction.Envelope.V1 = &envelope
+
+	created := makeAccountSummaryEntry(testAccount2ID, 0)
+	before := makeAccountSummaryEntry(testAccount1ID, 0)
+	after := makeAccountSummaryEntry(testAccount1ID, 1)
+	transaction.UnsafeMeta = xdr.TransactionMeta{
+		V: 1,
+		V1: &xdr.TransactionMetaV1{
+			Operations: []xdr.OperationMeta{
+				{Changes: xdr.LedgerEntryChanges{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &created},
+				}},
+				{Changes: xdr.LedgerEntryChanges{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &before},
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &after},
+				}},
+			},
+		},
+	}
+	return transaction
+}
+
+func TestAccountSummaryAddTransaction(t *testing.T) {
+	summary := NewAccountSummary()
+	summary.Load([]AccountSummaryOutput{
+		{AccountID: testAccount1Address, FirstSeenLedger: 5, LastSeenLedger: 6, PaymentsSent: 3, PaymentsReceived: 2},
+	})
+	require.NoError(t, summary.AddTransaction(makeAccountSummaryTestInput(), 10))
+
+	assert.Equal(t, []AccountSummaryOutput{
+		{
+			AccountID:        testAccount1Address,
+			FirstSeenLedger:  5,
+			LastSeenLedger:   10,
+			PaymentsSent:     4,
+			PaymentsReceived: 2,
+			Flags:            1,
+		},
+		{
+			AccountID:        testAccount5Address,
+			FirstSeenLedger:  10,
+			LastSeenLedger:   10,
+			PaymentsReceived: 1,
+		},
+		{
+			AccountID:       testAccount2Address,
+			FirstSeenLedger: 10,
+			LastSeenLedger:  10,
+			Funder:          null.StringFrom(testAccount1Address),
+		},
+	}, summary.Outputs())
+}
+
+func TestAccountSummaryLatestChange(t *testing.T) {
+	summary := NewAccountSummary()
+	updated := makeAccountSummaryEntry(testAccount1ID, 2)
+	stale := makeAccountSummaryEntry(testAccount1ID, 4)
+
+	summary.addChange(ingest.Change{Type: xdr.LedgerEntryTypeAccount, Post: &updated}, 10)
+	summary.addChange(ingest.Change{Type: xdr.LedgerEntryTypeAccount, Post: &stale}, 8)
+	outputs := summary.Outputs()
+	require.Len(t, outputs, 1)
+	assert.Equal(t, AccountSummaryOutput{AccountID: testAccount1Address, FirstSeenLedger: 8, LastSeenLedger: 10, Flags: 2}, outputs[0])
+
+	summary.addChange(ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: &updated}, 12)
+	outputs = summary.Outputs()
+	assert.True(t, outputs[0].Deleted)
+	assert.Equal(t, uint32(12), outputs[0].LastSeenLedger)
+	assert.Equal(t, uint32(2), outputs[0].Flags)
+}
@@ -104,7 +104,7 @@ func TestTransformAccount(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformAccount(test.input.ledgerChange, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -0,0 +1,233 @@
+package transform
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/ledgerkey"
+)
+
+const (
+	ArchivalEventCreated     = "created"
+	ArchivalEventTtlExtended = "ttl_extended"
+	ArchivalEventRestored    = "restored"
+	ArchivalEventRemoved     = "removed"
+	ArchivalEventEvicted     = "evicted"
+)
+
+// TransformArchivalHistory combines the ttl changes made by the transactions of a ledger and the entries evicted
+// at the close of that ledger into a single history of the lifetime of soroban ledger entries.
+func TransformArchivalHistory(ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string, ids toid.Generator) (_ []ArchivalHistoryOutput, err error) {
+	ledgerSequence := ledgerCloseMeta.LedgerSequence()
+	defer wrapError(&err, ledgerCoordinates(ErrorCodeInvalidLedgerEntry, ledgerSequence, networkPassphrase))
+	if err := checkLedgerProtocol(ledgerCloseMeta.ProtocolVersion()); err != nil {
+		return []ArchivalHistoryOutput{}, err
+	}
+	closedAt, err := utils.GetCloseTime(ledgerCloseMeta)
+	if err != nil {
+		return []ArchivalHistoryOutput{}, err
+	}
+
+	txReader, err := ingest.NewLedgerTransactionReaderFromLedgerCloseMeta(networkPassphrase, ledgerCloseMeta)
+	if err != nil {
+		return []ArchivalHistoryOutput{}, fmt.Errorf("could not create transaction reader for ledger %d: %v", ledgerSequence, err)
+	}
+	defer txReader.Close()
+
+	transformedHistory := []ArchivalHistoryOutput{}
+	for {
+		transaction, err := txReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return []ArchivalHistoryOutput{}, fmt.Errorf("could not read transaction in ledger %d: %v", ledgerSequence, err)
+		}
+
+		if !transaction.Successful() || !transaction.IsSorobanTx() {
+			continue
+		}
+
+		history, err := transformTransactionTtlChanges(transaction, ledgerSequence, ids)
+		if err != nil {
+			return []ArchivalHistoryOutput{}, fmt.Errorf("for ledger %d; transaction %d: %v", ledgerSequence, transaction.Index, err)
+		}
+		transformedHistory = append(transformedHistory, history...)
+	}
+
+	evictions, err := transformEvictions(ledgerCloseMeta)
+	if err != nil {
+		return []ArchivalHistoryOutput{}, fmt.Errorf("for ledger %d: %v", ledgerSequence, err)
+	}
+	transformedHistory = append(transformedHistory, evictions...)
+
+	for i := range transformedHistory {
+		transformedHistory[i].LedgerSequence = ledgerSequence
+		transformedHistory[i].ClosedAt = closedAt
+	}
+
+	return transformedHistory, nil
+}
+
+func transformTransactionTtlChanges(transaction ingest.LedgerTransaction, ledgerSequence uint32, ids toid.Generator) ([]ArchivalHistoryOutput, error) {
+	// ttl entries only carry the hash of the key they belong to, so the footprint is used to find the actual entry
+	footprintKeys := map[string]xdr.LedgerKey{}
+	sorobanData, ok := transaction.GetSorobanData()
+	if ok {
+		footprint := sorobanData.Resources.Footprint
+		for _, keys := range [][]xdr.LedgerKey{footprint.ReadOnly, footprint.ReadWrite} {
+			for _, key := range keys {
+				footprintKeys[utils.LedgerKeyToLedgerKeyHash(key)] = key
+			}
+		}
+	}
+
+	outputTransactionHash := null.StringFrom(utils.HashToHexString(transaction.Result.TransactionHash))
+
+	history := []ArchivalHistoryOutput{}
+	for opi, op := range transaction.Envelope.Operations() {
+		changes, err := transaction.GetOperationChanges(uint32(opi))
+		if err != nil {
+			return []ArchivalHistoryOutput{}, err
+		}
+
+		outputOperationID := null.IntFrom(ids.New(int32(ledgerSequence), int32(transaction.Index), int32(opi+1)))
+
+		for _, change := range changes {
+			if change.Type != xdr.LedgerEntryTypeTtl {
+				continue
+			}
+
+			var eventType string
+			var keyHash string
+			var liveUntil, previousLiveUntil null.Int
+			switch {
+			case change.Pre == nil:
+				// Up to protocol 22, archived entries stay in the live state and restoring one updates its ttl. From
+				// protocol 23, they are moved to the hot archive, so restoring one creates its ttl again. The entries
+				// that InvokeHostFunction restores on its own from protocol 23 are only told apart by the restored
+				// changes of TransactionMeta V4, which checkLedgerProtocol refuses for now.
+				eventType = ArchivalEventCreated
+				if op.Body.Type == xdr.OperationTypeRestoreFootprint {
+					eventType = ArchivalEventRestored
+				}
+				ttl := change.Post.Data.MustTtl()
+				keyHash = ttl.KeyHash.HexString()
+				liveUntil = null.IntFrom(int64(ttl.LiveUntilLedgerSeq))
+			case change.Post == nil:
+				eventType = ArchivalEventRemoved
+				ttl := change.Pre.Data.MustTtl()
+				keyHash = ttl.KeyHash.HexString()
+				previousLiveUntil = null.IntFrom(int64(ttl.LiveUntilLedgerSeq))
+			default:
+				eventType = ArchivalEventTtlExtended
+				if op.Body.Type == xdr.OperationTypeRestoreFootprint {
+					eventType = ArchivalEventRestored
+				}
+				ttl := change.Post.Data.MustTtl()
+				keyHash = ttl.KeyHash.HexString()
+				liveUntil = null.IntFrom(int64(ttl.LiveUntilLedgerSeq))
+				previousLiveUntil = null.IntFrom(int64(change.Pre.Data.MustTtl().LiveUntilLedgerSeq))
+			}
+
+			// Unchanged ttls are not part of the archival history
+			if eventType == ArchivalEventTtlExtended && liveUntil == previousLiveUntil {
+				continue
+			}
+
+			output := ArchivalHistoryOutput{
+				KeyHash:                    keyHash,
+				EventType:                  eventType,
+				LiveUntilLedgerSeq:         liveUntil,
+				PreviousLiveUntilLedgerSeq: previousLiveUntil,
+				TransactionHash:            outputTransactionHash,
+				OperationID:                outputOperationID,
+			}
+			if key, ok := footprintKeys[keyHash]; ok {
+				if err := addArchivalKeyDetails(&output, key); err != nil {
+					return []ArchivalHistoryOutput{}, err
+				}
+			}
+
+			history = append(history, output)
+		}
+	}
+
+	return history, nil
+}
+
+func transformEvictions(ledgerCloseMeta xdr.LedgerCloseMeta) ([]ArchivalHistoryOutput, error) {
+	evictedKeys, err := ledgerCloseMeta.EvictedTemporaryLedgerKeys()
+	if err != nil {
+		return []ArchivalHistoryOutput{}, err
+	}
+
+	evictedEntries, err := ledgerCloseMeta.EvictedPersistentLedgerEntries()
+	if err != nil {
+		return []ArchivalHistoryOutput{}, err
+	}
+	for _, entry := range evictedEntries {
+		key, err := entry.LedgerKey()
+		if err != nil {
+			return []ArchivalHistoryOutput{}, err
+		}
+		evictedKeys = append(evictedKeys, key)
+	}
+
+	evictions := []ArchivalHistoryOutput{}
+	for _, key := range evictedKeys {
+		// The ttl entries are evicted alongside the entries they belong to
+		if key.Type == xdr.LedgerEntryTypeTtl {
+			continue
+		}
+
+		output := ArchivalHistoryOutput{
+			KeyHash:   utils.LedgerKeyToLedgerKeyHash(key),
+			EventType: ArchivalEventEvicted,
+		}
+		if err := addArchivalKeyDetails(&output, key); err != nil {
+			return []ArchivalHistoryOutput{}, err
+		}
+
+		evictions = append(evictions, output)
+	}
+
+	return evictions, nil
+}
+
+func addArchivalKeyDetails(output *ArchivalHistoryOutput, key xdr.LedgerKey) error {
+	output.LedgerKeyType = key.Type.String()
+
+	var err error
+	if output.LedgerKey, err = ledgerkey.Base64(key); err != nil {
+		return err
+	}
+	if output.LedgerKeyCanonical, err = ledgerkey.Canonical(key); err != nil {
+		return err
+	}
+
+	switch key.Type {
+	case xdr.LedgerEntryTypeContractData:
+		contractData := key.MustContractData()
+		if contractId, ok := contractData.Contract.GetContractId(); ok {
+			contractIdByte, _ := contractId.MarshalBinary()
+			outputContractId, err := strkey.Encode(strkey.VersionByteContract, contractIdByte)
+			if err != nil {
+				return err
+			}
+			output.ContractId = outputContractId
+		}
+		output.ContractDurability = contractData.Durability.String()
+	case xdr.LedgerEntryTypeContractCode:
+		output.ContractCodeHash = key.MustContractCode().Hash.HexString()
+		output.ContractDurability = xdr.ContractDataDurabilityPersistent.String()
+	}
+
+	return nil
+}
@@ -0,0 +1,292 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/hash"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+func TestTransformArchivalHistory(t *testing.T) {
+	contractDataKey, contractCodeKey := makeArchivalHistoryTestKeys()
+
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:  xdr.StellarValue{CloseTime: 1000},
+					LedgerSeq: 10,
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+			EvictedTemporaryLedgerKeys: []xdr.LedgerKey{
+				contractDataKey,
+				{
+					Type: xdr.LedgerEntryTypeTtl,
+					Ttl:  &xdr.LedgerKeyTtl{KeyHash: xdr.Hash{}},
+				},
+			},
+			EvictedPersistentLedgerEntries: []xdr.LedgerEntry{
+				{
+					Data: xdr.LedgerEntryData{
+						Type: xdr.LedgerEntryTypeContractCode,
+						ContractCode: &xdr.ContractCodeEntry{
+							Hash: contractCodeKey.ContractCode.Hash,
+							Code: []byte{0x00, 0x61, 0x73, 0x6d},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	closedAt := time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC)
+	expected := []ArchivalHistoryOutput{
+		{
+			KeyHash:            utils.LedgerKeyToLedgerKeyHash(contractDataKey),
+			EventType:          ArchivalEventEvicted,
+			LedgerKeyType:      "LedgerEntryTypeContractData",
+			LedgerKey:          "AAAABgAAAAESNFZ1AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABQAAAAA",
+			LedgerKeyCanonical: "contract_data:CAJDIVTVAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADML:temporary:AAAAFA==",
+			ContractId:         "CAJDIVTVAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADML",
+			ContractDurability: "ContractDataDurabilityTemporary",
+			LedgerSequence:     10,
+			ClosedAt:           closedAt,
+		},
+		{
+			KeyHash:            utils.LedgerKeyToLedgerKeyHash(contractCodeKey),
+			EventType:          ArchivalEventEvicted,
+			LedgerKeyType:      "LedgerEntryTypeContractCode",
+			LedgerKey:          "AAAABwEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEB",
+			LedgerKeyCanonical: "contract_code:0101010101010101010101010101010101010101010101010101010101010101",
+			ContractCodeHash:   "0101010101010101010101010101010101010101010101010101010101010101",
+			ContractDurability: "ContractDataDurabilityPersistent",
+			LedgerSequence:     10,
+			ClosedAt:           closedAt,
+		},
+	}
+
+	actualOutput, actualError := TransformArchivalHistory(lcm, "", toid.Generator{})
+	assert.NoError(t, actualError)
+	assert.Equal(t, expected, actualOutput)
+}
+
+func TestTransformTransactionTtlChanges(t *testing.T) {
+	contractDataKey, contractCodeKey := makeArchivalHistoryTestKeys()
+
+	ttlEntry := func(key xdr.LedgerKey, liveUntil xdr.Uint32) *xdr.LedgerEntry {
+		keyBytes, _ := key.MarshalBinary()
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTtl,
+				Ttl: &xdr.TtlEntry{
+					KeyHash:            xdr.Hash(hash.Hash(keyBytes)),
+					LiveUntilLedgerSeq: liveUntil,
+				},
+			},
+		}
+	}
+
+	restoreOp := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type:               xdr.OperationTypeRestoreFootprint,
+			RestoreFootprintOp: &xdr.RestoreFootprintOp{},
+		},
+	}
+
+	transaction := ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations:    []xdr.Operation{restoreOp},
+					Ext: xdr.TransactionExt{
+						V: 1,
+						SorobanData: &xdr.SorobanTransactionData{
+							Resources: xdr.SorobanResources{
+								Footprint: xdr.LedgerFootprint{
+									ReadOnly:  []xdr.LedgerKey{contractCodeKey},
+									ReadWrite: []xdr.LedgerKey{contractDataKey},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Result: wrapOperationsResultsSlice([]xdr.OperationResult{}, true),
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 3,
+			V3: &xdr.TransactionMetaV3{
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{
+								Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+								State: ttlEntry(contractDataKey, 100),
+							},
+							{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+								Updated: ttlEntry(contractDataKey, 500),
+							},
+							{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+								Created: ttlEntry(contractCodeKey, 600),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	expected := []ArchivalHistoryOutput{
+		{
+			KeyHash:                    utils.LedgerKeyToLedgerKeyHash(contractDataKey),
+			EventType:                  ArchivalEventRestored,
+			LedgerKeyType:              "LedgerEntryTypeContractData",
+			LedgerKey:                  "AAAABgAAAAESNFZ1AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABQAAAAA",
+			LedgerKeyCanonical:         "contract_data:CAJDIVTVAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADML:temporary:AAAAFA==",
+			ContractId:                 "CAJDIVTVAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAADML",
+			ContractDurability:         "ContractDataDurabilityTemporary",
+			LiveUntilLedgerSeq:         null.IntFrom(500),
+			PreviousLiveUntilLedgerSeq: null.IntFrom(100),
+			TransactionHash:            null.StringFrom("0000000000000000000000000000000000000000000000000000000000000000"),
+			OperationID:                null.IntFrom(42949677057),
+		},
+		{
+			KeyHash:            utils.LedgerKeyToLedgerKeyHash(contractCodeKey),
+			EventType:          ArchivalEventRestored,
+			LedgerKeyType:      "LedgerEntryTypeContractCode",
+			LedgerKey:          "AAAABwEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEB",
+			LedgerKeyCanonical: "contract_code:0101010101010101010101010101010101010101010101010101010101010101",
+			ContractCodeHash:   "0101010101010101010101010101010101010101010101010101010101010101",
+			ContractDurability: "ContractDataDurabilityPersistent",
+			LiveUntilLedgerSeq: null.IntFrom(600),
+			TransactionHash:    null.StringFrom("0000000000000000000000000000000000000000000000000000000000000000"),
+			OperationID:        null.IntFrom(42949677057),
+		},
+	}
+
+	actualOutput, actualError := transformTransactionTtlChanges(transaction, 10, toid.Generator{})
+	assert.NoError(t, actualError)
+	assert.Equal(t, expected, actualOutput)
+}
+
+func TestTransformTransactionTtlChangesProtocol23Restore(t *testing.T) {
+	_, contractCodeKey := makeArchivalHistoryTestKeys()
+	keyBytes, _ := contractCodeKey.MarshalBinary()
+	createdTtl := xdr.LedgerEntryChange{
+		Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+		Created: &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeTtl,
+				Ttl:  &xdr.TtlEntry{KeyHash: xdr.Hash(hash.Hash(keyBytes)), LiveUntilLedgerSeq: 600},
+			},
+		},
+	}
+
+	// From protocol 23, restoring an entry from the hot archive creates its ttl again, while a new entry of a
+	// contract invocation creates its ttl for the first time
+	for _, test := range []struct {
+		op        xdr.OperationBody
+		eventType string
+	}{
+		{
+			op:        xdr.OperationBody{Type: xdr.OperationTypeRestoreFootprint, RestoreFootprintOp: &xdr.RestoreFootprintOp{}},
+			eventType: ArchivalEventRestored,
+		},
+		{
+			op:        xdr.OperationBody{Type: xdr.OperationTypeInvokeHostFunction, InvokeHostFunctionOp: &xdr.InvokeHostFunctionOp{}},
+			eventType: ArchivalEventCreated,
+		},
+	} {
+		t.Run(test.op.Type.String(), func(t *testing.T) {
+			transaction := ingest.LedgerTransaction{
+				Index: 1,
+				Envelope: xdr.TransactionEnvelope{
+					Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+					V1: &xdr.TransactionV1Envelope{
+						Tx: xdr.Transaction{
+							SourceAccount: testAccount1,
+							Operations:    []xdr.Operation{{Body: test.op}},
+							Ext: xdr.TransactionExt{
+								V: 1,
+								SorobanData: &xdr.SorobanTransactionData{
+									Resources: xdr.SorobanResources{
+										Footprint: xdr.LedgerFootprint{ReadWrite: []xdr.LedgerKey{contractCodeKey}},
+									},
+								},
+							},
+						},
+					},
+				},
+				Result: wrapOperationsResultsSlice([]xdr.OperationResult{}, true),
+				UnsafeMeta: xdr.TransactionMeta{
+					V: 3,
+					V3: &xdr.TransactionMetaV3{
+						Operations: []xdr.OperationMeta{{Changes: xdr.LedgerEntryChanges{createdTtl}}},
+					},
+				},
+			}
+
+			actualOutput, actualError := transformTransactionTtlChanges(transaction, 10, toid.Generator{})
+			assert.NoError(t, actualError)
+			assert.Equal(t, []ArchivalHistoryOutput{
+				{
+					KeyHash:            utils.LedgerKeyToLedgerKeyHash(contractCodeKey),
+					EventType:          test.eventType,
+					LedgerKeyType:      "LedgerEntryTypeContractCode",
+					LedgerKey:          "AAAABwEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEBAQEB",
+					LedgerKeyCanonical: "contract_code:0101010101010101010101010101010101010101010101010101010101010101",
+					ContractCodeHash:   "0101010101010101010101010101010101010101010101010101010101010101",
+					ContractDurability: "ContractDataDurabilityPersistent",
+					LiveUntilLedgerSeq: null.IntFrom(600),
+					TransactionHash:    null.StringFrom("0000000000000000000000000000000000000000000000000000000000000000"),
+					OperationID:        null.IntFrom(42949677057),
+				},
+			}, actualOutput)
+		})
+	}
+}
+
+func makeArchivalHistoryTestKeys() (contractDataKey xdr.LedgerKey, contractCodeKey xdr.LedgerKey) {
+	contractID := xdr.Hash([32]byte{0x12, 0x34, 0x56, 0x75})
+	contractDataKey = xdr.LedgerKey{
+		Type: xdr.LedgerEntryTypeContractData,
+		ContractData: &xdr.LedgerKeyContractData{
+			Contract: xdr.ScAddress{
+				Type:       xdr.ScAddressTypeScAddressTypeContract,
+				ContractId: &contractID,
+			},
+			Key: xdr.ScVal{
+				Type: xdr.ScValTypeScvLedgerKeyContractInstance,
+			},
+			Durability: xdr.ContractDataDurabilityTemporary,
+		},
+	}
+
+	var codeHash xdr.Hash
+	for i := range codeHash {
+		codeHash[i] = 0x01
+	}
+	contractCodeKey = xdr.LedgerKey{
+		Type:         xdr.LedgerEntryTypeContractCode,
+		ContractCode: &xdr.LedgerKeyContractCode{Hash: codeHash},
+	}
+
+	return contractDataKey, contractCodeKey
+}
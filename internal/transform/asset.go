@@ -11,8 +11,12 @@ import (
 )
 
 // TransformAsset converts an asset from a payment operation into a form suitable for BigQuery
-func TransformAsset(operation xdr.Operation, operationIndex int32, transactionIndex int32, ledgerSeq int32, lcm xdr.LedgerCloseMeta) (AssetOutput, error) {
-	operationID := toid.New(ledgerSeq, int32(transactionIndex), operationIndex).ToInt64()
+func TransformAsset(operation xdr.Operation, operationIndex int32, transactionIndex int32, ledgerSeq int32, lcm xdr.LedgerCloseMeta, network string, ids toid.Generator) (_ AssetOutput, err error) {
+	coordinates := ledgerCoordinates(ErrorCodeInvalidOperation, uint32(ledgerSeq), network)
+	coordinates.OperationIndex = operationIndex
+	coordinates.OperationType, _ = mapOperationType(operation)
+	defer wrapError(&err, coordinates)
+	operationID := ids.New(ledgerSeq, int32(transactionIndex), operationIndex)
 
 	opType := operation.Body.Type
 	if opType != xdr.OperationTypePayment && opType != xdr.OperationTypeManageSellOffer {
@@ -42,6 +46,11 @@ func TransformAsset(operation xdr.Operation, operationIndex int32, transactionIn
 		return AssetOutput{}, fmt.Errorf("%s (id %d)", err.Error(), operationID)
 	}
 
+	outputAsset.ContractID, err = utils.AssetContractID(asset, network)
+	if err != nil {
+		return AssetOutput{}, fmt.Errorf("could not get contract id of asset (id %d): %v", operationID, err)
+	}
+
 	outputCloseTime, err := utils.GetCloseTime(lcm)
 	if err != nil {
 		return AssetOutput{}, err
@@ -0,0 +1,201 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/contractevents"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// AssetDimension collects the distinct assets seen in the operations, trustlines, effects and Stellar Asset Contract
+// events of a range of transactions
+type AssetDimension struct {
+	passphrase string
+	assets     map[int64]*AssetDimensionOutput
+}
+
+// NewAssetDimension returns an empty AssetDimension for the network with the given passphrase
+func NewAssetDimension(passphrase string) *AssetDimension {
+	return &AssetDimension{
+		passphrase: passphrase,
+		assets:     map[int64]*AssetDimensionOutput{},
+	}
+}
+
+// AddTransaction adds the assets seen in a transaction. Transactions may be added in any order, the first seen
+// ledger of an asset is the lowest ledger it was seen in.
+func (d *AssetDimension) AddTransaction(transaction ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) error {
+	ledgerSeq := utils.GetLedgerSequence(lcm)
+
+	for i, op := range transaction.Envelope.Operations() {
+		operation, err := TransformOperation(op, int32(i), transaction, int32(ledgerSeq), lcm, d.passphrase, toid.Generator{})
+		if err != nil {
+			return err
+		}
+		d.addDetails(operation.OperationDetails, ledgerSeq)
+	}
+
+	effects, err := TransformEffect(transaction, ledgerSeq, lcm, d.passphrase, toid.Generator{})
+	if err != nil {
+		return err
+	}
+	for _, effect := range effects {
+		d.addDetails(effect.Details, ledgerSeq)
+	}
+
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	for i := range transaction.Envelope.Operations() {
+		changes, err := transaction.GetOperationChanges(uint32(i))
+		if err != nil {
+			return err
+		}
+		for _, change := range changes {
+			if err := d.addChange(change, ledgerSeq); err != nil {
+				return err
+			}
+		}
+	}
+
+	diagnosticEvents, err := transaction.GetDiagnosticEvents()
+	if err != nil {
+		// transactions before soroban have no events
+		return nil
+	}
+	for _, event := range filterEvents(diagnosticEvents) {
+		sacEvent, err := contractevents.NewStellarAssetContractEvent(&event, d.passphrase)
+		if err != nil {
+			continue // not an event of a Stellar Asset Contract
+		}
+		if err := d.addContract(sacEvent.GetAsset(), ledgerSeq); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Outputs returns the collected assets ordered by the ledger they were first seen in
+func (d *AssetDimension) Outputs() []AssetDimensionOutput {
+	outputs := make([]AssetDimensionOutput, 0, len(d.assets))
+	for _, asset := range d.assets {
+		outputs = append(outputs, *asset)
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].FirstSeenLedger != outputs[j].FirstSeenLedger {
+			return outputs[i].FirstSeenLedger < outputs[j].FirstSeenLedger
+		}
+		return outputs[i].AssetID < outputs[j].AssetID
+	})
+	return outputs
+}
+
+// addChange adds the asset of a trustline, and the asset of a Stellar Asset Contract instance along with its
+// contract id
+func (d *AssetDimension) addChange(change ingest.Change, ledgerSeq uint32) error {
+	entry := change.Post
+	if entry == nil {
+		entry = change.Pre
+	}
+
+	switch change.Type {
+	case xdr.LedgerEntryTypeTrustline:
+		trustline := entry.Data.MustTrustLine()
+		if trustline.Asset.Type == xdr.AssetTypeAssetTypePoolShare {
+			return nil
+		}
+		_, err := d.addAsset(trustline.Asset.ToAsset(), ledgerSeq)
+		return err
+	case xdr.LedgerEntryTypeContractData:
+		asset := AssetFromContractData(*entry, d.passphrase)
+		if asset == nil {
+			return nil
+		}
+		return d.addContract(*asset, ledgerSeq)
+	}
+	return nil
+}
+
+// addDetails adds the assets of the details of an operation or effect, which are the asset_type, asset_code and
+// asset_issuer keys with a common prefix
+func (d *AssetDimension) addDetails(details map[string]interface{}, ledgerSeq uint32) {
+	for key, value := range details {
+		if !strings.HasSuffix(key, "asset_type") {
+			continue
+		}
+		assetType, ok := value.(string)
+		if !ok || !isClassicAssetType(assetType) {
+			continue
+		}
+		prefix := strings.TrimSuffix(key, "asset_type")
+		code, _ := details[prefix+"asset_code"].(string)
+		issuer, _ := details[prefix+"asset_issuer"].(string)
+		d.add(code, issuer, assetType, ledgerSeq)
+	}
+
+	if path, ok := details["path"].([]Path); ok {
+		for _, asset := range path {
+			d.add(asset.AssetCode, asset.AssetIssuer, asset.AssetType, ledgerSeq)
+		}
+	}
+}
+
+func (d *AssetDimension) addAsset(asset xdr.Asset, ledgerSeq uint32) (*AssetDimensionOutput, error) {
+	var assetType, code, issuer string
+	if err := asset.Extract(&assetType, &code, &issuer); err != nil {
+		return nil, err
+	}
+	return d.add(code, issuer, assetType, ledgerSeq), nil
+}
+
+// addContract adds an asset with a deployed Stellar Asset Contract
+func (d *AssetDimension) addContract(asset xdr.Asset, ledgerSeq uint32) error {
+	output, err := d.addAsset(asset, ledgerSeq)
+	if err != nil {
+		return err
+	}
+
+	contractID, err := utils.AssetContractID(asset, d.passphrase)
+	if err != nil {
+		return fmt.Errorf("could not get contract id of asset %s: %v", asset.StringCanonical(), err)
+	}
+	output.ContractID = null.StringFrom(contractID)
+	return nil
+}
+
+func (d *AssetDimension) add(code, issuer, assetType string, ledgerSeq uint32) *AssetDimensionOutput {
+	assetID := FarmHashAsset(code, issuer, assetType)
+	if asset, ok := d.assets[assetID]; ok {
+		if ledgerSeq < asset.FirstSeenLedger {
+			asset.FirstSeenLedger = ledgerSeq
+		}
+		return asset
+	}
+
+	asset := &AssetDimensionOutput{
+		AssetCode:       code,
+		AssetIssuer:     issuer,
+		AssetType:       assetType,
+		AssetID:         assetID,
+		FirstSeenLedger: ledgerSeq,
+	}
+	d.assets[assetID] = asset
+	return asset
+}
+
+func isClassicAssetType(assetType string) bool {
+	switch assetType {
+	case "native", "credit_alphanum4", "credit_alphanum12":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetDimensionAddTransaction(t *testing.T) {
+	transaction, err := makeAssetTestInput()
+	require.NoError(t, err)
+
+	dimension := NewAssetDimension(network.TestNetworkPassphrase)
+	require.NoError(t, dimension.AddTransaction(transaction, genericLedgerCloseMeta))
+
+	assert.Equal(t, []AssetDimensionOutput{
+		{
+			AssetCode:       "USDT",
+			AssetIssuer:     "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+			AssetType:       "credit_alphanum4",
+			AssetID:         -8205667356306085451,
+			FirstSeenLedger: 2,
+		},
+		{
+			AssetType:       "native",
+			AssetID:         -5706705804583548011,
+			FirstSeenLedger: 2,
+		},
+	}, dimension.Outputs())
+}
+
+func TestAssetDimensionFirstSeenLedger(t *testing.T) {
+	dimension := NewAssetDimension(network.TestNetworkPassphrase)
+	dimension.addDetails(map[string]interface{}{
+		"asset_type":          "credit_alphanum4",
+		"asset_code":          "USDT",
+		"asset_issuer":        testAccount3Address,
+		"source_asset_type":   "native",
+		"liquidity_pool_id":   "abc",
+		"selling_asset_type":  "liquidity_pool_shares",
+		"path":                []Path{{AssetType: "credit_alphanum12", AssetCode: "LONGCODE", AssetIssuer: testAccount4Address}},
+		"bought_asset_issuer": testAccount1Address,
+	}, 20)
+	dimension.addDetails(map[string]interface{}{"asset_type": "native"}, 10)
+
+	outputs := dimension.Outputs()
+	require.Len(t, outputs, 3)
+	assert.Equal(t, AssetDimensionOutput{AssetType: "native", AssetID: FarmHashAsset("", "", "native"), FirstSeenLedger: 10}, outputs[0])
+	for _, output := range outputs[1:] {
+		assert.Equal(t, uint32(20), output.FirstSeenLedger)
+		assert.Equal(t, FarmHashAsset(output.AssetCode, output.AssetIssuer, output.AssetType), output.AssetID)
+	}
+}
+
+func TestAssetDimensionTrustlinesAndContracts(t *testing.T) {
+	dimension := NewAssetDimension(network.TestNetworkPassphrase)
+	trustline := xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeTrustline,
+			TrustLine: &xdr.TrustLineEntry{
+				AccountId: testAccount1ID,
+				Asset:     usdtAsset.ToTrustLineAsset(),
+			},
+		},
+	}
+	require.NoError(t, dimension.addChange(ingest.Change{Type: xdr.LedgerEntryTypeTrustline, Pre: &trustline}, 5))
+	require.NoError(t, dimension.addContract(xdr.MustNewNativeAsset(), 7))
+
+	nativeContractID, err := xdr.MustNewNativeAsset().ContractID(network.TestNetworkPassphrase)
+	require.NoError(t, err)
+	nativeContract, err := strkey.Encode(strkey.VersionByteContract, nativeContractID[:])
+	require.NoError(t, err)
+
+	assert.Equal(t, []AssetDimensionOutput{
+		{
+			AssetCode:       "USDT",
+			AssetIssuer:     "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
+			AssetType:       "credit_alphanum4",
+			AssetID:         -8205667356306085451,
+			FirstSeenLedger: 5,
+		},
+		{
+			AssetType:       "native",
+			AssetID:         -5706705804583548011,
+			FirstSeenLedger: 7,
+			ContractID:      null.StringFrom(nativeContract),
+		},
+	}, dimension.Outputs())
+}
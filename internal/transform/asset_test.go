@@ -8,7 +8,10 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 func TestTransformAsset(t *testing.T) {
@@ -59,8 +62,8 @@ func TestTransformAsset(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformAsset(test.input.operation, test.input.index, test.input.txnIndex, 0, test.input.lcm)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformAsset(test.input.operation, test.input.index, test.input.txnIndex, 0, test.input.lcm, network.TestNetworkPassphrase, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -108,6 +111,7 @@ func makeAssetTestOutput() (transformedAssets []AssetOutput) {
 			AssetIssuer:    "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
 			AssetType:      "credit_alphanum4",
 			AssetID:        -8205667356306085451,
+			ContractID:     "CDXNFX6V6MOOBTDBDOTSWHVDW73SLUCB5HXQPWWKN35N4FCAZATKMXKY",
 			ClosedAt:       time.Date(1970, time.January, 1, 0, 0, 10, 0, time.UTC),
 			LedgerSequence: 2,
 		},
@@ -116,6 +120,7 @@ func makeAssetTestOutput() (transformedAssets []AssetOutput) {
 			AssetIssuer:    "",
 			AssetType:      "native",
 			AssetID:        -5706705804583548011,
+			ContractID:     "CDLZFC3SYJYDZT7K67VZ75HPJVIEUVNIXF47ZG2FB2RMQQVU2HHGCYSC",
 			ClosedAt:       time.Date(1970, time.January, 1, 0, 0, 10, 0, time.UTC),
 			LedgerSequence: 2,
 		},
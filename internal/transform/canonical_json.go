@@ -0,0 +1,63 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// CanonicalJSON encodes a value, such as the details of an operation or effect, so that it serializes identically
+// across runs and output formats. The keys of all the nested objects are sorted, including the ones of types with
+// their own MarshalJSON, and numbers are written in plain decimal notation instead of the exponent notation that
+// encoding/json uses for very small and very large floats.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	marshalled, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(CanonicalNumbers(decoded))
+}
+
+// CanonicalNumbers rewrites the numbers of a value decoded with UseNumber in plain decimal notation. Numbers
+// without an exponent are kept as they are, so that large integers do not lose precision.
+func CanonicalNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, item := range value {
+			value[key] = CanonicalNumbers(item)
+		}
+		return value
+	case []interface{}:
+		for i, item := range value {
+			value[i] = CanonicalNumbers(item)
+		}
+		return value
+	case json.Number:
+		return canonicalNumber(value)
+	default:
+		return v
+	}
+}
+
+func canonicalNumber(number json.Number) json.Number {
+	text := number.String()
+	if !strings.ContainsAny(text, "eE") {
+		return number
+	}
+
+	// numbers with an exponent are the floats formatted by encoding/json, which round trip through float64
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return number
+	}
+	return json.Number(strconv.FormatFloat(f, 'f', -1, 64))
+}
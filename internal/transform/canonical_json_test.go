@@ -0,0 +1,46 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reversedKeys marshals its keys in reverse order, like a type with its own MarshalJSON might
+type reversedKeys struct{}
+
+func (reversedKeys) MarshalJSON() ([]byte, error) {
+	return []byte(`{"b":1,"a":2}`), nil
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	details := map[string]interface{}{
+		"amount":    0.0000001,
+		"price":     2.5,
+		"large":     1e21,
+		"asset_id":  int64(-5706705804583548011),
+		"custom":    reversedKeys{},
+		"reserves":  []base.AssetAmount{{Asset: "native", Amount: "1.0000000"}},
+		"price_r":   map[string]interface{}{"n": int32(5), "d": int32(2)},
+		"min_price": float32(0.0000005),
+	}
+
+	encoded, err := CanonicalJSON(details)
+	require.NoError(t, err)
+	assert.Equal(t, `{"amount":0.0000001,"asset_id":-5706705804583548011,"custom":{"a":2,"b":1},"large":1000000000000000000000,"min_price":0.0000005,"price":2.5,"price_r":{"d":2,"n":5},"reserves":[{"amount":"1.0000000","asset":"native"}]}`, string(encoded))
+
+	// the encoding does not depend on the run
+	for i := 0; i < 10; i++ {
+		again, err := CanonicalJSON(details)
+		require.NoError(t, err)
+		assert.Equal(t, encoded, again)
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	decoded := []interface{}{json.Number("1e-07"), json.Number("12345678901234567890"), json.Number("2.5"), "1e-07"}
+	assert.Equal(t, []interface{}{json.Number("0.0000001"), json.Number("12345678901234567890"), json.Number("2.5"), "1e-07"}, CanonicalNumbers(decoded))
+}
@@ -2,30 +2,249 @@ package transform
 
 import (
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/guregu/null"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 )
 
-func transformClaimants(claimants []xdr.Claimant) []Claimant {
+func transformClaimants(claimants []xdr.Claimant) ([]Claimant, error) {
 	var transformed []Claimant
 	for _, c := range claimants {
 		cv0 := c.MustV0()
+		predicate, err := transformClaimPredicate(cv0.Predicate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid predicate of claimant %s: %v", cv0.Destination.Address(), err)
+		}
 		transformed = append(transformed, Claimant{
 			Destination: cv0.Destination.Address(),
-			Predicate:   cv0.Predicate,
+			Predicate:   predicate,
 		})
 	}
-	return transformed
+	return transformed, nil
+}
+
+// minRFC3339Epoch and maxRFC3339Epoch are the unix times of 0000-01-01T00:00:00Z and 9999-12-31T23:59:59Z
+const (
+	minRFC3339Epoch = -62167219200
+	maxRFC3339Epoch = 253402300799
+)
+
+// transformClaimPredicate renders a claim predicate as nested json objects with a single key, the one of Horizon:
+// unconditional, and, or, not, abs_before or rel_before. abs_before is an RFC3339 timestamp next to the exact
+// abs_before_epoch; it is left out for times beyond year 9999, which RFC3339 cannot represent.
+func transformClaimPredicate(predicate xdr.ClaimPredicate) (map[string]interface{}, error) {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return map[string]interface{}{"unconditional": true}, nil
+	case xdr.ClaimPredicateTypeClaimPredicateAnd, xdr.ClaimPredicateTypeClaimPredicateOr:
+		key := "and"
+		inner, ok := predicate.GetAndPredicates()
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			key = "or"
+			inner, ok = predicate.GetOrPredicates()
+		}
+		if !ok {
+			return nil, fmt.Errorf("missing %s predicates", key)
+		}
+		transformed := make([]interface{}, len(inner))
+		for i, p := range inner {
+			innerPredicate, err := transformClaimPredicate(p)
+			if err != nil {
+				return nil, err
+			}
+			transformed[i] = innerPredicate
+		}
+		return map[string]interface{}{key: transformed}, nil
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		// GetNotPredicate dereferences the optional predicate, so it is checked before
+		if predicate.NotPredicate == nil || *predicate.NotPredicate == nil {
+			return nil, fmt.Errorf("missing not predicate")
+		}
+		transformed, err := transformClaimPredicate(**predicate.NotPredicate)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"not": transformed}, nil
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		absBefore, ok := predicate.GetAbsBefore()
+		if !ok {
+			return nil, fmt.Errorf("missing abs_before")
+		}
+		transformed := map[string]interface{}{"abs_before_epoch": strconv.FormatInt(int64(absBefore), 10)}
+		if absBefore >= minRFC3339Epoch && absBefore <= maxRFC3339Epoch {
+			transformed["abs_before"] = time.Unix(int64(absBefore), 0).UTC().Format(time.RFC3339)
+		}
+		return transformed, nil
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime:
+		relBefore, ok := predicate.GetRelBefore()
+		if !ok {
+			return nil, fmt.Errorf("missing rel_before")
+		}
+		return map[string]interface{}{"rel_before": strconv.FormatInt(int64(relBefore), 10)}, nil
+	default:
+		return nil, fmt.Errorf("unknown predicate type %d", predicate.Type)
+	}
+}
+
+// claimWindow is a time interval [start, end) of unix times, where math.MinInt64 and math.MaxInt64 are unbounded
+type claimWindow struct {
+	start, end int64
+}
+
+// claimWindows returns the sorted, disjoint intervals of close times at which a claim predicate holds. The returned
+// bool is false when they cannot be derived, which is the case of rel_before predicates: their times are relative to
+// when the balance was created, which is not part of the ledger entry. Core turns them into abs_before predicates
+// when it creates the balance, so the predicates of ledger entries only have abs_before times.
+func claimWindows(predicate xdr.ClaimPredicate) ([]claimWindow, bool) {
+	switch predicate.Type {
+	case xdr.ClaimPredicateTypeClaimPredicateUnconditional:
+		return []claimWindow{{math.MinInt64, math.MaxInt64}}, true
+	case xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime:
+		absBefore, ok := predicate.GetAbsBefore()
+		if !ok {
+			return nil, false
+		}
+		return []claimWindow{{math.MinInt64, int64(absBefore)}}, true
+	case xdr.ClaimPredicateTypeClaimPredicateNot:
+		if predicate.NotPredicate == nil || *predicate.NotPredicate == nil {
+			return nil, false
+		}
+		inner, ok := claimWindows(**predicate.NotPredicate)
+		if !ok {
+			return nil, false
+		}
+		complement := []claimWindow{}
+		start := int64(math.MinInt64)
+		for _, window := range inner {
+			if window.start > start {
+				complement = append(complement, claimWindow{start, window.start})
+			}
+			start = window.end
+		}
+		if start < math.MaxInt64 {
+			complement = append(complement, claimWindow{start, math.MaxInt64})
+		}
+		return complement, true
+	case xdr.ClaimPredicateTypeClaimPredicateAnd, xdr.ClaimPredicateTypeClaimPredicateOr:
+		inner, ok := predicate.GetAndPredicates()
+		if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateOr {
+			inner, ok = predicate.GetOrPredicates()
+		}
+		if !ok || len(inner) == 0 {
+			return nil, false
+		}
+		windows, ok := claimWindows(inner[0])
+		if !ok {
+			return nil, false
+		}
+		for _, p := range inner[1:] {
+			other, ok := claimWindows(p)
+			if !ok {
+				return nil, false
+			}
+			if predicate.Type == xdr.ClaimPredicateTypeClaimPredicateAnd {
+				windows = intersectClaimWindows(windows, other)
+			} else {
+				windows = unionClaimWindows(windows, other)
+			}
+		}
+		return windows, true
+	default:
+		return nil, false
+	}
+}
+
+func intersectClaimWindows(a, b []claimWindow) []claimWindow {
+	intersection := []claimWindow{}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		start, end := max(a[i].start, b[j].start), min(a[i].end, b[j].end)
+		if start < end {
+			intersection = append(intersection, claimWindow{start, end})
+		}
+		if a[i].end < b[j].end {
+			i++
+		} else {
+			j++
+		}
+	}
+	return intersection
+}
+
+func unionClaimWindows(a, b []claimWindow) []claimWindow {
+	all := append(append([]claimWindow{}, a...), b...)
+	sort.Slice(all, func(i, j int) bool { return all[i].start < all[j].start })
+	union := []claimWindow{}
+	for _, window := range all {
+		if last := len(union) - 1; last >= 0 && window.start <= union[last].end {
+			union[last].end = max(union[last].end, window.end)
+			continue
+		}
+		union = append(union, window)
+	}
+	return union
+}
+
+// setClaimableTimes sets the claimable_from and claimable_before of the claimants of a claimable balance, and of the
+// balance itself from those of its claimants
+func setClaimableTimes(balance *ClaimableBalanceOutput, claimants []xdr.Claimant) {
+	fromUnbounded, beforeUnbounded := false, false
+	for i, c := range claimants {
+		windows, ok := claimWindows(c.MustV0().Predicate)
+		if !ok {
+			fromUnbounded, beforeUnbounded = true, true
+			continue
+		}
+		if len(windows) == 0 {
+			// The claimant can never claim the balance
+			continue
+		}
+
+		if start := windows[0].start; start != math.MinInt64 {
+			from := time.Unix(start, 0).UTC()
+			balance.Claimants[i].ClaimableFrom = &from
+			if !balance.ClaimableFrom.Valid || from.Before(balance.ClaimableFrom.Time) {
+				balance.ClaimableFrom = null.TimeFrom(from)
+			}
+		} else {
+			fromUnbounded = true
+		}
+
+		if end := windows[len(windows)-1].end; end != math.MaxInt64 {
+			before := time.Unix(end, 0).UTC()
+			balance.Claimants[i].ClaimableBefore = &before
+			if !balance.ClaimableBefore.Valid || before.After(balance.ClaimableBefore.Time) {
+				balance.ClaimableBefore = null.TimeFrom(before)
+			}
+		} else {
+			beforeUnbounded = true
+		}
+	}
+
+	if fromUnbounded {
+		balance.ClaimableFrom = null.Time{}
+	}
+	if beforeUnbounded {
+		balance.ClaimableBefore = null.Time{}
+	}
 }
 
 // TransformClaimableBalance converts a claimable balance from the history archive ingestion system into a form suitable for BigQuery
-func TransformClaimableBalance(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (ClaimableBalanceOutput, error) {
+func TransformClaimableBalance(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ ClaimableBalanceOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return ClaimableBalanceOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return ClaimableBalanceOutput{}, err
+	}
 
 	balanceEntry, balanceFound := ledgerEntry.Data.GetClaimableBalance()
 	if !balanceFound {
@@ -44,7 +263,10 @@ func TransformClaimableBalance(ledgerChange ingest.Change, header xdr.LedgerHead
 	if err != nil {
 		return ClaimableBalanceOutput{}, err
 	}
-	outputClaimants := transformClaimants(balanceEntry.Claimants)
+	outputClaimants, err := transformClaimants(balanceEntry.Claimants)
+	if err != nil {
+		return ClaimableBalanceOutput{}, err
+	}
 	outputAmount := balanceEntry.Amount
 
 	outputLastModifiedLedger := uint32(ledgerEntry.LastModifiedLedgerSeq)
@@ -73,5 +295,6 @@ func TransformClaimableBalance(ledgerChange ingest.Change, header xdr.LedgerHead
 		LedgerSequence:     uint32(ledgerSequence),
 		BalanceIDStrkey:    balanceIDStrkey,
 	}
+	setClaimableTimes(&transformed, balanceEntry.Claimants)
 	return transformed, nil
 }
@@ -1,6 +1,8 @@
 package transform
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 	"time"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTransformClaimableBalance(t *testing.T) {
@@ -44,7 +47,7 @@ func TestTransformClaimableBalance(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformClaimableBalance(test.input.ingest, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -109,9 +112,7 @@ func makeClaimableBalanceTestOutput() ClaimableBalanceOutput {
 		Claimants: []Claimant{
 			{
 				Destination: "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ",
-				Predicate: xdr.ClaimPredicate{
-					Type: xdr.ClaimPredicateTypeClaimPredicateUnconditional,
-				},
+				Predicate:   map[string]interface{}{"unconditional": true},
 			},
 		},
 		AssetIssuer:        "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
@@ -129,3 +130,128 @@ func makeClaimableBalanceTestOutput() ClaimableBalanceOutput {
 		BalanceIDStrkey:    "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
 	}
 }
+
+func TestTransformClaimPredicate(t *testing.T) {
+	absBefore := xdr.Int64(1609459200)
+	farFuture := xdr.Int64(math.MaxInt64)
+	relBefore := xdr.Int64(3600)
+	notInner := &xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime, RelBefore: &relBefore}
+	predicate := xdr.ClaimPredicate{
+		Type: xdr.ClaimPredicateTypeClaimPredicateAnd,
+		AndPredicates: &[]xdr.ClaimPredicate{
+			{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime, AbsBefore: &absBefore},
+			{
+				Type: xdr.ClaimPredicateTypeClaimPredicateOr,
+				OrPredicates: &[]xdr.ClaimPredicate{
+					{Type: xdr.ClaimPredicateTypeClaimPredicateNot, NotPredicate: &notInner},
+					{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime, AbsBefore: &farFuture},
+				},
+			},
+		},
+	}
+
+	transformed, err := transformClaimPredicate(predicate)
+	require.NoError(t, err)
+	encoded, err := json.Marshal(transformed)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"and": [
+		{"abs_before": "2021-01-01T00:00:00Z", "abs_before_epoch": "1609459200"},
+		{"or": [
+			{"not": {"rel_before": "3600"}},
+			{"abs_before_epoch": "9223372036854775807"}
+		]}
+	]}`, string(encoded))
+
+	_, err = transformClaimPredicate(xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateNot})
+	assert.Error(t, err)
+}
+
+func TestSetClaimableTimes(t *testing.T) {
+	absPredicate := func(absBefore int64) xdr.ClaimPredicate {
+		before := xdr.Int64(absBefore)
+		return xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeAbsoluteTime, AbsBefore: &before}
+	}
+	notPredicate := func(inner xdr.ClaimPredicate) xdr.ClaimPredicate {
+		p := &inner
+		return xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateNot, NotPredicate: &p}
+	}
+	claimant := func(predicate xdr.ClaimPredicate) xdr.Claimant {
+		return xdr.Claimant{Type: xdr.ClaimantTypeClaimantTypeV0, V0: &xdr.ClaimantV0{Predicate: predicate}}
+	}
+	unix := func(seconds int64) *time.Time {
+		closedAt := time.Unix(seconds, 0).UTC()
+		return &closedAt
+	}
+	relBefore := xdr.Int64(3600)
+
+	type claimableTimes struct {
+		from, before *time.Time
+	}
+	type testCase struct {
+		name          string
+		claimants     []xdr.Claimant
+		wantClaimants []claimableTimes
+		wantFrom      null.Time
+		wantBefore    null.Time
+	}
+	tests := []testCase{
+		{
+			name:          "abs_before and not abs_before",
+			claimants:     []xdr.Claimant{claimant(absPredicate(2000)), claimant(notPredicate(absPredicate(1000)))},
+			wantClaimants: []claimableTimes{{nil, unix(2000)}, {unix(1000), nil}},
+		},
+		{
+			name: "window of and",
+			claimants: []xdr.Claimant{claimant(xdr.ClaimPredicate{
+				Type:          xdr.ClaimPredicateTypeClaimPredicateAnd,
+				AndPredicates: &[]xdr.ClaimPredicate{notPredicate(absPredicate(1000)), absPredicate(2000)},
+			})},
+			wantClaimants: []claimableTimes{{unix(1000), unix(2000)}},
+			wantFrom:      null.TimeFrom(*unix(1000)),
+			wantBefore:    null.TimeFrom(*unix(2000)),
+		},
+		{
+			name: "windows of or and of several claimants",
+			claimants: []xdr.Claimant{
+				claimant(xdr.ClaimPredicate{
+					Type: xdr.ClaimPredicateTypeClaimPredicateOr,
+					OrPredicates: &[]xdr.ClaimPredicate{
+						{Type: xdr.ClaimPredicateTypeClaimPredicateAnd, AndPredicates: &[]xdr.ClaimPredicate{notPredicate(absPredicate(1000)), absPredicate(2000)}},
+						{Type: xdr.ClaimPredicateTypeClaimPredicateAnd, AndPredicates: &[]xdr.ClaimPredicate{notPredicate(absPredicate(3000)), absPredicate(4000)}},
+					},
+				}),
+				claimant(xdr.ClaimPredicate{
+					Type:          xdr.ClaimPredicateTypeClaimPredicateAnd,
+					AndPredicates: &[]xdr.ClaimPredicate{notPredicate(absPredicate(500)), absPredicate(1500)},
+				}),
+			},
+			wantClaimants: []claimableTimes{{unix(1000), unix(4000)}, {unix(500), unix(1500)}},
+			wantFrom:      null.TimeFrom(*unix(500)),
+			wantBefore:    null.TimeFrom(*unix(4000)),
+		},
+		{
+			name: "never claimable and rel_before",
+			claimants: []xdr.Claimant{
+				claimant(xdr.ClaimPredicate{
+					Type:          xdr.ClaimPredicateTypeClaimPredicateAnd,
+					AndPredicates: &[]xdr.ClaimPredicate{notPredicate(absPredicate(2000)), absPredicate(1000)},
+				}),
+				claimant(xdr.ClaimPredicate{Type: xdr.ClaimPredicateTypeClaimPredicateBeforeRelativeTime, RelBefore: &relBefore}),
+			},
+			wantClaimants: []claimableTimes{{nil, nil}, {nil, nil}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			balance := ClaimableBalanceOutput{Claimants: make([]Claimant, len(test.claimants))}
+			setClaimableTimes(&balance, test.claimants)
+			for i, want := range test.wantClaimants {
+				assert.Equal(t, want.from, balance.Claimants[i].ClaimableFrom)
+				assert.Equal(t, want.before, balance.Claimants[i].ClaimableBefore)
+			}
+			assert.Equal(t, test.wantFrom, balance.ClaimableFrom)
+			assert.Equal(t, test.wantBefore, balance.ClaimableBefore)
+		})
+	}
+}
@@ -10,11 +10,15 @@ import (
 )
 
 // TransformConfigSetting converts an config setting ledger change entry into a form suitable for BigQuery
-func TransformConfigSetting(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (ConfigSettingOutput, error) {
+func TransformConfigSetting(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ ConfigSettingOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return ConfigSettingOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return ConfigSettingOutput{}, err
+	}
 
 	configSetting, ok := ledgerEntry.Data.GetConfigSetting()
 	if !ok {
@@ -49,11 +49,12 @@ func TestTransformConfigSetting(t *testing.T) {
 				ScpValue: xdr.StellarValue{
 					CloseTime: 1000,
 				},
-				LedgerSeq: 10,
+				LedgerSeq:     10,
+				LedgerVersion: 22,
 			},
 		}
 		actualOutput, actualError := TransformConfigSetting(test.input, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -0,0 +1,129 @@
+package transform
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformContractBalance converts a Stellar Asset Contract balance entry into a row of the contract balance holders.
+// Unlike ContractBalanceFromContractData, the holder can be an account as well as a contract. The returned bool is
+// false when the contract data change is not a balance entry.
+func TransformContractBalance(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ ContractBalanceOutput, _ bool, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
+	if err != nil {
+		return ContractBalanceOutput{}, false, err
+	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return ContractBalanceOutput{}, false, err
+	}
+
+	contractData, ok := ledgerEntry.Data.GetContractData()
+	if !ok {
+		return ContractBalanceOutput{}, false, fmt.Errorf("could not extract contract data from ledger entry; actual type is %s", ledgerEntry.Data.Type)
+	}
+
+	holder, ok := contractBalanceHolder(contractData.Key)
+	if !ok {
+		return ContractBalanceOutput{}, false, nil
+	}
+	balance, authorized, clawback, ok := contractBalanceValue(contractData.Val)
+	if !ok {
+		return ContractBalanceOutput{}, false, nil
+	}
+
+	contractID, ok := contractData.Contract.GetContractId()
+	if !ok {
+		return ContractBalanceOutput{}, false, nil
+	}
+	outputContractID, err := strkey.Encode(strkey.VersionByteContract, contractID[:])
+	if err != nil {
+		return ContractBalanceOutput{}, false, err
+	}
+
+	holderAddress, err := holder.String()
+	if err != nil {
+		return ContractBalanceOutput{}, false, err
+	}
+	holderType := "account"
+	if holder.Type == xdr.ScAddressTypeScAddressTypeContract {
+		holderType = "contract"
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return ContractBalanceOutput{}, false, err
+	}
+
+	return ContractBalanceOutput{
+		ContractId:         outputContractID,
+		Holder:             holderAddress,
+		HolderType:         holderType,
+		Balance:            balance.String(),
+		Authorized:         authorized,
+		Clawback:           clawback,
+		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:  uint32(changeType),
+		Deleted:            outputDeleted,
+		ClosedAt:           closedAt,
+		LedgerSequence:     uint32(header.Header.LedgerSeq),
+		LedgerKeyHash:      utils.LedgerEntryToLedgerKeyHash(ledgerEntry),
+	}, true, nil
+}
+
+// contractBalanceHolder returns the holder of a balance entry, whose key is the vector [Balance, holder]
+func contractBalanceHolder(key xdr.ScVal) (xdr.ScAddress, bool) {
+	keyVecPtr, ok := key.GetVec()
+	if !ok || keyVecPtr == nil {
+		return xdr.ScAddress{}, false
+	}
+	keyVec := *keyVecPtr
+	if len(keyVec) != 2 || !keyVec[0].Equals(xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &balanceMetadataSym}) {
+		return xdr.ScAddress{}, false
+	}
+	return keyVec[1].GetAddress()
+}
+
+// contractBalanceValue returns the amount and flags of a balance entry, whose value is the map
+// {amount, authorized, clawback}
+func contractBalanceValue(val xdr.ScVal) (*big.Int, bool, bool, bool) {
+	balanceMapPtr, ok := val.GetMap()
+	if !ok || balanceMapPtr == nil || len(*balanceMapPtr) != 3 {
+		return nil, false, false, false
+	}
+	balanceMap := *balanceMapPtr
+
+	if sym, ok := balanceMap[0].Key.GetSym(); !ok || sym != "amount" {
+		return nil, false, false, false
+	}
+	amount, ok := balanceMap[0].Val.GetI128()
+	// amount cannot be negative
+	if !ok || int64(amount.Hi) < 0 {
+		return nil, false, false, false
+	}
+
+	if sym, ok := balanceMap[1].Key.GetSym(); !ok || sym != "authorized" {
+		return nil, false, false, false
+	}
+	authorized, ok := balanceMap[1].Val.GetB()
+	if !ok {
+		return nil, false, false, false
+	}
+
+	if sym, ok := balanceMap[2].Key.GetSym(); !ok || sym != "clawback" {
+		return nil, false, false, false
+	}
+	clawback, ok := balanceMap[2].Val.GetB()
+	if !ok {
+		return nil, false, false, false
+	}
+
+	balance := new(big.Int).Lsh(new(big.Int).SetInt64(int64(amount.Hi)), 64)
+	balance.Add(balance, new(big.Int).SetUint64(uint64(amount.Lo)))
+	return balance, authorized, clawback, true
+}
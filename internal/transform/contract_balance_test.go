@@ -0,0 +1,160 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+func TestTransformContractBalance(t *testing.T) {
+	type transformTest struct {
+		input      ingest.Change
+		wantOutput ContractBalanceOutput
+		wantOk     bool
+		wantErr    error
+	}
+
+	hardCodedInput := makeContractBalanceTestInput()
+	hardCodedOutput := makeContractBalanceTestOutput()
+	tests := []transformTest{
+		{
+			ingest.Change{
+				Type: xdr.LedgerEntryTypeOffer,
+				Pre:  nil,
+				Post: &xdr.LedgerEntry{
+					Data: xdr.LedgerEntryData{
+						Type: xdr.LedgerEntryTypeOffer,
+					},
+				},
+			},
+			ContractBalanceOutput{}, false, fmt.Errorf("could not extract contract data from ledger entry; actual type is LedgerEntryTypeOffer"),
+		},
+		{
+			makeContractDataTestInput()[0],
+			ContractBalanceOutput{}, false, nil,
+		},
+	}
+
+	for i := range hardCodedInput {
+		tests = append(tests, transformTest{
+			input:      hardCodedInput[i],
+			wantOutput: hardCodedOutput[i],
+			wantOk:     true,
+			wantErr:    nil,
+		})
+	}
+
+	for _, test := range tests {
+		header := xdr.LedgerHeaderHistoryEntry{
+			Header: xdr.LedgerHeader{
+				ScpValue: xdr.StellarValue{
+					CloseTime: 1000,
+				},
+				LedgerSeq:     10,
+				LedgerVersion: 22,
+			},
+		}
+		actualOutput, actualOk, actualError := TransformContractBalance(test.input, header)
+		assertTransformError(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantOk, actualOk)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeContractBalanceEntry(holder xdr.ScAddress, amount uint64, authorized, clawback bool) xdr.LedgerEntry {
+	contractID := xdr.Hash{1}
+	balanceSym := xdr.ScSymbol("Balance")
+	amountSym, authorizedSym, clawbackSym := xdr.ScSymbol("amount"), xdr.ScSymbol("authorized"), xdr.ScSymbol("clawback")
+	key := xdr.ScVec{
+		{Type: xdr.ScValTypeScvSymbol, Sym: &balanceSym},
+		{Type: xdr.ScValTypeScvAddress, Address: &holder},
+	}
+	val := xdr.ScMap{
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &amountSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvI128, I128: &xdr.Int128Parts{Hi: 1, Lo: xdr.Uint64(amount)}},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &authorizedSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &authorized},
+		},
+		{
+			Key: xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &clawbackSym},
+			Val: xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &clawback},
+		},
+	}
+	keyVec, valMap := &key, &val
+
+	return xdr.LedgerEntry{
+		LastModifiedLedgerSeq: 24229503,
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeContractData,
+			ContractData: &xdr.ContractDataEntry{
+				Contract: xdr.ScAddress{
+					Type:       xdr.ScAddressTypeScAddressTypeContract,
+					ContractId: &contractID,
+				},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvVec, Vec: &keyVec},
+				Durability: xdr.ContractDataDurabilityPersistent,
+				Val:        xdr.ScVal{Type: xdr.ScValTypeScvMap, Map: &valMap},
+			},
+		},
+	}
+}
+
+func makeContractBalanceTestInput() []ingest.Change {
+	holderContract := xdr.Hash{2}
+	accountEntry := makeContractBalanceEntry(xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &testAccount1ID}, 5, true, false)
+	contractEntry := makeContractBalanceEntry(xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &holderContract}, 0, false, true)
+
+	return []ingest.Change{
+		{
+			Type: xdr.LedgerEntryTypeContractData,
+			Pre:  nil,
+			Post: &accountEntry,
+		},
+		{
+			Type: xdr.LedgerEntryTypeContractData,
+			Pre:  &contractEntry,
+			Post: nil,
+		},
+	}
+}
+
+func makeContractBalanceTestOutput() []ContractBalanceOutput {
+	return []ContractBalanceOutput{
+		{
+			ContractId:         "CAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABDQF",
+			Holder:             testAccount1Address,
+			HolderType:         "account",
+			Balance:            "18446744073709551621",
+			Authorized:         true,
+			Clawback:           false,
+			LastModifiedLedger: 24229503,
+			LedgerEntryChange:  0,
+			Deleted:            false,
+			ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+			LedgerSequence:     10,
+			LedgerKeyHash:      "e83751e4933c74bdc1a4c26888a8da8f86ef43c794b6282624172030a83d4dcf",
+		},
+		{
+			ContractId:         "CAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABDQF",
+			Holder:             "CABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAARHO",
+			HolderType:         "contract",
+			Balance:            "18446744073709551616",
+			Authorized:         false,
+			Clawback:           true,
+			LastModifiedLedger: 24229503,
+			LedgerEntryChange:  2,
+			Deleted:            true,
+			ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+			LedgerSequence:     10,
+			LedgerKeyHash:      "7bff3a6a7240a33d5714a5fc6fa24141379d3993336ecaee3eb0a3a4df5fa9b2",
+		},
+	}
+}
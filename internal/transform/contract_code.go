@@ -9,11 +9,15 @@ import (
 )
 
 // TransformContractCode converts a contract code ledger change entry into a form suitable for BigQuery
-func TransformContractCode(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (ContractCodeOutput, error) {
+func TransformContractCode(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ ContractCodeOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return ContractCodeOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return ContractCodeOutput{}, err
+	}
 
 	contractCode, ok := ledgerEntry.Data.GetContractCode()
 	if !ok {
@@ -76,26 +80,37 @@ func TransformContractCode(ledgerChange ingest.Change, header xdr.LedgerHeaderHi
 		outputNDataSegmentBytes = uint32(extV1.CostInputs.NDataSegmentBytes)
 	}
 
+	wasmMeta, err := parseContractWasmMeta(contractCode.Code)
+	if err != nil {
+		return ContractCodeOutput{}, fmt.Errorf("could not read the metadata of contract code %s: %v", contractCodeHash, err)
+	}
+
 	transformedCode := ContractCodeOutput{
-		ContractCodeHash:    contractCodeHash,
-		ContractCodeExtV:    int32(contractCodeExtV),
-		LastModifiedLedger:  uint32(ledgerEntry.LastModifiedLedgerSeq),
-		LedgerEntryChange:   uint32(changeType),
-		Deleted:             outputDeleted,
-		ClosedAt:            closedAt,
-		LedgerSequence:      uint32(ledgerSequence),
-		LedgerKeyHash:       ledgerKeyHash,
-		NInstructions:       outputNInstructions,
-		NFunctions:          outputNFunctions,
-		NGlobals:            outputNGlobals,
-		NTableEntries:       outputNTableEntries,
-		NTypes:              outputNTypes,
-		NDataSegments:       outputNDataSegments,
-		NElemSegments:       outputNElemSegments,
-		NImports:            outputNImports,
-		NExports:            outputNExports,
-		NDataSegmentBytes:   outputNDataSegmentBytes,
-		LedgerKeyHashBase64: ledgerKeyHashBase64,
+		ContractCodeHash:       contractCodeHash,
+		ContractCodeExtV:       int32(contractCodeExtV),
+		LastModifiedLedger:     uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:      uint32(changeType),
+		Deleted:                outputDeleted,
+		ClosedAt:               closedAt,
+		LedgerSequence:         uint32(ledgerSequence),
+		LedgerKeyHash:          ledgerKeyHash,
+		NInstructions:          outputNInstructions,
+		NFunctions:             outputNFunctions,
+		NGlobals:               outputNGlobals,
+		NTableEntries:          outputNTableEntries,
+		NTypes:                 outputNTypes,
+		NDataSegments:          outputNDataSegments,
+		NElemSegments:          outputNElemSegments,
+		NImports:               outputNImports,
+		NExports:               outputNExports,
+		NDataSegmentBytes:      outputNDataSegmentBytes,
+		LedgerKeyHashBase64:    ledgerKeyHashBase64,
+		EnvInterfaceProtocol:   wasmMeta.envInterfaceProtocol,
+		EnvInterfacePreRelease: wasmMeta.envInterfacePreRelease,
+		RustVersion:            wasmMeta.rustVersion,
+		RustSdkVersion:         wasmMeta.rustSdkVersion,
+		ContractMeta:           wasmMeta.meta,
+		ContractFunctions:      wasmMeta.functions,
 	}
 	return transformedCode, nil
 }
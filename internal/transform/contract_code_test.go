@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
@@ -49,11 +50,12 @@ func TestTransformContractCode(t *testing.T) {
 				ScpValue: xdr.StellarValue{
 					CloseTime: 1000,
 				},
-				LedgerSeq: 10,
+				LedgerSeq:     10,
+				LedgerVersion: 22,
 			},
 		}
 		actualOutput, actualError := TransformContractCode(test.input, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -67,6 +69,7 @@ func makeContractCodeTestInput() []ingest.Change {
 			Type: xdr.LedgerEntryTypeContractCode,
 			ContractCode: &xdr.ContractCodeEntry{
 				Hash: hash,
+				Code: makeContractWasmTestInput(),
 				Ext: xdr.ContractCodeEntryExt{
 					V: 1,
 					V1: &xdr.ContractCodeEntryV1{
@@ -100,25 +103,127 @@ func makeContractCodeTestInput() []ingest.Change {
 func makeContractCodeTestOutput() []ContractCodeOutput {
 	return []ContractCodeOutput{
 		{
-			ContractCodeHash:    "0000000000000000000000000000000000000000000000000000000000000000",
-			ContractCodeExtV:    1,
-			LastModifiedLedger:  24229503,
-			LedgerEntryChange:   1,
-			Deleted:             false,
-			LedgerSequence:      10,
-			ClosedAt:            time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
-			LedgerKeyHash:       "dfed061dbe464e0ff320744fcd604ac08b39daa74fa24110936654cbcb915ccc",
-			NInstructions:       1,
-			NFunctions:          2,
-			NGlobals:            3,
-			NTableEntries:       4,
-			NTypes:              5,
-			NDataSegments:       6,
-			NElemSegments:       7,
-			NImports:            8,
-			NExports:            9,
-			NDataSegmentBytes:   10,
-			LedgerKeyHashBase64: "AAAABwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+			ContractCodeHash:       "0000000000000000000000000000000000000000000000000000000000000000",
+			ContractCodeExtV:       1,
+			LastModifiedLedger:     24229503,
+			LedgerEntryChange:      1,
+			Deleted:                false,
+			LedgerSequence:         10,
+			ClosedAt:               time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+			LedgerKeyHash:          "dfed061dbe464e0ff320744fcd604ac08b39daa74fa24110936654cbcb915ccc",
+			NInstructions:          1,
+			NFunctions:             2,
+			NGlobals:               3,
+			NTableEntries:          4,
+			NTypes:                 5,
+			NDataSegments:          6,
+			NElemSegments:          7,
+			NImports:               8,
+			NExports:               9,
+			NDataSegmentBytes:      10,
+			LedgerKeyHashBase64:    "AAAABwAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+			EnvInterfaceProtocol:   null.IntFrom(22),
+			EnvInterfacePreRelease: null.IntFrom(0),
+			RustVersion:            null.StringFrom("1.84.0"),
+			RustSdkVersion:         null.StringFrom("22.0.7"),
+			ContractMeta:           map[string]string{"rsver": "1.84.0", "rssdkver": "22.0.7"},
+			ContractFunctions: []ContractFunction{
+				{
+					Name: "transfer",
+					Inputs: []ContractFunctionInput{
+						{Name: "from", Type: "address"},
+						{Name: "amounts", Type: "vec<i128>"},
+					},
+					Outputs: []string{"result<option<u32>,Error>"},
+				},
+			},
 		},
 	}
 }
+
+// wasmTestSection encodes a wasm section with the size of its content
+func wasmTestSection(id byte, content []byte) []byte {
+	section := []byte{id}
+	size := len(content)
+	for size >= 0x80 {
+		section = append(section, byte(size&0x7f)|0x80)
+		size >>= 7
+	}
+	section = append(section, byte(size))
+	return append(section, content...)
+}
+
+func wasmTestCustomSection(name string, entries ...interface{ MarshalBinary() ([]byte, error) }) []byte {
+	content := append([]byte{byte(len(name))}, name...)
+	for _, entry := range entries {
+		encoded, _ := entry.MarshalBinary()
+		content = append(content, encoded...)
+	}
+	return wasmTestSection(0, content)
+}
+
+func makeContractWasmTestInput() []byte {
+	i128 := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeI128}
+	u32 := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeU32}
+	errorType := xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeUdt, Udt: &xdr.ScSpecTypeUdt{Name: "Error"}}
+	transfer := xdr.ScSpecEntry{
+		Kind: xdr.ScSpecEntryKindScSpecEntryFunctionV0,
+		FunctionV0: &xdr.ScSpecFunctionV0{
+			Name: "transfer",
+			Inputs: []xdr.ScSpecFunctionInputV0{
+				{Name: "from", Type: xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeAddress}},
+				{Name: "amounts", Type: xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeVec, Vec: &xdr.ScSpecTypeVec{ElementType: i128}}},
+			},
+			Outputs: []xdr.ScSpecTypeDef{{
+				Type: xdr.ScSpecTypeScSpecTypeResult,
+				Result: &xdr.ScSpecTypeResult{
+					OkType:    xdr.ScSpecTypeDef{Type: xdr.ScSpecTypeScSpecTypeOption, Option: &xdr.ScSpecTypeOption{ValueType: u32}},
+					ErrorType: errorType,
+				},
+			}},
+		},
+	}
+	errorEnum := xdr.ScSpecEntry{
+		Kind:           xdr.ScSpecEntryKindScSpecEntryUdtErrorEnumV0,
+		UdtErrorEnumV0: &xdr.ScSpecUdtErrorEnumV0{Name: "Error"},
+	}
+
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	// An empty type section, which is not a custom section
+	code = append(code, wasmTestSection(1, []byte{0})...)
+	code = append(code, wasmTestCustomSection("contractenvmetav0", xdr.ScEnvMetaEntry{
+		Kind:             xdr.ScEnvMetaKindScEnvMetaKindInterfaceVersion,
+		InterfaceVersion: &xdr.ScEnvMetaEntryInterfaceVersion{Protocol: 22},
+	})...)
+	code = append(code, wasmTestCustomSection("contractmetav0",
+		xdr.ScMetaEntry{Kind: xdr.ScMetaKindScMetaV0, V0: &xdr.ScMetaV0{Key: "rsver", Val: "1.84.0"}},
+		xdr.ScMetaEntry{Kind: xdr.ScMetaKindScMetaV0, V0: &xdr.ScMetaV0{Key: "rssdkver", Val: "22.0.7"}},
+	)...)
+	// The spec of every function is in a section of its own, which are read as one
+	code = append(code, wasmTestCustomSection("contractspecv0", transfer)...)
+	code = append(code, wasmTestCustomSection("contractspecv0", errorEnum)...)
+	return code
+}
+
+func TestWasmCustomSectionsErrors(t *testing.T) {
+	_, err := wasmCustomSections([]byte("not wasm"))
+	assert.EqualError(t, err, "contract code is not a wasm module")
+
+	truncated := append([]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}, 0, 10, 1)
+	_, err = wasmCustomSections(truncated)
+	assert.EqualError(t, err, "wasm section 0 of 10 bytes overflows the module")
+}
+
+func TestParseContractWasmMetaInvalidSpec(t *testing.T) {
+	code := []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+	code = append(code, wasmTestCustomSection("contractmetav0",
+		xdr.ScMetaEntry{Kind: xdr.ScMetaKindScMetaV0, V0: &xdr.ScMetaV0{Key: "rssdkver", Val: "21.0.0"}},
+	)...)
+	code = append(code, wasmTestSection(0, append([]byte{byte(len("contractspecv0"))}, "contractspecv0\xff"...))...)
+
+	wasmMeta, err := parseContractWasmMeta(code)
+	assert.NoError(t, err)
+	assert.Equal(t, null.StringFrom("21.0.0"), wasmMeta.rustSdkVersion)
+	assert.Empty(t, wasmMeta.functions)
+	assert.False(t, wasmMeta.envInterfaceProtocol.Valid)
+}
@@ -5,6 +5,7 @@ import (
 	"math/big"
 	"strings"
 
+	"github.com/guregu/null"
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/xdr"
@@ -46,11 +47,18 @@ func NewTransformContractDataStruct(assetFrom AssetFromContractDataFunc, contrac
 }
 
 // TransformContractData converts a contract data ledger change entry into a form suitable for BigQuery
-func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.Change, passphrase string, header xdr.LedgerHeaderHistoryEntry) (ContractDataOutput, error, bool) {
+func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.Change, passphrase string, header xdr.LedgerHeaderHistoryEntry) (_ ContractDataOutput, err error, _ bool) {
+	coordinates := changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq))
+	coordinates.Network = utils.NetworkName(passphrase)
+	defer wrapError(&err, coordinates)
+
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return ContractDataOutput{}, err, false
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return ContractDataOutput{}, err, false
+	}
 
 	contractData, ok := ledgerEntry.Data.GetContractData()
 	if !ok {
@@ -80,6 +88,7 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 	var contractDataAssetType string
 	var contractDataAssetCode string
 	var contractDataAssetIssuer string
+	var contractDataAssetID null.Int
 
 	contractDataAsset := t.AssetFromContractData(ledgerEntry, passphrase)
 	if contractDataAsset != nil {
@@ -88,6 +97,7 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 			return ContractDataOutput{}, err, false
 		}
 		contractDataAssetCode = strings.ReplaceAll(contractDataAssetCode, "\x00", "")
+		contractDataAssetID = null.IntFrom(FarmHashAsset(contractDataAssetCode, contractDataAssetIssuer, contractDataAssetType))
 	}
 
 	var contractDataBalanceHolder string
@@ -139,6 +149,7 @@ func (t *TransformContractDataStruct) TransformContractData(ledgerChange ingest.
 		ContractDataAssetCode:     contractDataAssetCode,
 		ContractDataAssetIssuer:   contractDataAssetIssuer,
 		ContractDataAssetType:     contractDataAssetType,
+		ContractDataAssetID:       contractDataAssetID,
 		ContractDataBalanceHolder: contractDataBalanceHolder,
 		ContractDataBalance:       contractDataBalance,
 		LastModifiedLedger:        uint32(ledgerEntry.LastModifiedLedgerSeq),
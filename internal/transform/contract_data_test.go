@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
@@ -54,12 +55,13 @@ func TestTransformContractData(t *testing.T) {
 				ScpValue: xdr.StellarValue{
 					CloseTime: 1000,
 				},
-				LedgerSeq: 10,
+				LedgerSeq:     10,
+				LedgerVersion: 22,
 			},
 		}
 		TransformContractData := NewTransformContractDataStruct(MockAssetFromContractData, MockContractBalanceFromContractData)
 		actualOutput, actualError, _ := TransformContractData.TransformContractData(test.input, test.passphrase, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -145,6 +147,7 @@ func makeContractDataTestOutput() []ContractDataOutput {
 			ContractDataAssetCode:     "",
 			ContractDataAssetIssuer:   "",
 			ContractDataAssetType:     "native",
+			ContractDataAssetID:       null.IntFrom(FarmHashAsset("", "", "native")),
 			ContractDataBalanceHolder: "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4",
 			ContractDataBalance:       "0",
 			LastModifiedLedger:        24229503,
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/guregu/null"
 	"github.com/stellar/go-stellar-xdr-json/xdrjson"
 	"github.com/stellar/stellar-etl/v2/internal/toid"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
@@ -17,14 +18,33 @@ import (
 // TransformContractEvent converts a transaction's contract events and diagnostic events into a form suitable for BigQuery.
 // It is known that contract events are a subset of the diagnostic events XDR definition. We are opting to call all of these events
 // contract events for better clarity to data analytics users.
-func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) ([]ContractEventOutput, error) {
+func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, ids toid.Generator) ([]ContractEventOutput, error) {
+	return TransformContractEventWithOptions(transaction, lhe, ids, ContractEventOptions{})
+}
+
+// ContractEventOptions restricts the contract events that are transformed
+type ContractEventOptions struct {
+	// ContractIDs limits the events to those emitted by these contracts; all events are transformed if it is empty
+	ContractIDs map[xdr.Hash]bool
+	// Filters limits the events to those whose topics match one of the filters; all events are transformed if it is
+	// empty
+	Filters []EventFilter
+}
+
+// TransformContractEventWithOptions is TransformContractEvent with the events restricted by the options. The events
+// that are left out are skipped before their topics and data are decoded.
+func TransformContractEventWithOptions(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, ids toid.Generator, options ContractEventOptions) (_ []ContractEventOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidEvent, transaction, uint32(lhe.Header.LedgerSeq), ""))
+	if err := checkTransactionMeta(transaction.UnsafeMeta, uint32(lhe.Header.LedgerVersion)); err != nil {
+		return []ContractEventOutput{}, err
+	}
 	ledgerHeader := lhe.Header
 	outputTransactionHash := utils.HashToHexString(transaction.Result.TransactionHash)
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
 
 	transactionIndex := uint32(transaction.Index)
 
-	outputTransactionID := toid.New(int32(outputLedgerSequence), int32(transactionIndex), 0).ToInt64()
+	outputTransactionID := ids.New(int32(outputLedgerSequence), int32(transactionIndex), 0)
 
 	outputCloseTime, err := utils.TimePointToUTCTimeStamp(ledgerHeader.ScpValue.CloseTime)
 	if err != nil {
@@ -47,18 +67,43 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 		var outputData interface{}
 		var outputDataDecoded interface{}
 
-		outputInSuccessfulContractCall := contractEvent.InSuccessfulContractCall
 		event := contractEvent.Event
+		if len(options.ContractIDs) > 0 && (event.ContractId == nil || !options.ContractIDs[*event.ContractId]) {
+			continue
+		}
+
+		outputInSuccessfulContractCall := contractEvent.InSuccessfulContractCall
 		outputType := event.Type
 		outputTypeString := event.Type.String()
 
-		eventTopics := getEventTopics(event.Body)
+		eventTopics, err := getEventTopics(event.Body)
+		if err != nil {
+			return []ContractEventOutput{}, err
+		}
+		if !matchesAnyEventFilter(options.Filters, eventTopics) {
+			continue
+		}
+
 		outputTopics, outputTopicsDecoded, err = serializeScValArray(eventTopics)
 		if err != nil {
 			return []ContractEventOutput{}, err
 		}
 
-		eventData := getEventData(event.Body)
+		outputTopicColumns, err := topicColumns(outputTopicsDecoded)
+		if err != nil {
+			return []ContractEventOutput{}, err
+		}
+		var outputTopic0Symbol null.String
+		if len(eventTopics) > 0 {
+			if sym, ok := eventTopics[0].GetSym(); ok {
+				outputTopic0Symbol = null.StringFrom(string(sym))
+			}
+		}
+
+		eventData, err := getEventData(event.Body)
+		if err != nil {
+			return []ContractEventOutput{}, err
+		}
 		outputData, outputDataDecoded, err = serializeScVal(eventData)
 		if err != nil {
 			return []ContractEventOutput{}, err
@@ -77,7 +122,7 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 			return []ContractEventOutput{}, err
 		}
 
-		outputTransactionID := toid.New(int32(outputLedgerSequence), int32(transactionIndex), 0).ToInt64()
+		outputTransactionID := ids.New(int32(outputLedgerSequence), int32(transactionIndex), 0)
 		outputSuccessful := transaction.Result.Successful()
 
 		transformedDiagnosticEvent := ContractEventOutput{
@@ -92,6 +137,11 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 			TypeString:               outputTypeString,
 			Topics:                   outputTopics,
 			TopicsDecoded:            outputTopicsDecoded,
+			Topic0:                   outputTopicColumns[0],
+			Topic1:                   outputTopicColumns[1],
+			Topic2:                   outputTopicColumns[2],
+			Topic3:                   outputTopicColumns[3],
+			Topic0Symbol:             outputTopic0Symbol,
 			Data:                     outputData,
 			DataDecoded:              outputDataDecoded,
 			ContractEventXDR:         outputContractEventXDR,
@@ -103,26 +153,67 @@ func TransformContractEvent(transaction ingest.LedgerTransaction, lhe xdr.Ledger
 	return transformedContractEvents, nil
 }
 
+// ParseContractIDs decodes the strkeys of contracts, such as C..., to their ids. It returns nil when no strkeys are
+// given.
+func ParseContractIDs(addresses []string) (map[xdr.Hash]bool, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+
+	contractIDs := map[xdr.Hash]bool{}
+	for _, address := range addresses {
+		decoded, err := strkey.Decode(strkey.VersionByteContract, address)
+		if err != nil {
+			return nil, fmt.Errorf("invalid contract id %s: %v", address, err)
+		}
+		var contractID xdr.Hash
+		copy(contractID[:], decoded)
+		contractIDs[contractID] = true
+	}
+	return contractIDs, nil
+}
+
+// TransactionTouchesContracts tells whether a transaction invokes one of the contracts, or whether one of them
+// emitted a contract or diagnostic event in it, as when it is called by another contract
+func TransactionTouchesContracts(transaction ingest.LedgerTransaction, contractIDs map[xdr.Hash]bool) bool {
+	for _, operation := range transaction.Envelope.Operations() {
+		invoke, ok := operation.Body.GetInvokeHostFunctionOp()
+		if !ok || invoke.HostFunction.Type != xdr.HostFunctionTypeHostFunctionTypeInvokeContract {
+			continue
+		}
+		if contractID := invoke.HostFunction.MustInvokeContract().ContractAddress.ContractId; contractID != nil && contractIDs[*contractID] {
+			return true
+		}
+	}
+
+	events, err := transaction.GetDiagnosticEvents()
+	if err != nil {
+		return false
+	}
+	for _, event := range events {
+		if event.Event.ContractId != nil && contractIDs[*event.Event.ContractId] {
+			return true
+		}
+	}
+	return false
+}
+
 // TODO this should be a stellar/go/xdr function
-func getEventTopics(eventBody xdr.ContractEventBody) []xdr.ScVal {
-	switch eventBody.V {
-	case 0:
-		contractEventV0 := eventBody.MustV0()
-		return contractEventV0.Topics
-	default:
-		panic("unsupported event body version: " + string(eventBody.V))
+func getEventTopics(eventBody xdr.ContractEventBody) ([]xdr.ScVal, error) {
+	contractEventV0, ok := eventBody.GetV0()
+	if !ok {
+		return nil, versionError("contract event body V%d is not supported", eventBody.V)
 	}
+	return contractEventV0.Topics, nil
 }
 
 // TODO this should be a stellar/go/xdr function
-func getEventData(eventBody xdr.ContractEventBody) xdr.ScVal {
-	switch eventBody.V {
-	case 0:
-		contractEventV0 := eventBody.MustV0()
-		return contractEventV0.Data
-	default:
-		panic("unsupported event body version: " + string(eventBody.V))
+func getEventData(eventBody xdr.ContractEventBody) (xdr.ScVal, error) {
+	contractEventV0, ok := eventBody.GetV0()
+	if !ok {
+		return xdr.ScVal{}, versionError("contract event body V%d is not supported", eventBody.V)
 	}
+	return contractEventV0.Data, nil
 }
 
 func serializeScVal(scVal xdr.ScVal) (interface{}, interface{}, error) {
@@ -167,3 +258,21 @@ func serializeScValArray(scVals []xdr.ScVal) ([]interface{}, []interface{}, erro
 
 	return data, dataDecoded, nil
 }
+
+// topicColumns returns the first four decoded topics as canonical JSON strings, so that they can be compared as
+// plain strings. Events have at most four topics; the columns of missing topics are null.
+func topicColumns(topicsDecoded []interface{}) ([4]null.String, error) {
+	var columns [4]null.String
+	for i, topic := range topicsDecoded {
+		if i >= len(columns) {
+			break
+		}
+		canonical, err := CanonicalJSON(topic)
+		if err != nil {
+			return columns, err
+		}
+		columns[i] = null.StringFrom(string(canonical))
+	}
+
+	return columns, nil
+}
@@ -5,10 +5,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 func TestTransformContractEvent(t *testing.T) {
@@ -38,12 +41,74 @@ func TestTransformContractEvent(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformContractEvent(test.input.transaction, test.input.historyHeader)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformContractEvent(test.input.transaction, test.input.historyHeader, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
 
+func TestTransformContractEventWithOptions(t *testing.T) {
+	transactions, headers, err := makeContractEventTestInput()
+	assert.NoError(t, err)
+	wantOutput, err := makeContractEventTestOutput()
+	assert.NoError(t, err)
+
+	// the test event is emitted by the all zero contract id
+	contractIDs, err := ParseContractIDs([]string{"CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4"})
+	assert.NoError(t, err)
+	actualOutput, err := TransformContractEventWithOptions(transactions[0], headers[0], toid.Generator{}, ContractEventOptions{ContractIDs: contractIDs})
+	assert.NoError(t, err)
+	assert.Equal(t, wantOutput[0], actualOutput)
+	assert.True(t, TransactionTouchesContracts(transactions[0], contractIDs))
+
+	otherIDs := map[xdr.Hash]bool{{1}: true}
+	actualOutput, err = TransformContractEventWithOptions(transactions[0], headers[0], toid.Generator{}, ContractEventOptions{ContractIDs: otherIDs})
+	assert.NoError(t, err)
+	assert.Empty(t, actualOutput)
+	assert.False(t, TransactionTouchesContracts(transactions[0], otherIDs))
+
+	// the topic of the test event is the boolean true
+	filters, err := ParseEventFilters([]string{"topic0=AAAAAAAAAAE="})
+	assert.NoError(t, err)
+	actualOutput, err = TransformContractEventWithOptions(transactions[0], headers[0], toid.Generator{}, ContractEventOptions{Filters: filters})
+	assert.NoError(t, err)
+	assert.Equal(t, wantOutput[0], actualOutput)
+
+	filters, err = ParseEventFilters([]string{"topic0=transfer"})
+	assert.NoError(t, err)
+	actualOutput, err = TransformContractEventWithOptions(transactions[0], headers[0], toid.Generator{}, ContractEventOptions{Filters: filters})
+	assert.NoError(t, err)
+	assert.Empty(t, actualOutput)
+}
+
+func TestParseContractIDs(t *testing.T) {
+	contractIDs, err := ParseContractIDs(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, contractIDs)
+
+	contractIDs, err = ParseContractIDs([]string{"CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[xdr.Hash]bool{{}: true}, contractIDs)
+
+	_, err = ParseContractIDs([]string{"GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"})
+	assert.Error(t, err)
+}
+
+func TestTopicColumns(t *testing.T) {
+	columns, err := topicColumns([]interface{}{
+		json.RawMessage(`{"symbol": "transfer"}`),
+		json.RawMessage(`{"address":"GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"}`),
+		json.RawMessage(`{"i128":{"lo":1,"hi":0}}`),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, [4]null.String{
+		null.StringFrom(`{"symbol":"transfer"}`),
+		null.StringFrom(`{"address":"GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7"}`),
+		null.StringFrom(`{"i128":{"hi":0,"lo":1}}`),
+		{},
+	}, columns)
+}
+
 func makeContractEventTestOutput() (output [][]ContractEventOutput, err error) {
 
 	var topics, topicsDecoded []interface{}
@@ -67,6 +132,7 @@ func makeContractEventTestOutput() (output [][]ContractEventOutput, err error) {
 			TypeString:               "ContractEventTypeDiagnostic",
 			Topics:                   topics,
 			TopicsDecoded:            topicsDecoded,
+			Topic0:                   null.StringFrom("{\"bool\":true}"),
 			Data:                     data,
 			DataDecoded:              dataDecoded,
 			ContractEventXDR:         "AAAAAQAAAAAAAAABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACAAAAAAAAAAEAAAAAAAAAAQAAAAAAAAAB",
@@ -182,8 +248,9 @@ func makeContractEventTestInput() (transaction []ingest.LedgerTransaction, histo
 	historyHeader = []xdr.LedgerHeaderHistoryEntry{
 		{
 			Header: xdr.LedgerHeader{
-				LedgerSeq: 30521816,
-				ScpValue:  xdr.StellarValue{CloseTime: 1594272522},
+				LedgerSeq:     30521816,
+				ScpValue:      xdr.StellarValue{CloseTime: 1594272522},
+				LedgerVersion: 22,
 			},
 		},
 	}
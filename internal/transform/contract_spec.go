@@ -0,0 +1,222 @@
+package transform
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+)
+
+// Names of the custom sections written by the Soroban SDK into contract Wasm
+const (
+	wasmSectionContractSpec    = "contractspecv0"
+	wasmSectionContractEnvMeta = "contractenvmetav0"
+	wasmSectionContractMeta    = "contractmetav0"
+)
+
+// Keys of the contractmetav0 entries written by the Rust SDK
+const (
+	contractMetaRustVersion    = "rsver"
+	contractMetaRustSdkVersion = "rssdkver"
+)
+
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d}
+
+// contractWasmMeta is the interface and build metadata read from the custom sections of a contract Wasm
+type contractWasmMeta struct {
+	envInterfaceProtocol   null.Int
+	envInterfacePreRelease null.Int
+	rustVersion            null.String
+	rustSdkVersion         null.String
+	meta                   map[string]string
+	functions              []ContractFunction
+}
+
+// parseContractWasmMeta reads the spec, environment metadata and contract metadata of a contract Wasm. The sections
+// are decoded as streams of xdr entries; a section that is not valid xdr keeps the entries read before the invalid
+// one, since custom sections are not validated by core.
+func parseContractWasmMeta(code []byte) (contractWasmMeta, error) {
+	sections, err := wasmCustomSections(code)
+	if err != nil {
+		return contractWasmMeta{}, err
+	}
+
+	var wasmMeta contractWasmMeta
+	decoder := xdr.NewBytesDecoder()
+
+	envMeta := sections[wasmSectionContractEnvMeta]
+	for len(envMeta) > 0 {
+		var entry xdr.ScEnvMetaEntry
+		read, err := decoder.DecodeBytes(&entry, envMeta)
+		if err != nil {
+			break
+		}
+		envMeta = envMeta[read:]
+		if version, ok := entry.GetInterfaceVersion(); ok {
+			wasmMeta.envInterfaceProtocol = null.IntFrom(int64(version.Protocol))
+			wasmMeta.envInterfacePreRelease = null.IntFrom(int64(version.PreRelease))
+		}
+	}
+
+	meta := sections[wasmSectionContractMeta]
+	for len(meta) > 0 {
+		var entry xdr.ScMetaEntry
+		read, err := decoder.DecodeBytes(&entry, meta)
+		if err != nil {
+			break
+		}
+		meta = meta[read:]
+		metaV0, ok := entry.GetV0()
+		if !ok {
+			continue
+		}
+		if wasmMeta.meta == nil {
+			wasmMeta.meta = map[string]string{}
+		}
+		wasmMeta.meta[metaV0.Key] = metaV0.Val
+		switch metaV0.Key {
+		case contractMetaRustVersion:
+			wasmMeta.rustVersion = null.StringFrom(metaV0.Val)
+		case contractMetaRustSdkVersion:
+			wasmMeta.rustSdkVersion = null.StringFrom(metaV0.Val)
+		}
+	}
+
+	spec := sections[wasmSectionContractSpec]
+	for len(spec) > 0 {
+		var entry xdr.ScSpecEntry
+		read, err := decoder.DecodeBytes(&entry, spec)
+		if err != nil {
+			break
+		}
+		spec = spec[read:]
+		function, ok := entry.GetFunctionV0()
+		if !ok {
+			continue
+		}
+		wasmMeta.functions = append(wasmMeta.functions, transformContractFunction(function))
+	}
+
+	return wasmMeta, nil
+}
+
+// wasmCustomSections returns the contents of the custom sections of a Wasm module by name. Sections with the same
+// name are concatenated, as the linker does.
+func wasmCustomSections(code []byte) (map[string][]byte, error) {
+	if len(code) < 8 || !bytes.Equal(code[:4], wasmMagic) {
+		return nil, fmt.Errorf("contract code is not a wasm module")
+	}
+
+	sections := map[string][]byte{}
+	offset := 8
+	for offset < len(code) {
+		sectionID := code[offset]
+		offset++
+		size, read, err := readWasmVarUint32(code[offset:])
+		if err != nil {
+			return nil, fmt.Errorf("could not read the size of wasm section %d: %v", sectionID, err)
+		}
+		offset += read
+		if uint64(offset)+uint64(size) > uint64(len(code)) {
+			return nil, fmt.Errorf("wasm section %d of %d bytes overflows the module", sectionID, size)
+		}
+		section := code[offset : offset+int(size)]
+		offset += int(size)
+
+		if sectionID != 0 {
+			continue
+		}
+		nameLength, read, err := readWasmVarUint32(section)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the name of a wasm custom section: %v", err)
+		}
+		if uint64(read)+uint64(nameLength) > uint64(len(section)) {
+			return nil, fmt.Errorf("the name of a wasm custom section overflows the section")
+		}
+		name := string(section[read : read+int(nameLength)])
+		sections[name] = append(sections[name], section[read+int(nameLength):]...)
+	}
+
+	return sections, nil
+}
+
+// readWasmVarUint32 reads an unsigned LEB128 integer, returning it along with the number of bytes read
+func readWasmVarUint32(b []byte) (uint32, int, error) {
+	var value uint32
+	for i := 0; i < 5; i++ {
+		if i >= len(b) {
+			return 0, 0, fmt.Errorf("unexpected end of leb128 integer")
+		}
+		value |= uint32(b[i]&0x7f) << (7 * i)
+		if b[i]&0x80 == 0 {
+			return value, i + 1, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("leb128 integer is longer than 5 bytes")
+}
+
+func transformContractFunction(function xdr.ScSpecFunctionV0) ContractFunction {
+	inputs := make([]ContractFunctionInput, 0, len(function.Inputs))
+	for _, input := range function.Inputs {
+		inputs = append(inputs, ContractFunctionInput{Name: input.Name, Type: scSpecTypeName(input.Type)})
+	}
+	outputs := make([]string, 0, len(function.Outputs))
+	for _, output := range function.Outputs {
+		outputs = append(outputs, scSpecTypeName(output))
+	}
+	return ContractFunction{Name: string(function.Name), Inputs: inputs, Outputs: outputs}
+}
+
+var scSpecTypeNames = map[xdr.ScSpecType]string{
+	xdr.ScSpecTypeScSpecTypeVal:       "val",
+	xdr.ScSpecTypeScSpecTypeBool:      "bool",
+	xdr.ScSpecTypeScSpecTypeVoid:      "void",
+	xdr.ScSpecTypeScSpecTypeError:     "error",
+	xdr.ScSpecTypeScSpecTypeU32:       "u32",
+	xdr.ScSpecTypeScSpecTypeI32:       "i32",
+	xdr.ScSpecTypeScSpecTypeU64:       "u64",
+	xdr.ScSpecTypeScSpecTypeI64:       "i64",
+	xdr.ScSpecTypeScSpecTypeTimepoint: "timepoint",
+	xdr.ScSpecTypeScSpecTypeDuration:  "duration",
+	xdr.ScSpecTypeScSpecTypeU128:      "u128",
+	xdr.ScSpecTypeScSpecTypeI128:      "i128",
+	xdr.ScSpecTypeScSpecTypeU256:      "u256",
+	xdr.ScSpecTypeScSpecTypeI256:      "i256",
+	xdr.ScSpecTypeScSpecTypeBytes:     "bytes",
+	xdr.ScSpecTypeScSpecTypeString:    "string",
+	xdr.ScSpecTypeScSpecTypeSymbol:    "symbol",
+	xdr.ScSpecTypeScSpecTypeAddress:   "address",
+}
+
+// scSpecTypeName renders a spec type the way the Rust SDK names it, such as vec<address> or option<i128>.
+// User defined types are rendered with their name.
+func scSpecTypeName(def xdr.ScSpecTypeDef) string {
+	if name, ok := scSpecTypeNames[def.Type]; ok {
+		return name
+	}
+
+	switch def.Type {
+	case xdr.ScSpecTypeScSpecTypeOption:
+		return fmt.Sprintf("option<%s>", scSpecTypeName(def.Option.ValueType))
+	case xdr.ScSpecTypeScSpecTypeResult:
+		return fmt.Sprintf("result<%s,%s>", scSpecTypeName(def.Result.OkType), scSpecTypeName(def.Result.ErrorType))
+	case xdr.ScSpecTypeScSpecTypeVec:
+		return fmt.Sprintf("vec<%s>", scSpecTypeName(def.Vec.ElementType))
+	case xdr.ScSpecTypeScSpecTypeMap:
+		return fmt.Sprintf("map<%s,%s>", scSpecTypeName(def.Map.KeyType), scSpecTypeName(def.Map.ValueType))
+	case xdr.ScSpecTypeScSpecTypeTuple:
+		names := make([]string, 0, len(def.Tuple.ValueTypes))
+		for _, valueType := range def.Tuple.ValueTypes {
+			names = append(names, scSpecTypeName(valueType))
+		}
+		return fmt.Sprintf("tuple<%s>", strings.Join(names, ","))
+	case xdr.ScSpecTypeScSpecTypeBytesN:
+		return fmt.Sprintf("bytes_n<%d>", def.BytesN.N)
+	case xdr.ScSpecTypeScSpecTypeUdt:
+		return def.Udt.Name
+	default:
+		return def.Type.String()
+	}
+}
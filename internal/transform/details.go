@@ -0,0 +1,54 @@
+package transform
+
+import (
+	"math"
+	"reflect"
+)
+
+// normalizeDetails rewrites the values of the details of an operation or effect, and of the objects nested in them,
+// to plain Go types: the integers, such as xdr.Int64 offer ids, xdr.Uint32 thresholds and xdr.SequenceNumber, to int64,
+// or uint64 for the unsigned ones that do not fit in an int64, and the named strings to string. The details are built from xdr
+// values all over the transforms, and normalizing them once here means that the output encoders only ever see the
+// same few types whatever the operation. The details are rewritten in place and returned.
+func normalizeDetails(details map[string]interface{}) map[string]interface{} {
+	for key, value := range details {
+		details[key] = normalizeDetail(value)
+	}
+	return details
+}
+
+func normalizeDetail(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, int64, float64:
+		return v
+	case map[string]interface{}:
+		return normalizeDetails(v)
+	case []map[string]interface{}:
+		for _, item := range v {
+			normalizeDetails(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeDetail(item)
+		}
+		return v
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflected.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if unsigned := reflected.Uint(); unsigned > math.MaxInt64 {
+			return unsigned
+		}
+		return int64(reflected.Uint())
+	case reflect.Float64:
+		return reflected.Float()
+	case reflect.String:
+		return reflected.String()
+	default:
+		return value
+	}
+}
@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeDetails(t *testing.T) {
+	details := map[string]interface{}{
+		"offer_id":          xdr.Int64(10072128),
+		"low_threshold":     xdr.Uint32(1),
+		"master_key_weight": int32(3),
+		"new_seq":           xdr.SequenceNumber(300000000000),
+		"muxed_id":          uint64(math.MaxUint64),
+		"name":              xdr.String64("hello"),
+		"amount":            "10.0000000",
+		"price":             2.5,
+		"authorize":         true,
+		"value":             nil,
+		"path":              []Path{{AssetType: "native"}},
+		"claimants": []map[string]interface{}{
+			{"weight": xdr.Uint32(2)},
+		},
+		"nested": map[string]interface{}{
+			"items": []interface{}{xdr.Int32(-1), xdr.Uint64(7)},
+		},
+	}
+
+	assert.Equal(t, map[string]interface{}{
+		"offer_id":          int64(10072128),
+		"low_threshold":     int64(1),
+		"master_key_weight": int64(3),
+		"new_seq":           int64(300000000000),
+		"muxed_id":          uint64(math.MaxUint64),
+		"name":              "hello",
+		"amount":            "10.0000000",
+		"price":             2.5,
+		"authorize":         true,
+		"value":             nil,
+		"path":              []Path{{AssetType: "native"}},
+		"claimants": []map[string]interface{}{
+			{"weight": int64(2)},
+		},
+		"nested": map[string]interface{}{
+			"items": []interface{}{int64(-1), int64(7)},
+		},
+	}, normalizeDetails(details))
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/guregu/null"
 	"github.com/stellar/go/amount"
@@ -17,11 +18,46 @@ import (
 	"github.com/stellar/go/support/contractevents"
 	"github.com/stellar/go/support/errors"
 	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/ledgerkey"
 )
 
-func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string) ([]EffectOutput, error) {
+func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string, ids toid.Generator) ([]EffectOutput, error) {
+	return TransformEffectWithOptions(transaction, ledgerSeq, ledgerCloseMeta, networkPassphrase, ids, EffectOptions{})
+}
+
+// EffectOptions enables effects that are not emitted by default, since horizon does not have them
+type EffectOptions struct {
+	// OfferSponsorships emits sponsorship created, updated and removed effects for offer entries
+	OfferSponsorships bool
+	// UnfilledOffers emits offer created effects for the offers that manage offer operations create without
+	// crossing any offer or pool
+	UnfilledOffers bool
+	// ZeroFills emits trade zero fill effects for the claims of offers whose amounts were both rounded down to zero,
+	// which horizon skips
+	ZeroFills bool
+	// OperationTypes limits the effects to those of the operations of these types; all operations have effects if
+	// it is empty
+	OperationTypes map[xdr.OperationType]bool
+	// ContractIDs limits the effects to those of the transactions that invoke these contracts or in which they emit
+	// events; all transactions have effects if it is empty
+	ContractIDs map[xdr.Hash]bool
+}
+
+// TransformEffectWithOptions is TransformEffect with the opt-in effects of the options
+func TransformEffectWithOptions(transaction ingest.LedgerTransaction, ledgerSeq uint32, ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string, ids toid.Generator, options EffectOptions) (_ []EffectOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidEffect, transaction, ledgerSeq, networkPassphrase))
 	effects := []EffectOutput{}
+	if protocolVersion, ok := closeMetaProtocolVersion(ledgerCloseMeta); ok {
+		if err := checkTransactionMeta(transaction.UnsafeMeta, protocolVersion); err != nil {
+			return effects, err
+		}
+	}
+
+	if len(options.ContractIDs) > 0 && !TransactionTouchesContracts(transaction, options.ContractIDs) {
+		return effects, nil
+	}
 
 	outputCloseTime, err := utils.GetCloseTime(ledgerCloseMeta)
 	if err != nil {
@@ -29,18 +65,26 @@ func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, led
 	}
 
 	for opi, op := range transaction.Envelope.Operations() {
+		if len(options.OperationTypes) > 0 && !options.OperationTypes[op.Body.Type] {
+			continue
+		}
+
 		operation := transactionOperationWrapper{
 			index:          uint32(opi),
 			transaction:    transaction,
 			operation:      op,
 			ledgerSequence: ledgerSeq,
+			ids:            ids,
 			network:        networkPassphrase,
 			ledgerClosed:   outputCloseTime,
+			effectOptions:  options,
 		}
 
 		p, err := operation.effects()
 		if err != nil {
-			return effects, errors.Wrapf(err, "reading operation %v effects", operation.ID())
+			coordinates := operationCoordinates(ErrorCodeInvalidEffect, op, int32(opi), transaction, ledgerSeq, networkPassphrase)
+			coordinates.Err = err
+			return effects, &coordinates
 		}
 
 		effects = append(effects, p...)
@@ -50,6 +94,11 @@ func TransformEffect(transaction ingest.LedgerTransaction, ledgerSeq uint32, led
 	return effects, nil
 }
 
+// errMissingResult is returned when an operation result does not have the arm of the operation type
+func errMissingResult(arm string) error {
+	return fmt.Errorf("operation result is missing %s", arm)
+}
+
 // Effects returns the operation effects
 func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error) {
 	if !operation.transaction.Result.Successful() {
@@ -60,10 +109,11 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 		err error
 	)
 
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	index, err := operation.operationChanges()
 	if err != nil {
 		return nil, err
 	}
+	changes := index.changes
 
 	wrapper := &effectsWrapper{
 		effects:   []EffectOutput{},
@@ -92,23 +142,23 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 	case xdr.OperationTypeAllowTrust:
 		err = wrapper.addAllowTrustEffects()
 	case xdr.OperationTypeAccountMerge:
-		wrapper.addAccountMergeEffects()
+		err = wrapper.addAccountMergeEffects()
 	case xdr.OperationTypeInflation:
-		wrapper.addInflationEffects()
+		err = wrapper.addInflationEffects()
 	case xdr.OperationTypeManageData:
 		err = wrapper.addManageDataEffects()
 	case xdr.OperationTypeBumpSequence:
 		err = wrapper.addBumpSequenceEffects()
 	case xdr.OperationTypeCreateClaimableBalance:
-		err = wrapper.addCreateClaimableBalanceEffects(changes)
+		err = wrapper.addCreateClaimableBalanceEffects(index)
 	case xdr.OperationTypeClaimClaimableBalance:
-		err = wrapper.addClaimClaimableBalanceEffects(changes)
+		err = wrapper.addClaimClaimableBalanceEffects(index)
 	case xdr.OperationTypeBeginSponsoringFutureReserves, xdr.OperationTypeEndSponsoringFutureReserves, xdr.OperationTypeRevokeSponsorship:
 	// The effects of these operations are obtained  indirectly from the ledger entries
 	case xdr.OperationTypeClawback:
 		err = wrapper.addClawbackEffects()
 	case xdr.OperationTypeClawbackClaimableBalance:
-		err = wrapper.addClawbackClaimableBalanceEffects(changes)
+		err = wrapper.addClawbackClaimableBalanceEffects(index)
 	case xdr.OperationTypeSetTrustLineFlags:
 		err = wrapper.addSetTrustLineFlagsEffects()
 	case xdr.OperationTypeLiquidityPoolDeposit:
@@ -160,6 +210,9 @@ func (operation *transactionOperationWrapper) effects() ([]EffectOutput, error)
 		wrapper.effects[i].LedgerSequence = operation.ledgerSequence
 		wrapper.effects[i].EffectIndex = uint32(i)
 		wrapper.effects[i].EffectId = fmt.Sprintf("%d-%d", wrapper.effects[i].OperationID, wrapper.effects[i].EffectIndex)
+		// Horizon numbers the effects of an operation from 1 in its cursors
+		wrapper.effects[i].PagingToken = fmt.Sprintf("%d-%d", wrapper.effects[i].OperationID, wrapper.effects[i].EffectIndex+1)
+		wrapper.effects[i].TransactionSuccessful = operation.transaction.Result.Successful()
 	}
 
 	return wrapper.effects, nil
@@ -171,6 +224,12 @@ type effectsWrapper struct {
 }
 
 func (e *effectsWrapper) add(address string, addressMuxed null.String, effectType EffectType, details map[string]interface{}) {
+	if e.operation.network != "" {
+		addAssetContractIDs(details, e.operation.network)
+	}
+	normalizeDetails(details)
+	addOfferIDStrings(details)
+
 	e.effects = append(e.effects, EffectOutput{
 		Address:      address,
 		AddressMuxed: addressMuxed,
@@ -178,9 +237,46 @@ func (e *effectsWrapper) add(address string, addressMuxed null.String, effectTyp
 		TypeString:   EffectTypeNames[effectType],
 		Type:         int32(effectType),
 		Details:      details,
+		Category:     EffectCategories[effectType],
 	})
 }
 
+// addOfferIDStrings adds every offer id of the details as a decimal string next to it, with the same key and a _str
+// suffix, like horizon does, since javascript consumers of the json output lose the precision of the ids above 2^53
+func addOfferIDStrings(details map[string]interface{}) {
+	for key, value := range details {
+		if id, ok := value.(int64); ok && strings.HasSuffix(key, "offer_id") {
+			details[key+"_str"] = strconv.FormatInt(id, 10)
+		}
+	}
+}
+
+// addAssetContractIDs adds the address of the Stellar Asset Contract of every classic asset in the details, with the
+// same prefix as the other details of the asset
+func addAssetContractIDs(details map[string]interface{}, passphrase string) {
+	for key, value := range details {
+		if !strings.HasSuffix(key, "asset_type") {
+			continue
+		}
+		assetType, ok := value.(string)
+		if !ok || !isClassicAssetType(assetType) {
+			continue
+		}
+		prefix := strings.TrimSuffix(key, "asset_type")
+		code, _ := details[prefix+"asset_code"].(string)
+		issuer, _ := details[prefix+"asset_issuer"].(string)
+		asset, err := xdr.BuildAsset(assetType, issuer, code)
+		if err != nil {
+			continue
+		}
+		contractID, err := utils.AssetContractID(asset, passphrase)
+		if err != nil {
+			continue
+		}
+		details[prefix+"asset_contract_id"] = contractID
+	}
+}
+
 func (e *effectsWrapper) addUnmuxed(address *xdr.AccountId, effectType EffectType, details map[string]interface{}) {
 	e.add(address.Address(), null.String{}, effectType, details)
 }
@@ -194,9 +290,12 @@ func (e *effectsWrapper) addMuxed(address *xdr.MuxedAccount, effectType EffectTy
 	e.add(accID.Address(), addressMuxed, effectType, details)
 }
 
-var sponsoringEffectsTable = map[xdr.LedgerEntryType]struct {
+// sponsoringEffects are the effect types of the sponsorship changes of a ledger entry type
+type sponsoringEffects struct {
 	created, updated, removed EffectType
-}{
+}
+
+var sponsoringEffectsTable = map[xdr.LedgerEntryType]sponsoringEffects{
 	xdr.LedgerEntryTypeAccount: {
 		created: EffectAccountSponsorshipCreated,
 		updated: EffectAccountSponsorshipUpdated,
@@ -220,6 +319,13 @@ var sponsoringEffectsTable = map[xdr.LedgerEntryType]struct {
 
 	// We intentionally don't have Sponsoring effects for Offer
 	// entries because we don't generate creation effects for them.
+	// They are opt-in with EffectOptions.OfferSponsorships.
+}
+
+var offerSponsoringEffects = sponsoringEffects{
+	created: EffectOfferSponsorshipCreated,
+	updated: EffectOfferSponsorshipUpdated,
+	removed: EffectOfferSponsorshipRemoved,
 }
 
 func (e *effectsWrapper) addSignerSponsorshipEffects(change ingest.Change) {
@@ -286,6 +392,9 @@ func (e *effectsWrapper) addSignerSponsorshipEffects(change ingest.Change) {
 
 func (e *effectsWrapper) addLedgerEntrySponsorshipEffects(change ingest.Change) error {
 	effectsForEntryType, found := sponsoringEffectsTable[change.Type]
+	if !found && change.Type == xdr.LedgerEntryTypeOffer && e.operation.effectOptions.OfferSponsorships {
+		effectsForEntryType, found = offerSponsoringEffects, true
+	}
 	if !found {
 		return nil
 	}
@@ -351,6 +460,10 @@ func (e *effectsWrapper) addLedgerEntrySponsorshipEffects(change ingest.Change)
 		if err != nil {
 			return errors.Wrapf(err, "Invalid balanceId in change from op %d", e.operation.index)
 		}
+	case xdr.LedgerEntryTypeOffer:
+		offer := data.MustOffer()
+		accountID = &offer.SellerId
+		details["offer_id"] = int64(offer.OfferId)
 	case xdr.LedgerEntryTypeLiquidityPool:
 		// liquidity pools cannot be sponsored
 		fallthrough
@@ -413,6 +526,7 @@ func (e *effectsWrapper) addAccountCreatedEffects() {
 		EffectAccountDebited,
 		map[string]interface{}{
 			"asset_type": "native",
+			"asset_id":   FarmHashAsset("", "", "native"),
 			"amount":     amount.String(op.StartingBalance),
 		},
 	)
@@ -446,7 +560,18 @@ func (e *effectsWrapper) addPaymentEffects() {
 
 func (e *effectsWrapper) pathPaymentStrictReceiveEffects() error {
 	op := e.operation.operation.Body.MustPathPaymentStrictReceiveOp()
-	resultSuccess := e.operation.OperationResult().MustPathPaymentStrictReceiveResult().MustSuccess()
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetPathPaymentStrictReceiveResult()
+	if !ok {
+		return errMissingResult("PathPaymentStrictReceiveResult")
+	}
+	resultSuccess, ok := result.GetSuccess()
+	if !ok {
+		return errMissingResult("PathPaymentStrictReceiveResult success")
+	}
 	source := e.operation.SourceAccount()
 
 	details := map[string]interface{}{"amount": amount.String(op.DestAmount)}
@@ -458,7 +583,6 @@ func (e *effectsWrapper) pathPaymentStrictReceiveEffects() error {
 		details,
 	)
 
-	result := e.operation.OperationResult().MustPathPaymentStrictReceiveResult()
 	details = map[string]interface{}{"amount": amount.String(result.SendAmount())}
 	addAssetDetails(details, op.SendAsset, "")
 
@@ -474,8 +598,18 @@ func (e *effectsWrapper) pathPaymentStrictReceiveEffects() error {
 func (e *effectsWrapper) addPathPaymentStrictSendEffects() error {
 	source := e.operation.SourceAccount()
 	op := e.operation.operation.Body.MustPathPaymentStrictSendOp()
-	resultSuccess := e.operation.OperationResult().MustPathPaymentStrictSendResult().MustSuccess()
-	result := e.operation.OperationResult().MustPathPaymentStrictSendResult()
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetPathPaymentStrictSendResult()
+	if !ok {
+		return errMissingResult("PathPaymentStrictSendResult")
+	}
+	resultSuccess, ok := result.GetSuccess()
+	if !ok {
+		return errMissingResult("PathPaymentStrictSendResult success")
+	}
 
 	details := map[string]interface{}{"amount": amount.String(result.DestAmount())}
 	addAssetDetails(details, op.DestAsset, "")
@@ -490,31 +624,107 @@ func (e *effectsWrapper) addPathPaymentStrictSendEffects() error {
 
 func (e *effectsWrapper) addManageSellOfferEffects() error {
 	source := e.operation.SourceAccount()
-	result := e.operation.OperationResult().MustManageSellOfferResult().MustSuccess()
-	return e.addIngestTradeEffects(*source, result.OffersClaimed, false)
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetManageSellOfferResult()
+	if !ok {
+		return errMissingResult("ManageSellOfferResult")
+	}
+	success, ok := result.GetSuccess()
+	if !ok {
+		return errMissingResult("ManageSellOfferResult success")
+	}
+	if err := e.addIngestTradeEffects(*source, success.OffersClaimed, false); err != nil {
+		return err
+	}
+	return e.addUnfilledOfferCreatedEffects(success.OffersClaimed)
 }
 
 func (e *effectsWrapper) addManageBuyOfferEffects() error {
 	source := e.operation.SourceAccount()
-	result := e.operation.OperationResult().MustManageBuyOfferResult().MustSuccess()
-	return e.addIngestTradeEffects(*source, result.OffersClaimed, false)
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetManageBuyOfferResult()
+	if !ok {
+		return errMissingResult("ManageBuyOfferResult")
+	}
+	success, ok := result.GetSuccess()
+	if !ok {
+		return errMissingResult("ManageBuyOfferResult success")
+	}
+	if err := e.addIngestTradeEffects(*source, success.OffersClaimed, false); err != nil {
+		return err
+	}
+	return e.addUnfilledOfferCreatedEffects(success.OffersClaimed)
 }
 
 func (e *effectsWrapper) addCreatePassiveSellOfferEffect() error {
-	result := e.operation.OperationResult()
+	result, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
 	source := e.operation.SourceAccount()
 
-	var claims []xdr.ClaimAtom
-
 	// KNOWN ISSUE:  stellar-core creates results for CreatePassiveOffer operations
 	// with the wrong result arm set.
-	if result.Type == xdr.OperationTypeManageSellOffer {
-		claims = result.MustManageSellOfferResult().MustSuccess().OffersClaimed
-	} else {
-		claims = result.MustCreatePassiveSellOfferResult().MustSuccess().OffersClaimed
+	offerResult, ok := result.GetManageSellOfferResult()
+	if result.Type != xdr.OperationTypeManageSellOffer {
+		offerResult, ok = result.GetCreatePassiveSellOfferResult()
 	}
+	if !ok {
+		return errMissingResult("CreatePassiveSellOfferResult")
+	}
+	success, ok := offerResult.GetSuccess()
+	if !ok {
+		return errMissingResult("CreatePassiveSellOfferResult success")
+	}
+	claims := success.OffersClaimed
 
-	return e.addIngestTradeEffects(*source, claims, false)
+	if err := e.addIngestTradeEffects(*source, claims, false); err != nil {
+		return err
+	}
+	return e.addUnfilledOfferCreatedEffects(claims)
+}
+
+// addUnfilledOfferCreatedEffects adds an offer created effect for the offer created by a manage offer operation
+// that claimed nothing, read from the meta changes of the operation. Offers that crossed others already have their
+// offer created effect along with the trade effects.
+func (e *effectsWrapper) addUnfilledOfferCreatedEffects(claims []xdr.ClaimAtom) error {
+	if !e.operation.effectOptions.UnfilledOffers || len(claims) > 0 {
+		return nil
+	}
+
+	index, err := e.operation.operationChanges()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range index.ofType(xdr.LedgerEntryTypeOffer) {
+		if change.Pre != nil || change.Post == nil {
+			continue
+		}
+
+		offer := change.Post.Data.MustOffer()
+		details := map[string]interface{}{
+			"offer_id": int64(offer.OfferId),
+			"amount":   amount.String(offer.Amount),
+		}
+		if err := addAssetDetails(details, offer.Selling, "selling_"); err != nil {
+			return err
+		}
+		if err := addAssetDetails(details, offer.Buying, "buying_"); err != nil {
+			return err
+		}
+		if err := addPriceDetails(details, offer.Price, ""); err != nil {
+			return err
+		}
+		e.addMuxed(e.operation.SourceAccount(), EffectOfferCreated, details)
+	}
+	return nil
 }
 
 func (e *effectsWrapper) addSetOptionsEffects() error {
@@ -566,24 +776,34 @@ func (e *effectsWrapper) addSetOptionsEffects() error {
 			},
 		)
 	}
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
 
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeAccount {
-			continue
-		}
-
+	for _, change := range index.ofType(xdr.LedgerEntryTypeAccount) {
 		beforeAccount := change.Pre.Data.MustAccount()
 		afterAccount := change.Post.Data.MustAccount()
 
 		before := beforeAccount.SignerSummary()
 		after := afterAccount.SignerSummary()
 
+		// The signer summaries leave out a master key of weight 0, so changes of the master key weight are tracked
+		// apart to make sure each gets a signer effect for the account's own key
+		masterKey := afterAccount.AccountId.Address()
+		masterKeyWeight := int32(afterAccount.MasterKeyWeight())
+		masterKeyWeightChanged := int32(beforeAccount.MasterKeyWeight()) != masterKeyWeight
+		masterKeyEffect := false
+		signerDetails := func(addy string, details map[string]interface{}) map[string]interface{} {
+			if addy == masterKey && masterKeyWeightChanged {
+				details["master_key_weight"] = masterKeyWeight
+				masterKeyEffect = true
+			}
+			return details
+		}
+
 		// if before and after are the same, the signers have not changed
-		if reflect.DeepEqual(before, after) {
+		if reflect.DeepEqual(before, after) && !masterKeyWeightChanged {
 			continue
 		}
 
@@ -596,17 +816,17 @@ func (e *effectsWrapper) addSetOptionsEffects() error {
 		for _, addy := range beforeSortedSigners {
 			weight, ok := after[addy]
 			if !ok {
-				e.addMuxed(source, EffectSignerRemoved, map[string]interface{}{
+				e.addMuxed(source, EffectSignerRemoved, signerDetails(addy, map[string]interface{}{
 					"public_key": addy,
-				})
+				}))
 				continue
 			}
 
 			if weight != before[addy] {
-				e.addMuxed(source, EffectSignerUpdated, map[string]interface{}{
+				e.addMuxed(source, EffectSignerUpdated, signerDetails(addy, map[string]interface{}{
 					"public_key": addy,
 					"weight":     weight,
-				})
+				}))
 			}
 		}
 
@@ -625,9 +845,17 @@ func (e *effectsWrapper) addSetOptionsEffects() error {
 				continue
 			}
 
-			e.addMuxed(source, EffectSignerCreated, map[string]interface{}{
+			e.addMuxed(source, EffectSignerCreated, signerDetails(addy, map[string]interface{}{
 				"public_key": addy,
 				"weight":     weight,
+			}))
+		}
+
+		if masterKeyWeightChanged && !masterKeyEffect {
+			e.addMuxed(source, EffectSignerUpdated, map[string]interface{}{
+				"public_key":        masterKey,
+				"weight":            masterKeyWeight,
+				"master_key_weight": masterKeyWeight,
 			})
 		}
 	}
@@ -638,18 +866,14 @@ func (e *effectsWrapper) addChangeTrustEffects() error {
 	source := e.operation.SourceAccount()
 
 	op := e.operation.operation.Body.MustChangeTrustOp()
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
 
 	// NOTE:  when an account trusts itself, the transaction is successful but
 	// no ledger entries are actually modified.
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeTrustline {
-			continue
-		}
-
+	for _, change := range index.ofType(xdr.LedgerEntryTypeTrustline) {
 		var (
 			effect    EffectType
 			trustLine xdr.TrustLineEntry
@@ -666,7 +890,7 @@ func (e *effectsWrapper) addChangeTrustEffects() error {
 			effect = EffectTrustlineUpdated
 			trustLine = *change.Post.Data.TrustLine
 		default:
-			panic("Invalid change")
+			return fmt.Errorf("invalid trustline change without pre or post state")
 		}
 
 		// We want to add a single effect for change_trust op. If it's modifying
@@ -727,31 +951,57 @@ func (e *effectsWrapper) addAllowTrustEffects() error {
 	return e.addLiquidityPoolRevokedEffect()
 }
 
-func (e *effectsWrapper) addAccountMergeEffects() {
+func (e *effectsWrapper) addAccountMergeEffects() error {
 	source := e.operation.SourceAccount()
 
 	dest := e.operation.operation.Body.MustDestination()
-	result := e.operation.OperationResult().MustAccountMergeResult()
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetAccountMergeResult()
+	if !ok {
+		return errMissingResult("AccountMergeResult")
+	}
+	sourceAccountBalance, ok := result.GetSourceAccountBalance()
+	if !ok {
+		return errMissingResult("AccountMergeResult source account balance")
+	}
 	details := map[string]interface{}{
-		"amount":     amount.String(result.MustSourceAccountBalance()),
+		"amount":     amount.String(sourceAccountBalance),
 		"asset_type": "native",
+		"asset_id":   FarmHashAsset("", "", "native"),
 	}
 
 	e.addMuxed(source, EffectAccountDebited, details)
 	e.addMuxed(&dest, EffectAccountCredited, details)
 	e.addMuxed(source, EffectAccountRemoved, map[string]interface{}{})
+	return nil
 }
 
-func (e *effectsWrapper) addInflationEffects() {
-	payouts := e.operation.OperationResult().MustInflationResult().MustPayouts()
+func (e *effectsWrapper) addInflationEffects() error {
+	operationResult, err := e.operation.operationResultTr()
+	if err != nil {
+		return err
+	}
+	result, ok := operationResult.GetInflationResult()
+	if !ok {
+		return errMissingResult("InflationResult")
+	}
+	payouts, ok := result.GetPayouts()
+	if !ok {
+		return errMissingResult("InflationResult payouts")
+	}
 	for _, payout := range payouts {
 		e.addUnmuxed(&payout.Destination, EffectAccountCredited,
 			map[string]interface{}{
 				"amount":     amount.String(payout.Amount),
 				"asset_type": "native",
+				"asset_id":   FarmHashAsset("", "", "native"),
 			},
 		)
 	}
+	return nil
 }
 
 func (e *effectsWrapper) addManageDataEffects() error {
@@ -759,16 +1009,12 @@ func (e *effectsWrapper) addManageDataEffects() error {
 	op := e.operation.operation.Body.MustManageDataOp()
 	details := map[string]interface{}{"name": op.DataName}
 	effect := EffectType(0)
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
 
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeData {
-			continue
-		}
-
+	for _, change := range index.ofType(xdr.LedgerEntryTypeData) {
 		before := change.Pre
 		after := change.Post
 
@@ -785,7 +1031,7 @@ func (e *effectsWrapper) addManageDataEffects() error {
 		case before != nil && after != nil:
 			effect = EffectDataUpdated
 		default:
-			panic("Invalid before-and-after state")
+			return fmt.Errorf("invalid data change without pre or post state")
 		}
 
 		break
@@ -797,16 +1043,12 @@ func (e *effectsWrapper) addManageDataEffects() error {
 
 func (e *effectsWrapper) addBumpSequenceEffects() error {
 	source := e.operation.SourceAccount()
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
 
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeAccount {
-			continue
-		}
-
+	for _, change := range index.ofType(xdr.LedgerEntryTypeAccount) {
 		before := change.Pre
 		after := change.Post
 
@@ -830,11 +1072,11 @@ func setClaimableBalanceFlagDetails(details map[string]interface{}, flags xdr.Cl
 	}
 }
 
-func (e *effectsWrapper) addCreateClaimableBalanceEffects(changes []ingest.Change) error {
+func (e *effectsWrapper) addCreateClaimableBalanceEffects(index *operationChangeIndex) error {
 	source := e.operation.SourceAccount()
 	var cb *xdr.ClaimableBalanceEntry
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeClaimableBalance || change.Post == nil {
+	for _, change := range index.ofType(xdr.LedgerEntryTypeClaimableBalance) {
+		if change.Post == nil {
 			continue
 		}
 		cb = change.Post.Data.ClaimableBalance
@@ -889,13 +1131,17 @@ func (e *effectsWrapper) addClaimableBalanceEntryCreatedEffects(source *xdr.Muxe
 	}
 	for _, c := range claimants {
 		cv0 := c.MustV0()
+		predicate, err := transformClaimPredicate(cv0.Predicate)
+		if err != nil {
+			return err
+		}
 		e.addUnmuxed(
 			&cv0.Destination,
 			EffectClaimableBalanceClaimantCreated,
 			map[string]interface{}{
 				"balance_id": id,
 				"amount":     amount.String(cb.Amount),
-				"predicate":  cv0.Predicate,
+				"predicate":  predicate,
 				"asset":      cb.Asset.StringCanonical(),
 			},
 		)
@@ -903,7 +1149,7 @@ func (e *effectsWrapper) addClaimableBalanceEntryCreatedEffects(source *xdr.Muxe
 	return err
 }
 
-func (e *effectsWrapper) addClaimClaimableBalanceEffects(changes []ingest.Change) error {
+func (e *effectsWrapper) addClaimClaimableBalanceEffects(index *operationChangeIndex) error {
 	op := e.operation.operation.Body.MustClaimClaimableBalanceOp()
 
 	balanceID, err := xdr.MarshalHex(op.BalanceId)
@@ -911,30 +1157,11 @@ func (e *effectsWrapper) addClaimClaimableBalanceEffects(changes []ingest.Change
 		return fmt.Errorf("invalid balanceId in op: %d", e.operation.index)
 	}
 
-	var cBalance xdr.ClaimableBalanceEntry
-	found := false
-	for _, change := range changes {
-		if change.Type != xdr.LedgerEntryTypeClaimableBalance {
-			continue
-		}
-
-		if change.Pre != nil && change.Post == nil {
-			cBalance = change.Pre.Data.MustClaimableBalance()
-			preBalanceID, err := xdr.MarshalHex(cBalance.BalanceId)
-			if err != nil {
-				return fmt.Errorf("invalid balanceId in meta changes for op: %d", e.operation.index)
-			}
-
-			if preBalanceID == balanceID {
-				found = true
-				break
-			}
-		}
-	}
-
-	if !found {
+	change, found := index.claimableBalance(balanceID)
+	if !found || change.Pre == nil || change.Post != nil {
 		return fmt.Errorf("change not found for balanceId : %s", balanceID)
 	}
+	cBalance := change.Pre.Data.MustClaimableBalance()
 
 	details := map[string]interface{}{
 		"amount":     amount.String(cBalance.Amount),
@@ -965,6 +1192,9 @@ func (e *effectsWrapper) addClaimClaimableBalanceEffects(changes []ingest.Change
 func (e *effectsWrapper) addIngestTradeEffects(buyer xdr.MuxedAccount, claims []xdr.ClaimAtom, isPathPayment bool) error {
 	for _, claim := range claims {
 		if claim.AmountSold() == 0 && claim.AmountBought() == 0 {
+			if e.operation.effectOptions.ZeroFills && claim.Type != xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+				e.addZeroFillEffects(buyer, claim)
+			}
 			continue
 		}
 		switch claim.Type {
@@ -996,20 +1226,47 @@ func (e *effectsWrapper) addClaimTradeEffects(buyer xdr.MuxedAccount, claim xdr.
 			continue
 		}
 
+		buyerDetails, sellerDetails := bd, sd
+		if effect == EffectTrade {
+			buyerDetails = withTradeType(bd, TradeTypeOrderbook)
+			sellerDetails = withTradeType(sd, TradeTypeOrderbook)
+		}
+
 		e.addMuxed(
 			&buyer,
 			effect,
-			bd,
+			buyerDetails,
 		)
 
 		e.addUnmuxed(
 			&seller,
 			effect,
-			sd,
+			sellerDetails,
 		)
 	}
 }
 
+// addZeroFillEffects adds a trade zero fill effect for both sides of a claim that crossed an offer without
+// exchanging anything, as when the amounts of a fill are rounded down to zero. The offer is usually removed from the
+// book by the claim all the same.
+func (e *effectsWrapper) addZeroFillEffects(buyer xdr.MuxedAccount, claim xdr.ClaimAtom) {
+	seller := claim.SellerId()
+	bd, sd := tradeDetails(buyer, seller, claim)
+	e.addMuxed(&buyer, EffectTradeZeroFill, withTradeType(bd, TradeTypeOrderbook))
+	e.addUnmuxed(&seller, EffectTradeZeroFill, withTradeType(sd, TradeTypeOrderbook))
+}
+
+// withTradeType returns a copy of the details of a trade effect with the claim atom type of the trade, so that the
+// offer effects sharing the details are left without it
+func withTradeType(details map[string]interface{}, tradeType string) map[string]interface{} {
+	tradeDetails := make(map[string]interface{}, len(details)+1)
+	for key, value := range details {
+		tradeDetails[key] = value
+	}
+	tradeDetails["trade_type"] = tradeType
+	return tradeDetails
+}
+
 func (e *effectsWrapper) addClaimLiquidityPoolTradeEffect(claim xdr.ClaimAtom) error {
 	lp, _, err := e.operation.getLiquidityPoolAndProductDelta(&claim.LiquidityPool.LiquidityPoolId)
 	if err != nil {
@@ -1025,6 +1282,11 @@ func (e *effectsWrapper) addClaimLiquidityPoolTradeEffect(claim xdr.ClaimAtom) e
 			"asset":  claim.LiquidityPool.AssetBought.StringCanonical(),
 			"amount": amount.String(claim.LiquidityPool.AmountBought),
 		},
+		"trade_type":            TradeTypeLiquidityPool,
+		"liquidity_pool_fee_bp": uint32(lp.Body.ConstantProduct.Params.Fee),
+	}
+	if err := e.addLiquidityPoolBeforeDetails(details, &claim.LiquidityPool.LiquidityPoolId); err != nil {
+		return err
 	}
 	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolTrade, details)
 	return nil
@@ -1054,7 +1316,7 @@ func (e *effectsWrapper) addClawbackEffects() error {
 	return nil
 }
 
-func (e *effectsWrapper) addClawbackClaimableBalanceEffects(changes []ingest.Change) error {
+func (e *effectsWrapper) addClawbackClaimableBalanceEffects(index *operationChangeIndex) error {
 	op := e.operation.operation.Body.MustClawbackClaimableBalanceOp()
 	balanceId, err := xdr.MarshalHex(op.BalanceId)
 	if err != nil {
@@ -1071,8 +1333,8 @@ func (e *effectsWrapper) addClawbackClaimableBalanceEffects(changes []ingest.Cha
 	)
 
 	// Generate the account credited effect (although the funds will be burned) for the asset issuer
-	for _, c := range changes {
-		if c.Type == xdr.LedgerEntryTypeClaimableBalance && c.Post == nil && c.Pre != nil {
+	for _, c := range index.ofType(xdr.LedgerEntryTypeClaimableBalance) {
+		if c.Post == nil && c.Pre != nil {
 			cb := c.Pre.Data.ClaimableBalance
 			details = map[string]interface{}{"amount": amount.String(cb.Amount)}
 			addAssetDetails(details, cb.Asset, "")
@@ -1149,14 +1411,14 @@ func (e *effectsWrapper) addLiquidityPoolRevokedEffect() error {
 		}
 		return err
 	}
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
 	assetToCBID := map[string]string{}
 	var cbs sortableClaimableBalanceEntries
-	for _, change := range changes {
-		if change.Type == xdr.LedgerEntryTypeClaimableBalance && change.Pre == nil && change.Post != nil {
+	for _, change := range index.ofType(xdr.LedgerEntryTypeClaimableBalance) {
+		if change.Pre == nil && change.Post != nil {
 			cb := change.Post.Data.ClaimableBalance
 			id, err := xdr.MarshalHex(cb.BalanceId)
 			if err != nil {
@@ -1204,6 +1466,9 @@ func (e *effectsWrapper) addLiquidityPoolRevokedEffect() error {
 		"reserves_revoked": reservesRevoked,
 		"shares_revoked":   amount.String(-delta.TotalPoolShares),
 	}
+	if err := e.addLiquidityPoolBeforeDetails(details, nil); err != nil {
+		return err
+	}
 	e.addMuxed(source, EffectLiquidityPoolRevoked, details)
 	return nil
 }
@@ -1252,19 +1517,44 @@ func liquidityPoolDetails(lp *xdr.LiquidityPoolEntry) map[string]interface{} {
 		"type":             "constant_product",
 		"total_trustlines": strconv.FormatInt(int64(lp.Body.ConstantProduct.PoolSharesTrustLineCount), 10),
 		"total_shares":     amount.String(lp.Body.ConstantProduct.TotalPoolShares),
-		"reserves": []base.AssetAmount{
-			{
-				Asset:  lp.Body.ConstantProduct.Params.AssetA.StringCanonical(),
-				Amount: amount.String(lp.Body.ConstantProduct.ReserveA),
-			},
-			{
-				Asset:  lp.Body.ConstantProduct.Params.AssetB.StringCanonical(),
-				Amount: amount.String(lp.Body.ConstantProduct.ReserveB),
-			},
+		"reserves":         liquidityPoolReserveAmounts(lp),
+	}
+}
+
+func liquidityPoolReserveAmounts(lp *xdr.LiquidityPoolEntry) []base.AssetAmount {
+	return []base.AssetAmount{
+		{
+			Asset:  lp.Body.ConstantProduct.Params.AssetA.StringCanonical(),
+			Amount: amount.String(lp.Body.ConstantProduct.ReserveA),
+		},
+		{
+			Asset:  lp.Body.ConstantProduct.Params.AssetB.StringCanonical(),
+			Amount: amount.String(lp.Body.ConstantProduct.ReserveB),
 		},
 	}
 }
 
+// addLiquidityPoolBeforeDetails adds the reserves and shares of the pool before the operation to the details of a
+// pool effect, next to the liquidity_pool snapshot of the pool after it, so that the price implied by the pool
+// before and after the operation can be computed from the effect alone. Nothing is added if the pool did not exist
+// before the operation.
+func (e *effectsWrapper) addLiquidityPoolBeforeDetails(details map[string]interface{}, lpID *xdr.PoolId) error {
+	index, err := e.operation.operationChanges()
+	if err != nil {
+		return err
+	}
+	change, ok := index.liquidityPool(lpID)
+	if !ok || change.Pre == nil {
+		return nil
+	}
+	lp := change.Pre.Data.MustLiquidityPool()
+	details["liquidity_pool_before"] = map[string]interface{}{
+		"total_shares": amount.String(lp.Body.ConstantProduct.TotalPoolShares),
+		"reserves":     liquidityPoolReserveAmounts(&lp),
+	}
+	return nil
+}
+
 func (e *effectsWrapper) addLiquidityPoolDepositEffect() error {
 	op := e.operation.operation.Body.MustLiquidityPoolDepositOp()
 	lp, delta, err := e.operation.getLiquidityPoolAndProductDelta(&op.LiquidityPoolId)
@@ -1285,6 +1575,9 @@ func (e *effectsWrapper) addLiquidityPoolDepositEffect() error {
 		},
 		"shares_received": amount.String(delta.TotalPoolShares),
 	}
+	if err := e.addLiquidityPoolBeforeDetails(details, &op.LiquidityPoolId); err != nil {
+		return err
+	}
 	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolDeposited, details)
 	return nil
 }
@@ -1309,6 +1602,9 @@ func (e *effectsWrapper) addLiquidityPoolWithdrawEffect() error {
 		},
 		"shares_redeemed": amount.String(-delta.TotalPoolShares),
 	}
+	if err := e.addLiquidityPoolBeforeDetails(details, &op.LiquidityPoolId); err != nil {
+		return err
+	}
 	e.addMuxed(e.operation.SourceAccount(), EffectLiquidityPoolWithdrew, details)
 	return nil
 }
@@ -1433,11 +1729,13 @@ func (e *effectsWrapper) addExtendFootprintTtlEffect() error {
 	op := e.operation.operation.Body.MustExtendFootprintTtlOp()
 
 	// Figure out which entries were affected
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
+	changes := index.changes
 	entries := make([]string, 0, len(changes))
+	canonicalEntries := make([]string, 0, len(changes))
 	for _, change := range changes {
 		// They should all have a post
 		if change.Post == nil {
@@ -1457,15 +1755,21 @@ func (e *effectsWrapper) addExtendFootprintTtlEffect() error {
 			// affected, for example, the user's balance.
 			continue
 		}
-		b64, err := xdr.MarshalBase64(key)
+		b64, err := ledgerkey.Base64(key)
+		if err != nil {
+			return err
+		}
+		canonical, err := ledgerkey.Canonical(key)
 		if err != nil {
 			return err
 		}
 		entries = append(entries, b64)
+		canonicalEntries = append(canonicalEntries, canonical)
 	}
 	details := map[string]interface{}{
-		"entries":   entries,
-		"extend_to": op.ExtendTo,
+		"entries":           entries,
+		"entries_canonical": canonicalEntries,
+		"extend_to":         op.ExtendTo,
 	}
 	e.addMuxed(e.operation.SourceAccount(), EffectExtendFootprintTtl, details)
 	return nil
@@ -1475,11 +1779,13 @@ func (e *effectsWrapper) addRestoreFootprintExpirationEffect() error {
 	op := e.operation.operation.Body.MustRestoreFootprintOp()
 
 	// Figure out which entries were affected
-	changes, err := e.operation.transaction.GetOperationChanges(e.operation.index)
+	index, err := e.operation.operationChanges()
 	if err != nil {
 		return err
 	}
+	changes := index.changes
 	entries := make([]string, 0, len(changes))
+	canonicalEntries := make([]string, 0, len(changes))
 	for _, change := range changes {
 		// They should all have a post
 		if change.Post == nil {
@@ -1499,14 +1805,20 @@ func (e *effectsWrapper) addRestoreFootprintExpirationEffect() error {
 			// affected, for example, the user's balance.
 			continue
 		}
-		b64, err := xdr.MarshalBase64(key)
+		b64, err := ledgerkey.Base64(key)
+		if err != nil {
+			return err
+		}
+		canonical, err := ledgerkey.Canonical(key)
 		if err != nil {
 			return err
 		}
 		entries = append(entries, b64)
+		canonicalEntries = append(canonicalEntries, canonical)
 	}
 	details := map[string]interface{}{
-		"entries": entries,
+		"entries":           entries,
+		"entries_canonical": canonicalEntries,
 	}
 	e.addMuxed(e.operation.SourceAccount(), EffectRestoreFootprint, details)
 	return nil
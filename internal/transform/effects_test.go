@@ -392,6 +392,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "1000.0000000",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectAccountDebited),
 					TypeString:     EffectTypeNames[EffectAccountDebited],
@@ -403,7 +404,7 @@ func TestOperationEffects(t *testing.T) {
 					OperationID: int64(244813139969),
 					Details: map[string]interface{}{
 						"public_key": "GCQZP3IU7XU6EJ63JZXKCQOYT2RNXN3HB5CNHENNUEUHSMA4VUJJJSEN",
-						"weight":     1,
+						"weight":     int64(1),
 					},
 					Type:           int32(EffectSignerCreated),
 					TypeString:     EffectTypeNames[EffectSignerCreated],
@@ -461,6 +462,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "10.0000000",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectAccountCredited),
 					TypeString:     EffectTypeNames[EffectAccountCredited],
@@ -473,6 +475,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "10.0000000",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectAccountDebited),
 					TypeString:     EffectTypeNames[EffectAccountDebited],
@@ -500,6 +503,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "ARS",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectAccountCredited),
 					TypeString:     EffectTypeNames[EffectAccountCredited],
@@ -514,6 +518,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "BRL",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectAccountDebited),
 					TypeString:     EffectTypeNames[EffectAccountDebited],
@@ -528,12 +533,16 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -548,12 +557,16 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -568,12 +581,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -588,12 +604,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -608,12 +627,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -628,12 +650,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -661,6 +686,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "ARS",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectAccountCredited),
 					TypeString:     EffectTypeNames[EffectAccountCredited],
@@ -676,6 +702,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":   "BRL",
 						"asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectAccountDebited),
 					TypeString:     EffectTypeNames[EffectAccountDebited],
@@ -691,12 +718,16 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -711,14 +742,18 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
-						"seller_muxed_id":     uint64(0xcafebabe),
+						"seller_muxed_id":     int64(0xcafebabe),
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -734,12 +769,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -754,14 +792,17 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
-						"seller_muxed_id":     uint64(0xcafebabe),
+						"seller_muxed_id":     int64(0xcafebabe),
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -777,12 +818,15 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "ARS",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GDEOVUDLCYTO46D6GD6WH7BFESPBV5RACC6F6NUFCIRU7PL2XONQHVGJ",
 						"sold_amount":         "0.0300000",
 						"sold_asset_code":     "BRL",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -797,14 +841,17 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "BRL",
 						"bought_asset_issuer": "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10072128),
+						"bought_asset_id":     FarmHashAsset("BRL", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
+						"offer_id":            int64(10072128),
+						"offer_id_str":        "10072128",
 						"seller":              "GD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY737V",
 						"seller_muxed":        "MD3MMHD2YZWL5RAUWG6O3RMA5HTZYM7S3JLSZ2Z35JNJAWTDIKXY6AAAAAAMV7V2XZY4C",
-						"seller_muxed_id":     uint64(0xcafebabe),
+						"seller_muxed_id":     int64(0xcafebabe),
 						"sold_amount":         "1.0000000",
 						"sold_asset_code":     "ARS",
 						"sold_asset_issuer":   "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF",
 						"sold_asset_type":     "credit_alphanum4",
+						"sold_asset_id":       FarmHashAsset("ARS", "GCXI6Q73J7F6EUSBZTPW4G4OUGVDHABPYF2U4KO7MVEX52OH5VMVUCRF", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -842,10 +889,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(9248760),
+						"bought_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
+						"offer_id":            int64(9248760),
+						"offer_id_str":        "9248760",
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -858,12 +909,16 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(9248760),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(9248760),
+						"offer_id_str":      "9248760",
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
+						"trade_type":        TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -878,10 +933,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(9248760),
+						"bought_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
+						"offer_id":            int64(9248760),
+						"offer_id_str":        "9248760",
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -894,12 +952,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(9248760),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(9248760),
+						"offer_id_str":      "9248760",
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -914,10 +975,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(9248760),
+						"bought_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
+						"offer_id":            int64(9248760),
+						"offer_id_str":        "9248760",
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -930,12 +994,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(9248760),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(9248760),
+						"offer_id_str":      "9248760",
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -950,10 +1017,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "STR",
 						"bought_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(9248760),
+						"bought_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
+						"offer_id":            int64(9248760),
+						"offer_id_str":        "9248760",
 						"seller":              "GAHEPWQ2B5ZOPI2NB647QCIXFPQR4H56FPYADQY54GNMFG4IYB5ZAJ5H",
 						"sold_amount":         "999.9999999",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -966,12 +1036,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "999.9999999",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(9248760),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(9248760),
+						"offer_id_str":      "9248760",
 						"seller":            "GD5OGQTZZ2PYI2RSMOJA6BQ7CDCW2JXAXBKR6XZK6PPRFUZ3BUXNLFKP",
 						"sold_amount":       "505.0505050",
 						"sold_asset_code":   "STR",
 						"sold_asset_issuer": "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("STR", "GBEYFNS6KJRFEI22X5OBUFKQ5LK7Z2FZVFMAXBINC2SOCKA25AS62PUN", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -998,10 +1071,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
-						"offer_id":            xdr.Int64(10104690),
+						"bought_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
+						"offer_id":            int64(10104690),
+						"offer_id_str":        "10104690",
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -1014,12 +1091,16 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10104690),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10104690),
+						"offer_id_str":      "10104690",
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
+						"trade_type":        TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -1034,10 +1115,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
-						"offer_id":            xdr.Int64(10104690),
+						"bought_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
+						"offer_id":            int64(10104690),
+						"offer_id_str":        "10104690",
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -1050,12 +1134,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10104690),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10104690),
+						"offer_id_str":      "10104690",
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -1070,10 +1157,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
-						"offer_id":            xdr.Int64(10104690),
+						"bought_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
+						"offer_id":            int64(10104690),
+						"offer_id_str":        "10104690",
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -1086,12 +1176,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10104690),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10104690),
+						"offer_id_str":      "10104690",
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -1106,10 +1199,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "TXTalpha4",
 						"bought_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"bought_asset_type":   "credit_alphanum12",
-						"offer_id":            xdr.Int64(10104690),
+						"bought_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
+						"offer_id":            int64(10104690),
+						"offer_id_str":        "10104690",
 						"seller":              "GCA3EPMNR26H3BO55PQPAMOGKBAIMARLQHWCRK7KTUPGR62SDVLIL7D6",
 						"sold_amount":         "200.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -1122,12 +1218,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "200.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10104690),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10104690),
+						"offer_id_str":      "10104690",
 						"seller":            "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_amount":       "200.0000000",
 						"sold_asset_code":   "TXTalpha4",
 						"sold_asset_issuer": "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV",
 						"sold_asset_type":   "credit_alphanum12",
+						"sold_asset_id":     FarmHashAsset("TXTalpha4", "GBFC3KATHWQOZ3TWJEOLMBBFMPZ4OS2KYVZRKWVRMQKZ2LFNRLQEIRCV", "credit_alphanum12"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -1154,10 +1253,14 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10694502),
+						"bought_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
+						"offer_id":            int64(10694502),
+						"offer_id_str":        "10694502",
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
+						"trade_type":          TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -1170,12 +1273,16 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10694502),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10694502),
+						"offer_id_str":      "10694502",
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
+						"trade_type":        TradeTypeOrderbook,
 					},
 					Type:           int32(EffectTrade),
 					TypeString:     EffectTypeNames[EffectTrade],
@@ -1190,10 +1297,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10694502),
+						"bought_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
+						"offer_id":            int64(10694502),
+						"offer_id_str":        "10694502",
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -1206,12 +1316,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10694502),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10694502),
+						"offer_id_str":      "10694502",
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferUpdated),
 					TypeString:     EffectTypeNames[EffectOfferUpdated],
@@ -1226,10 +1339,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10694502),
+						"bought_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
+						"offer_id":            int64(10694502),
+						"offer_id_str":        "10694502",
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -1242,12 +1358,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10694502),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10694502),
+						"offer_id_str":      "10694502",
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferRemoved),
 					TypeString:     EffectTypeNames[EffectOfferRemoved],
@@ -1262,10 +1381,13 @@ func TestOperationEffects(t *testing.T) {
 						"bought_asset_code":   "COP",
 						"bought_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"bought_asset_type":   "credit_alphanum4",
-						"offer_id":            xdr.Int64(10694502),
+						"bought_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
+						"offer_id":            int64(10694502),
+						"offer_id_str":        "10694502",
 						"seller":              "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
 						"sold_amount":         "100.0000000",
 						"sold_asset_type":     "native",
+						"sold_asset_id":       FarmHashAsset("", "", "native"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -1278,12 +1400,15 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"bought_amount":     "100.0000000",
 						"bought_asset_type": "native",
-						"offer_id":          xdr.Int64(10694502),
+						"bought_asset_id":   FarmHashAsset("", "", "native"),
+						"offer_id":          int64(10694502),
+						"offer_id_str":      "10694502",
 						"seller":            "GAA7AZYCJ65VJSMFAGQLBNCXA43QQ6ZEUR4GL4YSVB2FXUAHLLYUHIO5",
 						"sold_amount":       "100000.0000000",
 						"sold_asset_code":   "COP",
 						"sold_asset_issuer": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 						"sold_asset_type":   "credit_alphanum4",
+						"sold_asset_id":     FarmHashAsset("COP", "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH", "credit_alphanum4"),
 					},
 					Type:           int32(EffectOfferCreated),
 					TypeString:     EffectTypeNames[EffectOfferCreated],
@@ -1317,9 +1442,9 @@ func TestOperationEffects(t *testing.T) {
 				{
 					Address: "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 					Details: map[string]interface{}{
-						"high_threshold": xdr.Uint32(3),
-						"low_threshold":  xdr.Uint32(1),
-						"med_threshold":  xdr.Uint32(2),
+						"high_threshold": int64(3),
+						"low_threshold":  int64(1),
+						"med_threshold":  int64(2),
 					},
 					Type:           int32(EffectAccountThresholdsUpdated),
 					TypeString:     EffectTypeNames[EffectAccountThresholdsUpdated],
@@ -1353,8 +1478,9 @@ func TestOperationEffects(t *testing.T) {
 				{
 					Address: "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 					Details: map[string]interface{}{
-						"public_key": "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
-						"weight":     int32(3),
+						"public_key":        "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
+						"weight":            int64(3),
+						"master_key_weight": int64(3),
 					},
 					Type:           int32(EffectSignerUpdated),
 					TypeString:     EffectTypeNames[EffectSignerUpdated],
@@ -1366,7 +1492,7 @@ func TestOperationEffects(t *testing.T) {
 					Address: "GC4XF7RE3R4P77GY5XNGICM56IOKUURWAAANPXHFC7G5H6FCNQVVH3OH",
 					Details: map[string]interface{}{
 						"public_key": "GAQHWQYBBW272OOXNQMMLCA5WY2XAZPODGB7Q3S5OKKIXVESKO55ZQ7C",
-						"weight":     int32(2),
+						"weight":     int64(2),
 					},
 					Type:           int32(EffectSignerCreated),
 					TypeString:     EffectTypeNames[EffectSignerCreated],
@@ -1395,6 +1521,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "922337203685.4775807",
 						"asset_code":   "USD",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("USD", "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF", "credit_alphanum4"),
 						"asset_issuer": "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1421,6 +1548,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "0.0000000",
 						"asset_code":   "OCIToken",
 						"asset_type":   "credit_alphanum12",
+						"asset_id":     FarmHashAsset("OCIToken", "GBE4L76HUCHCQ2B7IIWBXRAJDBDPIY6MGWX7VZHUZD2N5RO7XI4J6GTJ", "credit_alphanum12"),
 						"asset_issuer": "GBE4L76HUCHCQ2B7IIWBXRAJDBDPIY6MGWX7VZHUZD2N5RO7XI4J6GTJ",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1447,6 +1575,7 @@ func TestOperationEffects(t *testing.T) {
 						"limit":        "100.0000000",
 						"asset_code":   "TESTASSET",
 						"asset_type":   "credit_alphanum12",
+						"asset_id":     FarmHashAsset("TESTASSET", "GA5SKSJEB7VWACRNWFGVZBDSZYLGK44A2JPPBWUK3GB7NYEFOOQJAC2B", "credit_alphanum12"),
 						"asset_issuer": "GA5SKSJEB7VWACRNWFGVZBDSZYLGK44A2JPPBWUK3GB7NYEFOOQJAC2B",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1473,6 +1602,7 @@ func TestOperationEffects(t *testing.T) {
 						"trustor":      "GCVW5LCRZFP7PENXTAGOVIQXADDNUXXZJCNKF4VQB2IK7W2LPJWF73UG",
 						"asset_code":   "USD",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("USD", "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF", "credit_alphanum4"),
 						"asset_issuer": "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1487,6 +1617,7 @@ func TestOperationEffects(t *testing.T) {
 						"asset_code":      "USD",
 						"asset_issuer":    "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF",
 						"asset_type":      "credit_alphanum4",
+						"asset_id":        FarmHashAsset("USD", "GD4SMOE3VPSF7ZR3CTEQ3P5UNTBMEJDA2GLXTHR7MMARANKKJDZ7RPGF", "credit_alphanum4"),
 						"authorized_flag": true,
 						"trustor":         "GCVW5LCRZFP7PENXTAGOVIQXADDNUXXZJCNKF4VQB2IK7W2LPJWF73UG",
 					},
@@ -1513,6 +1644,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "999.9999900",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 44,
@@ -1525,6 +1657,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "999.9999900",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 44,
@@ -1558,6 +1691,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "15257676.9536092",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 47,
@@ -1570,6 +1704,7 @@ func TestOperationEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "3814420.0001419",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 47,
@@ -1592,7 +1727,7 @@ func TestOperationEffects(t *testing.T) {
 					TypeString:  EffectTypeNames[EffectDataCreated],
 					OperationID: int64(210453401601),
 					Details: map[string]interface{}{
-						"name":  xdr.String64("name2"),
+						"name":  "name2",
 						"value": "NTY3OA==",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1616,7 +1751,7 @@ func TestOperationEffects(t *testing.T) {
 					TypeString:  EffectTypeNames[EffectDataRemoved],
 					OperationID: int64(210453401601),
 					Details: map[string]interface{}{
-						"name": xdr.String64("hello"),
+						"name": "hello",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 49,
@@ -1639,7 +1774,7 @@ func TestOperationEffects(t *testing.T) {
 					TypeString:  EffectTypeNames[EffectDataUpdated],
 					OperationID: int64(210453401601),
 					Details: map[string]interface{}{
-						"name":  xdr.String64("GCR3TQ2TVH3QRI7GQMC3IJGUUBR32YQHWBIKIMTYRQ2YH4XUTDB75UKE"),
+						"name":  "GCR3TQ2TVH3QRI7GQMC3IJGUUBR32YQHWBIKIMTYRQ2YH4XUTDB75UKE",
 						"value": "MTU3ODUyMTIwNF8yOTMyOTAyNzg=",
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
@@ -1687,7 +1822,7 @@ func TestOperationEffects(t *testing.T) {
 					TypeString:  EffectTypeNames[EffectSequenceBumped],
 					OperationID: int64(249108107265),
 					Details: map[string]interface{}{
-						"new_seq": xdr.SequenceNumber(300000000000),
+						"new_seq": int64(300000000000),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 58,
@@ -1742,6 +1877,9 @@ func TestOperationEffects(t *testing.T) {
 			for i := range tc.expected {
 				tc.expected[i].EffectIndex = uint32(i)
 				tc.expected[i].EffectId = fmt.Sprintf("%d-%d", tc.expected[i].OperationID, tc.expected[i].EffectIndex)
+				tc.expected[i].PagingToken = fmt.Sprintf("%d-%d", tc.expected[i].OperationID, tc.expected[i].EffectIndex+1)
+				tc.expected[i].TransactionSuccessful = true
+				tc.expected[i].Category = EffectCategories[EffectType(tc.expected[i].Type)]
 			}
 
 			effects, err := operation.effects()
@@ -1843,7 +1981,7 @@ func TestOperationEffectsSetOptionsSignersOrder(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GCAHY6JSXQFKWKP6R7U5JPXDVNV4DJWOWRFLY3Y6YPBF64QRL4BPFDNS",
-				"weight":     int32(15),
+				"weight":     int64(15),
 			},
 			Type:           int32(EffectSignerUpdated),
 			TypeString:     EffectTypeNames[EffectSignerUpdated],
@@ -1855,7 +1993,7 @@ func TestOperationEffectsSetOptionsSignersOrder(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GCBBDQLCTNASZJ3MTKAOYEOWRGSHDFAJVI7VPZUOP7KXNHYR3HP2BUKV",
-				"weight":     int32(16),
+				"weight":     int64(16),
 			},
 			Type:           int32(EffectSignerUpdated),
 			TypeString:     EffectTypeNames[EffectSignerUpdated],
@@ -1867,7 +2005,7 @@ func TestOperationEffectsSetOptionsSignersOrder(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GA4O5DLUUTLCTMM2UOWOYPNIH2FTD4NLO6KDZOFQRUISQ3FYKABGJLPC",
-				"weight":     int32(17),
+				"weight":     int64(17),
 			},
 			Type:           int32(EffectSignerCreated),
 			TypeString:     EffectTypeNames[EffectSignerCreated],
@@ -1879,7 +2017,7 @@ func TestOperationEffectsSetOptionsSignersOrder(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GCR3TQ2TVH3QRI7GQMC3IJGUUBR32YQHWBIKIMTYRQ2YH4XUTDB75UKE",
-				"weight":     int32(14),
+				"weight":     int64(14),
 			},
 			Type:           int32(EffectSignerCreated),
 			TypeString:     EffectTypeNames[EffectSignerCreated],
@@ -1890,6 +2028,83 @@ func TestOperationEffectsSetOptionsSignersOrder(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
+	}
+
+	tt.Equal(expected, effects)
+}
+
+func TestOperationEffectsSetOptionsMasterKeyWeight(t *testing.T) {
+	tt := assert.New(t)
+	account := "GCBBDQLCTNASZJ3MTKAOYEOWRGSHDFAJVI7VPZUOP7KXNHYR3HP2BUKV"
+	accountEntry := func(masterKeyWeight byte) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:  xdr.MustAddress(account),
+					Thresholds: xdr.Thresholds{masterKeyWeight, 0, 0, 0},
+				},
+			},
+		}
+	}
+	transaction := ingest.LedgerTransaction{
+		UnsafeMeta: createTransactionMeta([]xdr.OperationMeta{
+			{
+				Changes: []xdr.LedgerEntryChange{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: accountEntry(2)},
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: accountEntry(0)},
+				},
+			},
+		}),
+	}
+	transaction.Index = 1
+	transaction.Envelope.Type = xdr.EnvelopeTypeEnvelopeTypeTx
+	aid := xdr.MustAddress(account)
+	transaction.Envelope.V1 = &xdr.TransactionV1Envelope{
+		Tx: xdr.Transaction{
+			SourceAccount: aid.ToMuxedAccount(),
+		},
+	}
+
+	masterWeight := xdr.Uint32(0)
+	operation := transactionOperationWrapper{
+		index:       0,
+		transaction: transaction,
+		operation: xdr.Operation{
+			Body: xdr.OperationBody{
+				Type:         xdr.OperationTypeSetOptions,
+				SetOptionsOp: &xdr.SetOptionsOp{MasterWeight: &masterWeight},
+			},
+		},
+		ledgerSequence: 46,
+		ledgerClosed:   genericCloseTime.UTC(),
+	}
+
+	effects, err := operation.effects()
+	tt.NoError(err)
+	expected := []EffectOutput{
+		{
+			Address:     account,
+			OperationID: int64(197568499713),
+			Details: map[string]interface{}{
+				"public_key":        account,
+				"master_key_weight": int64(0),
+			},
+			Type:           int32(EffectSignerRemoved),
+			TypeString:     EffectTypeNames[EffectSignerRemoved],
+			LedgerClosed:   genericCloseTime.UTC(),
+			LedgerSequence: 46,
+		},
+	}
+	for i := range expected {
+		expected[i].EffectIndex = uint32(i)
+		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -1998,7 +2213,7 @@ func TestOperationEffectsSetOptionsSignersNoUpdated(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GCBBDQLCTNASZJ3MTKAOYEOWRGSHDFAJVI7VPZUOP7KXNHYR3HP2BUKV",
-				"weight":     int32(16),
+				"weight":     int64(16),
 			},
 			Type:           int32(EffectSignerUpdated),
 			TypeString:     EffectTypeNames[EffectSignerUpdated],
@@ -2010,7 +2225,7 @@ func TestOperationEffectsSetOptionsSignersNoUpdated(t *testing.T) {
 			OperationID: int64(197568499713),
 			Details: map[string]interface{}{
 				"public_key": "GCR3TQ2TVH3QRI7GQMC3IJGUUBR32YQHWBIKIMTYRQ2YH4XUTDB75UKE",
-				"weight":     int32(14),
+				"weight":     int64(14),
 			},
 			Type:           int32(EffectSignerCreated),
 			TypeString:     EffectTypeNames[EffectSignerCreated],
@@ -2021,6 +2236,9 @@ func TestOperationEffectsSetOptionsSignersNoUpdated(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -2104,6 +2322,7 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     FarmHashAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD", "credit_alphanum4"),
 				"trustor":      "GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3",
 			},
 			Type:           int32(EffectTrustlineFlagsUpdated),
@@ -2118,6 +2337,7 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 				"asset_code":                        "COP",
 				"asset_issuer":                      "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":                        "credit_alphanum4",
+				"asset_id":                          FarmHashAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD", "credit_alphanum4"),
 				"authorized_to_maintain_liabilites": true,
 				"trustor":                           "GDQNY3PBOJOKYZSRMK2S7LHHGWZIUISD4QORETLMXEWXBI7KFZZMKTL3",
 			},
@@ -2130,6 +2350,9 @@ func TestOperationEffectsAllowTrustAuthorizedToMaintainLiabilities(t *testing.T)
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -2175,6 +2398,7 @@ func TestOperationEffectsClawback(t *testing.T) {
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     FarmHashAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD", "credit_alphanum4"),
 				"amount":       "0.0000034",
 			},
 			Type:           int32(EffectAccountCredited),
@@ -2189,6 +2413,7 @@ func TestOperationEffectsClawback(t *testing.T) {
 				"asset_code":   "COP",
 				"asset_issuer": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":   "credit_alphanum4",
+				"asset_id":     FarmHashAsset("COP", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD", "credit_alphanum4"),
 				"amount":       "0.0000034",
 			},
 			Type:           int32(EffectAccountDebited),
@@ -2200,6 +2425,9 @@ func TestOperationEffectsClawback(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -2253,6 +2481,9 @@ func TestOperationEffectsClawbackClaimableBalance(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -2302,6 +2533,7 @@ func TestOperationEffectsSetTrustLineFlags(t *testing.T) {
 				"asset_code":                        "USD",
 				"asset_issuer":                      "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
 				"asset_type":                        "credit_alphanum4",
+				"asset_id":                          FarmHashAsset("USD", "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD", "credit_alphanum4"),
 				"authorized_flag":                   false,
 				"authorized_to_maintain_liabilites": true,
 				"clawback_enabled_flag":             false,
@@ -2316,6 +2548,9 @@ func TestOperationEffectsSetTrustLineFlags(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	tt.Equal(expected, effects)
@@ -2522,6 +2757,9 @@ func TestTrustlineSponsorhipEffects(t *testing.T) {
 	for i := range expected {
 		expected[i].EffectIndex = uint32(i)
 		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
 	}
 
 	// pick an operation with no intrinsic effects
@@ -2564,6 +2802,139 @@ func TestTrustlineSponsorhipEffects(t *testing.T) {
 
 }
 
+func TestOfferSponsorshipEffects(t *testing.T) {
+	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	sponsor1 := xdr.MustAddress("GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2")
+	sponsor2 := xdr.MustAddress("GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD")
+	offerEntry := func(sponsor *xdr.AccountId) xdr.LedgerEntry {
+		return xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 20,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeOffer,
+				Offer: &xdr.OfferEntry{
+					SellerId: source.ToAccountId(),
+					OfferId:  12,
+					Selling:  xdr.MustNewNativeAsset(),
+					Buying:   xdr.MustNewCreditAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY"),
+					Amount:   100,
+					Price:    xdr.Price{N: 1, D: 1},
+				},
+			},
+			Ext: xdr.LedgerEntryExt{V: 1, V1: &xdr.LedgerEntryExtensionV1{SponsoringId: sponsor}},
+		}
+	}
+	created := offerEntry(&sponsor1)
+	updatedBefore := offerEntry(&sponsor1)
+	updatedAfter := offerEntry(&sponsor2)
+	removed := offerEntry(&sponsor2)
+
+	changes := xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: &created},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &updatedBefore},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: &updatedAfter},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: &removed},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &xdr.LedgerKey{Type: xdr.LedgerEntryTypeOffer, Offer: &xdr.LedgerKeyOffer{SellerId: source.ToAccountId(), OfferId: 12}}},
+	}
+
+	phonyOp := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeEndSponsoringFutureReserves,
+		},
+	}
+	operation := transactionOperationWrapper{
+		index: 0,
+		transaction: ingest.LedgerTransaction{
+			Index: 0,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: source,
+						Operations:    []xdr.Operation{phonyOp},
+					},
+				},
+			},
+			UnsafeMeta: xdr.TransactionMeta{
+				V: 2,
+				V2: &xdr.TransactionMetaV2{
+					Operations: []xdr.OperationMeta{{Changes: changes}},
+				},
+			},
+		},
+		operation:      phonyOp,
+		ledgerSequence: 1,
+		ledgerClosed:   genericCloseTime.UTC(),
+	}
+
+	// offers have no sponsorship effects by default
+	effects, err := operation.effects()
+	assert.NoError(t, err)
+	assert.Empty(t, effects)
+
+	operation.effectOptions = EffectOptions{OfferSponsorships: true}
+	effects, err = operation.effects()
+	assert.NoError(t, err)
+
+	expected := []EffectOutput{
+		{
+			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"offer_id":     int64(12),
+				"offer_id_str": "12",
+				"sponsor":      "GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2",
+			},
+			Type:       int32(EffectOfferSponsorshipCreated),
+			TypeString: EffectTypeNames[EffectOfferSponsorshipCreated],
+		},
+		{
+			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"offer_id":       int64(12),
+				"offer_id_str":   "12",
+				"former_sponsor": "GDMQUXK7ZUCWM5472ZU3YLDP4BMJLQQ76DEMNYDEY2ODEEGGRKLEWGW2",
+				"new_sponsor":    "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+			},
+			Type:       int32(EffectOfferSponsorshipUpdated),
+			TypeString: EffectTypeNames[EffectOfferSponsorshipUpdated],
+		},
+		{
+			Address:     "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"offer_id":       int64(12),
+				"offer_id_str":   "12",
+				"former_sponsor": "GDRW375MAYR46ODGF2WGANQC2RRZL7O246DYHHCGWTV2RE7IHE2QUQLD",
+			},
+			Type:       int32(EffectOfferSponsorshipRemoved),
+			TypeString: EffectTypeNames[EffectOfferSponsorshipRemoved],
+		},
+	}
+	for i := range expected {
+		expected[i].LedgerClosed = genericCloseTime.UTC()
+		expected[i].LedgerSequence = 1
+		expected[i].EffectIndex = uint32(i)
+		expected[i].EffectId = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex)
+		expected[i].PagingToken = fmt.Sprintf("%d-%d", expected[i].OperationID, expected[i].EffectIndex+1)
+		expected[i].TransactionSuccessful = true
+		expected[i].Category = EffectCategories[EffectType(expected[i].Type)]
+	}
+	assert.Equal(t, expected, effects)
+}
+
+func TestEffectCategories(t *testing.T) {
+	for effectType, name := range EffectTypeNames {
+		assert.NotEmpty(t, EffectCategories[effectType], name)
+	}
+	assert.Len(t, EffectCategories, len(EffectTypeNames))
+
+	assert.Equal(t, "account", EffectCategories[EffectSignerCreated])
+	assert.Equal(t, "trade", EffectCategories[EffectTrade])
+	assert.Equal(t, "sponsorship", EffectCategories[EffectTrustlineSponsorshipCreated])
+	assert.Equal(t, "soroban", EffectCategories[EffectRestoreFootprint])
+}
+
 func TestLiquidityPoolEffects(t *testing.T) {
 	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
 	usdAsset := xdr.MustNewCreditAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
@@ -2691,7 +3062,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					OperationID: 4294967297,
 					Details: map[string]interface{}{
 						"liquidity_pool": map[string]interface{}{
-							"fee_bp": uint32(20),
+							"fee_bp": int64(20),
 							"id":     poolIDStr,
 							"reserves": []base.AssetAmount{
 								{
@@ -2750,7 +3121,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					OperationID: 4294967297,
 					Details: map[string]interface{}{
 						"liquidity_pool": map[string]interface{}{
-							"fee_bp": uint32(20),
+							"fee_bp": int64(20),
 							"id":     poolIDStr,
 							"reserves": []base.AssetAmount{
 								{
@@ -2766,6 +3137,19 @@ func TestLiquidityPoolEffects(t *testing.T) {
 							"total_trustlines": "10",
 							"type":             "constant_product",
 						},
+						"liquidity_pool_before": map[string]interface{}{
+							"reserves": []base.AssetAmount{
+								{
+									Asset:  "native",
+									Amount: "0.0000200",
+								},
+								{
+									Asset:  "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+									Amount: "0.0000100",
+								},
+							},
+							"total_shares": "0.0001000",
+						},
 						"reserves_deposited": []base.AssetAmount{
 							{
 								Asset:  "native",
@@ -2813,7 +3197,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					OperationID: 4294967297,
 					Details: map[string]interface{}{
 						"liquidity_pool": map[string]interface{}{
-							"fee_bp": uint32(20),
+							"fee_bp": int64(20),
 							"id":     poolIDStr,
 							"reserves": []base.AssetAmount{
 								{
@@ -2829,6 +3213,19 @@ func TestLiquidityPoolEffects(t *testing.T) {
 							"total_trustlines": "10",
 							"type":             "constant_product",
 						},
+						"liquidity_pool_before": map[string]interface{}{
+							"reserves": []base.AssetAmount{
+								{
+									Asset:  "native",
+									Amount: "0.0000200",
+								},
+								{
+									Asset:  "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+									Amount: "0.0000100",
+								},
+							},
+							"total_shares": "0.0001000",
+						},
 						"reserves_received": []base.AssetAmount{
 							{
 								Asset:  "native",
@@ -2909,6 +3306,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 						"asset_code":   "USD",
 						"asset_issuer": "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						"asset_type":   "credit_alphanum4",
+						"asset_id":     FarmHashAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", "credit_alphanum4"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 1,
@@ -2921,6 +3319,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":     "0.0000010",
 						"asset_type": "native",
+						"asset_id":   FarmHashAsset("", "", "native"),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 1,
@@ -2936,7 +3335,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 							"asset":  "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						},
 						"liquidity_pool": map[string]interface{}{
-							"fee_bp": uint32(20),
+							"fee_bp": int64(20),
 							"id":     poolIDStr,
 							"reserves": []base.AssetAmount{
 								{
@@ -2952,10 +3351,25 @@ func TestLiquidityPoolEffects(t *testing.T) {
 							"total_trustlines": "10",
 							"type":             "constant_product",
 						},
+						"liquidity_pool_before": map[string]interface{}{
+							"reserves": []base.AssetAmount{
+								{
+									Asset:  "native",
+									Amount: "0.0000200",
+								},
+								{
+									Asset:  "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+									Amount: "0.0000100",
+								},
+							},
+							"total_shares": "0.0001000",
+						},
 						"sold": map[string]string{
 							"amount": "0.0000010",
 							"asset":  "native",
 						},
+						"trade_type":            TradeTypeLiquidityPool,
+						"liquidity_pool_fee_bp": int64(20),
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 1,
@@ -3097,6 +3511,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 						"asset_code":      "USD",
 						"asset_issuer":    "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						"asset_type":      "credit_alphanum4",
+						"asset_id":        FarmHashAsset("USD", "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY", "credit_alphanum4"),
 						"authorized_flag": false,
 						"trustor":         "GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 					},
@@ -3125,7 +3540,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 						"amount":     "0.0000100",
 						"asset":      "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
 						"balance_id": "000000000a0b000000000000000000000000000000000000000000000000000000000000",
-						"predicate":  xdr.ClaimPredicate{},
+						"predicate":  map[string]interface{}{"unconditional": true},
 					},
 					LedgerClosed:   genericCloseTime.UTC(),
 					LedgerSequence: 1,
@@ -3137,7 +3552,7 @@ func TestLiquidityPoolEffects(t *testing.T) {
 					OperationID: 4294967297,
 					Details: map[string]interface{}{
 						"liquidity_pool": map[string]interface{}{
-							"fee_bp": uint32(20),
+							"fee_bp": int64(20),
 							"id":     poolIDStr,
 							"reserves": []base.AssetAmount{
 								{
@@ -3153,6 +3568,19 @@ func TestLiquidityPoolEffects(t *testing.T) {
 							"total_trustlines": "10",
 							"type":             "constant_product",
 						},
+						"liquidity_pool_before": map[string]interface{}{
+							"reserves": []base.AssetAmount{
+								{
+									Asset:  "native",
+									Amount: "0.0000200",
+								},
+								{
+									Asset:  "USD:GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY",
+									Amount: "0.0000100",
+								},
+							},
+							"total_shares": "0.0001000",
+						},
 						"reserves_revoked": []map[string]string{
 							{
 								"amount":               "0.0000100",
@@ -3213,6 +3641,9 @@ func TestLiquidityPoolEffects(t *testing.T) {
 		for i := range tc.expected {
 			tc.expected[i].EffectIndex = uint32(i)
 			tc.expected[i].EffectId = fmt.Sprintf("%d-%d", tc.expected[i].OperationID, tc.expected[i].EffectIndex)
+			tc.expected[i].PagingToken = fmt.Sprintf("%d-%d", tc.expected[i].OperationID, tc.expected[i].EffectIndex+1)
+			tc.expected[i].TransactionSuccessful = true
+			tc.expected[i].Category = EffectCategories[EffectType(tc.expected[i].Type)]
 		}
 
 		t.Run(tc.desc, func(t *testing.T) {
@@ -3493,6 +3924,10 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 	admin := randAddr()
 	asset := xdr.MustNewCreditAsset("TESTER", admin)
 	nativeAsset := xdr.MustNewNativeAsset()
+	assetContractID, err := utils.AssetContractID(asset, networkPassphrase)
+	assert.NoError(t, err)
+	nativeContractID, err := utils.AssetContractID(nativeAsset, networkPassphrase)
+	assert.NoError(t, err)
 	from, to := randAddr(), randAddr()
 	fromContractBytes, toContractBytes := xdr.Hash{}, xdr.Hash{1}
 	fromContract := strkey.MustEncode(strkey.VersionByteContract, fromContractBytes[:])
@@ -3522,6 +3957,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountDebited),
@@ -3536,6 +3973,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountCredited),
@@ -3559,6 +3998,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            fromContract,
 						"contract_event_type": "transfer",
 					},
@@ -3574,6 +4015,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            toContract,
 						"contract_event_type": "transfer",
 					},
@@ -3596,6 +4039,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "mint",
 					},
 					Type:           int32(EffectAccountCredited),
@@ -3617,6 +4062,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "burn",
 					},
 					Type:           int32(EffectAccountDebited),
@@ -3639,6 +4086,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            fromContract,
 						"contract_event_type": "burn",
 					},
@@ -3661,6 +4110,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "clawback",
 					},
 					Type:           int32(EffectAccountDebited),
@@ -3683,6 +4134,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            fromContract,
 						"contract_event_type": "clawback",
 					},
@@ -3703,6 +4156,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":              "0.0012345",
 						"asset_type":          "native",
+						"asset_id":            FarmHashAsset("", "", "native"),
+						"asset_contract_id":   nativeContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountDebited),
@@ -3715,6 +4170,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 					Details: map[string]interface{}{
 						"amount":              "0.0012345",
 						"asset_type":          "native",
+						"asset_id":            FarmHashAsset("", "", "native"),
+						"asset_contract_id":   nativeContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountCredited),
@@ -3737,6 +4194,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountDebited),
@@ -3751,6 +4210,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            toContract,
 						"contract_event_type": "transfer",
 					},
@@ -3774,6 +4235,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract":            fromContract,
 						"contract_event_type": "transfer",
 					},
@@ -3789,6 +4252,8 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 						"asset_code":          strings.Trim(asset.GetCode(), "\x00"),
 						"asset_issuer":        asset.GetIssuer(),
 						"asset_type":          "credit_alphanum12",
+						"asset_id":            FarmHashAsset(strings.Trim(asset.GetCode(), "\x00"), asset.GetIssuer(), "credit_alphanum12"),
+						"asset_contract_id":   assetContractID,
 						"contract_event_type": "transfer",
 					},
 					Type:           int32(EffectAccountCredited),
@@ -3834,6 +4299,9 @@ func TestInvokeHostFunctionEffects(t *testing.T) {
 			for i := range testCase.expected {
 				testCase.expected[i].EffectIndex = uint32(i)
 				testCase.expected[i].EffectId = fmt.Sprintf("%d-%d", testCase.expected[i].OperationID, testCase.expected[i].EffectIndex)
+				testCase.expected[i].TransactionSuccessful = true
+				testCase.expected[i].Category = EffectCategories[EffectType(testCase.expected[i].Type)]
+				testCase.expected[i].PagingToken = fmt.Sprintf("%d-%d", testCase.expected[i].OperationID, testCase.expected[i].EffectIndex+1)
 			}
 
 			effects, err := operation.effects()
@@ -4015,14 +4483,20 @@ func TestBumpFootprintExpirationEffects(t *testing.T) {
 					"entries": []string{
 						ledgerEntryKeyStr,
 					},
-					"extend_to": xdr.Uint32(1234),
+					"entries_canonical": []string{
+						"ttl:0000000000000000000000000000000000000000000000000000000000000000",
+					},
+					"extend_to": int64(1234),
 				},
-				Type:           int32(EffectExtendFootprintTtl),
-				TypeString:     EffectTypeNames[EffectExtendFootprintTtl],
-				LedgerClosed:   time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
-				LedgerSequence: 1,
-				EffectIndex:    0,
-				EffectId:       fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 0),
+				Type:                  int32(EffectExtendFootprintTtl),
+				TypeString:            EffectTypeNames[EffectExtendFootprintTtl],
+				LedgerClosed:          time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+				LedgerSequence:        1,
+				EffectIndex:           0,
+				EffectId:              fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 0),
+				TransactionSuccessful: true,
+				Category:              "soroban",
+				PagingToken:           fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 1),
 			},
 		},
 		effects,
@@ -4133,15 +4607,431 @@ func TestAddRestoreFootprintExpirationEffect(t *testing.T) {
 					"entries": []string{
 						ledgerEntryKeyStr,
 					},
+					"entries_canonical": []string{
+						"ttl:0000000000000000000000000000000000000000000000000000000000000000",
+					},
 				},
-				Type:           int32(EffectRestoreFootprint),
-				TypeString:     EffectTypeNames[EffectRestoreFootprint],
-				LedgerClosed:   time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
-				LedgerSequence: 1,
-				EffectIndex:    0,
-				EffectId:       fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 0),
+				Type:                  int32(EffectRestoreFootprint),
+				TypeString:            EffectTypeNames[EffectRestoreFootprint],
+				LedgerClosed:          time.Date(1, time.January, 1, 0, 0, 0, 0, time.UTC),
+				LedgerSequence:        1,
+				EffectIndex:           0,
+				EffectId:              fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 0),
+				TransactionSuccessful: true,
+				Category:              "soroban",
+				PagingToken:           fmt.Sprintf("%d-%d", toid.New(1, 0, 1).ToInt64(), 1),
 			},
 		},
 		effects,
 	)
 }
+
+func TestUnfilledOfferCreatedEffects(t *testing.T) {
+	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	usdAsset := xdr.MustNewCreditAsset("USD", "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU")
+	nativeAsset := xdr.MustNewNativeAsset()
+
+	op := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  usdAsset,
+				Amount:  1000,
+				Price:   xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+	offer := xdr.OfferEntry{
+		SellerId: source.ToAccountId(),
+		OfferId:  11,
+		Selling:  nativeAsset,
+		Buying:   usdAsset,
+		Amount:   1000,
+		Price:    xdr.Price{N: 1, D: 2},
+	}
+	result := xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferResult: &xdr.ManageSellOfferResult{
+				Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+				Success: &xdr.ManageOfferSuccessResult{
+					Offer: xdr.ManageOfferSuccessResultOffer{
+						Effect: xdr.ManageOfferEffectManageOfferCreated,
+						Offer:  &offer,
+					},
+				},
+			},
+		},
+	}
+	changes := xdr.LedgerEntryChanges{
+		{
+			Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+			Created: &xdr.LedgerEntry{
+				LastModifiedLedgerSeq: 1,
+				Data:                  xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer, Offer: &offer},
+			},
+		},
+	}
+
+	operation := transactionOperationWrapper{
+		index: 0,
+		transaction: ingest.LedgerTransaction{
+			Index: 0,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: source,
+						Operations:    []xdr.Operation{op},
+					},
+				},
+			},
+			Result: xdr.TransactionResultPair{
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{
+						Results: &[]xdr.OperationResult{result},
+					},
+				},
+			},
+			UnsafeMeta: xdr.TransactionMeta{
+				V: 2,
+				V2: &xdr.TransactionMetaV2{
+					Operations: []xdr.OperationMeta{{Changes: changes}},
+				},
+			},
+		},
+		operation:      op,
+		ledgerSequence: 1,
+		ledgerClosed:   genericCloseTime.UTC(),
+	}
+
+	// like horizon, offers that were not filled have no effects by default
+	effects, err := operation.effects()
+	assert.NoError(t, err)
+	assert.Empty(t, effects)
+
+	operation.effectOptions = EffectOptions{UnfilledOffers: true}
+	effects, err = operation.effects()
+	assert.NoError(t, err)
+	assert.Equal(t, []EffectOutput{
+		{
+			Address:     source.Address(),
+			OperationID: 4294967297,
+			Details: map[string]interface{}{
+				"offer_id":            int64(11),
+				"offer_id_str":        "11",
+				"amount":              "0.0001000",
+				"selling_asset_type":  "native",
+				"selling_asset_id":    FarmHashAsset("", "", "native"),
+				"buying_asset_type":   "credit_alphanum4",
+				"buying_asset_code":   "USD",
+				"buying_asset_issuer": "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
+				"buying_asset_id":     FarmHashAsset("USD", "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU", "credit_alphanum4"),
+				"price":               0.5,
+				"price_r":             Price{Numerator: 1, Denominator: 2},
+			},
+			Type:                  int32(EffectOfferCreated),
+			TypeString:            EffectTypeNames[EffectOfferCreated],
+			LedgerClosed:          genericCloseTime.UTC(),
+			LedgerSequence:        1,
+			EffectId:              "4294967297-0",
+			PagingToken:           "4294967297-1",
+			TransactionSuccessful: true,
+			Category:              EffectCategories[EffectOfferCreated],
+		},
+	}, effects)
+}
+
+func TestZeroFillEffects(t *testing.T) {
+	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	seller := xdr.MustAddress("GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU")
+	usdAsset := xdr.MustNewCreditAsset("USD", "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU")
+	nativeAsset := xdr.MustNewNativeAsset()
+
+	op := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendOp: &xdr.PathPaymentStrictSendOp{
+				SendAsset:   nativeAsset,
+				SendAmount:  1,
+				Destination: source,
+				DestAsset:   usdAsset,
+				DestMin:     0,
+			},
+		},
+	}
+	// the claimed offer sells USD for a price that rounds the amounts down to zero
+	claim := xdr.ClaimAtom{
+		Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+		OrderBook: &xdr.ClaimOfferAtom{
+			SellerId:     seller,
+			OfferId:      12,
+			AssetSold:    usdAsset,
+			AmountSold:   0,
+			AssetBought:  nativeAsset,
+			AmountBought: 0,
+		},
+	}
+	result := xdr.OperationResult{
+		Code: xdr.OperationResultCodeOpInner,
+		Tr: &xdr.OperationResultTr{
+			Type: xdr.OperationTypePathPaymentStrictSend,
+			PathPaymentStrictSendResult: &xdr.PathPaymentStrictSendResult{
+				Code: xdr.PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess,
+				Success: &xdr.PathPaymentStrictSendResultSuccess{
+					Offers: []xdr.ClaimAtom{claim},
+					Last:   xdr.SimplePaymentResult{Destination: source.ToAccountId(), Asset: usdAsset, Amount: 0},
+				},
+			},
+		},
+	}
+
+	operation := transactionOperationWrapper{
+		index: 0,
+		transaction: ingest.LedgerTransaction{
+			Index: 0,
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: source,
+						Operations:    []xdr.Operation{op},
+					},
+				},
+			},
+			Result: xdr.TransactionResultPair{
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{
+						Results: &[]xdr.OperationResult{result},
+					},
+				},
+			},
+			UnsafeMeta: xdr.TransactionMeta{
+				V:  2,
+				V2: &xdr.TransactionMetaV2{Operations: []xdr.OperationMeta{{}}},
+			},
+		},
+		operation:      op,
+		ledgerSequence: 1,
+		ledgerClosed:   genericCloseTime.UTC(),
+	}
+
+	zeroFills := func(effects []EffectOutput) []EffectOutput {
+		filtered := []EffectOutput{}
+		for _, effect := range effects {
+			if effect.Type == int32(EffectTradeZeroFill) {
+				filtered = append(filtered, effect)
+			}
+		}
+		return filtered
+	}
+
+	// like horizon, claims rounded down to zero have no effects by default
+	effects, err := operation.effects()
+	assert.NoError(t, err)
+	assert.Len(t, effects, 2)
+	assert.Empty(t, zeroFills(effects))
+
+	operation.effectOptions = EffectOptions{ZeroFills: true}
+	effects, err = operation.effects()
+	assert.NoError(t, err)
+	effects = zeroFills(effects)
+	if assert.Len(t, effects, 2) {
+		assert.Equal(t, source.Address(), effects[0].Address)
+		assert.Equal(t, map[string]interface{}{
+			"offer_id":            int64(12),
+			"offer_id_str":        "12",
+			"seller":              seller.Address(),
+			"bought_amount":       "0.0000000",
+			"sold_amount":         "0.0000000",
+			"bought_asset_type":   "credit_alphanum4",
+			"bought_asset_code":   "USD",
+			"bought_asset_issuer": "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU",
+			"bought_asset_id":     FarmHashAsset("USD", "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU", "credit_alphanum4"),
+			"sold_asset_type":     "native",
+			"sold_asset_id":       FarmHashAsset("", "", "native"),
+			"trade_type":          TradeTypeOrderbook,
+		}, effects[0].Details)
+		assert.Equal(t, EffectTypeNames[EffectTradeZeroFill], effects[0].TypeString)
+		assert.Equal(t, EffectCategories[EffectTradeZeroFill], effects[0].Category)
+		assert.Equal(t, seller.Address(), effects[1].Address)
+		assert.Equal(t, "12", effects[1].Details["offer_id_str"])
+	}
+}
+
+func TestTransformEffectMalformedResult(t *testing.T) {
+	manageSellOffer := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  usdtAsset,
+				Amount:  100,
+				Price:   xdr.Price{N: 1, D: 1},
+			},
+		},
+	}
+	inflation := xdr.Operation{Body: xdr.OperationBody{Type: xdr.OperationTypeInflation}}
+
+	testCases := []struct {
+		desc      string
+		operation xdr.Operation
+		results   []xdr.OperationResult
+		wantErr   string
+	}{
+		{
+			desc:      "missing operation results",
+			operation: manageSellOffer,
+			results:   []xdr.OperationResult{},
+			wantErr:   "transaction result has no result for operation 0",
+		},
+		{
+			desc:      "operation not performed",
+			operation: manageSellOffer,
+			results:   []xdr.OperationResult{{Code: xdr.OperationResultCodeOpNoAccount}},
+			wantErr:   "result of operation 0 has code OperationResultCodeOpNoAccount",
+		},
+		{
+			desc:      "wrong result arm",
+			operation: inflation,
+			results: []xdr.OperationResult{{
+				Code: xdr.OperationResultCodeOpInner,
+				Tr: &xdr.OperationResultTr{
+					Type:          xdr.OperationTypeBumpSequence,
+					BumpSeqResult: &xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess},
+				},
+			}},
+			wantErr: "operation result is missing InflationResult",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			results := tc.results
+			transaction := ingest.LedgerTransaction{
+				Index: 1,
+				Envelope: xdr.TransactionEnvelope{
+					Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+					V1: &xdr.TransactionV1Envelope{
+						Tx: xdr.Transaction{
+							SourceAccount: testAccount1,
+							Operations:    []xdr.Operation{tc.operation},
+						},
+					},
+				},
+				Result: xdr.TransactionResultPair{
+					Result: xdr.TransactionResult{
+						Result: xdr.TransactionResultResult{
+							Code:    xdr.TransactionResultCodeTxSuccess,
+							Results: &results,
+						},
+					},
+				},
+				UnsafeMeta: createTransactionMeta([]xdr.OperationMeta{{}}),
+			}
+
+			_, err := TransformEffect(transaction, 2, genericLedgerCloseMeta, "", toid.Generator{})
+
+			var transformErr *TransformError
+			assert.ErrorAs(t, err, &transformErr)
+			assert.Equal(t, ErrorCodeInvalidEffect, transformErr.Code)
+			assert.Equal(t, uint32(2), transformErr.LedgerSequence)
+			assert.Equal(t, int32(0), transformErr.OperationIndex)
+			assert.EqualError(t, transformErr.Err, tc.wantErr)
+		})
+	}
+}
+
+func TestTransformEffectOperationTypes(t *testing.T) {
+	// the manage sell offer has no result, so its effects can only be transformed with an error
+	manageSellOffer := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  usdtAsset,
+				Amount:  100,
+				Price:   xdr.Price{N: 1, D: 1},
+			},
+		},
+	}
+	results := []xdr.OperationResult{}
+	transaction := ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations:    []xdr.Operation{manageSellOffer},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &results,
+				},
+			},
+		},
+		UnsafeMeta: createTransactionMeta([]xdr.OperationMeta{{}}),
+	}
+
+	effects, err := TransformEffectWithOptions(transaction, 2, genericLedgerCloseMeta, "", toid.Generator{}, EffectOptions{
+		OperationTypes: map[xdr.OperationType]bool{xdr.OperationTypePayment: true},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, effects)
+
+	_, err = TransformEffectWithOptions(transaction, 2, genericLedgerCloseMeta, "", toid.Generator{}, EffectOptions{
+		OperationTypes: map[xdr.OperationType]bool{xdr.OperationTypeManageSellOffer: true},
+	})
+	assert.Error(t, err)
+}
+
+func TestTransformEffectContractIDs(t *testing.T) {
+	// the manage sell offer has no result, so its effects can only be transformed with an error
+	transaction := ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations: []xdr.Operation{{
+						Body: xdr.OperationBody{
+							Type: xdr.OperationTypeManageSellOffer,
+							ManageSellOfferOp: &xdr.ManageSellOfferOp{
+								Selling: nativeAsset,
+								Buying:  usdtAsset,
+								Amount:  100,
+								Price:   xdr.Price{N: 1, D: 1},
+							},
+						},
+					}},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{
+					Code:    xdr.TransactionResultCodeTxSuccess,
+					Results: &[]xdr.OperationResult{},
+				},
+			},
+		},
+		UnsafeMeta: createTransactionMeta([]xdr.OperationMeta{{}}),
+	}
+
+	effects, err := TransformEffectWithOptions(transaction, 2, genericLedgerCloseMeta, "", toid.Generator{}, EffectOptions{
+		ContractIDs: map[xdr.Hash]bool{{1}: true},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, effects)
+
+	_, err = TransformEffectWithOptions(transaction, 2, genericLedgerCloseMeta, "", toid.Generator{}, EffectOptions{})
+	assert.Error(t, err)
+}
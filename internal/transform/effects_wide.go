@@ -0,0 +1,108 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/guregu/null"
+)
+
+// TransformWideEffect moves the most common details of an effect into top-level columns. The details that are not
+// columns, or whose value does not have the type of their column, stay in the details object.
+func TransformWideEffect(effect EffectOutput) (EffectWideOutput, error) {
+	details, err := decodeEffectDetails(effect.Details)
+	if err != nil {
+		return EffectWideOutput{}, fmt.Errorf("could not decode details of effect %s: %v", effect.EffectId, err)
+	}
+
+	return EffectWideOutput{
+		Address:               effect.Address,
+		AddressMuxed:          effect.AddressMuxed,
+		OperationID:           effect.OperationID,
+		Type:                  effect.Type,
+		TypeString:            effect.TypeString,
+		LedgerClosed:          effect.LedgerClosed,
+		LedgerSequence:        effect.LedgerSequence,
+		EffectIndex:           effect.EffectIndex,
+		EffectId:              effect.EffectId,
+		TransactionSuccessful: effect.TransactionSuccessful,
+		Category:              effect.Category,
+		PagingToken:           effect.PagingToken,
+		Amount:                takeDetailString(details, "amount"),
+		StartingBalance:       takeDetailString(details, "starting_balance"),
+		Asset:                 takeDetailString(details, "asset"),
+		AssetType:             takeDetailString(details, "asset_type"),
+		AssetCode:             takeDetailString(details, "asset_code"),
+		AssetIssuer:           takeDetailString(details, "asset_issuer"),
+		AssetID:               takeDetailInt(details, "asset_id"),
+		AssetContractID:       takeDetailString(details, "asset_contract_id"),
+		BalanceID:             takeDetailString(details, "balance_id"),
+		LiquidityPoolID:       takeDetailString(details, "liquidity_pool_id"),
+		OfferID:               takeDetailInt(details, "offer_id"),
+		OfferIDStr:            takeDetailString(details, "offer_id_str"),
+		Seller:                takeDetailString(details, "seller"),
+		SoldAmount:            takeDetailString(details, "sold_amount"),
+		SoldAssetType:         takeDetailString(details, "sold_asset_type"),
+		SoldAssetCode:         takeDetailString(details, "sold_asset_code"),
+		SoldAssetIssuer:       takeDetailString(details, "sold_asset_issuer"),
+		SoldAssetID:           takeDetailInt(details, "sold_asset_id"),
+		BoughtAmount:          takeDetailString(details, "bought_amount"),
+		BoughtAssetType:       takeDetailString(details, "bought_asset_type"),
+		BoughtAssetCode:       takeDetailString(details, "bought_asset_code"),
+		BoughtAssetIssuer:     takeDetailString(details, "bought_asset_issuer"),
+		BoughtAssetID:         takeDetailInt(details, "bought_asset_id"),
+		Trustor:               takeDetailString(details, "trustor"),
+		PublicKey:             takeDetailString(details, "public_key"),
+		Weight:                takeDetailInt(details, "weight"),
+		Sponsor:               takeDetailString(details, "sponsor"),
+		FormerSponsor:         takeDetailString(details, "former_sponsor"),
+		NewSponsor:            takeDetailString(details, "new_sponsor"),
+		Contract:              takeDetailString(details, "contract"),
+		TradeType:             takeDetailString(details, "trade_type"),
+		LiquidityPoolFeeBp:    takeDetailInt(details, "liquidity_pool_fee_bp"),
+		Details:               details,
+	}, nil
+}
+
+// decodeEffectDetails copies the details through their JSON encoding, so that the values have the types they are
+// exported with and the effect itself is left untouched
+func decodeEffectDetails(details map[string]interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	decoded := map[string]interface{}{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	if decoded == nil {
+		decoded = map[string]interface{}{}
+	}
+	return decoded, nil
+}
+
+func takeDetailString(details map[string]interface{}, key string) null.String {
+	value, ok := details[key].(string)
+	if !ok {
+		return null.String{}
+	}
+	delete(details, key)
+	return null.StringFrom(value)
+}
+
+func takeDetailInt(details map[string]interface{}, key string) null.Int {
+	number, ok := details[key].(json.Number)
+	if !ok {
+		return null.Int{}
+	}
+	value, err := number.Int64()
+	if err != nil {
+		return null.Int{}
+	}
+	delete(details, key)
+	return null.IntFrom(value)
+}
@@ -0,0 +1,101 @@
+package transform
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/protocols/horizon/base"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformWideEffect(t *testing.T) {
+	closedAt := time.Unix(1594586912, 0).UTC()
+	effect := EffectOutput{
+		Address:               "GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A",
+		OperationID:           42949677057,
+		Type:                  int32(EffectTrade),
+		TypeString:            EffectTypeNames[EffectTrade],
+		LedgerClosed:          closedAt,
+		LedgerSequence:        10,
+		EffectIndex:           1,
+		EffectId:              "42949677057-1",
+		PagingToken:           "42949677057-2",
+		TransactionSuccessful: true,
+		Category:              "trade",
+		Details: map[string]interface{}{
+			"seller":            "GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
+			"offer_id":          int64(97),
+			"offer_id_str":      "97",
+			"sold_amount":       "10.0000000",
+			"sold_asset_type":   "native",
+			"sold_asset_id":     FarmHashAsset("", "", "native"),
+			"bought_amount":     "5.0000000",
+			"bought_asset_type": "credit_alphanum4",
+			"bought_asset_code": "USDT",
+			"price":             0.5,
+			"reserves":          []base.AssetAmount{{Asset: "native", Amount: "1.0000000"}},
+			"trade_type":        TradeTypeOrderbook,
+		},
+	}
+
+	wide, err := TransformWideEffect(effect)
+	require.NoError(t, err)
+
+	assert.Equal(t, EffectWideOutput{
+		Address:               effect.Address,
+		OperationID:           42949677057,
+		Type:                  int32(EffectTrade),
+		TypeString:            "trade",
+		LedgerClosed:          closedAt,
+		LedgerSequence:        10,
+		EffectIndex:           1,
+		EffectId:              "42949677057-1",
+		PagingToken:           "42949677057-2",
+		TransactionSuccessful: true,
+		Category:              "trade",
+		Seller:                null.StringFrom("GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN"),
+		OfferID:               null.IntFrom(97),
+		OfferIDStr:            null.StringFrom("97"),
+		SoldAmount:            null.StringFrom("10.0000000"),
+		SoldAssetType:         null.StringFrom("native"),
+		SoldAssetID:           null.IntFrom(FarmHashAsset("", "", "native")),
+		BoughtAmount:          null.StringFrom("5.0000000"),
+		BoughtAssetType:       null.StringFrom("credit_alphanum4"),
+		BoughtAssetCode:       null.StringFrom("USDT"),
+		TradeType:             null.StringFrom(TradeTypeOrderbook),
+		Details: map[string]interface{}{
+			"price":    json.Number("0.5"),
+			"reserves": []interface{}{map[string]interface{}{"asset": "native", "amount": "1.0000000"}},
+		},
+	}, wide)
+
+	// the effect keeps all of its details
+	assert.Len(t, effect.Details, 12)
+}
+
+func TestTransformWideEffectMismatchedTypes(t *testing.T) {
+	wide, err := TransformWideEffect(EffectOutput{Details: map[string]interface{}{
+		"amount":   10,
+		"offer_id": "97",
+		"weight":   1.5,
+	}})
+	require.NoError(t, err)
+
+	assert.False(t, wide.Amount.Valid)
+	assert.False(t, wide.OfferID.Valid)
+	assert.False(t, wide.Weight.Valid)
+	assert.Equal(t, map[string]interface{}{
+		"amount":   json.Number("10"),
+		"offer_id": "97",
+		"weight":   json.Number("1.5"),
+	}, wide.Details)
+}
+
+func TestTransformWideEffectNoDetails(t *testing.T) {
+	wide, err := TransformWideEffect(EffectOutput{EffectId: "1-0"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{}, wide.Details)
+}
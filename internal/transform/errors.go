@@ -0,0 +1,187 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// ErrorCode is the machine-readable class of a transform error, so that failed rows can be counted and routed
+// without parsing the error messages
+type ErrorCode string
+
+const (
+	ErrorCodeInvalidLedger      ErrorCode = "invalid_ledger"
+	ErrorCodeInvalidTransaction ErrorCode = "invalid_transaction"
+	ErrorCodeInvalidOperation   ErrorCode = "invalid_operation"
+	ErrorCodeInvalidEffect      ErrorCode = "invalid_effect"
+	ErrorCodeInvalidTrade       ErrorCode = "invalid_trade"
+	ErrorCodeInvalidEvent       ErrorCode = "invalid_event"
+	ErrorCodeInvalidLedgerEntry ErrorCode = "invalid_ledger_entry"
+	// ErrorCodeUnsupportedVersion is returned for the parts of a ledger whose version cannot exist at the protocol of
+	// the ledger, or that the transforms cannot read yet
+	ErrorCodeUnsupportedVersion ErrorCode = "unsupported_version"
+	ErrorCodeUnknown            ErrorCode = "unknown"
+)
+
+// Field names of the coordinates of a transform error in the structured log lines, next to the shared ones of utils
+const (
+	LogFieldErrorCode       = "error_code"
+	LogFieldOperationIndex  = "op_index"
+	LogFieldOperationType   = "op_type"
+	LogFieldLedgerEntryType = "ledger_entry_type"
+)
+
+// TransformError is returned by the transforms when a row cannot be derived from a ledger, along with the coordinates
+// of what was being transformed. The coordinates that do not apply, or that the transform is not given, are left
+// empty.
+type TransformError struct {
+	Code            ErrorCode
+	Network         string
+	LedgerSequence  uint32
+	TransactionHash string
+	// OperationIndex is the index of the operation in its transaction, or -1 when the error is not about an operation
+	OperationIndex int32
+	// OperationType is the type of the operation that was transformed, or whose effects were being built
+	OperationType   string
+	LedgerEntryType string
+	Err             error
+}
+
+func (e *TransformError) Error() string {
+	coordinates := []string{}
+	if e.Network != "" {
+		coordinates = append(coordinates, "network "+e.Network)
+	}
+	if e.LedgerSequence != 0 {
+		coordinates = append(coordinates, fmt.Sprintf("ledger %d", e.LedgerSequence))
+	}
+	if e.TransactionHash != "" {
+		coordinates = append(coordinates, "transaction "+e.TransactionHash)
+	}
+	if e.OperationIndex >= 0 {
+		coordinates = append(coordinates, fmt.Sprintf("operation %d", e.OperationIndex))
+	}
+	if e.OperationType != "" {
+		coordinates = append(coordinates, "operation type "+e.OperationType)
+	}
+	if e.LedgerEntryType != "" {
+		coordinates = append(coordinates, "ledger entry type "+e.LedgerEntryType)
+	}
+	return fmt.Sprintf("%s (%s): %v", e.Code, strings.Join(coordinates, ", "), e.Err)
+}
+
+func (e *TransformError) Unwrap() error {
+	return e.Err
+}
+
+// LogFields returns the code and coordinates of the error as structured log fields
+func (e *TransformError) LogFields() log.F {
+	fields := log.F{LogFieldErrorCode: string(e.Code)}
+	if e.Network != "" {
+		fields[utils.LogFieldNetwork] = e.Network
+	}
+	if e.LedgerSequence != 0 {
+		fields[utils.LogFieldLedger] = e.LedgerSequence
+	}
+	if e.TransactionHash != "" {
+		fields[utils.LogFieldTxHash] = e.TransactionHash
+	}
+	if e.OperationIndex >= 0 {
+		fields[LogFieldOperationIndex] = e.OperationIndex
+	}
+	if e.OperationType != "" {
+		fields[LogFieldOperationType] = e.OperationType
+	}
+	if e.LedgerEntryType != "" {
+		fields[LogFieldLedgerEntryType] = e.LedgerEntryType
+	}
+	return fields
+}
+
+// ErrorCodeOf returns the code of a transform error, or ErrorCodeUnknown if the error does not wrap one
+func ErrorCodeOf(err error) ErrorCode {
+	var transformErr *TransformError
+	if errors.As(err, &transformErr) {
+		return transformErr.Code
+	}
+	return ErrorCodeUnknown
+}
+
+// wrapError sets *err to a TransformError with the given code and coordinates. If the error already wraps a
+// TransformError, as when a transform calls another one, its code is kept and only its missing coordinates are set.
+// It is deferred by the transforms over their named error result.
+func wrapError(err *error, coordinates TransformError) {
+	if *err == nil {
+		return
+	}
+	var transformErr *TransformError
+	if errors.As(*err, &transformErr) {
+		if transformErr.Network == "" {
+			transformErr.Network = coordinates.Network
+		}
+		if transformErr.LedgerSequence == 0 {
+			transformErr.LedgerSequence = coordinates.LedgerSequence
+		}
+		if transformErr.TransactionHash == "" {
+			transformErr.TransactionHash = coordinates.TransactionHash
+		}
+		if transformErr.OperationIndex < 0 {
+			transformErr.OperationIndex = coordinates.OperationIndex
+		}
+		if transformErr.OperationType == "" {
+			transformErr.OperationType = coordinates.OperationType
+		}
+		if transformErr.LedgerEntryType == "" {
+			transformErr.LedgerEntryType = coordinates.LedgerEntryType
+		}
+		return
+	}
+	coordinates.Err = *err
+	*err = &coordinates
+}
+
+// ledgerCoordinates returns the coordinates of a ledger
+func ledgerCoordinates(code ErrorCode, ledgerSeq uint32, networkPassphrase string) TransformError {
+	return TransformError{
+		Code:           code,
+		Network:        utils.NetworkName(networkPassphrase),
+		LedgerSequence: ledgerSeq,
+		OperationIndex: -1,
+	}
+}
+
+// transactionCoordinates returns the coordinates of a transaction in its ledger
+func transactionCoordinates(code ErrorCode, transaction ingest.LedgerTransaction, ledgerSeq uint32, networkPassphrase string) TransformError {
+	coordinates := ledgerCoordinates(code, ledgerSeq, networkPassphrase)
+	coordinates.TransactionHash = transaction.Result.TransactionHash.HexString()
+	return coordinates
+}
+
+// operationCoordinates returns the coordinates of an operation in its transaction
+func operationCoordinates(code ErrorCode, operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq uint32, networkPassphrase string) TransformError {
+	coordinates := transactionCoordinates(code, transaction, ledgerSeq, networkPassphrase)
+	coordinates.OperationIndex = operationIndex
+	coordinates.OperationType, _ = mapOperationType(operation)
+	return coordinates
+}
+
+// changeCoordinates returns the coordinates of a ledger entry change, along with the transaction and operation that
+// made it when the change reader sets them
+func changeCoordinates(change ingest.Change, ledgerSeq uint32) TransformError {
+	coordinates := ledgerCoordinates(ErrorCodeInvalidLedgerEntry, ledgerSeq, "")
+	coordinates.LedgerEntryType = change.Type.String()
+	if change.Transaction != nil {
+		coordinates.TransactionHash = change.Transaction.Result.TransactionHash.HexString()
+		if change.Reason == ingest.LedgerEntryChangeReasonOperation {
+			coordinates.OperationIndex = int32(change.OperationIndex)
+		}
+	}
+	return coordinates
+}
@@ -0,0 +1,81 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertTransformError asserts that err is nil if want is, and otherwise a TransformError wrapping want
+func assertTransformError(t *testing.T, want, err error) {
+	t.Helper()
+	if want == nil {
+		assert.NoError(t, err)
+		return
+	}
+	var transformErr *TransformError
+	if assert.ErrorAs(t, err, &transformErr) {
+		assert.Equal(t, want, transformErr.Err)
+	}
+}
+
+func TestTransformErrorCoordinates(t *testing.T) {
+	hash := xdr.Hash{0xab}
+	transaction := ingest.LedgerTransaction{Result: xdr.TransactionResultPair{TransactionHash: hash}}
+	payment := xdr.Operation{Body: xdr.OperationBody{Type: xdr.OperationTypePayment, PaymentOp: &xdr.PaymentOp{}}}
+	cause := errors.New("malformed result")
+
+	err := func() (err error) {
+		defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidEffect, transaction, 30, network.PublicNetworkPassphrase))
+		return func() (err error) {
+			defer wrapError(&err, operationCoordinates(ErrorCodeInvalidOperation, payment, 2, transaction, 0, ""))
+			return cause
+		}()
+	}()
+
+	var transformErr *TransformError
+	require.ErrorAs(t, err, &transformErr)
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, ErrorCodeInvalidOperation, ErrorCodeOf(err))
+	assert.Equal(t, "invalid_operation (network pubnet, ledger 30, transaction "+hash.HexString()+", operation 2, operation type payment): malformed result", err.Error())
+	assert.Equal(t, log.F{
+		"error_code": "invalid_operation",
+		"network":    "pubnet",
+		"ledger":     uint32(30),
+		"tx_hash":    hash.HexString(),
+		"op_index":   int32(2),
+		"op_type":    "payment",
+	}, transformErr.LogFields())
+
+	assert.Equal(t, ErrorCodeUnknown, ErrorCodeOf(cause))
+}
+
+func TestChangeCoordinates(t *testing.T) {
+	transaction := ingest.LedgerTransaction{Result: xdr.TransactionResultPair{TransactionHash: xdr.Hash{0xcd}}}
+	change := ingest.Change{
+		Type:           xdr.LedgerEntryTypeTrustline,
+		Reason:         ingest.LedgerEntryChangeReasonOperation,
+		OperationIndex: 1,
+		Transaction:    &transaction,
+	}
+
+	coordinates := changeCoordinates(change, 10)
+	assert.Equal(t, TransformError{
+		Code:            ErrorCodeInvalidLedgerEntry,
+		LedgerSequence:  10,
+		TransactionHash: transaction.Result.TransactionHash.HexString(),
+		OperationIndex:  1,
+		LedgerEntryType: "LedgerEntryTypeTrustline",
+	}, coordinates)
+
+	change.Reason, change.Transaction = ingest.LedgerEntryChangeReasonUpgrade, nil
+	coordinates = changeCoordinates(change, 10)
+	assert.Equal(t, int32(-1), coordinates.OperationIndex)
+	assert.Empty(t, coordinates.TransactionHash)
+}
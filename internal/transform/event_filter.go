@@ -0,0 +1,128 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/go/xdr"
+)
+
+// wildcardTopic matches any value of a topic, as long as the event has that topic
+const wildcardTopic = "*"
+
+// EventFilter matches contract events by their topics. It is parsed from expressions such as
+// `topic0=transfer,topic2=*`: the event matches when every one of its terms does.
+//   - topicN=value matches the events whose topic N is the symbol or string value, the address with that strkey, or
+//     the ScVal whose base64 xdr is the value. topicN=* matches any value of topic N, like the wildcard segments
+//     of the getEvents filters of Stellar RPC.
+//   - topics=N matches the events with exactly N topics. Without it, the events can have more topics than the terms
+//     name, like a trailing ** segment of Stellar RPC.
+type EventFilter struct {
+	Topics     map[int]string
+	TopicCount int
+}
+
+// ParseEventFilters parses the expressions of event filters. It returns nil when no expressions are given.
+func ParseEventFilters(expressions []string) ([]EventFilter, error) {
+	if len(expressions) == 0 {
+		return nil, nil
+	}
+
+	filters := make([]EventFilter, 0, len(expressions))
+	for _, expression := range expressions {
+		filter, err := parseEventFilter(expression)
+		if err != nil {
+			return nil, fmt.Errorf("invalid event filter %s: %v", expression, err)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+func parseEventFilter(expression string) (EventFilter, error) {
+	filter := EventFilter{Topics: map[int]string{}, TopicCount: -1}
+	for _, term := range strings.Split(expression, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(term), "=")
+		if !ok || value == "" {
+			return filter, fmt.Errorf("term %q is not of the form topicN=value or topics=N", term)
+		}
+
+		if key == "topics" {
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 0 {
+				return filter, fmt.Errorf("topic count %q is not a number", value)
+			}
+			filter.TopicCount = count
+			continue
+		}
+
+		index, err := strconv.Atoi(strings.TrimPrefix(key, "topic"))
+		if !strings.HasPrefix(key, "topic") || err != nil || index < 0 {
+			return filter, fmt.Errorf("unknown key %q; expected topicN or topics", key)
+		}
+		if _, ok := filter.Topics[index]; ok {
+			return filter, fmt.Errorf("topic%d is set more than once", index)
+		}
+		filter.Topics[index] = value
+	}
+
+	if filter.TopicCount >= 0 {
+		for index := range filter.Topics {
+			if index >= filter.TopicCount {
+				return filter, fmt.Errorf("topic%d is past the %d topics of the filter", index, filter.TopicCount)
+			}
+		}
+	}
+	return filter, nil
+}
+
+// Matches tells whether the topics of an event match the filter
+func (f EventFilter) Matches(topics []xdr.ScVal) bool {
+	if f.TopicCount >= 0 && len(topics) != f.TopicCount {
+		return false
+	}
+	for index, value := range f.Topics {
+		if index >= len(topics) {
+			return false
+		}
+		if value != wildcardTopic && !topicMatches(topics[index], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesAnyEventFilter tells whether the topics of an event match one of the filters, or whether there are no
+// filters
+func matchesAnyEventFilter(filters []EventFilter, topics []xdr.ScVal) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, filter := range filters {
+		if filter.Matches(topics) {
+			return true
+		}
+	}
+	return false
+}
+
+func topicMatches(topic xdr.ScVal, value string) bool {
+	switch topic.Type {
+	case xdr.ScValTypeScvSymbol:
+		if string(topic.MustSym()) == value {
+			return true
+		}
+	case xdr.ScValTypeScvString:
+		if string(topic.MustStr()) == value {
+			return true
+		}
+	case xdr.ScValTypeScvAddress:
+		if address, err := topic.MustAddress().String(); err == nil && address == value {
+			return true
+		}
+	}
+
+	encoded, err := xdr.MarshalBase64(topic)
+	return err == nil && encoded == value
+}
@@ -0,0 +1,67 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEventFilters(t *testing.T) {
+	filters, err := ParseEventFilters(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, filters)
+
+	filters, err = ParseEventFilters([]string{"topic0=transfer, topic2=*", "topic0=mint,topics=3"})
+	assert.NoError(t, err)
+	assert.Equal(t, []EventFilter{
+		{Topics: map[int]string{0: "transfer", 2: "*"}, TopicCount: -1},
+		{Topics: map[int]string{0: "mint"}, TopicCount: 3},
+	}, filters)
+
+	for _, expression := range []string{"transfer", "topic0=", "topicx=transfer", "data=1", "topics=-1", "topic0=a,topic0=b", "topic3=*,topics=2"} {
+		_, err := ParseEventFilters([]string{expression})
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestEventFilterMatches(t *testing.T) {
+	transfer := xdr.ScSymbol("transfer")
+	memo := xdr.ScString("memo")
+	account := xdr.MustAddress("GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7")
+	amount := xdr.Uint32(7)
+	topics := []xdr.ScVal{
+		{Type: xdr.ScValTypeScvSymbol, Sym: &transfer},
+		{Type: xdr.ScValTypeScvAddress, Address: &xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeAccount, AccountId: &account}},
+		{Type: xdr.ScValTypeScvString, Str: &memo},
+	}
+	encoded, err := xdr.MarshalBase64(xdr.ScVal{Type: xdr.ScValTypeScvU32, U32: &amount})
+	require.NoError(t, err)
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{"topic0=transfer", true},
+		{"topic0=mint", false},
+		{"topic0=transfer,topic1=GAAZI4TCR3TY5OJHCTJC2A4QSY6CJWJH5IAJTGKIN2ER7LBNVKOCCWN7", true},
+		{"topic2=memo", true},
+		{"topic2=*", true},
+		{"topic3=*", false},
+		{"topic0=transfer,topics=3", true},
+		{"topic0=transfer,topics=4", false},
+		{"topic2=" + encoded, false},
+	}
+	for _, test := range tests {
+		filters, err := ParseEventFilters([]string{test.expression})
+		require.NoError(t, err)
+		assert.Equal(t, test.want, filters[0].Matches(topics), test.expression)
+	}
+
+	filters, err := ParseEventFilters([]string{"topic0=" + encoded})
+	require.NoError(t, err)
+	assert.True(t, filters[0].Matches([]xdr.ScVal{{Type: xdr.ScValTypeScvU32, U32: &amount}}))
+	assert.True(t, matchesAnyEventFilter(nil, topics))
+	assert.False(t, matchesAnyEventFilter(filters, topics))
+}
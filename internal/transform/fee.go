@@ -0,0 +1,101 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/processors/token_transfer"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformFee converts the fee events of a transaction into a row of the fees table. The fee events are the
+// CAP-67 events of the unified events stream: a debit of the fee charged up front and, for Soroban transactions,
+// a credit of the refunded resource fee.
+func TransformFee(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, networkPassphrase string, ids toid.Generator) (_ FeeOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidTransaction, transaction, uint32(lhe.Header.LedgerSeq), networkPassphrase))
+	if err := checkTransactionMeta(transaction.UnsafeMeta, uint32(lhe.Header.LedgerVersion)); err != nil {
+		return FeeOutput{}, err
+	}
+	eventsProcessor := token_transfer.NewEventsProcessor(networkPassphrase)
+	events, err := eventsProcessor.EventsFromTransaction(transaction)
+	if err != nil {
+		return FeeOutput{}, fmt.Errorf("for ledger %d; transaction %d: %v", lhe.Header.LedgerSeq, transaction.Index, err)
+	}
+
+	return transformFeeEvents(events.FeeEvents, transaction, lhe, ids)
+}
+
+func transformFeeEvents(events []*token_transfer.TokenTransferEvent, transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, ids toid.Generator) (FeeOutput, error) {
+	ledgerHeader := lhe.Header
+	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
+	transactionIndex := uint32(transaction.Index)
+	outputTransactionID := ids.New(int32(outputLedgerSequence), int32(transactionIndex), 0)
+
+	if len(events) == 0 {
+		return FeeOutput{}, fmt.Errorf("no fee events for ledger %d; transaction %d (transaction id=%d)", outputLedgerSequence, transactionIndex, outputTransactionID)
+	}
+
+	var outputFeeAccount string
+	var outputInitialFeeCharged, outputFeeRefund int64
+	for _, event := range events {
+		fee := event.GetFee()
+		if fee == nil {
+			return FeeOutput{}, fmt.Errorf("the %s event is not a fee event for ledger %d; transaction %d (transaction id=%d)", event.GetEventType(), outputLedgerSequence, transactionIndex, outputTransactionID)
+		}
+		amount, err := strconv.ParseInt(fee.Amount, 10, 64)
+		if err != nil {
+			return FeeOutput{}, fmt.Errorf("could not parse fee amount %s for ledger %d; transaction %d (transaction id=%d): %v", fee.Amount, outputLedgerSequence, transactionIndex, outputTransactionID, err)
+		}
+
+		// Refunds are fee events with a negative amount
+		if amount < 0 {
+			outputFeeRefund += -amount
+		} else {
+			outputInitialFeeCharged += amount
+		}
+		outputFeeAccount = fee.From
+	}
+
+	// The inclusion fee is paid for every operation, and for the fee bump itself
+	operationCount := int64(len(transaction.Envelope.Operations()))
+	outputMaxFee := int64(transaction.Envelope.Fee())
+	if transaction.Envelope.Type == xdr.EnvelopeTypeEnvelopeTypeTxFeeBump {
+		operationCount += 1
+		outputMaxFee = transaction.Envelope.FeeBumpFee()
+	}
+
+	outputInclusionFeeCharged := outputInitialFeeCharged
+	outputSoroban := false
+	if sorobanData, ok := transaction.GetSorobanData(); ok {
+		outputSoroban = true
+		outputInclusionFeeCharged -= int64(sorobanData.ResourceFee)
+	}
+
+	outputBaseFee := uint32(ledgerHeader.BaseFee)
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(ledgerHeader.ScpValue.CloseTime)
+	if err != nil {
+		return FeeOutput{}, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %v", outputLedgerSequence, transactionIndex, outputTransactionID, err)
+	}
+
+	transformedFee := FeeOutput{
+		TransactionHash:     utils.HashToHexString(transaction.Result.TransactionHash),
+		TransactionID:       outputTransactionID,
+		LedgerSequence:      outputLedgerSequence,
+		FeeAccount:          outputFeeAccount,
+		MaxFee:              outputMaxFee,
+		InitialFeeCharged:   outputInitialFeeCharged,
+		FeeRefund:           outputFeeRefund,
+		FeeCharged:          outputInitialFeeCharged - outputFeeRefund,
+		InclusionFeeCharged: outputInclusionFeeCharged,
+		BaseFee:             outputBaseFee,
+		SurgePriced:         outputInclusionFeeCharged > int64(outputBaseFee)*operationCount,
+		Soroban:             outputSoroban,
+		Successful:          transaction.Result.Successful(),
+		ClosedAt:            outputCloseTime,
+	}
+
+	return transformedFee, nil
+}
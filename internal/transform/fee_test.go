@@ -0,0 +1,136 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/asset"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/processors/token_transfer"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+)
+
+func makeFeeTestEvents(amounts ...string) []*token_transfer.TokenTransferEvent {
+	events := []*token_transfer.TokenTransferEvent{}
+	for _, amount := range amounts {
+		meta := &token_transfer.EventMeta{LedgerSequence: 30, TxHash: "txhash", TransactionIndex: 1}
+		events = append(events, token_transfer.NewFeeEvent(meta, testAccount1Address, amount, asset.NewNativeAsset()))
+	}
+	return events
+}
+
+func makeFeeTestInput() (classic, soroban ingest.LedgerTransaction, header xdr.LedgerHeaderHistoryEntry) {
+	classic = genericLedgerTransaction
+	classic.Result.TransactionHash = xdr.Hash{1}
+
+	sorobanEnvelope := genericBumpOperationEnvelope
+	sorobanEnvelope.Tx.Ext = xdr.TransactionExt{
+		V:           1,
+		SorobanData: &xdr.SorobanTransactionData{ResourceFee: 1000},
+	}
+	soroban = genericLedgerTransaction
+	soroban.Result.TransactionHash = xdr.Hash{2}
+	soroban.Envelope = xdr.TransactionEnvelope{
+		Type: xdr.EnvelopeTypeEnvelopeTypeTxFeeBump,
+		FeeBump: &xdr.FeeBumpTransactionEnvelope{
+			Tx: xdr.FeeBumpTransaction{
+				FeeSource: testAccount1,
+				Fee:       3000,
+				InnerTx: xdr.FeeBumpTransactionInnerTx{
+					Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+					V1:   &sorobanEnvelope,
+				},
+			},
+		},
+	}
+
+	header = xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			LedgerSeq: 30,
+			BaseFee:   100,
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+		},
+	}
+	return
+}
+
+func TestTransformFeeEvents(t *testing.T) {
+	classic, soroban, header := makeFeeTestInput()
+	closedAt := time.Unix(1000, 0).UTC()
+
+	tests := []struct {
+		name        string
+		events      []*token_transfer.TokenTransferEvent
+		transaction ingest.LedgerTransaction
+		wantOutput  FeeOutput
+	}{
+		{
+			name:        "classic",
+			events:      makeFeeTestEvents("100"),
+			transaction: classic,
+			wantOutput: FeeOutput{
+				TransactionHash:     "0100000000000000000000000000000000000000000000000000000000000000",
+				TransactionID:       128849022976,
+				LedgerSequence:      30,
+				FeeAccount:          testAccount1Address,
+				MaxFee:              int64(classic.Envelope.Fee()),
+				InitialFeeCharged:   100,
+				FeeCharged:          100,
+				InclusionFeeCharged: 100,
+				BaseFee:             100,
+				Successful:          true,
+				ClosedAt:            closedAt,
+			},
+		},
+		{
+			name:        "soroban fee bump with refund",
+			events:      makeFeeTestEvents("1500", "-200"),
+			transaction: soroban,
+			wantOutput: FeeOutput{
+				TransactionHash:     "0200000000000000000000000000000000000000000000000000000000000000",
+				TransactionID:       128849022976,
+				LedgerSequence:      30,
+				FeeAccount:          testAccount1Address,
+				MaxFee:              3000,
+				InitialFeeCharged:   1500,
+				FeeRefund:           200,
+				FeeCharged:          1300,
+				InclusionFeeCharged: 500,
+				BaseFee:             100,
+				SurgePriced:         true,
+				Soroban:             true,
+				Successful:          true,
+				ClosedAt:            closedAt,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := transformFeeEvents(test.events, test.transaction, header, toid.Generator{})
+			require.NoError(t, err)
+			assert.Equal(t, test.wantOutput, output)
+		})
+	}
+}
+
+func TestTransformFeeEventsErrors(t *testing.T) {
+	classic, _, header := makeFeeTestInput()
+
+	_, err := transformFeeEvents(nil, classic, header, toid.Generator{})
+	assert.EqualError(t, err, "no fee events for ledger 30; transaction 1 (transaction id=128849022976)")
+
+	_, err = transformFeeEvents(makeFeeTestEvents("1.5"), classic, header, toid.Generator{})
+	assert.Error(t, err)
+
+	mint := &token_transfer.TokenTransferEvent{
+		Meta:  &token_transfer.EventMeta{LedgerSequence: 30, TxHash: "txhash", TransactionIndex: 1},
+		Event: &token_transfer.TokenTransferEvent_Mint{Mint: &token_transfer.Mint{To: testAccount1Address, Amount: "1"}},
+	}
+	_, err = transformFeeEvents([]*token_transfer.TokenTransferEvent{mint}, classic, header, toid.Generator{})
+	assert.EqualError(t, err, "the mint event is not a fee event for ledger 30; transaction 1 (transaction id=128849022976)")
+}
@@ -0,0 +1,148 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// The fuzz targets mutate the XDR of transactions and ledger entries and check that the transforms only return
+// errors on malformed input instead of panicking. Without -fuzz only the seed corpus is run. To fuzz a target:
+//
+//	go test ./internal/transform -run '^$' -fuzz FuzzTransactionTransforms -fuzztime 5m
+
+// marshalSeed encodes a test fixture as a seed. Some fixtures only fill in the fields their test reads and
+// cannot be encoded; the xdr encoder panics on those, so they are left out of the corpus.
+func marshalSeed(v interface{ MarshalBinary() ([]byte, error) }) (b []byte, ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	b, err := v.MarshalBinary()
+	return b, err == nil
+}
+
+func addChangeSeed(f *testing.F, change ingest.Change) {
+	var pre, post []byte
+	ok := true
+	if change.Pre != nil {
+		pre, ok = marshalSeed(change.Pre)
+	}
+	if change.Post != nil && ok {
+		post, ok = marshalSeed(change.Post)
+	}
+	if ok {
+		f.Add(pre, post)
+	}
+}
+
+// FuzzTransactionTransforms is seeded from testdata/fuzz/FuzzTransactionTransforms with the envelope, result, meta and
+// fee changes of the network transactions used by TestOperationEffects
+func FuzzTransactionTransforms(f *testing.F) {
+	f.Fuzz(func(t *testing.T, envelope, result, meta, feeChanges []byte) {
+		transaction := ingest.LedgerTransaction{Index: 1}
+		if xdr.SafeUnmarshal(envelope, &transaction.Envelope) != nil ||
+			xdr.SafeUnmarshal(result, &transaction.Result.Result) != nil ||
+			xdr.SafeUnmarshal(meta, &transaction.UnsafeMeta) != nil ||
+			xdr.SafeUnmarshal(feeChanges, &transaction.FeeChanges) != nil {
+			t.Skip("not valid xdr")
+		}
+
+		lcm := genericLedgerCloseMeta
+		lhe := lcm.LedgerHeaderHistoryEntry()
+		ledgerSeq := lcm.LedgerSequence()
+		closeTime, err := utils.GetCloseTime(lcm)
+		if err != nil {
+			t.Skip(err)
+		}
+
+		TransformTransaction(transaction, lhe, toid.Generator{})
+		TransformLedgerTransaction(transaction, lhe)
+		TransformContractEvent(transaction, lhe, toid.Generator{})
+		TransformOfferEvent(transaction, lhe, toid.Generator{})
+		TransformEffect(transaction, ledgerSeq, lcm, network.TestNetworkPassphrase, toid.Generator{})
+		for index, op := range transaction.Envelope.Operations() {
+			TransformOperation(op, int32(index), transaction, int32(ledgerSeq), lcm, network.TestNetworkPassphrase, toid.Generator{})
+			TransformTrade(int32(index), int64(index), transaction, closeTime, toid.Generator{})
+		}
+	})
+}
+
+func FuzzLedgerEntryTransforms(f *testing.F) {
+	addChangeSeed(f, makeAccountTestInput())
+	addChangeSeed(f, makeClaimableBalanceTestInput())
+	addChangeSeed(f, makePoolTestInput())
+	if change, err := makeOfferTestInput(); err == nil {
+		addChangeSeed(f, change)
+	}
+	for _, changes := range [][]ingest.Change{
+		makeAccountDataTestInput(),
+		makeTrustlineTestInput(),
+		makeContractDataTestInput(),
+		makeContractCodeTestInput(),
+		makeConfigSettingTestInput(),
+	} {
+		for _, change := range changes {
+			addChangeSeed(f, change)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, pre, post []byte) {
+		change := ingest.Change{}
+		for _, entry := range []struct {
+			raw []byte
+			dst **xdr.LedgerEntry
+		}{{pre, &change.Pre}, {post, &change.Post}} {
+			if len(entry.raw) == 0 {
+				continue
+			}
+			var ledgerEntry xdr.LedgerEntry
+			if xdr.SafeUnmarshal(entry.raw, &ledgerEntry) != nil {
+				t.Skip("not valid xdr")
+			}
+			*entry.dst = &ledgerEntry
+		}
+
+		switch {
+		case change.Pre != nil:
+			change.Type = change.Pre.Data.Type
+		case change.Post != nil:
+			change.Type = change.Post.Data.Type
+		default:
+			t.Skip("no ledger entries")
+		}
+
+		lhe := genericLedgerHeaderHistoryEntry
+		switch change.Type {
+		case xdr.LedgerEntryTypeAccount:
+			TransformAccount(change, lhe)
+			TransformSigners(change, lhe)
+		case xdr.LedgerEntryTypeTrustline:
+			TransformTrustline(change, lhe)
+		case xdr.LedgerEntryTypeOffer:
+			TransformOffer(change, lhe)
+			TransformOfferNormalized(change, 1)
+		case xdr.LedgerEntryTypeData:
+			TransformAccountData(change, lhe)
+		case xdr.LedgerEntryTypeClaimableBalance:
+			TransformClaimableBalance(change, lhe)
+		case xdr.LedgerEntryTypeLiquidityPool:
+			TransformPool(change, lhe)
+		case xdr.LedgerEntryTypeContractData:
+			transformContractData := NewTransformContractDataStruct(MockAssetFromContractData, MockContractBalanceFromContractData)
+			transformContractData.TransformContractData(change, network.TestNetworkPassphrase, lhe)
+		case xdr.LedgerEntryTypeContractCode:
+			TransformContractCode(change, lhe)
+		case xdr.LedgerEntryTypeConfigSetting:
+			TransformConfigSetting(change, lhe)
+		case xdr.LedgerEntryTypeTtl:
+			TransformTtl(change, lhe)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package transform
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformHomeDomainChange converts an account change into a row of the home domain history. The returned bool is
+// false when the change does not set a new home domain, including when the account is removed.
+func TransformHomeDomainChange(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ HomeDomainOutput, _ bool, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	if err := checkLedgerProtocol(uint32(header.Header.LedgerVersion)); err != nil {
+		return HomeDomainOutput{}, false, err
+	}
+	before, after, err := accountChangeEntries(ledgerChange)
+	if err != nil || after == nil {
+		return HomeDomainOutput{}, false, err
+	}
+
+	var outputOldHomeDomain string
+	if before != nil {
+		outputOldHomeDomain = string(before.HomeDomain)
+	}
+
+	outputNewHomeDomain := string(after.HomeDomain)
+	if outputNewHomeDomain == outputOldHomeDomain {
+		return HomeDomainOutput{}, false, nil
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return HomeDomainOutput{}, false, err
+	}
+
+	return HomeDomainOutput{
+		AccountID:      after.AccountId.Address(),
+		OldHomeDomain:  outputOldHomeDomain,
+		NewHomeDomain:  outputNewHomeDomain,
+		ClosedAt:       closedAt,
+		LedgerSequence: uint32(header.Header.LedgerSeq),
+	}, true, nil
+}
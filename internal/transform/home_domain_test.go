@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformHomeDomainChange(t *testing.T) {
+	accountEntry := func(homeDomain string) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:  testAccount1ID,
+					HomeDomain: xdr.String32(homeDomain),
+				},
+			},
+		}
+	}
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+	homeDomainOutput := func(oldHomeDomain, newHomeDomain string) HomeDomainOutput {
+		return HomeDomainOutput{
+			AccountID:      testAccount1Address,
+			OldHomeDomain:  oldHomeDomain,
+			NewHomeDomain:  newHomeDomain,
+			ClosedAt:       time.Unix(1000, 0).UTC(),
+			LedgerSequence: 10,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		change     ingest.Change
+		wantOutput HomeDomainOutput
+		wantOk     bool
+		wantErr    error
+	}{
+		{
+			name:       "home domain set",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(""), Post: accountEntry("anchor.example.com")},
+			wantOutput: homeDomainOutput("", "anchor.example.com"),
+			wantOk:     true,
+		},
+		{
+			name:       "home domain changed",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry("old.example.com"), Post: accountEntry("new.example.com")},
+			wantOutput: homeDomainOutput("old.example.com", "new.example.com"),
+			wantOk:     true,
+		},
+		{
+			name:       "home domain cleared",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry("old.example.com"), Post: accountEntry("")},
+			wantOutput: homeDomainOutput("old.example.com", ""),
+			wantOk:     true,
+		},
+		{
+			name:       "account created with a home domain",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Post: accountEntry("anchor.example.com")},
+			wantOutput: homeDomainOutput("", "anchor.example.com"),
+			wantOk:     true,
+		},
+		{
+			name:   "home domain unchanged",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry("anchor.example.com"), Post: accountEntry("anchor.example.com")},
+		},
+		{
+			name:   "account removed",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry("anchor.example.com")},
+		},
+		{
+			name:    "not an account",
+			change:  ingest.Change{Type: xdr.LedgerEntryTypeOffer, Post: &xdr.LedgerEntry{Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeOffer}}},
+			wantErr: fmt.Errorf("could not extract account data from ledger entry; actual type is LedgerEntryTypeOffer"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, ok, err := TransformHomeDomainChange(test.change, header)
+			assertTransformError(t, test.wantErr, err)
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.wantOutput, output)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package transform
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformInflationDestinationChange converts an account change into a row of the inflation destination history.
+// The returned bool is false when the change does not set a new inflation destination, including when the account is
+// removed.
+func TransformInflationDestinationChange(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ InflationDestinationOutput, _ bool, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	if err := checkLedgerProtocol(uint32(header.Header.LedgerVersion)); err != nil {
+		return InflationDestinationOutput{}, false, err
+	}
+	before, after, err := accountChangeEntries(ledgerChange)
+	if err != nil || after == nil {
+		return InflationDestinationOutput{}, false, err
+	}
+
+	var outputOldInflationDest string
+	if before != nil && before.InflationDest != nil {
+		outputOldInflationDest = before.InflationDest.Address()
+	}
+
+	var outputNewInflationDest string
+	if after.InflationDest != nil {
+		outputNewInflationDest = after.InflationDest.Address()
+	}
+
+	if outputNewInflationDest == outputOldInflationDest {
+		return InflationDestinationOutput{}, false, nil
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return InflationDestinationOutput{}, false, err
+	}
+
+	return InflationDestinationOutput{
+		AccountID:               after.AccountId.Address(),
+		OldInflationDestination: outputOldInflationDest,
+		NewInflationDestination: outputNewInflationDest,
+		ClosedAt:                closedAt,
+		LedgerSequence:          uint32(header.Header.LedgerSeq),
+	}, true, nil
+}
@@ -0,0 +1,82 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformInflationDestinationChange(t *testing.T) {
+	accountEntry := func(inflationDest *xdr.AccountId) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeAccount,
+				Account: &xdr.AccountEntry{
+					AccountId:     testAccount1ID,
+					InflationDest: inflationDest,
+				},
+			},
+		}
+	}
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+			LedgerSeq: 10,
+		},
+	}
+	inflationDestinationOutput := func(oldInflationDest, newInflationDest string) InflationDestinationOutput {
+		return InflationDestinationOutput{
+			AccountID:               testAccount1Address,
+			OldInflationDestination: oldInflationDest,
+			NewInflationDestination: newInflationDest,
+			ClosedAt:                time.Unix(1000, 0).UTC(),
+			LedgerSequence:          10,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		change     ingest.Change
+		wantOutput InflationDestinationOutput
+		wantOk     bool
+	}{
+		{
+			name:       "inflation destination set",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(nil), Post: accountEntry(&testAccount2ID)},
+			wantOutput: inflationDestinationOutput("", testAccount2Address),
+			wantOk:     true,
+		},
+		{
+			name:       "inflation destination changed",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(&testAccount2ID), Post: accountEntry(&testAccount3ID)},
+			wantOutput: inflationDestinationOutput(testAccount2Address, testAccount3Address),
+			wantOk:     true,
+		},
+		{
+			name:       "inflation destination cleared",
+			change:     ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(&testAccount2ID), Post: accountEntry(nil)},
+			wantOutput: inflationDestinationOutput(testAccount2Address, ""),
+			wantOk:     true,
+		},
+		{
+			name:   "inflation destination unchanged",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(&testAccount2ID), Post: accountEntry(&testAccount2ID)},
+		},
+		{
+			name:   "account removed",
+			change: ingest.Change{Type: xdr.LedgerEntryTypeAccount, Pre: accountEntry(&testAccount2ID)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, ok, err := TransformInflationDestinationChange(test.change, header)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantOk, ok)
+			assert.Equal(t, test.wantOutput, output)
+		})
+	}
+}
@@ -14,12 +14,16 @@ import (
 )
 
 // TransformLedger converts a ledger from the history archive ingestion system into a form suitable for BigQuery
-func TransformLedger(inputLedger historyarchive.Ledger, lcm xdr.LedgerCloseMeta) (LedgerOutput, error) {
+func TransformLedger(inputLedger historyarchive.Ledger, lcm xdr.LedgerCloseMeta, ids toid.Generator) (_ LedgerOutput, err error) {
+	defer wrapError(&err, ledgerCoordinates(ErrorCodeInvalidLedger, uint32(inputLedger.Header.Header.LedgerSeq), ""))
 	ledgerHeader := inputLedger.Header.Header
+	if err := checkLedgerProtocol(uint32(ledgerHeader.LedgerVersion)); err != nil {
+		return LedgerOutput{}, err
+	}
 
 	outputSequence := uint32(ledgerHeader.LedgerSeq)
 
-	outputLedgerID := toid.New(int32(outputSequence), 0, 0).ToInt64()
+	outputLedgerID := ids.New(int32(outputSequence), 0, 0)
 
 	outputLedgerHash := utils.HashToHexString(inputLedger.Header.Hash)
 	outputPreviousHash := utils.HashToHexString(ledgerHeader.PreviousLedgerHash)
@@ -31,7 +35,7 @@ func TransformLedger(inputLedger historyarchive.Ledger, lcm xdr.LedgerCloseMeta)
 
 	outputTransactionCount, outputOperationCount, outputSuccessfulCount, outputFailedCount, outputTxSetOperationCount, err := extractCounts(inputLedger)
 	if err != nil {
-		return LedgerOutput{}, fmt.Errorf("for ledger %d (ledger id=%d): %v", outputSequence, outputLedgerID, err)
+		return LedgerOutput{}, fmt.Errorf("for ledger %d (ledger id=%d): %w", outputSequence, outputLedgerID, err)
 	}
 
 	outputCloseTime, err := utils.TimePointToUTCTimeStamp(ledgerHeader.ScpValue.CloseTime)
@@ -118,7 +122,10 @@ func TransactionProcessing(l xdr.LedgerCloseMeta) []xdr.TransactionResultMeta {
 }
 
 func extractCounts(ledger historyarchive.Ledger) (transactionCount int32, operationCount int32, successTxCount int32, failedTxCount int32, txSetOperationCount string, err error) {
-	transactions := GetTransactionSet(ledger)
+	transactions, err := GetTransactionSet(ledger)
+	if err != nil {
+		return
+	}
 	results := ledger.TransactionResult.TxResultSet.Results
 	txCount := len(transactions)
 	if txCount != len(results) {
@@ -152,39 +159,43 @@ func extractCounts(ledger historyarchive.Ledger) (transactionCount int32, operat
 	return
 }
 
-func GetTransactionSet(transactionEntry historyarchive.Ledger) (transactionProcessing []xdr.TransactionEnvelope) {
+// GetTransactionSet returns the transactions of a ledger from the history archives, in the order of its transaction
+// set. Generalized transaction sets only exist from ProtocolSoroban.
+func GetTransactionSet(transactionEntry historyarchive.Ledger) ([]xdr.TransactionEnvelope, error) {
+	protocolVersion := uint32(transactionEntry.Header.Header.LedgerVersion)
 	switch transactionEntry.Transaction.Ext.V {
 	case 0:
-		return transactionEntry.Transaction.TxSet.Txs
+		return transactionEntry.Transaction.TxSet.Txs, nil
 	case 1:
-		return getTransactionPhase(transactionEntry.Transaction.Ext.GeneralizedTxSet.V1TxSet.Phases)
+		if protocolVersion < ProtocolSoroban {
+			return nil, versionError("generalized transaction sets only exist from protocol %d, but the ledger is at protocol %d", ProtocolSoroban, protocolVersion)
+		}
+		txSet, ok := transactionEntry.Transaction.Ext.MustGeneralizedTxSet().GetV1TxSet()
+		if !ok {
+			return nil, versionError("generalized transaction set V%d is not supported", transactionEntry.Transaction.Ext.MustGeneralizedTxSet().V)
+		}
+		return getTransactionPhase(txSet.Phases)
 	default:
-		panic(fmt.Sprintf("Unsupported TransactionHistoryEntry.Ext: %d", transactionEntry.Transaction.Ext.V))
+		return nil, versionError("TransactionHistoryEntry.Ext V%d is not supported", transactionEntry.Transaction.Ext.V)
 	}
 }
 
-func getTransactionPhase(transactionPhase []xdr.TransactionPhase) (transactionEnvelope []xdr.TransactionEnvelope) {
+func getTransactionPhase(transactionPhase []xdr.TransactionPhase) ([]xdr.TransactionEnvelope, error) {
 	transactionSlice := []xdr.TransactionEnvelope{}
 	for _, phase := range transactionPhase {
-		switch phase.V {
-		case 0:
-			components := phase.MustV0Components()
-			for _, component := range components {
-				switch component.Type {
-				case 0:
-					transactionSlice = append(transactionSlice, component.TxsMaybeDiscountedFee.Txs...)
-
-				default:
-					panic(fmt.Sprintf("Unsupported TxSetComponentType: %d", component.Type))
-				}
-
+		components, ok := phase.GetV0Components()
+		if !ok {
+			return nil, versionError("transaction phase V%d is not supported", phase.V)
+		}
+		for _, component := range components {
+			txs, ok := component.GetTxsMaybeDiscountedFee()
+			if !ok {
+				return nil, versionError("transaction set component type %d is not supported", component.Type)
 			}
-		default:
-			panic(fmt.Sprintf("Unsupported TransactionPhase.V: %d", phase.V))
+			transactionSlice = append(transactionSlice, txs.Txs...)
 		}
 	}
-	return transactionSlice
-
+	return transactionSlice, nil
 }
 
 // TODO: This should be moved into the go monorepo xdr functions
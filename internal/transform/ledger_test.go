@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 
 	"github.com/stellar/go/historyarchive"
@@ -81,8 +82,8 @@ func TestTransformLedger(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformLedger(test.input.Ledger, test.input.LCM)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformLedger(test.input.Ledger, test.input.LCM, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -10,7 +10,11 @@ import (
 )
 
 // TransformTransaction converts a transaction from the history archive ingestion system into a form suitable for BigQuery
-func TransformLedgerTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (LedgerTransactionOutput, error) {
+func TransformLedgerTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (_ LedgerTransactionOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidTransaction, transaction, uint32(lhe.Header.LedgerSeq), ""))
+	if err := checkTransactionMeta(transaction.UnsafeMeta, uint32(lhe.Header.LedgerVersion)); err != nil {
+		return LedgerTransactionOutput{}, err
+	}
 	ledgerHeader := lhe.Header
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
 
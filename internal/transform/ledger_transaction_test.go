@@ -38,7 +38,7 @@ func TestTransformTx(t *testing.T) {
 
 	for _, test := range tests {
 		actualOutput, actualError := TransformLedgerTransaction(test.input.transaction, test.input.historyHeader)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -10,11 +10,15 @@ import (
 )
 
 // TransformPool converts an liquidity pool ledger change entry into a form suitable for BigQuery
-func TransformPool(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (PoolOutput, error) {
+func TransformPool(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ PoolOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return PoolOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return PoolOutput{}, err
+	}
 
 	// LedgerEntryChange must contain a liquidity pool state change to be parsed, otherwise skip
 	if ledgerEntry.Data.Type != xdr.LedgerEntryTypeLiquidityPool {
@@ -56,7 +56,7 @@ func TestTransformPool(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformPool(test.input.ingest, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -0,0 +1,137 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stellar/go/amount"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// muxedAccountStatsKey identifies the stats of an asset moved by a muxed account
+type muxedAccountStatsKey struct {
+	accountMuxed string
+	assetID      int64
+}
+
+// MuxedAccountStats folds the credit and debit effects of muxed accounts into payment counts and volumes per base
+// account, muxed id and asset, so that the virtual accounts of an exchange can be reconciled without scanning the effects
+type MuxedAccountStats struct {
+	passphrase string
+	stats      map[muxedAccountStatsKey]*muxedAccountStats
+}
+
+// muxedAccountStats is an output whose volumes are kept in stroops until the outputs are built
+type muxedAccountStats struct {
+	output   MuxedAccountStatsOutput
+	sent     int64
+	received int64
+}
+
+// NewMuxedAccountStats returns an empty MuxedAccountStats for the network with the given passphrase
+func NewMuxedAccountStats(passphrase string) *MuxedAccountStats {
+	return &MuxedAccountStats{
+		passphrase: passphrase,
+		stats:      map[muxedAccountStatsKey]*muxedAccountStats{},
+	}
+}
+
+// AddTransaction adds the effects of a transaction that credit or debit a muxed account
+func (s *MuxedAccountStats) AddTransaction(transaction ingest.LedgerTransaction, lcm xdr.LedgerCloseMeta) error {
+	effects, err := TransformEffect(transaction, utils.GetLedgerSequence(lcm), lcm, s.passphrase, toid.Generator{})
+	if err != nil {
+		return err
+	}
+	for _, effect := range effects {
+		if err := s.AddEffect(effect); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddEffect adds an effect if it credits or debits a muxed account. Other effects are ignored.
+func (s *MuxedAccountStats) AddEffect(effect EffectOutput) error {
+	received := EffectType(effect.Type) == EffectAccountCredited
+	if !received && EffectType(effect.Type) != EffectAccountDebited {
+		return nil
+	}
+	if !effect.AddressMuxed.Valid {
+		return nil
+	}
+
+	muxedAccount, err := xdr.AddressToMuxedAccount(effect.AddressMuxed.String)
+	if err != nil {
+		return fmt.Errorf("could not decode muxed account %s of effect %s: %v", effect.AddressMuxed.String, effect.EffectId, err)
+	}
+	muxedID, err := muxedAccount.GetId()
+	if err != nil {
+		return fmt.Errorf("could not get the id of muxed account %s of effect %s: %v", effect.AddressMuxed.String, effect.EffectId, err)
+	}
+
+	amountString, _ := effect.Details["amount"].(string)
+	stroops, err := amount.ParseInt64(amountString)
+	if err != nil {
+		return fmt.Errorf("could not parse the amount of effect %s: %v", effect.EffectId, err)
+	}
+
+	assetType, _ := effect.Details["asset_type"].(string)
+	code, _ := effect.Details["asset_code"].(string)
+	issuer, _ := effect.Details["asset_issuer"].(string)
+	key := muxedAccountStatsKey{accountMuxed: effect.AddressMuxed.String, assetID: FarmHashAsset(code, issuer, assetType)}
+
+	stats, ok := s.stats[key]
+	if !ok {
+		stats = &muxedAccountStats{output: MuxedAccountStatsOutput{
+			AccountID:       effect.Address,
+			MuxedID:         muxedID,
+			AccountMuxed:    effect.AddressMuxed.String,
+			AssetCode:       code,
+			AssetIssuer:     issuer,
+			AssetType:       assetType,
+			AssetID:         key.assetID,
+			FirstSeenLedger: effect.LedgerSequence,
+			LastSeenLedger:  effect.LedgerSequence,
+		}}
+		s.stats[key] = stats
+	}
+	if effect.LedgerSequence < stats.output.FirstSeenLedger {
+		stats.output.FirstSeenLedger = effect.LedgerSequence
+	}
+	if effect.LedgerSequence > stats.output.LastSeenLedger {
+		stats.output.LastSeenLedger = effect.LedgerSequence
+	}
+
+	if received {
+		stats.output.PaymentsReceived++
+		stats.received += stroops
+	} else {
+		stats.output.PaymentsSent++
+		stats.sent += stroops
+	}
+	return nil
+}
+
+// Outputs returns the stats ordered by base account, muxed id and asset
+func (s *MuxedAccountStats) Outputs() []MuxedAccountStatsOutput {
+	outputs := make([]MuxedAccountStatsOutput, 0, len(s.stats))
+	for _, stats := range s.stats {
+		output := stats.output
+		output.AmountSent = utils.ConvertStroopValueToReal(xdr.Int64(stats.sent))
+		output.AmountReceived = utils.ConvertStroopValueToReal(xdr.Int64(stats.received))
+		outputs = append(outputs, output)
+	}
+	sort.Slice(outputs, func(i, j int) bool {
+		if outputs[i].AccountID != outputs[j].AccountID {
+			return outputs[i].AccountID < outputs[j].AccountID
+		}
+		if outputs[i].MuxedID != outputs[j].MuxedID {
+			return outputs[i].MuxedID < outputs[j].MuxedID
+		}
+		return outputs[i].AssetID < outputs[j].AssetID
+	})
+	return outputs
+}
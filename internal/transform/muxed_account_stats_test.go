@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMuxedAccountStatsAddEffect(t *testing.T) {
+	stats := NewMuxedAccountStats("")
+	muxed := testAccount5.Address()
+	otherMuxed, err := xdr.MuxedAccountFromAccountId(testAccount5Address, 2)
+	require.NoError(t, err)
+
+	effects := []EffectOutput{
+		{
+			Address:        testAccount5Address,
+			AddressMuxed:   null.StringFrom(muxed),
+			Type:           int32(EffectAccountCredited),
+			Details:        map[string]interface{}{"amount": "10.5000000", "asset_type": "native"},
+			LedgerSequence: 20,
+		},
+		{
+			Address:        testAccount5Address,
+			AddressMuxed:   null.StringFrom(muxed),
+			Type:           int32(EffectAccountCredited),
+			Details:        map[string]interface{}{"amount": "0.0000001", "asset_type": "native"},
+			LedgerSequence: 10,
+		},
+		{
+			Address:        testAccount5Address,
+			AddressMuxed:   null.StringFrom(muxed),
+			Type:           int32(EffectAccountDebited),
+			Details:        map[string]interface{}{"amount": "3.0000000", "asset_type": "credit_alphanum4", "asset_code": "USDT", "asset_issuer": testAccount4Address},
+			LedgerSequence: 15,
+		},
+		{
+			Address:        testAccount5Address,
+			AddressMuxed:   null.StringFrom(otherMuxed.Address()),
+			Type:           int32(EffectAccountDebited),
+			Details:        map[string]interface{}{"amount": "1.0000000", "asset_type": "native"},
+			LedgerSequence: 30,
+		},
+		// Payments to the base account and other effects are not counted
+		{
+			Address:        testAccount5Address,
+			Type:           int32(EffectAccountCredited),
+			Details:        map[string]interface{}{"amount": "1.0000000", "asset_type": "native"},
+			LedgerSequence: 30,
+		},
+		{
+			Address:        testAccount5Address,
+			AddressMuxed:   null.StringFrom(muxed),
+			Type:           int32(EffectTrade),
+			Details:        map[string]interface{}{"sold_amount": "1.0000000"},
+			LedgerSequence: 30,
+		},
+	}
+	for _, effect := range effects {
+		require.NoError(t, stats.AddEffect(effect))
+	}
+
+	nativeID := FarmHashAsset("", "", "native")
+	usdtID := FarmHashAsset("USDT", testAccount4Address, "credit_alphanum4")
+	assert.Equal(t, []MuxedAccountStatsOutput{
+		{
+			AccountID:       testAccount5Address,
+			MuxedID:         1,
+			AccountMuxed:    muxed,
+			AssetCode:       "USDT",
+			AssetIssuer:     testAccount4Address,
+			AssetType:       "credit_alphanum4",
+			AssetID:         usdtID,
+			PaymentsSent:    1,
+			AmountSent:      3,
+			FirstSeenLedger: 15,
+			LastSeenLedger:  15,
+		},
+		{
+			AccountID:        testAccount5Address,
+			MuxedID:          1,
+			AccountMuxed:     muxed,
+			AssetType:        "native",
+			AssetID:          nativeID,
+			PaymentsReceived: 2,
+			AmountReceived:   10.5000001,
+			FirstSeenLedger:  10,
+			LastSeenLedger:   20,
+		},
+		{
+			AccountID:       testAccount5Address,
+			MuxedID:         2,
+			AccountMuxed:    otherMuxed.Address(),
+			AssetType:       "native",
+			AssetID:         nativeID,
+			PaymentsSent:    1,
+			AmountSent:      1,
+			FirstSeenLedger: 30,
+			LastSeenLedger:  30,
+		},
+	}, stats.Outputs())
+
+	err = stats.AddEffect(EffectOutput{
+		AddressMuxed: null.StringFrom(muxed),
+		Type:         int32(EffectAccountCredited),
+		Details:      map[string]interface{}{"amount": "not an amount"},
+		EffectId:     "1-1",
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+const (
+	NetworkUpgradeProtocolVersion     = "protocol_version"
+	NetworkUpgradeBaseFee             = "base_fee"
+	NetworkUpgradeMaxTxSetSize        = "max_tx_set_size"
+	NetworkUpgradeBaseReserve         = "base_reserve"
+	NetworkUpgradeFlags               = "flags"
+	NetworkUpgradeConfig              = "config"
+	NetworkUpgradeMaxSorobanTxSetSize = "max_soroban_tx_set_size"
+)
+
+var networkUpgradeTypes = map[xdr.LedgerUpgradeType]string{
+	xdr.LedgerUpgradeTypeLedgerUpgradeVersion:             NetworkUpgradeProtocolVersion,
+	xdr.LedgerUpgradeTypeLedgerUpgradeBaseFee:             NetworkUpgradeBaseFee,
+	xdr.LedgerUpgradeTypeLedgerUpgradeMaxTxSetSize:        NetworkUpgradeMaxTxSetSize,
+	xdr.LedgerUpgradeTypeLedgerUpgradeBaseReserve:         NetworkUpgradeBaseReserve,
+	xdr.LedgerUpgradeTypeLedgerUpgradeFlags:               NetworkUpgradeFlags,
+	xdr.LedgerUpgradeTypeLedgerUpgradeConfig:              NetworkUpgradeConfig,
+	xdr.LedgerUpgradeTypeLedgerUpgradeMaxSorobanTxSetSize: NetworkUpgradeMaxSorobanTxSetSize,
+}
+
+// TransformNetworkUpgrades converts the upgrades applied at the close of a ledger into rows of the network_upgrades
+// table. Upgrades of the ledger header fields are one row each, with the previous value read from the header of the
+// previous ledger; it is null when that header is not given. Soroban config upgrades, and protocol upgrades that
+// create config settings, are one row per config setting they changed, with the settings as base64 xdr.
+func TransformNetworkUpgrades(ledgerCloseMeta xdr.LedgerCloseMeta, previousHeader *xdr.LedgerHeader) (_ []NetworkUpgradeOutput, err error) {
+	ledgerSequence := ledgerCloseMeta.LedgerSequence()
+	defer wrapError(&err, ledgerCoordinates(ErrorCodeInvalidLedger, ledgerSequence, ""))
+	closedAt, err := utils.GetCloseTime(ledgerCloseMeta)
+	if err != nil {
+		return []NetworkUpgradeOutput{}, err
+	}
+
+	transformedUpgrades := []NetworkUpgradeOutput{}
+	for i, upgradeMeta := range ledgerCloseMeta.UpgradesProcessing() {
+		upgradeType, ok := networkUpgradeTypes[upgradeMeta.Upgrade.Type]
+		if !ok {
+			return []NetworkUpgradeOutput{}, fmt.Errorf("unknown upgrade type %d in ledger %d", upgradeMeta.Upgrade.Type, ledgerSequence)
+		}
+
+		upgrades := []NetworkUpgradeOutput{}
+		if newValue, previousValue, ok := headerUpgradeValues(upgradeMeta.Upgrade, previousHeader); ok {
+			upgrades = append(upgrades, NetworkUpgradeOutput{PreviousValue: previousValue, NewValue: newValue})
+		}
+
+		configUpgrades, err := transformConfigSettingUpgrades(upgradeMeta.Changes)
+		if err != nil {
+			return []NetworkUpgradeOutput{}, fmt.Errorf("for ledger %d; upgrade %d: %v", ledgerSequence, i, err)
+		}
+		upgrades = append(upgrades, configUpgrades...)
+
+		for _, upgrade := range upgrades {
+			upgrade.LedgerSequence = ledgerSequence
+			upgrade.UpgradeIndex = uint32(i)
+			upgrade.UpgradeType = upgradeType
+			upgrade.ClosedAt = closedAt
+			transformedUpgrades = append(transformedUpgrades, upgrade)
+		}
+	}
+
+	return transformedUpgrades, nil
+}
+
+// headerUpgradeValues returns the new and previous values of an upgrade of a ledger header field
+func headerUpgradeValues(upgrade xdr.LedgerUpgrade, previousHeader *xdr.LedgerHeader) (string, null.String, bool) {
+	var newValue uint32
+	var previousValue func(header *xdr.LedgerHeader) uint32
+	switch upgrade.Type {
+	case xdr.LedgerUpgradeTypeLedgerUpgradeVersion:
+		newValue = uint32(upgrade.MustNewLedgerVersion())
+		previousValue = func(header *xdr.LedgerHeader) uint32 { return uint32(header.LedgerVersion) }
+	case xdr.LedgerUpgradeTypeLedgerUpgradeBaseFee:
+		newValue = uint32(upgrade.MustNewBaseFee())
+		previousValue = func(header *xdr.LedgerHeader) uint32 { return uint32(header.BaseFee) }
+	case xdr.LedgerUpgradeTypeLedgerUpgradeMaxTxSetSize:
+		newValue = uint32(upgrade.MustNewMaxTxSetSize())
+		previousValue = func(header *xdr.LedgerHeader) uint32 { return uint32(header.MaxTxSetSize) }
+	case xdr.LedgerUpgradeTypeLedgerUpgradeBaseReserve:
+		newValue = uint32(upgrade.MustNewBaseReserve())
+		previousValue = func(header *xdr.LedgerHeader) uint32 { return uint32(header.BaseReserve) }
+	case xdr.LedgerUpgradeTypeLedgerUpgradeFlags:
+		newValue = uint32(upgrade.MustNewFlags())
+		previousValue = func(header *xdr.LedgerHeader) uint32 {
+			if v1, ok := header.Ext.GetV1(); ok {
+				return uint32(v1.Flags)
+			}
+			return 0
+		}
+	default:
+		return "", null.String{}, false
+	}
+
+	var outputPreviousValue null.String
+	if previousHeader != nil {
+		outputPreviousValue = null.StringFrom(strconv.FormatUint(uint64(previousValue(previousHeader)), 10))
+	}
+	return strconv.FormatUint(uint64(newValue), 10), outputPreviousValue, true
+}
+
+// transformConfigSettingUpgrades returns a row for every config setting changed by an upgrade. Config upgrades
+// write the whole upgrade set, so the settings that kept their value are left out.
+func transformConfigSettingUpgrades(changes xdr.LedgerEntryChanges) ([]NetworkUpgradeOutput, error) {
+	upgrades := []NetworkUpgradeOutput{}
+	for _, change := range ingest.GetChangesFromLedgerEntryChanges(changes) {
+		if change.Type != xdr.LedgerEntryTypeConfigSetting || change.Post == nil {
+			continue
+		}
+
+		configSetting := change.Post.Data.MustConfigSetting()
+		newValue, err := xdr.MarshalBase64(configSetting)
+		if err != nil {
+			return []NetworkUpgradeOutput{}, err
+		}
+
+		var previousValue null.String
+		if change.Pre != nil {
+			encoded, err := xdr.MarshalBase64(change.Pre.Data.MustConfigSetting())
+			if err != nil {
+				return []NetworkUpgradeOutput{}, err
+			}
+			if encoded == newValue {
+				continue
+			}
+			previousValue = null.StringFrom(encoded)
+		}
+
+		upgrades = append(upgrades, NetworkUpgradeOutput{
+			ConfigSettingId: null.IntFrom(int64(configSetting.ConfigSettingId)),
+			PreviousValue:   previousValue,
+			NewValue:        newValue,
+		})
+	}
+
+	return upgrades, nil
+}
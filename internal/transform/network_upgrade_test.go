@@ -0,0 +1,128 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeNetworkUpgradeTestInput() (xdr.LedgerCloseMeta, xdr.LedgerHeader) {
+	newVersion := xdr.Uint32(23)
+	newBaseFee := xdr.Uint32(200)
+	newMaxSorobanTxSetSize := xdr.Uint32(150)
+
+	contractMaxSize := func(size xdr.Uint32) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeConfigSetting,
+				ConfigSetting: &xdr.ConfigSettingEntry{
+					ConfigSettingId:      xdr.ConfigSettingIdConfigSettingContractMaxSizeBytes,
+					ContractMaxSizeBytes: &size,
+				},
+			},
+		}
+	}
+	dataKeySize := xdr.Uint32(200)
+	unchanged := &xdr.LedgerEntry{
+		Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeConfigSetting,
+			ConfigSetting: &xdr.ConfigSettingEntry{
+				ConfigSettingId:          xdr.ConfigSettingIdConfigSettingContractDataKeySizeBytes,
+				ContractDataKeySizeBytes: &dataKeySize,
+			},
+		},
+	}
+
+	lcm := xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:  xdr.StellarValue{CloseTime: 1000},
+					LedgerSeq: 10,
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+			UpgradesProcessing: []xdr.UpgradeEntryMeta{
+				{
+					Upgrade: xdr.LedgerUpgrade{Type: xdr.LedgerUpgradeTypeLedgerUpgradeVersion, NewLedgerVersion: &newVersion},
+				},
+				{
+					Upgrade: xdr.LedgerUpgrade{Type: xdr.LedgerUpgradeTypeLedgerUpgradeBaseFee, NewBaseFee: &newBaseFee},
+				},
+				{
+					Upgrade: xdr.LedgerUpgrade{Type: xdr.LedgerUpgradeTypeLedgerUpgradeConfig, NewConfig: &xdr.ConfigUpgradeSetKey{}},
+					Changes: xdr.LedgerEntryChanges{
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: contractMaxSize(65536)},
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: contractMaxSize(131072)},
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: unchanged},
+						{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: unchanged},
+					},
+				},
+				{
+					Upgrade: xdr.LedgerUpgrade{Type: xdr.LedgerUpgradeTypeLedgerUpgradeMaxSorobanTxSetSize, NewMaxSorobanTxSetSize: &newMaxSorobanTxSetSize},
+				},
+			},
+		},
+	}
+
+	previousHeader := xdr.LedgerHeader{
+		LedgerSeq:     9,
+		LedgerVersion: 22,
+		BaseFee:       100,
+	}
+
+	return lcm, previousHeader
+}
+
+func TestTransformNetworkUpgrades(t *testing.T) {
+	lcm, previousHeader := makeNetworkUpgradeTestInput()
+	closedAt := time.Unix(1000, 0).UTC()
+
+	expected := []NetworkUpgradeOutput{
+		{
+			LedgerSequence: 10,
+			UpgradeIndex:   0,
+			UpgradeType:    NetworkUpgradeProtocolVersion,
+			PreviousValue:  null.StringFrom("22"),
+			NewValue:       "23",
+			ClosedAt:       closedAt,
+		},
+		{
+			LedgerSequence: 10,
+			UpgradeIndex:   1,
+			UpgradeType:    NetworkUpgradeBaseFee,
+			PreviousValue:  null.StringFrom("100"),
+			NewValue:       "200",
+			ClosedAt:       closedAt,
+		},
+		{
+			LedgerSequence:  10,
+			UpgradeIndex:    2,
+			UpgradeType:     NetworkUpgradeConfig,
+			ConfigSettingId: null.IntFrom(int64(xdr.ConfigSettingIdConfigSettingContractMaxSizeBytes)),
+			PreviousValue:   null.StringFrom("AAAAAAABAAA="),
+			NewValue:        "AAAAAAACAAA=",
+			ClosedAt:        closedAt,
+		},
+	}
+
+	actualOutput, err := TransformNetworkUpgrades(lcm, &previousHeader)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actualOutput)
+
+	// Without the previous header, the previous values of the header fields are unknown
+	actualOutput, err = TransformNetworkUpgrades(lcm, nil)
+	require.NoError(t, err)
+	require.Len(t, actualOutput, 3)
+	assert.False(t, actualOutput[0].PreviousValue.Valid)
+	assert.False(t, actualOutput[1].PreviousValue.Valid)
+	assert.Equal(t, null.StringFrom("AAAAAAABAAA="), actualOutput[2].PreviousValue)
+}
@@ -2,6 +2,7 @@ package transform
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 
@@ -10,11 +11,15 @@ import (
 )
 
 // TransformOffer converts an account from the history archive ingestion system into a form suitable for BigQuery
-func TransformOffer(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (OfferOutput, error) {
+func TransformOffer(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ OfferOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return OfferOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return OfferOutput{}, err
+	}
 
 	offerEntry, offerFound := ledgerEntry.Data.GetOffer()
 	if !offerFound {
@@ -98,6 +103,8 @@ func TransformOffer(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEn
 		Sponsor:            ledgerEntrySponsorToNullString(ledgerEntry),
 		ClosedAt:           closedAt,
 		LedgerSequence:     uint32(ledgerSequence),
+		PriceDecimal:       utils.ConvertPriceToDecimal(int64(outputPriceN), int64(outputPriceD)),
+		OfferIDStr:         strconv.FormatInt(outputOfferID, 10),
 	}
 	return transformedOffer, nil
 }
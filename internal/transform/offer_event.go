@@ -0,0 +1,171 @@
+package transform
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+const (
+	OfferEventCreated = "offer_created"
+	OfferEventUpdated = "offer_updated"
+	OfferEventRemoved = "offer_removed"
+
+	OfferEventCauseManageOffer = "manage_offer"
+	OfferEventCauseTradeFill   = "trade_fill"
+	OfferEventCausePathPayment = "path_payment"
+	OfferEventCauseRevocation  = "revocation"
+	OfferEventCauseOther       = "other"
+)
+
+// TransformOfferEvent converts the offer ledger entry changes of a transaction into explicit offer lifecycle events.
+// Unlike the offer effects, which are only emitted as a side effect of trades, every change to an offer is reported
+// along with the reason it happened.
+func TransformOfferEvent(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, ids toid.Generator) (_ []OfferEventOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidEvent, transaction, uint32(lhe.Header.LedgerSeq), ""))
+	if err := checkTransactionMeta(transaction.UnsafeMeta, uint32(lhe.Header.LedgerVersion)); err != nil {
+		return []OfferEventOutput{}, err
+	}
+	ledgerHeader := lhe.Header
+	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
+	outputTransactionHash := utils.HashToHexString(transaction.Result.TransactionHash)
+
+	outputCloseTime, err := utils.TimePointToUTCTimeStamp(ledgerHeader.ScpValue.CloseTime)
+	if err != nil {
+		return []OfferEventOutput{}, fmt.Errorf("for ledger %d; transaction %d: %v", outputLedgerSequence, transaction.Index, err)
+	}
+
+	transformedEvents := []OfferEventOutput{}
+
+	// Failed transactions do not modify any offers
+	if !transaction.Result.Successful() {
+		return transformedEvents, nil
+	}
+
+	for opi, op := range transaction.Envelope.Operations() {
+		operation := transactionOperationWrapper{
+			index:          uint32(opi),
+			transaction:    transaction,
+			operation:      op,
+			ledgerSequence: outputLedgerSequence,
+			ids:            ids,
+		}
+
+		changes, err := transaction.GetOperationChanges(uint32(opi))
+		if err != nil {
+			return []OfferEventOutput{}, fmt.Errorf("for ledger %d; transaction %d; operation %d: %v", outputLedgerSequence, transaction.Index, opi, err)
+		}
+
+		for _, change := range changes {
+			if change.Type != xdr.LedgerEntryTypeOffer {
+				continue
+			}
+
+			transformedEvent, err := transformOfferChange(change, &operation)
+			if err != nil {
+				return []OfferEventOutput{}, fmt.Errorf("for ledger %d; transaction %d; operation %d: %v", outputLedgerSequence, transaction.Index, opi, err)
+			}
+
+			transformedEvent.TransactionHash = outputTransactionHash
+			transformedEvent.ClosedAt = outputCloseTime
+			transformedEvent.LedgerSequence = outputLedgerSequence
+			transformedEvents = append(transformedEvents, transformedEvent)
+		}
+	}
+
+	return transformedEvents, nil
+}
+
+func transformOfferChange(change ingest.Change, operation *transactionOperationWrapper) (OfferEventOutput, error) {
+	var eventType string
+	var offer xdr.OfferEntry
+	switch {
+	case change.Pre == nil:
+		eventType = OfferEventCreated
+		offer = change.Post.Data.MustOffer()
+	case change.Post == nil:
+		eventType = OfferEventRemoved
+		offer = change.Pre.Data.MustOffer()
+	default:
+		eventType = OfferEventUpdated
+		offer = change.Post.Data.MustOffer()
+	}
+
+	outputSellerID, err := offer.SellerId.GetAddress()
+	if err != nil {
+		return OfferEventOutput{}, err
+	}
+
+	outputSellingAsset, err := transformSingleAsset(offer.Selling)
+	if err != nil {
+		return OfferEventOutput{}, err
+	}
+
+	outputBuyingAsset, err := transformSingleAsset(offer.Buying)
+	if err != nil {
+		return OfferEventOutput{}, err
+	}
+
+	// A removed offer no longer has anything left to sell
+	var outputAmount xdr.Int64
+	if eventType != OfferEventRemoved {
+		outputAmount = offer.Amount
+	}
+
+	var outputPrice float64
+	if offer.Price.D != 0 {
+		outputPrice = float64(offer.Price.N) / float64(offer.Price.D)
+	}
+
+	outputOperationType, err := mapOperationType(operation.operation)
+	if err != nil {
+		return OfferEventOutput{}, err
+	}
+
+	return OfferEventOutput{
+		OfferID:            int64(offer.OfferId),
+		SellerID:           outputSellerID,
+		EventType:          eventType,
+		Cause:              offerEventCause(operation, offer.SellerId),
+		SellingAssetType:   outputSellingAsset.AssetType,
+		SellingAssetCode:   outputSellingAsset.AssetCode,
+		SellingAssetIssuer: outputSellingAsset.AssetIssuer,
+		SellingAssetID:     outputSellingAsset.AssetID,
+		BuyingAssetType:    outputBuyingAsset.AssetType,
+		BuyingAssetCode:    outputBuyingAsset.AssetCode,
+		BuyingAssetIssuer:  outputBuyingAsset.AssetIssuer,
+		BuyingAssetID:      outputBuyingAsset.AssetID,
+		Amount:             utils.ConvertStroopValueToReal(outputAmount),
+		PriceN:             int32(offer.Price.N),
+		PriceD:             int32(offer.Price.D),
+		Price:              outputPrice,
+		OperationID:        operation.ID(),
+		OperationType:      outputOperationType,
+		TransactionID:      operation.TransactionID(),
+		PriceDecimal:       utils.ConvertPriceToDecimal(int64(offer.Price.N), int64(offer.Price.D)),
+		OfferIDStr:         strconv.FormatInt(int64(offer.OfferId), 10),
+	}, nil
+}
+
+// offerEventCause attributes an offer change to the operation that caused it. Offers owned by the source
+// of a manage offer operation are changed by their owner, while any other offer touched by it was crossed.
+func offerEventCause(operation *transactionOperationWrapper, sellerID xdr.AccountId) string {
+	switch operation.OperationType() {
+	case xdr.OperationTypeManageSellOffer, xdr.OperationTypeManageBuyOffer, xdr.OperationTypeCreatePassiveSellOffer:
+		sourceID := operation.SourceAccount().ToAccountId()
+		if sourceID.Equals(sellerID) {
+			return OfferEventCauseManageOffer
+		}
+		return OfferEventCauseTradeFill
+	case xdr.OperationTypePathPaymentStrictReceive, xdr.OperationTypePathPaymentStrictSend:
+		return OfferEventCausePathPayment
+	case xdr.OperationTypeAllowTrust, xdr.OperationTypeSetTrustLineFlags:
+		return OfferEventCauseRevocation
+	default:
+		return OfferEventCauseOther
+	}
+}
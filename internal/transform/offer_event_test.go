@@ -0,0 +1,216 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+)
+
+func TestTransformOfferEvent(t *testing.T) {
+	type transformTest struct {
+		input      ingest.LedgerTransaction
+		wantOutput []OfferEventOutput
+		wantErr    error
+	}
+
+	hardCodedInput := makeOfferEventTestInput()
+	hardCodedOutput := makeOfferEventTestOutput()
+
+	failedInput := hardCodedInput
+	failedInput.Result = wrapOperationsResultsSlice([]xdr.OperationResult{}, false)
+
+	tests := []transformTest{
+		{
+			input:      hardCodedInput,
+			wantOutput: hardCodedOutput,
+			wantErr:    nil,
+		},
+		{
+			input:      failedInput,
+			wantOutput: []OfferEventOutput{},
+			wantErr:    nil,
+		},
+	}
+
+	for _, test := range tests {
+		actualOutput, actualError := TransformOfferEvent(test.input, genericLedgerHeaderHistoryEntry, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
+		assert.Equal(t, test.wantOutput, actualOutput)
+	}
+}
+
+func makeOfferEventTestInput() ingest.LedgerTransaction {
+	offerEntry := func(sellerID xdr.AccountId, offerID, amount xdr.Int64, selling, buying xdr.Asset, price xdr.Price) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 30705278,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeOffer,
+				Offer: &xdr.OfferEntry{
+					SellerId: sellerID,
+					OfferId:  offerID,
+					Selling:  selling,
+					Buying:   buying,
+					Amount:   amount,
+					Price:    price,
+				},
+			},
+		}
+	}
+
+	manageOfferOp := xdr.Operation{
+		SourceAccount: &testAccount1,
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  ethAsset,
+				Amount:  1000,
+				Price:   xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+	revokeOp := xdr.Operation{
+		SourceAccount: &testAccount3,
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeSetTrustLineFlags,
+			SetTrustLineFlagsOp: &xdr.SetTrustLineFlagsOp{
+				Trustor:    testAccount2ID,
+				Asset:      ethAsset,
+				ClearFlags: xdr.Uint32(xdr.TrustLineFlagsAuthorizedFlag),
+			},
+		},
+	}
+
+	return ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: testAccount1,
+					Operations:    []xdr.Operation{manageOfferOp, revokeOp},
+				},
+			},
+		},
+		Result: wrapOperationsResultsSlice([]xdr.OperationResult{}, true),
+		UnsafeMeta: xdr.TransactionMeta{
+			V: 2,
+			V2: &xdr.TransactionMetaV2{
+				Operations: []xdr.OperationMeta{
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{
+								Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+								State: offerEntry(testAccount2ID, 260678439, 400, ethAsset, nativeAsset, xdr.Price{N: 2, D: 1}),
+							},
+							{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryUpdated,
+								Updated: offerEntry(testAccount2ID, 260678439, 150, ethAsset, nativeAsset, xdr.Price{N: 2, D: 1}),
+							},
+							{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryCreated,
+								Created: offerEntry(testAccount1ID, 260678440, 500, nativeAsset, ethAsset, xdr.Price{N: 1, D: 2}),
+							},
+						},
+					},
+					{
+						Changes: xdr.LedgerEntryChanges{
+							{
+								Type:  xdr.LedgerEntryChangeTypeLedgerEntryState,
+								State: offerEntry(testAccount2ID, 260678439, 150, ethAsset, nativeAsset, xdr.Price{N: 2, D: 1}),
+							},
+							{
+								Type:    xdr.LedgerEntryChangeTypeLedgerEntryRemoved,
+								Removed: &xdr.LedgerKey{Type: xdr.LedgerEntryTypeOffer, Offer: &xdr.LedgerKeyOffer{SellerId: testAccount2ID, OfferId: 260678439}},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func makeOfferEventTestOutput() []OfferEventOutput {
+	ethAssetID := FarmHashAsset("ETH", testAccount3Address, "credit_alphanum4")
+	nativeAssetID := FarmHashAsset("", "", "native")
+	closedAt := time.Unix(0, 0).UTC()
+
+	return []OfferEventOutput{
+		{
+			OfferID:            260678439,
+			SellerID:           testAccount2Address,
+			EventType:          OfferEventUpdated,
+			Cause:              OfferEventCauseTradeFill,
+			SellingAssetType:   "credit_alphanum4",
+			SellingAssetCode:   "ETH",
+			SellingAssetIssuer: testAccount3Address,
+			SellingAssetID:     ethAssetID,
+			BuyingAssetType:    "native",
+			BuyingAssetID:      nativeAssetID,
+			Amount:             0.000015,
+			PriceN:             2,
+			PriceD:             1,
+			Price:              2,
+			OperationID:        4097,
+			OperationType:      "manage_sell_offer",
+			TransactionID:      4096,
+			TransactionHash:    "0000000000000000000000000000000000000000000000000000000000000000",
+			ClosedAt:           closedAt,
+			PriceDecimal:       "2",
+			OfferIDStr:         "260678439",
+		},
+		{
+			OfferID:           260678440,
+			SellerID:          testAccount1Address,
+			EventType:         OfferEventCreated,
+			Cause:             OfferEventCauseManageOffer,
+			SellingAssetType:  "native",
+			SellingAssetID:    nativeAssetID,
+			BuyingAssetType:   "credit_alphanum4",
+			BuyingAssetCode:   "ETH",
+			BuyingAssetIssuer: testAccount3Address,
+			BuyingAssetID:     ethAssetID,
+			Amount:            0.00005,
+			PriceN:            1,
+			PriceD:            2,
+			Price:             0.5,
+			OperationID:       4097,
+			OperationType:     "manage_sell_offer",
+			TransactionID:     4096,
+			TransactionHash:   "0000000000000000000000000000000000000000000000000000000000000000",
+			ClosedAt:          closedAt,
+			PriceDecimal:      "0.5",
+			OfferIDStr:        "260678440",
+		},
+		{
+			OfferID:            260678439,
+			SellerID:           testAccount2Address,
+			EventType:          OfferEventRemoved,
+			Cause:              OfferEventCauseRevocation,
+			SellingAssetType:   "credit_alphanum4",
+			SellingAssetCode:   "ETH",
+			SellingAssetIssuer: testAccount3Address,
+			SellingAssetID:     ethAssetID,
+			BuyingAssetType:    "native",
+			BuyingAssetID:      nativeAssetID,
+			Amount:             0,
+			PriceN:             2,
+			PriceD:             1,
+			Price:              2,
+			OperationID:        4098,
+			OperationType:      "set_trust_line_flags",
+			TransactionID:      4096,
+			TransactionHash:    "0000000000000000000000000000000000000000000000000000000000000000",
+			ClosedAt:           closedAt,
+			PriceDecimal:       "2",
+			OfferIDStr:         "260678439",
+		},
+	}
+}
@@ -13,7 +13,8 @@ import (
 )
 
 // TransformOfferNormalized converts an offer into a normalized form, allowing it to be stored as part of the historical orderbook dataset
-func TransformOfferNormalized(ledgerChange ingest.Change, ledgerSeq uint32) (NormalizedOfferOutput, error) {
+func TransformOfferNormalized(ledgerChange ingest.Change, ledgerSeq uint32) (_ NormalizedOfferOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, ledgerSeq))
 
 	var header xdr.LedgerHeaderHistoryEntry
 	transformed, err := TransformOffer(ledgerChange, header)
@@ -56,7 +56,7 @@ func TestTransformOfferNormalized(t *testing.T) {
 
 	for _, test := range tests {
 		actualOutput, actualError := TransformOfferNormalized(test.input.change, test.input.ledger)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -106,7 +106,7 @@ func TestTransformOffer(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformOffer(test.input.ingest, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -180,5 +180,7 @@ func makeOfferTestOutput() OfferOutput {
 		Sponsor:            null.StringFrom(testAccount3Address),
 		LedgerSequence:     10,
 		ClosedAt:           time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+		PriceDecimal:       "0.514237344440486500",
+		OfferIDStr:         "260678439",
 	}
 }
@@ -27,9 +27,27 @@ type liquidityPoolDelta struct {
 }
 
 // TransformOperation converts an operation from the history archive ingestion system into a form suitable for BigQuery
-func TransformOperation(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, ledgerCloseMeta xdr.LedgerCloseMeta, network string) (OperationOutput, error) {
-	outputTransactionID := toid.New(ledgerSeq, int32(transaction.Index), 0).ToInt64()
-	outputOperationID := toid.New(ledgerSeq, int32(transaction.Index), operationIndex+1).ToInt64() //operationIndex needs +1 increment to stay in sync with ingest package
+func TransformOperation(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, ledgerCloseMeta xdr.LedgerCloseMeta, network string, ids toid.Generator) (OperationOutput, error) {
+	return TransformOperationWithOptions(operation, operationIndex, transaction, ledgerSeq, ledgerCloseMeta, network, ids, OperationOptions{})
+}
+
+// OperationOptions enables operation details that are not exported by default
+type OperationOptions struct {
+	// ClaimedOffers adds the offers claimed by manage offer operations to their details, with what was left of them
+	// on the book, along with the offer the operation left on the book
+	ClaimedOffers bool
+}
+
+// TransformOperationWithOptions is TransformOperation with the opt-in details of the options
+func TransformOperationWithOptions(operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction, ledgerSeq int32, ledgerCloseMeta xdr.LedgerCloseMeta, network string, ids toid.Generator, options OperationOptions) (_ OperationOutput, err error) {
+	defer wrapError(&err, operationCoordinates(ErrorCodeInvalidOperation, operation, operationIndex, transaction, uint32(ledgerSeq), network))
+	if protocolVersion, ok := closeMetaProtocolVersion(ledgerCloseMeta); ok {
+		if err := checkTransactionMeta(transaction.UnsafeMeta, protocolVersion); err != nil {
+			return OperationOutput{}, err
+		}
+	}
+	outputTransactionID := ids.New(ledgerSeq, int32(transaction.Index), 0)
+	outputOperationID := ids.New(ledgerSeq, int32(transaction.Index), operationIndex+1) //operationIndex needs +1 increment to stay in sync with ingest package
 
 	sourceAccount := getOperationSourceAccount(operation, transaction)
 	outputSourceAccount, err := utils.GetAccountAddressFromMuxedAccount(sourceAccount)
@@ -55,6 +73,12 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 	if err != nil {
 		return OperationOutput{}, err
 	}
+	if options.ClaimedOffers {
+		if err := addClaimedOfferDetails(outputDetails, operation, operationIndex, transaction); err != nil {
+			return OperationOutput{}, err
+		}
+	}
+	outputDetails = normalizeDetails(outputDetails)
 
 	outputOperationTypeString, err := mapOperationType(operation)
 	if err != nil {
@@ -83,23 +107,137 @@ func TransformOperation(operation xdr.Operation, operationIndex int32, transacti
 	outputLedgerSequence := utils.GetLedgerSequence(ledgerCloseMeta)
 
 	transformedOperation := OperationOutput{
-		SourceAccount:        outputSourceAccount,
-		SourceAccountMuxed:   outputSourceAccountMuxed.String,
-		Type:                 outputOperationType,
-		TypeString:           outputOperationTypeString,
-		TransactionID:        outputTransactionID,
-		OperationID:          outputOperationID,
-		OperationDetails:     outputDetails,
-		ClosedAt:             outputCloseTime,
-		OperationResultCode:  outputOperationResultCode,
-		OperationTraceCode:   outputOperationTraceCode,
-		LedgerSequence:       outputLedgerSequence,
-		OperationDetailsJSON: outputDetails,
+		SourceAccount:         outputSourceAccount,
+		SourceAccountMuxed:    outputSourceAccountMuxed.String,
+		Type:                  outputOperationType,
+		TypeString:            outputOperationTypeString,
+		TransactionID:         outputTransactionID,
+		OperationID:           outputOperationID,
+		OperationDetails:      outputDetails,
+		ClosedAt:              outputCloseTime,
+		OperationResultCode:   outputOperationResultCode,
+		OperationTraceCode:    outputOperationTraceCode,
+		LedgerSequence:        outputLedgerSequence,
+		OperationDetailsJSON:  outputDetails,
+		TransactionSuccessful: transaction.Result.Successful(),
+		PagingToken:           strconv.FormatInt(outputOperationID, 10),
 	}
 
 	return transformedOperation, nil
 }
 
+// addClaimedOfferDetails adds the offers claimed by a successful manage offer operation to its details as
+// claimed_offers, and the offer the operation left on the book as remaining_offer, both read from the meta changes
+// of the operation. A claimed offer that was fully filled was removed from the book, so it is marked as such with
+// nothing remaining, while one that was partially filled has the amount left on the book. remaining_offer is null
+// when the operation did not leave an offer on the book.
+func addClaimedOfferDetails(details map[string]interface{}, operation xdr.Operation, operationIndex int32, transaction ingest.LedgerTransaction) error {
+	if !transaction.Result.Successful() {
+		return nil
+	}
+
+	results, ok := transaction.Result.Result.OperationResults()
+	if !ok {
+		return nil
+	}
+	tr, ok := results[operationIndex].GetTr()
+	if !ok {
+		return nil
+	}
+	switch operation.Body.Type {
+	case xdr.OperationTypeManageBuyOffer, xdr.OperationTypeManageSellOffer, xdr.OperationTypeCreatePassiveSellOffer:
+	default:
+		return nil
+	}
+	// KNOWN ISSUE: stellar-core creates results for CreatePassiveOffer operations
+	// with the wrong result arm set, so the arm is read from the result type.
+	var success xdr.ManageOfferSuccessResult
+	switch tr.Type {
+	case xdr.OperationTypeManageBuyOffer:
+		var result xdr.ManageBuyOfferResult
+		if result, ok = tr.GetManageBuyOfferResult(); ok {
+			success, ok = result.GetSuccess()
+		}
+	case xdr.OperationTypeManageSellOffer:
+		var result xdr.ManageSellOfferResult
+		if result, ok = tr.GetManageSellOfferResult(); ok {
+			success, ok = result.GetSuccess()
+		}
+	case xdr.OperationTypeCreatePassiveSellOffer:
+		var result xdr.ManageSellOfferResult
+		if result, ok = tr.GetCreatePassiveSellOfferResult(); ok {
+			success, ok = result.GetSuccess()
+		}
+	default:
+		ok = false
+	}
+	if !ok {
+		return fmt.Errorf("could not access the success result of manage offer operation %d", operationIndex)
+	}
+
+	changes, err := transaction.GetOperationChanges(uint32(operationIndex))
+	if err != nil {
+		return err
+	}
+	offerChanges := map[xdr.Int64]ingest.Change{}
+	for _, change := range changes {
+		if change.Type != xdr.LedgerEntryTypeOffer {
+			continue
+		}
+		entry := change.Post
+		if entry == nil {
+			entry = change.Pre
+		}
+		offerChanges[entry.Data.MustOffer().OfferId] = change
+	}
+
+	claimedOffers := []map[string]interface{}{}
+	for _, claim := range success.OffersClaimed {
+		if claim.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			continue
+		}
+		change, ok := offerChanges[claim.OfferId()]
+		if !ok {
+			return fmt.Errorf("claimed offer %d is not in the changes of operation %d", claim.OfferId(), operationIndex)
+		}
+
+		claimedOffer := map[string]interface{}{
+			"offer_id":      int64(claim.OfferId()),
+			"seller_id":     claim.SellerId().Address(),
+			"amount_sold":   utils.ConvertStroopValueToReal(claim.AmountSold()),
+			"amount_bought": utils.ConvertStroopValueToReal(claim.AmountBought()),
+			"fully_filled":  change.Post == nil,
+		}
+		if change.Post == nil {
+			claimedOffer["remaining_amount"] = utils.ConvertStroopValueToReal(0)
+		} else {
+			claimedOffer["remaining_amount"] = utils.ConvertStroopValueToReal(change.Post.Data.MustOffer().Amount)
+		}
+		claimedOffers = append(claimedOffers, claimedOffer)
+	}
+	details["claimed_offers"] = claimedOffers
+
+	// the offer left on the book is the one of the result, looked up by its id since the operation may have touched
+	// other offers of its source account
+	details["remaining_offer"] = nil
+	if offer, ok := success.Offer.GetOffer(); ok {
+		change, ok := offerChanges[offer.OfferId]
+		if !ok || change.Post == nil {
+			return fmt.Errorf("offer %d left on the book is not in the changes of operation %d", offer.OfferId, operationIndex)
+		}
+		remaining := change.Post.Data.MustOffer()
+		remainingOffer := map[string]interface{}{
+			"offer_id": int64(remaining.OfferId),
+			"amount":   utils.ConvertStroopValueToReal(remaining.Amount),
+		}
+		if err := addPriceDetails(remainingOffer, remaining.Price, ""); err != nil {
+			return err
+		}
+		details["remaining_offer"] = remainingOffer
+	}
+	return nil
+}
+
 func mapOperationType(operation xdr.Operation) (string, error) {
 	var op_string_type string
 	operationType := operation.Body.Type
@@ -165,6 +303,41 @@ func mapOperationType(operation xdr.Operation) (string, error) {
 	return op_string_type, nil
 }
 
+// ParseOperationTypes maps the names of operation types, as in the type_string column, to their types. It returns
+// nil when no names are given.
+func ParseOperationTypes(names []string) (map[xdr.OperationType]bool, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	byName := map[string]xdr.OperationType{}
+	for operationType := xdr.OperationType(0); operationType.ValidEnum(int32(operationType)); operationType++ {
+		name, err := mapOperationType(xdr.Operation{Body: xdr.OperationBody{Type: operationType}})
+		if err == nil {
+			byName[name] = operationType
+		}
+	}
+
+	types := map[xdr.OperationType]bool{}
+	for _, name := range names {
+		operationType, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown operation type: %s", name)
+		}
+		types[operationType] = true
+	}
+	return types, nil
+}
+
+// OperationTypeName returns the name of an operation type as in the type_string column
+func OperationTypeName(operationType xdr.OperationType) string {
+	name, err := mapOperationType(xdr.Operation{Body: xdr.OperationBody{Type: operationType}})
+	if err != nil {
+		return operationType.String()
+	}
+	return name
+}
+
 func mapOperationTrace(operationTrace xdr.OperationResultTr) (string, error) {
 	var operationTraceDescription string
 	operationType := operationTrace.Type
@@ -882,7 +1055,11 @@ func extractOperationDetails(operation xdr.Operation, transaction ingest.LedgerT
 		op := operation.Body.MustCreateClaimableBalanceOp()
 		details["asset"] = op.Asset.StringCanonical()
 		details["amount"] = utils.ConvertStroopValueToReal(op.Amount)
-		details["claimants"] = transformClaimants(op.Claimants)
+		claimants, err := transformClaimants(op.Claimants)
+		if err != nil {
+			return details, err
+		}
+		details["claimants"] = claimants
 
 	case xdr.OperationTypeClaimClaimableBalance:
 		op := operation.Body.MustClaimClaimableBalanceOp()
@@ -1178,17 +1355,21 @@ type transactionOperationWrapper struct {
 	transaction    ingest.LedgerTransaction
 	operation      xdr.Operation
 	ledgerSequence uint32
+	ids            toid.Generator
 	network        string
 	ledgerClosed   time.Time
+	effectOptions  EffectOptions
+	// changeIndex is built from the meta by operationChanges on first use
+	changeIndex *operationChangeIndex
 }
 
 // ID returns the ID for the operation.
 func (operation *transactionOperationWrapper) ID() int64 {
-	return toid.New(
+	return operation.ids.New(
 		int32(operation.ledgerSequence),
 		int32(operation.transaction.Index),
 		int32(operation.index+1),
-	).ToInt64()
+	)
 }
 
 // Order returns the operation order.
@@ -1198,7 +1379,7 @@ func (operation *transactionOperationWrapper) Order() uint32 {
 
 // TransactionID returns the id for the transaction related with this operation.
 func (operation *transactionOperationWrapper) TransactionID() int64 {
-	return toid.New(int32(operation.ledgerSequence), int32(operation.transaction.Index), 0).ToInt64()
+	return operation.ids.New(int32(operation.ledgerSequence), int32(operation.transaction.Index), 0)
 }
 
 // SourceAccount returns the operation's source account.
@@ -1250,10 +1431,11 @@ func (operation *transactionOperationWrapper) getSignerSponsorInChange(signerKey
 }
 
 func (operation *transactionOperationWrapper) getSponsor() (*xdr.AccountId, error) {
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	index, err := operation.operationChanges()
 	if err != nil {
 		return nil, err
 	}
+	changes := index.changes
 	var signerKey string
 	if setOps, ok := operation.operation.Body.GetSetOptionsOp(); ok && setOps.Signer != nil {
 		signerKey = setOps.Signer.Key.Address()
@@ -1284,52 +1466,42 @@ func (operation *transactionOperationWrapper) getSponsor() (*xdr.AccountId, erro
 var errLiquidityPoolChangeNotFound = errors.New("liquidity pool change not found")
 
 func (operation *transactionOperationWrapper) getLiquidityPoolAndProductDelta(lpID *xdr.PoolId) (*xdr.LiquidityPoolEntry, *liquidityPoolDelta, error) {
-	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	index, err := operation.operationChanges()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	for _, c := range changes {
-		if c.Type != xdr.LedgerEntryTypeLiquidityPool {
-			continue
-		}
-		// The delta can be caused by a full removal or full creation of the liquidity pool
-		var lp *xdr.LiquidityPoolEntry
-		var preA, preB, preShares xdr.Int64
-		if c.Pre != nil {
-			if lpID != nil && c.Pre.Data.LiquidityPool.LiquidityPoolId != *lpID {
-				// if we were looking for specific pool id, then check on it
-				continue
-			}
-			lp = c.Pre.Data.LiquidityPool
-			if c.Pre.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
-				return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Pre.Data.LiquidityPool.Body.Type)
-			}
-			cpPre := c.Pre.Data.LiquidityPool.Body.ConstantProduct
-			preA, preB, preShares = cpPre.ReserveA, cpPre.ReserveB, cpPre.TotalPoolShares
-		}
-		var postA, postB, postShares xdr.Int64
-		if c.Post != nil {
-			if lpID != nil && c.Post.Data.LiquidityPool.LiquidityPoolId != *lpID {
-				// if we were looking for specific pool id, then check on it
-				continue
-			}
-			lp = c.Post.Data.LiquidityPool
-			if c.Post.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
-				return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Post.Data.LiquidityPool.Body.Type)
-			}
-			cpPost := c.Post.Data.LiquidityPool.Body.ConstantProduct
-			postA, postB, postShares = cpPost.ReserveA, cpPost.ReserveB, cpPost.TotalPoolShares
+	c, ok := index.liquidityPool(lpID)
+	if !ok {
+		return nil, nil, errLiquidityPoolChangeNotFound
+	}
+
+	// The delta can be caused by a full removal or full creation of the liquidity pool
+	var lp *xdr.LiquidityPoolEntry
+	var preA, preB, preShares xdr.Int64
+	if c.Pre != nil {
+		lp = c.Pre.Data.LiquidityPool
+		if c.Pre.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
+			return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Pre.Data.LiquidityPool.Body.Type)
 		}
-		delta := &liquidityPoolDelta{
-			ReserveA:        postA - preA,
-			ReserveB:        postB - preB,
-			TotalPoolShares: postShares - preShares,
+		cpPre := c.Pre.Data.LiquidityPool.Body.ConstantProduct
+		preA, preB, preShares = cpPre.ReserveA, cpPre.ReserveB, cpPre.TotalPoolShares
+	}
+	var postA, postB, postShares xdr.Int64
+	if c.Post != nil {
+		lp = c.Post.Data.LiquidityPool
+		if c.Post.Data.LiquidityPool.Body.Type != xdr.LiquidityPoolTypeLiquidityPoolConstantProduct {
+			return nil, nil, fmt.Errorf("unexpected liquity pool body type %d", c.Post.Data.LiquidityPool.Body.Type)
 		}
-		return lp, delta, nil
+		cpPost := c.Post.Data.LiquidityPool.Body.ConstantProduct
+		postA, postB, postShares = cpPost.ReserveA, cpPost.ReserveB, cpPost.TotalPoolShares
 	}
-
-	return nil, nil, errLiquidityPoolChangeNotFound
+	delta := &liquidityPoolDelta{
+		ReserveA:        postA - preA,
+		ReserveB:        postB - preB,
+		TotalPoolShares: postShares - preShares,
+	}
+	return lp, delta, nil
 }
 
 // OperationResult returns the operation's result record
@@ -1339,6 +1511,22 @@ func (operation *transactionOperationWrapper) OperationResult() *xdr.OperationRe
 	return &tr
 }
 
+// operationResultTr returns the operation's result record, or an error if the transaction result does not have one.
+// Unlike OperationResult it does not panic on malformed results.
+func (operation *transactionOperationWrapper) operationResultTr() (*xdr.OperationResultTr, error) {
+	results, ok := operation.transaction.Result.OperationResults()
+	if !ok || int(operation.index) >= len(results) {
+		return nil, fmt.Errorf("transaction result has no result for operation %d", operation.index)
+	}
+
+	tr, ok := results[operation.index].GetTr()
+	if !ok {
+		return nil, fmt.Errorf("result of operation %d has code %s", operation.index, results[operation.index].Code)
+	}
+
+	return &tr, nil
+}
+
 func (operation *transactionOperationWrapper) findInitatingBeginSponsoringOp() *transactionOperationWrapper {
 	if !operation.transaction.Result.Successful() {
 		// Failed transactions may not have a compliant sandwich structure
@@ -1538,13 +1726,9 @@ func (operation *transactionOperationWrapper) Details() (map[string]interface{},
 		op := operation.operation.Body.MustCreateClaimableBalanceOp()
 		details["asset"] = op.Asset.StringCanonical()
 		details["amount"] = amount.String(op.Amount)
-		var claimants []Claimant
-		for _, c := range op.Claimants {
-			cv0 := c.MustV0()
-			claimants = append(claimants, Claimant{
-				Destination: cv0.Destination.Address(),
-				Predicate:   cv0.Predicate,
-			})
+		claimants, err := transformClaimants(op.Claimants)
+		if err != nil {
+			return nil, err
 		}
 		details["claimants"] = claimants
 	case xdr.OperationTypeClaimClaimableBalance:
@@ -2010,6 +2194,7 @@ func addAssetDetails(result map[string]interface{}, a xdr.Asset, prefix string)
 		return err
 	}
 	result[prefix+"asset_type"] = assetType
+	result[prefix+"asset_id"] = FarmHashAsset(code, issuer, assetType)
 
 	if a.Type == xdr.AssetTypeAssetTypeNative {
 		return nil
@@ -0,0 +1,94 @@
+package transform
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+)
+
+// operationChangeIndex holds the ledger entry changes of an operation, read from the transaction meta once, indexed
+// by entry type, along with the liquidity pool and claimable balance changes keyed by their ids. The effects of an
+// operation look up the same changes many times, once per claim of a path payment crossing pools for instance, and
+// reading them again from the meta for each lookup is what made the effects of AMM-heavy ledgers slow.
+type operationChangeIndex struct {
+	changes []ingest.Change
+	byType  map[xdr.LedgerEntryType][]ingest.Change
+	// liquidityPools and claimableBalances are the first change of each pool and balance, by pool id and hex
+	// balance id
+	liquidityPools    map[xdr.PoolId]ingest.Change
+	claimableBalances map[string]ingest.Change
+}
+
+func newOperationChangeIndex(changes []ingest.Change) *operationChangeIndex {
+	index := &operationChangeIndex{
+		changes:           changes,
+		byType:            map[xdr.LedgerEntryType][]ingest.Change{},
+		liquidityPools:    map[xdr.PoolId]ingest.Change{},
+		claimableBalances: map[string]ingest.Change{},
+	}
+	for _, change := range changes {
+		index.byType[change.Type] = append(index.byType[change.Type], change)
+
+		entry := change.Post
+		if entry == nil {
+			entry = change.Pre
+		}
+		if entry == nil {
+			continue
+		}
+		switch change.Type {
+		case xdr.LedgerEntryTypeLiquidityPool:
+			id := entry.Data.MustLiquidityPool().LiquidityPoolId
+			if _, ok := index.liquidityPools[id]; !ok {
+				index.liquidityPools[id] = change
+			}
+		case xdr.LedgerEntryTypeClaimableBalance:
+			// balances whose id cannot be encoded are left out, so that looking them up fails as not found
+			id, err := xdr.MarshalHex(entry.Data.MustClaimableBalance().BalanceId)
+			if err != nil {
+				continue
+			}
+			if _, ok := index.claimableBalances[id]; !ok {
+				index.claimableBalances[id] = change
+			}
+		}
+	}
+	return index
+}
+
+// ofType returns the changes of the entries of a type, in the order of the meta
+func (index *operationChangeIndex) ofType(entryType xdr.LedgerEntryType) []ingest.Change {
+	return index.byType[entryType]
+}
+
+// liquidityPool returns the change of a liquidity pool, or of the first pool changed if the id is nil
+func (index *operationChangeIndex) liquidityPool(id *xdr.PoolId) (ingest.Change, bool) {
+	if id == nil {
+		pools := index.ofType(xdr.LedgerEntryTypeLiquidityPool)
+		if len(pools) == 0 {
+			return ingest.Change{}, false
+		}
+		return pools[0], true
+	}
+	change, ok := index.liquidityPools[*id]
+	return change, ok
+}
+
+// claimableBalance returns the change of a claimable balance by its hex id
+func (index *operationChangeIndex) claimableBalance(id string) (ingest.Change, bool) {
+	change, ok := index.claimableBalances[id]
+	return change, ok
+}
+
+// operationChanges returns the index of the ledger entry changes of the operation, reading them from the meta the
+// first time
+func (operation *transactionOperationWrapper) operationChanges() (*operationChangeIndex, error) {
+	if operation.changeIndex != nil {
+		return operation.changeIndex, nil
+	}
+	changes, err := operation.transaction.GetOperationChanges(operation.index)
+	if err != nil {
+		return nil, err
+	}
+	operation.changeIndex = newOperationChangeIndex(changes)
+	return operation.changeIndex, nil
+}
@@ -0,0 +1,81 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationChangeIndex(t *testing.T) {
+	pool := func(id byte, reserveA xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+			Type: xdr.LedgerEntryTypeLiquidityPool,
+			LiquidityPool: &xdr.LiquidityPoolEntry{
+				LiquidityPoolId: xdr.PoolId{id},
+				Body: xdr.LiquidityPoolEntryBody{
+					Type:            xdr.LiquidityPoolTypeLiquidityPoolConstantProduct,
+					ConstantProduct: &xdr.LiquidityPoolEntryConstantProduct{ReserveA: reserveA},
+				},
+			},
+		}}
+	}
+	balance := &xdr.LedgerEntry{Data: xdr.LedgerEntryData{
+		Type:             xdr.LedgerEntryTypeClaimableBalance,
+		ClaimableBalance: &xdr.ClaimableBalanceEntry{BalanceId: genericClaimableBalance, Amount: 5},
+	}}
+	balanceID, err := xdr.MarshalHex(genericClaimableBalance)
+	require.NoError(t, err)
+
+	changes := []ingest.Change{
+		{Type: xdr.LedgerEntryTypeLiquidityPool, Pre: pool(1, 10), Post: pool(1, 15)},
+		{Type: xdr.LedgerEntryTypeAccount},
+		{Type: xdr.LedgerEntryTypeLiquidityPool, Pre: pool(2, 20), Post: pool(2, 18)},
+		{Type: xdr.LedgerEntryTypeClaimableBalance, Pre: balance},
+	}
+	index := newOperationChangeIndex(changes)
+
+	assert.Equal(t, changes, index.changes)
+	assert.Equal(t, []ingest.Change{changes[0], changes[2]}, index.ofType(xdr.LedgerEntryTypeLiquidityPool))
+	assert.Empty(t, index.ofType(xdr.LedgerEntryTypeOffer))
+
+	change, ok := index.liquidityPool(nil)
+	assert.True(t, ok)
+	assert.Equal(t, changes[0], change)
+	change, ok = index.liquidityPool(&xdr.PoolId{2})
+	assert.True(t, ok)
+	assert.Equal(t, changes[2], change)
+	_, ok = index.liquidityPool(&xdr.PoolId{3})
+	assert.False(t, ok)
+
+	change, ok = index.claimableBalance(balanceID)
+	assert.True(t, ok)
+	assert.Equal(t, changes[3], change)
+
+	// the lookups of the effect builders go through the index of the operation
+	operation := transactionOperationWrapper{changeIndex: index}
+	lp, delta, err := operation.getLiquidityPoolAndProductDelta(&xdr.PoolId{2})
+	assert.NoError(t, err)
+	assert.Equal(t, xdr.PoolId{2}, lp.LiquidityPoolId)
+	assert.Equal(t, xdr.Int64(-2), delta.ReserveA)
+	_, _, err = operation.getLiquidityPoolAndProductDelta(&xdr.PoolId{3})
+	assert.Equal(t, errLiquidityPoolChangeNotFound, err)
+}
+
+func TestOperationChangesAreReadOnce(t *testing.T) {
+	operation := transactionOperationWrapper{
+		transaction: ingest.LedgerTransaction{
+			UnsafeMeta: xdr.TransactionMeta{
+				V:  2,
+				V2: &xdr.TransactionMetaV2{Operations: []xdr.OperationMeta{{}}},
+			},
+		},
+	}
+	first, err := operation.operationChanges()
+	require.NoError(t, err)
+	second, err := operation.operationChanges()
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+}
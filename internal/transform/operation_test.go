@@ -10,6 +10,8 @@ import (
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 func TestTransformOperation(t *testing.T) {
@@ -68,8 +70,8 @@ func TestTransformOperation(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformOperation(test.input.operation, test.input.index, test.input.transaction, 0, test.input.ledgerClosedMeta, "")
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformOperation(test.input.operation, test.input.index, test.input.transaction, 0, test.input.ledgerClosedMeta, "", toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -1026,15 +1028,17 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			TypeString:    "create_account",
 			TransactionID: 4096,
 			OperationID:   4097,
+			PagingToken:   "4097",
 			OperationDetails: map[string]interface{}{
 				"account":          hardCodedDestAccountAddress,
 				"funder":           hardCodedSourceAccountAddress,
 				"starting_balance": 2.5,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "CreateAccountResultCodeCreateAccountSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "CreateAccountResultCodeCreateAccountSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"account":          hardCodedDestAccountAddress,
 				"funder":           hardCodedSourceAccountAddress,
@@ -1047,6 +1051,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4098,
+			PagingToken:   "4098",
 			OperationDetails: map[string]interface{}{
 				"from":         hardCodedSourceAccountAddress,
 				"to":           hardCodedDestAccountAddress,
@@ -1056,10 +1061,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_issuer": hardCodedDestAccountAddress,
 				"asset_id":     int64(-8205667356306085451),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "PaymentResultCodePaymentSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "PaymentResultCodePaymentSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"from":         hardCodedSourceAccountAddress,
 				"to":           hardCodedDestAccountAddress,
@@ -1076,6 +1082,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4099,
+			PagingToken:   "4099",
 			OperationDetails: map[string]interface{}{
 				"from":       hardCodedSourceAccountAddress,
 				"to":         hardCodedDestAccountAddress,
@@ -1083,10 +1090,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_type": "native",
 				"asset_id":   int64(-5706705804583548011),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "PaymentResultCodePaymentSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "PaymentResultCodePaymentSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"from":       hardCodedSourceAccountAddress,
 				"to":         hardCodedDestAccountAddress,
@@ -1101,6 +1109,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4100,
+			PagingToken:   "4100",
 			OperationDetails: map[string]interface{}{
 				"from":              hardCodedSourceAccountAddress,
 				"to":                hardCodedDestAccountAddress,
@@ -1113,10 +1122,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_id":          int64(-5706705804583548011),
 				"path":              []Path{usdtAssetPath},
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "PathPaymentStrictReceiveResultCodePathPaymentStrictReceiveSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"from":              hardCodedSourceAccountAddress,
 				"to":                hardCodedDestAccountAddress,
@@ -1136,6 +1146,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4101,
+			PagingToken:   "4101",
 			OperationDetails: map[string]interface{}{
 				"price":    0.514092,
 				"amount":   76.586,
@@ -1151,10 +1162,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"buying_asset_type":    "native",
 				"buying_asset_id":      int64(-5706705804583548011),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ManageSellOfferResultCodeManageSellOfferSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ManageSellOfferResultCodeManageSellOfferSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"price":    0.514092,
 				"amount":   76.586,
@@ -1177,6 +1189,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4102,
+			PagingToken:   "4102",
 			OperationDetails: map[string]interface{}{
 				"amount": 63.1595,
 				"price":  0.0791606,
@@ -1191,10 +1204,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"selling_asset_type":  "native",
 				"selling_asset_id":    int64(-5706705804583548011),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ManageSellOfferResultCodeManageSellOfferSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ManageSellOfferResultCodeManageSellOfferSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"amount": 63.1595,
 				"price":  0.0791606,
@@ -1216,37 +1230,39 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4103,
+			PagingToken:   "4103",
 			OperationDetails: map[string]interface{}{
 				"inflation_dest":    hardCodedDestAccountAddress,
 				"clear_flags":       []int32{1, 2},
 				"clear_flags_s":     []string{"auth_required", "auth_revocable"},
 				"set_flags":         []int32{4},
 				"set_flags_s":       []string{"auth_immutable"},
-				"master_key_weight": uint32(3),
-				"low_threshold":     uint32(1),
-				"med_threshold":     uint32(3),
-				"high_threshold":    uint32(5),
+				"master_key_weight": int64(3),
+				"low_threshold":     int64(1),
+				"med_threshold":     int64(3),
+				"high_threshold":    int64(5),
 				"home_domain":       "2019=DRA;n-test",
 				"signer_key":        "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
-				"signer_weight":     uint32(1),
+				"signer_weight":     int64(1),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "SetOptionsResultCodeSetOptionsSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "SetOptionsResultCodeSetOptionsSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"inflation_dest":    hardCodedDestAccountAddress,
 				"clear_flags":       []int32{1, 2},
 				"clear_flags_s":     []string{"auth_required", "auth_revocable"},
 				"set_flags":         []int32{4},
 				"set_flags_s":       []string{"auth_immutable"},
-				"master_key_weight": uint32(3),
-				"low_threshold":     uint32(1),
-				"med_threshold":     uint32(3),
-				"high_threshold":    uint32(5),
+				"master_key_weight": int64(3),
+				"low_threshold":     int64(1),
+				"med_threshold":     int64(3),
+				"high_threshold":    int64(5),
 				"home_domain":       "2019=DRA;n-test",
 				"signer_key":        "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
-				"signer_weight":     uint32(1),
+				"signer_weight":     int64(1),
 			},
 		},
 		{
@@ -1255,6 +1271,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4104,
+			PagingToken:   "4104",
 			OperationDetails: map[string]interface{}{
 				"trustor":      hardCodedSourceAccountAddress,
 				"trustee":      hardCodedDestAccountAddress,
@@ -1264,10 +1281,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_issuer": hardCodedDestAccountAddress,
 				"asset_id":     int64(6690054458235693884),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ChangeTrustResultCodeChangeTrustSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ChangeTrustResultCodeChangeTrustSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"trustor":      hardCodedSourceAccountAddress,
 				"trustee":      hardCodedDestAccountAddress,
@@ -1284,6 +1302,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4105,
+			PagingToken:   "4105",
 			OperationDetails: map[string]interface{}{
 				"trustor":                  hardCodedSourceAccountAddress,
 				"limit":                    50000000000.0,
@@ -1291,10 +1310,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"liquidity_pool_id":        "185a6b384c651552ba09b32851b79f5f6ab61e80883d303f52bea1406a4923f0",
 				"liquidity_pool_id_strkey": "LAMFU2ZYJRSRKUV2BGZSQUNXT5PWVNQ6QCED2MB7KK7KCQDKJER7BGLT",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ChangeTrustResultCodeChangeTrustSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ChangeTrustResultCodeChangeTrustSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"trustor":                  hardCodedSourceAccountAddress,
 				"limit":                    50000000000.0,
@@ -1309,6 +1329,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4106,
+			PagingToken:   "4106",
 			OperationDetails: map[string]interface{}{
 				"trustee":      hardCodedSourceAccountAddress,
 				"trustor":      hardCodedDestAccountAddress,
@@ -1318,10 +1339,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_issuer": hardCodedSourceAccountAddress,
 				"asset_id":     int64(8485542065083974675),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "AllowTrustResultCodeAllowTrustSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "AllowTrustResultCodeAllowTrustSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"trustee":      hardCodedSourceAccountAddress,
 				"trustor":      hardCodedDestAccountAddress,
@@ -1338,31 +1360,35 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4107,
+			PagingToken:   "4107",
 			OperationDetails: map[string]interface{}{
 				"account": hardCodedSourceAccountAddress,
 				"into":    hardCodedDestAccountAddress,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "AccountMergeResultCodeAccountMergeSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "AccountMergeResultCodeAccountMergeSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"account": hardCodedSourceAccountAddress,
 				"into":    hardCodedDestAccountAddress,
 			},
 		},
 		{
-			Type:                 9,
-			TypeString:           "inflation",
-			SourceAccount:        hardCodedSourceAccountAddress,
-			TransactionID:        4096,
-			OperationID:          4108,
-			OperationDetails:     map[string]interface{}{},
-			ClosedAt:             hardCodedLedgerClose,
-			OperationResultCode:  "OperationResultCodeOpInner",
-			OperationTraceCode:   "InflationResultCodeInflationSuccess",
-			LedgerSequence:       0,
-			OperationDetailsJSON: map[string]interface{}{},
+			Type:                  9,
+			TypeString:            "inflation",
+			SourceAccount:         hardCodedSourceAccountAddress,
+			TransactionID:         4096,
+			OperationID:           4108,
+			PagingToken:           "4108",
+			OperationDetails:      map[string]interface{}{},
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InflationResultCodeInflationSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
+			OperationDetailsJSON:  map[string]interface{}{},
 		},
 		{
 			Type:          10,
@@ -1370,14 +1396,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4109,
+			PagingToken:   "4109",
 			OperationDetails: map[string]interface{}{
 				"name":  "test",
 				"value": base64.StdEncoding.EncodeToString([]byte{0x76, 0x61, 0x6c, 0x75, 0x65}),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ManageDataResultCodeManageDataSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ManageDataResultCodeManageDataSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"name":  "test",
 				"value": base64.StdEncoding.EncodeToString([]byte{0x76, 0x61, 0x6c, 0x75, 0x65}),
@@ -1389,13 +1417,15 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4110,
+			PagingToken:   "4110",
 			OperationDetails: map[string]interface{}{
 				"bump_to": "100",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "BumpSequenceResultCodeBumpSequenceSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "BumpSequenceResultCodeBumpSequenceSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"bump_to": "100",
 			},
@@ -1406,6 +1436,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4111,
+			PagingToken:   "4111",
 			OperationDetails: map[string]interface{}{
 				"price":  0.3496823,
 				"amount": 765.4501001,
@@ -1421,10 +1452,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"buying_asset_id":      int64(-5706705804583548011),
 				"offer_id":             int64(100),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ManageBuyOfferResultCodeManageBuyOfferSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ManageBuyOfferResultCodeManageBuyOfferSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"price":  0.3496823,
 				"amount": 765.4501001,
@@ -1447,6 +1479,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4112,
+			PagingToken:   "4112",
 			OperationDetails: map[string]interface{}{
 				"from":              hardCodedSourceAccountAddress,
 				"to":                hardCodedDestAccountAddress,
@@ -1459,10 +1492,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_type":        "native",
 				"asset_id":          int64(-5706705804583548011),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "PathPaymentStrictSendResultCodePathPaymentStrictSendSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"from":              hardCodedSourceAccountAddress,
 				"to":                hardCodedDestAccountAddress,
@@ -1482,15 +1516,17 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4113,
+			PagingToken:   "4113",
 			OperationDetails: map[string]interface{}{
 				"asset":     "USDT:GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
 				"amount":    123456.789,
 				"claimants": []Claimant{testClaimantDetails},
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "CreateClaimableBalanceResultCodeCreateClaimableBalanceSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"asset":     "USDT:GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
 				"amount":    123456.789,
@@ -1503,15 +1539,17 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: testAccount3Address,
 			TransactionID: 4096,
 			OperationID:   4114,
+			PagingToken:   "4114",
 			OperationDetails: map[string]interface{}{
 				"claimant":          hardCodedSourceAccountAddress,
 				"balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
 				"balance_id_strkey": "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ClaimClaimableBalanceResultCodeClaimClaimableBalanceSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"claimant":          hardCodedSourceAccountAddress,
 				"balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
@@ -1524,13 +1562,15 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4115,
+			PagingToken:   "4115",
 			OperationDetails: map[string]interface{}{
 				"sponsored_id": hardCodedDestAccountAddress,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "BeginSponsoringFutureReservesResultCodeBeginSponsoringFutureReservesSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"sponsored_id": hardCodedDestAccountAddress,
 			},
@@ -1541,14 +1581,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4116,
+			PagingToken:   "4116",
 			OperationDetails: map[string]interface{}{
 				"signer_account_id": hardCodedDestAccountAddress,
 				"signer_key":        "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"signer_account_id": hardCodedDestAccountAddress,
 				"signer_key":        "GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
@@ -1560,13 +1602,15 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4117,
+			PagingToken:   "4117",
 			OperationDetails: map[string]interface{}{
 				"account_id": hardCodedDestAccountAddress,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"account_id": hardCodedDestAccountAddress,
 			},
@@ -1577,14 +1621,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4118,
+			PagingToken:   "4118",
 			OperationDetails: map[string]interface{}{
 				"claimable_balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
 				"claimable_balance_id_strkey": "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"claimable_balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
 				"claimable_balance_id_strkey": "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
@@ -1596,14 +1642,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4119,
+			PagingToken:   "4119",
 			OperationDetails: map[string]interface{}{
 				"data_account_id": hardCodedDestAccountAddress,
 				"data_name":       "test",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"data_account_id": hardCodedDestAccountAddress,
 				"data_name":       "test",
@@ -1615,13 +1663,15 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4120,
+			PagingToken:   "4120",
 			OperationDetails: map[string]interface{}{
 				"offer_id": int64(100),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"offer_id": int64(100),
 			},
@@ -1632,14 +1682,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4121,
+			PagingToken:   "4121",
 			OperationDetails: map[string]interface{}{
 				"trustline_account_id": testAccount3Address,
 				"trustline_asset":      "USTT:GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"trustline_account_id": testAccount3Address,
 				"trustline_asset":      "USTT:GBT4YAEGJQ5YSFUMNKX6BPBUOCPNAIOFAVZOF6MIME2CECBMEIUXFZZN",
@@ -1651,14 +1703,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4122,
+			PagingToken:   "4122",
 			OperationDetails: map[string]interface{}{
 				"liquidity_pool_id":        "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey": "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "RevokeSponsorshipResultCodeRevokeSponsorshipSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"liquidity_pool_id":        "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey": "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
@@ -1670,6 +1724,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4123,
+			PagingToken:   "4123",
 			OperationDetails: map[string]interface{}{
 				"from":         hardCodedDestAccountAddress,
 				"amount":       0.1598182,
@@ -1678,10 +1733,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset_type":   "credit_alphanum4",
 				"asset_id":     int64(-8205667356306085451),
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ClawbackResultCodeClawbackSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ClawbackResultCodeClawbackSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"from":         hardCodedDestAccountAddress,
 				"amount":       0.1598182,
@@ -1697,14 +1753,16 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4124,
+			PagingToken:   "4124",
 			OperationDetails: map[string]interface{}{
 				"balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
 				"balance_id_strkey": "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "ClawbackClaimableBalanceResultCodeClawbackClaimableBalanceSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"balance_id":        "000000000102030405060708090000000000000000000000000000000000000000000000",
 				"balance_id_strkey": "BAAACAQDAQCQMBYIBEAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAACPGI",
@@ -1716,6 +1774,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4125,
+			PagingToken:   "4125",
 			OperationDetails: map[string]interface{}{
 				"asset_code":    "USDT",
 				"asset_issuer":  "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
@@ -1727,10 +1786,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"set_flags":     []int32{4},
 				"set_flags_s":   []string{"clawback_enabled"},
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "SetTrustLineFlagsResultCodeSetTrustLineFlagsSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"asset_code":    "USDT",
 				"asset_issuer":  "GBVVRXLMNCJQW3IDDXC3X6XCH35B5Q7QXNMMFPENSOGUPQO7WO7HGZPA",
@@ -1749,6 +1809,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4126,
+			PagingToken:   "4126",
 			OperationDetails: map[string]interface{}{
 				"liquidity_pool_id":        "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey": "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
@@ -1774,10 +1835,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				},
 				"shares_received": 0.0000002,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "LiquidityPoolDepositResultCodeLiquidityPoolDepositSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"liquidity_pool_id":        "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey": "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
@@ -1810,6 +1872,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4127,
+			PagingToken:   "4127",
 			OperationDetails: map[string]interface{}{
 				"liquidity_pool_id":         "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey":  "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
@@ -1825,10 +1888,11 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"reserve_b_min_amount":      0.0000001,
 				"shares":                    0.0000004,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess",
-			LedgerSequence:      0,
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "LiquidityPoolWithdrawResultCodeLiquidityPoolWithdrawSuccess",
+			LedgerSequence:        0,
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"liquidity_pool_id":         "0102030405060708090000000000000000000000000000000000000000000000",
 				"liquidity_pool_id_strkey":  "LAAQEAYEAUDAOCAJAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAATUC",
@@ -1851,6 +1915,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4128,
+			PagingToken:   "4128",
 			OperationDetails: map[string]interface{}{
 				"function":              "HostFunctionTypeHostFunctionTypeInvokeContract",
 				"type":                  "invoke_contract",
@@ -1891,9 +1956,10 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 					),
 				},
 			},
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
-			ClosedAt:            hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
+			ClosedAt:              hardCodedLedgerClose,
 			OperationDetailsJSON: map[string]interface{}{
 				"function":              "HostFunctionTypeHostFunctionTypeInvokeContract",
 				"type":                  "invoke_contract",
@@ -1941,6 +2007,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4129,
+			PagingToken:   "4129",
 			OperationDetails: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContract",
 				"type":               "create_contract",
@@ -1950,9 +2017,10 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"address":            "CAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABSC4",
 				"ledger_key_hash":    nilStringArray,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContract",
 				"type":               "create_contract",
@@ -1969,6 +2037,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4130,
+			PagingToken:   "4130",
 			OperationDetails: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContract",
 				"type":               "create_contract",
@@ -1978,9 +2047,10 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				"asset":              ":GAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAWHF",
 				"ledger_key_hash":    nilStringArray,
 			},
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
-			ClosedAt:            hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
+			ClosedAt:              hardCodedLedgerClose,
 			OperationDetailsJSON: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContract",
 				"type":               "create_contract",
@@ -1997,6 +2067,7 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4131,
+			PagingToken:   "4131",
 			OperationDetails: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContractV2",
 				"type":               "create_contract_v2",
@@ -2022,9 +2093,10 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 				},
 				"parameters_json_decoded": []interface{}{json.RawMessage("{\"bool\":true}")},
 			},
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
-			ClosedAt:            hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
+			ClosedAt:              hardCodedLedgerClose,
 			OperationDetailsJSON: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeCreateContractV2",
 				"type":               "create_contract_v2",
@@ -2057,15 +2129,17 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4132,
+			PagingToken:   "4132",
 			OperationDetails: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeUploadContractWasm",
 				"type":               "upload_wasm",
 				"contract_code_hash": "",
 				"ledger_key_hash":    nilStringArray,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"function":           "HostFunctionTypeHostFunctionTypeUploadContractWasm",
 				"type":               "upload_wasm",
@@ -2079,19 +2153,21 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4133,
+			PagingToken:   "4133",
 			OperationDetails: map[string]interface{}{
 				"type":               "extend_footprint_ttl",
-				"extend_to":          xdr.Uint32(1234),
+				"extend_to":          int64(1234),
 				"contract_id":        "",
 				"contract_code_hash": "",
 				"ledger_key_hash":    nilStringArray,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"type":               "extend_footprint_ttl",
-				"extend_to":          xdr.Uint32(1234),
+				"extend_to":          int64(1234),
 				"contract_id":        "",
 				"contract_code_hash": "",
 				"ledger_key_hash":    nilStringArray,
@@ -2103,15 +2179,17 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 			SourceAccount: hardCodedSourceAccountAddress,
 			TransactionID: 4096,
 			OperationID:   4134,
+			PagingToken:   "4134",
 			OperationDetails: map[string]interface{}{
 				"type":               "restore_footprint",
 				"contract_id":        "",
 				"contract_code_hash": "",
 				"ledger_key_hash":    nilStringArray,
 			},
-			ClosedAt:            hardCodedLedgerClose,
-			OperationResultCode: "OperationResultCodeOpInner",
-			OperationTraceCode:  "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			ClosedAt:              hardCodedLedgerClose,
+			OperationResultCode:   "OperationResultCodeOpInner",
+			OperationTraceCode:    "InvokeHostFunctionResultCodeInvokeHostFunctionSuccess",
+			TransactionSuccessful: true,
 			OperationDetailsJSON: map[string]interface{}{
 				"type":               "restore_footprint",
 				"contract_id":        "",
@@ -2122,3 +2200,188 @@ func makeOperationTestOutputs() (transformedOperations []OperationOutput) {
 	}
 	return
 }
+
+func TestParseOperationTypes(t *testing.T) {
+	types, err := ParseOperationTypes([]string{"payment", "manage_sell_offer", "restore_footprint"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[xdr.OperationType]bool{
+		xdr.OperationTypePayment:          true,
+		xdr.OperationTypeManageSellOffer:  true,
+		xdr.OperationTypeRestoreFootprint: true,
+	}, types)
+
+	types, err = ParseOperationTypes(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, types)
+
+	_, err = ParseOperationTypes([]string{"payments"})
+	assert.EqualError(t, err, "unknown operation type: payments")
+}
+
+func TestClaimedOfferDetails(t *testing.T) {
+	source := xdr.MustMuxedAddress("GAUJETIZVEP2NRYLUESJ3LS66NVCEGMON4UDCBCSBEVPIID773P2W6AY")
+	seller := xdr.MustAddress("GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU")
+	usdAsset := xdr.MustNewCreditAsset("USD", "GAZAIOXF7GBHGPHOYJSTPIIC4K6AJM55S5Q44OCJHEHIF6YU2IHO6VHU")
+	nativeAsset := xdr.MustNewNativeAsset()
+
+	offerEntry := func(sellerID xdr.AccountId, offerID, amount xdr.Int64, price xdr.Price) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			LastModifiedLedgerSeq: 20,
+			Data: xdr.LedgerEntryData{
+				Type: xdr.LedgerEntryTypeOffer,
+				Offer: &xdr.OfferEntry{
+					SellerId: sellerID,
+					OfferId:  offerID,
+					Selling:  usdAsset,
+					Buying:   nativeAsset,
+					Amount:   amount,
+					Price:    price,
+				},
+			},
+		}
+	}
+	claim := func(offerID, amountSold, amountBought xdr.Int64) xdr.ClaimAtom {
+		return xdr.ClaimAtom{
+			Type: xdr.ClaimAtomTypeClaimAtomTypeOrderBook,
+			OrderBook: &xdr.ClaimOfferAtom{
+				SellerId:     seller,
+				OfferId:      offerID,
+				AssetSold:    usdAsset,
+				AmountSold:   amountSold,
+				AssetBought:  nativeAsset,
+				AmountBought: amountBought,
+			},
+		}
+	}
+
+	op := xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeManageSellOffer,
+			ManageSellOfferOp: &xdr.ManageSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  usdAsset,
+				Amount:  1000,
+				Price:   xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+	remainingOffer := offerEntry(source.ToAccountId(), 11, 500, xdr.Price{N: 1, D: 2})
+	transaction := func(successful bool, claims []xdr.ClaimAtom, changes xdr.LedgerEntryChanges) ingest.LedgerTransaction {
+		code := xdr.TransactionResultCodeTxSuccess
+		if !successful {
+			code = xdr.TransactionResultCodeTxFailed
+		}
+		return ingest.LedgerTransaction{
+			Envelope: xdr.TransactionEnvelope{
+				Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+				V1: &xdr.TransactionV1Envelope{
+					Tx: xdr.Transaction{
+						SourceAccount: source,
+						Operations:    []xdr.Operation{op},
+					},
+				},
+			},
+			Result: xdr.TransactionResultPair{
+				Result: xdr.TransactionResult{
+					Result: xdr.TransactionResultResult{
+						Code: code,
+						Results: &[]xdr.OperationResult{{
+							Code: xdr.OperationResultCodeOpInner,
+							Tr: &xdr.OperationResultTr{
+								Type: xdr.OperationTypeManageSellOffer,
+								ManageSellOfferResult: &xdr.ManageSellOfferResult{
+									Code: xdr.ManageSellOfferResultCodeManageSellOfferSuccess,
+									Success: &xdr.ManageOfferSuccessResult{
+										OffersClaimed: claims,
+										Offer: xdr.ManageOfferSuccessResultOffer{
+											Effect: xdr.ManageOfferEffectManageOfferCreated,
+											Offer:  remainingOffer.Data.Offer,
+										},
+									},
+								},
+							},
+						}},
+					},
+				},
+			},
+			UnsafeMeta: xdr.TransactionMeta{
+				V: 2,
+				V2: &xdr.TransactionMetaV2{
+					Operations: []xdr.OperationMeta{{Changes: changes}},
+				},
+			},
+		}
+	}
+
+	changes := xdr.LedgerEntryChanges{
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: offerEntry(seller, 10, 400, xdr.Price{N: 2, D: 1})},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: offerEntry(seller, 10, 150, xdr.Price{N: 2, D: 1})},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: offerEntry(seller, 12, 100, xdr.Price{N: 2, D: 1})},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryRemoved, Removed: &xdr.LedgerKey{
+			Type:  xdr.LedgerEntryTypeOffer,
+			Offer: &xdr.LedgerKeyOffer{SellerId: seller, OfferId: 12},
+		}},
+		{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: remainingOffer},
+	}
+	claims := []xdr.ClaimAtom{claim(10, 250, 500), claim(12, 100, 200)}
+	options := OperationOptions{ClaimedOffers: true}
+
+	output, err := TransformOperationWithOptions(op, 0, transaction(true, claims, changes), 0, genericLedgerCloseMeta, "", toid.Generator{}, options)
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string]interface{}{
+		{
+			"offer_id":         int64(10),
+			"seller_id":        seller.Address(),
+			"amount_sold":      0.000025,
+			"amount_bought":    0.00005,
+			"remaining_amount": 0.000015,
+			"fully_filled":     false,
+		},
+		{
+			"offer_id":         int64(12),
+			"seller_id":        seller.Address(),
+			"amount_sold":      0.00001,
+			"amount_bought":    0.00002,
+			"remaining_amount": 0.0,
+			"fully_filled":     true,
+		},
+	}, output.OperationDetails["claimed_offers"])
+	assert.Equal(t, map[string]interface{}{
+		"offer_id": int64(11),
+		"amount":   0.00005,
+		"price":    0.5,
+		"price_r":  Price{Numerator: 1, Denominator: 2},
+	}, output.OperationDetails["remaining_offer"])
+
+	// the details are opt-in
+	output, err = TransformOperation(op, 0, transaction(true, claims, changes), 0, genericLedgerCloseMeta, "", toid.Generator{})
+	assert.NoError(t, err)
+	assert.NotContains(t, output.OperationDetails, "claimed_offers")
+	assert.NotContains(t, output.OperationDetails, "remaining_offer")
+
+	// failed transactions did not change the book
+	output, err = TransformOperationWithOptions(op, 0, transaction(false, claims, nil), 0, genericLedgerCloseMeta, "", toid.Generator{}, options)
+	assert.NoError(t, err)
+	assert.NotContains(t, output.OperationDetails, "claimed_offers")
+
+	// a claimed offer missing from the meta is an error rather than an offer with nothing left
+	_, err = TransformOperationWithOptions(op, 0, transaction(true, []xdr.ClaimAtom{claim(13, 1, 2)}, changes), 0, genericLedgerCloseMeta, "", toid.Generator{}, options)
+	assert.ErrorContains(t, err, "claimed offer 13 is not in the changes of operation 0")
+
+	// the results of passive offers may have the manage sell offer arm set
+	op = xdr.Operation{
+		Body: xdr.OperationBody{
+			Type: xdr.OperationTypeCreatePassiveSellOffer,
+			CreatePassiveSellOfferOp: &xdr.CreatePassiveSellOfferOp{
+				Selling: nativeAsset,
+				Buying:  usdAsset,
+				Amount:  1000,
+				Price:   xdr.Price{N: 1, D: 2},
+			},
+		},
+	}
+	output, err = TransformOperationWithOptions(op, 0, transaction(true, claims, changes), 0, genericLedgerCloseMeta, "", toid.Generator{}, options)
+	assert.NoError(t, err)
+	assert.Len(t, output.OperationDetails["claimed_offers"], 2)
+	assert.Contains(t, output.OperationDetails, "remaining_offer")
+}
@@ -8,16 +8,12 @@
 
 package transform
 
-import (
-	"encoding/json"
-)
-
 type SchemaParquet interface {
 	ToParquet() interface{}
 }
 
 func toJSONString(v interface{}) string {
-	jsonData, err := json.Marshal(v)
+	jsonData, err := CanonicalJSON(v)
 	if err != nil {
 		return ""
 	}
@@ -76,7 +72,7 @@ func (to TransactionOutput) ToParquet() interface{} {
 		FeeAccount:                           to.FeeAccount,
 		FeeAccountMuxed:                      to.FeeAccountMuxed,
 		InnerTransactionHash:                 to.InnerTransactionHash,
-		NewMaxFee:                            int64(to.NewMaxFee),
+		NewMaxFee:                            to.NewMaxFee,
 		LedgerBounds:                         to.LedgerBounds,
 		MinAccountSequence:                   to.MinAccountSequence.Int64,
 		MinAccountSequenceAge:                to.MinAccountSequenceAge.Int64,
@@ -94,6 +90,14 @@ func (to TransactionOutput) ToParquet() interface{} {
 		TotalNonRefundableResourceFeeCharged: to.TotalNonRefundableResourceFeeCharged,
 		TotalRefundableResourceFeeCharged:    to.TotalRefundableResourceFeeCharged,
 		RentFeeCharged:                       to.RentFeeCharged,
+		SorobanReturnValue:                   to.SorobanReturnValue.String,
+		ContractEventsCount:                  int64(to.ContractEventsCount),
+		DiagnosticEventsCount:                int64(to.DiagnosticEventsCount),
+		PagingToken:                          to.PagingToken,
+		EnvelopeSize:                         to.EnvelopeSize.Int64,
+		ResultSize:                           to.ResultSize.Int64,
+		MetaSize:                             to.MetaSize.Int64,
+		OperationChangesCount:                to.OperationChangesCount.Int64,
 	}
 }
 
@@ -141,17 +145,19 @@ func (aso AccountSignerOutput) ToParquet() interface{} {
 
 func (oo OperationOutput) ToParquet() interface{} {
 	return OperationOutputParquet{
-		SourceAccount:       oo.SourceAccount,
-		SourceAccountMuxed:  oo.SourceAccountMuxed,
-		Type:                oo.Type,
-		TypeString:          oo.TypeString,
-		OperationDetails:    toJSONString(oo.OperationDetails),
-		TransactionID:       oo.TransactionID,
-		OperationID:         oo.OperationID,
-		ClosedAt:            oo.ClosedAt.UnixMilli(),
-		OperationResultCode: oo.OperationResultCode,
-		OperationTraceCode:  oo.OperationTraceCode,
-		LedgerSequence:      int64(oo.LedgerSequence),
+		SourceAccount:         oo.SourceAccount,
+		SourceAccountMuxed:    oo.SourceAccountMuxed,
+		Type:                  oo.Type,
+		TypeString:            oo.TypeString,
+		OperationDetails:      toJSONString(oo.OperationDetails),
+		TransactionID:         oo.TransactionID,
+		OperationID:           oo.OperationID,
+		ClosedAt:              oo.ClosedAt.UnixMilli(),
+		OperationResultCode:   oo.OperationResultCode,
+		OperationTraceCode:    oo.OperationTraceCode,
+		LedgerSequence:        int64(oo.LedgerSequence),
+		TransactionSuccessful: oo.TransactionSuccessful,
+		PagingToken:           oo.PagingToken,
 	}
 }
 
@@ -186,11 +192,54 @@ func (ao AssetOutput) ToParquet() interface{} {
 		AssetIssuer:    ao.AssetIssuer,
 		AssetType:      ao.AssetType,
 		AssetID:        ao.AssetID,
+		ContractID:     ao.ContractID,
 		ClosedAt:       ao.ClosedAt.UnixMilli(),
 		LedgerSequence: int64(ao.LedgerSequence),
 	}
 }
 
+func (ado AssetDimensionOutput) ToParquet() interface{} {
+	return AssetDimensionOutputParquet{
+		AssetCode:       ado.AssetCode,
+		AssetIssuer:     ado.AssetIssuer,
+		AssetType:       ado.AssetType,
+		AssetID:         ado.AssetID,
+		FirstSeenLedger: int64(ado.FirstSeenLedger),
+		ContractID:      ado.ContractID.String,
+	}
+}
+
+func (aso AccountSummaryOutput) ToParquet() interface{} {
+	return AccountSummaryOutputParquet{
+		AccountID:        aso.AccountID,
+		FirstSeenLedger:  int64(aso.FirstSeenLedger),
+		LastSeenLedger:   int64(aso.LastSeenLedger),
+		Funder:           aso.Funder.String,
+		PaymentsSent:     int64(aso.PaymentsSent),
+		PaymentsReceived: int64(aso.PaymentsReceived),
+		Flags:            int64(aso.Flags),
+		Deleted:          aso.Deleted,
+	}
+}
+
+func (mso MuxedAccountStatsOutput) ToParquet() interface{} {
+	return MuxedAccountStatsOutputParquet{
+		AccountID:        mso.AccountID,
+		MuxedID:          int64(mso.MuxedID),
+		AccountMuxed:     mso.AccountMuxed,
+		AssetCode:        mso.AssetCode,
+		AssetIssuer:      mso.AssetIssuer,
+		AssetType:        mso.AssetType,
+		AssetID:          mso.AssetID,
+		PaymentsSent:     int64(mso.PaymentsSent),
+		PaymentsReceived: int64(mso.PaymentsReceived),
+		AmountSent:       mso.AmountSent,
+		AmountReceived:   mso.AmountReceived,
+		FirstSeenLedger:  int64(mso.FirstSeenLedger),
+		LastSeenLedger:   int64(mso.LastSeenLedger),
+	}
+}
+
 func (to TrustlineOutput) ToParquet() interface{} {
 	return TrustlineOutputParquet{
 		LedgerKey:          to.LedgerKey,
@@ -214,6 +263,60 @@ func (to TrustlineOutput) ToParquet() interface{} {
 	}
 }
 
+func (psho PoolShareHolderOutput) ToParquet() interface{} {
+	return PoolShareHolderOutputParquet{
+		LedgerKey:             psho.LedgerKey,
+		AccountID:             psho.AccountID,
+		LiquidityPoolID:       psho.LiquidityPoolID,
+		LiquidityPoolIDStrkey: psho.LiquidityPoolIDStrkey,
+		Balance:               psho.Balance,
+		TrustlineLimit:        psho.TrustlineLimit,
+		LastModifiedLedger:    int64(psho.LastModifiedLedger),
+		LedgerEntryChange:     int64(psho.LedgerEntryChange),
+		Deleted:               psho.Deleted,
+		ClosedAt:              psho.ClosedAt.UnixMilli(),
+		LedgerSequence:        int64(psho.LedgerSequence),
+	}
+}
+
+func (hdo HomeDomainOutput) ToParquet() interface{} {
+	return HomeDomainOutputParquet{
+		AccountID:      hdo.AccountID,
+		OldHomeDomain:  hdo.OldHomeDomain,
+		NewHomeDomain:  hdo.NewHomeDomain,
+		ClosedAt:       hdo.ClosedAt.UnixMilli(),
+		LedgerSequence: int64(hdo.LedgerSequence),
+	}
+}
+
+func (ido InflationDestinationOutput) ToParquet() interface{} {
+	return InflationDestinationOutputParquet{
+		AccountID:               ido.AccountID,
+		OldInflationDestination: ido.OldInflationDestination,
+		NewInflationDestination: ido.NewInflationDestination,
+		ClosedAt:                ido.ClosedAt.UnixMilli(),
+		LedgerSequence:          int64(ido.LedgerSequence),
+	}
+}
+
+func (afo AccountFlagsOutput) ToParquet() interface{} {
+	return AccountFlagsOutputParquet{
+		AccountID:              afo.AccountID,
+		OldFlags:               int64(afo.OldFlags),
+		NewFlags:               int64(afo.NewFlags),
+		OldAuthRequired:        afo.OldAuthRequired,
+		NewAuthRequired:        afo.NewAuthRequired,
+		OldAuthRevocable:       afo.OldAuthRevocable,
+		NewAuthRevocable:       afo.NewAuthRevocable,
+		OldAuthImmutable:       afo.OldAuthImmutable,
+		NewAuthImmutable:       afo.NewAuthImmutable,
+		OldAuthClawbackEnabled: afo.OldAuthClawbackEnabled,
+		NewAuthClawbackEnabled: afo.NewAuthClawbackEnabled,
+		ClosedAt:               afo.ClosedAt.UnixMilli(),
+		LedgerSequence:         int64(afo.LedgerSequence),
+	}
+}
+
 func (oo OfferOutput) ToParquet() interface{} {
 	return OfferOutputParquet{
 		SellerID:           oo.SellerID,
@@ -237,6 +340,37 @@ func (oo OfferOutput) ToParquet() interface{} {
 		Sponsor:            oo.Sponsor.String,
 		ClosedAt:           oo.ClosedAt.UnixMilli(),
 		LedgerSequence:     int64(oo.LedgerSequence),
+		PriceDecimal:       oo.PriceDecimal,
+		OfferIDStr:         oo.OfferIDStr,
+	}
+}
+
+func (oeo OfferEventOutput) ToParquet() interface{} {
+	return OfferEventOutputParquet{
+		OfferID:            oeo.OfferID,
+		SellerID:           oeo.SellerID,
+		EventType:          oeo.EventType,
+		Cause:              oeo.Cause,
+		SellingAssetType:   oeo.SellingAssetType,
+		SellingAssetCode:   oeo.SellingAssetCode,
+		SellingAssetIssuer: oeo.SellingAssetIssuer,
+		SellingAssetID:     oeo.SellingAssetID,
+		BuyingAssetType:    oeo.BuyingAssetType,
+		BuyingAssetCode:    oeo.BuyingAssetCode,
+		BuyingAssetIssuer:  oeo.BuyingAssetIssuer,
+		BuyingAssetID:      oeo.BuyingAssetID,
+		Amount:             oeo.Amount,
+		PriceN:             oeo.PriceN,
+		PriceD:             oeo.PriceD,
+		Price:              oeo.Price,
+		OperationID:        oeo.OperationID,
+		OperationType:      oeo.OperationType,
+		TransactionID:      oeo.TransactionID,
+		TransactionHash:    oeo.TransactionHash,
+		ClosedAt:           oeo.ClosedAt.UnixMilli(),
+		LedgerSequence:     int64(oeo.LedgerSequence),
+		PriceDecimal:       oeo.PriceDecimal,
+		OfferIDStr:         oeo.OfferIDStr,
 	}
 }
 
@@ -266,21 +400,108 @@ func (to TradeOutput) ToParquet() interface{} {
 		TradeType:              to.TradeType,
 		RoundingSlippage:       to.RoundingSlippage.Int64,
 		SellerIsExact:          to.SellerIsExact.Bool,
+		PagingToken:            to.PagingToken,
+		PairID:                 to.PairID,
+		BaseIsSeller:           to.BaseIsSeller,
+		BaseAccountAddress:     to.BaseAccountAddress.String,
+		BaseAssetID:            to.BaseAssetID.Int64,
+		BaseAmount:             to.BaseAmount.Float64,
+		CounterAccountAddress:  to.CounterAccountAddress.String,
+		CounterAssetID:         to.CounterAssetID.Int64,
+		CounterAmount:          to.CounterAmount.Float64,
+		BasePriceN:             to.BasePriceN.Int64,
+		BasePriceD:             to.BasePriceD.Int64,
+		PriceDecimal:           to.PriceDecimal,
+		BasePriceDecimal:       to.BasePriceDecimal.String,
+		TradeID:                to.TradeID,
+		ClaimAtomType:          to.ClaimAtomType,
+		SellingOfferIDStr:      to.SellingOfferIDStr.String,
+		BuyingOfferIDStr:       to.BuyingOfferIDStr.String,
 	}
 }
 
 func (eo EffectOutput) ToParquet() interface{} {
 	return EffectOutputParquet{
-		Address:        eo.Address,
-		AddressMuxed:   eo.AddressMuxed.String,
-		OperationID:    eo.OperationID,
-		Details:        toJSONString(eo.Details),
-		Type:           eo.Type,
-		TypeString:     eo.TypeString,
-		LedgerClosed:   eo.LedgerClosed.UnixMilli(),
-		LedgerSequence: int64(eo.LedgerSequence),
-		EffectIndex:    int64(eo.EffectIndex),
-		EffectId:       eo.EffectId,
+		Address:               eo.Address,
+		AddressMuxed:          eo.AddressMuxed.String,
+		OperationID:           eo.OperationID,
+		Details:               toJSONString(eo.Details),
+		Type:                  eo.Type,
+		TypeString:            eo.TypeString,
+		LedgerClosed:          eo.LedgerClosed.UnixMilli(),
+		LedgerSequence:        int64(eo.LedgerSequence),
+		EffectIndex:           int64(eo.EffectIndex),
+		EffectId:              eo.EffectId,
+		TransactionSuccessful: eo.TransactionSuccessful,
+		Category:              eo.Category,
+		PagingToken:           eo.PagingToken,
+	}
+}
+
+func (ewo EffectWideOutput) ToParquet() interface{} {
+	return EffectWideOutputParquet{
+		Address:               ewo.Address,
+		AddressMuxed:          ewo.AddressMuxed.String,
+		OperationID:           ewo.OperationID,
+		Type:                  ewo.Type,
+		TypeString:            ewo.TypeString,
+		LedgerClosed:          ewo.LedgerClosed.UnixMilli(),
+		LedgerSequence:        int64(ewo.LedgerSequence),
+		EffectIndex:           int64(ewo.EffectIndex),
+		EffectId:              ewo.EffectId,
+		TransactionSuccessful: ewo.TransactionSuccessful,
+		Category:              ewo.Category,
+		Amount:                ewo.Amount.String,
+		StartingBalance:       ewo.StartingBalance.String,
+		Asset:                 ewo.Asset.String,
+		AssetType:             ewo.AssetType.String,
+		AssetCode:             ewo.AssetCode.String,
+		AssetIssuer:           ewo.AssetIssuer.String,
+		AssetID:               ewo.AssetID.Int64,
+		AssetContractID:       ewo.AssetContractID.String,
+		BalanceID:             ewo.BalanceID.String,
+		LiquidityPoolID:       ewo.LiquidityPoolID.String,
+		OfferID:               ewo.OfferID.Int64,
+		Seller:                ewo.Seller.String,
+		SoldAmount:            ewo.SoldAmount.String,
+		SoldAssetType:         ewo.SoldAssetType.String,
+		SoldAssetCode:         ewo.SoldAssetCode.String,
+		SoldAssetIssuer:       ewo.SoldAssetIssuer.String,
+		SoldAssetID:           ewo.SoldAssetID.Int64,
+		BoughtAmount:          ewo.BoughtAmount.String,
+		BoughtAssetType:       ewo.BoughtAssetType.String,
+		BoughtAssetCode:       ewo.BoughtAssetCode.String,
+		BoughtAssetIssuer:     ewo.BoughtAssetIssuer.String,
+		BoughtAssetID:         ewo.BoughtAssetID.Int64,
+		Trustor:               ewo.Trustor.String,
+		PublicKey:             ewo.PublicKey.String,
+		Weight:                ewo.Weight.Int64,
+		Sponsor:               ewo.Sponsor.String,
+		FormerSponsor:         ewo.FormerSponsor.String,
+		NewSponsor:            ewo.NewSponsor.String,
+		Contract:              ewo.Contract.String,
+		Details:               toJSONString(ewo.Details),
+		PagingToken:           ewo.PagingToken,
+		TradeType:             ewo.TradeType.String,
+		LiquidityPoolFeeBp:    ewo.LiquidityPoolFeeBp.Int64,
+		OfferIDStr:            ewo.OfferIDStr.String,
+	}
+}
+
+func (cbo ContractBalanceOutput) ToParquet() interface{} {
+	return ContractBalanceOutputParquet{
+		ContractId:         cbo.ContractId,
+		Holder:             cbo.Holder,
+		HolderType:         cbo.HolderType,
+		Balance:            cbo.Balance,
+		Authorized:         cbo.Authorized,
+		Clawback:           cbo.Clawback,
+		LastModifiedLedger: int64(cbo.LastModifiedLedger),
+		LedgerEntryChange:  int64(cbo.LedgerEntryChange),
+		Deleted:            cbo.Deleted,
+		ClosedAt:           cbo.ClosedAt.UnixMilli(),
+		LedgerSequence:     int64(cbo.LedgerSequence),
+		LedgerKeyHash:      cbo.LedgerKeyHash,
 	}
 }
 
@@ -292,6 +513,7 @@ func (cdo ContractDataOutput) ToParquet() interface{} {
 		ContractDataAssetCode:     cdo.ContractDataAssetCode,
 		ContractDataAssetIssuer:   cdo.ContractDataAssetIssuer,
 		ContractDataAssetType:     cdo.ContractDataAssetType,
+		ContractDataAssetID:       cdo.ContractDataAssetID.Int64,
 		ContractDataBalanceHolder: cdo.ContractDataBalanceHolder,
 		ContractDataBalance:       cdo.ContractDataBalance,
 		LastModifiedLedger:        int64(cdo.LastModifiedLedger),
@@ -310,24 +532,30 @@ func (cdo ContractDataOutput) ToParquet() interface{} {
 
 func (cco ContractCodeOutput) ToParquet() interface{} {
 	return ContractCodeOutputParquet{
-		ContractCodeHash:   cco.ContractCodeHash,
-		ContractCodeExtV:   cco.ContractCodeExtV,
-		LastModifiedLedger: int64(cco.LastModifiedLedger),
-		LedgerEntryChange:  int64(cco.LedgerEntryChange),
-		Deleted:            cco.Deleted,
-		ClosedAt:           cco.ClosedAt.UnixMilli(),
-		LedgerSequence:     int64(cco.LedgerSequence),
-		LedgerKeyHash:      cco.LedgerKeyHash,
-		NInstructions:      int64(cco.NInstructions),
-		NFunctions:         int64(cco.NFunctions),
-		NGlobals:           int64(cco.NGlobals),
-		NTableEntries:      int64(cco.NTableEntries),
-		NTypes:             int64(cco.NTypes),
-		NDataSegments:      int64(cco.NDataSegments),
-		NElemSegments:      int64(cco.NElemSegments),
-		NImports:           int64(cco.NImports),
-		NExports:           int64(cco.NExports),
-		NDataSegmentBytes:  int64(cco.NDataSegmentBytes),
+		ContractCodeHash:       cco.ContractCodeHash,
+		ContractCodeExtV:       cco.ContractCodeExtV,
+		LastModifiedLedger:     int64(cco.LastModifiedLedger),
+		LedgerEntryChange:      int64(cco.LedgerEntryChange),
+		Deleted:                cco.Deleted,
+		ClosedAt:               cco.ClosedAt.UnixMilli(),
+		LedgerSequence:         int64(cco.LedgerSequence),
+		LedgerKeyHash:          cco.LedgerKeyHash,
+		NInstructions:          int64(cco.NInstructions),
+		NFunctions:             int64(cco.NFunctions),
+		NGlobals:               int64(cco.NGlobals),
+		NTableEntries:          int64(cco.NTableEntries),
+		NTypes:                 int64(cco.NTypes),
+		NDataSegments:          int64(cco.NDataSegments),
+		NElemSegments:          int64(cco.NElemSegments),
+		NImports:               int64(cco.NImports),
+		NExports:               int64(cco.NExports),
+		NDataSegmentBytes:      int64(cco.NDataSegmentBytes),
+		EnvInterfaceProtocol:   cco.EnvInterfaceProtocol.Int64,
+		EnvInterfacePreRelease: cco.EnvInterfacePreRelease.Int64,
+		RustVersion:            cco.RustVersion.String,
+		RustSdkVersion:         cco.RustSdkVersion.String,
+		ContractMeta:           toJSONString(cco.ContractMeta),
+		ContractFunctions:      toJSONString(cco.ContractFunctions),
 	}
 }
 
@@ -391,9 +619,28 @@ func (cso ConfigSettingOutput) ToParquet() interface{} {
 	}
 }
 
+func (ado AccountDataOutput) ToParquet() interface{} {
+	return AccountDataOutputParquet{
+		AccountID:          ado.AccountID,
+		DataName:           ado.DataName,
+		DataValue:          ado.DataValue,
+		DataValueDecoded:   ado.DataValueDecoded.String,
+		Sponsor:            ado.Sponsor.String,
+		LastModifiedLedger: int64(ado.LastModifiedLedger),
+		LedgerEntryChange:  int64(ado.LedgerEntryChange),
+		Deleted:            ado.Deleted,
+		ClosedAt:           ado.ClosedAt.UnixMilli(),
+		LedgerSequence:     int64(ado.LedgerSequence),
+		CreatedLedger:      ado.CreatedLedger.Int64,
+		RemovedLedger:      ado.RemovedLedger.Int64,
+	}
+}
+
 func (to TtlOutput) ToParquet() interface{} {
 	return TtlOutputParquet{
 		KeyHash:            to.KeyHash,
+		LedgerKey:          to.LedgerKey,
+		LedgerKeyCanonical: to.LedgerKeyCanonical,
 		LiveUntilLedgerSeq: int64(to.LiveUntilLedgerSeq),
 		LastModifiedLedger: int64(to.LastModifiedLedger),
 		LedgerEntryChange:  int64(to.LedgerEntryChange),
@@ -403,6 +650,25 @@ func (to TtlOutput) ToParquet() interface{} {
 	}
 }
 
+func (aho ArchivalHistoryOutput) ToParquet() interface{} {
+	return ArchivalHistoryOutputParquet{
+		KeyHash:                    aho.KeyHash,
+		EventType:                  aho.EventType,
+		LedgerKeyType:              aho.LedgerKeyType,
+		LedgerKey:                  aho.LedgerKey,
+		LedgerKeyCanonical:         aho.LedgerKeyCanonical,
+		ContractId:                 aho.ContractId,
+		ContractCodeHash:           aho.ContractCodeHash,
+		ContractDurability:         aho.ContractDurability,
+		LiveUntilLedgerSeq:         aho.LiveUntilLedgerSeq.Int64,
+		PreviousLiveUntilLedgerSeq: aho.PreviousLiveUntilLedgerSeq.Int64,
+		TransactionHash:            aho.TransactionHash.String,
+		OperationID:                aho.OperationID.Int64,
+		LedgerSequence:             int64(aho.LedgerSequence),
+		ClosedAt:                   aho.ClosedAt.UnixMilli(),
+	}
+}
+
 func (ceo ContractEventOutput) ToParquet() interface{} {
 	return ContractEventOutputParquet{
 		TransactionHash:          ceo.TransactionHash,
@@ -416,8 +682,44 @@ func (ceo ContractEventOutput) ToParquet() interface{} {
 		TypeString:               ceo.TypeString,
 		Topics:                   ceo.Topics,
 		TopicsDecoded:            ceo.TopicsDecoded,
+		Topic0:                   ceo.Topic0.String,
+		Topic1:                   ceo.Topic1.String,
+		Topic2:                   ceo.Topic2.String,
+		Topic3:                   ceo.Topic3.String,
+		Topic0Symbol:             ceo.Topic0Symbol.String,
 		Data:                     ceo.Data,
 		DataDecoded:              ceo.DataDecoded,
 		ContractEventXDR:         ceo.ContractEventXDR,
 	}
 }
+
+func (fo FeeOutput) ToParquet() interface{} {
+	return FeeOutputParquet{
+		TransactionHash:     fo.TransactionHash,
+		TransactionID:       fo.TransactionID,
+		LedgerSequence:      int64(fo.LedgerSequence),
+		FeeAccount:          fo.FeeAccount,
+		MaxFee:              fo.MaxFee,
+		InitialFeeCharged:   fo.InitialFeeCharged,
+		FeeRefund:           fo.FeeRefund,
+		FeeCharged:          fo.FeeCharged,
+		InclusionFeeCharged: fo.InclusionFeeCharged,
+		BaseFee:             int64(fo.BaseFee),
+		SurgePriced:         fo.SurgePriced,
+		Soroban:             fo.Soroban,
+		Successful:          fo.Successful,
+		ClosedAt:            fo.ClosedAt.UnixMilli(),
+	}
+}
+
+func (nuo NetworkUpgradeOutput) ToParquet() interface{} {
+	return NetworkUpgradeOutputParquet{
+		LedgerSequence:  int64(nuo.LedgerSequence),
+		UpgradeIndex:    int64(nuo.UpgradeIndex),
+		UpgradeType:     nuo.UpgradeType,
+		ConfigSettingId: nuo.ConfigSettingId.Int64,
+		PreviousValue:   nuo.PreviousValue.String,
+		NewValue:        nuo.NewValue,
+		ClosedAt:        nuo.ClosedAt.UnixMilli(),
+	}
+}
@@ -0,0 +1,64 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+)
+
+// TransformPoolShareHolder converts a liquidity pool share trustline into a row of the pool share holders. The returned
+// bool is false when the trustline change is not of pool shares.
+func TransformPoolShareHolder(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ PoolShareHolderOutput, _ bool, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
+	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
+	if err != nil {
+		return PoolShareHolderOutput{}, false, err
+	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return PoolShareHolderOutput{}, false, err
+	}
+
+	trustEntry, ok := ledgerEntry.Data.GetTrustLine()
+	if !ok {
+		return PoolShareHolderOutput{}, false, fmt.Errorf("could not extract trustline data from ledger entry; actual type is %s", ledgerEntry.Data.Type)
+	}
+	poolID, ok := trustEntry.Asset.GetLiquidityPoolId()
+	if !ok {
+		return PoolShareHolderOutput{}, false, nil
+	}
+
+	outputAccountID, err := trustEntry.AccountId.GetAddress()
+	if err != nil {
+		return PoolShareHolderOutput{}, false, err
+	}
+	poolIDStrkey, err := strkey.Encode(strkey.VersionByteLiquidityPool, poolID[:])
+	if err != nil {
+		return PoolShareHolderOutput{}, false, err
+	}
+	outputLedgerKey, err := trustLineEntryToLedgerKeyString(trustEntry)
+	if err != nil {
+		return PoolShareHolderOutput{}, false, fmt.Errorf("could not create ledger key string for the pool share trustline of account %s: %v", outputAccountID, err)
+	}
+
+	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
+	if err != nil {
+		return PoolShareHolderOutput{}, false, err
+	}
+
+	return PoolShareHolderOutput{
+		LedgerKey:             outputLedgerKey,
+		AccountID:             outputAccountID,
+		LiquidityPoolID:       PoolIDToString(poolID),
+		LiquidityPoolIDStrkey: poolIDStrkey,
+		Balance:               utils.ConvertStroopValueToReal(trustEntry.Balance),
+		TrustlineLimit:        int64(trustEntry.Limit),
+		LastModifiedLedger:    uint32(ledgerEntry.LastModifiedLedgerSeq),
+		LedgerEntryChange:     uint32(changeType),
+		Deleted:               outputDeleted,
+		ClosedAt:              closedAt,
+		LedgerSequence:        uint32(header.Header.LedgerSeq),
+	}, true, nil
+}
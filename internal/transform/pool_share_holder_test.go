@@ -0,0 +1,51 @@
+package transform
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransformPoolShareHolder(t *testing.T) {
+	header := xdr.LedgerHeaderHistoryEntry{
+		Header: xdr.LedgerHeader{
+			ScpValue: xdr.StellarValue{
+				CloseTime: 1000,
+			},
+			LedgerSeq: 10,
+		},
+	}
+	changes := makeTrustlineTestInput()
+
+	// Trustlines of other assets are not pool shares
+	_, ok, err := TransformPoolShareHolder(changes[0], header)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	holder, ok, err := TransformPoolShareHolder(changes[1], header)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, PoolShareHolderOutput{
+		LedgerKey:             "AAAAAQAAAAAcR0GXGO76pFs4y38vJVAanjnLg4emNun7zAx0pHcDGAAAAAMBAwQFBwkAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA==",
+		AccountID:             testAccount2Address,
+		LiquidityPoolID:       "0103040507090000000000000000000000000000000000000000000000000000",
+		LiquidityPoolIDStrkey: "LAAQGBAFA4EQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA2VM",
+		Balance:               0.5,
+		TrustlineLimit:        1111111111111111111,
+		LastModifiedLedger:    123456789,
+		LedgerEntryChange:     1,
+		LedgerSequence:        10,
+		ClosedAt:              time.Date(1970, time.January, 1, 0, 16, 40, 0, time.UTC),
+	}, holder)
+
+	removed := changes[1]
+	removed.Pre, removed.Post = removed.Post, nil
+	holder, ok, err = TransformPoolShareHolder(removed, header)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, holder.Deleted)
+	assert.Equal(t, uint32(2), holder.LedgerEntryChange)
+}
@@ -0,0 +1,87 @@
+package transform
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/xdr"
+)
+
+// Protocol versions at which the shape of the ledger close metas changed. The transforms check the protocol version
+// of the ledger header before reading the parts of a ledger that only exist from one of them, so that unexpected
+// metas fail with an error instead of a nil pointer.
+const (
+	// ProtocolSoroban added the contract data, contract code, config setting and ttl ledger entries, Soroban
+	// transactions with TransactionMeta V3 and the generalized transaction sets
+	ProtocolSoroban uint32 = 20
+	// ProtocolSorobanFeeBumpFix fixed the fee charged to Soroban fee bump transactions
+	ProtocolSorobanFeeBumpFix uint32 = 21
+	// ProtocolUnifiedEvents moved the contract events to TransactionMeta V4 and LedgerCloseMeta V2 and added events
+	// for the classic operations and fees (CAP-67)
+	ProtocolUnifiedEvents uint32 = 23
+	// MaxSupportedProtocol is the latest protocol whose ledgers the transforms can read
+	MaxSupportedProtocol = ProtocolUnifiedEvents - 1
+)
+
+// maxTransactionMetaVersion is the latest TransactionMeta version the transforms can read
+const maxTransactionMetaVersion = 3
+
+// sorobanEntryTypes are the ledger entry types added by ProtocolSoroban
+var sorobanEntryTypes = map[xdr.LedgerEntryType]bool{
+	xdr.LedgerEntryTypeContractData:  true,
+	xdr.LedgerEntryTypeContractCode:  true,
+	xdr.LedgerEntryTypeConfigSetting: true,
+	xdr.LedgerEntryTypeTtl:           true,
+}
+
+// versionError returns a TransformError for a part of a ledger whose version the transforms do not support. Its
+// coordinates are set by the transform that returns it.
+func versionError(format string, args ...interface{}) error {
+	return &TransformError{Code: ErrorCodeUnsupportedVersion, OperationIndex: -1, Err: fmt.Errorf(format, args...)}
+}
+
+// checkLedgerProtocol returns an error for the ledgers of protocols that are newer than MaxSupportedProtocol
+func checkLedgerProtocol(protocolVersion uint32) error {
+	if protocolVersion >= ProtocolUnifiedEvents {
+		return versionError("protocol %d ledgers carry unified events in TransactionMeta V4 and LedgerCloseMeta V2, which are not supported; the latest supported protocol is %d", protocolVersion, MaxSupportedProtocol)
+	}
+	return nil
+}
+
+// checkLedgerEntryProtocol returns an error for the ledger entries that cannot exist at the protocol of their ledger
+func checkLedgerEntryProtocol(entryType xdr.LedgerEntryType, protocolVersion uint32) error {
+	if err := checkLedgerProtocol(protocolVersion); err != nil {
+		return err
+	}
+	if sorobanEntryTypes[entryType] && protocolVersion < ProtocolSoroban {
+		return versionError("%s entries only exist from protocol %d, but the ledger is at protocol %d", entryType, ProtocolSoroban, protocolVersion)
+	}
+	return nil
+}
+
+// checkTransactionMeta returns an error for the transaction metas whose version the transforms cannot read, or that
+// cannot exist at the protocol of their ledger
+func checkTransactionMeta(meta xdr.TransactionMeta, protocolVersion uint32) error {
+	if err := checkLedgerProtocol(protocolVersion); err != nil {
+		return err
+	}
+	if meta.V > maxTransactionMetaVersion {
+		return versionError("TransactionMeta V%d is not supported; the latest supported version is V%d", meta.V, maxTransactionMetaVersion)
+	}
+	if meta.V == 3 && protocolVersion < ProtocolSoroban {
+		return versionError("TransactionMeta V3 only exists from protocol %d, but the ledger is at protocol %d", ProtocolSoroban, protocolVersion)
+	}
+	return nil
+}
+
+// closeMetaProtocolVersion returns the protocol version of the ledger of a close meta. It returns false when the close
+// meta has no header, as for the operations read from the history archives.
+func closeMetaProtocolVersion(lcm xdr.LedgerCloseMeta) (uint32, bool) {
+	switch {
+	case lcm.V == 0 && lcm.V0 != nil:
+		return uint32(lcm.V0.LedgerHeader.Header.LedgerVersion), true
+	case lcm.V == 1 && lcm.V1 != nil:
+		return uint32(lcm.V1.LedgerHeader.Header.LedgerVersion), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,56 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckLedgerEntryProtocol(t *testing.T) {
+	assert.NoError(t, checkLedgerEntryProtocol(xdr.LedgerEntryTypeAccount, 1))
+	assert.NoError(t, checkLedgerEntryProtocol(xdr.LedgerEntryTypeTtl, ProtocolSoroban))
+	assert.EqualError(t, checkLedgerEntryProtocol(xdr.LedgerEntryTypeTtl, 19), "unsupported_version (): LedgerEntryTypeTtl entries only exist from protocol 20, but the ledger is at protocol 19")
+	assert.Equal(t, ErrorCodeUnsupportedVersion, ErrorCodeOf(checkLedgerEntryProtocol(xdr.LedgerEntryTypeAccount, ProtocolUnifiedEvents)))
+}
+
+func TestCheckTransactionMeta(t *testing.T) {
+	assert.NoError(t, checkTransactionMeta(xdr.TransactionMeta{V: 2}, 19))
+	assert.NoError(t, checkTransactionMeta(xdr.TransactionMeta{V: 3}, MaxSupportedProtocol))
+	assert.ErrorContains(t, checkTransactionMeta(xdr.TransactionMeta{V: 3}, 19), "TransactionMeta V3 only exists from protocol 20")
+	assert.ErrorContains(t, checkTransactionMeta(xdr.TransactionMeta{V: 4}, MaxSupportedProtocol), "TransactionMeta V4 is not supported")
+	assert.ErrorContains(t, checkTransactionMeta(xdr.TransactionMeta{V: 3}, ProtocolUnifiedEvents), "protocol 23 ledgers carry unified events")
+}
+
+func TestCloseMetaProtocolVersion(t *testing.T) {
+	_, ok := closeMetaProtocolVersion(xdr.LedgerCloseMeta{})
+	assert.False(t, ok)
+
+	protocolVersion, ok := closeMetaProtocolVersion(xdr.LedgerCloseMeta{V: 1, V1: &xdr.LedgerCloseMetaV1{
+		LedgerHeader: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerVersion: 21}},
+	}})
+	assert.True(t, ok)
+	assert.Equal(t, uint32(21), protocolVersion)
+}
+
+func TestTransformTtlBeforeSoroban(t *testing.T) {
+	change := ingest.Change{
+		Type: xdr.LedgerEntryTypeTtl,
+		Post: &xdr.LedgerEntry{Data: xdr.LedgerEntryData{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.TtlEntry{}}},
+	}
+	header := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: 10, LedgerVersion: 19}}
+
+	_, err := TransformTtl(change, header)
+	var transformErr *TransformError
+	require.ErrorAs(t, err, &transformErr)
+	assert.Equal(t, ErrorCodeUnsupportedVersion, transformErr.Code)
+	assert.Equal(t, uint32(10), transformErr.LedgerSequence)
+	assert.Equal(t, "LedgerEntryTypeTtl", transformErr.LedgerEntryType)
+}
+
+func TestGetEventTopicsOfUnknownBody(t *testing.T) {
+	_, err := getEventTopics(xdr.ContractEventBody{V: 1})
+	assert.Equal(t, ErrorCodeUnsupportedVersion, ErrorCodeOf(err))
+}
@@ -57,7 +57,7 @@ type TransactionOutput struct {
 	FeeAccount                           string         `json:"fee_account,omitempty"`
 	FeeAccountMuxed                      string         `json:"fee_account_muxed,omitempty"`
 	InnerTransactionHash                 string         `json:"inner_transaction_hash,omitempty"`
-	NewMaxFee                            uint32         `json:"new_max_fee,omitempty"`
+	NewMaxFee                            int64          `json:"new_max_fee,omitempty"`
 	LedgerBounds                         string         `json:"ledger_bounds"`
 	MinAccountSequence                   null.Int       `json:"min_account_sequence"`
 	MinAccountSequenceAge                null.Int       `json:"min_account_sequence_age"`
@@ -76,6 +76,14 @@ type TransactionOutput struct {
 	TotalRefundableResourceFeeCharged    int64          `json:"refundable_resource_fee_charged"`
 	RentFeeCharged                       int64          `json:"rent_fee_charged"`
 	TxSigners                            []string       `json:"tx_signers"`
+	SorobanReturnValue                   null.String    `json:"soroban_return_value"`
+	ContractEventsCount                  uint32         `json:"contract_events_count"`
+	DiagnosticEventsCount                uint32         `json:"diagnostic_events_count"`
+	PagingToken                          string         `json:"paging_token"`
+	EnvelopeSize                         null.Int       `json:"envelope_size"`
+	ResultSize                           null.Int       `json:"result_size"`
+	MetaSize                             null.Int       `json:"meta_size"`
+	OperationChangesCount                null.Int       `json:"operation_changes_count"`
 }
 
 type LedgerTransactionOutput struct {
@@ -130,18 +138,20 @@ type AccountSignerOutput struct {
 
 // OperationOutput is a representation of an operation that aligns with the BigQuery table history_operations
 type OperationOutput struct {
-	SourceAccount        string                 `json:"source_account"`
-	SourceAccountMuxed   string                 `json:"source_account_muxed,omitempty"`
-	Type                 int32                  `json:"type"`
-	TypeString           string                 `json:"type_string"`
-	OperationDetails     map[string]interface{} `json:"details"` //Details is a JSON object that varies based on operation type
-	TransactionID        int64                  `json:"transaction_id"`
-	OperationID          int64                  `json:"id"`
-	ClosedAt             time.Time              `json:"closed_at"`
-	OperationResultCode  string                 `json:"operation_result_code"`
-	OperationTraceCode   string                 `json:"operation_trace_code"`
-	LedgerSequence       uint32                 `json:"ledger_sequence"`
-	OperationDetailsJSON map[string]interface{} `json:"details_json"`
+	SourceAccount         string                 `json:"source_account"`
+	SourceAccountMuxed    string                 `json:"source_account_muxed,omitempty"`
+	Type                  int32                  `json:"type"`
+	TypeString            string                 `json:"type_string"`
+	OperationDetails      map[string]interface{} `json:"details"` //Details is a JSON object that varies based on operation type
+	TransactionID         int64                  `json:"transaction_id"`
+	OperationID           int64                  `json:"id"`
+	ClosedAt              time.Time              `json:"closed_at"`
+	OperationResultCode   string                 `json:"operation_result_code"`
+	OperationTraceCode    string                 `json:"operation_trace_code"`
+	LedgerSequence        uint32                 `json:"ledger_sequence"`
+	OperationDetailsJSON  map[string]interface{} `json:"details_json"`
+	TransactionSuccessful bool                   `json:"transaction_successful"`
+	PagingToken           string                 `json:"paging_token"`
 }
 
 // ClaimableBalanceOutput is a representation of a claimable balances that aligns with the BigQuery table claimable_balances
@@ -161,12 +171,16 @@ type ClaimableBalanceOutput struct {
 	ClosedAt           time.Time   `json:"closed_at"`
 	LedgerSequence     uint32      `json:"ledger_sequence"`
 	BalanceIDStrkey    string      `json:"balance_id_strkey"`
+	ClaimableFrom      null.Time   `json:"claimable_from"`   // Earliest claimable_from of the claimants; null if any can claim from the start
+	ClaimableBefore    null.Time   `json:"claimable_before"` // Latest claimable_before of the claimants; null if any can claim with no end
 }
 
 // Claimants
 type Claimant struct {
-	Destination string             `json:"destination"`
-	Predicate   xdr.ClaimPredicate `json:"predicate"`
+	Destination     string                 `json:"destination"`
+	Predicate       map[string]interface{} `json:"predicate"`
+	ClaimableFrom   *time.Time             `json:"claimable_from,omitempty"`   // First close time at which the claimant can claim the balance, when the predicate bounds it; claimable balances only
+	ClaimableBefore *time.Time             `json:"claimable_before,omitempty"` // Close time from which the claimant can no longer claim the balance, when the predicate bounds it; claimable balances only
 }
 
 // Price represents the price of an asset as a fraction
@@ -225,10 +239,52 @@ type AssetOutput struct {
 	AssetIssuer    string    `json:"asset_issuer"`
 	AssetType      string    `json:"asset_type"`
 	AssetID        int64     `json:"asset_id"`
+	ContractID     string    `json:"contract_id"`
 	ClosedAt       time.Time `json:"closed_at"`
 	LedgerSequence uint32    `json:"ledger_sequence"`
 }
 
+// AssetDimensionOutput is a distinct asset seen in an export, along with the contract id of its Stellar Asset Contract
+// if one was seen deployed
+type AssetDimensionOutput struct {
+	AssetCode       string      `json:"asset_code"`
+	AssetIssuer     string      `json:"asset_issuer"`
+	AssetType       string      `json:"asset_type"`
+	AssetID         int64       `json:"asset_id"`
+	FirstSeenLedger uint32      `json:"first_seen_ledger"`
+	ContractID      null.String `json:"contract_id"`
+}
+
+// AccountSummaryOutput is the lifetime summary of an account: the ledgers it was first and last seen in, the account
+// that funded its creation, the payments it sent and received and its current flags
+type AccountSummaryOutput struct {
+	AccountID        string      `json:"account_id"`
+	FirstSeenLedger  uint32      `json:"first_seen_ledger"`
+	LastSeenLedger   uint32      `json:"last_seen_ledger"`
+	Funder           null.String `json:"funder"`
+	PaymentsSent     uint64      `json:"payments_sent"`
+	PaymentsReceived uint64      `json:"payments_received"`
+	Flags            uint32      `json:"flags"`
+	Deleted          bool        `json:"deleted"`
+}
+
+// MuxedAccountStatsOutput is the number and volume of the payments of an asset sent and received by a muxed account
+type MuxedAccountStatsOutput struct {
+	AccountID        string  `json:"account_id"`
+	MuxedID          uint64  `json:"muxed_id"`
+	AccountMuxed     string  `json:"account_muxed"`
+	AssetCode        string  `json:"asset_code"`
+	AssetIssuer      string  `json:"asset_issuer"`
+	AssetType        string  `json:"asset_type"`
+	AssetID          int64   `json:"asset_id"`
+	PaymentsSent     uint64  `json:"payments_sent"`
+	PaymentsReceived uint64  `json:"payments_received"`
+	AmountSent       float64 `json:"amount_sent"`
+	AmountReceived   float64 `json:"amount_received"`
+	FirstSeenLedger  uint32  `json:"first_seen_ledger"`
+	LastSeenLedger   uint32  `json:"last_seen_ledger"`
+}
+
 // TrustlineOutput is a representation of a trustline that aligns with the BigQuery table trust_lines
 type TrustlineOutput struct {
 	LedgerKey             string      `json:"ledger_key"`
@@ -252,6 +308,56 @@ type TrustlineOutput struct {
 	LiquidityPoolIDStrkey string      `json:"liquidity_pool_id_strkey"`
 }
 
+// PoolShareHolderOutput is a liquidity pool share trustline, giving the shares of a pool held by an account
+type PoolShareHolderOutput struct {
+	LedgerKey             string    `json:"ledger_key"`
+	AccountID             string    `json:"account_id"`
+	LiquidityPoolID       string    `json:"liquidity_pool_id"`
+	LiquidityPoolIDStrkey string    `json:"liquidity_pool_id_strkey"`
+	Balance               float64   `json:"balance"`
+	TrustlineLimit        int64     `json:"trust_line_limit"`
+	LastModifiedLedger    uint32    `json:"last_modified_ledger"`
+	LedgerEntryChange     uint32    `json:"ledger_entry_change"`
+	Deleted               bool      `json:"deleted"`
+	ClosedAt              time.Time `json:"closed_at"`
+	LedgerSequence        uint32    `json:"ledger_sequence"`
+}
+
+// HomeDomainOutput is a change of the home domain of an account, which anchors are discovered by
+type HomeDomainOutput struct {
+	AccountID      string    `json:"account_id"`
+	OldHomeDomain  string    `json:"old_home_domain"` // Empty when the account had no home domain or was created
+	NewHomeDomain  string    `json:"new_home_domain"` // Empty when the home domain was cleared
+	ClosedAt       time.Time `json:"closed_at"`
+	LedgerSequence uint32    `json:"ledger_sequence"`
+}
+
+// InflationDestinationOutput is a change of the inflation destination of an account
+type InflationDestinationOutput struct {
+	AccountID               string    `json:"account_id"`
+	OldInflationDestination string    `json:"old_inflation_destination"` // Empty when the account had none or was created
+	NewInflationDestination string    `json:"new_inflation_destination"` // Empty when the inflation destination was cleared
+	ClosedAt                time.Time `json:"closed_at"`
+	LedgerSequence          uint32    `json:"ledger_sequence"`
+}
+
+// AccountFlagsOutput is a change of the auth flags of an account, with the flags before and after it
+type AccountFlagsOutput struct {
+	AccountID              string    `json:"account_id"`
+	OldFlags               uint32    `json:"old_flags"`
+	NewFlags               uint32    `json:"new_flags"`
+	OldAuthRequired        bool      `json:"old_auth_required"`
+	NewAuthRequired        bool      `json:"new_auth_required"`
+	OldAuthRevocable       bool      `json:"old_auth_revocable"`
+	NewAuthRevocable       bool      `json:"new_auth_revocable"`
+	OldAuthImmutable       bool      `json:"old_auth_immutable"`
+	NewAuthImmutable       bool      `json:"new_auth_immutable"`
+	OldAuthClawbackEnabled bool      `json:"old_auth_clawback_enabled"`
+	NewAuthClawbackEnabled bool      `json:"new_auth_clawback_enabled"`
+	ClosedAt               time.Time `json:"closed_at"`
+	LedgerSequence         uint32    `json:"ledger_sequence"`
+}
+
 // OfferOutput is a representation of an offer that aligns with the BigQuery table offers
 type OfferOutput struct {
 	SellerID           string      `json:"seller_id"` // Account address of the seller
@@ -275,6 +381,36 @@ type OfferOutput struct {
 	Sponsor            null.String `json:"sponsor"`
 	ClosedAt           time.Time   `json:"closed_at"`
 	LedgerSequence     uint32      `json:"ledger_sequence"`
+	PriceDecimal       string      `json:"price_decimal"`
+	OfferIDStr         string      `json:"offer_id_str"` // offer_id as a decimal string, for consumers that cannot hold 64-bit integers
+}
+
+// OfferEventOutput is a representation of a single change to an offer, along with the reason it happened
+type OfferEventOutput struct {
+	OfferID            int64     `json:"offer_id"`
+	SellerID           string    `json:"seller_id"`
+	EventType          string    `json:"event_type"`
+	Cause              string    `json:"cause"`
+	SellingAssetType   string    `json:"selling_asset_type"`
+	SellingAssetCode   string    `json:"selling_asset_code"`
+	SellingAssetIssuer string    `json:"selling_asset_issuer"`
+	SellingAssetID     int64     `json:"selling_asset_id"`
+	BuyingAssetType    string    `json:"buying_asset_type"`
+	BuyingAssetCode    string    `json:"buying_asset_code"`
+	BuyingAssetIssuer  string    `json:"buying_asset_issuer"`
+	BuyingAssetID      int64     `json:"buying_asset_id"`
+	Amount             float64   `json:"amount"`
+	PriceN             int32     `json:"pricen"`
+	PriceD             int32     `json:"priced"`
+	Price              float64   `json:"price"`
+	OperationID        int64     `json:"operation_id"`
+	OperationType      string    `json:"operation_type"`
+	TransactionID      int64     `json:"transaction_id"`
+	TransactionHash    string    `json:"transaction_hash"`
+	ClosedAt           time.Time `json:"closed_at"`
+	LedgerSequence     uint32    `json:"ledger_sequence"`
+	PriceDecimal       string    `json:"price_decimal"`
+	OfferIDStr         string    `json:"offer_id_str"` // offer_id as a decimal string, for consumers that cannot hold 64-bit integers
 }
 
 // TradeOutput is a representation of a trade that aligns with the BigQuery table history_trades
@@ -304,6 +440,23 @@ type TradeOutput struct {
 	RoundingSlippage             null.Int    `json:"rounding_slippage"`
 	SellerIsExact                null.Bool   `json:"seller_is_exact"`
 	SellingLiquidityPoolIDStrkey null.String `json:"selling_liquidity_pool_id_strkey"`
+	PagingToken                  string      `json:"paging_token"`
+	PairID                       int64       `json:"pair_id"`
+	BaseIsSeller                 bool        `json:"base_is_seller"`
+	BaseAccountAddress           null.String `json:"base_account_address"`
+	BaseAssetID                  null.Int    `json:"base_asset_id"`
+	BaseAmount                   null.Float  `json:"base_amount"`
+	CounterAccountAddress        null.String `json:"counter_account_address"`
+	CounterAssetID               null.Int    `json:"counter_asset_id"`
+	CounterAmount                null.Float  `json:"counter_amount"`
+	BasePriceN                   null.Int    `json:"base_price_n"`
+	BasePriceD                   null.Int    `json:"base_price_d"`
+	PriceDecimal                 string      `json:"price_decimal"`
+	BasePriceDecimal             null.String `json:"base_price_decimal"`
+	TradeID                      string      `json:"trade_id"`
+	ClaimAtomType                string      `json:"claim_atom_type"`
+	SellingOfferIDStr            null.String `json:"selling_offer_id_str"` // selling_offer_id as a decimal string
+	BuyingOfferIDStr             null.String `json:"buying_offer_id_str"`  // buying_offer_id as a decimal string
 }
 
 // DimAccount is a representation of an account that aligns with the BigQuery table dim_accounts
@@ -354,16 +507,69 @@ type SponsorshipOutput struct {
 
 // EffectOutput is a representation of an operation that aligns with the BigQuery table history_effects
 type EffectOutput struct {
-	Address        string                 `json:"address"`
-	AddressMuxed   null.String            `json:"address_muxed,omitempty"`
-	OperationID    int64                  `json:"operation_id"`
-	Details        map[string]interface{} `json:"details"`
-	Type           int32                  `json:"type"`
-	TypeString     string                 `json:"type_string"`
-	LedgerClosed   time.Time              `json:"closed_at"`
-	LedgerSequence uint32                 `json:"ledger_sequence"`
-	EffectIndex    uint32                 `json:"index"`
-	EffectId       string                 `json:"id"`
+	Address               string                 `json:"address"`
+	AddressMuxed          null.String            `json:"address_muxed,omitempty"`
+	OperationID           int64                  `json:"operation_id"`
+	Details               map[string]interface{} `json:"details"`
+	Type                  int32                  `json:"type"`
+	TypeString            string                 `json:"type_string"`
+	LedgerClosed          time.Time              `json:"closed_at"`
+	LedgerSequence        uint32                 `json:"ledger_sequence"`
+	EffectIndex           uint32                 `json:"index"`
+	EffectId              string                 `json:"id"`
+	TransactionSuccessful bool                   `json:"transaction_successful"`
+	Category              string                 `json:"category"`
+	PagingToken           string                 `json:"paging_token"`
+}
+
+// EffectWideOutput is an effect with its most common details as nullable top-level columns. The details that are
+// not columns stay in the details object.
+type EffectWideOutput struct {
+	Address               string                 `json:"address"`
+	AddressMuxed          null.String            `json:"address_muxed,omitempty"`
+	OperationID           int64                  `json:"operation_id"`
+	Type                  int32                  `json:"type"`
+	TypeString            string                 `json:"type_string"`
+	LedgerClosed          time.Time              `json:"closed_at"`
+	LedgerSequence        uint32                 `json:"ledger_sequence"`
+	EffectIndex           uint32                 `json:"index"`
+	EffectId              string                 `json:"id"`
+	TransactionSuccessful bool                   `json:"transaction_successful"`
+	Category              string                 `json:"category"`
+	Amount                null.String            `json:"amount"`
+	StartingBalance       null.String            `json:"starting_balance"`
+	Asset                 null.String            `json:"asset"`
+	AssetType             null.String            `json:"asset_type"`
+	AssetCode             null.String            `json:"asset_code"`
+	AssetIssuer           null.String            `json:"asset_issuer"`
+	AssetID               null.Int               `json:"asset_id"`
+	AssetContractID       null.String            `json:"asset_contract_id"`
+	BalanceID             null.String            `json:"balance_id"`
+	LiquidityPoolID       null.String            `json:"liquidity_pool_id"`
+	OfferID               null.Int               `json:"offer_id"`
+	Seller                null.String            `json:"seller"`
+	SoldAmount            null.String            `json:"sold_amount"`
+	SoldAssetType         null.String            `json:"sold_asset_type"`
+	SoldAssetCode         null.String            `json:"sold_asset_code"`
+	SoldAssetIssuer       null.String            `json:"sold_asset_issuer"`
+	SoldAssetID           null.Int               `json:"sold_asset_id"`
+	BoughtAmount          null.String            `json:"bought_amount"`
+	BoughtAssetType       null.String            `json:"bought_asset_type"`
+	BoughtAssetCode       null.String            `json:"bought_asset_code"`
+	BoughtAssetIssuer     null.String            `json:"bought_asset_issuer"`
+	BoughtAssetID         null.Int               `json:"bought_asset_id"`
+	Trustor               null.String            `json:"trustor"`
+	PublicKey             null.String            `json:"public_key"`
+	Weight                null.Int               `json:"weight"`
+	Sponsor               null.String            `json:"sponsor"`
+	FormerSponsor         null.String            `json:"former_sponsor"`
+	NewSponsor            null.String            `json:"new_sponsor"`
+	Contract              null.String            `json:"contract"`
+	Details               map[string]interface{} `json:"details"`
+	PagingToken           string                 `json:"paging_token"`
+	TradeType             null.String            `json:"trade_type"`
+	LiquidityPoolFeeBp    null.Int               `json:"liquidity_pool_fee_bp"`
+	OfferIDStr            null.String            `json:"offer_id_str"` // offer_id as a decimal string
 }
 
 // EffectType is the numeric type for an effect
@@ -389,6 +595,7 @@ const (
 	EffectOfferRemoved                       EffectType = 31
 	EffectOfferUpdated                       EffectType = 32
 	EffectTrade                              EffectType = 33
+	EffectTradeZeroFill                      EffectType = 34
 	EffectDataCreated                        EffectType = 40
 	EffectDataRemoved                        EffectType = 41
 	EffectDataUpdated                        EffectType = 42
@@ -411,6 +618,9 @@ const (
 	EffectSignerSponsorshipCreated           EffectType = 72
 	EffectSignerSponsorshipUpdated           EffectType = 73
 	EffectSignerSponsorshipRemoved           EffectType = 74
+	EffectOfferSponsorshipCreated            EffectType = 75
+	EffectOfferSponsorshipUpdated            EffectType = 76
+	EffectOfferSponsorshipRemoved            EffectType = 77
 	EffectClaimableBalanceClawedBack         EffectType = 80
 	EffectLiquidityPoolDeposited             EffectType = 90
 	EffectLiquidityPoolWithdrew              EffectType = 91
@@ -445,6 +655,7 @@ var EffectTypeNames = map[EffectType]string{
 	EffectOfferRemoved:                       "offer_removed",
 	EffectOfferUpdated:                       "offer_updated",
 	EffectTrade:                              "trade",
+	EffectTradeZeroFill:                      "trade_zero_fill",
 	EffectDataCreated:                        "data_created",
 	EffectDataRemoved:                        "data_removed",
 	EffectDataUpdated:                        "data_updated",
@@ -467,6 +678,9 @@ var EffectTypeNames = map[EffectType]string{
 	EffectSignerSponsorshipCreated:           "signer_sponsorship_created",
 	EffectSignerSponsorshipUpdated:           "signer_sponsorship_updated",
 	EffectSignerSponsorshipRemoved:           "signer_sponsorship_removed",
+	EffectOfferSponsorshipCreated:            "offer_sponsorship_created",
+	EffectOfferSponsorshipUpdated:            "offer_sponsorship_updated",
+	EffectOfferSponsorshipRemoved:            "offer_sponsorship_removed",
 	EffectClaimableBalanceClawedBack:         "claimable_balance_clawed_back",
 	EffectLiquidityPoolDeposited:             "liquidity_pool_deposited",
 	EffectLiquidityPoolWithdrew:              "liquidity_pool_withdrew",
@@ -480,6 +694,67 @@ var EffectTypeNames = map[EffectType]string{
 	EffectRestoreFootprint:                   "restore_footprint",
 }
 
+// EffectCategories maps the effect types to the condensed category of the entries they are about, so that
+// effects can be partitioned or clustered by category
+var EffectCategories = map[EffectType]string{
+	EffectAccountCreated:                     "account",
+	EffectAccountRemoved:                     "account",
+	EffectAccountCredited:                    "account",
+	EffectAccountDebited:                     "account",
+	EffectAccountThresholdsUpdated:           "account",
+	EffectAccountHomeDomainUpdated:           "account",
+	EffectAccountFlagsUpdated:                "account",
+	EffectAccountInflationDestinationUpdated: "account",
+	EffectSignerCreated:                      "account",
+	EffectSignerRemoved:                      "account",
+	EffectSignerUpdated:                      "account",
+	EffectSequenceBumped:                     "account",
+	EffectTrustlineCreated:                   "trustline",
+	EffectTrustlineRemoved:                   "trustline",
+	EffectTrustlineUpdated:                   "trustline",
+	EffectTrustlineFlagsUpdated:              "trustline",
+	EffectOfferCreated:                       "offer",
+	EffectOfferRemoved:                       "offer",
+	EffectOfferUpdated:                       "offer",
+	EffectTrade:                              "trade",
+	EffectTradeZeroFill:                      "trade",
+	EffectDataCreated:                        "data",
+	EffectDataRemoved:                        "data",
+	EffectDataUpdated:                        "data",
+	EffectClaimableBalanceCreated:            "claimable_balance",
+	EffectClaimableBalanceClaimed:            "claimable_balance",
+	EffectClaimableBalanceClaimantCreated:    "claimable_balance",
+	EffectClaimableBalanceClawedBack:         "claimable_balance",
+	EffectAccountSponsorshipCreated:          "sponsorship",
+	EffectAccountSponsorshipUpdated:          "sponsorship",
+	EffectAccountSponsorshipRemoved:          "sponsorship",
+	EffectTrustlineSponsorshipCreated:        "sponsorship",
+	EffectTrustlineSponsorshipUpdated:        "sponsorship",
+	EffectTrustlineSponsorshipRemoved:        "sponsorship",
+	EffectDataSponsorshipCreated:             "sponsorship",
+	EffectDataSponsorshipUpdated:             "sponsorship",
+	EffectDataSponsorshipRemoved:             "sponsorship",
+	EffectClaimableBalanceSponsorshipCreated: "sponsorship",
+	EffectClaimableBalanceSponsorshipUpdated: "sponsorship",
+	EffectClaimableBalanceSponsorshipRemoved: "sponsorship",
+	EffectSignerSponsorshipCreated:           "sponsorship",
+	EffectSignerSponsorshipUpdated:           "sponsorship",
+	EffectSignerSponsorshipRemoved:           "sponsorship",
+	EffectOfferSponsorshipCreated:            "sponsorship",
+	EffectOfferSponsorshipUpdated:            "sponsorship",
+	EffectOfferSponsorshipRemoved:            "sponsorship",
+	EffectLiquidityPoolDeposited:             "liquidity_pool",
+	EffectLiquidityPoolWithdrew:              "liquidity_pool",
+	EffectLiquidityPoolTrade:                 "liquidity_pool",
+	EffectLiquidityPoolCreated:               "liquidity_pool",
+	EffectLiquidityPoolRemoved:               "liquidity_pool",
+	EffectLiquidityPoolRevoked:               "liquidity_pool",
+	EffectContractCredited:                   "contract",
+	EffectContractDebited:                    "contract",
+	EffectExtendFootprintTtl:                 "soroban",
+	EffectRestoreFootprint:                   "soroban",
+}
+
 // TradeEffectDetails is a struct of data from `effects.DetailsString`
 // when the effect type is trade
 type TradeEffectDetails struct {
@@ -487,6 +762,7 @@ type TradeEffectDetails struct {
 	SellerMuxed       string `json:"seller_muxed,omitempty"`
 	SellerMuxedID     uint64 `json:"seller_muxed_id,omitempty"`
 	OfferID           int64  `json:"offer_id"`
+	OfferIDStr        string `json:"offer_id_str"`
 	SoldAmount        string `json:"sold_amount"`
 	SoldAssetType     string `json:"sold_asset_type"`
 	SoldAssetCode     string `json:"sold_asset_code,omitempty"`
@@ -507,6 +783,22 @@ type TestTransaction struct {
 	Hash          string
 }
 
+// ContractBalanceOutput is a representation of a Stellar Asset Contract balance, held by an account or a contract
+type ContractBalanceOutput struct {
+	ContractId         string    `json:"contract_id"`
+	Holder             string    `json:"holder"`
+	HolderType         string    `json:"holder_type"`
+	Balance            string    `json:"balance"` // balance is a string because it is go type big.Int
+	Authorized         bool      `json:"authorized"`
+	Clawback           bool      `json:"clawback"`
+	LastModifiedLedger uint32    `json:"last_modified_ledger"`
+	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
+	Deleted            bool      `json:"deleted"`
+	ClosedAt           time.Time `json:"closed_at"`
+	LedgerSequence     uint32    `json:"ledger_sequence"`
+	LedgerKeyHash      string    `json:"ledger_key_hash"`
+}
+
 // ContractDataOutput is a representation of contract data that aligns with the Bigquery table soroban_contract_data
 type ContractDataOutput struct {
 	ContractId                string      `json:"contract_id"`
@@ -515,6 +807,7 @@ type ContractDataOutput struct {
 	ContractDataAssetCode     string      `json:"asset_code"`
 	ContractDataAssetIssuer   string      `json:"asset_issuer"`
 	ContractDataAssetType     string      `json:"asset_type"`
+	ContractDataAssetID       null.Int    `json:"asset_id"`
 	ContractDataBalanceHolder string      `json:"balance_holder"`
 	ContractDataBalance       string      `json:"balance"` // balance is a string because it is go type big.Int
 	LastModifiedLedger        uint32      `json:"last_modified_ledger"`
@@ -542,17 +835,36 @@ type ContractCodeOutput struct {
 	LedgerSequence     uint32    `json:"ledger_sequence"`
 	LedgerKeyHash      string    `json:"ledger_key_hash"`
 	//ContractCodeCode                string `json:"contract_code"`
-	NInstructions       uint32 `json:"n_instructions"`
-	NFunctions          uint32 `json:"n_functions"`
-	NGlobals            uint32 `json:"n_globals"`
-	NTableEntries       uint32 `json:"n_table_entries"`
-	NTypes              uint32 `json:"n_types"`
-	NDataSegments       uint32 `json:"n_data_segments"`
-	NElemSegments       uint32 `json:"n_elem_segments"`
-	NImports            uint32 `json:"n_imports"`
-	NExports            uint32 `json:"n_exports"`
-	NDataSegmentBytes   uint32 `json:"n_data_segment_bytes"`
-	LedgerKeyHashBase64 string `json:"ledger_key_hash_base_64"`
+	NInstructions          uint32             `json:"n_instructions"`
+	NFunctions             uint32             `json:"n_functions"`
+	NGlobals               uint32             `json:"n_globals"`
+	NTableEntries          uint32             `json:"n_table_entries"`
+	NTypes                 uint32             `json:"n_types"`
+	NDataSegments          uint32             `json:"n_data_segments"`
+	NElemSegments          uint32             `json:"n_elem_segments"`
+	NImports               uint32             `json:"n_imports"`
+	NExports               uint32             `json:"n_exports"`
+	NDataSegmentBytes      uint32             `json:"n_data_segment_bytes"`
+	LedgerKeyHashBase64    string             `json:"ledger_key_hash_base_64"`
+	EnvInterfaceProtocol   null.Int           `json:"env_interface_protocol"`
+	EnvInterfacePreRelease null.Int           `json:"env_interface_pre_release"`
+	RustVersion            null.String        `json:"rust_version"`
+	RustSdkVersion         null.String        `json:"rust_sdk_version"`
+	ContractMeta           map[string]string  `json:"contract_meta"`
+	ContractFunctions      []ContractFunction `json:"contract_functions"`
+}
+
+// ContractFunction is a function of the interface of a contract, from the contractspecv0 section of its Wasm
+type ContractFunction struct {
+	Name    string                  `json:"name"`
+	Inputs  []ContractFunctionInput `json:"inputs"`
+	Outputs []string                `json:"outputs"`
+}
+
+// ContractFunctionInput is an argument of a contract function, with its type as named by the Rust SDK
+type ContractFunctionInput struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
 }
 
 // ConfigSettingOutput is a representation of soroban config settings that aligns with the Bigquery table config_settings
@@ -607,9 +919,27 @@ type ConfigSettingOutput struct {
 	LedgerSequence                  uint32              `json:"ledger_sequence"`
 }
 
+// AccountDataOutput is a representation of an account data entry that aligns with the BigQuery table account_data
+type AccountDataOutput struct {
+	AccountID          string      `json:"account_id"`
+	DataName           string      `json:"data_name"`
+	DataValue          string      `json:"data_value"`         // base64 encoded
+	DataValueDecoded   null.String `json:"data_value_decoded"` // only set when the value is valid UTF-8
+	Sponsor            null.String `json:"sponsor"`
+	LastModifiedLedger uint32      `json:"last_modified_ledger"`
+	LedgerEntryChange  uint32      `json:"ledger_entry_change"`
+	Deleted            bool        `json:"deleted"`
+	ClosedAt           time.Time   `json:"closed_at"`
+	LedgerSequence     uint32      `json:"ledger_sequence"`
+	CreatedLedger      null.Int    `json:"created_ledger"` // only set on the change that creates the entry
+	RemovedLedger      null.Int    `json:"removed_ledger"` // only set on the change that removes the entry
+}
+
 // TtlOutput is a representation of soroban ttl that aligns with the Bigquery table ttls
 type TtlOutput struct {
 	KeyHash            string    `json:"key_hash"` // key_hash is contract_code_hash or contract_id
+	LedgerKey          string    `json:"ledger_key"`
+	LedgerKeyCanonical string    `json:"ledger_key_canonical"`
 	LiveUntilLedgerSeq uint32    `json:"live_until_ledger_seq"`
 	LastModifiedLedger uint32    `json:"last_modified_ledger"`
 	LedgerEntryChange  uint32    `json:"ledger_entry_change"`
@@ -618,6 +948,24 @@ type TtlOutput struct {
 	LedgerSequence     uint32    `json:"ledger_sequence"`
 }
 
+// ArchivalHistoryOutput is a representation of a change in the lifetime of a soroban ledger entry that aligns with the BigQuery table archival_history
+type ArchivalHistoryOutput struct {
+	KeyHash                    string      `json:"key_hash"`
+	EventType                  string      `json:"event_type"`
+	LedgerKeyType              string      `json:"ledger_key_type"`
+	LedgerKey                  string      `json:"ledger_key"`
+	LedgerKeyCanonical         string      `json:"ledger_key_canonical"`
+	ContractId                 string      `json:"contract_id"`
+	ContractCodeHash           string      `json:"contract_code_hash"`
+	ContractDurability         string      `json:"contract_durability"`
+	LiveUntilLedgerSeq         null.Int    `json:"live_until_ledger_seq"`
+	PreviousLiveUntilLedgerSeq null.Int    `json:"previous_live_until_ledger_seq"`
+	TransactionHash            null.String `json:"transaction_hash"`
+	OperationID                null.Int    `json:"operation_id"`
+	LedgerSequence             uint32      `json:"ledger_sequence"`
+	ClosedAt                   time.Time   `json:"closed_at"`
+}
+
 // ContractEventOutput is a representation of soroban contract events and diagnostic events
 type ContractEventOutput struct {
 	TransactionHash          string        `json:"transaction_hash"`
@@ -631,6 +979,11 @@ type ContractEventOutput struct {
 	TypeString               string        `json:"type_string"`
 	Topics                   []interface{} `json:"topics"`
 	TopicsDecoded            []interface{} `json:"topics_decoded"`
+	Topic0                   null.String   `json:"topic0"`
+	Topic1                   null.String   `json:"topic1"`
+	Topic2                   null.String   `json:"topic2"`
+	Topic3                   null.String   `json:"topic3"`
+	Topic0Symbol             null.String   `json:"topic0_symbol"`
 	Data                     interface{}   `json:"data"`
 	DataDecoded              interface{}   `json:"data_decoded"`
 	ContractEventXDR         string        `json:"contract_event_xdr"`
@@ -647,6 +1000,7 @@ type TokenTransferOutput struct {
 	AssetType       string      `json:"asset_type"`
 	AssetCode       null.String `json:"asset_code"`
 	AssetIssuer     null.String `json:"asset_issuer"`
+	AssetID         null.Int    `json:"asset_id"`
 	Amount          float64     `json:"amount"`
 	AmountRaw       string      `json:"amount_raw"`
 	ContractID      string      `json:"contract_id"`
@@ -655,3 +1009,32 @@ type TokenTransferOutput struct {
 	ToMuxed         null.String `json:"to_muxed"`
 	ToMuxedID       null.String `json:"to_muxed_id"`
 }
+
+// FeeOutput is a representation of the fee events of a transaction that aligns with the BigQuery table fees
+type FeeOutput struct {
+	TransactionHash     string    `json:"transaction_hash"`
+	TransactionID       int64     `json:"transaction_id"`
+	LedgerSequence      uint32    `json:"ledger_sequence"`
+	FeeAccount          string    `json:"fee_account"`
+	MaxFee              int64     `json:"max_fee"`
+	InitialFeeCharged   int64     `json:"initial_fee_charged"`
+	FeeRefund           int64     `json:"fee_refund"`
+	FeeCharged          int64     `json:"fee_charged"`
+	InclusionFeeCharged int64     `json:"inclusion_fee_charged"`
+	BaseFee             uint32    `json:"base_fee"`
+	SurgePriced         bool      `json:"surge_priced"`
+	Soroban             bool      `json:"soroban"`
+	Successful          bool      `json:"successful"`
+	ClosedAt            time.Time `json:"closed_at"`
+}
+
+// NetworkUpgradeOutput is a representation of a network upgrade applied at the close of a ledger that aligns with the BigQuery table network_upgrades
+type NetworkUpgradeOutput struct {
+	LedgerSequence  uint32      `json:"ledger_sequence"`
+	UpgradeIndex    uint32      `json:"upgrade_index"`
+	UpgradeType     string      `json:"upgrade_type"`
+	ConfigSettingId null.Int    `json:"config_setting_id"`
+	PreviousValue   null.String `json:"previous_value"`
+	NewValue        string      `json:"new_value"`
+	ClosedAt        time.Time   `json:"closed_at"`
+}
@@ -0,0 +1,99 @@
+package transform
+
+import (
+	_ "embed"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//go:embed schema.go
+var schemaSource string
+
+// StructDocs holds the comments of a struct in schema.go, which are used as the descriptions of generated schemas
+type StructDocs struct {
+	Doc    string
+	Fields map[string]string // keyed by the json name of the field
+}
+
+var (
+	schemaDocsOnce sync.Once
+	schemaDocs     map[string]StructDocs
+)
+
+// SchemaDocs returns the comments of the struct of the output, or empty docs if it is not declared in schema.go
+func SchemaDocs(output interface{}) StructDocs {
+	schemaDocsOnce.Do(func() {
+		schemaDocs = parseSchemaDocs(schemaSource)
+	})
+
+	outputType := reflect.TypeOf(output)
+	for outputType.Kind() == reflect.Ptr {
+		outputType = outputType.Elem()
+	}
+
+	docs, ok := schemaDocs[outputType.Name()]
+	if !ok {
+		return StructDocs{Fields: map[string]string{}}
+	}
+	return docs
+}
+
+func parseSchemaDocs(source string) map[string]StructDocs {
+	docs := map[string]StructDocs{}
+	file, err := parser.ParseFile(token.NewFileSet(), "schema.go", source, parser.ParseComments)
+	if err != nil {
+		return docs
+	}
+
+	for _, decl := range file.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			structType, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			structDocs := StructDocs{
+				Doc:    commentText(genDecl.Doc),
+				Fields: map[string]string{},
+			}
+			for _, field := range structType.Fields.List {
+				if field.Tag == nil {
+					continue
+				}
+				tag, err := strconv.Unquote(field.Tag.Value)
+				if err != nil {
+					continue
+				}
+				name := strings.Split(reflect.StructTag(tag).Get("json"), ",")[0]
+				text := commentText(field.Doc)
+				if text == "" {
+					text = commentText(field.Comment)
+				}
+				if name != "" && text != "" {
+					structDocs.Fields[name] = text
+				}
+			}
+			docs[typeSpec.Name.Name] = structDocs
+		}
+	}
+
+	return docs
+}
+
+func commentText(group *ast.CommentGroup) string {
+	if group == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(group.Text()), " ")
+}
@@ -0,0 +1,37 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaDocs(t *testing.T) {
+	docs := SchemaDocs(LedgerOutput{})
+	assert.Equal(t, "LedgerOutput is a representation of a ledger that aligns with the BigQuery table history_ledgers", docs.Doc)
+	assert.Equal(t, "sequence number of the ledger", docs.Fields["sequence"])
+	assert.NotContains(t, docs.Fields, "ledger_hash")
+
+	assert.Equal(t, docs, SchemaDocs(&LedgerOutput{}))
+	assert.Equal(t, StructDocs{Fields: map[string]string{}}, SchemaDocs(struct{}{}))
+}
+
+func TestParseSchemaDocs(t *testing.T) {
+	source := `package transform
+
+// ExampleOutput is an example
+type ExampleOutput struct {
+	// ID identifies the row
+	ID       int64  ` + "`json:\"id\"`" + `
+	Name     string ` + "`json:\"name,omitempty\"`" + ` // Name of the row
+	Untagged string
+}
+`
+	expected := map[string]StructDocs{
+		"ExampleOutput": {
+			Doc:    "ExampleOutput is an example",
+			Fields: map[string]string{"id": "ID identifies the row", "name": "Name of the row"},
+		},
+	}
+	assert.Equal(t, expected, parseSchemaDocs(source))
+}
@@ -0,0 +1,139 @@
+package transform
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/guregu/null/zero"
+)
+
+// SchemaField is a column of an output table. Types use the BigQuery names: STRING, INTEGER, FLOAT, BOOLEAN,
+// TIMESTAMP, BYTES, JSON and RECORD.
+type SchemaField struct {
+	Name        string
+	Type        string
+	Nullable    bool
+	Repeated    bool
+	Description string
+	RecordName  string
+	Fields      []SchemaField
+}
+
+// SchemaFields describes the columns of an output struct, as it is serialized to json
+func SchemaFields(output interface{}) []SchemaField {
+	return structFields(reflect.TypeOf(output), map[reflect.Type]bool{})
+}
+
+func structFields(structType reflect.Type, visiting map[reflect.Type]bool) []SchemaField {
+	visiting[structType] = true
+	defer delete(visiting, structType)
+
+	docs := SchemaDocs(reflect.New(structType).Interface())
+	fields := []SchemaField{}
+	for i := 0; i < structType.NumField(); i++ {
+		structField := structType.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = structField.Name
+		}
+
+		field := SchemaField{Name: name, Description: docs.Fields[name]}
+		fieldType(structField.Type, &field, visiting)
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+	nullableTypes  = map[reflect.Type]string{
+		reflect.TypeOf(null.String{}): "STRING",
+		reflect.TypeOf(null.Int{}):    "INTEGER",
+		reflect.TypeOf(null.Float{}):  "FLOAT",
+		reflect.TypeOf(null.Bool{}):   "BOOLEAN",
+		reflect.TypeOf(null.Time{}):   "TIMESTAMP",
+		reflect.TypeOf(zero.String{}): "STRING",
+		reflect.TypeOf(zero.Int{}):    "INTEGER",
+		reflect.TypeOf(zero.Float{}):  "FLOAT",
+		reflect.TypeOf(zero.Bool{}):   "BOOLEAN",
+		reflect.TypeOf(zero.Time{}):   "TIMESTAMP",
+	}
+	transformPkgPath = reflect.TypeOf(LedgerOutput{}).PkgPath()
+)
+
+// fieldType sets the type of the field from the go type it is serialized from. Structs of the transform package
+// become records; other structs, such as xdr types, maps and interfaces are serialized as json.
+func fieldType(goType reflect.Type, field *SchemaField, visiting map[reflect.Type]bool) {
+	if nullableType, ok := nullableTypes[goType]; ok {
+		field.Type = nullableType
+		field.Nullable = true
+		return
+	}
+
+	switch {
+	case goType == timeType:
+		field.Type = "TIMESTAMP"
+		return
+	case goType == rawMessageType:
+		field.Type = "JSON"
+		field.Nullable = true
+		return
+	}
+
+	switch goType.Kind() {
+	case reflect.Ptr:
+		fieldType(goType.Elem(), field, visiting)
+		field.Nullable = true
+	case reflect.Interface, reflect.Map:
+		field.Type = "JSON"
+		field.Nullable = true
+	case reflect.Slice, reflect.Array:
+		if goType.Elem().Kind() == reflect.Uint8 {
+			field.Type = "BYTES"
+			return
+		}
+		if field.Repeated {
+			// Nested lists have no column type, so they are kept as json
+			field.Type = "JSON"
+			field.Repeated = false
+			field.Fields = nil
+			field.Nullable = true
+			return
+		}
+		field.Repeated = true
+		fieldType(goType.Elem(), field, visiting)
+	case reflect.Struct:
+		if goType.PkgPath() != transformPkgPath || visiting[goType] {
+			field.Type = "JSON"
+			field.Nullable = true
+			return
+		}
+		field.Type = "RECORD"
+		field.RecordName = goType.Name()
+		field.Fields = structFields(goType, visiting)
+	case reflect.String:
+		field.Type = "STRING"
+	case reflect.Bool:
+		field.Type = "BOOLEAN"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		field.Type = "INTEGER"
+	case reflect.Float32, reflect.Float64:
+		field.Type = "FLOAT"
+	default:
+		field.Type = "JSON"
+		field.Nullable = true
+	}
+}
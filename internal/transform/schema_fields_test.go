@@ -0,0 +1,32 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaFields(t *testing.T) {
+	fields := SchemaFields(ClaimableBalanceOutput{})
+
+	fieldsByName := map[string]SchemaField{}
+	for _, field := range fields {
+		fieldsByName[field.Name] = field
+	}
+
+	assert.Equal(t, SchemaField{Name: "balance_id", Type: "STRING"}, fieldsByName["balance_id"])
+	assert.Equal(t, SchemaField{Name: "sponsor", Type: "STRING", Nullable: true}, fieldsByName["sponsor"])
+	assert.Equal(t, SchemaField{Name: "closed_at", Type: "TIMESTAMP"}, fieldsByName["closed_at"])
+	assert.Equal(t, SchemaField{
+		Name:       "claimants",
+		Type:       "RECORD",
+		Repeated:   true,
+		RecordName: "Claimant",
+		Fields: []SchemaField{
+			{Name: "destination", Type: "STRING"},
+			{Name: "predicate", Type: "JSON", Nullable: true},
+			{Name: "claimable_from", Type: "TIMESTAMP", Nullable: true, Description: "First close time at which the claimant can claim the balance, when the predicate bounds it; claimable balances only"},
+			{Name: "claimable_before", Type: "TIMESTAMP", Nullable: true, Description: "Close time from which the claimant can no longer claim the balance, when the predicate bounds it; claimable balances only"},
+		},
+	}, fieldsByName["claimants"])
+}
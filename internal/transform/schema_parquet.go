@@ -66,6 +66,14 @@ type TransactionOutputParquet struct {
 	TotalNonRefundableResourceFeeCharged int64    `parquet:"name=non_refundable_resource_fee_charged, type=INT64"`
 	TotalRefundableResourceFeeCharged    int64    `parquet:"name=refundable_resource_fee_charged, type=INT64"`
 	RentFeeCharged                       int64    `parquet:"name=rent_fee_charged, type=INT64"`
+	SorobanReturnValue                   string   `parquet:"name=soroban_return_value, type=BYTE_ARRAY, convertedtype=UTF8"`
+	ContractEventsCount                  int64    `parquet:"name=contract_events_count, type=INT64, convertedtype=UINT_64"`
+	DiagnosticEventsCount                int64    `parquet:"name=diagnostic_events_count, type=INT64, convertedtype=UINT_64"`
+	PagingToken                          string   `parquet:"name=paging_token, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EnvelopeSize                         int64    `parquet:"name=envelope_size, type=INT64"`
+	ResultSize                           int64    `parquet:"name=result_size, type=INT64"`
+	MetaSize                             int64    `parquet:"name=meta_size, type=INT64"`
+	OperationChangesCount                int64    `parquet:"name=operation_changes_count, type=INT64"`
 }
 
 // AccountOutputParquet is a representation of an account that aligns with the BigQuery table accounts
@@ -110,17 +118,19 @@ type AccountSignerOutputParquet struct {
 
 // OperationOutputParquet is a representation of an operation that aligns with the BigQuery table history_operations
 type OperationOutputParquet struct {
-	SourceAccount       string `parquet:"name=source_account, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	SourceAccountMuxed  string `parquet:"name=source_account_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	Type                int32  `parquet:"name=type, type=INT32"`
-	TypeString          string `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	OperationDetails    string `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	TransactionID       int64  `parquet:"name=transaction_id, type=INT64"`
-	OperationID         int64  `parquet:"name=id, type=INT64"`
-	ClosedAt            int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
-	OperationResultCode string `parquet:"name=operation_result_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	OperationTraceCode  string `parquet:"name=operation_trace_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	LedgerSequence      int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=INT64, convertedtype=UINT_64"`
+	SourceAccount         string `parquet:"name=source_account, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SourceAccountMuxed    string `parquet:"name=source_account_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Type                  int32  `parquet:"name=type, type=INT32"`
+	TypeString            string `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OperationDetails      string `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionID         int64  `parquet:"name=transaction_id, type=INT64"`
+	OperationID           int64  `parquet:"name=id, type=INT64"`
+	ClosedAt              int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	OperationResultCode   string `parquet:"name=operation_result_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OperationTraceCode    string `parquet:"name=operation_trace_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerSequence        int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=INT64, convertedtype=UINT_64"`
+	TransactionSuccessful bool   `parquet:"name=transaction_successful, type=BOOLEAN"`
+	PagingToken           string `parquet:"name=paging_token, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 }
 
 //// Skipping ClaimableBalanceOutputParquet because it is not needed in the current scope of work
@@ -159,10 +169,50 @@ type AssetOutputParquet struct {
 	AssetIssuer    string `parquet:"name=asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	AssetType      string `parquet:"name=asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	AssetID        int64  `parquet:"name=asset_id, type=INT64"`
+	ContractID     string `parquet:"name=contract_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ClosedAt       int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
 	LedgerSequence int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
 }
 
+// AssetDimensionOutputParquet is a representation of a distinct asset that aligns with the assets dimension table
+type AssetDimensionOutputParquet struct {
+	AssetCode       string `parquet:"name=asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetIssuer     string `parquet:"name=asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetType       string `parquet:"name=asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetID         int64  `parquet:"name=asset_id, type=INT64"`
+	FirstSeenLedger int64  `parquet:"name=first_seen_ledger, type=INT64, convertedtype=UINT_64"`
+	ContractID      string `parquet:"name=contract_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// AccountSummaryOutputParquet is a representation of the lifetime summary of an account
+type AccountSummaryOutputParquet struct {
+	AccountID        string `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FirstSeenLedger  int64  `parquet:"name=first_seen_ledger, type=INT64, convertedtype=UINT_64"`
+	LastSeenLedger   int64  `parquet:"name=last_seen_ledger, type=INT64, convertedtype=UINT_64"`
+	Funder           string `parquet:"name=funder, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PaymentsSent     int64  `parquet:"name=payments_sent, type=INT64, convertedtype=UINT_64"`
+	PaymentsReceived int64  `parquet:"name=payments_received, type=INT64, convertedtype=UINT_64"`
+	Flags            int64  `parquet:"name=flags, type=INT64, convertedtype=UINT_64"`
+	Deleted          bool   `parquet:"name=deleted, type=BOOLEAN"`
+}
+
+// MuxedAccountStatsOutputParquet is a representation of the payments of an asset of a muxed account
+type MuxedAccountStatsOutputParquet struct {
+	AccountID        string  `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	MuxedID          int64   `parquet:"name=muxed_id, type=INT64, convertedtype=UINT_64"`
+	AccountMuxed     string  `parquet:"name=account_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetCode        string  `parquet:"name=asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetIssuer      string  `parquet:"name=asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetType        string  `parquet:"name=asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetID          int64   `parquet:"name=asset_id, type=INT64"`
+	PaymentsSent     int64   `parquet:"name=payments_sent, type=INT64, convertedtype=UINT_64"`
+	PaymentsReceived int64   `parquet:"name=payments_received, type=INT64, convertedtype=UINT_64"`
+	AmountSent       float64 `parquet:"name=amount_sent, type=DOUBLE"`
+	AmountReceived   float64 `parquet:"name=amount_received, type=DOUBLE"`
+	FirstSeenLedger  int64   `parquet:"name=first_seen_ledger, type=INT64, convertedtype=UINT_64"`
+	LastSeenLedger   int64   `parquet:"name=last_seen_ledger, type=INT64, convertedtype=UINT_64"`
+}
+
 // TrustlineOutputParquet is a representation of a trustline that aligns with the BigQuery table trust_lines
 type TrustlineOutputParquet struct {
 	LedgerKey          string  `parquet:"name=ledger_key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
@@ -185,6 +235,60 @@ type TrustlineOutputParquet struct {
 	LedgerSequence     int64   `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
 }
 
+// PoolShareHolderOutputParquet is a representation of a liquidity pool share trustline that aligns with the
+// pool_share_holders table
+type PoolShareHolderOutputParquet struct {
+	LedgerKey             string  `parquet:"name=ledger_key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AccountID             string  `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LiquidityPoolID       string  `parquet:"name=liquidity_pool_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LiquidityPoolIDStrkey string  `parquet:"name=liquidity_pool_id_strkey, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Balance               float64 `parquet:"name=balance, type=DOUBLE"`
+	TrustlineLimit        int64   `parquet:"name=trust_line_limit, type=INT64"`
+	LastModifiedLedger    int64   `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
+	LedgerEntryChange     int64   `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
+	Deleted               bool    `parquet:"name=deleted, type=BOOLEAN"`
+	ClosedAt              int64   `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence        int64   `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+}
+
+// HomeDomainOutputParquet is a representation of a change of the home domain of an account that aligns with the
+// home_domain_history table
+type HomeDomainOutputParquet struct {
+	AccountID      string `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OldHomeDomain  string `parquet:"name=old_home_domain, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	NewHomeDomain  string `parquet:"name=new_home_domain, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClosedAt       int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+}
+
+// InflationDestinationOutputParquet is a representation of a change of the inflation destination of an account that
+// aligns with the inflation_destination_history table
+type InflationDestinationOutputParquet struct {
+	AccountID               string `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OldInflationDestination string `parquet:"name=old_inflation_destination, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	NewInflationDestination string `parquet:"name=new_inflation_destination, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClosedAt                int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence          int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+}
+
+// AccountFlagsOutputParquet is a representation of a change of the auth flags of an account that aligns with the
+// account_flags_history table
+type AccountFlagsOutputParquet struct {
+	AccountID              string `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OldFlags               int64  `parquet:"name=old_flags, type=INT64, convertedtype=UINT_64"`
+	NewFlags               int64  `parquet:"name=new_flags, type=INT64, convertedtype=UINT_64"`
+	OldAuthRequired        bool   `parquet:"name=old_auth_required, type=BOOLEAN"`
+	NewAuthRequired        bool   `parquet:"name=new_auth_required, type=BOOLEAN"`
+	OldAuthRevocable       bool   `parquet:"name=old_auth_revocable, type=BOOLEAN"`
+	NewAuthRevocable       bool   `parquet:"name=new_auth_revocable, type=BOOLEAN"`
+	OldAuthImmutable       bool   `parquet:"name=old_auth_immutable, type=BOOLEAN"`
+	NewAuthImmutable       bool   `parquet:"name=new_auth_immutable, type=BOOLEAN"`
+	OldAuthClawbackEnabled bool   `parquet:"name=old_auth_clawback_enabled, type=BOOLEAN"`
+	NewAuthClawbackEnabled bool   `parquet:"name=new_auth_clawback_enabled, type=BOOLEAN"`
+	ClosedAt               int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence         int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+}
+
 // OfferOutputParquet is a representation of an offer that aligns with the BigQuery table offers
 type OfferOutputParquet struct {
 	SellerID           string  `parquet:"name=seller_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
@@ -208,6 +312,36 @@ type OfferOutputParquet struct {
 	Sponsor            string  `parquet:"name=sponsor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ClosedAt           int64   `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
 	LedgerSequence     int64   `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	PriceDecimal       string  `parquet:"name=price_decimal, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OfferIDStr         string  `parquet:"name=offer_id_str, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// OfferEventOutputParquet is a representation of an offer lifecycle event that aligns with the BigQuery table offer_events
+type OfferEventOutputParquet struct {
+	OfferID            int64   `parquet:"name=offer_id, type=INT64"`
+	SellerID           string  `parquet:"name=seller_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventType          string  `parquet:"name=event_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Cause              string  `parquet:"name=cause, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SellingAssetType   string  `parquet:"name=selling_asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SellingAssetCode   string  `parquet:"name=selling_asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SellingAssetIssuer string  `parquet:"name=selling_asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SellingAssetID     int64   `parquet:"name=selling_asset_id, type=INT64"`
+	BuyingAssetType    string  `parquet:"name=buying_asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BuyingAssetCode    string  `parquet:"name=buying_asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BuyingAssetIssuer  string  `parquet:"name=buying_asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BuyingAssetID      int64   `parquet:"name=buying_asset_id, type=INT64"`
+	Amount             float64 `parquet:"name=amount, type=DOUBLE"`
+	PriceN             int32   `parquet:"name=pricen, type=INT32"`
+	PriceD             int32   `parquet:"name=priced, type=INT32"`
+	Price              float64 `parquet:"name=price, type=DOUBLE"`
+	OperationID        int64   `parquet:"name=operation_id, type=INT64"`
+	OperationType      string  `parquet:"name=operation_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionID      int64   `parquet:"name=transaction_id, type=INT64"`
+	TransactionHash    string  `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClosedAt           int64   `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence     int64   `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	PriceDecimal       string  `parquet:"name=price_decimal, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OfferIDStr         string  `parquet:"name=offer_id_str, type=BYTE_ARRAY, convertedtype=UTF8"`
 }
 
 // TradeOutputParquet is a representation of a trade that aligns with the BigQuery table history_trades
@@ -236,20 +370,107 @@ type TradeOutputParquet struct {
 	TradeType              int32   `parquet:"name=trade_type, type=INT32"`
 	RoundingSlippage       int64   `parquet:"name=rounding_slippage, type=INT64"`
 	SellerIsExact          bool    `parquet:"name=seller_is_exact, type=BOOLEAN"`
+	PagingToken            string  `parquet:"name=paging_token, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PairID                 int64   `parquet:"name=pair_id, type=INT64"`
+	BaseIsSeller           bool    `parquet:"name=base_is_seller, type=BOOLEAN"`
+	BaseAccountAddress     string  `parquet:"name=base_account_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BaseAssetID            int64   `parquet:"name=base_asset_id, type=INT64"`
+	BaseAmount             float64 `parquet:"name=base_amount, type=DOUBLE"`
+	CounterAccountAddress  string  `parquet:"name=counter_account_address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	CounterAssetID         int64   `parquet:"name=counter_asset_id, type=INT64"`
+	CounterAmount          float64 `parquet:"name=counter_amount, type=DOUBLE"`
+	BasePriceN             int64   `parquet:"name=base_price_n, type=INT64"`
+	BasePriceD             int64   `parquet:"name=base_price_d, type=INT64"`
+	PriceDecimal           string  `parquet:"name=price_decimal, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BasePriceDecimal       string  `parquet:"name=base_price_decimal, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TradeID                string  `parquet:"name=trade_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClaimAtomType          string  `parquet:"name=claim_atom_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SellingOfferIDStr      string  `parquet:"name=selling_offer_id_str, type=BYTE_ARRAY, convertedtype=UTF8"`
+	BuyingOfferIDStr       string  `parquet:"name=buying_offer_id_str, type=BYTE_ARRAY, convertedtype=UTF8"`
 }
 
 // EffectOutputParquet is a representation of an operation that aligns with the BigQuery table history_effects
 type EffectOutputParquet struct {
-	Address        string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	AddressMuxed   string `parquet:"name=address_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	OperationID    int64  `parquet:"name=operation_id, type=INT64"`
-	Details        string `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	Type           int32  `parquet:"name=type, type=INT32"`
-	TypeString     string `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	LedgerClosed   int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
-	LedgerSequence int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
-	EffectIndex    int64  `parquet:"name=index, type=INT64, convertedtype=UINT_64"`
-	EffectId       string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Address               string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AddressMuxed          string `parquet:"name=address_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OperationID           int64  `parquet:"name=operation_id, type=INT64"`
+	Details               string `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Type                  int32  `parquet:"name=type, type=INT32"`
+	TypeString            string `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerClosed          int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence        int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	EffectIndex           int64  `parquet:"name=index, type=INT64, convertedtype=UINT_64"`
+	EffectId              string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionSuccessful bool   `parquet:"name=transaction_successful, type=BOOLEAN"`
+	Category              string `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PagingToken           string `parquet:"name=paging_token, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+}
+
+// EffectWideOutputParquet is a representation of an effect with its common details as columns that aligns with the
+// wide effects table
+type EffectWideOutputParquet struct {
+	Address               string `parquet:"name=address, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AddressMuxed          string `parquet:"name=address_muxed, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OperationID           int64  `parquet:"name=operation_id, type=INT64"`
+	Type                  int32  `parquet:"name=type, type=INT32"`
+	TypeString            string `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerClosed          int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence        int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	EffectIndex           int64  `parquet:"name=index, type=INT64, convertedtype=UINT_64"`
+	EffectId              string `parquet:"name=id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionSuccessful bool   `parquet:"name=transaction_successful, type=BOOLEAN"`
+	Category              string `parquet:"name=category, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Amount                string `parquet:"name=amount, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	StartingBalance       string `parquet:"name=starting_balance, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Asset                 string `parquet:"name=asset, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetType             string `parquet:"name=asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetCode             string `parquet:"name=asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetIssuer           string `parquet:"name=asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	AssetID               int64  `parquet:"name=asset_id, type=INT64"`
+	AssetContractID       string `parquet:"name=asset_contract_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BalanceID             string `parquet:"name=balance_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LiquidityPoolID       string `parquet:"name=liquidity_pool_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OfferID               int64  `parquet:"name=offer_id, type=INT64"`
+	Seller                string `parquet:"name=seller, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SoldAmount            string `parquet:"name=sold_amount, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SoldAssetType         string `parquet:"name=sold_asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SoldAssetCode         string `parquet:"name=sold_asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SoldAssetIssuer       string `parquet:"name=sold_asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	SoldAssetID           int64  `parquet:"name=sold_asset_id, type=INT64"`
+	BoughtAmount          string `parquet:"name=bought_amount, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BoughtAssetType       string `parquet:"name=bought_asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BoughtAssetCode       string `parquet:"name=bought_asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BoughtAssetIssuer     string `parquet:"name=bought_asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	BoughtAssetID         int64  `parquet:"name=bought_asset_id, type=INT64"`
+	Trustor               string `parquet:"name=trustor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PublicKey             string `parquet:"name=public_key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Weight                int64  `parquet:"name=weight, type=INT64"`
+	Sponsor               string `parquet:"name=sponsor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	FormerSponsor         string `parquet:"name=former_sponsor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	NewSponsor            string `parquet:"name=new_sponsor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Contract              string `parquet:"name=contract, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Details               string `parquet:"name=details, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	PagingToken           string `parquet:"name=paging_token, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TradeType             string `parquet:"name=trade_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LiquidityPoolFeeBp    int64  `parquet:"name=liquidity_pool_fee_bp, type=INT64"`
+	OfferIDStr            string `parquet:"name=offer_id_str, type=BYTE_ARRAY, convertedtype=UTF8"`
+}
+
+// ContractBalanceOutputParquet is a representation of a Stellar Asset Contract balance that aligns with the
+// contract_balances table
+type ContractBalanceOutputParquet struct {
+	ContractId         string `parquet:"name=contract_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Holder             string `parquet:"name=holder, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	HolderType         string `parquet:"name=holder_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Balance            string `parquet:"name=balance, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Authorized         bool   `parquet:"name=authorized, type=BOOLEAN"`
+	Clawback           bool   `parquet:"name=clawback, type=BOOLEAN"`
+	LastModifiedLedger int64  `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
+	LedgerEntryChange  int64  `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
+	Deleted            bool   `parquet:"name=deleted, type=BOOLEAN"`
+	ClosedAt           int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence     int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	LedgerKeyHash      string `parquet:"name=ledger_key_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 }
 
 // ContractDataOutputParquet is a representation of contract data that aligns with the Bigquery table soroban_contract_data
@@ -260,6 +481,7 @@ type ContractDataOutputParquet struct {
 	ContractDataAssetCode     string      `parquet:"name=asset_code, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ContractDataAssetIssuer   string      `parquet:"name=asset_issuer, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ContractDataAssetType     string      `parquet:"name=asset_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractDataAssetID       int64       `parquet:"name=asset_id, type=INT64"`
 	ContractDataBalanceHolder string      `parquet:"name=balance_holder, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ContractDataBalance       string      `parquet:"name=balance, type=BYTE_ARRAY, convertedtype=UTF8"`
 	LastModifiedLedger        int64       `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
@@ -277,24 +499,30 @@ type ContractDataOutputParquet struct {
 
 // ContractCodeOutputParquet is a representation of contract code that aligns with the Bigquery table soroban_contract_code
 type ContractCodeOutputParquet struct {
-	ContractCodeHash   string `parquet:"name=contract_code_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	ContractCodeExtV   int32  `parquet:"name=contract_code_ext_v, type=INT32"`
-	LastModifiedLedger int64  `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
-	LedgerEntryChange  int64  `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
-	Deleted            bool   `parquet:"name=deleted, type=BOOLEAN"`
-	ClosedAt           int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
-	LedgerSequence     int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
-	LedgerKeyHash      string `parquet:"name=ledger_key_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
-	NInstructions      int64  `parquet:"name=n_instructions, type=INT64, convertedtype=UINT_64"`
-	NFunctions         int64  `parquet:"name=n_functions, type=INT64, convertedtype=UINT_64"`
-	NGlobals           int64  `parquet:"name=n_globals, type=INT64, convertedtype=UINT_64"`
-	NTableEntries      int64  `parquet:"name=n_table_entries, type=INT64, convertedtype=UINT_64"`
-	NTypes             int64  `parquet:"name=n_types, type=INT64, convertedtype=UINT_64"`
-	NDataSegments      int64  `parquet:"name=n_data_segments, type=INT64, convertedtype=UINT_64"`
-	NElemSegments      int64  `parquet:"name=n_elem_segments, type=INT64, convertedtype=UINT_64"`
-	NImports           int64  `parquet:"name=n_imports, type=INT64, convertedtype=UINT_64"`
-	NExports           int64  `parquet:"name=n_exports, type=INT64, convertedtype=UINT_64"`
-	NDataSegmentBytes  int64  `parquet:"name=n_data_segment_bytes, type=INT64, convertedtype=UINT_64"`
+	ContractCodeHash       string `parquet:"name=contract_code_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractCodeExtV       int32  `parquet:"name=contract_code_ext_v, type=INT32"`
+	LastModifiedLedger     int64  `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
+	LedgerEntryChange      int64  `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
+	Deleted                bool   `parquet:"name=deleted, type=BOOLEAN"`
+	ClosedAt               int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence         int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	LedgerKeyHash          string `parquet:"name=ledger_key_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	NInstructions          int64  `parquet:"name=n_instructions, type=INT64, convertedtype=UINT_64"`
+	NFunctions             int64  `parquet:"name=n_functions, type=INT64, convertedtype=UINT_64"`
+	NGlobals               int64  `parquet:"name=n_globals, type=INT64, convertedtype=UINT_64"`
+	NTableEntries          int64  `parquet:"name=n_table_entries, type=INT64, convertedtype=UINT_64"`
+	NTypes                 int64  `parquet:"name=n_types, type=INT64, convertedtype=UINT_64"`
+	NDataSegments          int64  `parquet:"name=n_data_segments, type=INT64, convertedtype=UINT_64"`
+	NElemSegments          int64  `parquet:"name=n_elem_segments, type=INT64, convertedtype=UINT_64"`
+	NImports               int64  `parquet:"name=n_imports, type=INT64, convertedtype=UINT_64"`
+	NExports               int64  `parquet:"name=n_exports, type=INT64, convertedtype=UINT_64"`
+	NDataSegmentBytes      int64  `parquet:"name=n_data_segment_bytes, type=INT64, convertedtype=UINT_64"`
+	EnvInterfaceProtocol   int64  `parquet:"name=env_interface_protocol, type=INT64, convertedtype=UINT_64"`
+	EnvInterfacePreRelease int64  `parquet:"name=env_interface_pre_release, type=INT64, convertedtype=UINT_64"`
+	RustVersion            string `parquet:"name=rust_version, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	RustSdkVersion         string `parquet:"name=rust_sdk_version, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractMeta           string `parquet:"name=contract_meta, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractFunctions      string `parquet:"name=contract_functions, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 }
 
 // ConfigSettingOutputParquet is a representation of soroban config settings that aligns with the Bigquery table config_settings
@@ -349,9 +577,27 @@ type ConfigSettingOutputParquet struct {
 	LedgerSequence                  int64   `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
 }
 
+// AccountDataOutputParquet is a representation of an account data entry that aligns with the BigQuery table account_data
+type AccountDataOutputParquet struct {
+	AccountID          string `parquet:"name=account_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DataName           string `parquet:"name=data_name, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DataValue          string `parquet:"name=data_value, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	DataValueDecoded   string `parquet:"name=data_value_decoded, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Sponsor            string `parquet:"name=sponsor, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LastModifiedLedger int64  `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
+	LedgerEntryChange  int64  `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
+	Deleted            bool   `parquet:"name=deleted, type=BOOLEAN"`
+	ClosedAt           int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+	LedgerSequence     int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	CreatedLedger      int64  `parquet:"name=created_ledger, type=INT64"`
+	RemovedLedger      int64  `parquet:"name=removed_ledger, type=INT64"`
+}
+
 // TtlOutputParquet is a representation of soroban ttl that aligns with the Bigquery table ttls
 type TtlOutputParquet struct {
 	KeyHash            string `parquet:"name=key_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerKey          string `parquet:"name=ledger_key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerKeyCanonical string `parquet:"name=ledger_key_canonical, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	LiveUntilLedgerSeq int64  `parquet:"name=live_until_ledger_seq, type=INT64, convertedtype=UINT_64"`
 	LastModifiedLedger int64  `parquet:"name=last_modified_ledger, type=INT64, convertedtype=UINT_64"`
 	LedgerEntryChange  int64  `parquet:"name=ledger_entry_change, type=INT64, convertedtype=UINT_64"`
@@ -360,6 +606,24 @@ type TtlOutputParquet struct {
 	LedgerSequence     int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
 }
 
+// ArchivalHistoryOutputParquet is a representation of a change in the lifetime of a soroban ledger entry that aligns with the BigQuery table archival_history
+type ArchivalHistoryOutputParquet struct {
+	KeyHash                    string `parquet:"name=key_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	EventType                  string `parquet:"name=event_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerKeyType              string `parquet:"name=ledger_key_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerKey                  string `parquet:"name=ledger_key, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LedgerKeyCanonical         string `parquet:"name=ledger_key_canonical, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractId                 string `parquet:"name=contract_id, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractCodeHash           string `parquet:"name=contract_code_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ContractDurability         string `parquet:"name=contract_durability, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	LiveUntilLedgerSeq         int64  `parquet:"name=live_until_ledger_seq, type=INT64"`
+	PreviousLiveUntilLedgerSeq int64  `parquet:"name=previous_live_until_ledger_seq, type=INT64"`
+	TransactionHash            string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	OperationID                int64  `parquet:"name=operation_id, type=INT64"`
+	LedgerSequence             int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	ClosedAt                   int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
 // ContractEventOutputParquet is a representation of soroban contract events and diagnostic events
 type ContractEventOutputParquet struct {
 	TransactionHash          string        `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
@@ -373,7 +637,40 @@ type ContractEventOutputParquet struct {
 	TypeString               string        `parquet:"name=type_string, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	Topics                   []interface{} `parquet:"name=topics, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	TopicsDecoded            []interface{} `parquet:"name=topics_decoded, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topic0                   string        `parquet:"name=topic0, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topic1                   string        `parquet:"name=topic1, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topic2                   string        `parquet:"name=topic2, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topic3                   string        `parquet:"name=topic3, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	Topic0Symbol             string        `parquet:"name=topic0_symbol, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	Data                     interface{}   `parquet:"name=data, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	DataDecoded              interface{}   `parquet:"name=data_decoded, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 	ContractEventXDR         string        `parquet:"name=contract_event_xdr, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
 }
+
+type FeeOutputParquet struct {
+	TransactionHash     string `parquet:"name=transaction_hash, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	TransactionID       int64  `parquet:"name=transaction_id, type=INT64"`
+	LedgerSequence      int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	FeeAccount          string `parquet:"name=fee_account, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	MaxFee              int64  `parquet:"name=max_fee, type=INT64"`
+	InitialFeeCharged   int64  `parquet:"name=initial_fee_charged, type=INT64"`
+	FeeRefund           int64  `parquet:"name=fee_refund, type=INT64"`
+	FeeCharged          int64  `parquet:"name=fee_charged, type=INT64"`
+	InclusionFeeCharged int64  `parquet:"name=inclusion_fee_charged, type=INT64"`
+	BaseFee             int64  `parquet:"name=base_fee, type=INT64, convertedtype=UINT_64"`
+	SurgePriced         bool   `parquet:"name=surge_priced, type=BOOLEAN"`
+	Soroban             bool   `parquet:"name=soroban, type=BOOLEAN"`
+	Successful          bool   `parquet:"name=successful, type=BOOLEAN"`
+	ClosedAt            int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
+
+// NetworkUpgradeOutputParquet is a representation of a network upgrade applied at the close of a ledger that aligns with the BigQuery table network_upgrades
+type NetworkUpgradeOutputParquet struct {
+	LedgerSequence  int64  `parquet:"name=ledger_sequence, type=INT64, convertedtype=UINT_64"`
+	UpgradeIndex    int64  `parquet:"name=upgrade_index, type=INT64, convertedtype=UINT_64"`
+	UpgradeType     string `parquet:"name=upgrade_type, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ConfigSettingId int64  `parquet:"name=config_setting_id, type=INT64"`
+	PreviousValue   string `parquet:"name=previous_value, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	NewValue        string `parquet:"name=new_value, type=BYTE_ARRAY, convertedtype=UTF8, encoding=PLAIN_DICTIONARY"`
+	ClosedAt        int64  `parquet:"name=closed_at, type=INT64, convertedtype=TIMESTAMP_MILLIS"`
+}
@@ -0,0 +1,292 @@
+package transform
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ProtoPackage is the package of the generated .proto definitions of the output structs
+const ProtoPackage = "stellar_etl.records.v1"
+
+// ProtoExtraFieldNumber is the number of the extra_fields map of every message, which holds the fields added with
+// --extra-fields and --provenance. It is far above the numbers of the columns, which are numbered in struct order.
+const ProtoExtraFieldNumber = 10000
+
+var protoFieldsCache sync.Map
+
+// ProtoDefinition returns the proto3 definition of the message of an output struct. Records are nested messages,
+// timestamps are google.protobuf.Timestamp and json values, such as operation details, are strings holding the
+// serialized json. Nullable scalars are optional fields, so that null and zero values can be told apart.
+func ProtoDefinition(output interface{}) string {
+	name := protoMessageName(output)
+	fields := SchemaFields(output)
+
+	var b strings.Builder
+	b.WriteString("// Code generated by stellar-etl generate_schemas. DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	b.WriteString("package " + ProtoPackage + ";\n\n")
+	if usesProtoTimestamp(fields) {
+		b.WriteString("import \"google/protobuf/timestamp.proto\";\n\n")
+	}
+
+	writeProtoComment(&b, "", SchemaDocs(output).Doc)
+	b.WriteString("message " + name + " {\n")
+	writeProtoFields(&b, "  ", fields)
+	b.WriteString(fmt.Sprintf("  // Fields added with --extra-fields and --provenance\n  map<string, string> extra_fields = %d;\n", ProtoExtraFieldNumber))
+
+	records := map[string][]SchemaField{}
+	collectProtoRecords(fields, records)
+	recordNames := make([]string, 0, len(records))
+	for recordName := range records {
+		recordNames = append(recordNames, recordName)
+	}
+	sort.Strings(recordNames)
+	for _, recordName := range recordNames {
+		b.WriteString("\n  message " + recordName + " {\n")
+		writeProtoFields(&b, "    ", records[recordName])
+		b.WriteString("  }\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+func protoMessageName(output interface{}) string {
+	outputType := reflect.TypeOf(output)
+	for outputType.Kind() == reflect.Ptr {
+		outputType = outputType.Elem()
+	}
+	return outputType.Name()
+}
+
+func usesProtoTimestamp(fields []SchemaField) bool {
+	for _, field := range fields {
+		if field.Type == "TIMESTAMP" || (field.Type == "RECORD" && usesProtoTimestamp(field.Fields)) {
+			return true
+		}
+	}
+	return false
+}
+
+func collectProtoRecords(fields []SchemaField, records map[string][]SchemaField) {
+	for _, field := range fields {
+		if field.Type == "RECORD" {
+			records[field.RecordName] = field.Fields
+			collectProtoRecords(field.Fields, records)
+		}
+	}
+}
+
+func writeProtoFields(b *strings.Builder, indent string, fields []SchemaField) {
+	for i, field := range fields {
+		writeProtoComment(b, indent, field.Description)
+
+		label := ""
+		if field.Repeated {
+			label = "repeated "
+		} else if field.Nullable && field.Type != "RECORD" {
+			label = "optional "
+		}
+		b.WriteString(fmt.Sprintf("%s%s%s %s = %d;\n", indent, label, protoType(field), field.Name, i+1))
+	}
+}
+
+func writeProtoComment(b *strings.Builder, indent, text string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		b.WriteString(strings.TrimRight(indent+"// "+line, " ") + "\n")
+	}
+}
+
+func protoType(field SchemaField) string {
+	switch field.Type {
+	case "INTEGER":
+		return "int64"
+	case "FLOAT":
+		return "double"
+	case "BOOLEAN":
+		return "bool"
+	case "TIMESTAMP":
+		return "google.protobuf.Timestamp"
+	case "BYTES":
+		return "bytes"
+	case "RECORD":
+		return field.RecordName
+	default:
+		return "string"
+	}
+}
+
+// EncodeProto encodes a row, decoded from the json of an output struct with UseNumber, as the message of
+// ProtoDefinition. Keys of the row that are not fields of the output are added to extra_fields.
+func EncodeProto(output interface{}, row map[string]interface{}) ([]byte, error) {
+	outputType := reflect.TypeOf(output)
+	cached, ok := protoFieldsCache.Load(outputType)
+	if !ok {
+		cached, _ = protoFieldsCache.LoadOrStore(outputType, SchemaFields(output))
+	}
+	fields := cached.([]SchemaField)
+
+	encoded, err := appendProtoFields(nil, fields, row)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		known[field.Name] = true
+	}
+	extraKeys := []string{}
+	for key := range row {
+		if !known[key] {
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	sort.Strings(extraKeys)
+	for _, key := range extraKeys {
+		value, ok := row[key].(string)
+		if !ok {
+			return nil, fmt.Errorf("extra field %s: expected a string, got %T", key, row[key])
+		}
+		var entry []byte
+		entry = protowire.AppendTag(entry, 1, protowire.BytesType)
+		entry = protowire.AppendString(entry, key)
+		entry = protowire.AppendTag(entry, 2, protowire.BytesType)
+		entry = protowire.AppendString(entry, value)
+		encoded = protowire.AppendTag(encoded, ProtoExtraFieldNumber, protowire.BytesType)
+		encoded = protowire.AppendBytes(encoded, entry)
+	}
+
+	return encoded, nil
+}
+
+func appendProtoFields(b []byte, fields []SchemaField, values map[string]interface{}) ([]byte, error) {
+	for i, field := range fields {
+		value := values[field.Name]
+		if value == nil {
+			continue
+		}
+
+		number := protowire.Number(i + 1)
+		if !field.Repeated {
+			var err error
+			if b, err = appendProtoValue(b, number, field, value); err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name, err)
+			}
+			continue
+		}
+
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a list, got %T", field.Name, value)
+		}
+		for _, item := range items {
+			if item == nil {
+				continue
+			}
+			var err error
+			if b, err = appendProtoValue(b, number, field, item); err != nil {
+				return nil, fmt.Errorf("%s: %w", field.Name, err)
+			}
+		}
+	}
+
+	return b, nil
+}
+
+func appendProtoValue(b []byte, number protowire.Number, field SchemaField, value interface{}) ([]byte, error) {
+	switch field.Type {
+	case "INTEGER":
+		jsonNumber, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", value)
+		}
+		number64, err := jsonNumber.Int64()
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, number, protowire.VarintType)
+		return protowire.AppendVarint(b, uint64(number64)), nil
+	case "FLOAT":
+		jsonNumber, ok := value.(json.Number)
+		if !ok {
+			return nil, fmt.Errorf("expected a number, got %T", value)
+		}
+		float, err := jsonNumber.Float64()
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, number, protowire.Fixed64Type)
+		return protowire.AppendFixed64(b, math.Float64bits(float)), nil
+	case "BOOLEAN":
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("expected a boolean, got %T", value)
+		}
+		b = protowire.AppendTag(b, number, protowire.VarintType)
+		return protowire.AppendVarint(b, protowire.EncodeBool(boolean)), nil
+	case "TIMESTAMP":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a timestamp, got %T", value)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return nil, err
+		}
+		var timestamp []byte
+		timestamp = protowire.AppendTag(timestamp, 1, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(t.Unix()))
+		timestamp = protowire.AppendTag(timestamp, 2, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(t.Nanosecond()))
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		return protowire.AppendBytes(b, timestamp), nil
+	case "BYTES":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected base64 encoded bytes, got %T", value)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		return protowire.AppendBytes(b, decoded), nil
+	case "RECORD":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected an object, got %T", value)
+		}
+		record, err := appendProtoFields(nil, field.Fields, values)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		return protowire.AppendBytes(b, record), nil
+	case "STRING":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a string, got %T", value)
+		}
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		return protowire.AppendString(b, s), nil
+	default:
+		marshalled, err := CanonicalJSON(value)
+		if err != nil {
+			return nil, err
+		}
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		return protowire.AppendBytes(b, marshalled), nil
+	}
+}
@@ -0,0 +1,106 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestProtoDefinition(t *testing.T) {
+	definition := ProtoDefinition(ClaimableBalanceOutput{})
+
+	assert.Contains(t, definition, "package stellar_etl.records.v1;\n")
+	assert.Contains(t, definition, "import \"google/protobuf/timestamp.proto\";\n")
+	assert.Contains(t, definition, "message ClaimableBalanceOutput {\n")
+	assert.Contains(t, definition, "  string balance_id = 1;\n")
+	assert.Contains(t, definition, "  repeated Claimant claimants = 2;\n")
+	assert.Contains(t, definition, "  optional string sponsor = 8;\n")
+	assert.Contains(t, definition, "  google.protobuf.Timestamp closed_at = 13;\n")
+	assert.Contains(t, definition, "  map<string, string> extra_fields = 10000;\n")
+	assert.Contains(t, definition, "  message Claimant {\n    string destination = 1;\n    optional string predicate = 2;\n")
+	assert.Contains(t, definition, "    optional google.protobuf.Timestamp claimable_from = 3;\n")
+	assert.Contains(t, definition, "    optional google.protobuf.Timestamp claimable_before = 4;\n  }\n")
+}
+
+// protoTestFields decodes the top level fields of a message, keyed by field number
+func protoTestFields(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	fields := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		number, wireType, n := protowire.ConsumeTag(b)
+		require.GreaterOrEqual(t, n, 0)
+		b = b[n:]
+
+		var value []byte
+		switch wireType {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			require.GreaterOrEqual(t, m, 0)
+			value, n = v, m
+		default:
+			n = protowire.ConsumeFieldValue(number, wireType, b)
+			require.GreaterOrEqual(t, n, 0)
+			value = b[:n]
+		}
+		fields[number] = append(fields[number], value)
+		b = b[n:]
+	}
+	return fields
+}
+
+func TestEncodeProto(t *testing.T) {
+	output := ClaimableBalanceOutput{
+		BalanceID:      "balance",
+		Claimants:      []Claimant{{Destination: "GA"}, {Destination: "GB"}},
+		AssetAmount:    1.5,
+		Deleted:        true,
+		ClosedAt:       time.Unix(1700000000, 5),
+		LedgerSequence: 10,
+	}
+	marshalled, err := json.Marshal(output)
+	require.NoError(t, err)
+	row := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&row))
+	row["batch_id"] = "b1"
+
+	encoded, err := EncodeProto(output, row)
+	require.NoError(t, err)
+	fields := protoTestFields(t, encoded)
+
+	assert.Equal(t, [][]byte{[]byte("balance")}, fields[1])
+	require.Len(t, fields[2], 2)
+	assert.Equal(t, [][]byte{[]byte("GB")}, protoTestFields(t, fields[2][1])[1])
+
+	amount, _ := protowire.ConsumeFixed64(fields[7][0])
+	assert.Equal(t, 1.5, math.Float64frombits(amount))
+	sponsor, ok := fields[8]
+	assert.False(t, ok, "null fields are left out, got %v", sponsor)
+	deleted, _ := protowire.ConsumeVarint(fields[12][0])
+	assert.Equal(t, uint64(1), deleted)
+
+	closedAt := protoTestFields(t, fields[13][0])
+	seconds, _ := protowire.ConsumeVarint(closedAt[1][0])
+	nanos, _ := protowire.ConsumeVarint(closedAt[2][0])
+	assert.Equal(t, uint64(1700000000), seconds)
+	assert.Equal(t, uint64(5), nanos)
+
+	require.Len(t, fields[ProtoExtraFieldNumber], 1)
+	extra := protoTestFields(t, fields[ProtoExtraFieldNumber][0])
+	assert.Equal(t, [][]byte{[]byte("batch_id")}, extra[1])
+	assert.Equal(t, [][]byte{[]byte("b1")}, extra[2])
+}
+
+func TestEncodeProtoErrors(t *testing.T) {
+	_, err := EncodeProto(ClaimableBalanceOutput{}, map[string]interface{}{"flags": "1"})
+	assert.EqualError(t, err, "flags: expected a number, got string")
+
+	_, err = EncodeProto(ClaimableBalanceOutput{}, map[string]interface{}{"batch_id": json.Number("1")})
+	assert.EqualError(t, err, "extra field batch_id: expected a string, got json.Number")
+}
@@ -216,7 +216,7 @@ var testClaimant = xdr.Claimant{
 
 var testClaimantDetails = Claimant{
 	Destination: testAccount1Address,
-	Predicate:   xdr.ClaimPredicate{},
+	Predicate:   map[string]interface{}{"unconditional": true},
 }
 
 var genericLedgerCloseMeta = xdr.LedgerCloseMeta{
@@ -0,0 +1,76 @@
+package transform
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// timestampFields caches the schema fields of the output types, which are looked up for every exported row
+var timestampFields sync.Map
+
+// EpochMillisTimestamps rewrites the timestamps of a row, decoded from the json of output, as the number of
+// milliseconds since the unix epoch. The timestamps are the TIMESTAMP columns of the output, including the ones of
+// nested records; other values, such as the timestamps inside json details, are left as they are.
+func EpochMillisTimestamps(output interface{}, row map[string]interface{}) error {
+	outputType := reflect.TypeOf(output)
+	if outputType == nil || outputType.Kind() != reflect.Struct {
+		return nil
+	}
+
+	fields, ok := timestampFields.Load(outputType)
+	if !ok {
+		fields, _ = timestampFields.LoadOrStore(outputType, SchemaFields(output))
+	}
+	return epochMillisFields(fields.([]SchemaField), row)
+}
+
+func epochMillisFields(fields []SchemaField, row map[string]interface{}) error {
+	for _, field := range fields {
+		value, ok := row[field.Name]
+		if !ok || value == nil {
+			continue
+		}
+
+		switch {
+		case field.Type == "TIMESTAMP" && !field.Repeated:
+			millis, err := epochMillis(value)
+			if err != nil {
+				return fmt.Errorf("could not convert %s: %v", field.Name, err)
+			}
+			row[field.Name] = millis
+		case field.Type == "RECORD" && field.Repeated:
+			items, _ := value.([]interface{})
+			for _, item := range items {
+				if record, ok := item.(map[string]interface{}); ok {
+					if err := epochMillisFields(field.Fields, record); err != nil {
+						return err
+					}
+				}
+			}
+		case field.Type == "RECORD":
+			if record, ok := value.(map[string]interface{}); ok {
+				if err := epochMillisFields(field.Fields, record); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func epochMillis(value interface{}) (json.Number, error) {
+	text, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("timestamp %v is not a string", value)
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, text)
+	if err != nil {
+		return "", err
+	}
+	return json.Number(strconv.FormatInt(parsed.UnixMilli(), 10)), nil
+}
@@ -0,0 +1,60 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type timestampsTestRecord struct {
+	At null.Time `json:"at"`
+}
+
+type timestampsTestOutput struct {
+	ClosedAt time.Time              `json:"closed_at"`
+	Record   timestampsTestRecord   `json:"record"`
+	Records  []timestampsTestRecord `json:"records"`
+	Details  map[string]interface{} `json:"details"`
+}
+
+func decodeTimestampsTestRow(t *testing.T, output interface{}) map[string]interface{} {
+	marshalled, err := json.Marshal(output)
+	require.NoError(t, err)
+	row := map[string]interface{}{}
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&row))
+	return row
+}
+
+func TestEpochMillisTimestamps(t *testing.T) {
+	closedAt := time.Date(2020, time.July, 9, 5, 28, 42, 500000000, time.UTC)
+	output := timestampsTestOutput{
+		ClosedAt: closedAt,
+		Record:   timestampsTestRecord{At: null.TimeFrom(closedAt.Add(time.Second))},
+		Records:  []timestampsTestRecord{{At: null.TimeFrom(closedAt)}, {}},
+		Details:  map[string]interface{}{"closed_at": "2020-07-09T05:28:42Z"},
+	}
+
+	row := decodeTimestampsTestRow(t, output)
+	require.NoError(t, EpochMillisTimestamps(output, row))
+
+	assert.Equal(t, json.Number("1594272522500"), row["closed_at"])
+	assert.Equal(t, map[string]interface{}{"at": json.Number("1594272523500")}, row["record"])
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"at": json.Number("1594272522500")},
+		map[string]interface{}{"at": nil},
+	}, row["records"])
+	assert.Equal(t, map[string]interface{}{"closed_at": "2020-07-09T05:28:42Z"}, row["details"])
+}
+
+func TestEpochMillisTimestampsNotStruct(t *testing.T) {
+	row := map[string]interface{}{"closed_at": "2020-07-09T05:28:42Z"}
+	require.NoError(t, EpochMillisTimestamps(map[string]interface{}{}, row))
+	assert.Equal(t, "2020-07-09T05:28:42Z", row["closed_at"])
+}
@@ -11,7 +11,11 @@ import (
 	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
-func TransformTokenTransfer(ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string) ([]TokenTransferOutput, error) {
+func TransformTokenTransfer(ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphrase string, ids toid.Generator) (_ []TokenTransferOutput, err error) {
+	defer wrapError(&err, ledgerCoordinates(ErrorCodeInvalidEvent, ledgerCloseMeta.LedgerSequence(), networkPassphrase))
+	if err := checkLedgerProtocol(ledgerCloseMeta.ProtocolVersion()); err != nil {
+		return []TokenTransferOutput{}, err
+	}
 	eventsProcessor := token_transfer.NewEventsProcessor(networkPassphrase)
 
 	events, err := eventsProcessor.EventsFromLedger(ledgerCloseMeta)
@@ -26,7 +30,7 @@ func TransformTokenTransfer(ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphra
 
 	var transformedTTP []TokenTransferOutput
 
-	transformedTTP, err = transformEvents(events, ledgerCloseMeta)
+	transformedTTP, err = transformEvents(events, ledgerCloseMeta, ids)
 	if err != nil {
 		return []TokenTransferOutput{}, err
 	}
@@ -34,7 +38,7 @@ func TransformTokenTransfer(ledgerCloseMeta xdr.LedgerCloseMeta, networkPassphra
 	return transformedTTP, nil
 }
 
-func transformEvents(events []*token_transfer.TokenTransferEvent, ledgerCloseMeta xdr.LedgerCloseMeta) ([]TokenTransferOutput, error) {
+func transformEvents(events []*token_transfer.TokenTransferEvent, ledgerCloseMeta xdr.LedgerCloseMeta, ids toid.Generator) ([]TokenTransferOutput, error) {
 	var transformedTTP []TokenTransferOutput
 
 	for _, event := range events {
@@ -82,15 +86,19 @@ func transformEvents(events []*token_transfer.TokenTransferEvent, ledgerCloseMet
 		eventMeta := event.GetMeta()
 		ledgerSequence := eventMeta.LedgerSequence
 		transactionIndex := eventMeta.TransactionIndex
-		transactionID := toid.New(int32(ledgerSequence), int32(transactionIndex), 0).ToInt64()
+		transactionID := ids.New(int32(ledgerSequence), int32(transactionIndex), 0)
 		operationIndex := eventMeta.OperationIndex
 		if operationIndex != nil {
 			opIndex = int32(*operationIndex)
-			opID = toid.New(int32(ledgerSequence), int32(transactionIndex), opIndex).ToInt64()
+			opID = ids.New(int32(ledgerSequence), int32(transactionIndex), opIndex)
 			operationID = null.IntFrom(opID)
 		}
 
 		asset, assetType, assetCode, assetIssuer = getAssetFromEvent(event)
+		var assetID null.Int
+		if assetType != "" {
+			assetID = null.IntFrom(FarmHashAsset(assetCode.String, assetIssuer.String, assetType))
+		}
 
 		var toMuxedID null.String
 		var toMuxed null.String
@@ -116,6 +124,7 @@ func transformEvents(events []*token_transfer.TokenTransferEvent, ledgerCloseMet
 			AssetType:       assetType,
 			AssetCode:       assetCode,
 			AssetIssuer:     assetIssuer,
+			AssetID:         assetID,
 			AmountRaw:       amount,
 			Amount:          amountFloat,
 			ContractID:      eventMeta.ContractAddress,
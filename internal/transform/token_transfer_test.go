@@ -10,6 +10,8 @@ import (
 	"github.com/stellar/go/asset"
 	"github.com/stellar/go/processors/token_transfer"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 func TestTransformTokenTransfer(t *testing.T) {
@@ -43,8 +45,8 @@ func TestTransformTokenTransfer(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := transformEvents(test.input.events, test.input.lcm)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := transformEvents(test.input.events, test.input.lcm, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -63,6 +65,7 @@ func makeTokenTransferTestOutput() (output [][]TokenTransferOutput, err error) {
 				AssetType:       "credit_alphanum4",
 				AssetCode:       null.StringFrom("abc"),
 				AssetIssuer:     null.StringFrom("def"),
+				AssetID:         null.IntFrom(FarmHashAsset("abc", "def", "credit_alphanum4")),
 				Amount:          9.999999999999999e-06,
 				AmountRaw:       "100",
 				ContractID:      "contractaddress",
@@ -82,6 +85,7 @@ func makeTokenTransferTestOutput() (output [][]TokenTransferOutput, err error) {
 				AssetType:       "credit_alphanum4",
 				AssetCode:       null.StringFrom("abc"),
 				AssetIssuer:     null.StringFrom("def"),
+				AssetID:         null.IntFrom(FarmHashAsset("abc", "def", "credit_alphanum4")),
 				Amount:          9.999999999999999e-06,
 				AmountRaw:       "100",
 				ContractID:      "contractaddress",
@@ -101,6 +105,7 @@ func makeTokenTransferTestOutput() (output [][]TokenTransferOutput, err error) {
 				AssetType:       "credit_alphanum4",
 				AssetCode:       null.StringFrom("abc"),
 				AssetIssuer:     null.StringFrom("def"),
+				AssetID:         null.IntFrom(FarmHashAsset("abc", "def", "credit_alphanum4")),
 				Amount:          9.999999999999999e-06,
 				AmountRaw:       "100",
 				ContractID:      "contractaddress",
@@ -120,6 +125,7 @@ func makeTokenTransferTestOutput() (output [][]TokenTransferOutput, err error) {
 				AssetType:       "credit_alphanum4",
 				AssetCode:       null.StringFrom("abc"),
 				AssetIssuer:     null.StringFrom("def"),
+				AssetID:         null.IntFrom(FarmHashAsset("abc", "def", "credit_alphanum4")),
 				Amount:          9.999999999999999e-06,
 				AmountRaw:       "100",
 				ContractID:      "contractaddress",
@@ -139,6 +145,7 @@ func makeTokenTransferTestOutput() (output [][]TokenTransferOutput, err error) {
 				AssetType:       "credit_alphanum4",
 				AssetCode:       null.StringFrom("abc"),
 				AssetIssuer:     null.StringFrom("def"),
+				AssetID:         null.IntFrom(FarmHashAsset("abc", "def", "credit_alphanum4")),
 				Amount:          9.999999999999999e-06,
 				AmountRaw:       "100",
 				ContractID:      "contractaddress",
@@ -3,8 +3,10 @@ package transform
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"time"
 
+	"github.com/dgryski/go-farm"
 	"github.com/guregu/null"
 	"github.com/pkg/errors"
 
@@ -17,8 +19,22 @@ import (
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 )
 
+// The claim atom types of trades: offers of the order book and liquidity pools
+const (
+	TradeTypeOrderbook     = "orderbook"
+	TradeTypeLiquidityPool = "liquidity_pool"
+)
+
 // TransformTrade converts a relevant operation from the history archive ingestion system into a form suitable for BigQuery
-func TransformTrade(operationIndex int32, operationID int64, transaction ingest.LedgerTransaction, ledgerCloseTime time.Time) ([]TradeOutput, error) {
+func TransformTrade(operationIndex int32, operationID int64, transaction ingest.LedgerTransaction, ledgerCloseTime time.Time, ids toid.Generator) (_ []TradeOutput, err error) {
+	coordinates := transactionCoordinates(ErrorCodeInvalidTrade, transaction, uint32(ids.Parse(operationID).LedgerSequence), "")
+	coordinates.OperationIndex = operationIndex
+	defer wrapError(&err, coordinates)
+	if protocolVersion, ok := closeMetaProtocolVersion(transaction.Ledger); ok {
+		if err := checkTransactionMeta(transaction.UnsafeMeta, protocolVersion); err != nil {
+			return []TradeOutput{}, err
+		}
+	}
 	operationResults, ok := transaction.Result.OperationResults()
 	if !ok {
 		return []TradeOutput{}, fmt.Errorf("could not get any results from this transaction")
@@ -82,6 +98,7 @@ func TransformTrade(operationIndex int32, operationID int64, transaction ingest.
 		var outputPoolFee, roundingSlippageBips null.Int
 		var outputSellingOfferID, outputBuyingOfferID null.Int
 		var tradeType int32
+		claimAtomType := TradeTypeOrderbook
 		if claimOffer.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
 			id := claimOffer.MustLiquidityPool().LiquidityPoolId
 			liquidityPoolIDString, err = strkey.Encode(strkey.VersionByteLiquidityPool, id[:])
@@ -91,6 +108,7 @@ func TransformTrade(operationIndex int32, operationID int64, transaction ingest.
 			liquidityPoolIDStrkey = null.StringFrom(liquidityPoolIDString)
 			liquidityPoolID = null.StringFrom(PoolIDToString(id))
 			tradeType = int32(2)
+			claimAtomType = TradeTypeLiquidityPool
 			var fee uint32
 			if fee, err = findPoolFee(transaction, operationIndex, id); err != nil {
 				return []TradeOutput{}, fmt.Errorf("cannot parse fee for liquidity pool %v", liquidityPoolID)
@@ -154,7 +172,23 @@ func TransformTrade(operationIndex int32, operationID int64, transaction ingest.
 			RoundingSlippage:             roundingSlippageBips,
 			SellerIsExact:                sellerIsExact,
 			SellingLiquidityPoolIDStrkey: liquidityPoolIDStrkey,
+			PagingToken:                  fmt.Sprintf("%d-%d", outputOperationID, outputOrder),
+			PriceDecimal:                 utils.ConvertPriceToDecimal(outputPriceN, outputPriceD),
+			ClaimAtomType:                claimAtomType,
+			SellingOfferIDStr:            offerIDString(outputSellingOfferID),
+			BuyingOfferIDStr:             offerIDString(outputBuyingOfferID),
+		}
+		trade.PairID, trade.BaseIsSeller = TradePair(outputSellingAssetID, outputBuyingAssetID)
+		sellerID := outputSellingAccountAddress
+		if claimOffer.Type == xdr.ClaimAtomTypeClaimAtomTypeLiquidityPool {
+			sellerID = liquidityPoolIDString
 		}
+		trade.TradeID = tradeID(
+			outputOperationID,
+			outputOrder,
+			tradeSide(sellerID, outputSellingAssetID, int64(outputSellingAmount)),
+			tradeSide(outputBuyingAccountAddress, outputBuyingAssetID, outputBuyingAmount),
+		)
 
 		transformedTrades = append(transformedTrades, trade)
 	}
@@ -397,3 +431,65 @@ func roundingSlippage(t ingest.LedgerTransaction, operationIndex int32, trade xd
 	}
 
 }
+
+// TradePair returns the id of the pair of assets of a trade and whether the sold asset is the base of that pair.
+// As in Horizon, the base of a pair is the asset with the lowest id, so that the trades of both orientations of a
+// market share the same pair id.
+func TradePair(sellingAssetID, buyingAssetID int64) (pairID int64, baseIsSeller bool) {
+	baseAssetID, counterAssetID := buyingAssetID, sellingAssetID
+	if sellingAssetID < buyingAssetID {
+		baseAssetID, counterAssetID = sellingAssetID, buyingAssetID
+		baseIsSeller = true
+	}
+
+	pair := fmt.Sprintf("%d:%d", baseAssetID, counterAssetID)
+	return int64(farm.Fingerprint64([]byte(pair))), baseIsSeller
+}
+
+// NormalizeTradePair fills the base and counter columns of a trade, which orient it the same way as the other
+// trades of its pair whichever asset was sold. The price of the base asset is in units of the counter asset.
+func NormalizeTradePair(trade TradeOutput) TradeOutput {
+	sellingAccount := null.NewString(trade.SellingAccountAddress, trade.SellingAccountAddress != "")
+	buyingAccount := null.NewString(trade.BuyingAccountAddress, trade.BuyingAccountAddress != "")
+
+	if trade.BaseIsSeller {
+		trade.BaseAccountAddress, trade.CounterAccountAddress = sellingAccount, buyingAccount
+		trade.BaseAssetID, trade.CounterAssetID = null.IntFrom(trade.SellingAssetID), null.IntFrom(trade.BuyingAssetID)
+		trade.BaseAmount, trade.CounterAmount = null.FloatFrom(trade.SellingAmount), null.FloatFrom(trade.BuyingAmount)
+		trade.BasePriceN, trade.BasePriceD = null.IntFrom(trade.PriceN), null.IntFrom(trade.PriceD)
+		trade.BasePriceDecimal = null.StringFrom(trade.PriceDecimal)
+	} else {
+		trade.BaseAccountAddress, trade.CounterAccountAddress = buyingAccount, sellingAccount
+		trade.BaseAssetID, trade.CounterAssetID = null.IntFrom(trade.BuyingAssetID), null.IntFrom(trade.SellingAssetID)
+		trade.BaseAmount, trade.CounterAmount = null.FloatFrom(trade.BuyingAmount), null.FloatFrom(trade.SellingAmount)
+		trade.BasePriceN, trade.BasePriceD = null.IntFrom(trade.PriceD), null.IntFrom(trade.PriceN)
+		trade.BasePriceDecimal = null.StringFrom(utils.ConvertPriceToDecimal(trade.PriceD, trade.PriceN))
+	}
+
+	return trade
+}
+
+// offerIDString returns an offer id as a decimal string, or null for the trades that did not cross an offer
+func offerIDString(offerID null.Int) null.String {
+	if !offerID.Valid {
+		return null.String{}
+	}
+	return null.StringFrom(strconv.FormatInt(offerID.Int64, 10))
+}
+
+// tradeSide describes the asset and amount given up by one counterparty of a trade
+func tradeSide(party string, assetID, amount int64) string {
+	return fmt.Sprintf("%s:%d:%d", party, assetID, amount)
+}
+
+// tradeID returns the id of a trade: the toid of its operation and its claim order, followed by a hash of both of
+// its sides. The sides are sorted before hashing, so the hash does not depend on which counterparty was the seller.
+// Every part is read from the ledger, so re-exporting a range gives the same ids whatever the parallelism.
+func tradeID(operationID int64, order int32, sideA, sideB string) string {
+	if sideB < sideA {
+		sideA, sideB = sideB, sideA
+	}
+
+	hash := farm.Fingerprint64([]byte(sideA + "|" + sideB))
+	return fmt.Sprintf("%d-%d-%016x", operationID, order, hash)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/guregu/null"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
 
 	"github.com/stellar/go/ingest"
@@ -169,8 +170,8 @@ func TestTransformTrade(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformTrade(test.input.index, 100, test.input.transaction, test.input.closeTime)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformTrade(test.input.index, 100, test.input.transaction, test.input.closeTime, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -738,7 +739,14 @@ func makeTradeTestOutput() [][]TradeOutput {
 		SellingOfferID:        null.IntFrom(97684906),
 		BuyingOfferID:         null.IntFrom(4611686018427388005),
 		HistoryOperationID:    101,
+		PagingToken:           "101-0",
 		TradeType:             1,
+		PairID:                -8270026821126729100,
+		PriceDecimal:          "0.000949900028924057",
+		SellingOfferIDStr:     null.StringFrom("97684906"),
+		BuyingOfferIDStr:      null.StringFrom("4611686018427388005"),
+		TradeID:               "101-0-896b3c0050b41339",
+		ClaimAtomType:         TradeTypeOrderbook,
 	}
 	offerTwoOutput := TradeOutput{
 		Order:                 0,
@@ -760,7 +768,15 @@ func makeTradeTestOutput() [][]TradeOutput {
 		SellingOfferID:        null.IntFrom(86106895),
 		BuyingOfferID:         null.IntFrom(4611686018427388005),
 		HistoryOperationID:    101,
+		PagingToken:           "101-0",
 		TradeType:             1,
+		PairID:                -6344210668153144943,
+		BaseIsSeller:          true,
+		PriceDecimal:          "25",
+		SellingOfferIDStr:     null.StringFrom("86106895"),
+		BuyingOfferIDStr:      null.StringFrom("4611686018427388005"),
+		TradeID:               "101-0-092c04dc565d39d8",
+		ClaimAtomType:         TradeTypeOrderbook,
 	}
 
 	lPOneOutput := TradeOutput{
@@ -783,10 +799,16 @@ func makeTradeTestOutput() [][]TradeOutput {
 		SellingLiquidityPoolID:       null.StringFrom("0405060000000000000000000000000000000000000000000000000000000000"),
 		LiquidityPoolFee:             null.IntFrom(30),
 		HistoryOperationID:           101,
+		PagingToken:                  "101-0",
 		TradeType:                    2,
 		RoundingSlippage:             null.IntFrom(0),
 		SellerIsExact:                null.BoolFrom(false),
 		SellingLiquidityPoolIDStrkey: null.StringFrom("LACAKBQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAGOE"),
+		PairID:                       6016497284812811386,
+		PriceDecimal:                 "3.707317073170731707",
+		BuyingOfferIDStr:             null.StringFrom("4611686018427388005"),
+		TradeID:                      "101-0-1c00594aba1e241f",
+		ClaimAtomType:                TradeTypeLiquidityPool,
 	}
 
 	lPTwoOutput := TradeOutput{
@@ -809,10 +831,17 @@ func makeTradeTestOutput() [][]TradeOutput {
 		SellingLiquidityPoolID:       null.StringFrom("0102030405060000000000000000000000000000000000000000000000000000"),
 		LiquidityPoolFee:             null.IntFrom(30),
 		HistoryOperationID:           101,
+		PagingToken:                  "101-0",
 		TradeType:                    2,
 		RoundingSlippage:             null.IntFrom(9223372036854775807),
 		SellerIsExact:                null.BoolFrom(true),
 		SellingLiquidityPoolIDStrkey: null.StringFrom("LAAQEAYEAUDAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABUTF"),
+		PairID:                       -4475866481750984446,
+		BaseIsSeller:                 true,
+		PriceDecimal:                 "1",
+		BuyingOfferIDStr:             null.StringFrom("4611686018427388005"),
+		TradeID:                      "101-0-fbc025e432470e36",
+		ClaimAtomType:                TradeTypeLiquidityPool,
 	}
 
 	onePriceIsAmount := offerOneOutput
@@ -822,15 +851,20 @@ func makeTradeTestOutput() [][]TradeOutput {
 
 	offerOneOutputSecondPlace := onePriceIsAmount
 	offerOneOutputSecondPlace.Order = 1
+	offerOneOutputSecondPlace.PagingToken = "101-1"
+	offerOneOutputSecondPlace.TradeID = "101-1-896b3c0050b41339"
 	offerOneOutputSecondPlace.SellerIsExact = null.BoolFrom(true)
 
 	twoPriceIsAmount := offerTwoOutput
 	twoPriceIsAmount.PriceN = int64(twoPriceIsAmount.BuyingAmount * 10000000)
 	twoPriceIsAmount.PriceD = int64(twoPriceIsAmount.SellingAmount * 10000000)
+	twoPriceIsAmount.PriceDecimal = "0.04"
 	twoPriceIsAmount.SellerIsExact = null.BoolFrom(true)
 
 	offerTwoOutputSecondPlace := twoPriceIsAmount
 	offerTwoOutputSecondPlace.Order = 1
+	offerTwoOutputSecondPlace.PagingToken = "101-1"
+	offerTwoOutputSecondPlace.TradeID = "101-1-092c04dc565d39d8"
 	offerTwoOutputSecondPlace.SellerIsExact = null.BoolFrom(false)
 
 	output := [][]TradeOutput{
@@ -844,3 +878,49 @@ func makeTradeTestOutput() [][]TradeOutput {
 	}
 	return output
 }
+
+func TestNormalizeTradePair(t *testing.T) {
+	outputs := makeTradeTestOutput()
+	offerOneOutput, offerTwoOutput := outputs[0][0], outputs[1][0]
+
+	// The mirrored trade sells the asset that the first one bought, and belongs to the same pair
+	mirrored := offerOneOutput
+	mirrored.SellingAssetID, mirrored.BuyingAssetID = offerOneOutput.BuyingAssetID, offerOneOutput.SellingAssetID
+	mirrored.SellingAmount, mirrored.BuyingAmount = offerOneOutput.BuyingAmount, offerOneOutput.SellingAmount
+	mirrored.PriceN, mirrored.PriceD = offerOneOutput.PriceD, offerOneOutput.PriceN
+	pairID, baseIsSeller := TradePair(mirrored.SellingAssetID, mirrored.BuyingAssetID)
+	assert.Equal(t, offerOneOutput.PairID, pairID)
+	assert.True(t, baseIsSeller)
+
+	normalized := NormalizeTradePair(offerOneOutput)
+	assert.Equal(t, null.StringFrom(testAccount3Address), normalized.BaseAccountAddress)
+	assert.Equal(t, null.IntFrom(offerOneOutput.BuyingAssetID), normalized.BaseAssetID)
+	assert.Equal(t, null.FloatFrom(offerOneOutput.BuyingAmount), normalized.BaseAmount)
+	assert.Equal(t, null.StringFrom(testAccount1Address), normalized.CounterAccountAddress)
+	assert.Equal(t, null.IntFrom(offerOneOutput.SellingAssetID), normalized.CounterAssetID)
+	assert.Equal(t, null.FloatFrom(offerOneOutput.SellingAmount), normalized.CounterAmount)
+	assert.Equal(t, null.IntFrom(13300347), normalized.BasePriceN)
+	assert.Equal(t, null.IntFrom(12634), normalized.BasePriceD)
+	assert.Equal(t, null.StringFrom("1052.742361880639544087"), normalized.BasePriceDecimal)
+
+	normalized = NormalizeTradePair(offerTwoOutput)
+	assert.Equal(t, null.IntFrom(offerTwoOutput.SellingAssetID), normalized.BaseAssetID)
+	assert.Equal(t, null.IntFrom(offerTwoOutput.BuyingAssetID), normalized.CounterAssetID)
+	assert.Equal(t, null.IntFrom(25), normalized.BasePriceN)
+	assert.Equal(t, null.IntFrom(1), normalized.BasePriceD)
+	assert.Equal(t, null.StringFrom("25"), normalized.BasePriceDecimal)
+
+	// Liquidity pools have no selling account
+	normalized = NormalizeTradePair(outputs[5][0])
+	assert.False(t, normalized.BaseAccountAddress.Valid)
+}
+
+func TestTradeID(t *testing.T) {
+	seller := tradeSide(testAccount1Address, 4476940172956910889, 13300347)
+	buyer := tradeSide(testAccount3Address, -8205667356306085451, 12634)
+
+	id := tradeID(101, 0, seller, buyer)
+	assert.Equal(t, "101-0-896b3c0050b41339", id)
+	assert.Equal(t, id, tradeID(101, 0, buyer, seller))
+	assert.NotEqual(t, id, tradeID(101, 1, seller, buyer))
+}
@@ -3,8 +3,10 @@ package transform
 import (
 	"encoding/base64"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/guregu/null"
 	"github.com/lib/pq"
@@ -17,14 +19,18 @@ import (
 )
 
 // TransformTransaction converts a transaction from the history archive ingestion system into a form suitable for BigQuery
-func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry) (TransactionOutput, error) {
+func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHeaderHistoryEntry, ids toid.Generator) (_ TransactionOutput, err error) {
+	defer wrapError(&err, transactionCoordinates(ErrorCodeInvalidTransaction, transaction, uint32(lhe.Header.LedgerSeq), ""))
+	if err := checkTransactionMeta(transaction.UnsafeMeta, uint32(lhe.Header.LedgerVersion)); err != nil {
+		return TransactionOutput{}, err
+	}
 	ledgerHeader := lhe.Header
 	outputTransactionHash := utils.HashToHexString(transaction.Result.TransactionHash)
 	outputLedgerSequence := uint32(ledgerHeader.LedgerSeq)
 
 	transactionIndex := uint32(transaction.Index)
 
-	outputTransactionID := toid.New(int32(outputLedgerSequence), int32(transactionIndex), 0).ToInt64()
+	outputTransactionID := ids.New(int32(outputLedgerSequence), int32(transactionIndex), 0)
 
 	sourceAccount := transaction.Envelope.SourceAccount()
 	outputAccount, err := utils.GetAccountAddressFromMuxedAccount(transaction.Envelope.SourceAccount())
@@ -144,6 +150,8 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 	var outputTotalNonRefundableResourceFeeCharged int64
 	var outputTotalRefundableResourceFeeCharged int64
 	var outputRentFeeCharged int64
+	var outputSorobanReturnValue null.String
+	var outputContractEventsCount, outputDiagnosticEventsCount uint32
 	var feeAccountAddress string
 
 	// Soroban data can exist in V1 and FeeBump transactionEnvelopes
@@ -173,6 +181,10 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 			accountBalanceStart, accountBalanceEnd := getAccountBalanceFromLedgerEntryChanges(meta.TxChangesAfter, feeAccountAddress)
 			outputResourceFeeRefund = accountBalanceEnd - accountBalanceStart
 			if meta.SorobanMeta != nil {
+				outputSorobanReturnValue, outputContractEventsCount, outputDiagnosticEventsCount, err = summarizeSorobanMeta(*meta.SorobanMeta, transaction.Result.Successful())
+				if err != nil {
+					return TransactionOutput{}, fmt.Errorf("for ledger %d; transaction %d (transaction id=%d): %v", outputLedgerSequence, transactionIndex, outputTransactionID, err)
+				}
 				extV1, ok := meta.SorobanMeta.Ext.GetV1()
 				if ok {
 					outputTotalNonRefundableResourceFeeCharged = int64(extV1.TotalNonRefundableResourceFeeCharged)
@@ -185,7 +197,7 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		// Protocol 20 contained a bug where the feeCharged was incorrectly calculated but was fixed for
 		// Protocol 21 with https://github.com/stellar/stellar-core/issues/4188
 		// Any Soroban Fee Bump transactions before P21 will need the below logic to calculate the correct feeCharged
-		if ledgerHeader.LedgerVersion < 21 && transaction.Envelope.Type == xdr.EnvelopeTypeEnvelopeTypeTxFeeBump {
+		if uint32(ledgerHeader.LedgerVersion) < ProtocolSorobanFeeBumpFix && transaction.Envelope.Type == xdr.EnvelopeTypeEnvelopeTypeTxFeeBump {
 			outputFeeCharged = outputResourceFee - outputResourceFeeRefund
 		}
 	}
@@ -239,6 +251,10 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		TotalRefundableResourceFeeCharged:    outputTotalRefundableResourceFeeCharged,
 		RentFeeCharged:                       outputRentFeeCharged,
 		TxSigners:                            txSigners,
+		SorobanReturnValue:                   outputSorobanReturnValue,
+		ContractEventsCount:                  outputContractEventsCount,
+		DiagnosticEventsCount:                outputDiagnosticEventsCount,
+		PagingToken:                          strconv.FormatInt(outputTransactionID, 10),
 	}
 
 	// Add Muxed Account Details, if exists
@@ -262,7 +278,7 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 		transformedTransaction.FeeAccount = feeAccount.Address()
 		innerHash := transaction.Result.InnerHash()
 		transformedTransaction.InnerTransactionHash = hex.EncodeToString(innerHash[:])
-		transformedTransaction.NewMaxFee = uint32(transaction.Envelope.FeeBumpFee())
+		transformedTransaction.NewMaxFee = transaction.Envelope.FeeBumpFee()
 		txSigners, err := getTxSigners(transaction.Envelope.FeeBump.Signatures)
 		if err != nil {
 			return TransactionOutput{}, err
@@ -274,6 +290,43 @@ func TransformTransaction(transaction ingest.LedgerTransaction, lhe xdr.LedgerHe
 	return transformedTransaction, nil
 }
 
+// TransactionSizeMetrics fills the size columns of a transformed transaction: the sizes in bytes of the xdr of its
+// envelope, result and meta, and the number of ledger entries changed by its operations. They are opt-in, since
+// they are only needed to study the growth of the meta.
+func TransactionSizeMetrics(transaction ingest.LedgerTransaction, output TransactionOutput) (TransactionOutput, error) {
+	output.EnvelopeSize = null.IntFrom(xdrSize(output.TxEnvelope))
+	output.ResultSize = null.IntFrom(xdrSize(output.TxResult))
+	output.MetaSize = null.IntFrom(xdrSize(output.TxMeta))
+
+	// GetOperationChanges does not support the meta of the first protocols, so the operation metas are read here
+	var operationMetas []xdr.OperationMeta
+	switch transaction.UnsafeMeta.V {
+	case 0:
+		operationMetas = transaction.UnsafeMeta.MustOperations()
+	case 1:
+		operationMetas = transaction.UnsafeMeta.MustV1().Operations
+	case 2:
+		operationMetas = transaction.UnsafeMeta.MustV2().Operations
+	case 3:
+		operationMetas = transaction.UnsafeMeta.MustV3().Operations
+	default:
+		return TransactionOutput{}, fmt.Errorf("unsupported meta version %d for ledger %d; transaction %d (transaction id=%d)", transaction.UnsafeMeta.V, output.LedgerSequence, transaction.Index, output.TransactionID)
+	}
+
+	var operationChangesCount int64
+	for _, operationMeta := range operationMetas {
+		operationChangesCount += int64(len(ingest.GetChangesFromLedgerEntryChanges(operationMeta.Changes)))
+	}
+	output.OperationChangesCount = null.IntFrom(operationChangesCount)
+
+	return output, nil
+}
+
+// xdrSize returns the number of bytes encoded by a padded base64 string of xdr
+func xdrSize(encoded string) int64 {
+	return int64(base64.StdEncoding.DecodedLen(len(encoded)) - strings.Count(encoded[max(len(encoded)-2, 0):], "="))
+}
+
 func getAccountBalanceFromLedgerEntryChanges(changes xdr.LedgerEntryChanges, sourceAccountAddress string) (int64, int64) {
 	var accountBalanceStart int64
 	var accountBalanceEnd int64
@@ -330,3 +383,28 @@ func getTxSigners(xdrSignatures []xdr.DecoratedSignature) ([]string, error) {
 
 	return signers, nil
 }
+
+// summarizeSorobanMeta returns the decoded return value of a soroban transaction, which only successful transactions
+// have, along with its number of contract events and of diagnostic events. Diagnostic events are only recorded by
+// nodes with diagnostic events enabled.
+func summarizeSorobanMeta(meta xdr.SorobanTransactionMeta, successful bool) (null.String, uint32, uint32, error) {
+	var returnValue null.String
+	if successful {
+		_, decoded, err := serializeScVal(meta.ReturnValue)
+		if err != nil {
+			return null.String{}, 0, 0, err
+		}
+		if raw, ok := decoded.(json.RawMessage); ok {
+			returnValue = null.StringFrom(string(raw))
+		}
+	}
+
+	var diagnosticEventsCount uint32
+	for _, event := range meta.DiagnosticEvents {
+		if event.Event.Type == xdr.ContractEventTypeDiagnostic {
+			diagnosticEventsCount++
+		}
+	}
+
+	return returnValue, uint32(len(meta.Events)), diagnosticEventsCount, nil
+}
@@ -1,16 +1,21 @@
 package transform
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
 	"testing"
 	"time"
 
 	"github.com/guregu/null"
 	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 func TestTransformTransaction(t *testing.T) {
@@ -73,12 +78,120 @@ func TestTransformTransaction(t *testing.T) {
 	}
 
 	for _, test := range tests {
-		actualOutput, actualError := TransformTransaction(test.input.transaction, test.input.historyHeader)
-		assert.Equal(t, test.wantErr, actualError)
+		actualOutput, actualError := TransformTransaction(test.input.transaction, test.input.historyHeader, toid.Generator{})
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
 
+func TestTransformTransactionLargeNewMaxFee(t *testing.T) {
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeTransactionTestInput()
+	require.NoError(t, err)
+
+	// the outer fee of a fee bump is an int64, beyond the range of the uint32 fee of the inner transaction
+	feeBump := hardCodedTransaction[1]
+	newMaxFee := int64(math.MaxUint32) * 3
+	feeBump.Envelope.FeeBump.Tx.Fee = xdr.Int64(newMaxFee)
+
+	output, err := TransformTransaction(feeBump, hardCodedLedgerHeader[1], toid.Generator{})
+	require.NoError(t, err)
+	assert.Equal(t, newMaxFee, output.NewMaxFee)
+	assert.Equal(t, newMaxFee, output.ToParquet().(TransactionOutputParquet).NewMaxFee)
+
+	marshalled, err := json.Marshal(output)
+	require.NoError(t, err)
+	var unmarshalled TransactionOutput
+	require.NoError(t, json.Unmarshal(marshalled, &unmarshalled))
+	assert.Equal(t, newMaxFee, unmarshalled.NewMaxFee)
+}
+
+func TestTransformTransactionToidLedgerOffset(t *testing.T) {
+	hardCodedTransaction, hardCodedLedgerHeader, err := makeTransactionTestInput()
+	require.NoError(t, err)
+
+	// the offset moves the id of the transaction, while its ledger sequence is left as it is
+	ledgerSeq := int32(hardCodedLedgerHeader[0].Header.LedgerSeq)
+	output, err := TransformTransaction(hardCodedTransaction[0], hardCodedLedgerHeader[0], toid.Generator{LedgerOffset: 100})
+	require.NoError(t, err)
+	assert.Equal(t, toid.New(ledgerSeq+100, int32(hardCodedTransaction[0].Index), 0).ToInt64(), output.TransactionID)
+	assert.Equal(t, uint32(ledgerSeq), output.LedgerSequence)
+}
+
+func TestSummarizeSorobanMeta(t *testing.T) {
+	returned := true
+	event := func(eventType xdr.ContractEventType) xdr.ContractEvent {
+		return xdr.ContractEvent{
+			Type: eventType,
+			Body: xdr.ContractEventBody{V: 0, V0: &xdr.ContractEventV0{Data: xdr.ScVal{Type: xdr.ScValTypeScvVoid}}},
+		}
+	}
+	meta := xdr.SorobanTransactionMeta{
+		Events:      []xdr.ContractEvent{event(xdr.ContractEventTypeContract), event(xdr.ContractEventTypeContract)},
+		ReturnValue: xdr.ScVal{Type: xdr.ScValTypeScvBool, B: &returned},
+		DiagnosticEvents: []xdr.DiagnosticEvent{
+			{InSuccessfulContractCall: true, Event: event(xdr.ContractEventTypeContract)},
+			{InSuccessfulContractCall: true, Event: event(xdr.ContractEventTypeDiagnostic)},
+			{InSuccessfulContractCall: true, Event: event(xdr.ContractEventTypeDiagnostic)},
+			{InSuccessfulContractCall: true, Event: event(xdr.ContractEventTypeDiagnostic)},
+		},
+	}
+
+	returnValue, contractEventsCount, diagnosticEventsCount, err := summarizeSorobanMeta(meta, true)
+	assert.NoError(t, err)
+	assert.Equal(t, null.StringFrom(`{"bool":true}`), returnValue)
+	assert.Equal(t, uint32(2), contractEventsCount)
+	assert.Equal(t, uint32(3), diagnosticEventsCount)
+
+	returnValue, _, _, err = summarizeSorobanMeta(meta, false)
+	assert.NoError(t, err)
+	assert.Equal(t, null.String{}, returnValue)
+}
+
+func TestTransactionSizeMetrics(t *testing.T) {
+	offer := func(amount xdr.Int64) *xdr.LedgerEntry {
+		return &xdr.LedgerEntry{
+			Data: xdr.LedgerEntryData{
+				Type:  xdr.LedgerEntryTypeOffer,
+				Offer: &xdr.OfferEntry{SellerId: testAccount1ID, OfferId: 1, Amount: amount},
+			},
+		}
+	}
+	transactions, historyHeaders, err := makeTransactionTestInput()
+	require.NoError(t, err)
+	transaction := transactions[0]
+	transaction.UnsafeMeta = xdr.TransactionMeta{
+		V: 2,
+		V2: &xdr.TransactionMetaV2{
+			Operations: []xdr.OperationMeta{
+				{Changes: xdr.LedgerEntryChanges{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryCreated, Created: offer(10)},
+				}},
+				{Changes: xdr.LedgerEntryChanges{
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryState, State: offer(10)},
+					{Type: xdr.LedgerEntryChangeTypeLedgerEntryUpdated, Updated: offer(5)},
+				}},
+			},
+		},
+	}
+
+	transformed, err := TransformTransaction(transaction, historyHeaders[0], toid.Generator{})
+	require.NoError(t, err)
+	output, err := TransactionSizeMetrics(transaction, transformed)
+	require.NoError(t, err)
+
+	envelope, err := transaction.Envelope.MarshalBinary()
+	require.NoError(t, err)
+	result, err := transaction.Result.Result.MarshalBinary()
+	require.NoError(t, err)
+	meta, err := transaction.UnsafeMeta.MarshalBinary()
+	require.NoError(t, err)
+	assert.Equal(t, null.IntFrom(int64(len(envelope))), output.EnvelopeSize)
+	assert.Equal(t, null.IntFrom(int64(len(result))), output.ResultSize)
+	assert.Equal(t, null.IntFrom(int64(len(meta))), output.MetaSize)
+	// the state and update of the same offer are a single change
+	assert.Equal(t, null.IntFrom(2), output.OperationChangesCount)
+}
+
 func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 	correctTime, err := time.Parse("2006-1-2 15:04:05 MST", "2020-07-09 05:28:42 UTC")
 	output = []TransactionOutput{
@@ -90,6 +203,7 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			TransactionHash:              "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
 			LedgerSequence:               30521816,
 			TransactionID:                131090201534533632,
+			PagingToken:                  "131090201534533632",
 			Account:                      testAccount1Address,
 			AccountSequence:              112351890582290871,
 			MaxFee:                       90000,
@@ -116,6 +230,7 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			TransactionHash:              "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
 			LedgerSequence:               30521817,
 			TransactionID:                131090205829500928,
+			PagingToken:                  "131090205829500928",
 			Account:                      testAccount1Address,
 			AccountSequence:              150015399398735997,
 			MaxFee:                       0,
@@ -146,6 +261,7 @@ func makeTransactionTestOutput() (output []TransactionOutput, err error) {
 			TransactionHash:              "a87fef5eeb260269c380f2de456aad72b59bb315aaac777860456e09dac0bafb",
 			LedgerSequence:               30521818,
 			TransactionID:                131090210124468224,
+			PagingToken:                  "131090210124468224",
 			Account:                      testAccount2Address,
 			AccountSequence:              118426953012574851,
 			MaxFee:                       100,
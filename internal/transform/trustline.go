@@ -1,13 +1,13 @@
 package transform
 
 import (
-	"encoding/base64"
 	"fmt"
 
 	"github.com/guregu/null"
 	"github.com/pkg/errors"
 
 	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/ledgerkey"
 
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/strkey"
@@ -15,11 +15,15 @@ import (
 )
 
 // TransformTrustline converts a trustline from the history archive ingestion system into a form suitable for BigQuery
-func TransformTrustline(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (TrustlineOutput, error) {
+func TransformTrustline(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ TrustlineOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return TrustlineOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return TrustlineOutput{}, err
+	}
 
 	trustEntry, ok := ledgerEntry.Data.GetTrustLine()
 	if !ok {
@@ -97,12 +101,7 @@ func trustLineEntryToLedgerKeyString(trustLine xdr.TrustLineEntry) (string, erro
 		return "", fmt.Errorf("error running ledgerKey.SetTrustline when calculating ledger key")
 	}
 
-	key, err := ledgerKey.MarshalBinary()
-	if err != nil {
-		return "", fmt.Errorf("error running MarshalBinaryCompress when calculating ledger key")
-	}
-
-	return base64.StdEncoding.EncodeToString(key), nil
+	return ledgerkey.Base64(*ledgerKey)
 
 }
 
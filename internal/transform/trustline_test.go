@@ -59,7 +59,7 @@ func TestTransformTrustline(t *testing.T) {
 			},
 		}
 		actualOutput, actualError := TransformTrustline(test.input.ingest, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -6,14 +6,19 @@ import (
 	"github.com/stellar/go/ingest"
 	"github.com/stellar/go/xdr"
 	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/ledgerkey"
 )
 
 // TransformTtl converts an ttl ledger change entry into a form suitable for BigQuery
-func TransformTtl(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (TtlOutput, error) {
+func TransformTtl(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntry) (_ TtlOutput, err error) {
+	defer wrapError(&err, changeCoordinates(ledgerChange, uint32(header.Header.LedgerSeq)))
 	ledgerEntry, changeType, outputDeleted, err := utils.ExtractEntryFromChange(ledgerChange)
 	if err != nil {
 		return TtlOutput{}, err
 	}
+	if err := checkLedgerEntryProtocol(ledgerEntry.Data.Type, uint32(header.Header.LedgerVersion)); err != nil {
+		return TtlOutput{}, err
+	}
 
 	ttl, ok := ledgerEntry.Data.GetTtl()
 	if !ok {
@@ -26,6 +31,18 @@ func TransformTtl(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntr
 	}
 
 	keyHash := ttl.KeyHash.HexString()
+	ledgerKey, err := ledgerEntry.LedgerKey()
+	if err != nil {
+		return TtlOutput{}, err
+	}
+	outputLedgerKey, err := ledgerkey.Base64(ledgerKey)
+	if err != nil {
+		return TtlOutput{}, err
+	}
+	outputLedgerKeyCanonical, err := ledgerkey.Canonical(ledgerKey)
+	if err != nil {
+		return TtlOutput{}, err
+	}
 	liveUntilLedgerSeq := ttl.LiveUntilLedgerSeq
 
 	closedAt, err := utils.TimePointToUTCTimeStamp(header.Header.ScpValue.CloseTime)
@@ -37,6 +54,8 @@ func TransformTtl(ledgerChange ingest.Change, header xdr.LedgerHeaderHistoryEntr
 
 	transformedPool := TtlOutput{
 		KeyHash:            keyHash,
+		LedgerKey:          outputLedgerKey,
+		LedgerKeyCanonical: outputLedgerKeyCanonical,
 		LiveUntilLedgerSeq: uint32(liveUntilLedgerSeq),
 		LastModifiedLedger: uint32(ledgerEntry.LastModifiedLedgerSeq),
 		LedgerEntryChange:  uint32(changeType),
@@ -49,11 +49,12 @@ func TestTransformTtl(t *testing.T) {
 				ScpValue: xdr.StellarValue{
 					CloseTime: 1000,
 				},
-				LedgerSeq: 10,
+				LedgerSeq:     10,
+				LedgerVersion: 22,
 			},
 		}
 		actualOutput, actualError := TransformTtl(test.input, header)
-		assert.Equal(t, test.wantErr, actualError)
+		assertTransformError(t, test.wantErr, actualError)
 		assert.Equal(t, test.wantOutput, actualOutput)
 	}
 }
@@ -96,6 +97,8 @@ func makeTtlTestOutput() []TtlOutput {
 	return []TtlOutput{
 		{
 			KeyHash:            "0000000000000000000000000000000000000000000000000000000000000000",
+			LedgerKey:          "AAAACQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA",
+			LedgerKeyCanonical: "ttl:0000000000000000000000000000000000000000000000000000000000000000",
 			LiveUntilLedgerSeq: 123,
 			LastModifiedLedger: 1,
 			LedgerEntryChange:  1,
@@ -0,0 +1,139 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/support/log"
+	"github.com/stellar/go/xdr"
+)
+
+// autoBackend reads ledgers from the datastore where it has them, since that is much cheaper than replaying them,
+// and from captive core for the most recent ledgers that are not exported to the datastore yet. The split between
+// the two is found when the range is prepared, so that a range that straddles the end of the datastore is exported
+// as a single range.
+type autoBackend struct {
+	archive   ledgerbackend.LedgerBackend
+	head      ledgerbackend.LedgerBackend
+	newHead   func() (ledgerbackend.LedgerBackend, error)
+	findStart func(ctx context.Context, start, end uint32) (uint32, bool, error)
+	// split is the first ledger read from captive core, or 0 when the datastore has the whole range
+	split uint32
+}
+
+// newAutoBackend creates a backend that picks between the datastore backend and captive core for each ledger
+func newAutoBackend(ctx context.Context, env EnvironmentDetails, policy RetryPolicy) (ledgerbackend.LedgerBackend, error) {
+	dataStore, err := CreateDatastore(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+
+	archiveClient, err := CreateHistoryArchiveClient(env.ArchiveURLs)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := WithRetries(env.newDatastoreBackendFunc(dataStore), "datastore", policy)
+	if err != nil {
+		return nil, err
+	}
+
+	resumableManager := datastore.NewResumableManager(dataStore, dataStore.GetSchema(), archiveClient)
+	return &autoBackend{
+		archive: archive,
+		newHead: func() (ledgerbackend.LedgerBackend, error) {
+			backend, err := WithRetries(env.newCaptiveCoreBackend, "captive-core", policy)
+			if err != nil {
+				return nil, fmt.Errorf("could not create captive core for the ledgers missing from the datastore: %v", err)
+			}
+			return backend, nil
+		},
+		findStart: resumableManager.FindStart,
+	}, nil
+}
+
+// PrepareRange finds the first ledger of the range missing from the datastore and prepares the datastore for the
+// ledgers before it and captive core for the ones from it onwards
+func (b *autoBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	absent, ok, err := b.findStart(ctx, ledgerRange.From(), ledgerRange.To())
+	if err != nil {
+		return fmt.Errorf("could not find the end of the datastore: %v", err)
+	}
+	if !ok {
+		b.split = 0
+		log.Infof("reading ledgers %s from the datastore", ledgerRange)
+		return b.archive.PrepareRange(ctx, ledgerRange)
+	}
+
+	b.split = absent
+	if absent > ledgerRange.From() {
+		archiveRange := ledgerbackend.BoundedRange(ledgerRange.From(), absent-1)
+		log.Infof("reading ledgers %s from the datastore", archiveRange)
+		if err := b.archive.PrepareRange(ctx, archiveRange); err != nil {
+			return err
+		}
+	}
+
+	headRange := ledgerbackend.UnboundedRange(absent)
+	if ledgerRange.Bounded() {
+		headRange = ledgerbackend.BoundedRange(absent, ledgerRange.To())
+	}
+	if b.head == nil {
+		if b.head, err = b.newHead(); err != nil {
+			return err
+		}
+	}
+	log.Infof("reading ledgers %s from captive core", headRange)
+	return b.head.PrepareRange(ctx, headRange)
+}
+
+func (b *autoBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	if b.split != 0 && sequence >= b.split {
+		return b.head.GetLedger(ctx, sequence)
+	}
+	return b.archive.GetLedger(ctx, sequence)
+}
+
+func (b *autoBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	if b.split != 0 {
+		return b.head.GetLatestLedgerSequence(ctx)
+	}
+	return b.archive.GetLatestLedgerSequence(ctx)
+}
+
+func (b *autoBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	if b.split == 0 {
+		return b.archive.IsPrepared(ctx, ledgerRange)
+	}
+
+	if ledgerRange.From() < b.split {
+		to := b.split - 1
+		if ledgerRange.Bounded() {
+			to = min(to, ledgerRange.To())
+		}
+		prepared, err := b.archive.IsPrepared(ctx, ledgerbackend.BoundedRange(ledgerRange.From(), to))
+		if err != nil || !prepared {
+			return false, err
+		}
+	}
+
+	from := max(ledgerRange.From(), b.split)
+	if !ledgerRange.Bounded() {
+		return b.head.IsPrepared(ctx, ledgerbackend.UnboundedRange(from))
+	}
+	if ledgerRange.To() < from {
+		return true, nil
+	}
+	return b.head.IsPrepared(ctx, ledgerbackend.BoundedRange(from, ledgerRange.To()))
+}
+
+func (b *autoBackend) Close() error {
+	err := b.archive.Close()
+	if b.head != nil {
+		err = errors.Join(err, b.head.Close())
+	}
+	return err
+}
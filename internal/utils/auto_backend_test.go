@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func autoTestLedger(sequence uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 0,
+		V0: &xdr.LedgerCloseMetaV0{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{LedgerSeq: xdr.Uint32(sequence)}},
+		},
+	}
+}
+
+// newAutoTestBackend returns an auto backend over mocks, whose datastore has the ledgers before absent, or every
+// ledger when absent is 0
+func newAutoTestBackend(archive, head *ledgerbackend.MockDatabaseBackend, absent uint32) (*autoBackend, *int) {
+	heads := 0
+	return &autoBackend{
+		archive: archive,
+		newHead: func() (ledgerbackend.LedgerBackend, error) {
+			heads++
+			return head, nil
+		},
+		findStart: func(ctx context.Context, start, end uint32) (uint32, bool, error) {
+			if absent == 0 || (end != 0 && absent > end) {
+				return 0, false, nil
+			}
+			return max(absent, start), true, nil
+		},
+	}, &heads
+}
+
+func TestAutoBackendWholeRangeInDatastore(t *testing.T) {
+	ctx := context.Background()
+	archive := &ledgerbackend.MockDatabaseBackend{}
+	archive.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 20)).Return(nil).Once()
+	archive.On("GetLedger", mock.Anything, uint32(20)).Return(autoTestLedger(20), nil).Once()
+	archive.On("GetLatestLedgerSequence", mock.Anything).Return(uint32(20), nil).Once()
+	archive.On("IsPrepared", mock.Anything, ledgerbackend.BoundedRange(12, 20)).Return(true, nil).Once()
+	archive.On("Close").Return(nil).Once()
+
+	backend, heads := newAutoTestBackend(archive, nil, 0)
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)))
+	lcm, err := backend.GetLedger(ctx, 20)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(20), lcm.LedgerSequence())
+	latest, err := backend.GetLatestLedgerSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(20), latest)
+	prepared, err := backend.IsPrepared(ctx, ledgerbackend.BoundedRange(12, 20))
+	require.NoError(t, err)
+	assert.True(t, prepared)
+	require.NoError(t, backend.Close())
+
+	// captive core is only started for the ledgers missing from the datastore
+	assert.Equal(t, 0, *heads)
+	archive.AssertExpectations(t)
+}
+
+func TestAutoBackendSplitsRange(t *testing.T) {
+	ctx := context.Background()
+	archive := &ledgerbackend.MockDatabaseBackend{}
+	archive.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 14)).Return(nil).Once()
+	archive.On("GetLedger", mock.Anything, uint32(14)).Return(autoTestLedger(14), nil).Once()
+	archive.On("IsPrepared", mock.Anything, ledgerbackend.BoundedRange(12, 14)).Return(true, nil).Once()
+	archive.On("Close").Return(nil).Once()
+
+	head := &ledgerbackend.MockDatabaseBackend{}
+	head.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(15, 20)).Return(nil).Once()
+	head.On("GetLedger", mock.Anything, uint32(15)).Return(autoTestLedger(15), nil).Once()
+	head.On("GetLatestLedgerSequence", mock.Anything).Return(uint32(15), nil).Once()
+	head.On("IsPrepared", mock.Anything, ledgerbackend.BoundedRange(15, 18)).Return(true, nil).Once()
+	head.On("Close").Return(nil).Once()
+
+	backend, heads := newAutoTestBackend(archive, head, 15)
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)))
+	assert.Equal(t, uint32(15), backend.split)
+
+	// the last ledger of the datastore and the first one missing from it are read from each backend
+	lcm, err := backend.GetLedger(ctx, 14)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(14), lcm.LedgerSequence())
+	lcm, err = backend.GetLedger(ctx, 15)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(15), lcm.LedgerSequence())
+
+	latest, err := backend.GetLatestLedgerSequence(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(15), latest)
+
+	// a range straddling the split is prepared when both of its parts are
+	prepared, err := backend.IsPrepared(ctx, ledgerbackend.BoundedRange(12, 18))
+	require.NoError(t, err)
+	assert.True(t, prepared)
+
+	require.NoError(t, backend.Close())
+	assert.Equal(t, 1, *heads)
+	archive.AssertExpectations(t)
+	head.AssertExpectations(t)
+}
+
+func TestAutoBackendRangeMissingFromDatastore(t *testing.T) {
+	ctx := context.Background()
+	archive := &ledgerbackend.MockDatabaseBackend{}
+	head := &ledgerbackend.MockDatabaseBackend{}
+	head.On("PrepareRange", mock.Anything, ledgerbackend.UnboundedRange(30)).Return(nil).Once()
+	head.On("IsPrepared", mock.Anything, ledgerbackend.UnboundedRange(31)).Return(true, nil).Once()
+
+	// the datastore is not prepared when it has none of the ledgers of the range
+	backend, heads := newAutoTestBackend(archive, head, 15)
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.UnboundedRange(30)))
+	prepared, err := backend.IsPrepared(ctx, ledgerbackend.UnboundedRange(31))
+	require.NoError(t, err)
+	assert.True(t, prepared)
+	assert.Equal(t, 1, *heads)
+	archive.AssertExpectations(t)
+	head.AssertExpectations(t)
+}
+
+func TestAutoBackendErrors(t *testing.T) {
+	ctx := context.Background()
+	backend := &autoBackend{
+		findStart: func(ctx context.Context, start, end uint32) (uint32, bool, error) {
+			return 0, false, errors.New("could not list the datastore")
+		},
+	}
+	assert.EqualError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)), "could not find the end of the datastore: could not list the datastore")
+
+	archive := &ledgerbackend.MockDatabaseBackend{}
+	archive.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 14)).Return(nil).Once()
+	backend, _ = newAutoTestBackend(archive, nil, 15)
+	backend.newHead = func() (ledgerbackend.LedgerBackend, error) {
+		return nil, errors.New("no captive core")
+	}
+	assert.EqualError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 20)), "no captive core")
+	archive.AssertExpectations(t)
+}
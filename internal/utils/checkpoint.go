@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Checkpoint is the last ledger written by an export, from which a stopped export is resumed
+type Checkpoint struct {
+	Network    string    `json:"network"`
+	LastLedger uint32    `json:"last_ledger"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// WriteCheckpoint writes the checkpoint to path, through a temporary file so that a stopped export never leaves a
+// partial checkpoint behind
+func WriteCheckpoint(path string, checkpoint Checkpoint) error {
+	contents, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("could not create the checkpoint directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("could not write the checkpoint: %v", err)
+	}
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write the checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write the checkpoint: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("could not write the checkpoint: %v", err)
+	}
+	return nil
+}
+
+// ReadCheckpoint reads the checkpoint written to path
+func ReadCheckpoint(path string) (Checkpoint, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("could not read the checkpoint: %v", err)
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(contents, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("could not parse the checkpoint %s: %v", path, err)
+	}
+	return checkpoint, nil
+}
+
+// NetworkCheckpointPath is the checkpoint file of one of the networks of a multi-network export, such as
+// checkpoint-testnet.json for checkpoint.json
+func NetworkCheckpointPath(path, network string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + network + ext
+}
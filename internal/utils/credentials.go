@@ -0,0 +1,167 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+)
+
+// CloudCredentialsEnv is the environment variable that can hold either the path to the cloud credentials file
+// or the credentials json itself, so that secrets do not need to be passed as flags
+const CloudCredentialsEnv = "STELLAR_ETL_CLOUD_CREDENTIALS"
+
+// AWSCredentialsEnv holds either the path to an AWS shared credentials file or the credentials json, with
+// access_key_id, secret_access_key and session_token
+const AWSCredentialsEnv = "STELLAR_ETL_AWS_CREDENTIALS"
+
+const (
+	CredentialsSourceFlag    = "flag"
+	CredentialsSourceEnv     = "env"
+	CredentialsSourceDefault = "default"
+)
+
+// CloudCredentials are the credentials used to access cloud storage and the other sinks. When neither a file nor
+// json is set, the provider's default credentials are used, such as workload identity or
+// GOOGLE_APPLICATION_CREDENTIALS.
+type CloudCredentials struct {
+	Source string
+	File   string
+	JSON   []byte
+}
+
+// ResolveCloudCredentials picks the Google credentials to use for GCS, Pub/Sub and BigQuery. The cloud-credentials
+// flag takes precedence over the environment, which takes precedence over the provider defaults.
+func ResolveCloudCredentials(credentialsPath string) (CloudCredentials, error) {
+	return resolveCredentials(credentialsPath, CloudCredentialsEnv)
+}
+
+// ResolveAWSCredentials picks the AWS credentials to use for S3 and SQS from the environment. Without them, the
+// default chain of the AWS SDK is used, such as the AWS_* variables, the shared config or web identity.
+func ResolveAWSCredentials() (CloudCredentials, error) {
+	return resolveCredentials("", AWSCredentialsEnv)
+}
+
+// resolveCredentials reads the credentials from the file of a flag, or else from an environment variable holding
+// either a file path or the credentials json
+func resolveCredentials(credentialsPath, env string) (CloudCredentials, error) {
+	if credentialsPath != "" {
+		if _, err := os.Stat(credentialsPath); err != nil {
+			return CloudCredentials{}, fmt.Errorf("could not read credentials file %s: %v", credentialsPath, err)
+		}
+		return CloudCredentials{Source: CredentialsSourceFlag, File: credentialsPath}, nil
+	}
+
+	value := strings.TrimSpace(os.Getenv(env))
+	if value == "" {
+		return CloudCredentials{Source: CredentialsSourceDefault}, nil
+	}
+
+	if strings.HasPrefix(value, "{") {
+		return CloudCredentials{Source: CredentialsSourceEnv, JSON: []byte(value)}, nil
+	}
+
+	if _, err := os.Stat(value); err != nil {
+		return CloudCredentials{}, fmt.Errorf("could not read credentials file from %s: %v", env, err)
+	}
+	return CloudCredentials{Source: CredentialsSourceEnv, File: value}, nil
+}
+
+// GoogleClientOptions are the options of the Google clients using the credentials, or none for the defaults
+func (c CloudCredentials) GoogleClientOptions() []option.ClientOption {
+	switch {
+	case c.File != "":
+		return []option.ClientOption{option.WithCredentialsFile(c.File)}
+	case len(c.JSON) > 0:
+		return []option.ClientOption{option.WithCredentialsJSON(c.JSON)}
+	default:
+		return nil
+	}
+}
+
+// awsCredentialsJSON is the json form of AWS credentials
+type awsCredentialsJSON struct {
+	AccessKeyID     string `json:"access_key_id"`
+	SecretAccessKey string `json:"secret_access_key"`
+	SessionToken    string `json:"session_token"`
+}
+
+// AWSCredentials returns the AWS credentials, read from a shared credentials file with the AWS_PROFILE profile or
+// from json. It returns nil for the default chain of the AWS SDK.
+func (c CloudCredentials) AWSCredentials() (*credentials.Credentials, error) {
+	switch {
+	case c.File != "":
+		return credentials.NewSharedCredentials(c.File, os.Getenv("AWS_PROFILE")), nil
+	case len(c.JSON) > 0:
+		var values awsCredentialsJSON
+		if err := json.Unmarshal(c.JSON, &values); err != nil {
+			return nil, fmt.Errorf("could not parse the aws credentials: %v", err)
+		}
+		if values.AccessKeyID == "" || values.SecretAccessKey == "" {
+			return nil, errors.New("the aws credentials need an access_key_id and a secret_access_key")
+		}
+		return credentials.NewStaticCredentials(values.AccessKeyID, values.SecretAccessKey, values.SessionToken), nil
+	default:
+		return nil, nil
+	}
+}
+
+// String describes where the credentials come from without revealing them
+func (c CloudCredentials) String() string {
+	switch {
+	case c.File != "":
+		return fmt.Sprintf("credentials file %s (from %s)", c.File, c.Source)
+	case len(c.JSON) > 0:
+		return fmt.Sprintf("inline credentials (from %s)", c.Source)
+	default:
+		return "default credentials"
+	}
+}
+
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`),
+	regexp.MustCompile(`("(?:private_key|private_key_id|client_secret|refresh_token|secret_access_key|session_token|password)"\s*:\s*)"[^"]*"`),
+}
+
+// RedactSecrets replaces private keys and other credential values in a string
+func RedactSecrets(s string) string {
+	s = secretPatterns[0].ReplaceAllString(s, "[REDACTED]")
+	return secretPatterns[1].ReplaceAllString(s, `$1"[REDACTED]"`)
+}
+
+// redactHook removes secrets from log messages and their fields before they are written
+type redactHook struct{}
+
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (redactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = RedactSecrets(entry.Message)
+
+	// The fields are copied, since the map may be shared with the logger the entry was made from
+	data := make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			value = RedactSecrets(v)
+		case error:
+			if redacted := RedactSecrets(v.Error()); redacted != v.Error() {
+				value = redacted
+			}
+		case fmt.Stringer:
+			if redacted := RedactSecrets(v.String()); redacted != v.String() {
+				value = redacted
+			}
+		}
+		data[key] = value
+	}
+	entry.Data = data
+	return nil
+}
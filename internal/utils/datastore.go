@@ -0,0 +1,250 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/stellar/go/support/datastore"
+)
+
+// errReadOnlyDataStore is returned by the datastores that only read ledgers when a file is written to them
+var errReadOnlyDataStore = errors.New("the datastore is read only")
+
+// requesterPaysDataStore reads the ledgers of a GCS bucket billing the reads to a project of the reader, which
+// requester pays buckets require. The datastore of stellar/go does not expose its bucket handle, so the user project
+// cannot be set on it.
+type requesterPaysDataStore struct {
+	client *storage.Client
+	bucket *storage.BucketHandle
+	prefix string
+	schema datastore.DataStoreSchema
+}
+
+func newRequesterPaysDataStore(ctx context.Context, bucketPath, billingProject string, schema datastore.DataStoreSchema) (datastore.DataStore, error) {
+	client, err := storage.NewClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bucketName, prefix, _ := strings.Cut(strings.Trim(bucketPath, "/"), "/")
+	bucket := client.Bucket(bucketName).UserProject(billingProject)
+	if _, err := bucket.Attrs(ctx); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to retrieve attributes of bucket %s billed to project %s: %w", bucketName, billingProject, err)
+	}
+
+	return &requesterPaysDataStore{client: client, bucket: bucket, prefix: prefix, schema: schema}, nil
+}
+
+func (s *requesterPaysDataStore) attrs(ctx context.Context, filePath string) (*storage.ObjectAttrs, error) {
+	attrs, err := s.bucket.Object(path.Join(s.prefix, filePath)).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, os.ErrNotExist
+	}
+	return attrs, err
+}
+
+func (s *requesterPaysDataStore) GetFileMetadata(ctx context.Context, filePath string) (map[string]string, error) {
+	attrs, err := s.attrs(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return attrs.Metadata, nil
+}
+
+func (s *requesterPaysDataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	filePath = path.Join(s.prefix, filePath)
+	// reading compressed files as they are makes the reader validate their CRC, as in the datastore of stellar/go
+	r, err := s.bucket.Object(filePath).ReadCompressed(true).NewReader(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, os.ErrNotExist
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving file %s: %w", filePath, withoutURLQuery(err))
+	}
+	return r, nil
+}
+
+func (s *requesterPaysDataStore) Size(ctx context.Context, filePath string) (int64, error) {
+	attrs, err := s.attrs(ctx, filePath)
+	if err != nil {
+		return 0, err
+	}
+	return attrs.Size, nil
+}
+
+func (s *requesterPaysDataStore) Exists(ctx context.Context, filePath string) (bool, error) {
+	return exists(s.Size(ctx, filePath))
+}
+
+func (s *requesterPaysDataStore) PutFile(context.Context, string, io.WriterTo, map[string]string) error {
+	return errReadOnlyDataStore
+}
+
+func (s *requesterPaysDataStore) PutFileIfNotExists(context.Context, string, io.WriterTo, map[string]string) (bool, error) {
+	return false, errReadOnlyDataStore
+}
+
+func (s *requesterPaysDataStore) GetSchema() datastore.DataStoreSchema {
+	return s.schema
+}
+
+func (s *requesterPaysDataStore) Close() error {
+	return s.client.Close()
+}
+
+// urlDataStore reads the ledgers over plain HTTP(S) from the files under a base URL, such as the public URL of a
+// bucket or a URL prefix signed by a CDN. The query string of the base URL, which holds the signature of signed URL
+// prefixes, is kept on the URL of every file, so no credentials or bucket access grants are needed.
+type urlDataStore struct {
+	client  *http.Client
+	baseURL *url.URL
+	prefix  string
+	schema  datastore.DataStoreSchema
+}
+
+func newURLDataStore(baseURL, prefix string, schema datastore.DataStoreSchema) (datastore.DataStore, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid datastore url: %v", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return nil, fmt.Errorf("invalid datastore url %s: the scheme must be http or https", parsed.Redacted())
+	}
+	return &urlDataStore{client: http.DefaultClient, baseURL: parsed, prefix: prefix, schema: schema}, nil
+}
+
+// fileURL returns the URL of a file under the prefix of the base URL, with the query string of the base URL
+func (s *urlDataStore) fileURL(filePath string) string {
+	fileURL := *s.baseURL
+	fileURL.Path = path.Join("/", s.baseURL.Path, s.prefix, filePath)
+	fileURL.RawPath = ""
+	return fileURL.String()
+}
+
+func (s *urlDataStore) do(ctx context.Context, method, filePath string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.fileURL(filePath), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error retrieving file %s: %w", filePath, withoutURLQuery(err))
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, os.ErrNotExist
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("error retrieving file %s: %s", filePath, resp.Status)
+	}
+	return resp, nil
+}
+
+// GetFileMetadata returns the custom metadata of a file, which GCS and S3 return as x-goog-meta- and x-amz-meta-
+// headers
+func (s *urlDataStore) GetFileMetadata(ctx context.Context, filePath string) (map[string]string, error) {
+	resp, err := s.do(ctx, http.MethodHead, filePath)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+
+	metadata := map[string]string{}
+	for key := range resp.Header {
+		lower := strings.ToLower(key)
+		for _, prefix := range []string{"x-goog-meta-", "x-amz-meta-"} {
+			if strings.HasPrefix(lower, prefix) {
+				metadata[strings.TrimPrefix(lower, prefix)] = resp.Header.Get(key)
+			}
+		}
+	}
+	return metadata, nil
+}
+
+func (s *urlDataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, filePath)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *urlDataStore) Size(ctx context.Context, filePath string) (int64, error) {
+	resp, err := s.do(ctx, http.MethodHead, filePath)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	if resp.ContentLength < 0 {
+		return 0, fmt.Errorf("unknown size of file %s", filePath)
+	}
+	return resp.ContentLength, nil
+}
+
+func (s *urlDataStore) Exists(ctx context.Context, filePath string) (bool, error) {
+	return exists(s.Size(ctx, filePath))
+}
+
+func (s *urlDataStore) PutFile(context.Context, string, io.WriterTo, map[string]string) error {
+	return errReadOnlyDataStore
+}
+
+func (s *urlDataStore) PutFileIfNotExists(context.Context, string, io.WriterTo, map[string]string) (bool, error) {
+	return false, errReadOnlyDataStore
+}
+
+func (s *urlDataStore) GetSchema() datastore.DataStoreSchema {
+	return s.schema
+}
+
+func (s *urlDataStore) Close() error {
+	return nil
+}
+
+// withoutURLQuery strips the query string from the URL of a *url.Error, so that the signature of a signed URL is
+// not logged with the error. The errors wrapping it have their messages formatted already, so the URL is also
+// replaced in the message.
+func withoutURLQuery(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	withQuery := urlErr.URL
+	urlErr.URL, _, _ = strings.Cut(withQuery, "?")
+	if urlErr.URL == withQuery {
+		return err
+	}
+	return &redactedURLError{error: err, message: strings.ReplaceAll(err.Error(), withQuery, urlErr.URL)}
+}
+
+// redactedURLError is an error whose message has the URLs of withoutURLQuery stripped
+type redactedURLError struct {
+	error
+	message string
+}
+
+func (e *redactedURLError) Error() string {
+	return e.message
+}
+
+func (e *redactedURLError) Unwrap() error {
+	return e.error
+}
+
+// exists turns the result of a size lookup into whether the file exists
+func exists(_ int64, err error) (bool, error) {
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stellar/go/support/datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURLDataStore(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the query string of the base URL is kept on every file
+		if r.URL.Query().Get("sig") != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/ledgers/pubnet/FFFFFFFF--0-9/FFFFFFFF--0.xdr.zstd":
+			w.Header().Set("x-goog-meta-ledger-start", "0")
+			w.Header().Set("Content-Length", "5")
+			if r.Method == http.MethodGet {
+				w.Write([]byte("ledgr"))
+			}
+		case "/ledgers/pubnet/unsized":
+			// a HEAD response without a Content-Length
+			w.WriteHeader(http.StatusOK)
+		case "/ledgers/pubnet/failing":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	store, err := newURLDataStore(server.URL+"/ledgers?sig=abc", "pubnet", datastore.DataStoreSchema{LedgersPerFile: 1, FilesPerPartition: 10})
+	require.NoError(t, err)
+	defer store.Close()
+
+	file := "FFFFFFFF--0-9/FFFFFFFF--0.xdr.zstd"
+	reader, err := store.GetFile(ctx, file)
+	require.NoError(t, err)
+	contents, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	reader.Close()
+	assert.Equal(t, "ledgr", string(contents))
+
+	metadata, err := store.GetFileMetadata(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"ledger-start": "0"}, metadata)
+
+	size, err := store.Size(ctx, file)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), size)
+	_, err = store.Size(ctx, "unsized")
+	assert.EqualError(t, err, "unknown size of file unsized")
+
+	ok, err := store.Exists(ctx, file)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = store.Exists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = store.GetFile(ctx, "missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = store.GetFile(ctx, "failing")
+	assert.EqualError(t, err, "error retrieving file failing: 503 Service Unavailable")
+	assert.ErrorIs(t, store.PutFile(ctx, file, nil, nil), errReadOnlyDataStore)
+}
+
+func TestURLDataStoreErrorsLeaveOutQuery(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	baseURL := server.URL + "/ledgers?sig=abc"
+	server.Close()
+
+	store, err := newURLDataStore(baseURL, "pubnet", datastore.DataStoreSchema{LedgersPerFile: 1, FilesPerPartition: 10})
+	require.NoError(t, err)
+	_, err = store.GetFile(context.Background(), "file.xdr.zstd")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), server.URL+"/ledgers/pubnet/file.xdr.zstd")
+	assert.NotContains(t, err.Error(), "sig=abc")
+
+	// the errors of the GCS client are wrapped around the same *url.Error
+	err = withoutURLQuery(fmt.Errorf("reading object: %w", &url.Error{Op: "Get", URL: "https://storage.googleapis.com/ledgers/file?userProject=etl", Err: io.EOF}))
+	assert.EqualError(t, err, `reading object: Get "https://storage.googleapis.com/ledgers/file": EOF`)
+	assert.ErrorIs(t, err, io.EOF)
+}
@@ -0,0 +1,219 @@
+package utils
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/support/log"
+)
+
+// ledgerCacheSuffix is the extension of the files of the ledger cache, so that the temporary files being written and
+// any other file of the directory are left alone
+const ledgerCacheSuffix = ".lcm"
+
+// ledgerCacheEntry is a file of the ledger cache
+type ledgerCacheEntry struct {
+	name string
+	size int64
+}
+
+// cachingDataStore keeps the files read from a datastore in a directory of the local disk, so that the commands
+// exporting the same ledgers, or the same command run again, do not download them again. The least recently read
+// files are removed once the cache holds more than its maximum size.
+type cachingDataStore struct {
+	datastore.DataStore
+	dir string
+	// namespace tells apart the files of different datastores and networks sharing the directory
+	namespace string
+	maxBytes  int64
+
+	mu      sync.Mutex
+	size    int64
+	recent  *list.List
+	entries map[string]*list.Element
+}
+
+// WithLedgerCache wraps a datastore so that the files read from it are cached in dir, up to maxBytes. The files of
+// the directory are kept in the cache by the time they were last read, so the cache can be shared by successive runs
+// and by commands run at once. The namespace identifies the datastore the files are read from.
+func WithLedgerCache(store datastore.DataStore, dir, namespace string, maxBytes int64) (datastore.DataStore, error) {
+	if maxBytes <= 0 {
+		return nil, errors.New("the size of the ledger cache must be positive")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("could not create the ledger cache directory: %v", err)
+	}
+
+	s := &cachingDataStore{
+		DataStore: store,
+		dir:       dir,
+		namespace: namespace,
+		maxBytes:  maxBytes,
+		recent:    list.New(),
+		entries:   map[string]*list.Element{},
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load adds the files already in the directory to the cache from the least to the most recently read
+func (s *cachingDataStore) load() error {
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return fmt.Errorf("could not read the ledger cache directory: %v", err)
+	}
+
+	type cachedFile struct {
+		ledgerCacheEntry
+		readAt time.Time
+	}
+	files := []cachedFile{}
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ledgerCacheSuffix) {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("could not read the ledger cache directory: %v", err)
+		}
+		files = append(files, cachedFile{ledgerCacheEntry{name: dirEntry.Name(), size: info.Size()}, info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].readAt.Before(files[j].readAt) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, file := range files {
+		s.entries[file.name] = s.recent.PushFront(file.ledgerCacheEntry)
+		s.size += file.size
+	}
+	s.evict()
+	return nil
+}
+
+// fileName returns the name of the cached copy of a file of the datastore
+func (s *cachingDataStore) fileName(filePath string) string {
+	sum := sha256.Sum256([]byte(s.namespace + "/" + filePath))
+	return hex.EncodeToString(sum[:]) + ledgerCacheSuffix
+}
+
+func (s *cachingDataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	name := s.fileName(filePath)
+	if reader, ok := s.open(name); ok {
+		recordLedgerCacheRead(ctx, "hit")
+		return reader, nil
+	}
+	recordLedgerCacheRead(ctx, "miss")
+
+	reader, err := s.DataStore.GetFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading file %s: %w", filePath, err)
+	}
+	// the file was read all the same, so failing to cache it only costs another download later
+	if err := s.store(name, contents); err != nil {
+		log.Warnf("could not cache file %s: %v", filePath, err)
+	}
+	return io.NopCloser(bytes.NewReader(contents)), nil
+}
+
+// open opens a cached file and marks it as the most recently read
+func (s *cachingDataStore) open(name string) (io.ReadCloser, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[name]
+	if !ok {
+		return nil, false
+	}
+
+	path := filepath.Join(s.dir, name)
+	file, err := os.Open(path)
+	if err != nil {
+		// the file was removed by another process sharing the directory
+		s.remove(element)
+		return nil, false
+	}
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	s.recent.MoveToFront(element)
+	return file, true
+}
+
+// store writes a file to the cache, through a temporary file so that no partial file is ever read, and removes the
+// least recently read files beyond the maximum size
+func (s *cachingDataStore) store(name string, contents []byte) error {
+	if int64(len(contents)) > s.maxBytes {
+		return nil
+	}
+	tmp, err := os.CreateTemp(s.dir, name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(contents); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(s.dir, name)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if element, ok := s.entries[name]; ok {
+		s.size -= element.Value.(ledgerCacheEntry).size
+		s.recent.Remove(element)
+	}
+	s.entries[name] = s.recent.PushFront(ledgerCacheEntry{name: name, size: int64(len(contents))})
+	s.size += int64(len(contents))
+	s.evict()
+	return nil
+}
+
+// evict removes the least recently read files until the cache fits its maximum size. It is called with the lock held.
+func (s *cachingDataStore) evict() {
+	for s.size > s.maxBytes {
+		oldest := s.recent.Back()
+		if oldest == nil {
+			return
+		}
+		os.Remove(filepath.Join(s.dir, oldest.Value.(ledgerCacheEntry).name))
+		s.remove(oldest)
+	}
+}
+
+// remove drops an entry of the cache. It is called with the lock held.
+func (s *cachingDataStore) remove(element *list.Element) {
+	entry := element.Value.(ledgerCacheEntry)
+	s.size -= entry.size
+	s.recent.Remove(element)
+	delete(s.entries, entry.name)
+}
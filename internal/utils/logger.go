@@ -1,6 +1,33 @@
 package utils
 
-import "github.com/stellar/go/support/log"
+import (
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stellar/go/support/log"
+)
+
+// Field names shared by the structured log lines
+const (
+	LogFieldNetwork     = "network"
+	LogFieldLedger      = "ledger"
+	LogFieldLedgerStart = "ledger_start"
+	LogFieldLedgerEnd   = "ledger_end"
+	LogFieldTxHash      = "tx_hash"
+	LogFieldTable       = "table"
+	LogFieldRows        = "rows"
+	LogFieldDurationMs  = "duration_ms"
+)
+
+// RowLogFields returns the log fields of the rows of a table read from a ledger and, when the hash is set, from a
+// transaction of the ledger
+func RowLogFields(table string, ledger uint32, txHash string) log.F {
+	fields := log.F{LogFieldTable: table, LogFieldLedger: ledger}
+	if txHash != "" {
+		fields[LogFieldTxHash] = txHash
+	}
+	return fields
+}
 
 type EtlLogger struct {
 	*log.Entry
@@ -8,16 +35,41 @@ type EtlLogger struct {
 }
 
 func NewEtlLogger() *EtlLogger {
-	return &EtlLogger{
+	logger := &EtlLogger{
 		log.New(),
 		false,
 	}
+	logger.AddHook(redactHook{})
+	return logger
+}
+
+// Configure sets the log level and switches to json output when the format is json
+func (l *EtlLogger) Configure(level logrus.Level, format string) {
+	l.SetLevel(level)
+	if format == "json" {
+		l.UseJSONFormatter()
+	}
 }
 
+// WithFields returns a logger that adds the fields to every line it writes
+func (l *EtlLogger) WithFields(fields log.F) *EtlLogger {
+	return &EtlLogger{
+		Entry:        l.Entry.WithFields(fields),
+		StrictExport: l.StrictExport,
+	}
+}
+
+// LogError logs an error, or stops the program fatally if the export is strict. Errors that carry log fields, as
+// transform errors do with their code and coordinates, are logged with them.
 func (l *EtlLogger) LogError(err error) {
+	entry := l.Entry
+	var fielded interface{ LogFields() log.F }
+	if errors.As(err, &fielded) {
+		entry = entry.WithFields(fielded.LogFields())
+	}
 	if l.StrictExport {
-		l.Fatal(err)
+		entry.Fatal(err)
 	} else {
-		l.Error(err)
+		entry.Error(err)
 	}
 }
@@ -6,9 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"os"
+	"runtime"
+	"strings"
 	"time"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
 
 	"github.com/stellar/go/hash"
 	"github.com/stellar/go/historyarchive"
@@ -16,10 +21,13 @@ import (
 	"github.com/stellar/go/ingest/ledgerbackend"
 	"github.com/stellar/go/keypair"
 	"github.com/stellar/go/network"
+	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/support/datastore"
 	"github.com/stellar/go/support/storage"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
 )
 
 // PanicOnError is a function that panics if the provided error is not nil
@@ -86,6 +94,44 @@ func ConvertStroopValueToReal(input xdr.Int64) float64 {
 	return output
 }
 
+// priceDecimalScale is the number of decimal places of the prices that have no finite decimal representation
+const priceDecimalScale = 18
+
+// ConvertPriceToDecimal converts a price fraction into a decimal string without going through a float. Fractions
+// with a finite decimal representation are exact; the others, such as 1/3, are rounded to 18 decimal places. The
+// string is empty if the denominator is 0.
+func ConvertPriceToDecimal(n, d int64) string {
+	if d == 0 {
+		return ""
+	}
+
+	price := big.NewRat(n, d)
+	// A reduced fraction has a finite decimal representation when its denominator only has the factors 2 and 5
+	denominator := new(big.Int).Set(price.Denom())
+	scale := 0
+	for _, factor := range []int64{2, 5} {
+		count := 0
+		divisor := big.NewInt(factor)
+		remainder := new(big.Int)
+		for {
+			quotient, r := new(big.Int).QuoRem(denominator, divisor, remainder)
+			if r.Sign() != 0 {
+				break
+			}
+			denominator = quotient
+			count++
+		}
+		if count > scale {
+			scale = count
+		}
+	}
+	if denominator.Cmp(big.NewInt(1)) != 0 {
+		scale = priceDecimalScale
+	}
+
+	return price.FloatString(scale)
+}
+
 // CreateSampleResultMeta creates Transaction results with the desired success flag and number of sub operation results
 func CreateSampleResultMeta(successful bool, subOperationCount int) xdr.TransactionResultMeta {
 	resultCode := xdr.TransactionResultCodeTxFailed
@@ -236,11 +282,35 @@ func AddCommonFlags(flags *pflag.FlagSet) {
 	flags.StringToStringP("extra-fields", "u", map[string]string{}, "Additional fields to append to output jsons. Used for appending metadata")
 	flags.Bool("captive-core", false, "(Deprecated; Will be removed in the Protocol 23 update) If set, run captive core to retrieve data. Otherwise use TxMeta file datastore.")
 	flags.String("datastore-path", "sdf-ledger-close-meta/ledgers", "Datastore bucket path to read txmeta files from.")
+	flags.String("billing-project", "", "If set, the GCP project billed for the reads of the datastore, which requester pays buckets require.")
+	flags.String("datastore-url", "", "If set, read txmeta files over HTTP(S) from under this URL instead of the datastore bucket, e.g. a public bucket URL or a signed URL prefix whose query string is kept on every file.")
+	flags.Bool("verify-files", false, "If set, check the checksums and the ledgers of every txmeta file read from the datastore, and download the corrupt files again up to retry-limit times.")
+	flags.String("ledger-cache-dir", "", "If set, directory of the local disk where the txmeta files read from the datastore are cached, so that the commands reading the same ledgers do not download them again.")
+	flags.Uint32("ledger-cache-size", 1024, "Maximum size in MB of the ledger cache. The least recently read files are removed beyond it.")
+	flags.Bool("auto-backend", false, "If set, read ledgers from the datastore where it has them and from captive core for the most recent ledgers missing from it.")
 	flags.Uint32("buffer-size", 200, "Buffer size sets the max limit for the number of txmeta files that can be held in memory.")
 	flags.Uint32("num-workers", 10, "Number of workers to spawn that read txmeta files from the datastore.")
-	flags.Uint32("retry-limit", 3, "Datastore GetLedger retry limit.")
-	flags.Uint32("retry-wait", 5, "Time in seconds to wait for GetLedger retry.")
+	flags.Uint32("decode-workers", 0, "If set, the number of workers that decompress and decode txmeta files as soon as they are downloaded, instead of one file at a time as their ledgers are read.")
+	flags.Uint32("retry-limit", 3, "Number of times a failed ledger backend read or queue publish is retried.")
+	flags.Uint32("retry-wait", 5, "Base time in seconds of the jittered exponential backoff between ledger backend retries.")
+	flags.Uint32("retry-max-wait", 60, "Maximum time in seconds to wait between ledger backend retries. 0 leaves the backoff uncapped.")
+	flags.Uint32("retry-budget", 0, "Maximum number of ledger backend retries over the whole export. 0 leaves the retries unbounded.")
 	flags.Bool("write-parquet", false, "If set, write output as parquet files.")
+	flags.String("delta-table-root", "", "If set with write-parquet, also commit the parquet files to Delta Lake tables under this folder, one table per output.")
+	flags.String("log-level", "info", "Minimum level of the logs to write: debug, info, warn or error.")
+	flags.String("log-format", "text", "Format of the logs: text or json.")
+	flags.Uint32("max-memory", 0, "Memory budget in MB for the ledgers read ahead and the rows buffered for parquet output. The ledger files read ahead are bounded to half of the budget, and the rows buffered for every table share the other half; rows beyond it are spilled to temporary files. 0 keeps every row in memory.")
+	flags.Bool("provenance", false, "If set, add batch_id, etl_version, transform_version and exported_at fields to output jsons.")
+	flags.String("sample", "", "If set as 1/N, only export every Nth ledger and add a sample_rate field to output jsons.")
+	flags.Bool("sample-random", false, "If set with sample, export a random 1/N of the ledgers, drawn from sample-seed, instead of every Nth ledger.")
+	flags.Int64("sample-seed", 0, "Seed of the random sample; the same seed always selects the same ledgers.")
+	flags.Uint32("workers", 0, "Number of ledgers or transactions transformed at once; GOMAXPROCS if 0")
+	flags.Uint32("read-ahead", 0, "Number of ledgers or transactions transformed ahead of the one being written; picked from the workers and the backend if 0")
+	flags.Uint32("write-concurrency", 0, "Number of output files written or uploaded at once; GOMAXPROCS, at most 4, if 0")
+	flags.Uint32("transform-workers", 0, "Number of ledgers transformed at once")
+	flags.MarkDeprecated("transform-workers", "use --workers instead")
+	flags.Uint32("toid-ledger-offset", 0, "Offset added to the ledger sequence of the ledger, transaction and operation ids, for private networks restarted from a custom genesis whose ids would collide with a previous epoch.")
+	flags.String("checkpoint-file", "", "If set, the last exported ledger is written to this json file once the export, or a batch of a streaming export, is written, so that a stopped export can be resumed from the next ledger.")
 }
 
 // AddArchiveFlags adds the history archive specific flags: output, and limit
@@ -248,16 +318,22 @@ func AddCommonFlags(flags *pflag.FlagSet) {
 func AddArchiveFlags(objectName string, flags *pflag.FlagSet) {
 	flags.Uint32P("start-ledger", "s", 2, "The ledger sequence number for the beginning of the export period. Defaults to genesis ledger")
 	flags.StringP("output", "o", "exported_"+objectName+".txt", "Filename of the output file")
+	flags.Bool("stdout", false, "If set, stream the rows to stdout instead of the output file, such as to pipe a small export into jq")
 	flags.String("parquet-output", "exported_"+objectName+".parquet", "Filename of the parquet output file")
 	flags.Int64P("limit", "l", -1, "Maximum number of "+objectName+" to export. If the limit is set to a negative number, all the objects in the provided range are exported")
 }
 
+// StdoutPath is the output path of the exports streamed to stdout
+const StdoutPath = "-"
+
 // AddCloudStorageFlags adds the cloud storage releated flags: cloud-storage-bucket, cloud-credentials
 func AddCloudStorageFlags(flags *pflag.FlagSet) {
 	flags.String("cloud-storage-bucket", "stellar-etl-cli", "Cloud storage bucket to export to.")
 	flags.String("cloud-credentials", "", "Path to cloud provider service account credentials. Only used for local/dev purposes. "+
-		"When run on GCP, credentials should be inferred by service account json.")
+		"When run on GCP, credentials should be inferred by service account json. "+
+		"Alternatively set "+CloudCredentialsEnv+" to a credentials file path or the credentials json.")
 	flags.String("cloud-provider", "", "Cloud provider for storage services.")
+	flags.Bool("credentials-check", false, "If set, verify that the credentials of the sinks can be read, and that the cloud storage bucket is accessible when a cloud provider is set, before exporting.")
 }
 
 // AddCoreFlags adds the captive core specific flags: core-executable, core-config, batch-size, and output flags
@@ -277,16 +353,127 @@ func AddCoreFlags(flags *pflag.FlagSet, defaultFolder string) {
 // AddExportTypeFlags adds the captive core specifc flags: export-{type} flags
 func AddExportTypeFlags(flags *pflag.FlagSet) {
 	flags.BoolP("export-accounts", "a", false, "set in order to export account changes")
+	flags.BoolP("export-home-domain-history", "", false, "set in order to export the changes of the home domains of accounts")
+	flags.BoolP("export-inflation-destination-history", "", false, "set in order to export the changes of the inflation destinations of accounts")
+	flags.BoolP("export-account-flags-history", "", false, "set in order to export the changes of the auth flags of accounts")
 	flags.BoolP("export-trustlines", "t", false, "set in order to export trustline changes")
 	flags.BoolP("export-offers", "f", false, "set in order to export offer changes")
 	flags.BoolP("export-pools", "p", false, "set in order to export liquidity pool changes")
+	flags.BoolP("export-pool-share-holders", "", false, "set in order to export the changes of liquidity pool share trustlines held by accounts")
 	flags.BoolP("export-balances", "l", false, "set in order to export claimable balance changes")
+	flags.BoolP("export-account-data", "", false, "set in order to export account data entry changes")
 	flags.BoolP("export-contract-code", "", false, "set in order to export contract code changes")
 	flags.BoolP("export-contract-data", "", false, "set in order to export contract data changes")
+	flags.BoolP("export-contract-balances", "", false, "set in order to export the changes of Stellar Asset Contract balances held by accounts and contracts")
 	flags.BoolP("export-config-settings", "", false, "set in order to export config settings changes")
 	flags.BoolP("export-ttl", "", false, "set in order to export ttl changes")
 }
 
+// WebhookSecretEnv is the environment variable that holds the webhook signing secret when --webhook-secret is not set
+const WebhookSecretEnv = "STELLAR_ETL_WEBHOOK_SECRET"
+
+// AddWebhookFlags adds the flags of the webhook sink: webhook-url, webhook-routes, webhook-secret, webhook-batch-size,
+// webhook-retries and webhook-timeout
+func AddWebhookFlags(flags *pflag.FlagSet) {
+	flags.String("webhook-url", "", "If set, POST the exported rows as json to this endpoint")
+	flags.StringToString("webhook-routes", map[string]string{}, "Endpoints of specific tables, e.g. accounts=https://example.com/accounts. Tables without a route are posted to --webhook-url")
+	flags.String("webhook-secret", "", "Secret used to sign the webhook requests with HMAC-SHA256. Alternatively set "+WebhookSecretEnv)
+	flags.Int("webhook-batch-size", 500, "Maximum number of rows in a webhook request")
+	flags.Int("webhook-retries", 3, "Number of times a failed webhook request is retried")
+	flags.Duration("webhook-timeout", 10*time.Second, "Timeout of a webhook request")
+}
+
+// Encodings of the rows written by the export commands
+const (
+	OutputFormatJSON      = "json"
+	OutputFormatJSONArray = "json-array"
+	OutputFormatProto     = "proto"
+)
+
+// Representations of the timestamps of the rows exported as json
+const (
+	TimestampFormatRFC3339     = "rfc3339"
+	TimestampFormatEpochMillis = "epoch-millis"
+)
+
+// AddOutputFormatFlags adds the output-format flag of the export commands
+func AddOutputFormatFlags(flags *pflag.FlagSet) {
+	flags.String("output-format", OutputFormatJSON, "Encoding of the exported rows: json for json lines, json-array for a single json array of the rows, or proto for length-delimited protobuf messages of the definitions in proto/stellar_etl/records/v1")
+	flags.String("timestamp-format", TimestampFormatRFC3339, "Representation of the timestamps of the json rows: rfc3339 for UTC strings, or epoch-millis for the number of milliseconds since the unix epoch")
+}
+
+// AddIncludeFailedFlags adds the include-failed flag of the commands exporting the rows of transactions
+func AddIncludeFailedFlags(flags *pflag.FlagSet) {
+	flags.Bool("include-failed", true, "If set, rows of failed transactions are exported; their transaction_successful column is false")
+}
+
+// AddSorobanFilterFlags adds the flags of the commands exporting the rows of transactions that restrict them to
+// Soroban or classic transactions: soroban-only and classic-only
+func AddSorobanFilterFlags(flags *pflag.FlagSet) {
+	flags.Bool("soroban-only", false, "If set, only the rows of transactions invoking a host function, extending a footprint ttl or restoring a footprint are exported")
+	flags.Bool("classic-only", false, "If set, only the rows of transactions without Soroban operations are exported; the opposite of soroban-only")
+}
+
+// AddOperationTypeFlags adds the operation-types flag of the commands exporting operations and effects
+func AddOperationTypeFlags(flags *pflag.FlagSet) {
+	flags.StringSlice("operation-types", []string{}, "If set, only the rows of operations of these types are exported, such as payment,manage_sell_offer; the names are those of the type_string column of operations")
+}
+
+// AddContractIDFlags adds the contract-ids flag of the commands exporting contract events and effects
+func AddContractIDFlags(flags *pflag.FlagSet) {
+	flags.StringSlice("contract-ids", []string{}, "If set, only the rows of these contracts are exported, such as C...,C...: the events they emit and the effects of the transactions that invoke them or in which they emit events")
+}
+
+// AddEventFilterFlags adds the event-filter flag of the command exporting contract events
+func AddEventFilterFlags(flags *pflag.FlagSet) {
+	flags.StringArray("event-filter", []string{}, "If set, only the contract events whose topics match one of the filters are exported, such as 'topic0=transfer,topic2=*'; the flag can be repeated")
+}
+
+// AddTransformTimingFlags adds the transform-timing flag of the commands exporting transactions and operations
+func AddTransformTimingFlags(flags *pflag.FlagSet) {
+	flags.Bool("transform-timing", false, "If set, the time taken to transform every row is recorded in histograms by operation type, which are exported as metrics and logged at the end of the export")
+}
+
+// AddTradeFlags adds the normalize-pairs flag of the commands exporting trades
+func AddTradeFlags(flags *pflag.FlagSet) {
+	flags.Bool("normalize-pairs", false, "If set, the base and counter columns of the trades are filled along with the selling and buying ones, orienting the trades of a pair the same way whichever asset was sold")
+}
+
+// AddSplitFlags adds the flag splitting the output files by day: split-by-day
+func AddSplitFlags(flags *pflag.FlagSet) {
+	flags.Bool("split-by-day", false, "If set, write one output file per UTC day of the ledger close times, named after the day, such as exported_trades_2024-01-02.txt")
+}
+
+// AddOutputTemplateFlags adds the flags of the names of the files of each table: output-template and
+// output-templates
+func AddOutputTemplateFlags(flags *pflag.FlagSet) {
+	flags.String("output-template", DefaultOutputTemplate, "Template of the names of the files of the tables of each batch, relative to the output folders, with the placeholders {table}, {network}, {start}, {end}, {shard} and {ext}, e.g. {table}/{network}/{start}-{end}-{shard}.{ext}")
+	flags.StringToString("output-templates", map[string]string{}, "Templates of specific tables, e.g. accounts=accounts/{start}-{end}.{ext}. Tables without a template use --output-template")
+}
+
+// AddQueueFlags adds the flags of the message queue sinks: pubsub-topic, sqs-queue-url, sqs-region and queue-dedupe-keys
+func AddQueueFlags(flags *pflag.FlagSet) {
+	flags.String("pubsub-topic", "", "If set, publish every exported row as a message to this Google Pub/Sub topic, as projects/<project>/topics/<topic>")
+	flags.String("sqs-queue-url", "", "If set, send every exported row as a message to this Amazon SQS queue")
+	flags.String("sqs-region", "", "Region of the SQS queue; taken from the AWS environment if empty")
+	flags.Bool("queue-dedupe-keys", false, "If set, add a dedupe_key attribute made of the ledger, table and sequence of the row to every message, so that consumers can drop rows delivered twice")
+}
+
+// Modes of the data quality checks
+const (
+	QualityModeWarn = "warn"
+	QualityModeFail = "fail"
+)
+
+// AddQualityFlags adds the flags of the data quality checks run on the exported rows
+func AddQualityFlags(flags *pflag.FlagSet) {
+	flags.StringSlice("quality-checks", nil, "Data quality checks to run on the exported rows: non-null, non-negative, unique-ids, increasing-ids or all; none if empty")
+	flags.String("quality-mode", QualityModeWarn, "What failed quality checks do: warn logs the report, fail also stops the export before the files are uploaded")
+	flags.String("quality-report", "", "If set, write the json report of the quality checks to this path")
+	flags.StringSlice("quality-non-null", nil, "Columns, as <table>.<column>, that must not be null or empty; defaults to the key columns of every table")
+	flags.StringSlice("quality-non-negative", nil, "Columns, as <table>.<column>, that must not be negative; defaults to the amount columns of every table")
+}
+
 // TODO: https://stellarorg.atlassian.net/browse/HUBBLE-386 better flags/params
 // Some flags should be named better
 type FlagValues struct {
@@ -309,6 +496,7 @@ type FlagValues struct {
 	Credentials    string
 	Provider       string
 	WriteParquet   bool
+	DeltaTableRoot string
 }
 
 // MustFlags gets the values of the the flags for all commands.
@@ -349,6 +537,14 @@ func MustFlags(flags *pflag.FlagSet, logger *EtlLogger) FlagValues {
 		logger.Warn("warning: the option to run with captive-core will be deprecated in the Protocol 23 update")
 	}
 
+	autoBackend, err := flags.GetBool("auto-backend")
+	if err != nil {
+		logger.Fatal("could not get auto-backend flag: ", err)
+	}
+	if autoBackend && useCaptiveCore {
+		logger.Fatal("auto-backend and captive-core cannot be set together")
+	}
+
 	datastorePath, err := flags.GetString("datastore-path")
 	if err != nil {
 		logger.Fatal("could not get datastore-bucket-path string: ", err)
@@ -414,6 +610,11 @@ func MustFlags(flags *pflag.FlagSet, logger *EtlLogger) FlagValues {
 		logger.Fatal("could not get write-parquet flag: ", err)
 	}
 
+	deltaTableRoot, err := flags.GetString("delta-table-root")
+	if err != nil {
+		logger.Fatal("could not get delta-table-root: ", err)
+	}
+
 	return FlagValues{
 		StartNum:       startNum,
 		EndNum:         endNum,
@@ -434,22 +635,43 @@ func MustFlags(flags *pflag.FlagSet, logger *EtlLogger) FlagValues {
 		Credentials:    credentials,
 		Provider:       provider,
 		WriteParquet:   WriteParquet,
+		DeltaTableRoot: deltaTableRoot,
 	}
 }
 
 type CommonFlagValues struct {
-	EndNum         uint32
-	StrictExport   bool
-	IsTest         bool
-	IsFuture       bool
-	Extra          map[string]string
-	UseCaptiveCore bool
-	DatastorePath  string
-	BufferSize     uint32
-	NumWorkers     uint32
-	RetryLimit     uint32
-	RetryWait      uint32
-	WriteParquet   bool
+	EndNum          uint32
+	StrictExport    bool
+	IsTest          bool
+	IsFuture        bool
+	Extra           map[string]string
+	UseCaptiveCore  bool
+	AutoBackend     bool
+	DatastorePath   string
+	BillingProject  string
+	DatastoreURL    string
+	VerifyFiles     bool
+	LedgerCacheDir  string
+	LedgerCacheSize uint32
+	BufferSize      uint32
+	NumWorkers      uint32
+	DecodeWorkers   uint32
+	RetryLimit      uint32
+	RetryWait       uint32
+	RetryMaxWait    uint32
+	RetryBudget     uint32
+	WriteParquet    bool
+	DeltaTableRoot  string
+	LogLevel        logrus.Level
+	LogFormat       string
+	MaxMemory       int64
+	Provenance      bool
+	Sample          LedgerSample
+	Toid            toid.Generator
+	Concurrency     ConcurrencyFlagValues
+	CheckpointFile  string
+	// Logger is the logger of the command, which the backends and sinks created from the flags log to
+	Logger *EtlLogger
 }
 
 // MustCommonFlags gets the values of the the flags common to all commands: end-ledger and strict-export.
@@ -488,6 +710,14 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Warn("warning: the option to run with captive-core will be deprecated in the Protocol 23 update")
 	}
 
+	autoBackend, err := flags.GetBool("auto-backend")
+	if err != nil {
+		logger.Fatal("could not get auto-backend flag: ", err)
+	}
+	if autoBackend && useCaptiveCore {
+		logger.Fatal("auto-backend and captive-core cannot be set together")
+	}
+
 	datastorePath, err := flags.GetString("datastore-path")
 	if err != nil {
 		logger.Fatal("could not get datastore-bucket-path string: ", err)
@@ -503,6 +733,11 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Fatal("could not get num-workers uint32: ", err)
 	}
 
+	decodeWorkers, err := flags.GetUint32("decode-workers")
+	if err != nil {
+		logger.Fatal("could not get decode-workers uint32: ", err)
+	}
+
 	retryLimit, err := flags.GetUint32("retry-limit")
 	if err != nil {
 		logger.Fatal("could not get retry-limit uint32: ", err)
@@ -513,24 +748,159 @@ func MustCommonFlags(flags *pflag.FlagSet, logger *EtlLogger) CommonFlagValues {
 		logger.Fatal("could not get retry-wait uint32: ", err)
 	}
 
+	billingProject, err := flags.GetString("billing-project")
+	if err != nil {
+		logger.Fatal("could not get billing-project string: ", err)
+	}
+
+	datastoreURL, err := flags.GetString("datastore-url")
+	if err != nil {
+		logger.Fatal("could not get datastore-url string: ", err)
+	}
+	if datastoreURL != "" && billingProject != "" {
+		logger.Fatal("datastore-url and billing-project cannot be set together")
+	}
+
+	verifyFiles, err := flags.GetBool("verify-files")
+	if err != nil {
+		logger.Fatal("could not get verify-files flag: ", err)
+	}
+
+	ledgerCacheDir, err := flags.GetString("ledger-cache-dir")
+	if err != nil {
+		logger.Fatal("could not get ledger-cache-dir string: ", err)
+	}
+
+	ledgerCacheSize, err := flags.GetUint32("ledger-cache-size")
+	if err != nil {
+		logger.Fatal("could not get ledger-cache-size uint32: ", err)
+	}
+
+	retryMaxWait, err := flags.GetUint32("retry-max-wait")
+	if err != nil {
+		logger.Fatal("could not get retry-max-wait uint32: ", err)
+	}
+
+	retryBudget, err := flags.GetUint32("retry-budget")
+	if err != nil {
+		logger.Fatal("could not get retry-budget uint32: ", err)
+	}
+
 	WriteParquet, err := flags.GetBool("write-parquet")
 	if err != nil {
 		logger.Fatal("could not get write-parquet flag: ", err)
 	}
 
+	deltaTableRoot, err := flags.GetString("delta-table-root")
+	if err != nil {
+		logger.Fatal("could not get delta-table-root: ", err)
+	}
+
+	logLevelName, err := flags.GetString("log-level")
+	if err != nil {
+		logger.Fatal("could not get log-level string: ", err)
+	}
+
+	logLevel, err := logrus.ParseLevel(logLevelName)
+	if err != nil {
+		logger.Fatal("invalid log-level: ", err)
+	}
+
+	logFormat, err := flags.GetString("log-format")
+	if err != nil {
+		logger.Fatal("could not get log-format string: ", err)
+	}
+
+	if logFormat != "text" && logFormat != "json" {
+		logger.Fatalf("invalid log-format %s; expected text or json", logFormat)
+	}
+
+	maxMemory, err := flags.GetUint32("max-memory")
+	if err != nil {
+		logger.Fatal("could not get max-memory uint32: ", err)
+	}
+
+	concurrency := mustConcurrencyFlags(flags, useCaptiveCore, logger)
+
+	toidOffset, err := flags.GetUint32("toid-ledger-offset")
+	if err != nil {
+		logger.Fatal("could not get toid-ledger-offset uint32: ", err)
+	}
+	ids := toid.Generator{LedgerOffset: toidOffset}
+
+	provenance, err := flags.GetBool("provenance")
+	if err != nil {
+		logger.Fatal("could not get provenance flag: ", err)
+	}
+	if provenance {
+		extra = WithProvenance(extra, NewBatchID(), time.Now(), ids)
+	}
+
+	sampleValue, err := flags.GetString("sample")
+	if err != nil {
+		logger.Fatal("could not get sample string: ", err)
+	}
+
+	sampleRandom, err := flags.GetBool("sample-random")
+	if err != nil {
+		logger.Fatal("could not get sample-random flag: ", err)
+	}
+
+	sampleSeed, err := flags.GetInt64("sample-seed")
+	if err != nil {
+		logger.Fatal("could not get sample-seed int64: ", err)
+	}
+
+	checkpointFile, err := flags.GetString("checkpoint-file")
+	if err != nil {
+		logger.Fatal("could not get checkpoint-file string: ", err)
+	}
+
+	sample, err := ParseLedgerSample(sampleValue, sampleRandom, sampleSeed)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if sample.Sampled() {
+		sampled := make(map[string]string, len(extra)+1)
+		for k, v := range extra {
+			sampled[k] = v
+		}
+		sampled[SampleRateField] = sample.Rate()
+		extra = sampled
+	}
+
 	return CommonFlagValues{
-		EndNum:         endNum,
-		StrictExport:   strictExport,
-		IsTest:         isTest,
-		IsFuture:       isFuture,
-		Extra:          extra,
-		UseCaptiveCore: useCaptiveCore,
-		DatastorePath:  datastorePath,
-		BufferSize:     bufferSize,
-		NumWorkers:     numWorkers,
-		RetryLimit:     retryLimit,
-		RetryWait:      retryWait,
-		WriteParquet:   WriteParquet,
+		EndNum:          endNum,
+		StrictExport:    strictExport,
+		IsTest:          isTest,
+		IsFuture:        isFuture,
+		Extra:           extra,
+		UseCaptiveCore:  useCaptiveCore,
+		AutoBackend:     autoBackend,
+		DatastorePath:   datastorePath,
+		BillingProject:  billingProject,
+		DatastoreURL:    datastoreURL,
+		VerifyFiles:     verifyFiles,
+		LedgerCacheDir:  ledgerCacheDir,
+		LedgerCacheSize: ledgerCacheSize,
+		BufferSize:      bufferSize,
+		NumWorkers:      numWorkers,
+		DecodeWorkers:   decodeWorkers,
+		RetryLimit:      retryLimit,
+		RetryWait:       retryWait,
+		RetryMaxWait:    retryMaxWait,
+		RetryBudget:     retryBudget,
+		WriteParquet:    WriteParquet,
+		DeltaTableRoot:  deltaTableRoot,
+		LogLevel:        logLevel,
+		LogFormat:       logFormat,
+		MaxMemory:       int64(maxMemory) * 1024 * 1024,
+		Provenance:      provenance,
+		Sample:          sample,
+		Toid:            ids,
+		Concurrency:     concurrency,
+		CheckpointFile:  checkpointFile,
+		Logger:          logger,
 	}
 }
 
@@ -546,6 +916,14 @@ func MustArchiveFlags(flags *pflag.FlagSet, logger *EtlLogger) (startNum uint32,
 		logger.Fatal("could not get output filename: ", err)
 	}
 
+	stdout, err := flags.GetBool("stdout")
+	if err != nil {
+		logger.Fatal("could not get stdout flag: ", err)
+	}
+	if stdout {
+		path = StdoutPath
+	}
+
 	parquetPath, err = flags.GetString("parquet-output")
 	if err != nil {
 		logger.Fatal("could not get parquet-output filename: ", err)
@@ -624,20 +1002,381 @@ func MustCoreFlags(flags *pflag.FlagSet, logger *EtlLogger) (execPath, configPat
 	return
 }
 
+// WebhookFlagValues are the settings of the webhook sink
+type WebhookFlagValues struct {
+	URL       string
+	Routes    map[string]string
+	Secret    string
+	BatchSize int
+	Retries   int
+	Timeout   time.Duration
+}
+
+// MustWebhookFlags gets the values of the webhook sink flags. The sink is disabled when neither a url nor routes are set.
+func MustWebhookFlags(flags *pflag.FlagSet, logger *EtlLogger) WebhookFlagValues {
+	var values WebhookFlagValues
+	var err error
+
+	values.URL, err = flags.GetString("webhook-url")
+	if err != nil {
+		logger.Fatal("could not get webhook-url: ", err)
+	}
+
+	values.Routes, err = flags.GetStringToString("webhook-routes")
+	if err != nil {
+		logger.Fatal("could not get webhook-routes: ", err)
+	}
+
+	values.Secret, err = flags.GetString("webhook-secret")
+	if err != nil {
+		logger.Fatal("could not get webhook-secret: ", err)
+	}
+	if values.Secret == "" {
+		values.Secret = os.Getenv(WebhookSecretEnv)
+	}
+
+	values.BatchSize, err = flags.GetInt("webhook-batch-size")
+	if err != nil {
+		logger.Fatal("could not get webhook-batch-size: ", err)
+	}
+	if values.BatchSize <= 0 {
+		logger.Fatalf("webhook-batch-size (%d) must be greater than 0", values.BatchSize)
+	}
+
+	values.Retries, err = flags.GetInt("webhook-retries")
+	if err != nil {
+		logger.Fatal("could not get webhook-retries: ", err)
+	}
+
+	values.Timeout, err = flags.GetDuration("webhook-timeout")
+	if err != nil {
+		logger.Fatal("could not get webhook-timeout: ", err)
+	}
+
+	return values
+}
+
+// MustOutputTemplateFlags gets and validates the values of the output template flags
+func MustOutputTemplateFlags(flags *pflag.FlagSet, logger *EtlLogger) OutputTemplates {
+	var templates OutputTemplates
+	var err error
+
+	templates.Default, err = flags.GetString("output-template")
+	if err != nil {
+		logger.Fatal("could not get output-template: ", err)
+	}
+	if err := ValidateOutputTemplate(templates.Default, true); err != nil {
+		logger.Fatal("invalid output-template: ", err)
+	}
+
+	templates.Tables, err = flags.GetStringToString("output-templates")
+	if err != nil {
+		logger.Fatal("could not get output-templates: ", err)
+	}
+	// the templates of specific tables without {table} must differ, or the tables would be written to the same file
+	tablesOfTemplates := map[string]string{}
+	for table, template := range templates.Tables {
+		if err := ValidateOutputTemplate(template, false); err != nil {
+			logger.Fatalf("invalid output template of table %s: %v", table, err)
+		}
+		if other, ok := tablesOfTemplates[template]; ok && !strings.Contains(template, "{table}") {
+			logger.Fatalf("tables %s and %s have the same output template %s", other, table, template)
+		}
+		tablesOfTemplates[template] = table
+	}
+
+	return templates
+}
+
+// MustOutputFormatFlags gets the value of the output-format flag
+func MustOutputFormatFlags(flags *pflag.FlagSet, logger *EtlLogger) string {
+	outputFormat, err := flags.GetString("output-format")
+	if err != nil {
+		logger.Fatal("could not get output-format: ", err)
+	}
+
+	if outputFormat != OutputFormatJSON && outputFormat != OutputFormatJSONArray && outputFormat != OutputFormatProto {
+		logger.Fatalf("unknown output format %s; expected json, json-array or proto", outputFormat)
+	}
+
+	return outputFormat
+}
+
+// MustTimestampFormatFlags gets the value of the timestamp-format flag
+func MustTimestampFormatFlags(flags *pflag.FlagSet, logger *EtlLogger) string {
+	timestampFormat, err := flags.GetString("timestamp-format")
+	if err != nil {
+		logger.Fatal("could not get timestamp-format: ", err)
+	}
+
+	if timestampFormat != TimestampFormatRFC3339 && timestampFormat != TimestampFormatEpochMillis {
+		logger.Fatalf("unknown timestamp format %s; expected rfc3339 or epoch-millis", timestampFormat)
+	}
+
+	return timestampFormat
+}
+
+// MustIncludeFailedFlags gets the value of the include-failed flag
+func MustIncludeFailedFlags(flags *pflag.FlagSet, logger *EtlLogger) bool {
+	includeFailed, err := flags.GetBool("include-failed")
+	if err != nil {
+		logger.Fatal("could not get include-failed: ", err)
+	}
+
+	return includeFailed
+}
+
+// MustSorobanFilterFlags gets the values of the soroban-only and classic-only flags, which cannot be set together
+func MustSorobanFilterFlags(flags *pflag.FlagSet, logger *EtlLogger) (sorobanOnly, classicOnly bool) {
+	sorobanOnly, err := flags.GetBool("soroban-only")
+	if err != nil {
+		logger.Fatal("could not get soroban-only: ", err)
+	}
+
+	classicOnly, err = flags.GetBool("classic-only")
+	if err != nil {
+		logger.Fatal("could not get classic-only: ", err)
+	}
+
+	if sorobanOnly && classicOnly {
+		logger.Fatal("soroban-only and classic-only cannot be set together")
+	}
+
+	return sorobanOnly, classicOnly
+}
+
+// MustOperationTypeFlags gets the value of the operation-types flag
+func MustOperationTypeFlags(flags *pflag.FlagSet, logger *EtlLogger) []string {
+	operationTypes, err := flags.GetStringSlice("operation-types")
+	if err != nil {
+		logger.Fatal("could not get operation-types: ", err)
+	}
+
+	return operationTypes
+}
+
+// MustContractIDFlags gets the value of the contract-ids flag
+func MustContractIDFlags(flags *pflag.FlagSet, logger *EtlLogger) []string {
+	contractIDs, err := flags.GetStringSlice("contract-ids")
+	if err != nil {
+		logger.Fatal("could not get contract-ids: ", err)
+	}
+
+	return contractIDs
+}
+
+// MustEventFilterFlags gets the value of the event-filter flag
+func MustEventFilterFlags(flags *pflag.FlagSet, logger *EtlLogger) []string {
+	eventFilters, err := flags.GetStringArray("event-filter")
+	if err != nil {
+		logger.Fatal("could not get event-filter: ", err)
+	}
+
+	return eventFilters
+}
+
+// MustTransformTimingFlags gets the value of the transform-timing flag
+func MustTransformTimingFlags(flags *pflag.FlagSet, logger *EtlLogger) bool {
+	transformTiming, err := flags.GetBool("transform-timing")
+	if err != nil {
+		logger.Fatal("could not get transform-timing: ", err)
+	}
+
+	return transformTiming
+}
+
+// MustTradeFlags gets the value of the normalize-pairs flag
+func MustTradeFlags(flags *pflag.FlagSet, logger *EtlLogger) bool {
+	normalizePairs, err := flags.GetBool("normalize-pairs")
+	if err != nil {
+		logger.Fatal("could not get normalize-pairs: ", err)
+	}
+
+	return normalizePairs
+}
+
+// MustSplitFlags gets the value of the split-by-day flag
+func MustSplitFlags(flags *pflag.FlagSet, logger *EtlLogger) bool {
+	splitByDay, err := flags.GetBool("split-by-day")
+	if err != nil {
+		logger.Fatal("could not get split-by-day: ", err)
+	}
+
+	return splitByDay
+}
+
+// ConcurrencyFlagValues size the read, transform and write stages of the exports
+type ConcurrencyFlagValues struct {
+	// Workers is how many ledgers or transactions are transformed at once
+	Workers int
+	// ReadAhead is how many ledgers or transactions are transformed ahead of the one being written
+	ReadAhead int
+	// WriteConcurrency is how many output files are written or uploaded at once
+	WriteConcurrency int
+}
+
+// maxDefaultWriteConcurrency bounds the default number of files written at once, since writes and uploads are
+// bound by the disk and the network rather than the CPUs
+const maxDefaultWriteConcurrency = 4
+
+// mustConcurrencyFlags gets the values of the concurrency flags: workers, read-ahead and write-concurrency. The flags
+// left at 0 get defaults picked from GOMAXPROCS and the backend.
+func mustConcurrencyFlags(flags *pflag.FlagSet, useCaptiveCore bool, logger *EtlLogger) ConcurrencyFlagValues {
+	workers, err := flags.GetUint32("workers")
+	if err != nil {
+		logger.Fatal("could not get workers: ", err)
+	}
+
+	transformWorkers, err := flags.GetUint32("transform-workers")
+	if err != nil {
+		logger.Fatal("could not get transform-workers: ", err)
+	}
+	if workers == 0 {
+		workers = transformWorkers
+	}
+
+	readAhead, err := flags.GetUint32("read-ahead")
+	if err != nil {
+		logger.Fatal("could not get read-ahead: ", err)
+	}
+
+	writeConcurrency, err := flags.GetUint32("write-concurrency")
+	if err != nil {
+		logger.Fatal("could not get write-concurrency: ", err)
+	}
+
+	return DefaultConcurrency(ConcurrencyFlagValues{
+		Workers:          int(workers),
+		ReadAhead:        int(readAhead),
+		WriteConcurrency: int(writeConcurrency),
+	}, useCaptiveCore, runtime.GOMAXPROCS(0))
+}
+
+// DefaultConcurrency fills the unset values with defaults for procs usable CPUs. Workers default to procs. Captive
+// core replays ledgers one at a time, slower than they are transformed, so reading further ahead than the workers
+// only holds memory; the datastore downloads ledgers in parallel, so twice the workers are read ahead to keep them
+// busy.
+func DefaultConcurrency(values ConcurrencyFlagValues, useCaptiveCore bool, procs int) ConcurrencyFlagValues {
+	if values.Workers <= 0 {
+		values.Workers = procs
+	}
+
+	if values.ReadAhead <= 0 {
+		values.ReadAhead = 2 * values.Workers
+		if useCaptiveCore {
+			values.ReadAhead = values.Workers
+		}
+	}
+
+	if values.WriteConcurrency <= 0 {
+		values.WriteConcurrency = min(procs, maxDefaultWriteConcurrency)
+	}
+
+	return values
+}
+
+// QueueFlagValues are the settings of the message queue sinks
+type QueueFlagValues struct {
+	PubsubTopic string
+	SQSQueueURL string
+	SQSRegion   string
+	DedupeKeys  bool
+}
+
+// MustQueueFlags gets the values of the message queue sink flags
+func MustQueueFlags(flags *pflag.FlagSet, logger *EtlLogger) QueueFlagValues {
+	var values QueueFlagValues
+	var err error
+
+	values.PubsubTopic, err = flags.GetString("pubsub-topic")
+	if err != nil {
+		logger.Fatal("could not get pubsub-topic: ", err)
+	}
+
+	values.SQSQueueURL, err = flags.GetString("sqs-queue-url")
+	if err != nil {
+		logger.Fatal("could not get sqs-queue-url: ", err)
+	}
+
+	values.SQSRegion, err = flags.GetString("sqs-region")
+	if err != nil {
+		logger.Fatal("could not get sqs-region: ", err)
+	}
+
+	values.DedupeKeys, err = flags.GetBool("queue-dedupe-keys")
+	if err != nil {
+		logger.Fatal("could not get queue-dedupe-keys: ", err)
+	}
+
+	return values
+}
+
+// QualityFlagValues are the settings of the data quality checks
+type QualityFlagValues struct {
+	Checks      []string
+	Mode        string
+	ReportPath  string
+	NonNull     []string
+	NonNegative []string
+}
+
+// MustQualityFlags gets the values of the data quality check flags
+func MustQualityFlags(flags *pflag.FlagSet, logger *EtlLogger) QualityFlagValues {
+	var values QualityFlagValues
+	var err error
+
+	values.Checks, err = flags.GetStringSlice("quality-checks")
+	if err != nil {
+		logger.Fatal("could not get quality-checks: ", err)
+	}
+
+	values.Mode, err = flags.GetString("quality-mode")
+	if err != nil {
+		logger.Fatal("could not get quality-mode: ", err)
+	}
+
+	if values.Mode != QualityModeWarn && values.Mode != QualityModeFail {
+		logger.Fatalf("unknown quality mode %s; expected warn or fail", values.Mode)
+	}
+
+	values.ReportPath, err = flags.GetString("quality-report")
+	if err != nil {
+		logger.Fatal("could not get quality-report: ", err)
+	}
+
+	values.NonNull, err = flags.GetStringSlice("quality-non-null")
+	if err != nil {
+		logger.Fatal("could not get quality-non-null: ", err)
+	}
+
+	values.NonNegative, err = flags.GetStringSlice("quality-non-negative")
+	if err != nil {
+		logger.Fatal("could not get quality-non-negative: ", err)
+	}
+
+	return values
+}
+
 // MustExportTypeFlags gets the values for the export-accounts, export-offers, and export-trustlines flags. If any do not exist, it stops the program fatally using the logger
 // func MustExportTypeFlags(flags *pflag.FlagSet, logger *EtlLogger) (exportAccounts, exportOffers, exportTrustlines, exportPools, exportBalances, exportContractCode, exportContractData, exportConfigSettings, exportTtl bool) {
 func MustExportTypeFlags(flags *pflag.FlagSet, logger *EtlLogger) map[string]bool {
 	var err error
 	exports := map[string]bool{
-		"export-accounts":        false,
-		"export-trustlines":      false,
-		"export-offers":          false,
-		"export-pools":           false,
-		"export-balances":        false,
-		"export-contract-code":   false,
-		"export-contract-data":   false,
-		"export-config-settings": false,
-		"export-ttl":             false,
+		"export-accounts":                      false,
+		"export-home-domain-history":           false,
+		"export-inflation-destination-history": false,
+		"export-account-flags-history":         false,
+		"export-trustlines":                    false,
+		"export-offers":                        false,
+		"export-pools":                         false,
+		"export-pool-share-holders":            false,
+		"export-balances":                      false,
+		"export-account-data":                  false,
+		"export-contract-code":                 false,
+		"export-contract-data":                 false,
+		"export-contract-balances":             false,
+		"export-config-settings":               false,
+		"export-ttl":                           false,
 	}
 
 	for export_name := range exports {
@@ -853,6 +1592,8 @@ func GetMostRecentCheckpoint(seq uint32) uint32 {
 	return seq - remainder
 }
 
+const futureNetworkPassphrase = "Test SDF Future Network ; October 2022"
+
 type EnvironmentDetails struct {
 	NetworkPassphrase string
 	ArchiveURLs       []string
@@ -864,36 +1605,110 @@ type EnvironmentDetails struct {
 
 // GetPassphrase returns the correct Network Passphrase based on env preference
 func GetEnvironmentDetails(commonFlags CommonFlagValues) (details EnvironmentDetails) {
+	networkName := "pubnet"
 	if commonFlags.IsTest {
+		networkName = "testnet"
+	} else if commonFlags.IsFuture {
+		networkName = "futurenet"
+	}
+
+	details, _ = GetNetworkEnvironmentDetails(networkName, commonFlags)
+	return details
+}
+
+// GetNetworkEnvironmentDetails returns the environment details of a network by name: pubnet, testnet or futurenet
+func GetNetworkEnvironmentDetails(networkName string, commonFlags CommonFlagValues) (EnvironmentDetails, error) {
+	details := EnvironmentDetails{
+		BinaryPath:       "/usr/bin/stellar-core",
+		Network:          networkName,
+		CommonFlagValues: commonFlags,
+	}
+
+	switch networkName {
+	case "testnet":
 		// testnet passphrase to be used for testing
 		details.NetworkPassphrase = network.TestNetworkPassphrase
 		details.ArchiveURLs = testArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
 		details.CoreConfig = "/etl/docker/stellar-core_testnet.cfg"
-		details.Network = "testnet"
-		details.CommonFlagValues = commonFlags
-		return details
-	} else if commonFlags.IsFuture {
+	case "futurenet":
 		// details.NetworkPassphrase = network.FutureNetworkPassphrase
-		details.NetworkPassphrase = "Test SDF Future Network ; October 2022"
+		details.NetworkPassphrase = futureNetworkPassphrase
 		details.ArchiveURLs = futureArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
 		details.CoreConfig = "/etl/docker/stellar-core_futurenet.cfg"
-		details.Network = "futurenet"
-		details.CommonFlagValues = commonFlags
-		return details
-	} else {
-		// default: mainnet
+	case "pubnet":
 		details.NetworkPassphrase = network.PublicNetworkPassphrase
 		details.ArchiveURLs = mainArchiveURLs
-		details.BinaryPath = "/usr/bin/stellar-core"
 		details.CoreConfig = "/etl/docker/stellar-core.cfg"
-		details.Network = "pubnet"
-		details.CommonFlagValues = commonFlags
-		return details
+	default:
+		return EnvironmentDetails{}, fmt.Errorf("unknown network %s; expected one of pubnet, testnet or futurenet", networkName)
+	}
+
+	return details, nil
+}
+
+// NetworkName returns the name of the network with the passphrase, or the passphrase itself if it is not one of
+// pubnet, testnet or futurenet
+func NetworkName(passphrase string) string {
+	switch passphrase {
+	case network.PublicNetworkPassphrase:
+		return "pubnet"
+	case network.TestNetworkPassphrase:
+		return "testnet"
+	case futureNetworkPassphrase:
+		return "futurenet"
+	default:
+		return passphrase
 	}
 }
 
+// NetworkConfig is a named network read from the networks section of the config file
+type NetworkConfig struct {
+	Name         string `mapstructure:"name"`
+	Network      string `mapstructure:"network"`
+	StartLedger  uint32 `mapstructure:"start_ledger"`
+	EndLedger    uint32 `mapstructure:"end_ledger"`
+	OutputPrefix string `mapstructure:"output_prefix"`
+}
+
+// NetworkConfigs gets the named networks from the config file. The network name is used as the network and the
+// output prefix when they are not set.
+func NetworkConfigs() ([]NetworkConfig, error) {
+	var networks []NetworkConfig
+	if err := viper.UnmarshalKey("networks", &networks); err != nil {
+		return nil, fmt.Errorf("could not read networks from config file: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for i, network := range networks {
+		if network.Name == "" {
+			return nil, fmt.Errorf("network %d in the config file has no name", i)
+		}
+		if seen[network.Name] {
+			return nil, fmt.Errorf("network %s is configured more than once", network.Name)
+		}
+		seen[network.Name] = true
+
+		if network.Network == "" {
+			networks[i].Network = network.Name
+		}
+		if network.OutputPrefix == "" {
+			networks[i].OutputPrefix = network.Name
+		}
+	}
+
+	return networks, nil
+}
+
+// MustNetworkConfigs gets the named networks from the config file. If the section is malformed, it stops the program
+// fatally using the logger
+func MustNetworkConfigs(logger *EtlLogger) []NetworkConfig {
+	networks, err := NetworkConfigs()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	return networks
+}
+
 type CaptiveCore interface {
 	CreateCaptiveCoreBackend() (ledgerbackend.CaptiveStellarCore, error)
 }
@@ -957,6 +1772,16 @@ func GetLedgerSequence(lcm xdr.LedgerCloseMeta) uint32 {
 	return uint32(headerHistoryEntry.Header.LedgerSeq)
 }
 
+// AssetContractID returns the address of the Stellar Asset Contract of a classic asset on the network with the given
+// passphrase. The address is derived from the asset, so it is the same whether or not the contract is deployed.
+func AssetContractID(asset xdr.Asset, passphrase string) (string, error) {
+	contractID, err := asset.ContractID(passphrase)
+	if err != nil {
+		return "", err
+	}
+	return strkey.Encode(strkey.VersionByteContract, contractID[:])
+}
+
 func LedgerEntryToLedgerKeyHash(ledgerEntry xdr.LedgerEntry) string {
 	ledgerKey, _ := ledgerEntry.LedgerKey()
 	ledgerKeyByte, _ := ledgerKey.MarshalBinary()
@@ -969,53 +1794,102 @@ func LedgerEntryToLedgerKeyHash(ledgerEntry xdr.LedgerEntry) string {
 // CreateDatastore creates the datastore to interface with GCS
 // TODO: this can be updated to use different cloud storage services in the future.
 // For now only GCS works datastore.Datastore.
+// With datastore-url the files are read over HTTP(S) instead, and with billing-project the reads of the bucket are
+// billed to that project. With verify-files the integrity of every file read is checked, and with ledger-cache-dir
+// the files read are cached on the local disk.
 func CreateDatastore(ctx context.Context, env EnvironmentDetails) (datastore.DataStore, error) {
+	dataStore, err := createDatastore(ctx, env)
+	if err != nil {
+		return nil, err
+	}
+	if env.CommonFlagValues.VerifyFiles {
+		dataStore = WithVerification(dataStore)
+	}
+	if env.CommonFlagValues.LedgerCacheDir == "" {
+		return dataStore, nil
+	}
+
+	// the files are cached once verified, and told apart by the datastore and network they are read from. The query
+	// string of a datastore url is left out, since the signature of signed urls changes from run to run.
+	namespace := env.CommonFlagValues.DatastorePath + "/" + env.Network
+	if env.CommonFlagValues.DatastoreURL != "" {
+		baseURL, _, _ := strings.Cut(env.CommonFlagValues.DatastoreURL, "?")
+		namespace = baseURL + "/" + env.Network
+	}
+	cached, err := WithLedgerCache(dataStore, env.CommonFlagValues.LedgerCacheDir, namespace, int64(env.CommonFlagValues.LedgerCacheSize)<<20)
+	if err != nil {
+		dataStore.Close()
+		return nil, err
+	}
+	return cached, nil
+}
+
+func createDatastore(ctx context.Context, env EnvironmentDetails) (datastore.DataStore, error) {
+	// TODO: In the future these will come from a config file written by ledgerexporter
+	// Hard code DataStoreSchema values for now
+	schema := datastore.DataStoreSchema{
+		LedgersPerFile:    1,
+		FilesPerPartition: 64000,
+	}
+	bucketPath := env.CommonFlagValues.DatastorePath + "/" + env.Network
+
+	if env.CommonFlagValues.DatastoreURL != "" {
+		return newURLDataStore(env.CommonFlagValues.DatastoreURL, env.Network, schema)
+	}
+	if env.CommonFlagValues.BillingProject != "" {
+		return newRequesterPaysDataStore(ctx, bucketPath, env.CommonFlagValues.BillingProject, schema)
+	}
+
 	// These params are specific for GCS
 	params := make(map[string]string)
-	params["destination_bucket_path"] = env.CommonFlagValues.DatastorePath + "/" + env.Network
+	params["destination_bucket_path"] = bucketPath
 	dataStoreConfig := datastore.DataStoreConfig{
 		Type:   "GCS",
 		Params: params,
-		// TODO: In the future these will come from a config file written by ledgerexporter
-		// Hard code DataStoreSchema values for now
-		Schema: datastore.DataStoreSchema{
-			LedgersPerFile:    1,
-			FilesPerPartition: 64000,
-		},
+		Schema: schema,
 	}
 
 	return datastore.NewDataStore(ctx, dataStoreConfig)
 }
 
-// CreateLedgerBackend creates a ledger backend using captive core or datastore
-// Defaults to using datastore
+// CreateLedgerBackend creates a ledger backend using captive core or datastore, or both with auto-backend
+// Defaults to using datastore. Reads from the backend are retried with the policy of the retry flags.
 func CreateLedgerBackend(ctx context.Context, useCaptiveCore bool, env EnvironmentDetails) (ledgerbackend.LedgerBackend, error) {
+	policy := RetryPolicyFromFlags(env.CommonFlagValues)
+
+	if env.CommonFlagValues.AutoBackend && !useCaptiveCore {
+		return newAutoBackend(ctx, env, policy)
+	}
+
 	// Create ledger backend from captive core
 	if useCaptiveCore {
-		backend, err := env.CreateCaptiveCoreBackend()
-		if err != nil {
-			return nil, err
-		}
-		return backend, nil
+		return WithRetries(env.newCaptiveCoreBackend, "captive-core", policy)
 	}
 
 	dataStore, err := CreateDatastore(ctx, env)
 	if err != nil {
 		return nil, err
 	}
+	return WithRetries(env.newDatastoreBackendFunc(dataStore), "datastore", policy)
+}
 
-	BSBackendConfig := ledgerbackend.BufferedStorageBackendConfig{
-		BufferSize: env.CommonFlagValues.BufferSize,
-		NumWorkers: env.CommonFlagValues.NumWorkers,
-		RetryLimit: env.CommonFlagValues.RetryLimit,
-		RetryWait:  time.Duration(env.CommonFlagValues.RetryWait) * time.Second,
-	}
+// newCaptiveCoreBackend creates a captive core backend as a ledger backend
+func (e EnvironmentDetails) newCaptiveCoreBackend() (ledgerbackend.LedgerBackend, error) {
+	return e.CreateCaptiveCoreBackend()
+}
 
-	backend, err := ledgerbackend.NewBufferedStorageBackend(BSBackendConfig, dataStore)
-	if err != nil {
-		return nil, err
+// newDatastoreBackendFunc returns the function creating the backends that read from the datastore. Their failed
+// downloads are not retried, since the reads are retried by WithRetries with backends created again.
+func (e EnvironmentDetails) newDatastoreBackendFunc(dataStore datastore.DataStore) func() (ledgerbackend.LedgerBackend, error) {
+	config := ledgerbackend.BufferedStorageBackendConfig{
+		BufferSize: e.CommonFlagValues.BufferSize,
+		NumWorkers: e.CommonFlagValues.NumWorkers,
+		// the wait between the reads of the files not exported yet in unbounded ranges
+		RetryWait: time.Duration(e.CommonFlagValues.RetryWait) * time.Second,
+	}
+	return func() (ledgerbackend.LedgerBackend, error) {
+		return newDatastoreBackend(config, dataStore, e.CommonFlagValues.DecodeWorkers, e.CommonFlagValues.MaxMemory, e.CommonFlagValues.Sample)
 	}
-	return backend, nil
 }
 
 func LedgerKeyToLedgerKeyHash(ledgerKey xdr.LedgerKey) string {
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// DefaultOutputTemplate names the files of the tables of a batch after its range and the table, such as
+// 100-163-accounts.txt
+const DefaultOutputTemplate = "{start}-{end}-{table}.{ext}"
+
+// outputTemplatePlaceholders are the placeholders of the output filename templates
+var outputTemplatePlaceholders = map[string]bool{
+	"table":   true,
+	"network": true,
+	"start":   true,
+	"end":     true,
+	"shard":   true,
+	"ext":     true,
+}
+
+// OutputTemplates are the templates of the names of the files the tables of a batch are written to, relative to the
+// output folders. Tables without a template of their own use the default one.
+type OutputTemplates struct {
+	Default string
+	Tables  map[string]string
+}
+
+// OutputFile is what the placeholders of an output filename template are replaced with
+type OutputFile struct {
+	Table   string
+	Network string
+	Start   uint32
+	End     uint32
+	// Shard is the number of the file of the table within the batch
+	Shard int
+	// Ext is the extension of the file, without the dot: txt or parquet
+	Ext string
+}
+
+// Filename returns the name of an output file from the template of its table
+func (t OutputTemplates) Filename(file OutputFile) string {
+	template, ok := t.Tables[file.Table]
+	if !ok {
+		template = t.Default
+	}
+	return strings.NewReplacer(
+		"{table}", file.Table,
+		"{network}", file.Network,
+		"{start}", strconv.FormatUint(uint64(file.Start), 10),
+		"{end}", strconv.FormatUint(uint64(file.End), 10),
+		"{shard}", fmt.Sprintf("%05d", file.Shard),
+		"{ext}", file.Ext,
+	).Replace(template)
+}
+
+// ValidateOutputTemplate checks that a template only has known placeholders and names a file under the output
+// folder. Every batch must get files of its own, so the template must hold the start or the end of the batch, and
+// templates shared by all the tables must also hold the table.
+func ValidateOutputTemplate(template string, shared bool) error {
+	if template == "" {
+		return errors.New("the template is empty")
+	}
+
+	placeholders := map[string]bool{}
+	for rest := template; rest != ""; {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			break
+		}
+		if rest[open] == '}' {
+			return fmt.Errorf("unmatched } in %s", template)
+		}
+		closing := strings.IndexAny(rest[open+1:], "{}")
+		if closing < 0 || rest[open+1+closing] == '{' {
+			return fmt.Errorf("unmatched { in %s", template)
+		}
+		name := rest[open+1 : open+1+closing]
+		if !outputTemplatePlaceholders[name] {
+			return fmt.Errorf("unknown placeholder {%s} in %s; expected {table}, {network}, {start}, {end}, {shard} or {ext}", name, template)
+		}
+		placeholders[name] = true
+		rest = rest[open+1+closing+1:]
+	}
+
+	if !placeholders["start"] && !placeholders["end"] {
+		return fmt.Errorf("%s must hold {start} or {end}, or every batch would be written to the same file", template)
+	}
+	if shared && !placeholders["table"] {
+		return fmt.Errorf("%s must hold {table}, or every table would be written to the same file", template)
+	}
+	if path.IsAbs(template) || strings.HasSuffix(template, "/") {
+		return fmt.Errorf("%s must name a file relative to the output folder", template)
+	}
+	for _, element := range strings.Split(template, "/") {
+		if element == "" || element == "." || element == ".." {
+			return fmt.Errorf("%s must name a file under the output folder, without empty, . or .. elements", template)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,328 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/xdr"
+)
+
+// parallelDecodeBackend reads the ledgers of a datastore like the buffered storage backend of stellar/go, except that
+// its workers decompress and decode the files as soon as they are downloaded. The buffered storage backend decodes
+// the files one at a time as their ledgers are read, which keeps a single core busy with files of many ledgers once
+// the downloads are fast. The files are read ahead up to the buffer size and their ledgers are served in order.
+// With a sample, the files holding none of the sampled ledgers are not read, and the ledgers left out are skipped.
+type parallelDecodeBackend struct {
+	dataStore datastore.DataStore
+	config    ledgerbackend.BufferedStorageBackendConfig
+	sample    LedgerSample
+	// decoder decompresses the files of every worker, since DecodeAll can be called concurrently
+	decoder *zstd.Decoder
+	// downloads and decompressed are buffers reused for the files, which are dropped once decoded
+	downloads    sync.Pool
+	decompressed sync.Pool
+
+	mu       sync.Mutex
+	closed   bool
+	prepared *ledgerbackend.Range
+	cancel   context.CancelCauseFunc
+	wg       sync.WaitGroup
+	// files holds the results of the files being read, in the order of their ledgers
+	files chan chan decodedFile
+	batch xdr.LedgerCloseMetaBatch
+	next  uint32
+	// closing is closed as soon as Close is called, so that a GetLedger waiting for a file with the lock held returns
+	closing   chan struct{}
+	closeOnce sync.Once
+}
+
+type decodedFile struct {
+	batch xdr.LedgerCloseMetaBatch
+	err   error
+}
+
+// bufferedLedgerFileBytes is the memory a buffered ledger file is assumed to take once decoded, when the buffer is
+// bounded by the memory budget. The files of meta-heavy soroban ledgers take several MB.
+const bufferedLedgerFileBytes = 16 << 20
+
+// newDatastoreBackend returns the buffered storage backend of stellar/go, or a parallelDecodeBackend with the same
+// buffer and retries when decodeWorkers is set or the export is sampled, since the buffered storage backend reads
+// every ledger of a range. With a memory budget, the files buffered or being read fit half of it, the other half
+// being left to the rows.
+func newDatastoreBackend(config ledgerbackend.BufferedStorageBackendConfig, dataStore datastore.DataStore, decodeWorkers uint32, maxMemory int64, sample LedgerSample) (ledgerbackend.LedgerBackend, error) {
+	if decodeWorkers != 0 {
+		config.NumWorkers = decodeWorkers
+	}
+	config.BufferSize, config.NumWorkers = memoryBoundedBuffer(config.BufferSize, config.NumWorkers, maxMemory)
+	if decodeWorkers == 0 && !sample.Sampled() {
+		return ledgerbackend.NewBufferedStorageBackend(config, dataStore)
+	}
+	return newParallelDecodeBackend(dataStore, config, sample)
+}
+
+// memoryBoundedBuffer lowers the buffer size and the number of workers so that the ledger files they hold fit half of
+// the memory budget, keeping at least one of each. A budget of 0 leaves them as they are.
+func memoryBoundedBuffer(bufferSize, numWorkers uint32, maxMemory int64) (uint32, uint32) {
+	if maxMemory <= 0 {
+		return bufferSize, numWorkers
+	}
+	files := maxMemory / 2 / bufferedLedgerFileBytes
+	if files < 1 {
+		files = 1
+	}
+	if bufferSize == 0 || int64(bufferSize) > files {
+		bufferSize = uint32(files)
+	}
+	if numWorkers > bufferSize {
+		numWorkers = bufferSize
+	}
+	return bufferSize, numWorkers
+}
+
+// newParallelDecodeBackend returns a backend reading the files of the datastore with the ledgers of the sample, with
+// config.NumWorkers workers
+func newParallelDecodeBackend(dataStore datastore.DataStore, config ledgerbackend.BufferedStorageBackendConfig, sample LedgerSample) (ledgerbackend.LedgerBackend, error) {
+	if config.NumWorkers == 0 {
+		return nil, errors.New("the number of decode workers must be positive")
+	}
+	if config.BufferSize == 0 {
+		return nil, errors.New("the buffer size must be positive")
+	}
+	decoder, err := zstd.NewReader(nil, zstd.WithDecoderConcurrency(int(config.NumWorkers)))
+	if err != nil {
+		return nil, err
+	}
+	return &parallelDecodeBackend{
+		dataStore: dataStore,
+		config:    config,
+		sample:    sample,
+		decoder:   decoder,
+		closing:   make(chan struct{}),
+		downloads: sync.Pool{New: func() interface{} { return new(bytes.Buffer) }},
+		decompressed: sync.Pool{New: func() interface{} {
+			buf := []byte{}
+			return &buf
+		}},
+	}, nil
+}
+
+func (b *parallelDecodeBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return errors.New("the backend is closed; cannot PrepareRange")
+	}
+	if b.prepared != nil && b.prepared.Contains(ledgerRange) {
+		return nil
+	}
+	b.stop()
+
+	readCtx, cancel := context.WithCancelCause(context.Background())
+	schema := b.dataStore.GetSchema()
+	jobs := make(chan decodeJob)
+	b.files = make(chan chan decodedFile, b.config.BufferSize)
+	b.cancel = cancel
+	b.prepared = &ledgerRange
+	b.batch = xdr.LedgerCloseMetaBatch{}
+	b.next = ledgerRange.From()
+
+	b.wg.Add(int(b.config.NumWorkers) + 1)
+	for i := uint32(0); i < b.config.NumWorkers; i++ {
+		go func() {
+			defer b.wg.Done()
+			for job := range jobs {
+				job.result <- b.readFile(readCtx, ledgerRange, job.objectKey)
+			}
+		}()
+	}
+	// the files are queued in order, and the buffer of the queue bounds how far the workers read ahead
+	go func() {
+		defer b.wg.Done()
+		defer close(jobs)
+		defer close(b.files)
+		files := b.files
+		for first := schema.GetSequenceNumberStartBoundary(ledgerRange.From()); !ledgerRange.Bounded() || first <= ledgerRange.To(); first += schema.LedgersPerFile {
+			last := first + schema.LedgersPerFile - 1
+			if ledgerRange.Bounded() {
+				last = min(last, ledgerRange.To())
+			}
+			if !b.sample.IncludesAny(max(first, ledgerRange.From()), last) {
+				continue
+			}
+			job := decodeJob{objectKey: schema.GetObjectKeyFromSequenceNumber(first), result: make(chan decodedFile, 1)}
+			select {
+			case files <- job.result:
+			case <-readCtx.Done():
+				return
+			}
+			select {
+			case jobs <- job:
+			case <-readCtx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+type decodeJob struct {
+	objectKey string
+	result    chan decodedFile
+}
+
+// readFile downloads, decompresses and decodes a file. Failed downloads are retried up to the retry limit, and
+// missing files are waited for when the range is unbounded, as in the buffered storage backend.
+func (b *parallelDecodeBackend) readFile(ctx context.Context, ledgerRange ledgerbackend.Range, objectKey string) decodedFile {
+	for attempt := uint32(0); ; {
+		batch, err := b.decodeFile(ctx, objectKey)
+		if err == nil {
+			return decodedFile{batch: batch}
+		}
+		if ctx.Err() != nil {
+			return decodedFile{err: context.Cause(ctx)}
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			if ledgerRange.Bounded() {
+				return decodedFile{err: fmt.Errorf("ledger object %s is missing: %w", objectKey, err)}
+			}
+		} else if attempt++; attempt > b.config.RetryLimit {
+			return decodedFile{err: fmt.Errorf("maximum retries exceeded for ledger object %s: %w", objectKey, err)}
+		}
+		if sleepContext(ctx, b.config.RetryWait) != nil {
+			return decodedFile{err: context.Cause(ctx)}
+		}
+	}
+}
+
+func (b *parallelDecodeBackend) decodeFile(ctx context.Context, objectKey string) (xdr.LedgerCloseMetaBatch, error) {
+	var batch xdr.LedgerCloseMetaBatch
+	reader, err := b.dataStore.GetFile(ctx, objectKey)
+	if err != nil {
+		return batch, err
+	}
+	defer reader.Close()
+
+	compressed := b.downloads.Get().(*bytes.Buffer)
+	defer b.downloads.Put(compressed)
+	compressed.Reset()
+	if _, err := io.Copy(compressed, reader); err != nil {
+		return batch, fmt.Errorf("failed reading file %s: %w", objectKey, err)
+	}
+
+	decompressed := b.decompressed.Get().(*[]byte)
+	defer b.decompressed.Put(decompressed)
+	decoded, err := b.decoder.DecodeAll(compressed.Bytes(), (*decompressed)[:0])
+	if err != nil {
+		return batch, fmt.Errorf("could not decompress file %s: %w", objectKey, err)
+	}
+	// the decoded ledgers do not share memory with the buffer, which keeps its grown size for the next file
+	*decompressed = decoded
+
+	if err := xdr.SafeUnmarshal(decoded, &batch); err != nil {
+		return batch, fmt.Errorf("could not decode file %s: %w", objectKey, err)
+	}
+	return batch, nil
+}
+
+// GetLedger returns the ledgers of the prepared range in order, waiting for the workers to decode their files. The
+// ledgers left out by the sample may be skipped.
+func (b *parallelDecodeBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return xdr.LedgerCloseMeta{}, errors.New("the backend is closed; cannot GetLedger")
+	}
+	if b.prepared == nil {
+		return xdr.LedgerCloseMeta{}, errors.New("session is not prepared, call PrepareRange first")
+	}
+	if sequence < b.prepared.From() || (b.prepared.Bounded() && sequence > b.prepared.To()) {
+		return xdr.LedgerCloseMeta{}, fmt.Errorf("requested ledger %d is outside of the prepared range %s", sequence, b.prepared)
+	}
+	if !b.sample.Includes(sequence) {
+		return xdr.LedgerCloseMeta{}, fmt.Errorf("requested ledger %d is left out by the sample", sequence)
+	}
+	if sequence+1 < b.next || (sequence > b.next && b.sample.IncludesAny(b.next, sequence-1)) {
+		return xdr.LedgerCloseMeta{}, fmt.Errorf("requested ledger %d is neither the last ledger nor the next sampled one after %d", sequence, b.next)
+	}
+
+	for sequence > uint32(b.batch.EndSequence) || len(b.batch.LedgerCloseMetas) == 0 {
+		var result chan decodedFile
+		select {
+		case result = <-b.files:
+		case <-ctx.Done():
+			return xdr.LedgerCloseMeta{}, ctx.Err()
+		case <-b.closing:
+			return xdr.LedgerCloseMeta{}, errors.New("the backend is closed; cannot GetLedger")
+		}
+		if result == nil {
+			return xdr.LedgerCloseMeta{}, fmt.Errorf("no more files to read ledger %d from", sequence)
+		}
+		var file decodedFile
+		select {
+		case file = <-result:
+		case <-ctx.Done():
+			return xdr.LedgerCloseMeta{}, ctx.Err()
+		case <-b.closing:
+			return xdr.LedgerCloseMeta{}, errors.New("the backend is closed; cannot GetLedger")
+		}
+		if file.err != nil {
+			return xdr.LedgerCloseMeta{}, file.err
+		}
+		b.batch = file.batch
+	}
+
+	lcm, err := b.batch.GetLedger(sequence)
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, err
+	}
+	b.next = sequence + 1
+	return lcm, nil
+}
+
+// GetLatestLedgerSequence returns the last ledger of the file being read
+func (b *parallelDecodeBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.prepared == nil {
+		return 0, errors.New("session is not prepared, call PrepareRange first")
+	}
+	return uint32(b.batch.EndSequence), nil
+}
+
+func (b *parallelDecodeBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !b.closed && b.prepared != nil && b.prepared.Contains(ledgerRange), nil
+}
+
+func (b *parallelDecodeBackend) Close() error {
+	b.closeOnce.Do(func() { close(b.closing) })
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.stop()
+	b.decoder.Close()
+	return nil
+}
+
+// stop stops the workers reading the prepared range
+func (b *parallelDecodeBackend) stop() {
+	if b.cancel == nil {
+		return
+	}
+	b.cancel(errors.New("the backend was closed or prepared for another range"))
+	// the files read ahead are dropped so that the queue does not block
+	for range b.files {
+	}
+	b.wg.Wait()
+	b.cancel = nil
+	b.prepared = nil
+}
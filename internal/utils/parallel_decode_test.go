@@ -0,0 +1,181 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/support/compressxdr"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var decodeTestSchema = datastore.DataStoreSchema{LedgersPerFile: 2, FilesPerPartition: 10}
+
+// decodeTestStore is a datastore of files in memory, which counts the downloads of every file. The downloads of the
+// gated files wait for their gate to be closed.
+type decodeTestStore struct {
+	datastore.DataStore
+	mu        sync.Mutex
+	files     map[string][]byte
+	downloads map[string]int
+	gates     map[string]chan struct{}
+}
+
+// newDecodeTestStore returns a datastore with the files of the ledgers from first to last
+func newDecodeTestStore(t *testing.T, first, last uint32) *decodeTestStore {
+	store := &decodeTestStore{files: map[string][]byte{}, downloads: map[string]int{}, gates: map[string]chan struct{}{}}
+	for start := decodeTestSchema.GetSequenceNumberStartBoundary(first); start <= last; start += decodeTestSchema.LedgersPerFile {
+		batch := xdr.LedgerCloseMetaBatch{StartSequence: xdr.Uint32(start), EndSequence: xdr.Uint32(start + decodeTestSchema.LedgersPerFile - 1)}
+		for sequence := start; sequence < start+decodeTestSchema.LedgersPerFile; sequence++ {
+			batch.LedgerCloseMetas = append(batch.LedgerCloseMetas, autoTestLedger(sequence))
+		}
+		var buf bytes.Buffer
+		_, err := compressxdr.NewXDREncoder(compressxdr.DefaultCompressor, batch).WriteTo(&buf)
+		require.NoError(t, err)
+		store.files[decodeTestSchema.GetObjectKeyFromSequenceNumber(start)] = buf.Bytes()
+	}
+	return store
+}
+
+func (s *decodeTestStore) GetFile(ctx context.Context, path string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	file, ok := s.files[path]
+	if ok {
+		s.downloads[path]++
+	}
+	gate := s.gates[path]
+	s.mu.Unlock()
+	if !ok {
+		return nil, os.ErrNotExist
+	}
+	if gate != nil {
+		select {
+		case <-gate:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return io.NopCloser(bytes.NewReader(file)), nil
+}
+
+// downloaded returns the number of files whose download started
+func (s *decodeTestStore) downloaded() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.downloads)
+}
+
+func (s *decodeTestStore) GetSchema() datastore.DataStoreSchema {
+	return decodeTestSchema
+}
+
+func TestParallelDecodeBackendSample(t *testing.T) {
+	ctx := context.Background()
+	store := newDecodeTestStore(t, 10, 29)
+	config := ledgerbackend.BufferedStorageBackendConfig{BufferSize: 4, NumWorkers: 2}
+	backend, err := newDatastoreBackend(config, store, 0, 0, LedgerSample{Every: 5})
+	require.NoError(t, err)
+	defer backend.Close()
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 29)))
+
+	// the ledgers left out are skipped
+	for _, sequence := range []uint32{10, 15, 20, 25} {
+		lcm, err := backend.GetLedger(ctx, sequence)
+		require.NoError(t, err)
+		assert.Equal(t, sequence, lcm.LedgerSequence())
+	}
+	_, err = backend.GetLedger(ctx, 27)
+	assert.EqualError(t, err, "requested ledger 27 is left out by the sample")
+
+	// only the files holding sampled ledgers are downloaded
+	key := decodeTestSchema.GetObjectKeyFromSequenceNumber
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	assert.Equal(t, map[string]int{key(10): 1, key(14): 1, key(20): 1, key(24): 1}, store.downloads)
+}
+
+func TestParallelDecodeBackendOrder(t *testing.T) {
+	ctx := context.Background()
+	store := newDecodeTestStore(t, 10, 19)
+	gate := make(chan struct{})
+	store.gates[decodeTestSchema.GetObjectKeyFromSequenceNumber(10)] = gate
+	config := ledgerbackend.BufferedStorageBackendConfig{BufferSize: 5, NumWorkers: 4}
+	backend, err := newDatastoreBackend(config, store, 4, 0, LedgerSample{})
+	require.NoError(t, err)
+	defer backend.Close()
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 19)))
+
+	// the first file is downloaded last, and its ledgers are still served first
+	go func() {
+		for store.downloaded() < 5 {
+			time.Sleep(time.Millisecond)
+		}
+		close(gate)
+	}()
+	for sequence := uint32(10); sequence <= 19; sequence++ {
+		lcm, err := backend.GetLedger(ctx, sequence)
+		require.NoError(t, err)
+		assert.Equal(t, sequence, lcm.LedgerSequence())
+	}
+	_, err = backend.GetLedger(ctx, 12)
+	assert.EqualError(t, err, "requested ledger 12 is neither the last ledger nor the next sampled one after 20")
+}
+
+func TestParallelDecodeBackendCancel(t *testing.T) {
+	store := newDecodeTestStore(t, 10, 19)
+	store.gates[decodeTestSchema.GetObjectKeyFromSequenceNumber(10)] = make(chan struct{})
+	config := ledgerbackend.BufferedStorageBackendConfig{BufferSize: 2, NumWorkers: 2}
+	backend, err := newDatastoreBackend(config, store, 2, 0, LedgerSample{})
+	require.NoError(t, err)
+	defer backend.Close()
+	require.NoError(t, backend.PrepareRange(context.Background(), ledgerbackend.BoundedRange(10, 19)))
+
+	// a read waiting for its file stops with its context
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = backend.GetLedger(ctx, 10)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestParallelDecodeBackendClose(t *testing.T) {
+	ctx := context.Background()
+	store := newDecodeTestStore(t, 10, 19)
+	store.gates[decodeTestSchema.GetObjectKeyFromSequenceNumber(10)] = make(chan struct{})
+	config := ledgerbackend.BufferedStorageBackendConfig{BufferSize: 2, NumWorkers: 2}
+	backend, err := newDatastoreBackend(config, store, 2, 0, LedgerSample{})
+	require.NoError(t, err)
+	require.NoError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 19)))
+
+	// closing the backend stops a read waiting for its file, rather than waiting for it
+	read := make(chan error, 1)
+	go func() {
+		_, err := backend.GetLedger(ctx, 10)
+		read <- err
+	}()
+	for store.downloaded() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	closed := make(chan error, 1)
+	go func() {
+		closed <- backend.Close()
+	}()
+	select {
+	case err := <-closed:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close is blocked by GetLedger")
+	}
+	assert.EqualError(t, <-read, "the backend is closed; cannot GetLedger")
+
+	_, err = backend.GetLedger(ctx, 10)
+	assert.EqualError(t, err, "the backend is closed; cannot GetLedger")
+	assert.EqualError(t, backend.PrepareRange(ctx, ledgerbackend.BoundedRange(10, 19)), "the backend is closed; cannot PrepareRange")
+}
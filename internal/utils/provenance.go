@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+)
+
+// Provenance fields added to every output row when provenance is set
+const (
+	ProvenanceBatchID          = "batch_id"
+	ProvenanceEtlVersion       = "etl_version"
+	ProvenanceTransformVersion = "transform_version"
+	ProvenanceExportedAt       = "exported_at"
+	ProvenanceToidLedgerOffset = "toid_ledger_offset"
+)
+
+// IngestionLagField is the extra field holding how many seconds after the close of its last ledger a streamed batch
+// was exported
+const IngestionLagField = "ingestion_lag_seconds"
+
+// NewBatchID returns a random id for an export run or batch
+func NewBatchID() string {
+	return uuid.NewString()
+}
+
+// WithProvenance returns a copy of the extra fields with the provenance fields of a batch added. The provenance
+// fields take precedence over extra fields with the same name. The ledger offset of the ids is recorded when it is set.
+func WithProvenance(extra map[string]string, batchID string, exportedAt time.Time, ids toid.Generator) map[string]string {
+	fields := make(map[string]string, len(extra)+5)
+	for k, v := range extra {
+		fields[k] = v
+	}
+
+	buildInfo, _ := debug.ReadBuildInfo()
+	fields[ProvenanceBatchID] = batchID
+	fields[ProvenanceEtlVersion] = etlVersion(buildInfo)
+	fields[ProvenanceTransformVersion] = transformVersion(buildInfo)
+	fields[ProvenanceExportedAt] = exportedAt.UTC().Format(time.RFC3339)
+	if offset := ids.LedgerOffset; offset != 0 {
+		fields[ProvenanceToidLedgerOffset] = strconv.FormatUint(uint64(offset), 10)
+	}
+
+	return fields
+}
+
+// WithIngestionLag returns a copy of the extra fields with the ingestion lag of a batch added: the whole seconds
+// between the close time of its last ledger and the time it was exported
+func WithIngestionLag(extra map[string]string, closeTime, exportedAt time.Time) map[string]string {
+	fields := make(map[string]string, len(extra)+1)
+	for k, v := range extra {
+		fields[k] = v
+	}
+
+	fields[IngestionLagField] = strconv.FormatInt(int64(exportedAt.Sub(closeTime)/time.Second), 10)
+	return fields
+}
+
+// BuildVersions returns the etl and transform versions of the running binary, the same as in the provenance fields
+func BuildVersions() (etl, transform string) {
+	buildInfo, _ := debug.ReadBuildInfo()
+	return etlVersion(buildInfo), transformVersion(buildInfo)
+}
+
+// etlVersion is the git SHA stellar-etl was built from, falling back to the module version for builds without
+// vcs information
+func etlVersion(buildInfo *debug.BuildInfo) string {
+	if buildInfo == nil {
+		return "unknown"
+	}
+
+	revision, modified := "", false
+	for _, setting := range buildInfo.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		}
+	}
+
+	if revision == "" {
+		return buildInfo.Main.Version
+	}
+	if modified {
+		return revision + "-dirty"
+	}
+	return revision
+}
+
+// transformVersion is the version of the stellar/go ingest and xdr libraries the transforms are built on
+func transformVersion(buildInfo *debug.BuildInfo) string {
+	if buildInfo == nil {
+		return "unknown"
+	}
+
+	for _, dep := range buildInfo.Deps {
+		if dep.Path == "github.com/stellar/go" {
+			return dep.Version
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,349 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/xdr"
+	"google.golang.org/api/googleapi"
+)
+
+// RetryPolicy is how reads from a ledger backend are retried. The wait before retry n is drawn uniformly between
+// zero and BaseWait * 2^(n-1), capped at MaxWait. Budget caps the retries across all the reads of a backend, so that
+// an unreachable backend fails the export instead of retrying every ledger; 0 leaves the retries unbounded.
+type RetryPolicy struct {
+	Attempts uint32
+	BaseWait time.Duration
+	MaxWait  time.Duration
+	Budget   uint32
+	// Logger is the logger of the command the retries are logged to
+	Logger *EtlLogger
+}
+
+// RetryPolicyFromFlags returns the retry policy set by the common retry flags
+func RetryPolicyFromFlags(flags CommonFlagValues) RetryPolicy {
+	return RetryPolicy{
+		Attempts: flags.RetryLimit + 1,
+		BaseWait: time.Duration(flags.RetryWait) * time.Second,
+		MaxWait:  time.Duration(flags.RetryMaxWait) * time.Second,
+		Budget:   flags.RetryBudget,
+		Logger:   flags.Logger,
+	}
+}
+
+// backoff returns the jittered wait before the given retry, counting from 1
+func (p RetryPolicy) backoff(retry uint32, random *rand.Rand) time.Duration {
+	wait := p.BaseWait
+	for i := uint32(1); i < retry && (p.MaxWait <= 0 || wait < p.MaxWait); i++ {
+		wait *= 2
+	}
+	if p.MaxWait > 0 && wait > p.MaxWait {
+		wait = p.MaxWait
+	}
+	if wait <= 0 {
+		return 0
+	}
+	return time.Duration(random.Int63n(int64(wait) + 1))
+}
+
+// RetryableError is implemented by the errors of sinks and backends that know whether the call that failed can
+// succeed if it is made again. Transient failures, such as timeouts or throttling, are retried; the others, such as
+// schema or auth errors, stop the export at once instead of being retried until the attempts run out.
+type RetryableError interface {
+	error
+	Retryable() bool
+}
+
+// classifiedError is an error marked as retryable or not
+type classifiedError struct {
+	err       error
+	retryable bool
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+func (e *classifiedError) Retryable() bool {
+	return e.retryable
+}
+
+// TransientError marks an error as retryable
+func TransientError(err error) error {
+	return &classifiedError{err: err, retryable: true}
+}
+
+// PermanentError marks an error as not retryable
+func PermanentError(err error) error {
+	return &classifiedError{err: err, retryable: false}
+}
+
+// IsRetryable reports whether the call that failed with the error can succeed if it is made again. Errors that
+// classify themselves, and the errors of Google and AWS APIs by their HTTP status, are retried as they tell. The
+// calls cancelled by their context are not retried. Other errors are, since they are most often network failures.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var classified RetryableError
+	if errors.As(err, &classified) {
+		return classified.Retryable()
+	}
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		return RetryableStatus(apiErr.Code)
+	}
+	var requestFailure awserr.RequestFailure
+	if errors.As(err, &requestFailure) {
+		return RetryableStatus(requestFailure.StatusCode())
+	}
+	return true
+}
+
+// RetryableStatus reports whether a request that failed with the HTTP status can succeed if it is made again: the
+// server errors, timeouts and throttling can, while the other client errors, such as bad requests or missing
+// permissions, fail the same way every time
+func RetryableStatus(status int) bool {
+	return status >= 500 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests
+}
+
+// Retrier retries failed calls with the backoff of its policy. The budget of the policy is shared by all the calls.
+type Retrier struct {
+	policy  RetryPolicy
+	name    string
+	mu      sync.Mutex
+	random  *rand.Rand
+	retries uint32
+}
+
+// NewRetrier returns a retrier of the calls to a backend or sink. The name identifies it in logs and metrics.
+func NewRetrier(name string, policy RetryPolicy) *Retrier {
+	return &Retrier{
+		policy: policy,
+		name:   name,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// retryingBackend wraps a ledger backend so that failed reads are retried with the backoff of its policy. A backend
+// whose read failed cannot be read again, as the buffered storage backend stops its workers on the first error, so
+// it is closed and created again, prepared from the ledger that failed, before the read is retried.
+type retryingBackend struct {
+	*Retrier
+	newBackend func() (ledgerbackend.LedgerBackend, error)
+
+	mu      sync.Mutex
+	backend ledgerbackend.LedgerBackend
+	// prepared is the range last prepared, and next the first ledger of it not read yet
+	prepared *ledgerbackend.Range
+	next     uint32
+}
+
+// WithRetries wraps the backends created by newBackend so that GetLedger, GetLatestLedgerSequence and PrepareRange
+// are retried on failure. It is the only layer of retries of the reads, so the backends it creates must not retry
+// themselves. The name identifies the backend in logs and metrics.
+func WithRetries(newBackend func() (ledgerbackend.LedgerBackend, error), name string, policy RetryPolicy) (ledgerbackend.LedgerBackend, error) {
+	backend, err := newBackend()
+	if err != nil {
+		return nil, err
+	}
+	if policy.Attempts <= 1 {
+		return backend, nil
+	}
+	return &retryingBackend{
+		Retrier:    NewRetrier(name, policy),
+		newBackend: newBackend,
+		backend:    backend,
+	}, nil
+}
+
+// current returns the backend to read from, creating it again and preparing it from the next ledger after a failure
+func (b *retryingBackend) current(ctx context.Context) (ledgerbackend.LedgerBackend, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.backend != nil {
+		return b.backend, nil
+	}
+
+	backend, err := b.newBackend()
+	if err != nil {
+		return nil, err
+	}
+	if b.prepared != nil {
+		ledgerRange := ledgerbackend.UnboundedRange(b.next)
+		if b.prepared.Bounded() {
+			ledgerRange = ledgerbackend.BoundedRange(b.next, b.prepared.To())
+		}
+		if err := backend.PrepareRange(ctx, ledgerRange); err != nil {
+			backend.Close()
+			return nil, err
+		}
+	}
+	b.backend = backend
+	return backend, nil
+}
+
+// discard closes a backend whose call failed, so that the next call creates it again
+func (b *retryingBackend) discard(backend ledgerbackend.LedgerBackend) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.backend == backend {
+		b.backend = nil
+		backend.Close()
+	}
+}
+
+func (b *retryingBackend) GetLedger(ctx context.Context, sequence uint32) (xdr.LedgerCloseMeta, error) {
+	var lcm xdr.LedgerCloseMeta
+	err := b.Do(ctx, "get_ledger", func() error {
+		backend, err := b.current(ctx)
+		if err != nil {
+			return err
+		}
+		if lcm, err = backend.GetLedger(ctx, sequence); err != nil {
+			b.discard(backend)
+			return err
+		}
+		b.mu.Lock()
+		b.next = sequence + 1
+		b.mu.Unlock()
+		return nil
+	})
+	if err != nil {
+		return xdr.LedgerCloseMeta{}, fmt.Errorf("could not get ledger %d: %w", sequence, err)
+	}
+	return lcm, nil
+}
+
+func (b *retryingBackend) GetLatestLedgerSequence(ctx context.Context) (uint32, error) {
+	var sequence uint32
+	err := b.Do(ctx, "get_latest_ledger_sequence", func() error {
+		backend, err := b.current(ctx)
+		if err != nil {
+			return err
+		}
+		if sequence, err = backend.GetLatestLedgerSequence(ctx); err != nil {
+			b.discard(backend)
+		}
+		return err
+	})
+	return sequence, err
+}
+
+func (b *retryingBackend) PrepareRange(ctx context.Context, ledgerRange ledgerbackend.Range) error {
+	return b.Do(ctx, "prepare_range", func() error {
+		backend, err := b.current(ctx)
+		if err != nil {
+			return err
+		}
+		if err := backend.PrepareRange(ctx, ledgerRange); err != nil {
+			b.discard(backend)
+			return err
+		}
+		b.mu.Lock()
+		b.prepared = &ledgerRange
+		b.next = ledgerRange.From()
+		b.mu.Unlock()
+		return nil
+	})
+}
+
+func (b *retryingBackend) IsPrepared(ctx context.Context, ledgerRange ledgerbackend.Range) (bool, error) {
+	b.mu.Lock()
+	backend := b.backend
+	b.mu.Unlock()
+	if backend == nil {
+		return false, nil
+	}
+	return backend.IsPrepared(ctx, ledgerRange)
+}
+
+func (b *retryingBackend) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	// a closed backend is not created again
+	b.newBackend = func() (ledgerbackend.LedgerBackend, error) {
+		return nil, PermanentError(errors.New("the ledger backend is closed"))
+	}
+	if b.backend == nil {
+		return nil
+	}
+	err := b.backend.Close()
+	b.backend = nil
+	return err
+}
+
+// Do calls call until it succeeds, fails with an error that is not retryable, the attempts or the retry budget run
+// out, or the context is done. A nil retrier calls it once.
+func (r *Retrier) Do(ctx context.Context, operation string, call func() error) error {
+	if r == nil {
+		return call()
+	}
+	var err error
+	for attempt := uint32(1); ; attempt++ {
+		if err = call(); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !IsRetryable(err) {
+			return err
+		}
+		if attempt >= r.policy.Attempts {
+			recordBackendRetry(ctx, r.name, operation, "exhausted")
+			return fmt.Errorf("%s failed after %d attempts: %w", operation, attempt, err)
+		}
+
+		wait, ok := r.reserveRetry(attempt)
+		if !ok {
+			recordBackendRetry(ctx, r.name, operation, "budget_exhausted")
+			return fmt.Errorf("%s failed and the retry budget of %d is used up: %w", operation, r.policy.Budget, err)
+		}
+
+		recordBackendRetry(ctx, r.name, operation, "retried")
+		r.logger().Warnf("%s on %s failed, attempt %d of %d; retrying in %s: %v", operation, r.name, attempt, r.policy.Attempts, wait, err)
+		if sleepErr := sleepContext(ctx, wait); sleepErr != nil {
+			return err
+		}
+	}
+}
+
+// logger returns the logger of the policy, or a new one for the policies built without the logger of a command
+func (r *Retrier) logger() *EtlLogger {
+	if r.policy.Logger == nil {
+		return NewEtlLogger()
+	}
+	return r.policy.Logger
+}
+
+// reserveRetry takes a retry out of the budget and returns how long to wait before it
+func (r *Retrier) reserveRetry(attempt uint32) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.policy.Budget > 0 && r.retries >= r.policy.Budget {
+		return 0, false
+	}
+	r.retries++
+	return r.policy.backoff(attempt, r.random), true
+}
+
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
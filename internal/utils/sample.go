@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// SampleRateField is the extra field holding the fraction of ledgers kept by a sampled export
+const SampleRateField = "sample_rate"
+
+// LedgerSample selects the ledgers of a sampled export. A sample of 1/N keeps every Nth ledger, the ones whose
+// sequence is a multiple of N, or with Random set, each ledger with a 1/N chance drawn from a hash of the seed and
+// the sequence, so that the same seed always selects the same ledgers.
+type LedgerSample struct {
+	// Every is the N of a 1/N sample; 0 or 1 keeps every ledger
+	Every  uint32
+	Random bool
+	Seed   int64
+}
+
+// ParseLedgerSample parses a sample given as 1/N
+func ParseLedgerSample(value string, random bool, seed int64) (LedgerSample, error) {
+	if value == "" {
+		return LedgerSample{}, nil
+	}
+
+	numerator, denominator, ok := strings.Cut(value, "/")
+	if !ok || strings.TrimSpace(numerator) != "1" {
+		return LedgerSample{}, fmt.Errorf("invalid sample %s; expected 1/N", value)
+	}
+	every, err := strconv.ParseUint(strings.TrimSpace(denominator), 10, 32)
+	if err != nil || every == 0 {
+		return LedgerSample{}, fmt.Errorf("invalid sample %s; expected 1/N with N a positive integer", value)
+	}
+
+	return LedgerSample{Every: uint32(every), Random: random, Seed: seed}, nil
+}
+
+// Sampled returns true if the sample leaves ledgers out
+func (s LedgerSample) Sampled() bool {
+	return s.Every > 1
+}
+
+// Includes returns true if the ledger is part of the sample
+func (s LedgerSample) Includes(sequence uint32) bool {
+	if !s.Sampled() {
+		return true
+	}
+	if !s.Random {
+		return sequence%s.Every == 0
+	}
+
+	var key [12]byte
+	binary.BigEndian.PutUint64(key[:8], uint64(s.Seed))
+	binary.BigEndian.PutUint32(key[8:], sequence)
+	hash := fnv.New64a()
+	hash.Write(key[:])
+	return hash.Sum64()%uint64(s.Every) == 0
+}
+
+// IncludesAny returns true if any ledger from first to last is part of the sample
+func (s LedgerSample) IncludesAny(first, last uint32) bool {
+	for sequence := first; sequence <= last; sequence++ {
+		if s.Includes(sequence) {
+			return true
+		}
+		if sequence == last {
+			break
+		}
+	}
+	return false
+}
+
+// Rate is the fraction of ledgers kept by the sample, as written in the sample_rate field
+func (s LedgerSample) Rate() string {
+	if !s.Sampled() {
+		return "1"
+	}
+	return strconv.FormatFloat(1/float64(s.Every), 'g', -1, 64)
+}
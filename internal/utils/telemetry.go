@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/stellar/stellar-etl/v2"
+
+// SetupTelemetry registers otel providers that push traces and metrics over OTLP/HTTP to the endpoint, or to
+// the endpoint in the standard OTEL_EXPORTER_OTLP_ENDPOINT variable. Without either, telemetry stays disabled.
+// The returned function flushes and stops the exporters.
+func SetupTelemetry(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+	if endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" {
+		return noop, nil
+	}
+
+	var traceOptions []otlptracehttp.Option
+	var metricOptions []otlpmetrichttp.Option
+	if endpoint != "" {
+		traceOptions = append(traceOptions, otlptracehttp.WithEndpointURL(endpoint+"/v1/traces"))
+		metricOptions = append(metricOptions, otlpmetrichttp.WithEndpointURL(endpoint+"/v1/metrics"))
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("stellar-etl")))
+	if err != nil {
+		return noop, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, traceOptions...)
+	if err != nil {
+		return noop, err
+	}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter), sdktrace.WithResource(res))
+
+	metricExporter, err := otlpmetrichttp.New(ctx, metricOptions...)
+	if err != nil {
+		return noop, errors.Join(err, tracerProvider.Shutdown(ctx))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)), sdkmetric.WithResource(res))
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+// StartSpan starts a trace span for a phase of the export. Spans are only recorded once a tracer provider is
+// registered with otel; otherwise this is a no-op.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// LedgerRangeAttributes are the span attributes of a batch of ledgers
+func LedgerRangeAttributes(start, end uint32) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64(LogFieldLedgerStart, int64(start)),
+		attribute.Int64(LogFieldLedgerEnd, int64(end)),
+	}
+}
+
+type etlInstruments struct {
+	ledgers           metric.Int64Counter
+	rows              metric.Int64Counter
+	transforms        metric.Int64Counter
+	transformFailures metric.Int64Counter
+	phaseDuration     metric.Float64Histogram
+	transformDuration metric.Float64Histogram
+	backendRetries    metric.Int64Counter
+	ledgerCacheReads  metric.Int64Counter
+}
+
+var (
+	instrumentsOnce sync.Once
+	instruments     etlInstruments
+)
+
+// getInstruments creates the metric instruments from the global meter provider. Instruments that could not be
+// created fall back to no-ops, so recording metrics never fails an export.
+func getInstruments() etlInstruments {
+	instrumentsOnce.Do(func() {
+		meter := otel.Meter(tracerName)
+		instruments.ledgers, _ = meter.Int64Counter("stellar_etl.ledgers_exported", metric.WithDescription("Number of ledgers exported"))
+		instruments.rows, _ = meter.Int64Counter("stellar_etl.rows_exported", metric.WithDescription("Number of rows exported per table"))
+		instruments.transforms, _ = meter.Int64Counter("stellar_etl.transforms", metric.WithDescription("Number of attempted transforms"))
+		instruments.transformFailures, _ = meter.Int64Counter("stellar_etl.transform_failures", metric.WithDescription("Number of failed transforms"))
+		instruments.phaseDuration, _ = meter.Float64Histogram("stellar_etl.phase_duration", metric.WithDescription("Duration of the export phases"), metric.WithUnit("s"))
+		instruments.transformDuration, _ = meter.Float64Histogram("stellar_etl.transform_duration", metric.WithDescription("Duration of the transform of a row by table and operation type"), metric.WithUnit("ms"), metric.WithExplicitBucketBoundaries(TransformDurationBuckets...))
+		instruments.backendRetries, _ = meter.Int64Counter("stellar_etl.backend_retries", metric.WithDescription("Number of failed ledger backend reads by outcome"))
+		instruments.ledgerCacheReads, _ = meter.Int64Counter("stellar_etl.ledger_cache_reads", metric.WithDescription("Number of datastore files read through the ledger cache by outcome"))
+	})
+	return instruments
+}
+
+// RecordExportedBatch records the ledgers and rows per table of an exported batch
+func RecordExportedBatch(ctx context.Context, network string, start, end uint32, rows map[string]int) {
+	i := getInstruments()
+	networkAttr := attribute.String(LogFieldNetwork, network)
+	i.ledgers.Add(ctx, int64(end-start+1), metric.WithAttributes(networkAttr))
+	for table, count := range rows {
+		i.rows.Add(ctx, int64(count), metric.WithAttributes(networkAttr, attribute.String(LogFieldTable, table)))
+	}
+}
+
+// RecordPhaseDuration records how long a read, transform or write phase took
+func RecordPhaseDuration(ctx context.Context, network, phase string, duration time.Duration) {
+	getInstruments().phaseDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String(LogFieldNetwork, network),
+		attribute.String("phase", phase),
+	))
+}
+
+// TransformDurationBuckets are the upper bounds, in milliseconds, of the buckets of the transform duration histograms
+var TransformDurationBuckets = []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000}
+
+// RecordTransformDuration records how long the transform of a row of a table took, by the operation type of the row
+func RecordTransformDuration(ctx context.Context, table, operationType string, duration time.Duration) {
+	getInstruments().transformDuration.Record(ctx, float64(duration)/float64(time.Millisecond), metric.WithAttributes(
+		attribute.String(LogFieldTable, table),
+		attribute.String("op_type", operationType),
+	))
+}
+
+// RecordTransformStats records the attempted and failed transforms of an export
+func RecordTransformStats(ctx context.Context, attempts, failures int) {
+	i := getInstruments()
+	i.transforms.Add(ctx, int64(attempts))
+	i.transformFailures.Add(ctx, int64(failures))
+}
+
+// recordBackendRetry records a failed ledger backend read by its outcome: retried, exhausted or budget_exhausted
+func recordBackendRetry(ctx context.Context, backend, operation, outcome string) {
+	getInstruments().backendRetries.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("backend", backend),
+		attribute.String("operation", operation),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// recordLedgerCacheRead records a datastore file read through the ledger cache by its outcome: hit or miss
+func recordLedgerCacheRead(ctx context.Context, outcome string) {
+	getInstruments().ledgerCacheReads.Add(ctx, 1, metric.WithAttributes(attribute.String("outcome", outcome)))
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/stellar/go/support/compressxdr"
+	"github.com/stellar/go/support/datastore"
+	"github.com/stellar/go/xdr"
+)
+
+// ErrCorruptFile is the error of the datastore files that fail their integrity checks
+var ErrCorruptFile = errors.New("corrupt datastore file")
+
+var (
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// VerifyLedgerFile checks the integrity of a datastore file. The file is decompressed, which checks the checksums
+// embedded in its zstd frames or gzip members, and decoded, and it must hold the ledgers its object key is named
+// after, in sequence and each one chained to the hash of the previous one. The errors wrap ErrCorruptFile.
+func VerifyLedgerFile(contents []byte, schema datastore.DataStoreSchema, objectKey string) error {
+	var decompressor io.ReadCloser
+	var err error
+	switch {
+	case bytes.HasPrefix(contents, zstdMagic):
+		decompressor, err = compressxdr.DefaultCompressor.NewReader(bytes.NewReader(contents))
+	case bytes.HasPrefix(contents, gzipMagic):
+		decompressor, err = gzip.NewReader(bytes.NewReader(contents))
+	default:
+		return fmt.Errorf("%w %s: not zstd or gzip compressed", ErrCorruptFile, objectKey)
+	}
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrCorruptFile, objectKey, err)
+	}
+	defer decompressor.Close()
+
+	decompressed, err := io.ReadAll(decompressor)
+	if err != nil {
+		return fmt.Errorf("%w %s: could not decompress: %v", ErrCorruptFile, objectKey, err)
+	}
+
+	var batch xdr.LedgerCloseMetaBatch
+	if err := xdr.SafeUnmarshal(decompressed, &batch); err != nil {
+		return fmt.Errorf("%w %s: could not decode the ledgers: %v", ErrCorruptFile, objectKey, err)
+	}
+	if key := schema.GetObjectKeyFromSequenceNumber(uint32(batch.StartSequence)); key != objectKey {
+		return fmt.Errorf("%w %s: holds ledgers from %d, which belong in %s", ErrCorruptFile, objectKey, batch.StartSequence, key)
+	}
+	if count := int(batch.EndSequence) - int(batch.StartSequence) + 1; count != len(batch.LedgerCloseMetas) {
+		return fmt.Errorf("%w %s: holds %d ledgers, expected %d for ledgers [%d, %d]", ErrCorruptFile, objectKey, len(batch.LedgerCloseMetas), count, batch.StartSequence, batch.EndSequence)
+	}
+	for i, lcm := range batch.LedgerCloseMetas {
+		if sequence := uint32(batch.StartSequence) + uint32(i); lcm.LedgerSequence() != sequence {
+			return fmt.Errorf("%w %s: ledger %d is in the place of ledger %d", ErrCorruptFile, objectKey, lcm.LedgerSequence(), sequence)
+		}
+		if i > 0 && lcm.PreviousLedgerHash() != batch.LedgerCloseMetas[i-1].LedgerHash() {
+			return fmt.Errorf("%w %s: ledger %d is not chained to the hash of ledger %d", ErrCorruptFile, objectKey, lcm.LedgerSequence(), lcm.LedgerSequence()-1)
+		}
+	}
+	return nil
+}
+
+// verifyingDataStore checks the integrity of every file read from a datastore
+type verifyingDataStore struct {
+	datastore.DataStore
+}
+
+// WithVerification wraps a datastore so that the files read from it are checked with VerifyLedgerFile. Corrupt
+// files fail the read with a retryable error, so that the retries of the ledger backend download them again.
+func WithVerification(store datastore.DataStore) datastore.DataStore {
+	return &verifyingDataStore{DataStore: store}
+}
+
+func (s *verifyingDataStore) GetFile(ctx context.Context, filePath string) (io.ReadCloser, error) {
+	reader, err := s.DataStore.GetFile(ctx, filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	contents, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading file %s: %w", filePath, err)
+	}
+	if err := VerifyLedgerFile(contents, s.GetSchema(), filePath); err != nil {
+		return nil, TransientError(err)
+	}
+	return io.NopCloser(bytes.NewReader(contents)), nil
+}
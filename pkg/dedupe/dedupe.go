@@ -0,0 +1,111 @@
+// Package dedupe drops the rows that message queue consumers receive more than once. Pub/Sub and SQS deliver at
+// least once, and an export that is restarted publishes the rows of its last ledgers again. With --queue-dedupe-keys,
+// every message carries a key made of its ledger, its table and its position among the rows of that table in the
+// ledger, which is the same every time the ledger is exported.
+//
+//	deduper := dedupe.NewDeduper(checkpoint) // the last ledger loaded into the warehouse
+//	key, err := dedupe.ParseKey(message.Attributes[dedupe.Attribute])
+//	if deduper.Duplicate(key) {
+//		// ack and drop the message
+//	}
+//	...
+//	deduper.Checkpoint(loaded) // once the rows of every ledger up to loaded are in the warehouse
+package dedupe
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Attribute is the message attribute holding the key of a row
+const Attribute = "dedupe_key"
+
+// Key identifies a row published to a message queue. Keys increase with the ledger and, within the rows of a table in
+// a ledger, with the sequence.
+type Key struct {
+	Ledger   uint32
+	Table    string
+	Sequence uint32
+}
+
+// String encodes the key as <ledger>:<table>:<sequence>, with the ledger and sequence zero padded so that the keys of
+// a table sort in the order the rows were exported
+func (k Key) String() string {
+	return fmt.Sprintf("%010d:%s:%010d", k.Ledger, k.Table, k.Sequence)
+}
+
+// ParseKey decodes a key encoded by String
+func ParseKey(encoded string) (Key, error) {
+	parts := strings.Split(encoded, ":")
+	if len(parts) != 3 || parts[1] == "" {
+		return Key{}, fmt.Errorf("dedupe key %q is not of the form <ledger>:<table>:<sequence>", encoded)
+	}
+
+	ledger, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid ledger in dedupe key %q: %v", encoded, err)
+	}
+
+	sequence, err := strconv.ParseUint(parts[2], 10, 32)
+	if err != nil {
+		return Key{}, fmt.Errorf("invalid sequence in dedupe key %q: %v", encoded, err)
+	}
+
+	return Key{Ledger: uint32(ledger), Table: parts[1], Sequence: uint32(sequence)}, nil
+}
+
+// Deduper remembers the keys of the rows received since its checkpoint. The rows of the ledgers up to the checkpoint
+// are already loaded, so their keys are not kept. It is safe for concurrent use.
+type Deduper struct {
+	mu         sync.Mutex
+	checkpoint uint32
+	seen       map[Key]struct{}
+}
+
+// NewDeduper returns a deduper that drops the rows of the ledgers up to checkpoint, which is the last ledger whose
+// rows were loaded, or 0 if none were
+func NewDeduper(checkpoint uint32) *Deduper {
+	return &Deduper{checkpoint: checkpoint, seen: map[Key]struct{}{}}
+}
+
+// Duplicate returns whether the row of the key was received before, and records it otherwise
+func (d *Deduper) Duplicate(key Key) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if key.Ledger <= d.checkpoint {
+		return true
+	}
+	if _, ok := d.seen[key]; ok {
+		return true
+	}
+	d.seen[key] = struct{}{}
+	return false
+}
+
+// Checkpoint records that the rows of every ledger up to ledger are loaded, and forgets their keys. The checkpoint
+// should be stored with the loaded rows, so that a restarted consumer can pass it to NewDeduper. Checkpoints below
+// the current one are ignored.
+func (d *Deduper) Checkpoint(ledger uint32) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if ledger <= d.checkpoint {
+		return
+	}
+	d.checkpoint = ledger
+	for key := range d.seen {
+		if key.Ledger <= ledger {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// Checkpointed returns the last ledger whose rows are loaded
+func (d *Deduper) Checkpointed() uint32 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.checkpoint
+}
@@ -0,0 +1,43 @@
+package dedupe
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey(t *testing.T) {
+	key := Key{Ledger: 52000000, Table: "effects", Sequence: 12}
+	assert.Equal(t, "0052000000:effects:0000000012", key.String())
+
+	parsed, err := ParseKey(key.String())
+	require.NoError(t, err)
+	assert.Equal(t, key, parsed)
+
+	_, err = ParseKey("52000000:effects")
+	assert.EqualError(t, err, `dedupe key "52000000:effects" is not of the form <ledger>:<table>:<sequence>`)
+
+	_, err = ParseKey("52000000:effects:x")
+	assert.Error(t, err)
+}
+
+func TestDeduper(t *testing.T) {
+	deduper := NewDeduper(10)
+
+	assert.True(t, deduper.Duplicate(Key{Ledger: 10, Table: "effects"}))
+	assert.False(t, deduper.Duplicate(Key{Ledger: 11, Table: "effects"}))
+	assert.True(t, deduper.Duplicate(Key{Ledger: 11, Table: "effects"}))
+	assert.False(t, deduper.Duplicate(Key{Ledger: 11, Table: "effects", Sequence: 1}))
+	assert.False(t, deduper.Duplicate(Key{Ledger: 11, Table: "operations"}))
+	assert.False(t, deduper.Duplicate(Key{Ledger: 12, Table: "effects"}))
+
+	deduper.Checkpoint(11)
+	assert.Equal(t, uint32(11), deduper.Checkpointed())
+	assert.Len(t, deduper.seen, 1)
+	assert.True(t, deduper.Duplicate(Key{Ledger: 11, Table: "operations", Sequence: 5}))
+	assert.True(t, deduper.Duplicate(Key{Ledger: 12, Table: "effects"}))
+
+	deduper.Checkpoint(5)
+	assert.Equal(t, uint32(11), deduper.Checkpointed())
+}
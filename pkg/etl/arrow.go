@@ -0,0 +1,236 @@
+package etl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+// Schema returns the Arrow schema of the record batches of a table. Nested structs are struct columns, lists are
+// list columns and json values, such as operation details, are string columns holding the serialized json.
+func Schema(table string) (*arrow.Schema, error) {
+	tables, err := input.SelectLedgerTables([]string{table})
+	if err != nil {
+		return nil, err
+	}
+	return arrowSchema(transform.SchemaFields(tables[0].Output)), nil
+}
+
+// WriteFeather writes record batches of the same schema as a Feather (Arrow IPC) file
+func WriteFeather(w io.WriteSeeker, schema *arrow.Schema, records []array.Record) error {
+	writer, err := ipc.NewFileWriter(w, ipc.WithSchema(schema))
+	if err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := writer.Write(record); err != nil {
+			writer.Close()
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func arrowSchema(fields []transform.SchemaField) *arrow.Schema {
+	return arrow.NewSchema(arrowFields(fields), nil)
+}
+
+func arrowFields(fields []transform.SchemaField) []arrow.Field {
+	columns := make([]arrow.Field, len(fields))
+	for i, field := range fields {
+		column := arrow.Field{Name: field.Name, Type: arrowType(field), Nullable: true}
+		if field.Description != "" {
+			column.Metadata = arrow.NewMetadata([]string{"description"}, []string{field.Description})
+		}
+		columns[i] = column
+	}
+	return columns
+}
+
+func arrowType(field transform.SchemaField) arrow.DataType {
+	var dataType arrow.DataType
+	switch field.Type {
+	case "INTEGER":
+		dataType = arrow.PrimitiveTypes.Int64
+	case "FLOAT":
+		dataType = arrow.PrimitiveTypes.Float64
+	case "BOOLEAN":
+		dataType = arrow.FixedWidthTypes.Boolean
+	case "TIMESTAMP":
+		dataType = arrow.FixedWidthTypes.Timestamp_us
+	case "BYTES":
+		dataType = arrow.BinaryTypes.Binary
+	case "RECORD":
+		dataType = arrow.StructOf(arrowFields(field.Fields)...)
+	default:
+		dataType = arrow.BinaryTypes.String
+	}
+
+	if field.Repeated {
+		return arrow.ListOf(dataType)
+	}
+	return dataType
+}
+
+// recordBuilder converts the rows of a table into record batches. Rows are converted through their json encoding,
+// so the columns hold the same values the export commands write.
+type recordBuilder struct {
+	fields  []transform.SchemaField
+	builder *array.RecordBuilder
+	rows    int
+}
+
+func newRecordBuilder(mem memory.Allocator, output interface{}) *recordBuilder {
+	fields := transform.SchemaFields(output)
+	return &recordBuilder{
+		fields:  fields,
+		builder: array.NewRecordBuilder(mem, arrowSchema(fields)),
+	}
+}
+
+func (b *recordBuilder) append(row interface{}) error {
+	marshalled, err := json.Marshal(row)
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(marshalled))
+	decoder.UseNumber()
+	var values map[string]interface{}
+	if err := decoder.Decode(&values); err != nil {
+		return err
+	}
+
+	for i, field := range b.fields {
+		if err := appendValue(b.builder.Field(i), field, values[field.Name]); err != nil {
+			return fmt.Errorf("column %s: %w", field.Name, err)
+		}
+	}
+	b.rows++
+
+	return nil
+}
+
+// newRecord returns the rows appended so far as a record batch and resets the builder
+func (b *recordBuilder) newRecord() array.Record {
+	b.rows = 0
+	return b.builder.NewRecord()
+}
+
+func (b *recordBuilder) release() {
+	b.builder.Release()
+}
+
+func appendValue(builder array.Builder, field transform.SchemaField, value interface{}) error {
+	if value == nil {
+		builder.AppendNull()
+		return nil
+	}
+
+	if field.Repeated {
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("expected a list, got %T", value)
+		}
+		listBuilder := builder.(*array.ListBuilder)
+		listBuilder.Append(true)
+		item := field
+		item.Repeated = false
+		for _, itemValue := range items {
+			if err := appendValue(listBuilder.ValueBuilder(), item, itemValue); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch field.Type {
+	case "JSON":
+		marshalled, err := transform.CanonicalJSON(value)
+		if err != nil {
+			return err
+		}
+		builder.(*array.StringBuilder).Append(string(marshalled))
+	case "STRING":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string, got %T", value)
+		}
+		builder.(*array.StringBuilder).Append(s)
+	case "INTEGER":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number, got %T", value)
+		}
+		i, err := number.Int64()
+		if err != nil {
+			return err
+		}
+		builder.(*array.Int64Builder).Append(i)
+	case "FLOAT":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("expected a number, got %T", value)
+		}
+		f, err := number.Float64()
+		if err != nil {
+			return err
+		}
+		builder.(*array.Float64Builder).Append(f)
+	case "BOOLEAN":
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("expected a boolean, got %T", value)
+		}
+		builder.(*array.BooleanBuilder).Append(b)
+	case "TIMESTAMP":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a timestamp, got %T", value)
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return err
+		}
+		builder.(*array.TimestampBuilder).Append(arrow.Timestamp(t.UnixMicro()))
+	case "BYTES":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected base64 encoded bytes, got %T", value)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return err
+		}
+		builder.(*array.BinaryBuilder).Append(decoded)
+	case "RECORD":
+		values, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected an object, got %T", value)
+		}
+		structBuilder := builder.(*array.StructBuilder)
+		structBuilder.Append(true)
+		for i, child := range field.Fields {
+			if err := appendValue(structBuilder.FieldBuilder(i), child, values[child.Name]); err != nil {
+				return fmt.Errorf("%s: %w", child.Name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown column type %s", field.Type)
+	}
+
+	return nil
+}
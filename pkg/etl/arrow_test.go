@@ -0,0 +1,108 @@
+package etl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/guregu/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+func TestRecordBuilder(t *testing.T) {
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	builder := newRecordBuilder(mem, transform.ClaimableBalanceOutput{})
+	defer builder.release()
+
+	predicate := map[string]interface{}{"unconditional": true}
+	require.NoError(t, builder.append(transform.ClaimableBalanceOutput{
+		BalanceID:   "000000000a12cd57c169a34e7794bdcdf2d093fab135c59ea599e2d1233d7a53f26c1464",
+		Claimants:   []transform.Claimant{{Destination: "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ", Predicate: predicate}},
+		AssetAmount: 10.5,
+		ClosedAt:    time.Date(2020, time.July, 9, 5, 28, 42, 0, time.UTC),
+	}))
+	require.NoError(t, builder.append(transform.ClaimableBalanceOutput{Sponsor: null.StringFrom("GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A")}))
+	assert.Equal(t, 2, builder.rows)
+
+	record := builder.newRecord()
+	defer record.Release()
+	assert.Equal(t, 0, builder.rows)
+	require.Equal(t, int64(2), record.NumRows())
+
+	column := func(name string) array.Interface {
+		return record.Column(record.Schema().FieldIndices(name)[0])
+	}
+
+	sponsor := column("sponsor").(*array.String)
+	assert.True(t, sponsor.IsNull(0))
+	assert.Equal(t, "GAHK7EEG2WWHVKDNT4CEQFZGKF2LGDSW2IVM4S5DP42RBW3K6BTODB4A", sponsor.Value(1))
+
+	assert.Equal(t, 10.5, column("asset_amount").(*array.Float64).Value(0))
+	assert.Equal(t, int64(1594272522000000), int64(column("closed_at").(*array.Timestamp).Value(0)))
+
+	claimants := column("claimants").(*array.List)
+	assert.True(t, claimants.IsNull(1))
+	claimant := claimants.ListValues().(*array.Struct)
+	require.Equal(t, 1, claimant.Len())
+	assert.Equal(t, "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ", claimant.Field(0).(*array.String).Value(0))
+	assert.JSONEq(t, `{"unconditional":true}`, claimant.Field(1).(*array.String).Value(0))
+}
+
+func TestRecordBuilderTables(t *testing.T) {
+	for _, table := range input.LedgerTables() {
+		mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+		builder := newRecordBuilder(mem, table.Output)
+		assert.NoError(t, builder.append(table.Output), "table %s", table.Name)
+
+		record := builder.newRecord()
+		assert.Equal(t, int64(1), record.NumRows(), "table %s", table.Name)
+		record.Release()
+		builder.release()
+		mem.AssertSize(t, 0)
+	}
+}
+
+func TestSchema(t *testing.T) {
+	schema, err := Schema("ledgers")
+	require.NoError(t, err)
+	field := schema.Field(schema.FieldIndices("sequence")[0])
+	assert.Equal(t, []string{"description"}, field.Metadata.Keys())
+	assert.Equal(t, []string{"sequence number of the ledger"}, field.Metadata.Values())
+
+	_, err = Schema("unknown")
+	assert.EqualError(t, err, "unknown table unknown")
+}
+
+func TestWriteFeather(t *testing.T) {
+	builder := newRecordBuilder(memory.NewGoAllocator(), transform.LedgerOutput{})
+	defer builder.release()
+	require.NoError(t, builder.append(transform.LedgerOutput{Sequence: 10}))
+	record := builder.newRecord()
+	defer record.Release()
+
+	path := filepath.Join(t.TempDir(), "ledgers.feather")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, WriteFeather(file, record.Schema(), []array.Record{record}))
+	require.NoError(t, file.Close())
+
+	file, err = os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := ipc.NewFileReader(file)
+	require.NoError(t, err)
+	defer reader.Close()
+
+	require.Equal(t, 1, reader.NumRecords())
+	read, err := reader.Record(0)
+	require.NoError(t, err)
+	assert.True(t, array.RecordEqual(record, read))
+}
@@ -0,0 +1,33 @@
+package etl
+
+import (
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+// TransformError is the error returned by the transforms, with the code and the coordinates of what was being
+// transformed: network, ledger, transaction, operation and ledger entry type
+type TransformError = transform.TransformError
+
+// ErrorCode is the machine-readable class of a TransformError
+type ErrorCode = transform.ErrorCode
+
+const (
+	ErrorCodeInvalidLedger      = transform.ErrorCodeInvalidLedger
+	ErrorCodeInvalidTransaction = transform.ErrorCodeInvalidTransaction
+	ErrorCodeInvalidOperation   = transform.ErrorCodeInvalidOperation
+	ErrorCodeInvalidEffect      = transform.ErrorCodeInvalidEffect
+	ErrorCodeInvalidTrade       = transform.ErrorCodeInvalidTrade
+	ErrorCodeInvalidEvent       = transform.ErrorCodeInvalidEvent
+	ErrorCodeInvalidLedgerEntry = transform.ErrorCodeInvalidLedgerEntry
+	ErrorCodeUnsupportedVersion = transform.ErrorCodeUnsupportedVersion
+	ErrorCodeUnknown            = transform.ErrorCodeUnknown
+)
+
+// MaxSupportedProtocol is the latest protocol whose ledgers the transforms can read. Older ledgers that carry parts
+// their protocol does not allow fail with ErrorCodeUnsupportedVersion, as do the ledgers of newer protocols.
+const MaxSupportedProtocol = transform.MaxSupportedProtocol
+
+// ErrorCodeOf returns the code of a TransformError, or ErrorCodeUnknown if the error does not wrap one
+func ErrorCodeOf(err error) ErrorCode {
+	return transform.ErrorCodeOf(err)
+}
@@ -0,0 +1,21 @@
+package etl
+
+import (
+	"time"
+
+	"github.com/stellar/go/historyarchive"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+)
+
+// LedgerForTime returns the sequence of the ledger containing closeTime, which is the first ledger that closed on or
+// after it, by binary searching the ledger headers of the history archive. Times before the network started resolve
+// to ledger 2 and times after the most recent ledger of the archive resolve to it, so that the sequences can bound
+// the range of a pipeline.
+func LedgerForTime(archive historyarchive.ArchiveInterface, closeTime time.Time) (uint32, error) {
+	ledger, err := input.LedgerForTime(archive, closeTime)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(ledger), nil
+}
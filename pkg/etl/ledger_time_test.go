@@ -0,0 +1,27 @@
+package etl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stellar/go/historyarchive"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLedgerForTime(t *testing.T) {
+	archive := &historyarchive.MockArchive{}
+	archive.On("GetRootHAS").Return(historyarchive.HistoryArchiveState{CurrentLedger: 10}, nil)
+	for seq := uint32(2); seq <= 10; seq++ {
+		header := xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{
+			LedgerSeq: xdr.Uint32(seq),
+			ScpValue:  xdr.StellarValue{CloseTime: xdr.TimePoint(100 * seq)},
+		}}
+		archive.On("GetLedgerHeader", seq).Return(header, nil).Maybe()
+	}
+
+	ledger, err := LedgerForTime(archive, time.Unix(650, 0))
+	require.NoError(t, err)
+	assert.Equal(t, uint32(7), ledger)
+}
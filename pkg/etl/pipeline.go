@@ -0,0 +1,159 @@
+// Package etl exposes the stellar-etl transforms as a library, so that Go services can run them in process and
+// get the exported tables as Arrow record batches instead of running the export commands and parsing their files.
+//
+//	backend := ledgerbackend.NewBufferedStorageBackend(...)
+//	records, err := etl.NewPipeline(etl.Config{
+//		Backend:           backend,
+//		NetworkPassphrase: network.PublicNetworkPassphrase,
+//		StartLedger:       52000000,
+//		EndLedger:         52000100,
+//		Tables:            []string{"transactions", "operations"},
+//	}).Run(ctx)
+package etl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/stellar/go/ingest/ledgerbackend"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+)
+
+// DefaultBatchSize is the maximum number of rows in a record batch when Config.BatchSize is not set
+const DefaultBatchSize = 10000
+
+// Config holds the ledgers and tables a pipeline transforms
+type Config struct {
+	// Backend is the source of the ledgers. The pipeline prepares the range of ledgers on it but does not close it.
+	Backend           ledgerbackend.LedgerBackend
+	NetworkPassphrase string
+	// StartLedger and EndLedger are the range of ledgers to transform, inclusive on both ends
+	StartLedger uint32
+	EndLedger   uint32
+	// Tables are the names of the tables to transform, such as transactions or operations; all of them if empty
+	Tables []string
+	// BatchSize is the maximum number of rows in a record batch; DefaultBatchSize if 0
+	BatchSize int
+	// Allocator allocates the memory of the record batches; a Go allocator if nil
+	Allocator memory.Allocator
+	// ReadAhead is how many ledgers are read ahead of the one being converted; twice TransformWorkers if 0
+	ReadAhead int
+	// TransformWorkers is how many ledgers are transformed at once; the number of CPUs if 0
+	TransformWorkers int
+}
+
+// Pipeline transforms a range of ledgers into Arrow record batches
+type Pipeline struct {
+	config Config
+}
+
+// Tables returns the names of the tables a pipeline can transform
+func Tables() []string {
+	names := []string{}
+	for _, table := range input.LedgerTables() {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+// NewPipeline creates a pipeline; the config is validated when the pipeline is run
+func NewPipeline(config Config) *Pipeline {
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultBatchSize
+	}
+	if config.Allocator == nil {
+		config.Allocator = memory.NewGoAllocator()
+	}
+	return &Pipeline{config: config}
+}
+
+func (p *Pipeline) validate() error {
+	if p.config.Backend == nil {
+		return errors.New("a ledger backend is required")
+	}
+	if p.config.NetworkPassphrase == "" {
+		return errors.New("a network passphrase is required")
+	}
+	if p.config.StartLedger == 0 || p.config.EndLedger < p.config.StartLedger {
+		return fmt.Errorf("invalid ledger range [%d, %d]", p.config.StartLedger, p.config.EndLedger)
+	}
+	return nil
+}
+
+// Run transforms the ledgers of the range and returns the record batches of every table, keyed by table name.
+// The columns of the records are the fields of the rows as they are written by the export commands, see Schema.
+// The caller owns the records and should release them once done. If a ledger cannot be read or transformed, the
+// records built so far are released and the error is returned.
+func (p *Pipeline) Run(ctx context.Context) (map[string][]array.Record, error) {
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+
+	tables, err := input.SelectLedgerTables(p.config.Tables)
+	if err != nil {
+		return nil, err
+	}
+
+	builders := make([]*recordBuilder, len(tables))
+	for i, table := range tables {
+		builders[i] = newRecordBuilder(p.config.Allocator, table.Output)
+	}
+	defer func() {
+		for _, builder := range builders {
+			builder.release()
+		}
+	}()
+
+	records := map[string][]array.Record{}
+	fail := func(err error) (map[string][]array.Record, error) {
+		releaseRecords(records)
+		return nil, err
+	}
+
+	backend := p.config.Backend
+	if err := backend.PrepareRange(ctx, ledgerbackend.BoundedRange(p.config.StartLedger, p.config.EndLedger)); err != nil {
+		return fail(fmt.Errorf("could not prepare range [%d, %d]: %w", p.config.StartLedger, p.config.EndLedger, err))
+	}
+
+	stageOptions := input.StageOptions{ReadAhead: p.config.ReadAhead, TransformWorkers: p.config.TransformWorkers}
+	err = input.TransformLedgerRange(ctx, backend, p.config.StartLedger, p.config.EndLedger, p.config.NetworkPassphrase, tables, stageOptions, func(ledger input.TransformedLedger) error {
+		for i, table := range tables {
+			if err := ledger.Errors[i]; err != nil {
+				return fmt.Errorf("could not transform %s in ledger %d: %w", table.Name, ledger.Sequence, err)
+			}
+
+			for _, row := range ledger.Rows[i] {
+				if err := builders[i].append(row); err != nil {
+					return fmt.Errorf("could not convert %s row in ledger %d: %w", table.Name, ledger.Sequence, err)
+				}
+				if builders[i].rows >= p.config.BatchSize {
+					records[table.Name] = append(records[table.Name], builders[i].newRecord())
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fail(err)
+	}
+
+	for i, table := range tables {
+		if builders[i].rows > 0 {
+			records[table.Name] = append(records[table.Name], builders[i].newRecord())
+		}
+	}
+
+	return records, nil
+}
+
+func releaseRecords(records map[string][]array.Record) {
+	for _, tableRecords := range records {
+		for _, record := range tableRecords {
+			record.Release()
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package etl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/memory"
+	"github.com/stellar/go/ingest/ledgerbackend"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func makePipelineTestLedger(seq uint32) xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{
+		V: 1,
+		V1: &xdr.LedgerCloseMetaV1{
+			LedgerHeader: xdr.LedgerHeaderHistoryEntry{
+				Header: xdr.LedgerHeader{
+					ScpValue:      xdr.StellarValue{CloseTime: xdr.TimePoint(1000 + seq)},
+					LedgerSeq:     xdr.Uint32(seq),
+					LedgerVersion: 22,
+				},
+			},
+			TxSet: xdr.GeneralizedTransactionSet{
+				V:       1,
+				V1TxSet: &xdr.TransactionSetV1{},
+			},
+		},
+	}
+}
+
+func TestPipelineRun(t *testing.T) {
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makePipelineTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(makePipelineTestLedger(11), nil)
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	records, err := NewPipeline(Config{
+		Backend:           backend,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		StartLedger:       10,
+		EndLedger:         11,
+		Tables:            []string{"ledgers", "transactions"},
+		BatchSize:         1,
+		Allocator:         mem,
+	}).Run(context.Background())
+	require.NoError(t, err)
+	backend.AssertExpectations(t)
+
+	assert.NotContains(t, records, "transactions")
+	require.Len(t, records["ledgers"], 2)
+	for i, record := range records["ledgers"] {
+		require.Equal(t, int64(1), record.NumRows())
+		schema, err := Schema("ledgers")
+		require.NoError(t, err)
+		assert.True(t, schema.Equal(record.Schema()))
+
+		sequence := record.Column(schema.FieldIndices("sequence")[0]).(*array.Int64)
+		assert.Equal(t, int64(10+i), sequence.Value(0))
+		closedAt := record.Column(schema.FieldIndices("closed_at")[0]).(*array.Timestamp)
+		assert.Equal(t, int64(1010+i)*1000000, int64(closedAt.Value(0)))
+	}
+
+	releaseRecords(records)
+	mem.AssertSize(t, 0)
+}
+
+func TestPipelineRunErrors(t *testing.T) {
+	_, err := NewPipeline(Config{NetworkPassphrase: network.TestNetworkPassphrase, StartLedger: 1, EndLedger: 1}).Run(context.Background())
+	assert.EqualError(t, err, "a ledger backend is required")
+
+	backend := &ledgerbackend.MockDatabaseBackend{}
+	_, err = NewPipeline(Config{Backend: backend, NetworkPassphrase: network.TestNetworkPassphrase, StartLedger: 2, EndLedger: 1}).Run(context.Background())
+	assert.EqualError(t, err, "invalid ledger range [2, 1]")
+
+	_, err = NewPipeline(Config{Backend: backend, NetworkPassphrase: network.TestNetworkPassphrase, StartLedger: 1, EndLedger: 1, Tables: []string{"unknown"}}).Run(context.Background())
+	assert.EqualError(t, err, "unknown table unknown")
+
+	backend.On("PrepareRange", mock.Anything, ledgerbackend.BoundedRange(10, 11)).Return(nil)
+	backend.On("GetLedger", mock.Anything, uint32(10)).Return(makePipelineTestLedger(10), nil)
+	backend.On("GetLedger", mock.Anything, uint32(11)).Return(xdr.LedgerCloseMeta{}, errors.New("unavailable"))
+
+	mem := memory.NewCheckedAllocator(memory.NewGoAllocator())
+	_, err = NewPipeline(Config{
+		Backend:           backend,
+		NetworkPassphrase: network.TestNetworkPassphrase,
+		StartLedger:       10,
+		EndLedger:         11,
+		BatchSize:         1,
+		Allocator:         mem,
+	}).Run(context.Background())
+	assert.EqualError(t, err, "error getting ledger seq 11 from the backend: unavailable")
+	mem.AssertSize(t, 0)
+}
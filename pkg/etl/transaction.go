@@ -0,0 +1,108 @@
+package etl
+
+import (
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/xdr"
+
+	"github.com/stellar/stellar-etl/v2/internal/input"
+	"github.com/stellar/stellar-etl/v2/internal/toid"
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+	"github.com/stellar/stellar-etl/v2/internal/utils"
+	"github.com/stellar/stellar-etl/v2/pkg/schema"
+)
+
+// LedgerInfo is the ledger of a transaction read by the caller's own ingestion, such as from an
+// ingest.LedgerTransactionReader over the ledger close meta
+type LedgerInfo struct {
+	Header            xdr.LedgerHeaderHistoryEntry
+	NetworkPassphrase string
+	// ToidLedgerOffset is added to the ledger sequence of the ids of the rows, as with --toid-ledger-offset
+	ToidLedgerOffset uint32
+}
+
+// ids returns the generator of the ids of the rows of the ledger
+func (l LedgerInfo) ids() toid.Generator {
+	return toid.Generator{LedgerOffset: l.ToidLedgerOffset}
+}
+
+// closeMeta wraps the header of the ledger in a ledger close meta for the transforms that read the ledger sequence
+// and close time from one. The transaction sets and metas of the ledger are left empty; the transforms read them from
+// the transaction.
+func (l LedgerInfo) closeMeta() xdr.LedgerCloseMeta {
+	return xdr.LedgerCloseMeta{V: 0, V0: &xdr.LedgerCloseMetaV0{LedgerHeader: l.Header}}
+}
+
+// The functions below transform a single transaction into the rows it adds to a table, the same rows as the export
+// commands write for it, so that indexers that run their own ingestion can reuse the mapping of the tables they need.
+
+// TransformTransaction returns the row of the transaction in the transactions table
+func TransformTransaction(tx ingest.LedgerTransaction, ledger LedgerInfo) (schema.TransactionOutput, error) {
+	return transform.TransformTransaction(tx, ledger.Header, ledger.ids())
+}
+
+// TransformLedgerTransaction returns the row of the transaction in the ledger_transaction table
+func TransformLedgerTransaction(tx ingest.LedgerTransaction, ledger LedgerInfo) (schema.LedgerTransactionOutput, error) {
+	return transform.TransformLedgerTransaction(tx, ledger.Header)
+}
+
+// TransformFee returns the row of the fee charged to the transaction in the fees table
+func TransformFee(tx ingest.LedgerTransaction, ledger LedgerInfo) (schema.FeeOutput, error) {
+	return transform.TransformFee(tx, ledger.Header, ledger.NetworkPassphrase, ledger.ids())
+}
+
+// TransformOperations returns the rows of the operations of the transaction, in order
+func TransformOperations(tx ingest.LedgerTransaction, ledger LedgerInfo) ([]schema.OperationOutput, error) {
+	ledgerSeq := int32(ledger.Header.Header.LedgerSeq)
+	operations := []schema.OperationOutput{}
+	for index, op := range tx.Envelope.Operations() {
+		transformed, err := transform.TransformOperation(op, int32(index), tx, ledgerSeq, ledger.closeMeta(), ledger.NetworkPassphrase, ledger.ids())
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, transformed)
+	}
+	return operations, nil
+}
+
+// TransformEffects returns the rows of the effects of the operations of the transaction
+func TransformEffects(tx ingest.LedgerTransaction, ledger LedgerInfo) ([]schema.EffectOutput, error) {
+	return transform.TransformEffect(tx, uint32(ledger.Header.Header.LedgerSeq), ledger.closeMeta(), ledger.NetworkPassphrase, ledger.ids())
+}
+
+// TransformTrades returns the rows of the trades made by the operations of the transaction. Failed transactions
+// make no trades.
+func TransformTrades(tx ingest.LedgerTransaction, ledger LedgerInfo) ([]schema.TradeOutput, error) {
+	trades := []schema.TradeOutput{}
+	if !tx.Result.Successful() {
+		return trades, nil
+	}
+
+	closeTime, err := utils.ExtractLedgerCloseTime(ledger.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	ledgerSeq := int32(ledger.Header.Header.LedgerSeq)
+	for index, op := range tx.Envelope.Operations() {
+		if !input.OperationResultsInTrade(op) {
+			continue
+		}
+		operationID := ledger.ids().New(ledgerSeq, int32(tx.Index), int32(index))
+		transformed, err := transform.TransformTrade(int32(index), operationID, tx, closeTime, ledger.ids())
+		if err != nil {
+			return nil, err
+		}
+		trades = append(trades, transformed...)
+	}
+	return trades, nil
+}
+
+// TransformContractEvents returns the rows of the contract events emitted by the transaction
+func TransformContractEvents(tx ingest.LedgerTransaction, ledger LedgerInfo) ([]schema.ContractEventOutput, error) {
+	return transform.TransformContractEvent(tx, ledger.Header, ledger.ids())
+}
+
+// TransformOfferEvents returns the rows of the offer events of the transaction
+func TransformOfferEvents(tx ingest.LedgerTransaction, ledger LedgerInfo) ([]schema.OfferEventOutput, error) {
+	return transform.TransformOfferEvent(tx, ledger.Header, ledger.ids())
+}
@@ -0,0 +1,72 @@
+package etl
+
+import (
+	"testing"
+
+	"github.com/stellar/go/ingest"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTransactionTestInput(code xdr.TransactionResultCode) (ingest.LedgerTransaction, LedgerInfo) {
+	source := xdr.MustMuxedAddress(keypair.MustRandom().Address())
+	bumpTo := xdr.SequenceNumber(100)
+	bumped := xdr.OperationResult{Code: xdr.OperationResultCodeOpInner, Tr: &xdr.OperationResultTr{
+		Type:          xdr.OperationTypeBumpSequence,
+		BumpSeqResult: &xdr.BumpSequenceResult{Code: xdr.BumpSequenceResultCodeBumpSequenceSuccess},
+	}}
+	tx := ingest.LedgerTransaction{
+		Index: 1,
+		Envelope: xdr.TransactionEnvelope{
+			Type: xdr.EnvelopeTypeEnvelopeTypeTx,
+			V1: &xdr.TransactionV1Envelope{
+				Tx: xdr.Transaction{
+					SourceAccount: source,
+					SeqNum:        10,
+					Operations: []xdr.Operation{
+						{Body: xdr.OperationBody{Type: xdr.OperationTypeBumpSequence, BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: bumpTo}}},
+						{Body: xdr.OperationBody{Type: xdr.OperationTypeBumpSequence, BumpSequenceOp: &xdr.BumpSequenceOp{BumpTo: bumpTo + 1}}},
+					},
+				},
+			},
+		},
+		Result: xdr.TransactionResultPair{
+			Result: xdr.TransactionResult{
+				Result: xdr.TransactionResultResult{Code: code, Results: &[]xdr.OperationResult{bumped, bumped}},
+			},
+		},
+		UnsafeMeta: xdr.TransactionMeta{V: 1, V1: &xdr.TransactionMetaV1{}},
+	}
+	ledger := LedgerInfo{
+		Header: xdr.LedgerHeaderHistoryEntry{Header: xdr.LedgerHeader{
+			LedgerSeq: 30,
+			ScpValue:  xdr.StellarValue{CloseTime: 1000},
+		}},
+		NetworkPassphrase: network.TestNetworkPassphrase,
+	}
+	return tx, ledger
+}
+
+func TestTransformOperations(t *testing.T) {
+	tx, ledger := makeTransactionTestInput(xdr.TransactionResultCodeTxSuccess)
+
+	operations, err := TransformOperations(tx, ledger)
+	require.NoError(t, err)
+	require.Len(t, operations, 2)
+	for i, operation := range operations {
+		assert.Equal(t, uint32(30), operation.LedgerSequence)
+		assert.Equal(t, int64(30)<<32+int64(1)<<12+int64(i+1), operation.OperationID)
+		assert.Equal(t, int64(1000), operation.ClosedAt.Unix())
+	}
+}
+
+func TestTransformTradesOfFailedTransaction(t *testing.T) {
+	tx, ledger := makeTransactionTestInput(xdr.TransactionResultCodeTxFailed)
+
+	trades, err := TransformTrades(tx, ledger)
+	require.NoError(t, err)
+	assert.Empty(t, trades)
+}
@@ -0,0 +1,559 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.34.2
+// 	protoc        (unknown)
+// source: stellar_etl/v1/transform.proto
+
+package etlpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TransformRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Tables to transform, such as transactions or operations; all of them if empty.
+	Tables []string `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+	// Types that are assignable to Source:
+	//	*TransformRequest_Range
+	//	*TransformRequest_Ledgers
+	Source isTransformRequest_Source `protobuf_oneof:"source"`
+}
+
+func (x *TransformRequest) Reset() {
+	*x = TransformRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TransformRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransformRequest) ProtoMessage() {}
+
+func (x *TransformRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransformRequest.ProtoReflect.Descriptor instead.
+func (*TransformRequest) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TransformRequest) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+func (m *TransformRequest) GetSource() isTransformRequest_Source {
+	if m != nil {
+		return m.Source
+	}
+	return nil
+}
+
+func (x *TransformRequest) GetRange() *LedgerRange {
+	if x, ok := x.GetSource().(*TransformRequest_Range); ok {
+		return x.Range
+	}
+	return nil
+}
+
+func (x *TransformRequest) GetLedgers() *LedgerCloseMetas {
+	if x, ok := x.GetSource().(*TransformRequest_Ledgers); ok {
+		return x.Ledgers
+	}
+	return nil
+}
+
+type isTransformRequest_Source interface {
+	isTransformRequest_Source()
+}
+
+type TransformRequest_Range struct {
+	// Range of ledgers read from the datastore of the server.
+	Range *LedgerRange `protobuf:"bytes,2,opt,name=range,proto3,oneof"`
+}
+
+type TransformRequest_Ledgers struct {
+	// Ledgers provided by the caller.
+	Ledgers *LedgerCloseMetas `protobuf:"bytes,3,opt,name=ledgers,proto3,oneof"`
+}
+
+func (*TransformRequest_Range) isTransformRequest_Source() {}
+
+func (*TransformRequest_Ledgers) isTransformRequest_Source() {}
+
+type LedgerRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// First ledger of the range, inclusive.
+	StartLedger uint32 `protobuf:"varint,1,opt,name=start_ledger,json=startLedger,proto3" json:"start_ledger,omitempty"`
+	// Last ledger of the range, inclusive.
+	EndLedger uint32 `protobuf:"varint,2,opt,name=end_ledger,json=endLedger,proto3" json:"end_ledger,omitempty"`
+}
+
+func (x *LedgerRange) Reset() {
+	*x = LedgerRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LedgerRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerRange) ProtoMessage() {}
+
+func (x *LedgerRange) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerRange.ProtoReflect.Descriptor instead.
+func (*LedgerRange) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *LedgerRange) GetStartLedger() uint32 {
+	if x != nil {
+		return x.StartLedger
+	}
+	return 0
+}
+
+func (x *LedgerRange) GetEndLedger() uint32 {
+	if x != nil {
+		return x.EndLedger
+	}
+	return 0
+}
+
+type LedgerCloseMetas struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// XDR encoded LedgerCloseMeta of every ledger.
+	LedgerCloseMetas [][]byte `protobuf:"bytes,1,rep,name=ledger_close_metas,json=ledgerCloseMetas,proto3" json:"ledger_close_metas,omitempty"`
+}
+
+func (x *LedgerCloseMetas) Reset() {
+	*x = LedgerCloseMetas{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LedgerCloseMetas) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LedgerCloseMetas) ProtoMessage() {}
+
+func (x *LedgerCloseMetas) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LedgerCloseMetas.ProtoReflect.Descriptor instead.
+func (*LedgerCloseMetas) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *LedgerCloseMetas) GetLedgerCloseMetas() [][]byte {
+	if x != nil {
+		return x.LedgerCloseMetas
+	}
+	return nil
+}
+
+type Row struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Table the row belongs to.
+	Table string `protobuf:"bytes,1,opt,name=table,proto3" json:"table,omitempty"`
+	// Sequence of the ledger the row was transformed from.
+	LedgerSequence uint32 `protobuf:"varint,2,opt,name=ledger_sequence,json=ledgerSequence,proto3" json:"ledger_sequence,omitempty"`
+	// Row encoded as json, with the same fields as the rows written by the export commands.
+	Json []byte `protobuf:"bytes,3,opt,name=json,proto3" json:"json,omitempty"`
+}
+
+func (x *Row) Reset() {
+	*x = Row{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Row) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Row) ProtoMessage() {}
+
+func (x *Row) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Row.ProtoReflect.Descriptor instead.
+func (*Row) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Row) GetTable() string {
+	if x != nil {
+		return x.Table
+	}
+	return ""
+}
+
+func (x *Row) GetLedgerSequence() uint32 {
+	if x != nil {
+		return x.LedgerSequence
+	}
+	return 0
+}
+
+func (x *Row) GetJson() []byte {
+	if x != nil {
+		return x.Json
+	}
+	return nil
+}
+
+type ListTablesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListTablesRequest) Reset() {
+	*x = ListTablesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListTablesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTablesRequest) ProtoMessage() {}
+
+func (x *ListTablesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTablesRequest.ProtoReflect.Descriptor instead.
+func (*ListTablesRequest) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{4}
+}
+
+type ListTablesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Tables []string `protobuf:"bytes,1,rep,name=tables,proto3" json:"tables,omitempty"`
+}
+
+func (x *ListTablesResponse) Reset() {
+	*x = ListTablesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_stellar_etl_v1_transform_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListTablesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTablesResponse) ProtoMessage() {}
+
+func (x *ListTablesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stellar_etl_v1_transform_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTablesResponse.ProtoReflect.Descriptor instead.
+func (*ListTablesResponse) Descriptor() ([]byte, []int) {
+	return file_stellar_etl_v1_transform_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTablesResponse) GetTables() []string {
+	if x != nil {
+		return x.Tables
+	}
+	return nil
+}
+
+var File_stellar_etl_v1_transform_proto protoreflect.FileDescriptor
+
+var file_stellar_etl_v1_transform_proto_rawDesc = []byte{
+	0x0a, 0x1e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74, 0x6c, 0x2f, 0x76, 0x31,
+	0x2f, 0x74, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x0e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74, 0x6c, 0x2e, 0x76, 0x31,
+	0x22, 0xa7, 0x01, 0x0a, 0x10, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x12, 0x33, 0x0a,
+	0x05, 0x72, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x73,
+	0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65,
+	0x64, 0x67, 0x65, 0x72, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x48, 0x00, 0x52, 0x05, 0x72, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x3c, 0x0a, 0x07, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x73, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x43, 0x6c, 0x6f, 0x73, 0x65,
+	0x4d, 0x65, 0x74, 0x61, 0x73, 0x48, 0x00, 0x52, 0x07, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x73,
+	0x42, 0x08, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65, 0x22, 0x4f, 0x0a, 0x0b, 0x4c, 0x65,
+	0x64, 0x67, 0x65, 0x72, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x5f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0b, 0x73, 0x74, 0x61, 0x72, 0x74, 0x4c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
+	0x65, 0x6e, 0x64, 0x5f, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x09, 0x65, 0x6e, 0x64, 0x4c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x22, 0x40, 0x0a, 0x10, 0x4c,
+	0x65, 0x64, 0x67, 0x65, 0x72, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x73, 0x12,
+	0x2c, 0x0a, 0x12, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x5f, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x5f,
+	0x6d, 0x65, 0x74, 0x61, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0c, 0x52, 0x10, 0x6c, 0x65, 0x64,
+	0x67, 0x65, 0x72, 0x43, 0x6c, 0x6f, 0x73, 0x65, 0x4d, 0x65, 0x74, 0x61, 0x73, 0x22, 0x58, 0x0a,
+	0x03, 0x52, 0x6f, 0x77, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x12, 0x27, 0x0a, 0x0f, 0x6c, 0x65,
+	0x64, 0x67, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x0e, 0x6c, 0x65, 0x64, 0x67, 0x65, 0x72, 0x53, 0x65, 0x71, 0x75, 0x65,
+	0x6e, 0x63, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x6a, 0x73, 0x6f, 0x6e, 0x22, 0x13, 0x0a, 0x11, 0x4c, 0x69, 0x73, 0x74, 0x54,
+	0x61, 0x62, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x2c, 0x0a, 0x12,
+	0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x06, 0x74, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x32, 0xad, 0x01, 0x0a, 0x10, 0x54,
+	0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x44, 0x0a, 0x09, 0x54, 0x72, 0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x12, 0x20, 0x2e, 0x73,
+	0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72,
+	0x61, 0x6e, 0x73, 0x66, 0x6f, 0x72, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x13,
+	0x2e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74, 0x6c, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x6f, 0x77, 0x30, 0x01, 0x12, 0x53, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x62,
+	0x6c, 0x65, 0x73, 0x12, 0x21, 0x2e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x5f, 0x65, 0x74,
+	0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x62, 0x6c, 0x65, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72,
+	0x5f, 0x65, 0x74, 0x6c, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x54, 0x61, 0x62, 0x6c,
+	0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x2d, 0x5a, 0x2b, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72,
+	0x2f, 0x73, 0x74, 0x65, 0x6c, 0x6c, 0x61, 0x72, 0x2d, 0x65, 0x74, 0x6c, 0x2f, 0x76, 0x32, 0x2f,
+	0x70, 0x6b, 0x67, 0x2f, 0x65, 0x74, 0x6c, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x33,
+}
+
+var (
+	file_stellar_etl_v1_transform_proto_rawDescOnce sync.Once
+	file_stellar_etl_v1_transform_proto_rawDescData = file_stellar_etl_v1_transform_proto_rawDesc
+)
+
+func file_stellar_etl_v1_transform_proto_rawDescGZIP() []byte {
+	file_stellar_etl_v1_transform_proto_rawDescOnce.Do(func() {
+		file_stellar_etl_v1_transform_proto_rawDescData = protoimpl.X.CompressGZIP(file_stellar_etl_v1_transform_proto_rawDescData)
+	})
+	return file_stellar_etl_v1_transform_proto_rawDescData
+}
+
+var file_stellar_etl_v1_transform_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_stellar_etl_v1_transform_proto_goTypes = []any{
+	(*TransformRequest)(nil),   // 0: stellar_etl.v1.TransformRequest
+	(*LedgerRange)(nil),        // 1: stellar_etl.v1.LedgerRange
+	(*LedgerCloseMetas)(nil),   // 2: stellar_etl.v1.LedgerCloseMetas
+	(*Row)(nil),                // 3: stellar_etl.v1.Row
+	(*ListTablesRequest)(nil),  // 4: stellar_etl.v1.ListTablesRequest
+	(*ListTablesResponse)(nil), // 5: stellar_etl.v1.ListTablesResponse
+}
+var file_stellar_etl_v1_transform_proto_depIdxs = []int32{
+	1, // 0: stellar_etl.v1.TransformRequest.range:type_name -> stellar_etl.v1.LedgerRange
+	2, // 1: stellar_etl.v1.TransformRequest.ledgers:type_name -> stellar_etl.v1.LedgerCloseMetas
+	0, // 2: stellar_etl.v1.TransformService.Transform:input_type -> stellar_etl.v1.TransformRequest
+	4, // 3: stellar_etl.v1.TransformService.ListTables:input_type -> stellar_etl.v1.ListTablesRequest
+	3, // 4: stellar_etl.v1.TransformService.Transform:output_type -> stellar_etl.v1.Row
+	5, // 5: stellar_etl.v1.TransformService.ListTables:output_type -> stellar_etl.v1.ListTablesResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_stellar_etl_v1_transform_proto_init() }
+func file_stellar_etl_v1_transform_proto_init() {
+	if File_stellar_etl_v1_transform_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_stellar_etl_v1_transform_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*TransformRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stellar_etl_v1_transform_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*LedgerRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stellar_etl_v1_transform_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*LedgerCloseMetas); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stellar_etl_v1_transform_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*Row); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stellar_etl_v1_transform_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*ListTablesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_stellar_etl_v1_transform_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*ListTablesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_stellar_etl_v1_transform_proto_msgTypes[0].OneofWrappers = []any{
+		(*TransformRequest_Range)(nil),
+		(*TransformRequest_Ledgers)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_stellar_etl_v1_transform_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   6,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_stellar_etl_v1_transform_proto_goTypes,
+		DependencyIndexes: file_stellar_etl_v1_transform_proto_depIdxs,
+		MessageInfos:      file_stellar_etl_v1_transform_proto_msgTypes,
+	}.Build()
+	File_stellar_etl_v1_transform_proto = out.File
+	file_stellar_etl_v1_transform_proto_rawDesc = nil
+	file_stellar_etl_v1_transform_proto_goTypes = nil
+	file_stellar_etl_v1_transform_proto_depIdxs = nil
+}
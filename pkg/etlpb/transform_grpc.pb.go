@@ -0,0 +1,188 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             (unknown)
+// source: stellar_etl/v1/transform.proto
+
+package etlpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	TransformService_Transform_FullMethodName  = "/stellar_etl.v1.TransformService/Transform"
+	TransformService_ListTables_FullMethodName = "/stellar_etl.v1.TransformService/ListTables"
+)
+
+// TransformServiceClient is the client API for TransformService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TransformService runs the stellar-etl transforms on demand, so that other services can reuse them without
+// embedding the Go module.
+type TransformServiceClient interface {
+	// Transform streams the rows of the requested tables for a range of ledgers or for the given ledgers.
+	// Rows are streamed in ledger order; within a ledger, tables are streamed in the order they are requested.
+	Transform(ctx context.Context, in *TransformRequest, opts ...grpc.CallOption) (TransformService_TransformClient, error)
+	// ListTables returns the names of the tables that can be transformed.
+	ListTables(ctx context.Context, in *ListTablesRequest, opts ...grpc.CallOption) (*ListTablesResponse, error)
+}
+
+type transformServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTransformServiceClient(cc grpc.ClientConnInterface) TransformServiceClient {
+	return &transformServiceClient{cc}
+}
+
+func (c *transformServiceClient) Transform(ctx context.Context, in *TransformRequest, opts ...grpc.CallOption) (TransformService_TransformClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &TransformService_ServiceDesc.Streams[0], TransformService_Transform_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &transformServiceTransformClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type TransformService_TransformClient interface {
+	Recv() (*Row, error)
+	grpc.ClientStream
+}
+
+type transformServiceTransformClient struct {
+	grpc.ClientStream
+}
+
+func (x *transformServiceTransformClient) Recv() (*Row, error) {
+	m := new(Row)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *transformServiceClient) ListTables(ctx context.Context, in *ListTablesRequest, opts ...grpc.CallOption) (*ListTablesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTablesResponse)
+	err := c.cc.Invoke(ctx, TransformService_ListTables_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TransformServiceServer is the server API for TransformService service.
+// All implementations must embed UnimplementedTransformServiceServer
+// for forward compatibility
+//
+// TransformService runs the stellar-etl transforms on demand, so that other services can reuse them without
+// embedding the Go module.
+type TransformServiceServer interface {
+	// Transform streams the rows of the requested tables for a range of ledgers or for the given ledgers.
+	// Rows are streamed in ledger order; within a ledger, tables are streamed in the order they are requested.
+	Transform(*TransformRequest, TransformService_TransformServer) error
+	// ListTables returns the names of the tables that can be transformed.
+	ListTables(context.Context, *ListTablesRequest) (*ListTablesResponse, error)
+	mustEmbedUnimplementedTransformServiceServer()
+}
+
+// UnimplementedTransformServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedTransformServiceServer struct {
+}
+
+func (UnimplementedTransformServiceServer) Transform(*TransformRequest, TransformService_TransformServer) error {
+	return status.Errorf(codes.Unimplemented, "method Transform not implemented")
+}
+func (UnimplementedTransformServiceServer) ListTables(context.Context, *ListTablesRequest) (*ListTablesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTables not implemented")
+}
+func (UnimplementedTransformServiceServer) mustEmbedUnimplementedTransformServiceServer() {}
+
+// UnsafeTransformServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TransformServiceServer will
+// result in compilation errors.
+type UnsafeTransformServiceServer interface {
+	mustEmbedUnimplementedTransformServiceServer()
+}
+
+func RegisterTransformServiceServer(s grpc.ServiceRegistrar, srv TransformServiceServer) {
+	s.RegisterService(&TransformService_ServiceDesc, srv)
+}
+
+func _TransformService_Transform_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(TransformRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(TransformServiceServer).Transform(m, &transformServiceTransformServer{ServerStream: stream})
+}
+
+type TransformService_TransformServer interface {
+	Send(*Row) error
+	grpc.ServerStream
+}
+
+type transformServiceTransformServer struct {
+	grpc.ServerStream
+}
+
+func (x *transformServiceTransformServer) Send(m *Row) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _TransformService_ListTables_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTablesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TransformServiceServer).ListTables(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TransformService_ListTables_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TransformServiceServer).ListTables(ctx, req.(*ListTablesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TransformService_ServiceDesc is the grpc.ServiceDesc for TransformService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TransformService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "stellar_etl.v1.TransformService",
+	HandlerType: (*TransformServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTables",
+			Handler:    _TransformService_ListTables_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Transform",
+			Handler:       _TransformService_Transform_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "stellar_etl/v1/transform.proto",
+}
@@ -0,0 +1,114 @@
+// Package ledgerkey encodes ledger keys the way the stellar-etl tables export them, so that the keys of the
+// ttls, archival history, trustlines and effects tables can be joined with each other and with keys computed
+// outside of the etl.
+//
+//	encoded, err := ledgerkey.Base64(key)     // AAAAAAAAAAC...
+//	readable, err := ledgerkey.Canonical(key) // account:GABC...
+package ledgerkey
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/stellar/go/strkey"
+	"github.com/stellar/go/xdr"
+)
+
+// Base64 returns the base64 encoding of the XDR of a ledger key
+func Base64(key xdr.LedgerKey) (string, error) {
+	raw, err := key.MarshalBinary()
+	if err != nil {
+		return "", fmt.Errorf("could not marshal ledger key: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// Canonical returns a readable encoding of a ledger key, made of its type and its components joined with colons.
+// Accounts, contracts, liquidity pools and claimable balances are encoded as strkeys, hashes in hex and the keys of
+// contract data, which are arbitrary values, as the base64 encoding of their XDR.
+//
+//	account:<account>
+//	trustline:<account>:<asset code>:<asset issuer> or trustline:<account>:native or trustline:<account>:<pool>
+//	offer:<seller>:<offer id>
+//	data:<account>:<data name>
+//	claimable_balance:<balance id>
+//	liquidity_pool:<pool>
+//	contract_data:<contract>:<durability>:<key>
+//	contract_code:<hash>
+//	config_setting:<config setting id>
+//	ttl:<key hash>
+func Canonical(key xdr.LedgerKey) (string, error) {
+	var components []string
+	switch key.Type {
+	case xdr.LedgerEntryTypeAccount:
+		components = []string{"account", key.MustAccount().AccountId.Address()}
+	case xdr.LedgerEntryTypeTrustline:
+		trustline := key.MustTrustLine()
+		asset, err := trustLineAsset(trustline.Asset)
+		if err != nil {
+			return "", err
+		}
+		components = []string{"trustline", trustline.AccountId.Address(), asset}
+	case xdr.LedgerEntryTypeOffer:
+		offer := key.MustOffer()
+		components = []string{"offer", offer.SellerId.Address(), fmt.Sprint(int64(offer.OfferId))}
+	case xdr.LedgerEntryTypeData:
+		data := key.MustData()
+		components = []string{"data", data.AccountId.Address(), string(data.DataName)}
+	case xdr.LedgerEntryTypeClaimableBalance:
+		balanceID, err := key.MustClaimableBalance().BalanceId.EncodeToStrkey()
+		if err != nil {
+			return "", fmt.Errorf("could not encode claimable balance id: %v", err)
+		}
+		components = []string{"claimable_balance", balanceID}
+	case xdr.LedgerEntryTypeLiquidityPool:
+		poolID := key.MustLiquidityPool().LiquidityPoolId
+		pool, err := strkey.Encode(strkey.VersionByteLiquidityPool, poolID[:])
+		if err != nil {
+			return "", fmt.Errorf("could not encode liquidity pool id: %v", err)
+		}
+		components = []string{"liquidity_pool", pool}
+	case xdr.LedgerEntryTypeContractData:
+		contractData := key.MustContractData()
+		contract, err := contractData.Contract.String()
+		if err != nil {
+			return "", fmt.Errorf("could not encode contract address: %v", err)
+		}
+		dataKey, err := xdr.MarshalBase64(contractData.Key)
+		if err != nil {
+			return "", fmt.Errorf("could not marshal contract data key: %v", err)
+		}
+		durability := "persistent"
+		if contractData.Durability == xdr.ContractDataDurabilityTemporary {
+			durability = "temporary"
+		}
+		components = []string{"contract_data", contract, durability, dataKey}
+	case xdr.LedgerEntryTypeContractCode:
+		components = []string{"contract_code", key.MustContractCode().Hash.HexString()}
+	case xdr.LedgerEntryTypeConfigSetting:
+		components = []string{"config_setting", key.MustConfigSetting().ConfigSettingId.String()}
+	case xdr.LedgerEntryTypeTtl:
+		components = []string{"ttl", key.MustTtl().KeyHash.HexString()}
+	default:
+		return "", fmt.Errorf("unknown ledger key type %d", key.Type)
+	}
+
+	return strings.Join(components, ":"), nil
+}
+
+func trustLineAsset(asset xdr.TrustLineAsset) (string, error) {
+	switch asset.Type {
+	case xdr.AssetTypeAssetTypeNative:
+		return "native", nil
+	case xdr.AssetTypeAssetTypePoolShare:
+		poolID := asset.MustLiquidityPoolId()
+		pool, err := strkey.Encode(strkey.VersionByteLiquidityPool, poolID[:])
+		if err != nil {
+			return "", fmt.Errorf("could not encode liquidity pool id: %v", err)
+		}
+		return pool, nil
+	default:
+		return asset.ToAsset().StringCanonical(), nil
+	}
+}
@@ -0,0 +1,88 @@
+package ledgerkey
+
+import (
+	"testing"
+
+	"github.com/stellar/go/xdr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAccount = "GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ"
+
+func TestCanonical(t *testing.T) {
+	account := xdr.MustAddress(testAccount)
+	contractID := xdr.Hash{1}
+	poolID := xdr.PoolId{2}
+	symbol := xdr.ScSymbol("Balance")
+
+	trustlineKey := func(asset xdr.TrustLineAsset) xdr.LedgerKey {
+		var key xdr.LedgerKey
+		require.NoError(t, key.SetTrustline(account, asset))
+		return key
+	}
+
+	tests := []struct {
+		name string
+		key  xdr.LedgerKey
+		want string
+	}{
+		{
+			"account",
+			xdr.LedgerKey{Type: xdr.LedgerEntryTypeAccount, Account: &xdr.LedgerKeyAccount{AccountId: account}},
+			"account:" + testAccount,
+		},
+		{
+			"credit trustline",
+			trustlineKey(xdr.MustNewCreditAsset("USDT", testAccount).ToTrustLineAsset()),
+			"trustline:" + testAccount + ":USDT:" + testAccount,
+		},
+		{
+			"pool share trustline",
+			trustlineKey(xdr.TrustLineAsset{Type: xdr.AssetTypeAssetTypePoolShare, LiquidityPoolId: &poolID}),
+			"trustline:" + testAccount + ":LABAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAIND",
+		},
+		{
+			"offer",
+			xdr.LedgerKey{Type: xdr.LedgerEntryTypeOffer, Offer: &xdr.LedgerKeyOffer{SellerId: account, OfferId: 42}},
+			"offer:" + testAccount + ":42",
+		},
+		{
+			"data",
+			xdr.LedgerKey{Type: xdr.LedgerEntryTypeData, Data: &xdr.LedgerKeyData{AccountId: account, DataName: "name"}},
+			"data:" + testAccount + ":name",
+		},
+		{
+			"contract data",
+			xdr.LedgerKey{Type: xdr.LedgerEntryTypeContractData, ContractData: &xdr.LedgerKeyContractData{
+				Contract:   xdr.ScAddress{Type: xdr.ScAddressTypeScAddressTypeContract, ContractId: &contractID},
+				Key:        xdr.ScVal{Type: xdr.ScValTypeScvSymbol, Sym: &symbol},
+				Durability: xdr.ContractDataDurabilityTemporary,
+			}},
+			"contract_data:CAAQAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAABDQF:temporary:AAAADwAAAAdCYWxhbmNlAA==",
+		},
+		{
+			"ttl",
+			xdr.LedgerKey{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.LedgerKeyTtl{KeyHash: xdr.Hash{0xab}}},
+			"ttl:ab00000000000000000000000000000000000000000000000000000000000000",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			actual, err := Canonical(test.key)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, actual)
+		})
+	}
+}
+
+func TestBase64(t *testing.T) {
+	key := xdr.LedgerKey{Type: xdr.LedgerEntryTypeTtl, Ttl: &xdr.LedgerKeyTtl{KeyHash: xdr.Hash{0xab}}}
+	actual, err := Base64(key)
+	require.NoError(t, err)
+
+	expected, err := xdr.MarshalBase64(key)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,171 @@
+// Package schema exposes the structs of the rows exported by stellar-etl, so that Go consumers can unmarshal the
+// exported json without copying the struct definitions.
+//
+//	var effect schema.EffectOutput
+//	err := json.Unmarshal(line, &effect)
+//	name := schema.EffectTypeNames[schema.EffectType(effect.Type)]
+//
+// The types of this package are compatible within a major version of stellar-etl: columns are added to the rows,
+// but existing columns are not renamed, removed or given another type, and effect types keep their numbers.
+package schema
+
+import (
+	"github.com/stellar/stellar-etl/v2/internal/transform"
+)
+
+// The rows of the exported tables, and the records nested in them
+type (
+	AccountDataOutput          = transform.AccountDataOutput
+	AccountFlagsOutput         = transform.AccountFlagsOutput
+	AccountOutput              = transform.AccountOutput
+	AccountSignerOutput        = transform.AccountSignerOutput
+	AccountSummaryOutput       = transform.AccountSummaryOutput
+	ArchivalHistoryOutput      = transform.ArchivalHistoryOutput
+	AssetDimensionOutput       = transform.AssetDimensionOutput
+	AssetOutput                = transform.AssetOutput
+	ClaimableBalanceOutput     = transform.ClaimableBalanceOutput
+	Claimant                   = transform.Claimant
+	ConfigSettingOutput        = transform.ConfigSettingOutput
+	ContractBalanceOutput      = transform.ContractBalanceOutput
+	ContractCodeOutput         = transform.ContractCodeOutput
+	ContractDataOutput         = transform.ContractDataOutput
+	ContractEventOutput        = transform.ContractEventOutput
+	ContractFunction           = transform.ContractFunction
+	ContractFunctionInput      = transform.ContractFunctionInput
+	DimAccount                 = transform.DimAccount
+	DimMarket                  = transform.DimMarket
+	DimOffer                   = transform.DimOffer
+	EffectOutput               = transform.EffectOutput
+	EffectWideOutput           = transform.EffectWideOutput
+	FactOfferEvent             = transform.FactOfferEvent
+	FeeOutput                  = transform.FeeOutput
+	HomeDomainOutput           = transform.HomeDomainOutput
+	InflationDestinationOutput = transform.InflationDestinationOutput
+	LedgerOutput               = transform.LedgerOutput
+	LedgerTransactionOutput    = transform.LedgerTransactionOutput
+	MuxedAccountStatsOutput    = transform.MuxedAccountStatsOutput
+	NetworkUpgradeOutput       = transform.NetworkUpgradeOutput
+	NormalizedOfferOutput      = transform.NormalizedOfferOutput
+	OfferEventOutput           = transform.OfferEventOutput
+	OfferOutput                = transform.OfferOutput
+	OperationOutput            = transform.OperationOutput
+	Path                       = transform.Path
+	PoolOutput                 = transform.PoolOutput
+	PoolShareHolderOutput      = transform.PoolShareHolderOutput
+	SponsorshipOutput          = transform.SponsorshipOutput
+	TokenTransferOutput        = transform.TokenTransferOutput
+	TradeEffectDetails         = transform.TradeEffectDetails
+	TradeOutput                = transform.TradeOutput
+	TransactionOutput          = transform.TransactionOutput
+	TrustlineOutput            = transform.TrustlineOutput
+	TtlOutput                  = transform.TtlOutput
+)
+
+// EffectType is the type of an effect, exported as the type column of the effects
+type EffectType = transform.EffectType
+
+// The effect types, with the same numbers as the effect types of Horizon
+const (
+	EffectAccountCreated                     = transform.EffectAccountCreated
+	EffectAccountRemoved                     = transform.EffectAccountRemoved
+	EffectAccountCredited                    = transform.EffectAccountCredited
+	EffectAccountDebited                     = transform.EffectAccountDebited
+	EffectAccountThresholdsUpdated           = transform.EffectAccountThresholdsUpdated
+	EffectAccountHomeDomainUpdated           = transform.EffectAccountHomeDomainUpdated
+	EffectAccountFlagsUpdated                = transform.EffectAccountFlagsUpdated
+	EffectAccountInflationDestinationUpdated = transform.EffectAccountInflationDestinationUpdated
+	EffectSignerCreated                      = transform.EffectSignerCreated
+	EffectSignerRemoved                      = transform.EffectSignerRemoved
+	EffectSignerUpdated                      = transform.EffectSignerUpdated
+	EffectTrustlineCreated                   = transform.EffectTrustlineCreated
+	EffectTrustlineRemoved                   = transform.EffectTrustlineRemoved
+	EffectTrustlineUpdated                   = transform.EffectTrustlineUpdated
+	EffectTrustlineFlagsUpdated              = transform.EffectTrustlineFlagsUpdated
+	EffectOfferCreated                       = transform.EffectOfferCreated
+	EffectOfferRemoved                       = transform.EffectOfferRemoved
+	EffectOfferUpdated                       = transform.EffectOfferUpdated
+	EffectTrade                              = transform.EffectTrade
+	EffectTradeZeroFill                      = transform.EffectTradeZeroFill
+	EffectDataCreated                        = transform.EffectDataCreated
+	EffectDataRemoved                        = transform.EffectDataRemoved
+	EffectDataUpdated                        = transform.EffectDataUpdated
+	EffectSequenceBumped                     = transform.EffectSequenceBumped
+	EffectClaimableBalanceCreated            = transform.EffectClaimableBalanceCreated
+	EffectClaimableBalanceClaimantCreated    = transform.EffectClaimableBalanceClaimantCreated
+	EffectClaimableBalanceClaimed            = transform.EffectClaimableBalanceClaimed
+	EffectAccountSponsorshipCreated          = transform.EffectAccountSponsorshipCreated
+	EffectAccountSponsorshipUpdated          = transform.EffectAccountSponsorshipUpdated
+	EffectAccountSponsorshipRemoved          = transform.EffectAccountSponsorshipRemoved
+	EffectTrustlineSponsorshipCreated        = transform.EffectTrustlineSponsorshipCreated
+	EffectTrustlineSponsorshipUpdated        = transform.EffectTrustlineSponsorshipUpdated
+	EffectTrustlineSponsorshipRemoved        = transform.EffectTrustlineSponsorshipRemoved
+	EffectDataSponsorshipCreated             = transform.EffectDataSponsorshipCreated
+	EffectDataSponsorshipUpdated             = transform.EffectDataSponsorshipUpdated
+	EffectDataSponsorshipRemoved             = transform.EffectDataSponsorshipRemoved
+	EffectClaimableBalanceSponsorshipCreated = transform.EffectClaimableBalanceSponsorshipCreated
+	EffectClaimableBalanceSponsorshipUpdated = transform.EffectClaimableBalanceSponsorshipUpdated
+	EffectClaimableBalanceSponsorshipRemoved = transform.EffectClaimableBalanceSponsorshipRemoved
+	EffectSignerSponsorshipCreated           = transform.EffectSignerSponsorshipCreated
+	EffectSignerSponsorshipUpdated           = transform.EffectSignerSponsorshipUpdated
+	EffectSignerSponsorshipRemoved           = transform.EffectSignerSponsorshipRemoved
+	EffectOfferSponsorshipCreated            = transform.EffectOfferSponsorshipCreated
+	EffectOfferSponsorshipUpdated            = transform.EffectOfferSponsorshipUpdated
+	EffectOfferSponsorshipRemoved            = transform.EffectOfferSponsorshipRemoved
+	EffectClaimableBalanceClawedBack         = transform.EffectClaimableBalanceClawedBack
+	EffectLiquidityPoolDeposited             = transform.EffectLiquidityPoolDeposited
+	EffectLiquidityPoolWithdrew              = transform.EffectLiquidityPoolWithdrew
+	EffectLiquidityPoolTrade                 = transform.EffectLiquidityPoolTrade
+	EffectLiquidityPoolCreated               = transform.EffectLiquidityPoolCreated
+	EffectLiquidityPoolRemoved               = transform.EffectLiquidityPoolRemoved
+	EffectLiquidityPoolRevoked               = transform.EffectLiquidityPoolRevoked
+	EffectContractCredited                   = transform.EffectContractCredited
+	EffectContractDebited                    = transform.EffectContractDebited
+	EffectExtendFootprintTtl                 = transform.EffectExtendFootprintTtl
+	EffectRestoreFootprint                   = transform.EffectRestoreFootprint
+)
+
+// EffectTypeNames maps the effect types to the names exported as the type_string column of the effects. It must
+// not be modified.
+var EffectTypeNames = transform.EffectTypeNames
+
+// EffectCategories maps the effect types to the category column of the effects. It must not be modified.
+var EffectCategories = transform.EffectCategories
+
+// Tables returns the exported tables along with an empty row of each of them
+func Tables() map[string]interface{} {
+	return map[string]interface{}{
+		"ledgers":                       LedgerOutput{},
+		"transactions":                  TransactionOutput{},
+		"ledger_transaction":            LedgerTransactionOutput{},
+		"fees":                          FeeOutput{},
+		"operations":                    OperationOutput{},
+		"effects":                       EffectOutput{},
+		"effects_wide":                  EffectWideOutput{},
+		"trades":                        TradeOutput{},
+		"assets":                        AssetOutput{},
+		"asset_dimension":               AssetDimensionOutput{},
+		"account_summary":               AccountSummaryOutput{},
+		"muxed_account_stats":           MuxedAccountStatsOutput{},
+		"contract_events":               ContractEventOutput{},
+		"offer_events":                  OfferEventOutput{},
+		"token_transfers":               TokenTransferOutput{},
+		"archival_history":              ArchivalHistoryOutput{},
+		"network_upgrades":              NetworkUpgradeOutput{},
+		"accounts":                      AccountOutput{},
+		"account_data":                  AccountDataOutput{},
+		"signers":                       AccountSignerOutput{},
+		"home_domain_history":           HomeDomainOutput{},
+		"inflation_destination_history": InflationDestinationOutput{},
+		"account_flags_history":         AccountFlagsOutput{},
+		"trustlines":                    TrustlineOutput{},
+		"offers":                        OfferOutput{},
+		"liquidity_pools":               PoolOutput{},
+		"pool_share_holders":            PoolShareHolderOutput{},
+		"claimable_balances":            ClaimableBalanceOutput{},
+		"contract_data":                 ContractDataOutput{},
+		"contract_balances":             ContractBalanceOutput{},
+		"contract_code":                 ContractCodeOutput{},
+		"config_settings":               ConfigSettingOutput{},
+		"ttl":                           TtlOutput{},
+	}
+}
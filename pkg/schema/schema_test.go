@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmarshalEffect(t *testing.T) {
+	line := `{"address":"GCEODJVUUVYVFD5KT4TOEDTMXQ76OPFOQC2EMYYMLPXQCUVPOB6XRWPQ","operation_id":4294967297,` +
+		`"details":{"amount":"10.0000000","asset_type":"native"},"type":2,"type_string":"account_credited",` +
+		`"closed_at":"2020-07-09T05:28:42Z","ledger_sequence":1,"index":0,"id":"4294967297-0",` +
+		`"transaction_successful":true,"category":"account","batch_id":"abc"}`
+
+	var effect EffectOutput
+	require.NoError(t, json.Unmarshal([]byte(line), &effect))
+	assert.Equal(t, EffectAccountCredited, EffectType(effect.Type))
+	assert.Equal(t, effect.TypeString, EffectTypeNames[EffectType(effect.Type)])
+	assert.Equal(t, effect.Category, EffectCategories[EffectType(effect.Type)])
+	assert.Equal(t, "10.0000000", effect.Details["amount"])
+	assert.Equal(t, uint32(1), effect.LedgerSequence)
+}
+
+func TestTables(t *testing.T) {
+	tables := Tables()
+	assert.Contains(t, tables, "effects")
+	for table, row := range tables {
+		rowType := reflect.TypeOf(row)
+		require.Equal(t, reflect.Struct, rowType.Kind(), table)
+		assert.True(t, strings.HasSuffix(rowType.Name(), "Output"), table)
+	}
+}